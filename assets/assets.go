@@ -0,0 +1,169 @@
+// Package assets serves the static assets of an [fs.FS] under content-hash fingerprinted names,
+// so that they can be cached by the clients forever and busted by their content changes.
+//
+//	//go:embed static
+//	var static embed.FS
+//
+//	sub, _ := fs.Sub(static, "static")
+//	a, err := assets.New(sub, "/static")
+//	...
+//	a.Register(router.RouterGroup)
+//
+//	tmpl := template.New("").Funcs(a.FuncMap()) // {{ asset "app.css" }} -> /static/app-8f3a1b2c.css
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gowool/keratin"
+)
+
+const (
+	// ImmutableCacheControl is the Cache-Control header value of the fingerprinted assets.
+	ImmutableCacheControl = "public, max-age=31536000, immutable"
+
+	// RevalidateCacheControl is the Cache-Control header value of the assets requested by their original names.
+	RevalidateCacheControl = "no-cache"
+
+	fingerprintSize = 4 // bytes of the content hash, 8 hex characters
+)
+
+type asset struct {
+	name        string // original name
+	fingerprint string // fingerprinted name
+	etag        string
+}
+
+// Assets maps the files of an [fs.FS] to their fingerprinted URL paths and serves them.
+type Assets struct {
+	fsys   fs.FS
+	prefix string
+
+	byName        map[string]*asset
+	byFingerprint map[string]*asset
+}
+
+// New hashes the content of all files of fsys and returns the Assets served under the URL prefix, e.g. "/static".
+func New(fsys fs.FS, prefix string) (*Assets, error) {
+	if fsys == nil {
+		panic("assets: fsys is required")
+	}
+
+	a := &Assets{
+		fsys:          fsys,
+		prefix:        "/" + strings.Trim(prefix, "/"),
+		byName:        make(map[string]*asset),
+		byFingerprint: make(map[string]*asset),
+	}
+	if a.prefix == "/" {
+		a.prefix = ""
+	}
+
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		sum, err := hashFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		item := &asset{
+			name:        name,
+			fingerprint: Fingerprint(name, sum),
+			etag:        `"` + hex.EncodeToString(sum) + `"`,
+		}
+		a.byName[item.name] = item
+		a.byFingerprint[item.fingerprint] = item
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("assets: %w", err)
+	}
+
+	return a, nil
+}
+
+func hashFile(fsys fs.FS, name string) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// Fingerprint returns the name with the hex encoded prefix of the content hash inserted before its extension,
+// e.g. "css/app.css" -> "css/app-8f3a1b2c.css".
+func Fingerprint(name string, sum []byte) string {
+	if len(sum) > fingerprintSize {
+		sum = sum[:fingerprintSize]
+	}
+
+	ext := path.Ext(name)
+	if ext == path.Base(name) {
+		// dotfiles, e.g. ".htaccess"
+		ext = ""
+	}
+	return strings.TrimSuffix(name, ext) + "-" + hex.EncodeToString(sum) + ext
+}
+
+// Prefix returns the URL prefix of the assets.
+func (a *Assets) Prefix() string {
+	return a.prefix
+}
+
+// Path returns the fingerprinted URL path of the asset, e.g. "app.css" -> "/static/app-8f3a1b2c.css".
+// The unknown assets keep their name, so that a typo results in a 404 instead of a template error.
+func (a *Assets) Path(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if item, ok := a.byName[name]; ok {
+		name = item.fingerprint
+	}
+	return a.prefix + "/" + name
+}
+
+// FuncMap returns the template functions of the assets: "asset" returning [Assets.Path].
+func (a *Assets) FuncMap() template.FuncMap {
+	return template.FuncMap{"asset": a.Path}
+}
+
+// ServeHTTP serves the asset of the "path" route parameter. The fingerprinted assets are served
+// with [ImmutableCacheControl], the assets requested by their original names with [RevalidateCacheControl].
+// Both are served with a strong ETag of their content hash.
+func (a *Assets) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
+	name := r.PathValue("path")
+
+	cacheControl := ImmutableCacheControl
+	item, ok := a.byFingerprint[name]
+	if !ok {
+		if item, ok = a.byName[name]; !ok {
+			return keratin.ErrFileNotFound
+		}
+		cacheControl = RevalidateCacheControl
+	}
+
+	w.Header().Set(keratin.HeaderETag, item.etag)
+
+	return keratin.FileFS(a.fsys, item.name, keratin.CacheControl(cacheControl)).ServeHTTP(w, r)
+}
+
+// Register registers the GET route serving the assets under the prefix in group,
+// the group should have no prefix of its own for [Assets.Path] to match the route.
+func (a *Assets) Register(group *keratin.RouterGroup) *keratin.Route {
+	return group.GET(a.prefix+"/{path...}", a.ServeHTTP)
+}
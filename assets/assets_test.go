@@ -0,0 +1,145 @@
+package assets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"app.css":       {Data: []byte("body{}")},
+		"js/app.min.js": {Data: []byte("console.log(1)")},
+		"LICENSE":       {Data: []byte("MIT")},
+		".well-known":   {Data: []byte("x")},
+	}
+}
+
+func fingerprint(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:4])
+}
+
+func TestFingerprint(t *testing.T) {
+	sum, _ := hex.DecodeString("8f3a1b2c00ff")
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "app.css", want: "app-8f3a1b2c.css"},
+		{name: "js/app.min.js", want: "js/app.min-8f3a1b2c.js"},
+		{name: "LICENSE", want: "LICENSE-8f3a1b2c"},
+		{name: ".htaccess", want: ".htaccess-8f3a1b2c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Fingerprint(tt.name, sum))
+		})
+	}
+}
+
+func TestAssets_Path(t *testing.T) {
+	tests := []struct {
+		prefix string
+		name   string
+		want   string
+	}{
+		{prefix: "/static", name: "app.css", want: "/static/app-" + fingerprint("body{}") + ".css"},
+		{prefix: "static/", name: "/js/app.min.js", want: "/static/js/app.min-" + fingerprint("console.log(1)") + ".js"},
+		{prefix: "", name: "LICENSE", want: "/LICENSE-" + fingerprint("MIT")},
+		{prefix: "/static", name: "missing.css", want: "/static/missing.css"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix+tt.name, func(t *testing.T) {
+			a, err := New(testFS(), tt.prefix)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, a.Path(tt.name))
+		})
+	}
+}
+
+func TestAssets_FuncMap(t *testing.T) {
+	a, err := New(testFS(), "/static")
+	require.NoError(t, err)
+
+	tmpl := template.Must(template.New("").Funcs(a.FuncMap()).Parse(`<link href="{{ asset "app.css" }}">`))
+
+	var buf bytes.Buffer
+	require.NoError(t, tmpl.Execute(&buf, nil))
+	assert.Equal(t, `<link href="/static/app-`+fingerprint("body{}")+`.css">`, buf.String())
+}
+
+func TestAssets_Register(t *testing.T) {
+	a, err := New(testFS(), "/static")
+	require.NoError(t, err)
+
+	router := keratin.NewRouter()
+	a.Register(router.RouterGroup)
+	handler := router.Build()
+
+	sum := sha256.Sum256([]byte("body{}"))
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+
+	tests := []struct {
+		name             string
+		target           string
+		ifNoneMatch      string
+		wantStatus       int
+		wantBody         string
+		wantCacheControl string
+	}{
+		{name: "fingerprinted", target: a.Path("app.css"), wantStatus: http.StatusOK, wantBody: "body{}", wantCacheControl: ImmutableCacheControl},
+		{name: "nested fingerprinted", target: a.Path("js/app.min.js"), wantStatus: http.StatusOK, wantBody: "console.log(1)", wantCacheControl: ImmutableCacheControl},
+		{name: "original name", target: "/static/app.css", wantStatus: http.StatusOK, wantBody: "body{}", wantCacheControl: RevalidateCacheControl},
+		{name: "not modified", target: a.Path("app.css"), ifNoneMatch: etag, wantStatus: http.StatusNotModified, wantCacheControl: ImmutableCacheControl},
+		{name: "stale fingerprint", target: "/static/app-00000000.css", wantStatus: http.StatusNotFound, wantBody: "Not Found\n"},
+		{name: "missing", target: "/static/missing.css", wantStatus: http.StatusNotFound, wantBody: "Not Found\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set(keratin.HeaderIfNoneMatch, tt.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantBody, rec.Body.String())
+			assert.Equal(t, tt.wantCacheControl, rec.Header().Get(keratin.HeaderCacheControl))
+		})
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	assert.Panics(t, func() { _, _ = New(nil, "/static") })
+
+	_, err := New(openErrFS{testFS()}, "/static")
+	assert.ErrorIs(t, err, fs.ErrPermission)
+}
+
+type openErrFS struct {
+	fstest.MapFS
+}
+
+func (f openErrFS) Open(name string) (fs.File, error) {
+	if name != "." {
+		return nil, fs.ErrPermission
+	}
+	return f.MapFS.Open(name)
+}
@@ -0,0 +1,435 @@
+package keratin
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	// ACMEChallengePath is the path prefix of the ACME HTTP-01 challenges, see [ServeAutoTLS].
+	ACMEChallengePath = "/.well-known/acme-challenge/"
+
+	mimeOCSPRequest = "application/ocsp-request"
+
+	maxOCSPResponseLen = 1 << 20
+)
+
+// modernCipherSuites are the TLS 1.2 cipher suites with forward secrecy and AEAD,
+// the TLS 1.3 ones are not configurable.
+var modernCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+type AutoTLSOption func(*autoTLS)
+
+// WithAutoTLSEmail sets the contact email of the ACME account, notified about the certificate problems.
+func WithAutoTLSEmail(email string) AutoTLSOption {
+	return func(a *autoTLS) {
+		a.manager.Email = email
+	}
+}
+
+// WithAutoTLSDirectoryURL sets the ACME directory URL, e.g. the one of the Let's Encrypt staging environment.
+// Default value is [acme.LetsEncryptURL].
+func WithAutoTLSDirectoryURL(url string) AutoTLSOption {
+	return func(a *autoTLS) {
+		if url != "" {
+			a.manager.Client = &acme.Client{DirectoryURL: url}
+		}
+	}
+}
+
+// WithAutoTLSAddr sets the addresses of the HTTPS and the HTTP listeners.
+// Default values are ":443" and ":80". The ACME servers send the HTTP-01 challenges to the port 80 only.
+func WithAutoTLSAddr(addr, httpAddr string) AutoTLSOption {
+	return func(a *autoTLS) {
+		if addr != "" {
+			a.addr = addr
+		}
+		if httpAddr != "" {
+			a.httpAddr = httpAddr
+		}
+	}
+}
+
+// WithAutoTLSShutdownTimeout sets the time given to the in-flight requests to complete once the context is done.
+// Default value is 10 seconds.
+func WithAutoTLSShutdownTimeout(timeout time.Duration) AutoTLSOption {
+	return func(a *autoTLS) {
+		if timeout > 0 {
+			a.shutdownTimeout = timeout
+		}
+	}
+}
+
+// WithAutoTLSOCSPStapling enables or disables the OCSP stapling. Default value is true.
+func WithAutoTLSOCSPStapling(enabled bool) AutoTLSOption {
+	return func(a *autoTLS) {
+		a.stapling = enabled
+	}
+}
+
+type autoTLS struct {
+	manager         *autocert.Manager
+	stapler         *ocspStapler
+	logger          *slog.Logger
+	addr            string
+	httpAddr        string
+	shutdownTimeout time.Duration
+	stapling        bool
+}
+
+func newAutoTLS(router *Router, domains []string, cacheDir string, opts ...AutoTLSOption) (*autoTLS, error) {
+	if len(domains) == 0 {
+		return nil, errors.New("keratin: auto tls: no domains")
+	}
+
+	a := &autoTLS{
+		manager: &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(domains...),
+		},
+		logger:          router.logger,
+		addr:            ":443",
+		httpAddr:        ":80",
+		shutdownTimeout: 10 * time.Second,
+		stapling:        true,
+	}
+	if cacheDir != "" {
+		a.manager.Cache = autocert.DirCache(cacheDir)
+	}
+	if a.logger == nil {
+		a.logger = slog.Default()
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	if a.stapling {
+		a.stapler = &ocspStapler{
+			client: &http.Client{Timeout: 5 * time.Second},
+			logger: a.logger,
+			cache:  make(map[string]*ocspStaple),
+		}
+	}
+	return a, nil
+}
+
+// ServeAutoTLS serves the router over HTTPS with the certificates of the domains obtained and renewed
+// automatically from Let's Encrypt (ACME), until ctx is done. The certificates are cached in cacheDir,
+// an empty cacheDir requests them again on every start and quickly hits the rate limits of Let's Encrypt.
+//
+// The HTTP-01 challenges are answered by the route "GET /.well-known/acme-challenge/{token}" registered
+// on the router, so they pass through its middlewares and request logging. The HTTP listener serves
+// this route only and redirects the other requests to HTTPS.
+//
+// The TLS configuration accepts TLS 1.2 and later with the forward secret AEAD cipher suites only,
+// and staples the OCSP responses of the certificates issued with an OCSP responder.
+//
+// Once ctx is done the listeners are shut down gracefully and ServeAutoTLS returns nil,
+// otherwise it returns the first error of the listeners.
+func ServeAutoTLS(ctx context.Context, router *Router, domains []string, cacheDir string, opts ...AutoTLSOption) error {
+	a, err := newAutoTLS(router, domains, cacheDir, opts...)
+	if err != nil {
+		return err
+	}
+
+	router.GET(ACMEChallengePath+"{token}", a.challenge)
+	if err = router.Rebuild(); err != nil {
+		return err
+	}
+
+	return a.serve(ctx, router.Handler())
+}
+
+func (a *autoTLS) serve(ctx context.Context, handler http.Handler) error {
+	tlsListener, err := net.Listen("tcp", a.addr)
+	if err != nil {
+		return err
+	}
+	httpListener, err := net.Listen("tcp", a.httpAddr)
+	if err != nil {
+		_ = tlsListener.Close()
+		return err
+	}
+
+	errorLog := slog.NewLogLogger(a.logger.Handler(), slog.LevelError)
+	tlsServer := &http.Server{
+		Handler:           handler,
+		TLSConfig:         a.tlsConfig(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          errorLog,
+	}
+	httpServer := &http.Server{
+		Handler:           a.httpHandler(handler),
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          errorLog,
+	}
+
+	chErr := make(chan error, 2)
+	go func() { chErr <- tlsServer.ServeTLS(tlsListener, "", "") }()
+	go func() { chErr <- httpServer.Serve(httpListener) }()
+
+	a.logger.InfoContext(ctx, "start auto tls",
+		slog.String("address", tlsListener.Addr().String()),
+		slog.String("http_address", httpListener.Addr().String()),
+	)
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+	case serveErr = <-chErr:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), a.shutdownTimeout)
+	defer cancel()
+
+	return errors.Join(serveErr, tlsServer.Shutdown(shutdownCtx), httpServer.Shutdown(shutdownCtx))
+}
+
+func (a *autoTLS) challenge(w http.ResponseWriter, r *http.Request) error {
+	a.manager.HTTPHandler(nil).ServeHTTP(w, r)
+	return nil
+}
+
+// httpHandler serves the ACME challenges with the router handler and redirects the other requests to HTTPS.
+func (a *autoTLS) httpHandler(handler http.Handler) http.Handler {
+	_, port, _ := net.SplitHostPort(a.addr)
+	if port == "443" {
+		port = ""
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, ACMEChallengePath) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" {
+			host = net.JoinHostPort(host, port)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
+
+func (a *autoTLS) tlsConfig() *tls.Config {
+	cfg := a.manager.TLSConfig()
+	cfg.MinVersion = tls.VersionTLS12
+	cfg.CipherSuites = modernCipherSuites
+	cfg.CurvePreferences = []tls.CurveID{tls.X25519MLKEM768, tls.X25519, tls.CurveP256}
+
+	if a.stapler != nil {
+		cfg.GetCertificate = a.stapler.getCertificate(cfg.GetCertificate)
+	}
+	return cfg
+}
+
+type ocspStaple struct {
+	response  []byte
+	refreshAt time.Time
+	expiresAt time.Time
+	notAfter  time.Time
+	fetching  bool
+}
+
+// ocspStapler caches the OCSP responses of the certificates and refreshes them
+// in the background halfway through their validity.
+type ocspStapler struct {
+	client *http.Client
+	logger *slog.Logger
+	mu     sync.Mutex
+	cache  map[string]*ocspStaple
+}
+
+func (s *ocspStapler) getCertificate(next func(*tls.ClientHelloInfo) (*tls.Certificate, error)) func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		cert, err := next(hello)
+		if err != nil || cert == nil {
+			return cert, err
+		}
+
+		ctx := hello.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		response := s.staple(ctx, cert)
+		if response == nil {
+			return cert, nil
+		}
+
+		stapled := *cert
+		stapled.OCSPStaple = response
+		return &stapled, nil
+	}
+}
+
+// staple returns the cached OCSP response of the certificate. The missing response is fetched
+// by the first handshake, the stale one is refreshed in the background.
+func (s *ocspStapler) staple(ctx context.Context, cert *tls.Certificate) []byte {
+	leaf, issuer := ocspCertificates(cert)
+	if leaf == nil || issuer == nil || len(leaf.OCSPServer) == 0 {
+		return nil
+	}
+	key := string(leaf.Raw)
+	now := time.Now()
+
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	if !ok {
+		s.cache[key] = &ocspStaple{notAfter: leaf.NotAfter, fetching: true}
+		s.mu.Unlock()
+
+		return s.store(key, s.fetch(ctx, leaf, issuer))
+	}
+	defer s.mu.Unlock()
+
+	if !entry.fetching && now.After(entry.refreshAt) {
+		entry.fetching = true
+		go s.refresh(key, leaf, issuer)
+	}
+	if now.After(entry.expiresAt) {
+		return nil
+	}
+	return entry.response
+}
+
+func (s *ocspStapler) refresh(key string, leaf, issuer *x509.Certificate) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout)
+	defer cancel()
+
+	s.store(key, s.fetch(ctx, leaf, issuer))
+}
+
+// store caches the fetched staple and returns its response. A failed refresh keeps
+// the previous response until it expires.
+func (s *ocspStapler) store(key string, staple *ocspStaple) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, e := range s.cache {
+		if now.After(e.notAfter) {
+			delete(s.cache, k)
+		}
+	}
+
+	prev, ok := s.cache[key]
+	if !ok {
+		return nil
+	}
+	if staple.response == nil && now.Before(prev.expiresAt) {
+		staple.response, staple.expiresAt = prev.response, prev.expiresAt
+	}
+	staple.notAfter = prev.notAfter
+	s.cache[key] = staple
+
+	return staple.response
+}
+
+// fetch requests the OCSP response of the certificate, on failure the returned staple
+// has no response and is retried after a minute.
+func (s *ocspStapler) fetch(ctx context.Context, leaf, issuer *x509.Certificate) *ocspStaple {
+	raw, response, err := s.request(ctx, leaf, issuer)
+	if err != nil {
+		s.logger.WarnContext(ctx, "ocsp stapling",
+			slog.Any("dns_names", leaf.DNSNames),
+			slog.Any("error", err),
+		)
+		return &ocspStaple{refreshAt: time.Now().Add(time.Minute)}
+	}
+
+	staple := &ocspStaple{
+		response:  raw,
+		refreshAt: time.Now().Add(time.Hour),
+		expiresAt: response.NextUpdate,
+	}
+	if !response.NextUpdate.IsZero() {
+		staple.refreshAt = response.ThisUpdate.Add(response.NextUpdate.Sub(response.ThisUpdate) / 2)
+	} else {
+		staple.expiresAt = staple.refreshAt.Add(time.Hour)
+	}
+	return staple
+}
+
+func (s *ocspStapler) request(ctx context.Context, leaf, issuer *x509.Certificate) ([]byte, *ocsp.Response, error) {
+	body, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, leaf.OCSPServer[0], bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set(HeaderContentType, mimeOCSPRequest)
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(res.Body, maxOCSPResponseLen))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parsed, err := ocsp.ParseResponseForCert(raw, leaf, issuer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if parsed.Status != ocsp.Good {
+		return nil, nil, fmt.Errorf("certificate status %d", parsed.Status)
+	}
+	return raw, parsed, nil
+}
+
+func ocspCertificates(cert *tls.Certificate) (leaf, issuer *x509.Certificate) {
+	if len(cert.Certificate) < 2 {
+		return nil, nil
+	}
+
+	leaf = cert.Leaf
+	if leaf == nil {
+		var err error
+		if leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return nil, nil
+		}
+	}
+
+	issuer, err := x509.ParseCertificate(cert.Certificate[1])
+	if err != nil {
+		return nil, nil
+	}
+	return leaf, issuer
+}
@@ -0,0 +1,203 @@
+package keratin
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestServeAutoTLS_NoDomains(t *testing.T) {
+	err := ServeAutoTLS(t.Context(), NewRouter(), nil, t.TempDir())
+	require.Error(t, err)
+}
+
+func TestServeAutoTLS_Shutdown(t *testing.T) {
+	router := NewRouter()
+	router.GET("/", func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	err := ServeAutoTLS(ctx, router, []string{"example.com"}, t.TempDir(),
+		WithAutoTLSAddr("127.0.0.1:0", "127.0.0.1:0"),
+		WithAutoTLSShutdownTimeout(time.Second),
+	)
+	require.NoError(t, err)
+	assert.True(t, slices.Contains(slices.Collect(router.Patterns()), "GET "+ACMEChallengePath+"{token}"))
+}
+
+func TestAutoTLS_HTTPHandler(t *testing.T) {
+	a, err := newAutoTLS(NewRouter(), []string{"example.com"}, "", WithAutoTLSAddr(":8443", ""))
+	require.NoError(t, err)
+
+	var served bool
+	h := a.httpHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		served = true
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("redirect", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "http://example.com:8080/a?b=1", nil))
+
+		assert.Equal(t, http.StatusPermanentRedirect, rec.Code)
+		assert.Equal(t, "https://example.com:8443/a?b=1", rec.Header().Get(HeaderLocation))
+		assert.False(t, served)
+	})
+
+	t.Run("challenge", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://example.com"+ACMEChallengePath+"token", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, served)
+	})
+}
+
+func TestAutoTLS_TLSConfig(t *testing.T) {
+	a, err := newAutoTLS(NewRouter(), []string{"example.com"}, "", WithAutoTLSEmail("admin@example.com"))
+	require.NoError(t, err)
+
+	cfg := a.tlsConfig()
+	assert.Equal(t, uint16(tls.VersionTLS12), cfg.MinVersion)
+	assert.Equal(t, modernCipherSuites, cfg.CipherSuites)
+	assert.Contains(t, cfg.NextProtos, "h2")
+	assert.Contains(t, cfg.NextProtos, acme.ALPNProto)
+	assert.NotNil(t, cfg.GetCertificate)
+	assert.Equal(t, "admin@example.com", a.manager.Email)
+}
+
+func TestOCSPStapler(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, caKey.Public(), caKey)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	var (
+		requests atomic.Int32
+		failing  atomic.Bool
+	)
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		raw, _ := io.ReadAll(r.Body)
+		req, err := ocsp.ParseRequest(raw)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		res, _ := ocsp.CreateResponse(ca, ca, ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+		}, caKey)
+		_, _ = w.Write(res)
+	}))
+	defer responder.Close()
+
+	newCert := func(serial int64) *tls.Certificate {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			DNSNames:     []string{"example.com"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			OCSPServer:   []string{responder.URL},
+		}, ca, key.Public(), caKey)
+		require.NoError(t, err)
+		return &tls.Certificate{Certificate: [][]byte{der, caDER}, PrivateKey: key}
+	}
+
+	a, err := newAutoTLS(NewRouter(), []string{"example.com"}, "")
+	require.NoError(t, err)
+
+	getCertificate := func(cert *tls.Certificate) func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return a.stapler.getCertificate(func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return cert, nil
+		})
+	}
+	hello := &tls.ClientHelloInfo{}
+
+	t.Run("stapled and cached", func(t *testing.T) {
+		cert := newCert(2)
+		get := getCertificate(cert)
+
+		stapled, err := get(hello)
+		require.NoError(t, err)
+		require.NotEmpty(t, stapled.OCSPStaple)
+		assert.Nil(t, cert.OCSPStaple)
+
+		res, err := ocsp.ParseResponse(stapled.OCSPStaple, ca)
+		require.NoError(t, err)
+		assert.Equal(t, ocsp.Good, res.Status)
+
+		again, err := get(hello)
+		require.NoError(t, err)
+		assert.Equal(t, stapled.OCSPStaple, again.OCSPStaple)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("responder failure", func(t *testing.T) {
+		failing.Store(true)
+		defer failing.Store(false)
+
+		stapled, err := getCertificate(newCert(3))(hello)
+		require.NoError(t, err)
+		assert.Empty(t, stapled.OCSPStaple)
+	})
+
+	t.Run("no responder", func(t *testing.T) {
+		cert := newCert(4)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		leaf.OCSPServer = nil
+		cert.Leaf = leaf
+
+		stapled, err := getCertificate(cert)(hello)
+		require.NoError(t, err)
+		assert.Same(t, cert, stapled)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		a, err := newAutoTLS(NewRouter(), []string{"example.com"}, "", WithAutoTLSOCSPStapling(false))
+		require.NoError(t, err)
+		assert.Nil(t, a.stapler)
+	})
+}
@@ -0,0 +1,102 @@
+// Package bench provides realistic route tables, requests and middleware stacks for the router benchmarks.
+//
+// The benchmarks of the package report ns/op and allocs/op of the whole request path (router, response wrapper,
+// middleware chains), compare them across keratin versions with benchstat:
+//
+//	go test -run '^$' -bench . -benchmem -count 10 ./bench > old.txt
+//	git checkout <other version>
+//	go test -run '^$' -bench . -benchmem -count 10 ./bench > new.txt
+//	benchstat old.txt new.txt
+//
+// TestAllocs fails when the allocations per request exceed their budgets, so the hot path
+// regressions are caught by go test.
+package bench
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+
+	"github.com/gowool/keratin"
+)
+
+// Route is a route of a route table.
+type Route struct {
+	Method string
+	Path   string
+}
+
+var wildcard = regexp.MustCompile(`\{([^}.]+)(\.\.\.)?}`)
+
+// Register registers the routes on the group with the handler.
+func Register(group *keratin.RouterGroup, routes []Route, handler keratin.Handler) {
+	for _, route := range routes {
+		group.Route(route.Method, route.Path, handler)
+	}
+}
+
+// NewRequest returns a request matching the route, its wildcards are replaced by their names.
+func NewRequest(route Route) *http.Request {
+	return httptest.NewRequest(route.Method, wildcard.ReplaceAllString(route.Path, "$1"), nil)
+}
+
+// NewRequests returns a request for each route.
+func NewRequests(routes []Route) []*http.Request {
+	requests := make([]*http.Request, len(routes))
+	for i, route := range routes {
+		requests[i] = NewRequest(route)
+	}
+	return requests
+}
+
+// NoContent is a handler which responds 204 No Content.
+var NoContent = keratin.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+})
+
+// PassThrough returns n middlewares which only call the next handler,
+// it measures the cost of the chain composition.
+func PassThrough(n int) []func(keratin.Handler) keratin.Handler {
+	middlewares := make([]func(keratin.Handler) keratin.Handler, n)
+	for i := range middlewares {
+		middlewares[i] = func(next keratin.Handler) keratin.Handler {
+			return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				return next.ServeHTTP(w, r)
+			})
+		}
+	}
+	return middlewares
+}
+
+// DiscardWriter is a reusable [http.ResponseWriter] which discards the response,
+// unlike [httptest.ResponseRecorder] it does not allocate.
+type DiscardWriter struct {
+	header http.Header
+	Code   int
+}
+
+func NewDiscardWriter() *DiscardWriter {
+	return &DiscardWriter{header: make(http.Header)}
+}
+
+func (w *DiscardWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *DiscardWriter) WriteHeader(statusCode int) {
+	w.Code = statusCode
+}
+
+func (w *DiscardWriter) Write(b []byte) (int, error) {
+	if w.Code == 0 {
+		w.Code = http.StatusOK
+	}
+	return len(b), nil
+}
+
+// Reset clears the status code and the headers.
+func (w *DiscardWriter) Reset() {
+	w.Code = 0
+	clear(w.header)
+}
@@ -0,0 +1,152 @@
+package bench
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+	"github.com/gowool/keratin/middleware"
+)
+
+type stack struct {
+	name  string
+	setup func(router *keratin.Router)
+}
+
+var stacks = []stack{
+	{
+		name:  "bare",
+		setup: func(*keratin.Router) {},
+	},
+	{
+		name: "passthrough10",
+		setup: func(router *keratin.Router) {
+			router.UseFunc(PassThrough(10)...)
+		},
+	},
+	{
+		name: "realistic",
+		setup: func(router *keratin.Router) {
+			router.UseFunc(
+				middleware.Recover(middleware.RecoverConfig{}),
+				middleware.RequestID(middleware.RequestIDConfig{}),
+				middleware.Secure(middleware.SecureConfig{}),
+			)
+		},
+	},
+}
+
+func newGitHubHandler(s stack) http.Handler {
+	router := keratin.NewRouter()
+	s.setup(router)
+	Register(router.RouterGroup, GitHubAPI, NoContent)
+	return router.Build()
+}
+
+func TestGitHubAPI(t *testing.T) {
+	require.Len(t, GitHubAPI, 203)
+
+	handler := newGitHubHandler(stacks[0])
+	w := NewDiscardWriter()
+
+	for _, route := range GitHubAPI {
+		w.Reset()
+		handler.ServeHTTP(w, NewRequest(route))
+		assert.Equal(t, http.StatusNoContent, w.Code, "%s %s", route.Method, route.Path)
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	r := NewRequest(Route{Method: http.MethodGet, Path: "/repos/{owner}/{repo}/files/{path...}"})
+
+	assert.Equal(t, http.MethodGet, r.Method)
+	assert.Equal(t, "/repos/owner/repo/files/path", r.URL.Path)
+}
+
+// TestAllocs guards the allocations per request of the hot path, lower the budgets
+// when an optimisation lands and never raise them without a reason.
+func TestAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector adds allocations")
+	}
+
+	tests := []struct {
+		stack  stack
+		route  Route
+		budget float64
+	}{
+		{stack: stacks[0], route: Route{http.MethodGet, "/user/repos"}, budget: 3},
+		{stack: stacks[0], route: Route{http.MethodGet, "/repos/{owner}/{repo}/stargazers"}, budget: 5},
+		{stack: stacks[1], route: Route{http.MethodGet, "/repos/{owner}/{repo}/stargazers"}, budget: 5},
+		{stack: stacks[2], route: Route{http.MethodGet, "/repos/{owner}/{repo}/stargazers"}, budget: 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stack.name+" "+tt.route.Path, func(t *testing.T) {
+			handler := newGitHubHandler(tt.stack)
+			r := NewRequest(tt.route)
+			w := NewDiscardWriter()
+
+			allocs := testing.AllocsPerRun(100, func() {
+				w.Reset()
+				handler.ServeHTTP(w, r)
+			})
+
+			require.Equal(t, http.StatusNoContent, w.Code)
+			assert.LessOrEqual(t, allocs, tt.budget)
+		})
+	}
+}
+
+func BenchmarkGitHubAPI(b *testing.B) {
+	routes := []struct {
+		name  string
+		route Route
+	}{
+		{name: "static", route: Route{http.MethodGet, "/user/repos"}},
+		{name: "param", route: Route{http.MethodGet, "/repos/{owner}/{repo}/stargazers"}},
+		{name: "params4", route: Route{http.MethodGet, "/legacy/issues/search/{owner}/{repository}/{state}/{keyword}"}},
+	}
+
+	for _, s := range stacks {
+		handler := newGitHubHandler(s)
+
+		for _, rt := range routes {
+			b.Run(s.name+"/"+rt.name, func(b *testing.B) {
+				r := NewRequest(rt.route)
+				w := NewDiscardWriter()
+
+				b.ReportAllocs()
+				for b.Loop() {
+					w.Reset()
+					handler.ServeHTTP(w, r)
+				}
+			})
+		}
+
+		b.Run(s.name+"/all", func(b *testing.B) {
+			requests := NewRequests(GitHubAPI)
+			w := NewDiscardWriter()
+
+			b.ReportAllocs()
+			for b.Loop() {
+				for _, r := range requests {
+					w.Reset()
+					handler.ServeHTTP(w, r)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkBuild(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		router := keratin.NewRouter()
+		Register(router.RouterGroup, GitHubAPI, NoContent)
+		_ = router.Build()
+	}
+}
@@ -0,0 +1,182 @@
+package keratin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gowool/keratin/internal"
+)
+
+// Validator validates bound values, e.g. an adapter around github.com/go-playground/validator.
+//
+// Implementations should return [ValidationErrors] to get per-field details in the error response.
+type Validator interface {
+	Validate(i any) error
+}
+
+// ValidatorFunc is an adapter to allow the use of ordinary functions as [Validator].
+type ValidatorFunc func(i any) error
+
+func (f ValidatorFunc) Validate(i any) error {
+	return f(i)
+}
+
+// Validatable can be implemented by bound values to validate themselves
+// when no [Validator] is configured on the router.
+type Validatable interface {
+	Validate() error
+}
+
+// FieldError describes a validation failure of a single field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors is a list of per-field validation failures.
+type ValidationErrors []FieldError
+
+func (ve ValidationErrors) Error() string {
+	var b strings.Builder
+	for i, fe := range ve {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(fe.Field)
+		b.WriteString(": ")
+		b.WriteString(fe.Message)
+	}
+	return b.String()
+}
+
+// Validate validates i using the router validator (see [WithValidator]) or, when no validator
+// is configured, the [Validatable] implementation of i.
+//
+// Validation failures are returned as a 422 [HTTPError] with [ValidationErrors] as its data.
+func Validate(r *http.Request, i any) error {
+	var err error
+	if c, ok := FromContext(r.Context()).(*kContext); ok && c.validator != nil {
+		err = c.validator.Validate(i)
+	} else if v, ok := i.(Validatable); ok {
+		err = v.Validate()
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	httpErr := &HTTPError{
+		Code:    http.StatusUnprocessableEntity,
+		Message: http.StatusText(http.StatusUnprocessableEntity),
+		err:     err,
+	}
+
+	if fields, ok := errors.AsType[ValidationErrors](err); ok {
+		httpErr.Data = fields
+	} else {
+		httpErr.Message = err.Error()
+	}
+
+	return httpErr
+}
+
+// Bind decodes the request body into dst according to the request Content-Type.
+// JSON, XML and form (see [BindForm]) bodies are supported.
+func Bind(r *http.Request, dst any) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return NewHTTPError(http.StatusBadRequest, "request body is empty")
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get(HeaderContentType))
+	if err != nil {
+		return ErrUnsupportedMediaType.Wrap(err)
+	}
+
+	switch {
+	case mediaType == MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json"):
+		err = internal.UnmarshalJSON(r.Body, dst)
+	case mediaType == MIMEApplicationXML || mediaType == MIMETextXML || strings.HasSuffix(mediaType, "+xml"):
+		err = internal.UnmarshalXML(r.Body, dst)
+	case mediaType == MIMEApplicationForm || mediaType == MIMEMultipartForm:
+		return BindForm(r, dst)
+	default:
+		return ErrUnsupportedMediaType.Wrap(fmt.Errorf("unsupported content type %q", mediaType))
+	}
+
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return NewHTTPError(http.StatusBadRequest, "request body is empty")
+		}
+		if code := ErrorStatusCode(err); code >= http.StatusBadRequest {
+			return err
+		}
+		return ErrBadRequest.Wrap(err)
+	}
+
+	return nil
+}
+
+// BindForm binds the URL-encoded or multipart form values (including the query string)
+// into the struct pointed to by dst using `form` tags.
+//
+// Nested structs are bound from the dotted keys and slices from the repeated
+// or the "[]" suffixed keys, [time.Time] fields are parsed as RFC 3339 or with the `layout` tag:
+//
+//	type SignUp struct {
+//		Name    string    `form:"name"`
+//		Born    time.Time `form:"born" layout:"2006-01-02"`
+//		Tags    []string  `form:"tags"` // tags=a&tags=b or tags[]=a&tags[]=b
+//		Address *struct {
+//			City string `form:"city"` // address.city=Berlin
+//		} `form:"address"`
+//	}
+//
+// Conversion failures of all the fields are returned as a 422 [HTTPError] with [ValidationErrors] as its data.
+func BindForm(r *http.Request, dst any) error {
+	if r.Form == nil {
+		if err := r.ParseMultipartForm(MultipartMaxMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return multipartError(err)
+		}
+	}
+
+	err := internal.BindValues(dst, r.Form, "form")
+	if err == nil {
+		return nil
+	}
+
+	var fields ValidationErrors
+	for _, e := range joinedErrors(err) {
+		if bindErr, ok := errors.AsType[*internal.BindError](e); ok {
+			fields = append(fields, FieldError{Field: bindErr.Name, Message: bindErr.Err.Error()})
+		}
+	}
+	if len(fields) == 0 {
+		return err
+	}
+
+	return &HTTPError{
+		Code:    http.StatusUnprocessableEntity,
+		Message: http.StatusText(http.StatusUnprocessableEntity),
+		Data:    fields,
+		err:     err,
+	}
+}
+
+func joinedErrors(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+// BindAndValidate decodes the request body into dst (see [Bind]) and validates it (see [Validate]).
+func BindAndValidate(r *http.Request, dst any) error {
+	if err := Bind(r, dst); err != nil {
+		return err
+	}
+	return Validate(r, dst)
+}
@@ -0,0 +1,306 @@
+package keratin
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bindTestUser struct {
+	Name string `json:"name" xml:"name" form:"name"`
+	Age  int    `json:"age" xml:"age" form:"age"`
+}
+
+type bindTestSelfValidated struct {
+	Name string `json:"name"`
+}
+
+func (v bindTestSelfValidated) Validate() error {
+	if v.Name == "" {
+		return ValidationErrors{{Field: "name", Message: "is required"}}
+	}
+	return nil
+}
+
+func bindTestValidator(i any) error {
+	u, ok := i.(*bindTestUser)
+	if !ok {
+		return nil
+	}
+
+	var errs ValidationErrors
+	if u.Name == "" {
+		errs = append(errs, FieldError{Field: "name", Message: "is required"})
+	}
+	if u.Age < 18 {
+		errs = append(errs, FieldError{Field: "age", Message: "must be at least 18"})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func TestValidationErrors_Error(t *testing.T) {
+	errs := ValidationErrors{
+		{Field: "name", Message: "is required"},
+		{Field: "age", Message: "must be at least 18"},
+	}
+	assert.Equal(t, "name: is required; age: must be at least 18", errs.Error())
+}
+
+func TestBind(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        bindTestUser
+		wantCode    int
+	}{
+		{
+			name:        "json",
+			contentType: MIMEApplicationJSON + "; charset=utf-8",
+			body:        `{"name":"john","age":20}`,
+			want:        bindTestUser{Name: "john", Age: 20},
+		},
+		{
+			name:        "json suffix",
+			contentType: "application/vnd.api+json",
+			body:        `{"name":"john"}`,
+			want:        bindTestUser{Name: "john"},
+		},
+		{
+			name:        "xml",
+			contentType: MIMEApplicationXML,
+			body:        `<user><name>john</name><age>20</age></user>`,
+			want:        bindTestUser{Name: "john", Age: 20},
+		},
+		{
+			name:        "form",
+			contentType: MIMEApplicationForm,
+			body:        "name=john&age=20",
+			want:        bindTestUser{Name: "john", Age: 20},
+		},
+		{
+			name:        "malformed json",
+			contentType: MIMEApplicationJSON,
+			body:        `{"name":`,
+			wantCode:    http.StatusBadRequest,
+		},
+		{
+			name:        "empty body",
+			contentType: MIMEApplicationJSON,
+			wantCode:    http.StatusBadRequest,
+		},
+		{
+			name:        "unsupported content type",
+			contentType: MIMETextPlain,
+			body:        "john",
+			wantCode:    http.StatusUnsupportedMediaType,
+		},
+		{
+			name:     "missing content type",
+			body:     `{"name":"john"}`,
+			wantCode: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.body == "" {
+				r.Body = http.NoBody
+			}
+			if tt.contentType != "" {
+				r.Header.Set(HeaderContentType, tt.contentType)
+			}
+
+			var got bindTestUser
+			err := Bind(r, &got)
+
+			if tt.wantCode != 0 {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantCode, HTTPErrorStatusCode(err))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBindForm(t *testing.T) {
+	type address struct {
+		City string `form:"city"`
+		Zip  *int   `form:"zip"`
+	}
+	type signUp struct {
+		Name     string    `form:"name"`
+		Born     time.Time `form:"born" layout:"2006-01-02"`
+		Tags     []string  `form:"tags"`
+		Address  address   `form:"address"`
+		Billing  *address  `form:"billing"`
+		Shipping *address  `form:"shipping"`
+	}
+
+	t.Run("urlencoded", func(t *testing.T) {
+		body := "name=john&born=2000-01-02&tags[]=a&tags[]=b&address.city=Berlin&billing.zip=10115"
+		r := httptest.NewRequest(http.MethodPost, "/?tags=c", strings.NewReader(body))
+		r.Header.Set(HeaderContentType, MIMEApplicationForm)
+
+		var got signUp
+		require.NoError(t, BindForm(r, &got))
+
+		zip := 10115
+		assert.Equal(t, signUp{
+			Name:    "john",
+			Born:    time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC),
+			Tags:    []string{"c", "a", "b"},
+			Address: address{City: "Berlin"},
+			Billing: &address{Zip: &zip},
+		}, got)
+	})
+
+	t.Run("multipart", func(t *testing.T) {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		require.NoError(t, mw.WriteField("name", "john"))
+		require.NoError(t, mw.WriteField("tags", "a"))
+		require.NoError(t, mw.WriteField("tags", "b"))
+		require.NoError(t, mw.Close())
+
+		r := httptest.NewRequest(http.MethodPost, "/", &buf)
+		r.Header.Set(HeaderContentType, mw.FormDataContentType())
+
+		var got signUp
+		require.NoError(t, Bind(r, &got))
+		assert.Equal(t, signUp{Name: "john", Tags: []string{"a", "b"}}, got)
+	})
+
+	t.Run("field errors", func(t *testing.T) {
+		body := "born=yesterday&billing.zip=abc"
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.Header.Set(HeaderContentType, MIMEApplicationForm)
+
+		err := BindForm(r, &signUp{})
+		httpErr, ok := errors.AsType[*HTTPError](err)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+
+		fields, ok := httpErr.Data.(ValidationErrors)
+		require.True(t, ok)
+		require.Len(t, fields, 2)
+		assert.Equal(t, "born", fields[0].Field)
+		assert.Equal(t, "billing.zip", fields[1].Field)
+	})
+}
+
+func TestValidate_Validatable(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+
+	assert.NoError(t, Validate(r, bindTestSelfValidated{Name: "john"}))
+	assert.NoError(t, Validate(r, &bindTestUser{}))
+
+	err := Validate(r, bindTestSelfValidated{})
+	httpErr, ok := errors.AsType[*HTTPError](err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+	assert.Equal(t, ValidationErrors{{Field: "name", Message: "is required"}}, httpErr.Data)
+
+	var fields ValidationErrors
+	assert.ErrorAs(t, err, &fields)
+}
+
+func TestValidate_PlainError(t *testing.T) {
+	router := NewRouter(WithValidator(ValidatorFunc(func(any) error {
+		return errors.New("invalid payload")
+	})))
+
+	var err error
+	router.POST("/", func(w http.ResponseWriter, r *http.Request) error {
+		err = Validate(r, &bindTestUser{})
+		return nil
+	})
+	router.Build().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil))
+
+	httpErr, ok := errors.AsType[*HTTPError](err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusUnprocessableEntity, httpErr.Code)
+	assert.Equal(t, "invalid payload", httpErr.Message)
+	assert.Nil(t, httpErr.Data)
+}
+
+func TestBindAndValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "valid",
+			body:     `{"name":"john","age":20}`,
+			wantCode: http.StatusOK,
+			wantBody: "john",
+		},
+		{
+			name:     "invalid",
+			body:     `{"age":10}`,
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: `{"code":422,"message":"Unprocessable Entity","data":[{"field":"name","message":"is required"},{"field":"age","message":"must be at least 18"}]}`,
+		},
+		{
+			name:     "malformed",
+			body:     `{`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"code":400,"message":"Bad Request"}`,
+		},
+	}
+
+	router := NewRouter(WithValidator(ValidatorFunc(bindTestValidator)))
+	router.POST("/", func(w http.ResponseWriter, r *http.Request) error {
+		var u bindTestUser
+		if err := BindAndValidate(r, &u); err != nil {
+			return err
+		}
+		return TextPlain(w, http.StatusOK, u.Name)
+	})
+	handler := router.Build()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			r.Header.Set(HeaderContentType, MIMEApplicationJSON)
+			r.Header.Set(HeaderAccept, MIMEApplicationJSON)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			} else {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestWithValidator(t *testing.T) {
+	v := ValidatorFunc(func(any) error { return nil })
+
+	router := NewRouter(WithValidator(v))
+	assert.NotNil(t, router.validator)
+
+	router = NewRouter(WithValidator(nil))
+	assert.Nil(t, router.validator)
+}
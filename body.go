@@ -0,0 +1,76 @@
+package keratin
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// bufferedBody is a request body kept in memory by [BufferBody].
+type bufferedBody struct {
+	*bytes.Reader
+	data []byte
+}
+
+func (b *bufferedBody) Close() error {
+	return nil
+}
+
+// BufferBody reads the request body into memory and replaces it with a rewound copy, so that
+// the middlewares can read the body (e.g. to parse a form or compute a digest) and the next handlers
+// still get all of it. A limit <= 0 means no limit.
+//
+// A body already buffered is rewound and returned without being read again.
+// A body larger than the limit fails with 413 Payload Too Large, the body is left readable from its start.
+func BufferBody(r *http.Request, limit int64) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if b, ok := r.Body.(*bufferedBody); ok {
+		_, _ = b.Seek(0, io.SeekStart)
+		return b.data, nil
+	}
+
+	reader := io.Reader(r.Body)
+	if limit > 0 {
+		reader = io.LimitReader(r.Body, limit+1)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil || limit > 0 && int64(len(data)) > limit {
+		// put back what was read, so the body is not lost
+		r.Body = &prependedBody{Reader: io.MultiReader(bytes.NewReader(data), r.Body), Closer: r.Body}
+		if err == nil {
+			err = ErrRequestEntityTooLarge
+		}
+		return nil, err
+	}
+
+	_ = r.Body.Close()
+	r.Body = &bufferedBody{Reader: bytes.NewReader(data), data: data}
+
+	return data, nil
+}
+
+// RewindBody rewinds the request body buffered by [BufferBody] and reports whether it was buffered.
+func RewindBody(r *http.Request) bool {
+	if b, ok := r.Body.(*bufferedBody); ok {
+		_, _ = b.Seek(0, io.SeekStart)
+		return true
+	}
+	return false
+}
+
+// BufferedBody returns the request body buffered by [BufferBody] without rewinding it.
+func BufferedBody(r *http.Request) ([]byte, bool) {
+	if b, ok := r.Body.(*bufferedBody); ok {
+		return b.data, true
+	}
+	return nil, false
+}
+
+type prependedBody struct {
+	io.Reader
+	io.Closer
+}
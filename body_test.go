@@ -0,0 +1,90 @@
+package keratin
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBufferBody(t *testing.T) {
+	t.Run("reread", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("a=1&b=2"))
+		r.Header.Set(HeaderContentType, MIMEApplicationForm)
+
+		data, err := BufferBody(r, 1024)
+		require.NoError(t, err)
+		assert.Equal(t, "a=1&b=2", string(data))
+
+		// parsing the form consumes the body
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "1", r.PostForm.Get("a"))
+
+		assert.True(t, RewindBody(r))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "a=1&b=2", string(body))
+
+		// buffering again rewinds without reading
+		data, err = BufferBody(r, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "a=1&b=2", string(data))
+
+		buffered, ok := BufferedBody(r)
+		assert.True(t, ok)
+		assert.Equal(t, "a=1&b=2", string(buffered))
+	})
+
+	t.Run("no limit", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("x", 4096)))
+
+		data, err := BufferBody(r, 0)
+		require.NoError(t, err)
+		assert.Len(t, data, 4096)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("0123456789"))
+
+		_, err := BufferBody(r, 4)
+		require.Error(t, err)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, HTTPErrorStatusCode(err))
+		assert.False(t, RewindBody(r))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "0123456789", string(body), "the body is not lost")
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/", io.MultiReader(strings.NewReader("ab"), iotestErrReader{}))
+
+		_, err := BufferBody(r, 0)
+		assert.ErrorIs(t, err, errRead)
+	})
+
+	t.Run("no body", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		data, err := BufferBody(r, 0)
+		require.NoError(t, err)
+		assert.Nil(t, data)
+
+		_, ok := BufferedBody(r)
+		assert.False(t, ok)
+	})
+}
+
+var errRead = errors.New("read error")
+
+type iotestErrReader struct{}
+
+func (iotestErrReader) Read([]byte) (int, error) {
+	return 0, errRead
+}
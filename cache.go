@@ -0,0 +1,88 @@
+package keratin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheOptions describes the HTTP caching directives of a response, see [SetCacheControl].
+// The zero durations are omitted, the durations are rounded down to seconds.
+type CacheOptions struct {
+	Public          bool `json:"public,omitempty" yaml:"public,omitempty"`
+	Private         bool `json:"private,omitempty" yaml:"private,omitempty"`
+	NoCache         bool `json:"noCache,omitempty" yaml:"noCache,omitempty"`
+	NoStore         bool `json:"noStore,omitempty" yaml:"noStore,omitempty"`
+	NoTransform     bool `json:"noTransform,omitempty" yaml:"noTransform,omitempty"`
+	MustRevalidate  bool `json:"mustRevalidate,omitempty" yaml:"mustRevalidate,omitempty"`
+	ProxyRevalidate bool `json:"proxyRevalidate,omitempty" yaml:"proxyRevalidate,omitempty"`
+	Immutable       bool `json:"immutable,omitempty" yaml:"immutable,omitempty"`
+
+	// MaxAge is the max-age directive.
+	MaxAge time.Duration `json:"maxAge,omitempty,format:units" yaml:"maxAge,omitempty"`
+	// SharedMaxAge is the s-maxage directive, the max-age of the shared caches (proxies and CDNs).
+	SharedMaxAge         time.Duration `json:"sharedMaxAge,omitempty,format:units" yaml:"sharedMaxAge,omitempty"`
+	StaleWhileRevalidate time.Duration `json:"staleWhileRevalidate,omitempty,format:units" yaml:"staleWhileRevalidate,omitempty"`
+	StaleIfError         time.Duration `json:"staleIfError,omitempty,format:units" yaml:"staleIfError,omitempty"`
+
+	// SurrogateMaxAge sets the Surrogate-Control max-age, honored (and stripped) by the CDNs only.
+	SurrogateMaxAge time.Duration `json:"surrogateMaxAge,omitempty,format:units" yaml:"surrogateMaxAge,omitempty"`
+
+	// Expires also sets the Expires header for the HTTP/1.0 caches: MaxAge from now,
+	// or "0" (already expired) without MaxAge or with NoCache or NoStore.
+	Expires bool `json:"expires,omitempty" yaml:"expires,omitempty"`
+}
+
+// String returns the Cache-Control header value.
+func (o CacheOptions) String() string {
+	directives := make([]string, 0, 8)
+
+	flag := func(set bool, name string) {
+		if set {
+			directives = append(directives, name)
+		}
+	}
+	seconds := func(d time.Duration, name string) {
+		if d > 0 {
+			directives = append(directives, name+"="+strconv.FormatInt(int64(d/time.Second), 10))
+		}
+	}
+
+	flag(o.Public, "public")
+	flag(o.Private, "private")
+	flag(o.NoCache, "no-cache")
+	flag(o.NoStore, "no-store")
+	flag(o.NoTransform, "no-transform")
+	flag(o.MustRevalidate, "must-revalidate")
+	flag(o.ProxyRevalidate, "proxy-revalidate")
+	seconds(o.MaxAge, "max-age")
+	seconds(o.SharedMaxAge, "s-maxage")
+	seconds(o.StaleWhileRevalidate, "stale-while-revalidate")
+	seconds(o.StaleIfError, "stale-if-error")
+	flag(o.Immutable, "immutable")
+
+	return strings.Join(directives, ", ")
+}
+
+// SetCacheControl sets the Cache-Control, Surrogate-Control and Expires headers of the response
+// described by opts, it must be called before the response is written.
+func SetCacheControl(w http.ResponseWriter, opts CacheOptions) {
+	header := w.Header()
+
+	if value := opts.String(); value != "" {
+		header.Set(HeaderCacheControl, value)
+	}
+
+	if opts.SurrogateMaxAge > 0 {
+		header.Set(HeaderSurrogateControl, "max-age="+strconv.FormatInt(int64(opts.SurrogateMaxAge/time.Second), 10))
+	}
+
+	if opts.Expires {
+		if opts.MaxAge > 0 && !opts.NoCache && !opts.NoStore {
+			header.Set(HeaderExpires, time.Now().Add(opts.MaxAge).UTC().Format(http.TimeFormat))
+		} else {
+			header.Set(HeaderExpires, "0")
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package keratin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheOptions_String(t *testing.T) {
+	tests := []struct {
+		name string
+		opts CacheOptions
+		want string
+	}{
+		{name: "empty", opts: CacheOptions{}, want: ""},
+		{name: "no store", opts: CacheOptions{NoStore: true}, want: "no-store"},
+		{
+			name: "public",
+			opts: CacheOptions{Public: true, MaxAge: time.Minute, SharedMaxAge: time.Hour, StaleWhileRevalidate: 30 * time.Second, StaleIfError: 1500 * time.Millisecond},
+			want: "public, max-age=60, s-maxage=3600, stale-while-revalidate=30, stale-if-error=1",
+		},
+		{
+			name: "immutable",
+			opts: CacheOptions{Public: true, MaxAge: 365 * 24 * time.Hour, Immutable: true},
+			want: "public, max-age=31536000, immutable",
+		},
+		{
+			name: "private",
+			opts: CacheOptions{Private: true, NoCache: true, NoTransform: true, MustRevalidate: true, ProxyRevalidate: true},
+			want: "private, no-cache, no-transform, must-revalidate, proxy-revalidate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.opts.String())
+		})
+	}
+}
+
+func TestSetCacheControl(t *testing.T) {
+	rec := httptest.NewRecorder()
+	SetCacheControl(rec, CacheOptions{Public: true, MaxAge: time.Hour, SurrogateMaxAge: 24 * time.Hour, Expires: true})
+
+	assert.Equal(t, "public, max-age=3600", rec.Header().Get(HeaderCacheControl))
+	assert.Equal(t, "max-age=86400", rec.Header().Get(HeaderSurrogateControl))
+
+	expires, err := http.ParseTime(rec.Header().Get(HeaderExpires))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expires, 2*time.Second)
+
+	rec = httptest.NewRecorder()
+	SetCacheControl(rec, CacheOptions{NoStore: true, MaxAge: time.Hour, Expires: true})
+
+	assert.Equal(t, "no-store, max-age=3600", rec.Header().Get(HeaderCacheControl))
+	assert.Equal(t, "0", rec.Header().Get(HeaderExpires))
+	assert.Empty(t, rec.Header().Get(HeaderSurrogateControl))
+
+	rec = httptest.NewRecorder()
+	SetCacheControl(rec, CacheOptions{})
+
+	assert.Empty(t, rec.Header())
+}
@@ -0,0 +1,284 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gowool/keratin"
+	"github.com/gowool/keratin/internal"
+	"github.com/gowool/keratin/middleware"
+)
+
+// maxErrorBody limits the bytes of a non-2xx response body read for the error message.
+const maxErrorBody = 64 << 10
+
+// Client is a thin wrapper over [http.Client] for the outbound calls of the services built on keratin.
+//
+// Every request attempt is bounded by Config.Timeout. The requests made with the context of a request
+// handled by the [keratin.Router] carry its request ID (X-Request-Id), the Config.PropagateHeaders
+// and the headers captured by [middleware.PropagateHeaders], and the attempts carry the remaining
+// deadline budget set by [middleware.Budget].
+// The idempotent requests (GET, HEAD, OPTIONS, TRACE, PUT, DELETE or with the Idempotency-Key header)
+// are retried on the network errors, 408, 425, 429 and 5xx responses with an exponential backoff,
+// honouring the Retry-After header, until Config.MaxAttempts is reached.
+//
+// The non-2xx responses are returned as *[keratin.HTTPError] (wrapped in a [keratin.RetryableError]
+// when the response has the Retry-After header) decoded from the keratin JSON, problem details or text body.
+type Client struct {
+	cfg   Config
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func New(cfg Config) *Client {
+	cfg.SetDefaults()
+
+	return &Client{
+		cfg:   cfg,
+		now:   time.Now,
+		sleep: internal.Sleep,
+	}
+}
+
+// Get issues a GET request to the url.
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(r)
+}
+
+// Post issues a POST request to the url.
+func (c *Client) Post(ctx context.Context, url, contentType string, body io.Reader) (*http.Response, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set(keratin.HeaderContentType, contentType)
+	return c.Do(r)
+}
+
+// JSON sends in (if not nil) JSON encoded and decodes the response body into out (if not nil).
+func (c *Client) JSON(ctx context.Context, method, url string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("client: encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	r.Header.Set(keratin.HeaderAccept, keratin.MIMEApplicationJSON)
+	if in != nil {
+		r.Header.Set(keratin.HeaderContentType, keratin.MIMEApplicationJSON)
+	}
+
+	res, err := c.Do(r)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if out == nil {
+		return nil
+	}
+	if err = json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("client: decode response: %w", err)
+	}
+	return nil
+}
+
+// Do sends the request, see [Client] for the retries and the error mapping.
+// On success the caller must close the response body, the attempt timeout applies until then.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	header := c.header(ctx, req.Header)
+	retry := idempotent(req) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+
+	for attempt := 1; ; attempt++ {
+		res, status, err := c.attempt(req, header, attempt)
+		if err == nil {
+			return res, nil
+		}
+
+		if !retry || attempt >= c.cfg.MaxAttempts || ctx.Err() != nil || !internal.Retryable(status) {
+			return nil, err
+		}
+
+		if err = c.sleep(ctx, max(min(keratin.ErrorRetryAfter(err), c.cfg.MaxBackoff), c.backoff(attempt))); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt sends the request once and returns the response status code (0 for the network errors).
+func (c *Client) attempt(req *http.Request, header http.Header, attempt int) (*http.Response, int, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), c.cfg.Timeout)
+
+	r := req.Clone(ctx)
+	r.Header = header.Clone()
+	// the remaining budget of every attempt, bounded by the attempt timeout
+	middleware.InjectBudget(ctx, r.Header)
+	if attempt > 1 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, 0, err
+		}
+		r.Body = body
+	}
+
+	res, err := c.cfg.Client.Do(r)
+	if err != nil {
+		cancel()
+		return nil, 0, err
+	}
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+		return res, res.StatusCode, nil
+	}
+
+	defer cancel()
+	return nil, res.StatusCode, c.decodeError(res)
+}
+
+// header returns the request header with the User-Agent and the headers propagated from ctx.
+func (c *Client) header(ctx context.Context, h http.Header) http.Header {
+	h = h.Clone()
+	if h == nil {
+		h = make(http.Header)
+	}
+	if h.Get("User-Agent") == "" {
+		h.Set("User-Agent", c.cfg.UserAgent)
+	}
+
+	incoming := keratin.RequestHeader(ctx)
+
+	if h.Get(keratin.HeaderXRequestID) == "" {
+		id := keratin.FromContext(ctx).RequestID()
+		if id == "" {
+			id = incoming.Get(keratin.HeaderXRequestID)
+		}
+		if id != "" {
+			h.Set(keratin.HeaderXRequestID, id)
+		}
+	}
+
+	for _, name := range c.cfg.PropagateHeaders {
+		if values := incoming.Values(name); len(values) > 0 && h.Get(name) == "" {
+			h[http.CanonicalHeaderKey(name)] = slices.Clone(values)
+		}
+	}
+	middleware.InjectPropagatedHeaders(ctx, h)
+	return h
+}
+
+// decodeError reads and closes the non-2xx response body and returns it as an error.
+func (c *Client) decodeError(res *http.Response) error {
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxErrorBody))
+		_ = res.Body.Close()
+	}()
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+	httpErr := &keratin.HTTPError{Code: res.StatusCode}
+
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get(keratin.HeaderContentType))
+	switch mediaType {
+	case keratin.MIMEApplicationJSON:
+		var decoded keratin.HTTPError
+		if json.Unmarshal(body, &decoded) == nil {
+			httpErr.Message = decoded.Message
+			httpErr.ErrorCode = decoded.ErrorCode
+			httpErr.Details = decoded.Details
+			httpErr.Data = decoded.Data
+		}
+	case keratin.MIMEApplicationProblemJSON:
+		var problem struct {
+			Title     string `json:"title"`
+			Detail    string `json:"detail"`
+			ErrorCode string `json:"error_code"`
+			Details   any    `json:"details"`
+		}
+		if json.Unmarshal(body, &problem) == nil {
+			httpErr.Message = problem.Detail
+			if httpErr.Message == "" {
+				httpErr.Message = problem.Title
+			}
+			httpErr.ErrorCode = problem.ErrorCode
+			httpErr.Details = problem.Details
+		}
+	case keratin.MIMETextPlain:
+		httpErr.Message = strings.TrimSpace(string(body))
+	}
+	if httpErr.Message == "" {
+		httpErr.Message = http.StatusText(res.StatusCode)
+	}
+
+	if after, ok := c.retryAfter(res.Header.Get(keratin.HeaderRetryAfter)); ok {
+		return keratin.NewRetryableError(res.StatusCode, after).Wrap(httpErr)
+	}
+	return httpErr
+}
+
+// backoff returns the delay after the given attempt.
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.cfg.InitialBackoff
+	for i := 1; i < attempt && delay < c.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, c.cfg.MaxBackoff)
+}
+
+func (c *Client) retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(c.now()), 0), true
+	}
+	return 0, false
+}
+
+// idempotent reports whether the request may be sent more than once.
+func idempotent(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return r.Header.Get(middleware.HeaderIdempotencyKey) != ""
+}
+
+// cancelBody cancels the attempt context when the response body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
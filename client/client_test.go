@@ -0,0 +1,352 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+	"github.com/gowool/keratin/middleware"
+)
+
+func newTestClient(cfg Config) (*Client, *[]time.Duration) {
+	var sleeps []time.Duration
+
+	c := New(cfg)
+	c.sleep = func(_ context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	return c, &sleeps
+}
+
+func TestConfig_SetDefaults(t *testing.T) {
+	var cfg Config
+	cfg.SetDefaults()
+
+	assert.NotNil(t, cfg.Client)
+	assert.Equal(t, 30*time.Second, cfg.Timeout)
+	assert.Equal(t, 3, cfg.MaxAttempts)
+	assert.Equal(t, 100*time.Millisecond, cfg.InitialBackoff)
+	assert.Equal(t, 2*time.Second, cfg.MaxBackoff)
+	assert.Equal(t, "keratin-client", cfg.UserAgent)
+	assert.Equal(t, []string{keratin.HeaderTraceparent, keratin.HeaderTracestate}, cfg.PropagateHeaders)
+}
+
+func TestClient_Propagation(t *testing.T) {
+	var received http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer upstream.Close()
+
+	c, _ := newTestClient(Config{})
+
+	router := keratin.NewRouter()
+	router.UseFunc(middleware.RequestID(middleware.RequestIDConfig{}))
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		res, err := c.Get(r.Context(), upstream.URL)
+		if err != nil {
+			return err
+		}
+		return res.Body.Close()
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(keratin.HeaderXRequestID, "req-1")
+	req.Header.Set(keratin.HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set(keratin.HeaderTracestate, "vendor=value")
+	req.Header.Set("X-Other", "other")
+	rec := httptest.NewRecorder()
+	router.Build().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "req-1", received.Get(keratin.HeaderXRequestID))
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", received.Get(keratin.HeaderTraceparent))
+	assert.Equal(t, "vendor=value", received.Get(keratin.HeaderTracestate))
+	assert.Empty(t, received.Get("X-Other"))
+	assert.Equal(t, "keratin-client", received.Get("User-Agent"))
+}
+
+func TestClient_PropagateHeadersMiddleware(t *testing.T) {
+	var received http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer upstream.Close()
+
+	c, _ := newTestClient(Config{})
+
+	router := keratin.NewRouter()
+	router.UseFunc(middleware.PropagateHeaders("X-B3-*", "X-Tenant"))
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		res, err := c.Get(r.Context(), upstream.URL)
+		if err != nil {
+			return err
+		}
+		return res.Body.Close()
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-B3-TraceId", "trace")
+	req.Header.Set("X-B3-SpanId", "span")
+	req.Header.Set("X-Tenant", "acme")
+	rec := httptest.NewRecorder()
+	router.Build().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "trace", received.Get("X-B3-TraceId"))
+	assert.Equal(t, "span", received.Get("X-B3-SpanId"))
+	assert.Equal(t, "acme", received.Get("X-Tenant"))
+}
+
+func TestClient_Budget(t *testing.T) {
+	var received http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer upstream.Close()
+
+	c, _ := newTestClient(Config{Timeout: time.Second})
+
+	router := keratin.NewRouter()
+	router.UseFunc(middleware.Budget("", time.Minute))
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		res, err := c.Get(r.Context(), upstream.URL)
+		if err != nil {
+			return err
+		}
+		return res.Body.Close()
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(middleware.HeaderRequestTimeout, "30s")
+	rec := httptest.NewRecorder()
+	router.Build().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// the attempt timeout is shorter than the budget
+	ms, err := strconv.Atoi(received.Get(middleware.HeaderRequestTimeout))
+	require.NoError(t, err)
+	assert.InDelta(t, 1000, ms, 100)
+}
+
+func TestClient_Do_Retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		header       http.Header
+		statuses     []int
+		wantAttempts int32
+		wantErr      bool
+		wantSleeps   []time.Duration
+	}{
+		{
+			name:         "get retried until success",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			wantAttempts: 3,
+			wantSleeps:   []time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+		},
+		{
+			name:         "get retried until max attempts",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK},
+			wantAttempts: 3,
+			wantErr:      true,
+			wantSleeps:   []time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+		},
+		{
+			name:         "client error not retried",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusNotFound, http.StatusOK},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "post not retried",
+			method:       http.MethodPost,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "post with idempotency key retried",
+			method:       http.MethodPost,
+			header:       http.Header{middleware.HeaderIdempotencyKey: {"key"}},
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantAttempts: 2,
+			wantSleeps:   []time.Duration{100 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := attempts.Add(1)
+				body, _ := io.ReadAll(r.Body)
+				if r.Method == http.MethodPost {
+					assert.Equal(t, "payload", string(body))
+				}
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer upstream.Close()
+
+			c, sleeps := newTestClient(Config{})
+
+			req, err := http.NewRequestWithContext(t.Context(), tt.method, upstream.URL, strings.NewReader("payload"))
+			require.NoError(t, err)
+			for name, values := range tt.header {
+				req.Header[name] = values
+			}
+
+			res, err := c.Do(req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, res)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, res.StatusCode)
+				_ = res.Body.Close()
+			}
+			assert.Equal(t, tt.wantAttempts, attempts.Load())
+			assert.Equal(t, tt.wantSleeps, *sleeps)
+		})
+	}
+}
+
+func TestClient_Do_RetryAfter(t *testing.T) {
+	var attempts atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.Header().Set(keratin.HeaderRetryAfter, "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer upstream.Close()
+
+	c, sleeps := newTestClient(Config{MaxAttempts: 2})
+
+	_, err := c.Get(t.Context(), upstream.URL)
+	require.Error(t, err)
+
+	assert.Equal(t, int32(2), attempts.Load())
+	assert.Equal(t, []time.Duration{time.Second}, *sleeps)
+	assert.ErrorIs(t, err, keratin.ErrTooManyRequests)
+	assert.Equal(t, time.Second, keratin.ErrorRetryAfter(err))
+	assert.Equal(t, http.StatusTooManyRequests, keratin.ErrorStatusCode(err))
+}
+
+func TestClient_Do_Error(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantErr     *keratin.HTTPError
+	}{
+		{
+			name:        "keratin json",
+			contentType: keratin.MIMEApplicationJSON + "; charset=utf-8",
+			body:        `{"code":404,"message":"User not found.","error_code":"user_not_found","details":{"id":"42"}}`,
+			wantErr: &keratin.HTTPError{
+				Code:      http.StatusNotFound,
+				Message:   "User not found.",
+				ErrorCode: "user_not_found",
+				Details:   map[string]any{"id": "42"},
+			},
+		},
+		{
+			name:        "problem details",
+			contentType: keratin.MIMEApplicationProblemJSON,
+			body:        `{"type":"about:blank","title":"Not Found","status":404,"detail":"User not found.","error_code":"user_not_found"}`,
+			wantErr: &keratin.HTTPError{
+				Code:      http.StatusNotFound,
+				Message:   "User not found.",
+				ErrorCode: "user_not_found",
+			},
+		},
+		{
+			name:        "text",
+			contentType: keratin.MIMETextPlainCharsetUTF8,
+			body:        "User not found.\n",
+			wantErr:     &keratin.HTTPError{Code: http.StatusNotFound, Message: "User not found."},
+		},
+		{
+			name:        "unknown body",
+			contentType: keratin.MIMETextHTMLCharsetUTF8,
+			body:        "<h1>Not Found</h1>",
+			wantErr:     &keratin.HTTPError{Code: http.StatusNotFound, Message: "Not Found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(keratin.HeaderContentType, tt.contentType)
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = io.WriteString(w, tt.body)
+			}))
+			defer upstream.Close()
+
+			c, _ := newTestClient(Config{})
+
+			_, err := c.Get(t.Context(), upstream.URL)
+
+			httpErr, ok := errors.AsType[*keratin.HTTPError](err)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantErr, httpErr)
+		})
+	}
+}
+
+func TestClient_Do_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer upstream.Close()
+	defer close(release)
+
+	c, sleeps := newTestClient(Config{Timeout: 20 * time.Millisecond, MaxAttempts: 2})
+
+	_, err := c.Get(t.Context(), upstream.URL)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Len(t, *sleeps, 1)
+}
+
+func TestClient_JSON(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, keratin.MIMEApplicationJSON, r.Header.Get(keratin.HeaderContentType))
+		assert.Equal(t, keratin.MIMEApplicationJSON, r.Header.Get(keratin.HeaderAccept))
+
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"name":"keratin"}`, string(body))
+
+		_ = keratin.JSON(w, http.StatusOK, map[string]int{"id": 42})
+	}))
+	defer upstream.Close()
+
+	c, _ := newTestClient(Config{})
+
+	var out struct {
+		ID int `json:"id"`
+	}
+	require.NoError(t, c.JSON(t.Context(), http.MethodPut, upstream.URL, map[string]string{"name": "keratin"}, &out))
+	assert.Equal(t, 42, out.ID)
+}
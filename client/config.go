@@ -0,0 +1,70 @@
+package client
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gowool/keratin"
+)
+
+type Config struct {
+	// Client sends the requests. Its Timeout should be left zero, the per-attempt timeout is Config.Timeout.
+	//
+	// Default: &http.Client{}
+	Client *http.Client `json:"-" yaml:"-"`
+
+	// Timeout is the timeout of a single request attempt, including reading the response body.
+	//
+	// Default: 30s
+	Timeout time.Duration `env:"TIMEOUT" json:"timeout,omitempty,format:units" yaml:"timeout,omitempty"`
+
+	// MaxAttempts is the maximum number of attempts of the idempotent requests, including the first one.
+	//
+	// Default: 3
+	MaxAttempts int `env:"MAX_ATTEMPTS" json:"maxAttempts,omitempty" yaml:"maxAttempts,omitempty"`
+
+	// InitialBackoff is the delay before the first retry, doubled for every next one.
+	//
+	// Default: 100ms
+	InitialBackoff time.Duration `env:"INITIAL_BACKOFF" json:"initialBackoff,omitempty,format:units" yaml:"initialBackoff,omitempty"`
+
+	// MaxBackoff caps the delay between the retries, including the one requested by the Retry-After header.
+	//
+	// Default: 2s
+	MaxBackoff time.Duration `env:"MAX_BACKOFF" json:"maxBackoff,omitempty,format:units" yaml:"maxBackoff,omitempty"`
+
+	// UserAgent is the User-Agent header of the requests without one.
+	//
+	// Default: "keratin-client"
+	UserAgent string `env:"USER_AGENT" json:"userAgent,omitempty" yaml:"userAgent,omitempty"`
+
+	// PropagateHeaders are the incoming request headers copied to the outbound requests made with its context.
+	// The request ID is always propagated in the X-Request-Id header.
+	//
+	// Default: ["Traceparent", "Tracestate"]
+	PropagateHeaders []string `env:"PROPAGATE_HEADERS" json:"propagateHeaders,omitempty" yaml:"propagateHeaders,omitempty"`
+}
+
+func (c *Config) SetDefaults() {
+	if c.Client == nil {
+		c.Client = &http.Client{}
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 30 * time.Second
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 3
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = 100 * time.Millisecond
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 2 * time.Second
+	}
+	if c.UserAgent == "" {
+		c.UserAgent = "keratin-client"
+	}
+	if len(c.PropagateHeaders) == 0 {
+		c.PropagateHeaders = []string{keratin.HeaderTraceparent, keratin.HeaderTracestate}
+	}
+}
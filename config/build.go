@@ -0,0 +1,136 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gowool/keratin"
+)
+
+// NewRouter returns a router with the options, the base path and the routes of the definition.
+// It returns the errors of [Definition.Apply] and [keratin.Router.Validate].
+func (d *Definition) NewRouter(registry *Registry, options ...keratin.Option) (*keratin.Router, error) {
+	if d.BasePath != "" {
+		options = append(options, keratin.WithBasePath(d.BasePath))
+	}
+
+	router := keratin.NewRouter(options...)
+	if err := d.Apply(router, registry); err != nil {
+		return nil, err
+	}
+	if err := router.Validate(); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	return router, nil
+}
+
+// Apply registers the middlewares, the limits and the routes of the definition on the router,
+// the base path is applied by [Definition.NewRouter] only. The definition is resolved against
+// the registry first, so nothing is registered when a handler or a middleware is unknown
+// or has invalid params, all such errors are returned.
+func (d *Definition) Apply(router *keratin.Router, registry *Registry) error {
+	b := &builder{registry: registry}
+
+	root := func() *keratin.RouterGroup { return router.RouterGroup }
+	b.group(root, "", Group{Middlewares: d.Middlewares, Limits: d.Limits, Routes: d.Routes, Groups: d.Groups}, true)
+
+	if len(b.errs) > 0 {
+		return fmt.Errorf("config: %w", errors.Join(b.errs...))
+	}
+
+	for _, op := range b.ops {
+		op()
+	}
+	return nil
+}
+
+// builder resolves a definition into the registration operations, run only if it is valid.
+type builder struct {
+	registry *Registry
+	errs     []error
+	ops      []func()
+}
+
+func (b *builder) errorf(path, format string, args ...any) {
+	b.errs = append(b.errs, fmt.Errorf("%s: "+format, append([]any{path}, args...)...))
+}
+
+// group registers the group g in the parent group, the root group is the parent itself.
+func (b *builder) group(parent func() *keratin.RouterGroup, path string, g Group, root bool) {
+	group := parent
+	if !root {
+		var created *keratin.RouterGroup
+		b.ops = append(b.ops, func() { created = parent().Group(g.Prefix) })
+		group = func() *keratin.RouterGroup { return created }
+	}
+
+	mws, httpMws := b.middlewares(path, g.Middlewares)
+	b.ops = append(b.ops, func() {
+		grp := group()
+		grp.UseHTTPFunc(httpMws...)
+		grp.UseFunc(mws...)
+		if limits := g.Limits.limits(); limits != (keratin.Limits{}) {
+			grp.Limits = limits
+		}
+	})
+
+	for i, route := range g.Routes {
+		b.route(group, fmt.Sprintf("%sroutes[%d]", prefixed(path), i), route)
+	}
+	for i, child := range g.Groups {
+		b.group(group, fmt.Sprintf("%sgroups[%d]", prefixed(path), i), child, false)
+	}
+}
+
+func (b *builder) route(group func() *keratin.RouterGroup, path string, r Route) {
+	if r.Path == "" {
+		b.errorf(path, "missing path")
+	}
+
+	handler, err := b.registry.handler(r.Handler)
+	if err != nil {
+		b.errorf(path, "%w", err)
+	}
+
+	mws, httpMws := b.middlewares(path, r.Middlewares)
+
+	b.ops = append(b.ops, func() {
+		route := group().Route(r.Method, r.Path, handler)
+		route.UseHTTPFunc(httpMws...)
+		route.UseFunc(mws...)
+		route.Limits = r.Limits.limits()
+		for key, value := range r.Meta {
+			route.SetMeta(key, value)
+		}
+		if r.Disabled {
+			route.Disable()
+		}
+	})
+}
+
+func (b *builder) middlewares(path string, declared []Middleware) ([]func(keratin.Handler) keratin.Handler, []func(http.Handler) http.Handler) {
+	var (
+		mws     []func(keratin.Handler) keratin.Handler
+		httpMws []func(http.Handler) http.Handler
+	)
+	for i, m := range declared {
+		mw, httpMw, err := b.registry.middleware(m)
+		switch {
+		case err != nil:
+			b.errorf(fmt.Sprintf("%smiddlewares[%d]", prefixed(path), i), "%w", err)
+		case httpMw != nil:
+			httpMws = append(httpMws, httpMw)
+		default:
+			mws = append(mws, mw)
+		}
+	}
+	return mws, httpMws
+}
+
+func prefixed(path string) string {
+	if path == "" {
+		return ""
+	}
+	return path + "."
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+	"github.com/gowool/keratin/middleware"
+)
+
+func testRegistry() *Registry {
+	ok := func(w http.ResponseWriter, r *http.Request) error {
+		route := keratin.FromContext(r.Context()).Route()
+		w.Header().Set("X-Roles", strings.Join(anyStrings(route.Meta("roles")), ","))
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+	return NewRegistry().
+		HandleFunc("health", ok).
+		HandleFunc("users.get", ok).
+		HandleFunc("users.create", ok)
+}
+
+func anyStrings(v any) []string {
+	values, _ := v.([]any)
+	s := make([]string, 0, len(values))
+	for _, value := range values {
+		s = append(s, value.(string))
+	}
+	return s
+}
+
+func TestDefinition_NewRouter(t *testing.T) {
+	def, err := Parse([]byte(testYAML))
+	require.NoError(t, err)
+
+	var limited []string
+	router, err := def.NewRouter(testRegistry(), keratin.WithRateLimiter(func(pattern string, limit keratin.RateLimit) func(keratin.Handler) keratin.Handler {
+		limited = append(limited, pattern)
+		assert.Equal(t, keratin.RateLimit{Max: 10, Window: time.Minute}, limit)
+		return func(next keratin.Handler) keratin.Handler { return next }
+	}))
+	require.NoError(t, err)
+
+	h := router.Handler()
+
+	t.Run("health", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+		req.Header.Set("Origin", "https://example.com")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "https://example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("group route with meta", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/1", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "admin", rec.Header().Get("X-Roles"))
+	})
+
+	t.Run("root body limit", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/1", strings.NewReader(strings.Repeat("a", 2048))))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+
+	t.Run("disabled route", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/users/", nil))
+
+		assert.NotEqual(t, http.StatusOK, rec.Code)
+	})
+
+	assert.Contains(t, limited, "GET /users/{id}")
+}
+
+func TestDefinition_Apply_Errors(t *testing.T) {
+	def, err := Parse([]byte(`
+middlewares: [unknown]
+routes:
+  - {method: GET, path: /a, handler: missing}
+  - {method: GET, handler: health}
+groups:
+  - prefix: /g
+    routes:
+      - method: GET
+        path: /b
+        handler: health
+        middlewares:
+          - name: body_limit
+            params: {limit: abc}
+`))
+	require.NoError(t, err)
+
+	router := keratin.NewRouter()
+	err = def.Apply(router, testRegistry())
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `middlewares[0]: unknown middleware "unknown"`)
+	assert.ErrorContains(t, err, `routes[0]: unknown handler "missing"`)
+	assert.ErrorContains(t, err, `routes[1]: missing path`)
+	assert.ErrorContains(t, err, `groups[0].routes[0].middlewares[0]: middleware "body_limit"`)
+
+	assert.Empty(t, slices.Collect(router.Patterns()))
+}
+
+func TestDefinition_NewRouter_ValidateError(t *testing.T) {
+	def, err := Parse([]byte(testYAML))
+	require.NoError(t, err)
+
+	// the rate limited routes require a rate limiter
+	_, err = def.NewRouter(testRegistry())
+	require.Error(t, err)
+}
+
+func TestDefinition_RequireRolesMeta(t *testing.T) {
+	def, err := Parse([]byte(`
+middlewares: [authenticate]
+groups:
+  - prefix: /admin
+    middlewares: [require_roles]
+    routes:
+      - {method: GET, path: /users, handler: health, meta: {roles: [admin]}}
+      - {method: GET, path: /open, handler: health}
+`))
+	require.NoError(t, err)
+
+	registry := testRegistry().Middleware("authenticate", func(Params) (func(keratin.Handler) keratin.Handler, error) {
+		return func(next keratin.Handler) keratin.Handler {
+			return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				principal := &middleware.Identity{ID: "john", Roles: strings.Split(r.Header.Get("X-User-Roles"), ",")}
+				return next.ServeHTTP(w, r.WithContext(middleware.WithPrincipal(r.Context(), principal)))
+			})
+		}, nil
+	})
+
+	router, err := def.NewRouter(registry)
+	require.NoError(t, err)
+	h := router.Handler()
+
+	tests := []struct {
+		name     string
+		target   string
+		roles    string
+		wantCode int
+	}{
+		{name: "route role granted", target: "/admin/users", roles: "user,admin", wantCode: http.StatusOK},
+		{name: "route role missing", target: "/admin/users", roles: "user", wantCode: http.StatusForbidden},
+		{name: "route without roles", target: "/admin/open", roles: "user,admin", wantCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set("X-User-Roles", tt.roles)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Package config builds a [keratin.Router] from a YAML or JSON definition of its routes and middlewares,
+// so that the routing is managed by the operations without recompiling the application.
+// The handlers and the middlewares are referenced by their names in a [Registry]:
+//
+//	basePath: /api
+//	middlewares:
+//	  - recover
+//	  - name: cors
+//	    params:
+//	      allowOrigins: [https://example.com]
+//	groups:
+//	  - prefix: /users
+//	    limits:
+//	      timeout: 5s
+//	      rateLimit: {max: 100, window: 1m}
+//	    routes:
+//	      - method: GET
+//	        path: /{id}
+//	        handler: users.get
+//	      - method: POST
+//	        path: /
+//	        handler: users.create
+//	        middlewares:
+//	          - name: body_limit
+//	            params:
+//	              limit: 1048576
+//
+// The application registers the handlers and builds the router:
+//
+//	registry := config.NewRegistry().
+//		HandleFunc("users.get", getUser).
+//		HandleFunc("users.create", createUser)
+//
+//	def, err := config.Load("routes.yaml")
+//	...
+//	router, err := def.NewRouter(registry)
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gowool/keratin"
+)
+
+// Definition declares the routes and the middlewares of a router.
+type Definition struct {
+	// BasePath is the path prefix of all the routes, see [keratin.WithBasePath].
+	BasePath string `json:"basePath,omitempty" yaml:"basePath,omitempty"`
+
+	// Middlewares run for all the routes, in the declaration order.
+	Middlewares []Middleware `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+
+	// Limits are the default limits of all the routes.
+	Limits Limits `json:"limits,omitzero" yaml:"limits,omitempty"`
+
+	Routes []Route `json:"routes,omitempty" yaml:"routes,omitempty"`
+	Groups []Group `json:"groups,omitempty" yaml:"groups,omitempty"`
+}
+
+// Group declares a group of routes sharing a path prefix, middlewares and limits.
+type Group struct {
+	Prefix      string       `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	Middlewares []Middleware `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+	Limits      Limits       `json:"limits,omitzero" yaml:"limits,omitempty"`
+	Routes      []Route      `json:"routes,omitempty" yaml:"routes,omitempty"`
+	Groups      []Group      `json:"groups,omitempty" yaml:"groups,omitempty"`
+}
+
+// Route declares a route served by the registered handler of the name Handler.
+type Route struct {
+	// Method of the route, empty matches any method.
+	Method  string `json:"method,omitempty" yaml:"method,omitempty"`
+	Path    string `json:"path" yaml:"path"`
+	Handler string `json:"handler" yaml:"handler"`
+
+	Middlewares []Middleware `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+	Limits      Limits       `json:"limits,omitzero" yaml:"limits,omitempty"`
+
+	// Meta are the route annotations, see [keratin.Route.SetMeta].
+	Meta map[string]any `json:"meta,omitempty" yaml:"meta,omitempty"`
+
+	// Disabled registers the route disabled, see [keratin.Route.Disable].
+	Disabled bool `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+}
+
+// Limits declare the [keratin.Limits] of a route or a group.
+type Limits struct {
+	Timeout   time.Duration `json:"timeout,omitempty,format:units" yaml:"timeout,omitempty"`
+	MaxBody   int64         `json:"maxBody,omitempty" yaml:"maxBody,omitempty"`
+	RateLimit *RateLimit    `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
+}
+
+// RateLimit declares the [keratin.RateLimit] of a route or a group, the router must have
+// a rate limiter, see [keratin.WithRateLimiter].
+type RateLimit struct {
+	Max    uint          `json:"max" yaml:"max"`
+	Window time.Duration `json:"window,format:units" yaml:"window"`
+}
+
+// Middleware declares a registered middleware with its parameters. It is declared
+// either as an object with the name and the params, or as the name alone.
+type Middleware struct {
+	Name   string `json:"name" yaml:"name"`
+	Params Params `json:"params,omitzero" yaml:"params,omitempty"`
+}
+
+func (m *Middleware) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&m.Name)
+	}
+
+	type plain Middleware
+	return node.Decode((*plain)(m))
+}
+
+// Params are the parameters of a middleware, decoded by its factory into its configuration.
+type Params struct {
+	node *yaml.Node
+}
+
+func (p *Params) UnmarshalYAML(node *yaml.Node) error {
+	p.node = node
+	return nil
+}
+
+// IsZero reports whether the middleware is declared without parameters.
+func (p Params) IsZero() bool {
+	return p.node == nil
+}
+
+// Decode decodes the parameters into v, e.g. a *middleware.CORSConfig, according to its yaml tags,
+// it rejects the unknown fields like [Parse]. The durations are decoded from the strings like "5s".
+// Decode keeps v as is without parameters.
+func (p Params) Decode(v any) error {
+	if p.node == nil {
+		return nil
+	}
+
+	// yaml.Node.Decode cannot reject the unknown fields
+	data, err := yaml.Marshal(p.node)
+	if err != nil {
+		return err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	return dec.Decode(v)
+}
+
+// Load reads the definition from the YAML or JSON file.
+func Load(path string) (*Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses the YAML or JSON definition, it rejects the unknown fields.
+func Parse(data []byte) (*Definition, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var def Definition
+	if err := dec.Decode(&def); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("config: parse definition: %w", err)
+	}
+	return &def, nil
+}
+
+func (l Limits) limits() keratin.Limits {
+	limits := keratin.Limits{Timeout: l.Timeout, MaxBody: l.MaxBody}
+	if l.RateLimit != nil {
+		limits.RateLimit = keratin.RateLimit{Max: l.RateLimit.Max, Window: l.RateLimit.Window}
+	}
+	return limits
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testYAML = `
+basePath: /api
+middlewares:
+  - recover
+  - name: cors
+    params:
+      allowOrigins: [https://example.com]
+limits:
+  maxBody: 1024
+routes:
+  - method: GET
+    path: /health
+    handler: health
+groups:
+  - prefix: /users
+    limits:
+      timeout: 5s
+      rateLimit: {max: 10, window: 1m}
+    routes:
+      - method: GET
+        path: /{id}
+        handler: users.get
+        meta:
+          roles: [admin]
+      - method: POST
+        path: /
+        handler: users.create
+        disabled: true
+        middlewares:
+          - name: body_limit
+            params:
+              limit: 512
+`
+
+func TestParse(t *testing.T) {
+	def, err := Parse([]byte(testYAML))
+	require.NoError(t, err)
+
+	assert.Equal(t, "/api", def.BasePath)
+	require.Len(t, def.Middlewares, 2)
+	assert.Equal(t, "recover", def.Middlewares[0].Name)
+	assert.True(t, def.Middlewares[0].Params.IsZero())
+	assert.Equal(t, "cors", def.Middlewares[1].Name)
+	assert.Equal(t, int64(1024), def.Limits.MaxBody)
+
+	require.Len(t, def.Routes, 1)
+	assert.Equal(t, Route{Method: "GET", Path: "/health", Handler: "health"}, def.Routes[0])
+
+	require.Len(t, def.Groups, 1)
+	group := def.Groups[0]
+	assert.Equal(t, "/users", group.Prefix)
+	assert.Equal(t, 5*time.Second, group.Limits.Timeout)
+	assert.Equal(t, &RateLimit{Max: 10, Window: time.Minute}, group.Limits.RateLimit)
+	require.Len(t, group.Routes, 2)
+	assert.Equal(t, map[string]any{"roles": []any{"admin"}}, group.Routes[0].Meta)
+	assert.True(t, group.Routes[1].Disabled)
+
+	var params struct {
+		Limit int64 `yaml:"limit"`
+	}
+	require.NoError(t, group.Routes[1].Middlewares[0].Params.Decode(&params))
+	assert.Equal(t, int64(512), params.Limit)
+}
+
+func TestParse_JSON(t *testing.T) {
+	def, err := Parse([]byte(`{
+		"routes": [{"method": "GET", "path": "/{id}", "handler": "get", "limits": {"timeout": "2s"}}],
+		"middlewares": ["recover", {"name": "body_limit", "params": {"limit": 10}}]
+	}`))
+	require.NoError(t, err)
+
+	require.Len(t, def.Routes, 1)
+	assert.Equal(t, "/{id}", def.Routes[0].Path)
+	assert.Equal(t, 2*time.Second, def.Routes[0].Limits.Timeout)
+	require.Len(t, def.Middlewares, 2)
+	assert.Equal(t, "body_limit", def.Middlewares[1].Name)
+	assert.False(t, def.Middlewares[1].Params.IsZero())
+}
+
+func TestParse_Errors(t *testing.T) {
+	_, err := Parse([]byte("routes:\n  - path: /\n    handlr: x\n"))
+	require.Error(t, err)
+
+	_, err = Parse([]byte("routes: {"))
+	require.Error(t, err)
+
+	def, err := Parse(nil)
+	require.NoError(t, err)
+	assert.Equal(t, &Definition{}, def)
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routes.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testYAML), 0o600))
+
+	def, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "/api", def.BasePath)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+}
+
+func TestParams_Decode(t *testing.T) {
+	var p Params
+	v := struct{ A int }{A: 1}
+	require.NoError(t, p.Decode(&v))
+	assert.Equal(t, 1, v.A)
+
+	p = mustParams(t, "{a: 2}")
+	require.NoError(t, p.Decode(&v))
+	assert.Equal(t, 2, v.A)
+
+	p = mustParams(t, "{b: 3}")
+	require.ErrorContains(t, p.Decode(&v), "field b not found")
+}
@@ -0,0 +1,21 @@
+module github.com/gowool/keratin/config
+
+go 1.26
+
+replace github.com/gowool/keratin => ../
+
+require (
+	github.com/gowool/keratin v0.0.0-20260213190635-cab4e888ff73
+	github.com/stretchr/testify v1.11.1
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/expr-lang/expr v1.17.8 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/crypto v0.48.0 // indirect
+	golang.org/x/net v0.49.0 // indirect
+	golang.org/x/text v0.34.0 // indirect
+)
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gowool/keratin"
+	"github.com/gowool/keratin/middleware"
+)
+
+// MiddlewareFactory builds a middleware from its declared parameters.
+type MiddlewareFactory func(params Params) (func(keratin.Handler) keratin.Handler, error)
+
+// HTTPMiddlewareFactory builds a raw [http.Handler] middleware from its declared parameters.
+type HTTPMiddlewareFactory func(params Params) (func(http.Handler) http.Handler, error)
+
+type middlewareFactory struct {
+	handler MiddlewareFactory
+	http    HTTPMiddlewareFactory
+}
+
+// Registry resolves the handler and middleware names of a [Definition].
+// It must not be modified concurrently with [Definition.Apply].
+type Registry struct {
+	handlers    map[string]keratin.Handler
+	middlewares map[string]middlewareFactory
+}
+
+// NewRegistry returns a Registry with the built-in middlewares of the middleware package,
+// their params are decoded into their configurations:
+//
+//   - "body_limit": [middleware.BodyLimitConfig]
+//   - "buffer": [middleware.BufferConfig]
+//   - "cors": [middleware.CORSConfig]
+//   - "digest": [middleware.DigestConfig]
+//   - "ip_filter": [middleware.IPFilterConfig]
+//   - "propagate_headers": {headers: [...]}
+//   - "recover": [middleware.RecoverConfig]
+//   - "reject_ambiguous_requests": no params
+//   - "request_id": [middleware.RequestIDConfig]
+//   - "require_roles": {roles: [...]}, without roles only the route "roles" meta applies
+//   - "secure": [middleware.SecureConfig]
+func NewRegistry() *Registry {
+	r := &Registry{
+		handlers:    make(map[string]keratin.Handler),
+		middlewares: make(map[string]middlewareFactory),
+	}
+
+	r.Middleware("body_limit", configured(middleware.BodyLimit))
+	r.Middleware("buffer", configured(middleware.Buffer))
+	r.Middleware("digest", configured(middleware.Digest))
+	r.Middleware("ip_filter", configured(middleware.IPFilter))
+	r.Middleware("request_id", configured(middleware.RequestID))
+	r.Middleware("secure", configured(middleware.Secure))
+	r.Middleware("recover", func(params Params) (func(keratin.Handler) keratin.Handler, error) {
+		var cfg middleware.RecoverConfig
+		if err := params.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return middleware.Recover(cfg), nil
+	})
+	r.Middleware("reject_ambiguous_requests", func(Params) (func(keratin.Handler) keratin.Handler, error) {
+		return middleware.RejectAmbiguousRequests(), nil
+	})
+	r.Middleware("require_roles", func(params Params) (func(keratin.Handler) keratin.Handler, error) {
+		var cfg struct {
+			Roles []string `yaml:"roles"`
+		}
+		if err := params.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return middleware.RequireRoles(cfg.Roles...), nil
+	})
+	r.Middleware("propagate_headers", func(params Params) (func(keratin.Handler) keratin.Handler, error) {
+		var cfg struct {
+			Headers []string `yaml:"headers"`
+		}
+		if err := params.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return middleware.PropagateHeaders(cfg.Headers...), nil
+	})
+	r.HTTPMiddleware("cors", func(params Params) (func(http.Handler) http.Handler, error) {
+		var cfg middleware.CORSConfig
+		if err := params.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return middleware.CORS(cfg), nil
+	})
+
+	return r
+}
+
+// Handle registers the handler under the name.
+func (r *Registry) Handle(name string, handler keratin.Handler) *Registry {
+	r.handlers[name] = handler
+	return r
+}
+
+// HandleFunc registers the handler function under the name.
+func (r *Registry) HandleFunc(name string, handler func(http.ResponseWriter, *http.Request) error) *Registry {
+	return r.Handle(name, keratin.HandlerFunc(handler))
+}
+
+// Middleware registers the middleware factory under the name, replacing a built-in one.
+func (r *Registry) Middleware(name string, factory MiddlewareFactory) *Registry {
+	r.middlewares[name] = middlewareFactory{handler: factory}
+	return r
+}
+
+// HTTPMiddleware registers the raw [http.Handler] middleware factory under the name, replacing a built-in one.
+func (r *Registry) HTTPMiddleware(name string, factory HTTPMiddlewareFactory) *Registry {
+	r.middlewares[name] = middlewareFactory{http: factory}
+	return r
+}
+
+func (r *Registry) handler(name string) (keratin.Handler, error) {
+	handler, ok := r.handlers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown handler %q", name)
+	}
+	return handler, nil
+}
+
+// middleware builds the declared middleware, exactly one of the returned middlewares is not nil.
+func (r *Registry) middleware(m Middleware) (func(keratin.Handler) keratin.Handler, func(http.Handler) http.Handler, error) {
+	factory, ok := r.middlewares[m.Name]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown middleware %q", m.Name)
+	}
+
+	if factory.http != nil {
+		mw, err := build(m, factory.http)
+		return nil, mw, err
+	}
+	mw, err := build(m, factory.handler)
+	return mw, nil, err
+}
+
+// build calls the factory of the declared middleware, the middleware constructors panic
+// on an invalid configuration, e.g. an unsupported digest algorithm.
+func build[M any](m Middleware, factory func(Params) (M, error)) (mw M, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("middleware %q: %v", m.Name, rec)
+		}
+	}()
+
+	if mw, err = factory(m.Params); err != nil {
+		err = fmt.Errorf("middleware %q: %w", m.Name, err)
+	}
+	return mw, err
+}
+
+// configured adapts a middleware constructor taking a configuration and skippers to a [MiddlewareFactory].
+func configured[C any](constructor func(C, ...middleware.Skipper) func(keratin.Handler) keratin.Handler) MiddlewareFactory {
+	return func(params Params) (func(keratin.Handler) keratin.Handler, error) {
+		var cfg C
+		if err := params.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		return constructor(cfg), nil
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+func mustParams(t *testing.T, src string) Params {
+	t.Helper()
+
+	def, err := Parse([]byte("middlewares:\n  - name: x\n    params: " + src + "\n"))
+	require.NoError(t, err)
+	return def.Middlewares[0].Params
+}
+
+func TestNewRegistry_BuiltinMiddlewares(t *testing.T) {
+	r := NewRegistry()
+
+	for _, name := range []string{
+		"body_limit", "buffer", "digest", "ip_filter", "recover",
+		"reject_ambiguous_requests", "request_id", "secure",
+	} {
+		t.Run(name, func(t *testing.T) {
+			mw, httpMw, err := r.middleware(Middleware{Name: name})
+			require.NoError(t, err)
+			assert.NotNil(t, mw)
+			assert.Nil(t, httpMw)
+		})
+	}
+
+	t.Run("require_roles", func(t *testing.T) {
+		mw, _, err := r.middleware(Middleware{Name: "require_roles", Params: mustParams(t, "{roles: [admin]}")})
+		require.NoError(t, err)
+		assert.NotNil(t, mw)
+	})
+
+	t.Run("propagate_headers", func(t *testing.T) {
+		mw, _, err := r.middleware(Middleware{Name: "propagate_headers", Params: mustParams(t, "{headers: [X-Tenant]}")})
+		require.NoError(t, err)
+		assert.NotNil(t, mw)
+	})
+
+	t.Run("cors", func(t *testing.T) {
+		mw, httpMw, err := r.middleware(Middleware{Name: "cors", Params: mustParams(t, "{allowOrigins: [https://example.com]}")})
+		require.NoError(t, err)
+		assert.Nil(t, mw)
+		assert.NotNil(t, httpMw)
+	})
+}
+
+func TestRegistry_MiddlewareErrors(t *testing.T) {
+	r := NewRegistry().Middleware("failing", func(Params) (func(keratin.Handler) keratin.Handler, error) {
+		return nil, errors.New("boom")
+	})
+
+	_, _, err := r.middleware(Middleware{Name: "unknown"})
+	require.ErrorContains(t, err, `unknown middleware "unknown"`)
+
+	_, _, err = r.middleware(Middleware{Name: "failing"})
+	require.ErrorContains(t, err, `middleware "failing": boom`)
+
+	_, _, err = r.middleware(Middleware{Name: "body_limit", Params: mustParams(t, "{limit: abc}")})
+	require.ErrorContains(t, err, `middleware "body_limit"`)
+
+	_, _, err = r.middleware(Middleware{Name: "digest", Params: mustParams(t, "{algorithms: [crc32]}")})
+	require.ErrorContains(t, err, "unsupported algorithm crc32")
+
+	_, _, err = r.middleware(Middleware{Name: "require_roles", Params: mustParams(t, "{role: [admin]}")})
+	require.ErrorContains(t, err, "field role not found")
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	r := NewRegistry().HandleFunc("ok", func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+
+	h, err := r.handler("ok")
+	require.NoError(t, err)
+	assert.NotNil(t, h)
+
+	_, err = r.handler("missing")
+	require.ErrorContains(t, err, `unknown handler "missing"`)
+}
@@ -9,7 +9,11 @@ const (
 // MIME types
 const (
 	// MIMEApplicationJSON JavaScript Object Notation (JSON) https://www.rfc-editor.org/rfc/rfc8259
-	MIMEApplicationJSON                  = "application/json"
+	MIMEApplicationJSON = "application/json"
+	// MIMEApplicationProblemJSON Problem Details for HTTP APIs https://www.rfc-editor.org/rfc/rfc9457
+	MIMEApplicationProblemJSON = "application/problem+json"
+	// MIMEApplicationNDJSON Newline Delimited JSON https://github.com/ndjson/ndjson-spec
+	MIMEApplicationNDJSON                = "application/x-ndjson"
 	MIMEApplicationJavaScript            = "application/javascript"
 	MIMEApplicationJavaScriptCharsetUTF8 = MIMEApplicationJavaScript + "; " + CharsetUTF8
 	MIMEApplicationXML                   = "application/xml"
@@ -34,6 +38,7 @@ const (
 	HeaderAccept         = "Accept"
 	HeaderAcceptEncoding = "Accept-Encoding"
 	HeaderAcceptLanguage = "Accept-Language"
+	HeaderAcceptRanges   = "Accept-Ranges"
 	// HeaderAllow is the name of the "Allow" header field used to list the set of methods
 	// advertised as supported by the target resource. Returning an Allow header is mandatory
 	// for Status 405 (method not found) and useful for the OPTIONS method in responses.
@@ -42,18 +47,29 @@ const (
 	HeaderAuthorization       = "Authorization"
 	HeaderContentDisposition  = "Content-Disposition"
 	HeaderContentEncoding     = "Content-Encoding"
+	HeaderContentLanguage     = "Content-Language"
 	HeaderContentLength       = "Content-Length"
+	HeaderContentRange        = "Content-Range"
 	HeaderContentType         = "Content-Type"
 	HeaderCookie              = "Cookie"
+	HeaderDate                = "Date"
+	HeaderETag                = "ETag"
+	HeaderHost                = "Host"
 	HeaderSetCookie           = "Set-Cookie"
 	HeaderIfModifiedSince     = "If-Modified-Since"
+	HeaderIfNoneMatch         = "If-None-Match"
 	HeaderLastModified        = "Last-Modified"
+	HeaderLink                = "Link"
 	HeaderLocation            = "Location"
+	HeaderRange               = "Range"
+	HeaderTrailer             = "Trailer"
+	HeaderTransferEncoding    = "Transfer-Encoding"
 	HeaderRetryAfter          = "Retry-After"
 	HeaderUpgrade             = "Upgrade"
 	HeaderVary                = "Vary"
 	HeaderWWWAuthenticate     = "WWW-Authenticate"
 	HeaderXForwardedFor       = "X-Forwarded-For"
+	HeaderXForwardedHost      = "X-Forwarded-Host"
 	HeaderXForwardedProto     = "X-Forwarded-Proto"
 	HeaderXForwardedProtocol  = "X-Forwarded-Protocol"
 	HeaderXForwardedSsl       = "X-Forwarded-Ssl"
@@ -64,13 +80,22 @@ const (
 	HeaderXCorrelationID      = "X-Correlation-Id"
 	HeaderXRequestedWith      = "X-Requested-With"
 	HeaderServer              = "Server"
+	HeaderTraceparent         = "Traceparent"
+	HeaderTracestate          = "Tracestate"
 	HeaderOrigin              = "Origin"
 	HeaderCacheControl        = "Cache-Control"
+	HeaderExpires             = "Expires"
+	HeaderSurrogateControl    = "Surrogate-Control"
 	HeaderConnection          = "Connection"
 	HeaderXRobotsTag          = "X-Robots-Tag"
 	HeaderXRateLimitLimit     = "X-RateLimit-Limit"
 	HeaderXRateLimitRemaining = "X-RateLimit-Remaining"
 	HeaderXRateLimitReset     = "X-RateLimit-Reset"
+	HeaderRateLimit           = "RateLimit"
+	HeaderRateLimitPolicy     = "RateLimit-Policy"
+	HeaderRateLimitLimit      = "RateLimit-Limit"
+	HeaderRateLimitRemaining  = "RateLimit-Remaining"
+	HeaderRateLimitReset      = "RateLimit-Reset"
 
 	// Access control
 	HeaderAccessControlRequestMethod    = "Access-Control-Request-Method"
@@ -1,15 +1,47 @@
 package keratin
 
-import "context"
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
 
 var nilKCtx = new(kContext)
 
+// Context is the per-request container stored in the request context by the [Router].
+// It exposes request metadata and a concurrency-safe key/value store
+// that middlewares and handlers can use to share data, see [ContextValue].
+//
+// Outside the router (e.g. in tests), [FromContext] returns an empty Context
+// which ignores all writes.
 type Context interface {
 	Scheme() string
 	RealIP() string
 	Pattern() string
 	Methods() string
 	AnyMethods() bool
+
+	// Route returns the matched route, nil if no route matched (yet).
+	Route() *Route
+
+	// Params returns the path parameters of the matched route pattern, e.g. {"id": "42"}.
+	// The returned map must not be modified.
+	Params() map[string]string
+
+	// RequestID returns the request ID set by the RequestID middleware (or [Context.SetRequestID]).
+	RequestID() string
+	SetRequestID(id string)
+
+	// StartTime returns the time the router started to handle the request.
+	StartTime() time.Time
+
+	// Get returns the value stored for key.
+	Get(key any) (any, bool)
+	// Set stores value for key. Like for [context.WithValue], keys should be of unexported types.
+	Set(key, value any)
 }
 
 func FromContext(ctx context.Context) Context {
@@ -19,6 +51,41 @@ func FromContext(ctx context.Context) Context {
 	return nilKCtx
 }
 
+// ContextValue returns the value of type T stored for key in the request [Context].
+func ContextValue[T any](ctx context.Context, key any) (T, bool) {
+	value, ok := FromContext(ctx).Get(key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+
+	t, ok := value.(T)
+	return t, ok
+}
+
+// RequestHeader returns the header of the request handled by the [Router], nil outside the router.
+// The returned header must not be modified.
+func RequestHeader(ctx context.Context) http.Header {
+	if c, ok := ctx.Value(ctxKey{}).(*kContext); ok {
+		return c.header
+	}
+	return nil
+}
+
+// BasePath returns the base path of the [Router] handling the request (see [WithBasePath]),
+// "" outside the router or without base path. Prepend it to the route paths to build their URLs.
+func BasePath(ctx context.Context) string {
+	if c, ok := ctx.Value(ctxKey{}).(*kContext); ok {
+		return c.basePath
+	}
+	return ""
+}
+
+// SetContextValue stores value for key in the request [Context].
+func SetContextValue[T any](ctx context.Context, key any, value T) {
+	FromContext(ctx).Set(key, value)
+}
+
 type ctxKey struct{}
 
 type kContext struct {
@@ -27,7 +94,31 @@ type kContext struct {
 	pattern    string
 	methods    string
 	anyMethods bool
+	route      *Route
+	paramNames []string
+	params     map[string]string
+	requestID  string
+	startTime  time.Time
+	validator  Validator
+	trace      *chainTrace
 	err        error
+	header     http.Header
+	basePath   string
+
+	// parent is the context of the request before the router added the kContext to it
+	parent context.Context
+
+	mu       sync.RWMutex
+	values   map[any]any
+	deferred []func(context.Context)
+
+	baseLogger       *slog.Logger
+	reqLogger        *slog.Logger
+	reqLoggerID      string
+	reqLoggerPattern string
+
+	// release resets the context and puts it back to the router pool
+	release func()
 }
 
 func (c *kContext) reset() {
@@ -36,7 +127,28 @@ func (c *kContext) reset() {
 	c.pattern = ""
 	c.methods = ""
 	c.anyMethods = false
+	c.route = nil
+	c.paramNames = nil
+	clear(c.params)
+	c.requestID = ""
+	c.startTime = time.Time{}
+	c.validator = nil
+	c.trace = nil
 	c.err = nil
+	c.header = nil
+	c.basePath = ""
+
+	c.parent = nil
+
+	c.mu.Lock()
+	clear(c.values)
+	clear(c.deferred)
+	c.deferred = c.deferred[:0]
+	c.baseLogger = nil
+	c.reqLogger = nil
+	c.reqLoggerID = ""
+	c.reqLoggerPattern = ""
+	c.mu.Unlock()
 }
 
 func (c *kContext) Scheme() string {
@@ -58,3 +170,84 @@ func (c *kContext) Methods() string {
 func (c *kContext) AnyMethods() bool {
 	return c.anyMethods
 }
+
+func (c *kContext) Route() *Route {
+	return c.route
+}
+
+func (c *kContext) Params() map[string]string {
+	return c.params
+}
+
+func (c *kContext) RequestID() string {
+	return c.requestID
+}
+
+func (c *kContext) SetRequestID(id string) {
+	if c == nilKCtx {
+		return
+	}
+	c.requestID = id
+}
+
+func (c *kContext) StartTime() time.Time {
+	return c.startTime
+}
+
+func (c *kContext) Get(key any) (any, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	value, ok := c.values[key]
+	return value, ok
+}
+
+func (c *kContext) Set(key, value any) {
+	if c == nilKCtx {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.values == nil {
+		c.values = make(map[any]any)
+	}
+	c.values[key] = value
+}
+
+// setParams fills the path parameters from the matched request.
+func (c *kContext) setParams(pathValue func(string) string) {
+	if len(c.paramNames) == 0 {
+		return
+	}
+
+	if c.params == nil {
+		c.params = make(map[string]string, len(c.paramNames))
+	}
+	for _, name := range c.paramNames {
+		c.params[name] = pathValue(name)
+	}
+}
+
+// patternParams returns the names of the wildcards of a ServeMux pattern,
+// e.g. "GET /users/{id}/files/{path...}" -> ["id", "path"].
+func patternParams(pattern string) []string {
+	var names []string
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			return names
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			return names
+		}
+
+		name := strings.TrimSuffix(pattern[start+1:start+end], "...")
+		if name != "" && name != "$" {
+			names = append(names, name)
+		}
+		pattern = pattern[start+end+1:]
+	}
+}
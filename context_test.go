@@ -2,6 +2,9 @@ package keratin
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -319,3 +322,102 @@ func TestKContext_WithAllFields(t *testing.T) {
 	require.Equal(t, "GET,HEAD,OPTIONS", ctx.Methods())
 	require.Equal(t, true, ctx.AnyMethods())
 }
+
+type ctxTestKey struct{}
+
+func TestKContext_Values(t *testing.T) {
+	c := &kContext{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, c)
+
+	_, ok := ContextValue[string](ctx, ctxTestKey{})
+	require.False(t, ok)
+
+	SetContextValue(ctx, ctxTestKey{}, "value")
+
+	got, ok := ContextValue[string](ctx, ctxTestKey{})
+	require.True(t, ok)
+	require.Equal(t, "value", got)
+
+	_, ok = ContextValue[int](ctx, ctxTestKey{})
+	require.False(t, ok, "wrong type")
+
+	c.reset()
+
+	_, ok = c.Get(ctxTestKey{})
+	require.False(t, ok)
+}
+
+func TestKContext_Values_Concurrent(t *testing.T) {
+	c := &kContext{}
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Go(func() {
+			c.Set(i, i)
+			_, _ = c.Get(i)
+		})
+	}
+	wg.Wait()
+
+	for i := range 10 {
+		got, ok := c.Get(i)
+		require.True(t, ok)
+		require.Equal(t, i, got)
+	}
+}
+
+func TestNilKCtx_IgnoresWrites(t *testing.T) {
+	ctx := context.Background()
+
+	SetContextValue(ctx, ctxTestKey{}, "value")
+	FromContext(ctx).SetRequestID("id")
+
+	_, ok := ContextValue[string](ctx, ctxTestKey{})
+	require.False(t, ok)
+	require.Empty(t, FromContext(ctx).RequestID())
+	require.Nil(t, FromContext(ctx).Params())
+	require.True(t, FromContext(ctx).StartTime().IsZero())
+}
+
+func TestPatternParams(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"/users", nil},
+		{"/users/{$}", nil},
+		{"GET /users/{id}", []string{"id"}},
+		{"example.com/orgs/{org}/users/{user}", []string{"org", "user"}},
+		{"/files/{path...}", []string{"path"}},
+		{"/broken/{id", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			require.Equal(t, tt.want, patternParams(tt.pattern))
+		})
+	}
+}
+
+func TestRouter_ContextMetadata(t *testing.T) {
+	router := NewRouter()
+
+	var got Context
+	router.GET("/orgs/{org}/files/{path...}", func(w http.ResponseWriter, r *http.Request) error {
+		got = FromContext(r.Context())
+
+		require.Equal(t, map[string]string{"org": "acme", "path": "a/b.txt"}, got.Params())
+		require.Equal(t, "/orgs/{org}/files/{path...}", got.Pattern())
+		require.False(t, got.StartTime().IsZero())
+
+		got.SetRequestID("rid")
+		require.Equal(t, "rid", got.RequestID())
+		return nil
+	})
+
+	router.Build().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orgs/acme/files/a/b.txt", nil))
+
+	// the context is returned to the pool after the request
+	require.Empty(t, got.Params())
+	require.Empty(t, got.RequestID())
+}
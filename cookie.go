@@ -0,0 +1,212 @@
+package keratin
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCookieSignature is returned by [GetSignedCookie] when the cookie value
+// is malformed or its signature does not match any of the signer keys.
+var ErrInvalidCookieSignature = errors.New("keratin: invalid cookie signature")
+
+const (
+	cookieSecurePrefix = "__Secure-"
+	cookieHostPrefix   = "__Host-"
+)
+
+// CookieOpts describes a cookie written by [SetCookie] and [SetSignedCookie].
+//
+// The zero value is a session cookie with the secure defaults:
+// Path "/", HttpOnly and SameSite=Lax.
+type CookieOpts struct {
+	Name   string
+	Value  string
+	Path   string
+	Domain string
+
+	// Expires and MaxAge are set as is, a negative MaxAge deletes the cookie.
+	Expires time.Time
+	MaxAge  int
+
+	// Secure restricts the cookie to HTTPS. It is forced for the SameSite=None,
+	// partitioned and the "__Secure-"/"__Host-" prefixed cookies.
+	Secure bool
+
+	// Scriptable allows the cookie to be read by JavaScript, aka. it omits the HttpOnly attribute.
+	Scriptable bool
+
+	// Partitioned sets the CHIPS Partitioned attribute.
+	Partitioned bool
+
+	// SameSite defaults to [http.SameSiteLaxMode],
+	// use [http.SameSiteDefaultMode] to omit the attribute.
+	SameSite http.SameSite
+}
+
+// Validate reports the cookie prefix and partitioning requirements violated by the options:
+// the "__Secure-" prefixed and the partitioned (CHIPS) cookies must be Secure,
+// the "__Host-" prefixed cookies must be Secure, without Domain and with Path "/".
+//
+// [CookieOpts.Cookie] enforces them silently, Validate allows to report a misconfiguration early.
+func (o CookieOpts) Validate() error {
+	switch {
+	case strings.HasPrefix(o.Name, cookieHostPrefix):
+		if !o.Secure {
+			return fmt.Errorf("keratin: cookie %q: %s prefix requires Secure", o.Name, cookieHostPrefix)
+		}
+		if o.Domain != "" {
+			return fmt.Errorf("keratin: cookie %q: %s prefix forbids Domain", o.Name, cookieHostPrefix)
+		}
+		if o.Path != "" && o.Path != "/" {
+			return fmt.Errorf("keratin: cookie %q: %s prefix requires Path \"/\"", o.Name, cookieHostPrefix)
+		}
+	case strings.HasPrefix(o.Name, cookieSecurePrefix):
+		if !o.Secure {
+			return fmt.Errorf("keratin: cookie %q: %s prefix requires Secure", o.Name, cookieSecurePrefix)
+		}
+	}
+
+	if o.Partitioned && !o.Secure {
+		return fmt.Errorf("keratin: cookie %q: Partitioned requires Secure", o.Name)
+	}
+
+	return nil
+}
+
+// Cookie returns the [http.Cookie] with the defaults applied.
+func (o CookieOpts) Cookie() *http.Cookie {
+	cookie := &http.Cookie{
+		Name:        o.Name,
+		Value:       o.Value,
+		Path:        o.Path,
+		Domain:      o.Domain,
+		Expires:     o.Expires,
+		MaxAge:      o.MaxAge,
+		Secure:      o.Secure,
+		HttpOnly:    !o.Scriptable,
+		Partitioned: o.Partitioned,
+		SameSite:    o.SameSite,
+	}
+
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+	if cookie.SameSite == 0 {
+		cookie.SameSite = http.SameSiteLaxMode
+	}
+	if cookie.SameSite == http.SameSiteNoneMode || cookie.Partitioned || strings.HasPrefix(cookie.Name, cookieSecurePrefix) {
+		cookie.Secure = true
+	}
+	if strings.HasPrefix(cookie.Name, cookieHostPrefix) {
+		// https://www.rfc-editor.org/rfc/rfc6265bis#name-the-__host-prefix
+		cookie.Secure = true
+		cookie.Path = "/"
+		cookie.Domain = ""
+	}
+
+	return cookie
+}
+
+// SetCookie adds the Set-Cookie header with the cookie described by opts to the response.
+func SetCookie(w http.ResponseWriter, opts CookieOpts) {
+	http.SetCookie(w, opts.Cookie())
+}
+
+// DeleteCookie adds the Set-Cookie header expiring the cookie described by opts.
+// Path and Domain must match the ones the cookie was set with.
+func DeleteCookie(w http.ResponseWriter, opts CookieOpts) {
+	opts.Value = ""
+	opts.Expires = time.Unix(1, 0)
+	opts.MaxAge = -1
+
+	SetCookie(w, opts)
+}
+
+// CookieSigner signs and verifies cookie values with HMAC-SHA256.
+//
+// The first key signs new values, all the keys verify them, so the keys can be rotated
+// by prepending a new key and removing the old one once the signed cookies expire.
+type CookieSigner struct {
+	keys [][]byte
+}
+
+// NewCookieSigner creates a [CookieSigner], it panics without keys.
+func NewCookieSigner(keys ...[]byte) *CookieSigner {
+	if len(keys) == 0 {
+		panic("keratin: cookie signer requires at least one key")
+	}
+	for _, key := range keys {
+		if len(key) == 0 {
+			panic("keratin: cookie signer key must not be empty")
+		}
+	}
+
+	return &CookieSigner{keys: keys}
+}
+
+// Sign returns the value encoded with its signature bound to the cookie name.
+func (s *CookieSigner) Sign(name, value string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(value))
+
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.mac(s.keys[0], name, payload))
+}
+
+// Verify returns the original value of the signed one,
+// or [ErrInvalidCookieSignature] if the signature does not match any key.
+func (s *CookieSigner) Verify(name, signed string) (string, error) {
+	payload, signature, ok := strings.Cut(signed, ".")
+	if !ok {
+		return "", ErrInvalidCookieSignature
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return "", ErrInvalidCookieSignature
+	}
+
+	for _, key := range s.keys {
+		if hmac.Equal(mac, s.mac(key, name, payload)) {
+			value, err := base64.RawURLEncoding.DecodeString(payload)
+			if err != nil {
+				return "", ErrInvalidCookieSignature
+			}
+			return string(value), nil
+		}
+	}
+
+	return "", ErrInvalidCookieSignature
+}
+
+func (s *CookieSigner) mac(key []byte, name, payload string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(payload))
+
+	return h.Sum(nil)
+}
+
+// SetSignedCookie is like [SetCookie] but signs the cookie value with the signer.
+func SetSignedCookie(w http.ResponseWriter, signer *CookieSigner, opts CookieOpts) {
+	opts.Value = signer.Sign(opts.Name, opts.Value)
+
+	SetCookie(w, opts)
+}
+
+// GetSignedCookie returns the verified value of the named cookie set by [SetSignedCookie].
+// It returns [http.ErrNoCookie] if the cookie is missing
+// and [ErrInvalidCookieSignature] if it was tampered with.
+func GetSignedCookie(r *http.Request, signer *CookieSigner, name string) (string, error) {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+
+	return signer.Verify(name, cookie.Value)
+}
@@ -0,0 +1,142 @@
+package keratin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCookieOpts_Cookie(t *testing.T) {
+	tests := []struct {
+		name string
+		opts CookieOpts
+		want *http.Cookie
+	}{
+		{
+			name: "defaults",
+			opts: CookieOpts{Name: "a", Value: "b"},
+			want: &http.Cookie{Name: "a", Value: "b", Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode},
+		},
+		{
+			name: "scriptable strict",
+			opts: CookieOpts{Name: "a", Path: "/app", Scriptable: true, SameSite: http.SameSiteStrictMode},
+			want: &http.Cookie{Name: "a", Path: "/app", SameSite: http.SameSiteStrictMode},
+		},
+		{
+			name: "same site none forces secure",
+			opts: CookieOpts{Name: "a", SameSite: http.SameSiteNoneMode},
+			want: &http.Cookie{Name: "a", Path: "/", HttpOnly: true, Secure: true, SameSite: http.SameSiteNoneMode},
+		},
+		{
+			name: "partitioned forces secure",
+			opts: CookieOpts{Name: "a", Partitioned: true},
+			want: &http.Cookie{Name: "a", Path: "/", HttpOnly: true, Secure: true, Partitioned: true, SameSite: http.SameSiteLaxMode},
+		},
+		{
+			name: "secure prefix",
+			opts: CookieOpts{Name: "__Secure-a", Domain: "example.com"},
+			want: &http.Cookie{Name: "__Secure-a", Path: "/", Domain: "example.com", HttpOnly: true, Secure: true, SameSite: http.SameSiteLaxMode},
+		},
+		{
+			name: "host prefix",
+			opts: CookieOpts{Name: "__Host-a", Path: "/app", Domain: "example.com"},
+			want: &http.Cookie{Name: "__Host-a", Path: "/", HttpOnly: true, Secure: true, SameSite: http.SameSiteLaxMode},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.opts.Cookie())
+		})
+	}
+}
+
+func TestDeleteCookie(t *testing.T) {
+	w := httptest.NewRecorder()
+	DeleteCookie(w, CookieOpts{Name: "a", Value: "b"})
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Empty(t, cookies[0].Value)
+	assert.Equal(t, -1, cookies[0].MaxAge)
+	assert.True(t, cookies[0].Expires.Before(time.Unix(2, 0)))
+}
+
+func TestSignedCookie(t *testing.T) {
+	oldSigner := NewCookieSigner([]byte("old-key"))
+	signer := NewCookieSigner([]byte("new-key"), []byte("old-key"))
+
+	w := httptest.NewRecorder()
+	SetSignedCookie(w, oldSigner, CookieOpts{Name: "user", Value: "id=1; admin"})
+	cookie := w.Result().Cookies()[0]
+
+	tests := []struct {
+		name    string
+		cookie  *http.Cookie
+		signer  *CookieSigner
+		want    string
+		wantErr error
+	}{
+		{name: "rotated key", cookie: cookie, signer: signer, want: "id=1; admin"},
+		{name: "unknown key", cookie: cookie, signer: NewCookieSigner([]byte("other")), wantErr: ErrInvalidCookieSignature},
+		{name: "other name", cookie: &http.Cookie{Name: "user", Value: signer.Sign("account", "id=1")}, signer: signer, wantErr: ErrInvalidCookieSignature},
+		{name: "tampered", cookie: &http.Cookie{Name: "user", Value: "aWQ9Mg." + cookie.Value[len("aWQ9MTsgYWRtaW4."):]}, signer: signer, wantErr: ErrInvalidCookieSignature},
+		{name: "malformed", cookie: &http.Cookie{Name: "user", Value: "abc"}, signer: signer, wantErr: ErrInvalidCookieSignature},
+		{name: "missing", signer: signer, wantErr: http.ErrNoCookie},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.cookie != nil {
+				r.AddCookie(tt.cookie)
+			}
+
+			got, err := GetSignedCookie(r, tt.signer, "user")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewCookieSigner_Panics(t *testing.T) {
+	assert.Panics(t, func() { NewCookieSigner() })
+	assert.Panics(t, func() { NewCookieSigner([]byte{}) })
+}
+
+func TestCookieOpts_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    CookieOpts
+		wantErr string
+	}{
+		{name: "plain", opts: CookieOpts{Name: "a"}},
+		{name: "host", opts: CookieOpts{Name: "__Host-a", Secure: true, Path: "/"}},
+		{name: "host without secure", opts: CookieOpts{Name: "__Host-a"}, wantErr: `keratin: cookie "__Host-a": __Host- prefix requires Secure`},
+		{name: "host with domain", opts: CookieOpts{Name: "__Host-a", Secure: true, Domain: "example.com"}, wantErr: "forbids Domain"},
+		{name: "host with path", opts: CookieOpts{Name: "__Host-a", Secure: true, Path: "/app"}, wantErr: `requires Path "/"`},
+		{name: "secure", opts: CookieOpts{Name: "__Secure-a", Secure: true, Domain: "example.com", Path: "/app"}},
+		{name: "secure without secure", opts: CookieOpts{Name: "__Secure-a"}, wantErr: "__Secure- prefix requires Secure"},
+		{name: "partitioned", opts: CookieOpts{Name: "a", Secure: true, Partitioned: true}},
+		{name: "partitioned without secure", opts: CookieOpts{Name: "a", Partitioned: true}, wantErr: "Partitioned requires Secure"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package keratin
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// HeaderXMiddlewareChain is the response header listing the IDs of the executed middlewares in debug mode.
+const HeaderXMiddlewareChain = "X-Middleware-Chain"
+
+// WithDebug enables the per-request middleware chain tracing.
+//
+// In debug mode the IDs of the route middlewares are added to the [HeaderXMiddlewareChain] response header
+// in their execution order, their durations are recorded (see [MiddlewareChain]) and the middlewares
+// which spent more than the slow threshold themselves (excluding the inner handlers) are logged.
+// Debug mode adds overhead to every request and should not be enabled in production.
+func WithDebug(enabled bool) Option {
+	return func(router *Router) {
+		router.debug = enabled
+	}
+}
+
+// WithDebugSlowThreshold sets the self duration above which a middleware is logged in debug mode.
+// Default value is 10ms.
+func WithDebugSlowThreshold(threshold time.Duration) Option {
+	return func(router *Router) {
+		if threshold > 0 {
+			router.debugSlowThreshold = threshold
+		}
+	}
+}
+
+// WithDebugLogger sets the logger of the slow middlewares in debug mode. Default value is [slog.Default].
+func WithDebugLogger(logger *slog.Logger) Option {
+	return func(router *Router) {
+		if logger != nil {
+			router.debugLogger = logger
+		}
+	}
+}
+
+// MiddlewareTrace is the execution record of a middleware in debug mode.
+type MiddlewareTrace struct {
+	// ID is the middleware ID.
+	ID string
+	// Duration is the total middleware duration including the inner middlewares and handler.
+	Duration time.Duration
+	// Self is the duration spent in the middleware itself.
+	Self time.Duration
+}
+
+// MiddlewareChain returns the middlewares executed so far for the request in their execution order.
+// It returns nil when the router debug mode is disabled.
+func MiddlewareChain(ctx context.Context) []MiddlewareTrace {
+	c, ok := ctx.Value(ctxKey{}).(*kContext)
+	if !ok || c.trace == nil {
+		return nil
+	}
+
+	c.trace.mu.Lock()
+	defer c.trace.mu.Unlock()
+
+	return slices.Clone(c.trace.entries)
+}
+
+type chainTrace struct {
+	mu      sync.Mutex
+	entries []MiddlewareTrace
+	inner   []time.Duration
+	stack   []int
+}
+
+func (t *chainTrace) enter(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stack = append(t.stack, len(t.entries))
+	t.entries = append(t.entries, MiddlewareTrace{ID: id})
+	t.inner = append(t.inner, 0)
+}
+
+// next adds the duration of the inner chain to the current middleware.
+func (t *chainTrace) next(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.stack) > 0 {
+		t.inner[t.stack[len(t.stack)-1]] += d
+	}
+}
+
+func (t *chainTrace) leave(d time.Duration) MiddlewareTrace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.stack) == 0 {
+		return MiddlewareTrace{}
+	}
+
+	i := t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+
+	t.entries[i].Duration = d
+	t.entries[i].Self = max(d-t.inner[i], 0)
+
+	return t.entries[i]
+}
+
+// traceMiddlewares wraps the middlewares to record their execution when debug mode is enabled.
+func (r *Router) traceMiddlewares(middlewares Middlewares[Handler]) Middlewares[Handler] {
+	if !r.debug {
+		return middlewares
+	}
+
+	traced := make(Middlewares[Handler], len(middlewares))
+	for i, mw := range middlewares {
+		traced[i] = r.traceMiddleware(mw)
+	}
+	return traced
+}
+
+func (r *Router) traceMiddleware(mw *Middleware[Handler]) *Middleware[Handler] {
+	// anonymous middlewares are identified by their function name
+	traced := &Middleware[Handler]{
+		ID:       cmp.Or(mw.ID, handlerName(mw.Func)),
+		Priority: mw.Priority,
+		Before:   mw.Before,
+		After:    mw.After,
+	}
+
+	traced.Func = func(next Handler) Handler {
+		handler := mw.Func(HandlerFunc(func(w http.ResponseWriter, req *http.Request) error {
+			start := time.Now()
+			err := next.ServeHTTP(w, req)
+			if c, ok := req.Context().Value(ctxKey{}).(*kContext); ok && c.trace != nil {
+				c.trace.next(time.Since(start))
+			}
+			return err
+		}))
+
+		return HandlerFunc(func(w http.ResponseWriter, req *http.Request) error {
+			c, ok := req.Context().Value(ctxKey{}).(*kContext)
+			if !ok || c.trace == nil {
+				return handler.ServeHTTP(w, req)
+			}
+
+			w.Header().Add(HeaderXMiddlewareChain, traced.ID)
+			c.trace.enter(traced.ID)
+
+			start := time.Now()
+			err := handler.ServeHTTP(w, req)
+			trace := c.trace.leave(time.Since(start))
+
+			if trace.Self > r.debugSlowThreshold {
+				r.debugLogger.LogAttrs(req.Context(), slog.LevelWarn, "slow middleware",
+					slog.String("id", trace.ID),
+					slog.String("pattern", c.pattern),
+					slog.Duration("self", trace.Self),
+					slog.Duration("duration", trace.Duration),
+				)
+			}
+
+			return err
+		})
+	}
+
+	return traced
+}
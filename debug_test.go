@@ -0,0 +1,88 @@
+package keratin
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func debugTestAnonymous(next Handler) Handler { return next }
+
+func TestRouter_WithDebug(t *testing.T) {
+	var (
+		logs  bytes.Buffer
+		chain []MiddlewareTrace
+	)
+
+	router := NewRouter(
+		WithDebug(true),
+		WithDebugSlowThreshold(20*time.Millisecond),
+		WithDebugLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	)
+	router.Use(&Middleware[Handler]{ID: "slow", Func: func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			time.Sleep(30 * time.Millisecond)
+			return next.ServeHTTP(w, r)
+		})
+	}})
+	router.Use(&Middleware[Handler]{ID: "first", Priority: -1, Func: debugTestAnonymous})
+	router.UseFunc(debugTestAnonymous)
+	router.GET("/test", func(w http.ResponseWriter, r *http.Request) error {
+		time.Sleep(30 * time.Millisecond)
+		chain = MiddlewareChain(r.Context())
+		return TextPlain(w, http.StatusOK, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	router.Build().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"first", "slow", "github.com/gowool/keratin.debugTestAnonymous"}, w.Header().Values(HeaderXMiddlewareChain))
+
+	require.Len(t, chain, 3)
+	assert.Equal(t, "first", chain[0].ID)
+	assert.Equal(t, "slow", chain[1].ID)
+
+	// slow middleware is logged, while the ones waiting for the slow handler are not
+	assert.Contains(t, logs.String(), `msg="slow middleware" id=slow pattern=/test`)
+	assert.NotContains(t, logs.String(), "id=first")
+	assert.NotContains(t, logs.String(), "debugTestAnonymous")
+}
+
+func TestRouter_WithoutDebug(t *testing.T) {
+	var chain []MiddlewareTrace
+
+	router := NewRouter()
+	router.UseFunc(debugTestAnonymous)
+	router.GET("/test", func(w http.ResponseWriter, r *http.Request) error {
+		chain = MiddlewareChain(r.Context())
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	router.Build().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	assert.Nil(t, chain)
+	assert.Empty(t, w.Header().Values(HeaderXMiddlewareChain))
+}
+
+func TestChainTrace(t *testing.T) {
+	trace := new(chainTrace)
+
+	trace.enter("outer")
+	trace.enter("inner")
+	trace.next(5 * time.Millisecond)
+	inner := trace.leave(8 * time.Millisecond)
+	trace.next(8 * time.Millisecond)
+	outer := trace.leave(10 * time.Millisecond)
+
+	assert.Equal(t, MiddlewareTrace{ID: "inner", Duration: 8 * time.Millisecond, Self: 3 * time.Millisecond}, inner)
+	assert.Equal(t, MiddlewareTrace{ID: "outer", Duration: 10 * time.Millisecond, Self: 2 * time.Millisecond}, outer)
+	assert.Equal(t, MiddlewareTrace{}, trace.leave(time.Second))
+}
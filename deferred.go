@@ -0,0 +1,125 @@
+package keratin
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"sync"
+)
+
+// WithDeferredWorkers runs the deferred tasks (see [Defer]) in a pool of workers goroutines
+// with a queue of queueSize pending tasks. When the queue is full, the request handling goroutine
+// blocks until a worker is available, which limits the load of the background work.
+// By default every deferred task runs in its own goroutine.
+func WithDeferredWorkers(workers, queueSize int) Option {
+	return func(router *Router) {
+		if workers > 0 {
+			router.deferred.workers = workers
+			router.deferred.queueSize = max(queueSize, 0)
+		}
+	}
+}
+
+// Defer schedules fn to run in the background after the response of r has been written,
+// e.g. to send an email without delaying the response.
+//
+// fn receives a context without the request cancellation and deadline, it must not use r and its writer.
+// The panics of fn are recovered and logged. Outside a router request fn is started right away.
+func Defer(r *http.Request, fn func(ctx context.Context)) {
+	if fn == nil {
+		return
+	}
+
+	c, ok := r.Context().Value(ctxKey{}).(*kContext)
+	if !ok {
+		go runDeferred(context.WithoutCancel(r.Context()), slog.Default(), fn)
+		return
+	}
+
+	c.mu.Lock()
+	c.deferred = append(c.deferred, fn)
+	c.mu.Unlock()
+}
+
+// WaitDeferred waits until all deferred tasks scheduled so far are finished or ctx is done,
+// e.g. during a graceful shutdown after the server stopped accepting requests.
+func (r *Router) WaitDeferred(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		r.deferred.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type deferredPool struct {
+	workers   int
+	queueSize int
+
+	once  sync.Once
+	queue chan func()
+	wg    sync.WaitGroup
+}
+
+// submitDeferred starts the deferred tasks of the finished request c.
+func (r *Router) submitDeferred(c *kContext) {
+	c.mu.Lock()
+	tasks := c.deferred
+	c.mu.Unlock()
+
+	if len(tasks) == 0 {
+		return
+	}
+
+	ctx := context.WithoutCancel(c.parent)
+	logger := r.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	p := &r.deferred
+	if p.workers > 0 {
+		p.once.Do(func() {
+			p.queue = make(chan func(), p.queueSize)
+			for range p.workers {
+				go func() {
+					for task := range p.queue {
+						task()
+					}
+				}()
+			}
+		})
+	}
+
+	for _, fn := range tasks {
+		p.wg.Add(1)
+		task := func() {
+			defer p.wg.Done()
+			runDeferred(ctx, logger, fn)
+		}
+
+		if p.queue != nil {
+			p.queue <- task
+		} else {
+			go task()
+		}
+	}
+}
+
+// runDeferred runs fn recovering and logging its panic.
+func runDeferred(ctx context.Context, logger *slog.Logger, fn func(context.Context)) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			logger.ErrorContext(ctx, "deferred task panic recovered", "error", &PanicError{Value: rec, Stack: debug.Stack()})
+		}
+	}()
+
+	fn(ctx)
+}
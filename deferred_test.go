@@ -0,0 +1,145 @@
+package keratin
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type deferredTestKey struct{}
+
+func TestDefer(t *testing.T) {
+	router := NewRouter()
+
+	var (
+		mu      sync.Mutex
+		events  []string
+		taskCtx context.Context
+	)
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+
+	release := make(chan struct{})
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		Defer(r, func(ctx context.Context) {
+			<-release
+			taskCtx = ctx
+			record("task")
+		})
+		Defer(r, nil)
+		record("handler")
+		return TextPlain(w, http.StatusOK, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(context.WithValue(req.Context(), deferredTestKey{}, "value"))
+	ctx, cancel := context.WithCancel(req.Context())
+	req = req.WithContext(ctx)
+
+	rec := httptest.NewRecorder()
+	router.Build().ServeHTTP(rec, req)
+	cancel()
+
+	assert.Equal(t, "ok", rec.Body.String())
+	record("response")
+	close(release)
+
+	require.NoError(t, router.WaitDeferred(context.Background()))
+	assert.Equal(t, []string{"handler", "response", "task"}, events)
+
+	require.NotNil(t, taskCtx)
+	assert.NoError(t, taskCtx.Err())
+	assert.Equal(t, "value", taskCtx.Value(deferredTestKey{}))
+	assert.Nil(t, taskCtx.Value(ctxKey{}))
+}
+
+func TestDefer_Panic(t *testing.T) {
+	var buf bytes.Buffer
+	router := NewRouter(WithLoggerInjector(slog.New(slog.NewTextHandler(&buf, nil))))
+
+	var ran atomic.Bool
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		Defer(r, func(context.Context) { panic("boom") })
+		Defer(r, func(context.Context) { ran.Store(true) })
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	router.Build().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.NoError(t, router.WaitDeferred(context.Background()))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, ran.Load())
+	assert.Contains(t, buf.String(), "deferred task panic recovered")
+	assert.Contains(t, buf.String(), "[PANIC RECOVER] boom")
+}
+
+func TestWithDeferredWorkers(t *testing.T) {
+	router := NewRouter(WithDeferredWorkers(2, 10))
+
+	var running, maxRunning, done atomic.Int32
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		Defer(r, func(context.Context) {
+			n := running.Add(1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+			done.Add(1)
+		})
+		return nil
+	})
+
+	handler := router.Build()
+	for range 8 {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	require.NoError(t, router.WaitDeferred(context.Background()))
+
+	assert.Equal(t, int32(8), done.Load())
+	assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+}
+
+func TestRouter_WaitDeferred_Timeout(t *testing.T) {
+	router := NewRouter()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		Defer(r, func(context.Context) { <-release })
+		return nil
+	})
+	router.Build().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, router.WaitDeferred(ctx), context.DeadlineExceeded)
+}
+
+func TestDefer_OutsideRouter(t *testing.T) {
+	done := make(chan struct{})
+	Defer(httptest.NewRequest(http.MethodGet, "/", nil), func(context.Context) { close(done) })
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("deferred task did not run")
+	}
+}
@@ -0,0 +1,32 @@
+package keratin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"syscall"
+)
+
+// StatusClientClosedRequest is the nginx-style non-standard status of the requests
+// abandoned by the client before the response was written.
+const StatusClientClosedRequest = 499
+
+// ErrClientClosedRequest is the error of the requests abandoned by the client, see [IsClientDisconnect].
+var ErrClientClosedRequest = NewHTTPError(StatusClientClosedRequest, "Client Closed Request")
+
+// IsClientDisconnect reports whether err is caused by the client going away: the canceled request
+// context, the broken or reset connection, the aborted handler or an error with the 499 status.
+//
+// Such errors are not failures of the server. The router reports them with the 499 status
+// (see [HTTPErrorStatusCode]) and logs them at debug level, the loggers should do the same.
+func IsClientDisconnect(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	return errors.Is(err, context.Canceled) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, http.ErrAbortHandler) ||
+		ErrorStatusCode(err) == StatusClientClosedRequest
+}
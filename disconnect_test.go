@@ -0,0 +1,75 @@
+package keratin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsClientDisconnect(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil"},
+		{name: "other", err: errors.New("boom")},
+		{name: "deadline", err: context.DeadlineExceeded},
+		{name: "canceled", err: context.Canceled, want: true},
+		{name: "wrapped canceled", err: fmt.Errorf("query: %w", context.Canceled), want: true},
+		{name: "broken pipe", err: &os.SyscallError{Syscall: "write", Err: syscall.EPIPE}, want: true},
+		{name: "connection reset", err: fmt.Errorf("read: %w", syscall.ECONNRESET), want: true},
+		{name: "abort handler", err: http.ErrAbortHandler, want: true},
+		{name: "client closed request", err: ErrClientClosedRequest, want: true},
+		{name: "http error", err: ErrBadRequest.Wrap(context.Canceled), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsClientDisconnect(tt.err))
+		})
+	}
+}
+
+func TestHTTPErrorStatusCode_ClientDisconnect(t *testing.T) {
+	assert.Equal(t, StatusClientClosedRequest, HTTPErrorStatusCode(context.Canceled))
+	assert.Equal(t, StatusClientClosedRequest, HTTPErrorStatusCode(fmt.Errorf("read body: %w", syscall.ECONNRESET)))
+	assert.Equal(t, http.StatusBadRequest, HTTPErrorStatusCode(ErrBadRequest.Wrap(context.Canceled)))
+	assert.Equal(t, http.StatusInternalServerError, HTTPErrorStatusCode(context.DeadlineExceeded))
+}
+
+func TestRouter_ClientDisconnect(t *testing.T) {
+	var buf bytes.Buffer
+	router := NewRouter(WithLoggerInjector(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+	router.GET("/report", func(_ http.ResponseWriter, r *http.Request) error {
+		return fmt.Errorf("query: %w", context.Canceled)
+	})
+	router.GET("/fail", func(http.ResponseWriter, *http.Request) error {
+		return errors.New("boom")
+	})
+	h := router.Build()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/report", nil))
+
+	assert.Equal(t, StatusClientClosedRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Client Closed Request")
+	assert.Contains(t, buf.String(), "level=DEBUG")
+	assert.Contains(t, buf.String(), `msg="client disconnected"`)
+
+	buf.Reset()
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Empty(t, buf.String())
+}
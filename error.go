@@ -1,8 +1,10 @@
 package keratin
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 )
 
 // Following errors can produce HTTP status code by implementing HTTPStatusCoder interface
@@ -53,6 +55,11 @@ type HTTPError struct {
 	Code    int    `json:"code,omitempty"`
 	Message string `json:"message,omitempty"`
 	Data    any    `json:"data,omitempty"`
+	// ErrorCode is the optional machine-readable error code, e.g. "user_not_found".
+	// The errors with the same ErrorCode match each other with [errors.Is].
+	ErrorCode string `json:"error_code,omitempty"`
+	// Details are the optional machine-readable details of the error.
+	Details any `json:"details,omitempty"`
 	err     error
 }
 
@@ -64,12 +71,30 @@ func NewHTTPError(code int, message string) *HTTPError {
 	}
 }
 
+// NewHTTPErrorWithCode creates a new instance of HTTPError with the machine-readable error code,
+// see [RegisterErrorCode] for the sentinel errors.
+func NewHTTPErrorWithCode(code int, errorCode, message string) *HTTPError {
+	return &HTTPError{
+		Code:      code,
+		Message:   message,
+		ErrorCode: errorCode,
+	}
+}
+
 // SetData sets data to be returned in HTTP response
 func (he *HTTPError) SetData(data any) *HTTPError {
 	he.Data = data
 	return he
 }
 
+// WithDetails returns a copy of the error with the given details, the receiver is not modified
+// so that it can be used with the sentinel errors.
+func (he *HTTPError) WithDetails(details any) *HTTPError {
+	cp := *he
+	cp.Details = details
+	return &cp
+}
+
 // StatusCode returns status code for HTTP response
 func (he *HTTPError) StatusCode() int {
 	return he.Code
@@ -81,18 +106,30 @@ func (he *HTTPError) Error() string {
 	if msg == "" {
 		msg = http.StatusText(he.Code)
 	}
+	code := strconv.Itoa(he.Code)
+	if he.ErrorCode != "" {
+		code += ", error_code=" + he.ErrorCode
+	}
 	if he.err == nil {
-		return fmt.Sprintf("code=%d, message=%v", he.Code, msg)
+		return fmt.Sprintf("code=%s, message=%v", code, msg)
 	}
-	return fmt.Sprintf("code=%d, message=%v, err=%v", he.Code, msg, he.err.Error())
+	return fmt.Sprintf("code=%s, message=%v, err=%v", code, msg, he.err.Error())
+}
+
+// Is reports whether target is an HTTPError with the same non-empty ErrorCode.
+func (he *HTTPError) Is(target error) bool {
+	t, ok := target.(*HTTPError)
+	return ok && he.ErrorCode != "" && he.ErrorCode == t.ErrorCode
 }
 
 // Wrap returns a new HTTPError with given errors wrapped inside
 func (he *HTTPError) Wrap(err error) error {
 	return &HTTPError{
-		Code:    he.Code,
-		Message: he.Message,
-		err:     err,
+		Code:      he.Code,
+		Message:   he.Message,
+		ErrorCode: he.ErrorCode,
+		Details:   he.Details,
+		err:       err,
 	}
 }
 
@@ -120,6 +157,8 @@ func (he httpError) Wrap(err error) error {
 	}
 }
 
+// HTTPErrorStatusCode returns the status code of err if it is an error status, [StatusClientClosedRequest]
+// for the client disconnects (see [IsClientDisconnect]) and 500 Internal Server Error otherwise.
 func HTTPErrorStatusCode(err error) int {
 	if err == nil {
 		panic("cannot get status code from nil error")
@@ -129,6 +168,10 @@ func HTTPErrorStatusCode(err error) int {
 		return code
 	}
 
+	if IsClientDisconnect(err) {
+		return StatusClientClosedRequest
+	}
+
 	return http.StatusInternalServerError
 }
 
@@ -156,3 +199,33 @@ func ErrorStatusCode(err error) int {
 		}
 	}
 }
+
+// ErrPanic matches (with [errors.Is]) the errors of the recovered panics, see [PanicError].
+var ErrPanic = errors.New("panic")
+
+// PanicError is the error of a recovered panic. It allows the error handlers and the loggers
+// to tell the panics apart from the ordinary 500 Internal Server Error.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the panic, it should not be exposed in production.
+	Stack []byte
+}
+
+// Error returns the panic value followed by the stack trace.
+func (pe *PanicError) Error() string {
+	return fmt.Sprintf("[PANIC RECOVER] %v %s", pe.Value, pe.Stack)
+}
+
+// Is reports whether target is [ErrPanic].
+func (pe *PanicError) Is(target error) bool {
+	return target == ErrPanic
+}
+
+// Unwrap returns the panic value if it is an error.
+func (pe *PanicError) Unwrap() error {
+	if err, ok := pe.Value.(error); ok {
+		return err
+	}
+	return nil
+}
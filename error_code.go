@@ -0,0 +1,64 @@
+package keratin
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+var (
+	errorCodesMu sync.RWMutex
+	errorCodes   = make(map[string]*HTTPError)
+)
+
+// RegisterErrorCode registers and returns the sentinel error of the machine-readable error code.
+// The errors with the same code, e.g. the wrapped sentinels or the errors decoded from the API responses,
+// match the sentinel with [errors.Is]:
+//
+//	var ErrUserNotFound = keratin.RegisterErrorCode(http.StatusNotFound, "user_not_found", "User not found")
+//
+//	return ErrUserNotFound.WithDetails(map[string]string{"id": id})
+//
+// The sentinel must not be modified, use [HTTPError.WithDetails] or [HTTPError.Wrap] instead.
+// It panics if the code is empty or already registered.
+func RegisterErrorCode(status int, code, message string) *HTTPError {
+	if code == "" {
+		panic("keratin: error code is required")
+	}
+
+	errorCodesMu.Lock()
+	defer errorCodesMu.Unlock()
+
+	if _, ok := errorCodes[code]; ok {
+		panic(fmt.Sprintf("keratin: error code %q is already registered", code))
+	}
+
+	err := NewHTTPErrorWithCode(status, code, message)
+	errorCodes[code] = err
+	return err
+}
+
+// LookupErrorCode returns the sentinel error registered with the code, see [RegisterErrorCode].
+func LookupErrorCode(code string) (*HTTPError, bool) {
+	errorCodesMu.RLock()
+	defer errorCodesMu.RUnlock()
+
+	err, ok := errorCodes[code]
+	return err, ok
+}
+
+// ErrorCodes returns the registered sentinel errors sorted by their codes, e.g. to document them for the API clients.
+func ErrorCodes() []*HTTPError {
+	errorCodesMu.RLock()
+	defer errorCodesMu.RUnlock()
+
+	errs := make([]*HTTPError, 0, len(errorCodes))
+	for _, err := range errorCodes {
+		errs = append(errs, err)
+	}
+	slices.SortFunc(errs, func(a, b *HTTPError) int {
+		return cmp.Compare(a.ErrorCode, b.ErrorCode)
+	})
+	return errs
+}
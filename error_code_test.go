@@ -0,0 +1,62 @@
+package keratin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterErrorCode(t *testing.T) {
+	t.Cleanup(func() {
+		errorCodesMu.Lock()
+		delete(errorCodes, "test_user_not_found")
+		delete(errorCodes, "test_account_locked")
+		errorCodesMu.Unlock()
+	})
+
+	errUserNotFound := RegisterErrorCode(http.StatusNotFound, "test_user_not_found", "User not found")
+	errAccountLocked := RegisterErrorCode(http.StatusLocked, "test_account_locked", "Account locked")
+
+	assert.Equal(t, http.StatusNotFound, errUserNotFound.StatusCode())
+	assert.Equal(t, "test_user_not_found", errUserNotFound.ErrorCode)
+
+	got, ok := LookupErrorCode("test_user_not_found")
+	require.True(t, ok)
+	assert.Same(t, errUserNotFound, got)
+
+	_, ok = LookupErrorCode("test_unknown")
+	assert.False(t, ok)
+
+	var codes []string
+	for _, err := range ErrorCodes() {
+		codes = append(codes, err.ErrorCode)
+	}
+	assert.Subset(t, codes, []string{"test_account_locked", "test_user_not_found"})
+	assert.IsNonDecreasing(t, codes)
+
+	assert.True(t, errors.Is(errUserNotFound.Wrap(errors.New("no rows")), errUserNotFound))
+	assert.False(t, errors.Is(errUserNotFound, errAccountLocked))
+
+	assert.Panics(t, func() { RegisterErrorCode(http.StatusNotFound, "", "") })
+	assert.Panics(t, func() { RegisterErrorCode(http.StatusNotFound, "test_user_not_found", "") })
+}
+
+func TestDefaultErrorHandler_ErrorCode(t *testing.T) {
+	err := NewHTTPErrorWithCode(http.StatusNotFound, "user_not_found", "User not found").
+		WithDetails(map[string]string{"id": "42"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(HeaderAccept, MIMEApplicationJSON)
+
+	wrapped := &response{}
+	wrapped.reset(w)
+	DefaultErrorHandler(wrapped, r, err.Wrap(errors.New("no rows")))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"code":404,"message":"User not found","error_code":"user_not_found","details":{"id":"42"}}`, w.Body.String())
+}
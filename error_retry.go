@@ -0,0 +1,103 @@
+package keratin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryAfterer can be implemented by errors to make the error handlers
+// ([DefaultErrorHandler], [ProblemDetailsErrorHandler]) set the Retry-After response header.
+type RetryAfterer interface {
+	RetryAfter() time.Duration
+}
+
+// RetryableError is an error with the HTTP status code and the delay after which the client may retry the request,
+// which the error handlers send in the Retry-After header.
+type RetryableError struct {
+	Code  int
+	After time.Duration
+	err   error
+}
+
+// NewRetryableError creates a new instance of RetryableError.
+func NewRetryableError(code int, after time.Duration) *RetryableError {
+	return &RetryableError{
+		Code:  code,
+		After: after,
+	}
+}
+
+// NewTooManyRequestsError creates a 429 Too Many Requests [RetryableError].
+func NewTooManyRequestsError(after time.Duration) *RetryableError {
+	return NewRetryableError(http.StatusTooManyRequests, after)
+}
+
+// NewServiceUnavailableError creates a 503 Service Unavailable [RetryableError].
+func NewServiceUnavailableError(after time.Duration) *RetryableError {
+	return NewRetryableError(http.StatusServiceUnavailable, after)
+}
+
+// StatusCode returns status code for HTTP response
+func (re *RetryableError) StatusCode() int {
+	return re.Code
+}
+
+// RetryAfter returns the delay after which the request may be retried.
+func (re *RetryableError) RetryAfter() time.Duration {
+	return re.After
+}
+
+// Error makes it compatible with an `error` interface.
+func (re *RetryableError) Error() string {
+	if re.err == nil {
+		return fmt.Sprintf("code=%d, message=%s, retry_after=%s", re.Code, http.StatusText(re.Code), re.After)
+	}
+	return fmt.Sprintf("code=%d, message=%s, retry_after=%s, err=%v", re.Code, http.StatusText(re.Code), re.After, re.err)
+}
+
+// Is reports whether target is the predefined error with the same status code, e.g. [ErrTooManyRequests].
+func (re *RetryableError) Is(target error) bool {
+	t, ok := target.(*httpError)
+	return ok && t.code == re.Code
+}
+
+// Wrap returns a new RetryableError with given errors wrapped inside.
+// The message of a wrapped [HTTPError] is used by the error handlers.
+func (re *RetryableError) Wrap(err error) error {
+	return &RetryableError{
+		Code:  re.Code,
+		After: re.After,
+		err:   err,
+	}
+}
+
+func (re *RetryableError) Unwrap() error {
+	return re.err
+}
+
+type retryAfterError interface {
+	error
+	RetryAfterer
+}
+
+// ErrorRetryAfter returns the retry delay of the first [RetryAfterer] in the err chain, or 0 if there is none.
+func ErrorRetryAfter(err error) time.Duration {
+	if retry, ok := errors.AsType[retryAfterError](err); ok {
+		return retry.RetryAfter()
+	}
+	return 0
+}
+
+// setRetryAfter sets the Retry-After header in seconds (rounded up) if err has a positive retry delay.
+func setRetryAfter(h http.Header, err error) {
+	after := ErrorRetryAfter(err)
+	if after <= 0 {
+		return
+	}
+
+	seconds := int64((after + time.Second - 1) / time.Second)
+	h.Set(HeaderRetryAfter, strconv.FormatInt(seconds, 10))
+}
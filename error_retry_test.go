@@ -0,0 +1,141 @@
+package keratin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryableError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       *RetryableError
+		wantCode  int
+		wantIs    error
+		wantError string
+	}{
+		{
+			name:      "too many requests",
+			err:       NewTooManyRequestsError(30 * time.Second),
+			wantCode:  http.StatusTooManyRequests,
+			wantIs:    ErrTooManyRequests,
+			wantError: "code=429, message=Too Many Requests, retry_after=30s",
+		},
+		{
+			name:      "service unavailable",
+			err:       NewServiceUnavailableError(time.Minute),
+			wantCode:  http.StatusServiceUnavailable,
+			wantIs:    ErrServiceUnavailable,
+			wantError: "code=503, message=Service Unavailable, retry_after=1m0s",
+		},
+		{
+			name:      "custom status code",
+			err:       NewRetryableError(http.StatusTooEarly, time.Second),
+			wantCode:  http.StatusTooEarly,
+			wantIs:    ErrTooEarly,
+			wantError: "code=425, message=Too Early, retry_after=1s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantCode, tt.err.StatusCode())
+			assert.Equal(t, tt.wantCode, HTTPErrorStatusCode(tt.err))
+			assert.Equal(t, tt.err.After, ErrorRetryAfter(tt.err))
+			assert.ErrorIs(t, tt.err, tt.wantIs)
+			assert.NotErrorIs(t, tt.err, ErrBadRequest)
+			assert.Equal(t, tt.wantError, tt.err.Error())
+		})
+	}
+}
+
+func TestRetryableError_Wrap(t *testing.T) {
+	cause := NewHTTPError(http.StatusTooManyRequests, "Rate limit exceeded.")
+	err := fmt.Errorf("limiter: %w", NewTooManyRequestsError(10*time.Second).Wrap(cause))
+
+	assert.ErrorIs(t, err, cause)
+	assert.ErrorIs(t, err, ErrTooManyRequests)
+	assert.Equal(t, 10*time.Second, ErrorRetryAfter(err))
+	assert.Equal(t, http.StatusTooManyRequests, HTTPErrorStatusCode(err))
+	assert.Contains(t, err.Error(), "err=code=429, message=Rate limit exceeded.")
+
+	assert.Zero(t, ErrorRetryAfter(cause))
+	assert.Zero(t, ErrorRetryAfter(errors.New("boom")))
+}
+
+func TestErrorHandler_RetryAfter(t *testing.T) {
+	tests := []struct {
+		name            string
+		handler         ErrorHandlerFunc
+		err             error
+		wantRetryAfter  string
+		wantStatus      int
+		wantBodyMessage string
+	}{
+		{
+			name:            "default handler",
+			handler:         DefaultErrorHandler,
+			err:             NewServiceUnavailableError(2 * time.Minute),
+			wantRetryAfter:  "120",
+			wantStatus:      http.StatusServiceUnavailable,
+			wantBodyMessage: "Service Unavailable",
+		},
+		{
+			name:            "default handler keeps the wrapped message",
+			handler:         DefaultErrorHandler,
+			err:             NewTooManyRequestsError(time.Second).Wrap(NewHTTPError(http.StatusTooManyRequests, "slow down")),
+			wantRetryAfter:  "1",
+			wantStatus:      http.StatusTooManyRequests,
+			wantBodyMessage: "slow down",
+		},
+		{
+			name:            "seconds are rounded up",
+			handler:         DefaultErrorHandler,
+			err:             NewTooManyRequestsError(1500 * time.Millisecond),
+			wantRetryAfter:  "2",
+			wantStatus:      http.StatusTooManyRequests,
+			wantBodyMessage: "Too Many Requests",
+		},
+		{
+			name:            "zero delay is not sent",
+			handler:         DefaultErrorHandler,
+			err:             NewTooManyRequestsError(0),
+			wantStatus:      http.StatusTooManyRequests,
+			wantBodyMessage: "Too Many Requests",
+		},
+		{
+			name:            "problem details handler",
+			handler:         ProblemDetailsErrorHandler,
+			err:             NewTooManyRequestsError(time.Minute),
+			wantRetryAfter:  "60",
+			wantStatus:      http.StatusTooManyRequests,
+			wantBodyMessage: "Too Many Requests",
+		},
+		{
+			name:            "other errors",
+			handler:         DefaultErrorHandler,
+			err:             ErrServiceUnavailable,
+			wantStatus:      http.StatusServiceUnavailable,
+			wantBodyMessage: "Service Unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			wrapped := &response{}
+			wrapped.reset(w)
+
+			tt.handler(wrapped, httptest.NewRequest(http.MethodGet, "/", nil), tt.err)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantRetryAfter, w.Header().Get(HeaderRetryAfter))
+			assert.Equal(t, tt.wantBodyMessage+"\n", w.Body.String())
+		})
+	}
+}
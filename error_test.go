@@ -114,6 +114,11 @@ func TestHTTPError_Error(t *testing.T) {
 			err:           &HTTPError{Code: http.StatusConflict, err: errors.New("duplicate key")},
 			expectedError: "code=409, message=Conflict, err=duplicate key",
 		},
+		{
+			name:          "error with error code",
+			err:           NewHTTPErrorWithCode(http.StatusNotFound, "user_not_found", "user not found"),
+			expectedError: "code=404, error_code=user_not_found, message=user not found",
+		},
 	}
 
 	for _, tt := range tests {
@@ -693,3 +698,76 @@ func (m *multiError) Error() string {
 func (m *multiError) Unwrap() []error {
 	return m.errs
 }
+
+func TestPanicError(t *testing.T) {
+	cause := errors.New("cause")
+
+	tests := []struct {
+		name      string
+		value     any
+		wantCause error
+	}{
+		{name: "string value", value: "boom"},
+		{name: "error value", value: cause, wantCause: cause},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ErrInternalServerError.Wrap(&PanicError{Value: tt.value, Stack: []byte("stack")})
+
+			assert.ErrorIs(t, err, ErrPanic)
+			assert.Equal(t, http.StatusInternalServerError, HTTPErrorStatusCode(err))
+			assert.Contains(t, err.Error(), fmt.Sprintf("[PANIC RECOVER] %v stack", tt.value))
+			if tt.wantCause != nil {
+				assert.ErrorIs(t, err, tt.wantCause)
+			}
+		})
+	}
+
+	assert.NotErrorIs(t, ErrInternalServerError.Wrap(cause), ErrPanic)
+}
+
+func TestHTTPError_ErrorCode(t *testing.T) {
+	sentinel := NewHTTPErrorWithCode(http.StatusNotFound, "user_not_found", "user not found")
+
+	tests := []struct {
+		name  string
+		err   error
+		match bool
+	}{
+		{name: "same error", err: sentinel, match: true},
+		{name: "wrapped sentinel", err: sentinel.Wrap(errors.New("no rows")), match: true},
+		{name: "sentinel with details", err: fmt.Errorf("get user: %w", sentinel.WithDetails("42")), match: true},
+		{name: "decoded error with the same code", err: &HTTPError{Code: http.StatusNotFound, ErrorCode: "user_not_found"}, match: true},
+		{name: "different code", err: NewHTTPErrorWithCode(http.StatusNotFound, "order_not_found", "order not found")},
+		{name: "error without code", err: NewHTTPError(http.StatusNotFound, "user not found")},
+		{name: "predefined error", err: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.match, errors.Is(tt.err, sentinel))
+		})
+	}
+
+	t.Run("errors without code do not match", func(t *testing.T) {
+		assert.NotErrorIs(t, NewHTTPError(http.StatusNotFound, ""), NewHTTPError(http.StatusNotFound, ""))
+	})
+
+	t.Run("WithDetails copies the error", func(t *testing.T) {
+		got := sentinel.WithDetails(map[string]string{"id": "42"})
+
+		assert.NotSame(t, sentinel, got)
+		assert.Nil(t, sentinel.Details)
+		assert.Equal(t, map[string]string{"id": "42"}, got.Details)
+		assert.Equal(t, "user_not_found", got.ErrorCode)
+	})
+
+	t.Run("Wrap keeps the code and details", func(t *testing.T) {
+		var got *HTTPError
+		require.ErrorAs(t, sentinel.WithDetails("42").Wrap(errors.New("no rows")), &got)
+
+		assert.Equal(t, "user_not_found", got.ErrorCode)
+		assert.Equal(t, "42", got.Details)
+	})
+}
@@ -0,0 +1,94 @@
+package keratin
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+var (
+	// ErrFlushNotSupported is returned by [http.ResponseController.Flush] of the router response writer
+	// when the underlying writer cannot flush. It matches [http.ErrNotSupported].
+	ErrFlushNotSupported = fmt.Errorf("keratin: response writer does not support flushing: %w", http.ErrNotSupported)
+
+	// ErrHijackNotSupported is returned by the Hijack method of the router response writer
+	// when the underlying writer cannot be hijacked. It matches [http.ErrNotSupported].
+	ErrHijackNotSupported = fmt.Errorf("keratin: response writer does not support hijacking: %w", http.ErrNotSupported)
+)
+
+// FlushPolicy decides what the Flush method of the router response writer does when the underlying
+// writer cannot flush, see [WithFlushPolicy]. The handlers flushing with [http.ResponseController.Flush]
+// always get [ErrFlushNotSupported] instead.
+type FlushPolicy uint8
+
+const (
+	// FlushPanic panics, so a streaming handler behind a non-flushing writer fails loudly. It is the default.
+	FlushPanic FlushPolicy = iota
+
+	// FlushWarn logs a warning with the [WithLoggerInjector] logger (or [slog.Default]) and does nothing.
+	FlushWarn
+
+	// FlushIgnore does nothing.
+	FlushIgnore
+)
+
+// WithFlushPolicy sets what the router response writer does when Flush is called
+// and the underlying writer cannot flush.
+func WithFlushPolicy(policy FlushPolicy) Option {
+	return func(router *Router) {
+		router.flushPolicy = policy
+	}
+}
+
+// ResponseFlushError flushes w with [http.ResponseController] and returns [ErrFlushNotSupported]
+// if w cannot flush. The response writer wrappers call it from their FlushError method.
+func ResponseFlushError(w http.ResponseWriter) error {
+	err := http.NewResponseController(w).Flush()
+	if err != nil && errors.Is(err, http.ErrNotSupported) && !errors.Is(err, ErrFlushNotSupported) {
+		return fmt.Errorf("%w: %T", ErrFlushNotSupported, w)
+	}
+	return err
+}
+
+// ResponseFlush flushes w like [ResponseFlushError]. When w cannot flush, it applies the [FlushPolicy]
+// of the router response writer wrapped by w and does nothing outside the router.
+// The response writer wrappers call it from their Flush method.
+func ResponseFlush(w http.ResponseWriter) {
+	err := ResponseFlushError(w)
+	if err == nil || !errors.Is(err, http.ErrNotSupported) {
+		return
+	}
+	if r := routerResponse(w); r != nil {
+		r.unsupportedFlush(err)
+	}
+}
+
+// routerResponse returns the router response writer wrapped by w, or nil if there is none.
+func routerResponse(w http.ResponseWriter) *response {
+	for {
+		switch t := w.(type) {
+		case *response:
+			return t
+		case RWUnwrapper:
+			w = t.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
+
+// unsupportedFlush applies the flush policy of the response after the underlying writer failed to flush.
+func (r *response) unsupportedFlush(err error) {
+	switch r.flushPolicy {
+	case FlushWarn:
+		logger := r.logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		logger.Warn("response flush is not supported", slog.String("writer", fmt.Sprintf("%T", r.ResponseWriter)), slog.Any("error", err))
+	case FlushIgnore:
+	default:
+		panic(fmt.Errorf("response writer %T does not support flushing (http.Flusher interface)", r.ResponseWriter))
+	}
+}
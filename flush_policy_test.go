@@ -0,0 +1,104 @@
+package keratin
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// plainWriter is a response writer which can neither flush nor be hijacked.
+type plainWriter struct {
+	header http.Header
+	body   bytes.Buffer
+	code   int
+}
+
+func (w *plainWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = make(http.Header)
+	}
+	return w.header
+}
+
+func (w *plainWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *plainWriter) WriteHeader(statusCode int) {
+	w.code = statusCode
+}
+
+func TestResponse_FlushError(t *testing.T) {
+	res := &response{}
+	res.reset(&plainWriter{})
+
+	err := http.NewResponseController(res).Flush()
+	require.ErrorIs(t, err, ErrFlushNotSupported)
+	require.ErrorIs(t, err, http.ErrNotSupported)
+	assert.Contains(t, err.Error(), "*keratin.plainWriter")
+
+	res.reset(httptest.NewRecorder())
+	assert.NoError(t, http.NewResponseController(res).Flush())
+}
+
+func TestResponse_HijackError(t *testing.T) {
+	res := &response{}
+	res.reset(httptest.NewRecorder())
+
+	_, _, err := http.NewResponseController(res).Hijack()
+	require.ErrorIs(t, err, ErrHijackNotSupported)
+	require.ErrorIs(t, err, http.ErrNotSupported)
+}
+
+func TestWithFlushPolicy(t *testing.T) {
+	stream := func(w http.ResponseWriter, _ *http.Request) error {
+		_, _ = w.Write([]byte("data"))
+		w.(http.Flusher).Flush()
+		return nil
+	}
+
+	t.Run("panic", func(t *testing.T) {
+		router := NewRouter()
+		router.GET("/events", stream)
+		h := router.Build()
+
+		assert.PanicsWithError(t, "response writer *keratin.plainWriter does not support flushing (http.Flusher interface)", func() {
+			h.ServeHTTP(&plainWriter{}, httptest.NewRequest(http.MethodGet, "/events", nil))
+		})
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		var buf bytes.Buffer
+		router := NewRouter(
+			WithFlushPolicy(FlushWarn),
+			WithLoggerInjector(slog.New(slog.NewTextHandler(&buf, nil))),
+		)
+		router.GET("/events", stream)
+		h := router.Build()
+
+		w := &plainWriter{}
+		assert.NotPanics(t, func() {
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events", nil))
+		})
+		assert.Equal(t, "data", w.body.String())
+		assert.Contains(t, buf.String(), "level=WARN")
+		assert.Contains(t, buf.String(), `msg="response flush is not supported"`)
+	})
+
+	t.Run("ignore", func(t *testing.T) {
+		router := NewRouter(WithFlushPolicy(FlushIgnore))
+		router.GET("/events", stream)
+		h := router.Build()
+
+		w := &plainWriter{}
+		assert.NotPanics(t, func() {
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events", nil))
+		})
+		assert.Equal(t, "data", w.body.String())
+	})
+}
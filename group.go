@@ -9,6 +9,20 @@ type RouterGroup struct {
 	prefix      string
 	children    []any // Route or Group
 	Middlewares Middlewares[Handler]
+
+	// HTTPMiddlewares are raw [http.Handler] middlewares run for the matched routes of the group
+	// before its Middlewares, see [RouterGroup.UseHTTP].
+	HTTPMiddlewares Middlewares[http.Handler]
+
+	// ResponseInterceptors and RequestInterceptors run for the matched routes of the group,
+	// see [RouterGroup.InterceptResponse] and [RouterGroup.InterceptRequest].
+	ResponseInterceptors Interceptors[http.ResponseWriter]
+	RequestInterceptors  Interceptors[*http.Request]
+
+	ErrorTranslators ErrorTranslators
+
+	// Limits are the group routes timeout and body size limit, see [RouterGroup.Timeout] and [RouterGroup.MaxBody].
+	Limits Limits
 }
 
 // Group creates and register a new child RouterGroup into the current one
@@ -51,6 +65,37 @@ func (group *RouterGroup) Use(middlewares ...*Middleware[Handler]) *RouterGroup
 	return group
 }
 
+// UseHTTPFunc registers one or multiple raw HTTP middleware functions to the current group.
+//
+// Unlike [Router.PreHTTPFunc] they only run for the routes of the group (and its subgroups),
+// after the route has been matched and before the group and route Middlewares.
+// Errors of the inner handlers are not visible to them and are handled by the router error handler.
+func (group *RouterGroup) UseHTTPFunc(middlewareFuncs ...func(http.Handler) http.Handler) *RouterGroup {
+	for _, mdw := range middlewareFuncs {
+		group.HTTPMiddlewares = append(group.HTTPMiddlewares, &Middleware[http.Handler]{Func: mdw})
+	}
+
+	return group
+}
+
+// UseHTTP registers one or multiple raw HTTP middleware handlers to the current group.
+func (group *RouterGroup) UseHTTP(middlewares ...*Middleware[http.Handler]) *RouterGroup {
+	group.HTTPMiddlewares = append(group.HTTPMiddlewares, middlewares...)
+
+	return group
+}
+
+// OnError registers one or multiple error translators to the current group.
+//
+// Group error translators apply to all routes of the group and its subgroups.
+// They run after the route and subgroup ones, in the order they were registered.
+// Like the route ones, they run inside the middlewares.
+func (group *RouterGroup) OnError(translators ...ErrorTranslatorFunc) *RouterGroup {
+	group.ErrorTranslators = append(group.ErrorTranslators, translators...)
+
+	return group
+}
+
 // Route registers a single route into the current group.
 //
 // Note that the final route path will be the concatenation of all parent groups prefixes + the route path.
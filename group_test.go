@@ -807,3 +807,27 @@ func TestRouterGroup_Use_NamedMiddlewares(t *testing.T) {
 	assert.Equal(t, "auth", group.Middlewares[0].ID)
 	assert.Equal(t, "logger", group.Middlewares[1].ID)
 }
+
+func TestRouterGroup_OnError(t *testing.T) {
+	group := &RouterGroup{}
+
+	et := func(w http.ResponseWriter, r *http.Request, err error) error { return err }
+
+	result := group.OnError(et, et)
+
+	assert.Same(t, group, result)
+	assert.Len(t, group.ErrorTranslators, 2)
+}
+
+func TestRouterGroup_UseHTTP(t *testing.T) {
+	group := new(RouterGroup)
+	mw := &Middleware[http.Handler]{ID: "raw", Func: func(next http.Handler) http.Handler { return next }}
+
+	assert.Same(t, group, group.UseHTTP(mw))
+	assert.Same(t, group, group.UseHTTPFunc(func(next http.Handler) http.Handler { return next }))
+
+	require.Len(t, group.HTTPMiddlewares, 2)
+	assert.Same(t, mw, group.HTTPMiddlewares[0])
+	assert.Empty(t, group.HTTPMiddlewares[1].ID)
+	assert.NotNil(t, group.HTTPMiddlewares[1].Func)
+}
@@ -0,0 +1,58 @@
+package keratin
+
+import (
+	"net/http"
+	"strings"
+)
+
+// MIMEApplicationGRPC is the gRPC content type, it may have a "+proto" or "+json" suffix.
+const MIMEApplicationGRPC = "application/grpc"
+
+// IsGRPC reports whether the request is a gRPC request, aka. an HTTP/2 request with
+// the "application/grpc" (or "application/grpc+...") content type.
+func IsGRPC(r *http.Request) bool {
+	if r.ProtoMajor != 2 {
+		return false
+	}
+
+	contentType := r.Header.Get(HeaderContentType)
+	if !strings.HasPrefix(contentType, MIMEApplicationGRPC) {
+		return false
+	}
+
+	rest := contentType[len(MIMEApplicationGRPC):]
+	return rest == "" || rest[0] == '+' || rest[0] == ';'
+}
+
+// SplitGRPC returns a handler which passes the gRPC requests (see [IsGRPC]) to grpcHandler,
+// e.g. a *grpc.Server, and all other requests to handler.
+func SplitGRPC(grpcHandler, handler http.Handler) http.Handler {
+	if grpcHandler == nil {
+		panic("keratin: grpc handler is required")
+	}
+	if handler == nil {
+		panic("keratin: handler is required")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IsGRPC(r) {
+			grpcHandler.ServeHTTP(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// BuildH2C builds the router and returns a handler serving gRPC and REST requests on the same port:
+// gRPC requests are passed to grpcHandler (e.g. a *grpc.Server) bypassing the router,
+// all other requests are handled by the router routes.
+//
+// gRPC requires HTTP/2, so without TLS the server must accept unencrypted HTTP/2 (h2c),
+// e.g. the keratin server does, or with the standard library:
+//
+//	srv := &http.Server{Handler: router.BuildH2C(grpcServer), Protocols: new(http.Protocols)}
+//	srv.Protocols.SetHTTP1(true)
+//	srv.Protocols.SetUnencryptedHTTP2(true)
+func (r *Router) BuildH2C(grpcHandler http.Handler) http.Handler {
+	return SplitGRPC(grpcHandler, r.Build())
+}
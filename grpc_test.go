@@ -0,0 +1,71 @@
+package keratin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsGRPC(t *testing.T) {
+	tests := []struct {
+		name        string
+		protoMajor  int
+		contentType string
+		want        bool
+	}{
+		{name: "grpc", protoMajor: 2, contentType: "application/grpc", want: true},
+		{name: "grpc proto", protoMajor: 2, contentType: "application/grpc+proto", want: true},
+		{name: "grpc with params", protoMajor: 2, contentType: "application/grpc; charset=utf-8", want: true},
+		{name: "grpc web", protoMajor: 2, contentType: "application/grpc-web"},
+		{name: "http/1.1", protoMajor: 1, contentType: "application/grpc"},
+		{name: "json", protoMajor: 2, contentType: MIMEApplicationJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.ProtoMajor = tt.protoMajor
+			r.Header.Set(HeaderContentType, tt.contentType)
+
+			assert.Equal(t, tt.want, IsGRPC(r))
+		})
+	}
+}
+
+func TestRouter_BuildH2C(t *testing.T) {
+	grpcHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, MIMEApplicationGRPC)
+		w.Header().Set("Grpc-Status", "0")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	router := NewRouter()
+	router.POST("/greeter.Greeter/SayHello", func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, "rest")
+	})
+
+	handler := router.BuildH2C(grpcHandler)
+
+	r := httptest.NewRequest(http.MethodPost, "/greeter.Greeter/SayHello", nil)
+	r.ProtoMajor = 2
+	r.Header.Set(HeaderContentType, "application/grpc+proto")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, "0", w.Header().Get("Grpc-Status"))
+	assert.Empty(t, w.Body.String())
+
+	r = httptest.NewRequest(http.MethodPost, "/greeter.Greeter/SayHello", nil)
+	r.ProtoMajor = 2
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, "rest", w.Body.String())
+}
+
+func TestSplitGRPC_Panics(t *testing.T) {
+	assert.Panics(t, func() { SplitGRPC(nil, http.NotFoundHandler()) })
+	assert.Panics(t, func() { SplitGRPC(http.NotFoundHandler(), nil) })
+}
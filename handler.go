@@ -2,8 +2,8 @@ package keratin
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
-	"strings"
 )
 
 type Handler interface {
@@ -18,23 +18,82 @@ func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
 
 type ErrorHandlerFunc func(http.ResponseWriter, *http.Request, error)
 
+// ErrorTranslatorFunc handles or translates an error returned by a route handler
+// before it reaches the router-wide [ErrorHandlerFunc].
+//
+// Returning nil marks the error as handled, returning an error (the same or a different one)
+// passes it further up the chain.
+type ErrorTranslatorFunc func(http.ResponseWriter, *http.Request, error) error
+
+type ErrorTranslators []ErrorTranslatorFunc
+
+func (ets ErrorTranslators) build(handler Handler) Handler {
+	for _, et := range ets {
+		next := handler
+		handler = HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if err := next.ServeHTTP(w, r); err != nil {
+				return et(w, r, err)
+			}
+			return nil
+		})
+	}
+	return handler
+}
+
+// DefaultErrorHandler responds with the status code and the message of the error as JSON or plain text,
+// and sets the Retry-After header for the errors implementing [RetryAfterer].
+// The message is translated with the request [Translator], if any (see [SetTranslator]).
+// The panic values and stack traces of the recovered panics are never exposed.
 func DefaultErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	writeError(w, r, err, false)
+}
+
+// DevErrorHandler is the [ErrorHandlerFunc] for development, which unlike [DefaultErrorHandler]
+// includes the value and the stack trace of the recovered panics (see [PanicError]) in the responses.
+// It must not be used in production.
+func DevErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	writeError(w, r, err, true)
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error, dev bool) {
 	if ResponseCommitted(w) {
 		return
 	}
 
+	setRetryAfter(w.Header(), err)
+
 	code := HTTPErrorStatusCode(err)
 
 	httpErr, ok := errors.AsType[*HTTPError](err)
 	if !ok {
 		httpErr = NewHTTPError(code, http.StatusText(code))
+		if code == StatusClientClosedRequest {
+			httpErr = ErrClientClosedRequest
+		}
 	}
+	httpErr = translateError(r, httpErr)
 
-	if strings.Contains(r.Header.Get(HeaderAccept), MIMEApplicationJSON) {
-		if err := JSON(w, code, httpErr); err == nil || ResponseCommitted(w) {
+	var panicErr *PanicError
+	if dev {
+		panicErr, _ = errors.AsType[*PanicError](err)
+	}
+
+	if NegotiateContentType(r, MIMETextPlain, MIMEApplicationJSON) == MIMEApplicationJSON {
+		body := httpErr
+		if panicErr != nil {
+			body = NewHTTPError(httpErr.Code, httpErr.Message).SetData(map[string]string{
+				"panic": fmt.Sprint(panicErr.Value),
+				"stack": string(panicErr.Stack),
+			})
+		}
+		if err := JSON(w, code, body); err == nil || ResponseCommitted(w) {
 			return
 		}
 	}
 
-	http.Error(w, httpErr.Message, code)
+	message := httpErr.Message
+	if panicErr != nil {
+		message = fmt.Sprintf("%s\n\npanic: %v\n\n%s", message, panicErr.Value, panicErr.Stack)
+	}
+	http.Error(w, message, code)
 }
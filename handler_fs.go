@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 const (
@@ -104,9 +105,86 @@ func FileFS(fsys fs.FS, filename string, beforeServe ...BeforeServeFunc) Handler
 			bs(w, r, f, fi)
 		}
 
-		http.ServeContent(w, r, fi.Name(), fi.ModTime(), frs)
+		return ServeContent(w, r, fi.Name(), fi.ModTime(), frs)
+	}
+}
+
+// ServeFile replies to the request with the contents of the named file or directory
+// like [http.ServeFile], but returns failures (missing file, invalid range, ...)
+// as errors instead of writing plain-text error responses.
+func ServeFile(w http.ResponseWriter, r *http.Request, name string) error {
+	sw := &serveWriter{ResponseWriter: w}
+	w.Header().Set(HeaderAcceptRanges, "bytes")
+
+	http.ServeFile(sw, r, name)
+
+	return sw.error()
+}
+
+// ServeContent replies to the request using the content in the provided ReadSeeker
+// like [http.ServeContent], including Range, If-Match, If-None-Match and If-Modified-Since handling,
+// but returns failures (e.g. 416 for an unsatisfiable range) as errors
+// instead of writing plain-text error responses.
+func ServeContent(w http.ResponseWriter, r *http.Request, name string, modtime time.Time, content io.ReadSeeker) error {
+	sw := &serveWriter{ResponseWriter: w}
+	w.Header().Set(HeaderAcceptRanges, "bytes")
+
+	http.ServeContent(sw, r, name, modtime, content)
+
+	return sw.error()
+}
+
+// serveWriter swallows the plain-text error responses written by the stdlib file serving functions
+// and records their status code instead.
+type serveWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+func (w *serveWriter) WriteHeader(statusCode int) {
+	if statusCode >= http.StatusBadRequest {
+		w.code = statusCode
+
+		// undo the headers set by http.Error so the error handler can write its own response
+		h := w.ResponseWriter.Header()
+		h.Del(HeaderContentType)
+		h.Del(HeaderXContentTypeOptions)
+		h.Del(HeaderAcceptRanges)
+		return
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *serveWriter) Write(b []byte) (int, error) {
+	if w.code != 0 {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *serveWriter) ReadFrom(reader io.Reader) (int64, error) {
+	if w.code != 0 {
+		return io.Copy(io.Discard, reader)
+	}
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		return rf.ReadFrom(reader)
+	}
+	return io.Copy(w.ResponseWriter, reader)
+}
+
+func (w *serveWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
 
+func (w *serveWriter) error() error {
+	switch w.code {
+	case 0:
 		return nil
+	case http.StatusNotFound:
+		return ErrFileNotFound
+	default:
+		return NewHTTPError(w.code, http.StatusText(w.code))
 	}
 }
 
@@ -7,7 +7,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -648,3 +650,93 @@ func TestFileFS_ReadSeeker(t *testing.T) {
 		assert.NotEmpty(t, w.Body.String())
 	})
 }
+
+func TestServeContent(t *testing.T) {
+	modtime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		header    map[string]string
+		wantErr   int
+		wantCode  int
+		wantBody  string
+		wantRange string
+	}{
+		{
+			name:     "full content",
+			wantCode: http.StatusOK,
+			wantBody: "hello world",
+		},
+		{
+			name:      "partial content",
+			header:    map[string]string{HeaderRange: "bytes=0-4"},
+			wantCode:  http.StatusPartialContent,
+			wantBody:  "hello",
+			wantRange: "bytes 0-4/11",
+		},
+		{
+			name:     "not modified",
+			header:   map[string]string{HeaderIfModifiedSince: modtime.Format(http.TimeFormat)},
+			wantCode: http.StatusNotModified,
+		},
+		{
+			name:      "unsatisfiable range",
+			header:    map[string]string{HeaderRange: "bytes=100-200"},
+			wantErr:   http.StatusRequestedRangeNotSatisfiable,
+			wantRange: "bytes */11",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+
+			rec := httptest.NewRecorder()
+			res := &response{}
+			res.reset(rec)
+
+			err := ServeContent(res, r, "hello.txt", modtime, strings.NewReader("hello world"))
+
+			assert.Equal(t, tt.wantRange, rec.Header().Get(HeaderContentRange))
+
+			if tt.wantErr != 0 {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, HTTPErrorStatusCode(err))
+				assert.False(t, res.Committed())
+				assert.Empty(t, rec.Header().Get(HeaderContentType))
+				assert.Empty(t, rec.Body.String())
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCode, res.StatusCode())
+			assert.Equal(t, int64(len(tt.wantBody)), res.Size())
+			assert.Equal(t, tt.wantBody, rec.Body.String())
+			assert.Equal(t, "bytes", rec.Header().Get(HeaderAcceptRanges))
+		})
+	}
+}
+
+func TestServeFile(t *testing.T) {
+	t.Run("existing file", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/router.go", nil)
+		r.Header.Set(HeaderRange, "bytes=0-6")
+
+		require.NoError(t, ServeFile(w, r, "router.go"))
+		assert.Equal(t, http.StatusPartialContent, w.Code)
+		assert.Equal(t, "package", w.Body.String())
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/missing.go", nil)
+
+		err := ServeFile(w, r, "missing.go")
+		assert.ErrorIs(t, err, ErrFileNotFound)
+		assert.Empty(t, w.Body.String())
+	})
+}
@@ -300,6 +300,69 @@ func TestDefaultErrorHandler_HTTPErrorResponse(t *testing.T) {
 	}
 }
 
+func TestErrorHandler_Panic(t *testing.T) {
+	err := ErrInternalServerError.Wrap(&PanicError{Value: "boom", Stack: []byte("goroutine 1 [running]:")})
+
+	tests := []struct {
+		name         string
+		handler      ErrorHandlerFunc
+		acceptHeader string
+		expectedBody string
+	}{
+		{
+			name:         "default handler redacts panic as JSON",
+			handler:      DefaultErrorHandler,
+			acceptHeader: MIMEApplicationJSON,
+			expectedBody: "{\"code\":500,\"message\":\"Internal Server Error\"}\n",
+		},
+		{
+			name:         "default handler redacts panic as text",
+			handler:      DefaultErrorHandler,
+			expectedBody: "Internal Server Error\n",
+		},
+		{
+			name:         "dev handler includes panic as JSON",
+			handler:      DevErrorHandler,
+			acceptHeader: MIMEApplicationJSON,
+			expectedBody: "{\"code\":500,\"message\":\"Internal Server Error\",\"data\":{\"panic\":\"boom\",\"stack\":\"goroutine 1 [running]:\"}}\n",
+		},
+		{
+			name:         "dev handler includes panic as text",
+			handler:      DevErrorHandler,
+			expectedBody: "Internal Server Error\n\npanic: boom\n\ngoroutine 1 [running]:\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.acceptHeader != "" {
+				r.Header.Set(HeaderAccept, tt.acceptHeader)
+			}
+
+			wrapped := &response{}
+			wrapped.reset(w)
+			tt.handler(wrapped, r, err)
+
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+
+	t.Run("dev handler without panic", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		wrapped := &response{}
+		wrapped.reset(w)
+		DevErrorHandler(wrapped, r, ErrNotFound)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "Not Found\n", w.Body.String())
+	})
+}
+
 func TestDefaultErrorHandler_VariousErrorTypes(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -491,12 +554,12 @@ func TestDefaultErrorHandler_CaseInsensitiveAccept(t *testing.T) {
 		{
 			name:         "uppercase APPLICATION/JSON",
 			acceptHeader: "APPLICATION/JSON",
-			expectJSON:   false,
+			expectJSON:   true,
 		},
 		{
 			name:         "mixed case Application/Json",
 			acceptHeader: "Application/Json",
-			expectJSON:   false,
+			expectJSON:   true,
 		},
 		{
 			name:         "application/json with charset",
@@ -571,8 +634,24 @@ func TestDefaultErrorHandler_AcceptHeaderValues(t *testing.T) {
 		},
 		{
 			name:         "multiple accepts with json last",
+			acceptHeader: "text/html, application/json",
+			err:          ErrBadRequest,
+			checkJSON: func(t *testing.T, body string) {
+				assert.JSONEq(t, `{"code":400,"message":"Bad Request"}`, body)
+			},
+		},
+		{
+			name:         "equally preferred text and json",
 			acceptHeader: "text/html, text/plain, application/json",
 			err:          ErrBadRequest,
+			checkText: func(t *testing.T, body string) {
+				assert.Equal(t, "Bad Request\n", body)
+			},
+		},
+		{
+			name:         "json preferred by quality",
+			acceptHeader: "text/plain;q=0.5, application/json",
+			err:          ErrBadRequest,
 			checkJSON: func(t *testing.T, body string) {
 				assert.JSONEq(t, `{"code":400,"message":"Bad Request"}`, body)
 			},
@@ -581,8 +660,16 @@ func TestDefaultErrorHandler_AcceptHeaderValues(t *testing.T) {
 			name:         "json with wildcards",
 			acceptHeader: "application/*",
 			err:          ErrForbidden,
-			checkText: func(t *testing.T, body string) {
-				assert.Equal(t, "Forbidden\n", body)
+			checkJSON: func(t *testing.T, body string) {
+				assert.JSONEq(t, `{"code":403,"message":"Forbidden"}`, body)
+			},
+		},
+		{
+			name:         "json more specific than wildcard",
+			acceptHeader: "*/*;q=0.8, application/json",
+			err:          ErrForbidden,
+			checkJSON: func(t *testing.T, body string) {
+				assert.JSONEq(t, `{"code":403,"message":"Forbidden"}`, body)
 			},
 		},
 		{
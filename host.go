@@ -0,0 +1,126 @@
+package keratin
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// HostWildcard is the host label wildcard of [Router.HostGroup] matching the tenant.
+const HostWildcard = "{tenant}"
+
+// HostGroup creates and registers a new child RouterGroup matching the requests by their host.
+//
+// The pattern has the "HOST[/PATH]" format, where one of the HOST labels may be the [HostWildcard]
+// matching a single label of the request host, e.g. "{tenant}.example.com" or "{tenant}.example.com/api".
+// The matched label is stored in the request [Context], see [TenantFromContext].
+// A pattern without the wildcard is the same as [RouterGroup.Group].
+//
+// The routes of a wildcard group are registered without the host, so the routes with a literal host
+// (e.g. of the "admin.example.com" group) take precedence over them, but they must not overlap
+// with the routes without a host. The requests to other hosts are rejected with 404 Not Found.
+func (r *Router) HostGroup(pattern string) *RouterGroup {
+	host, path, _ := strings.Cut(pattern, "/")
+	if !strings.Contains(host, "{") {
+		return r.Group(pattern)
+	}
+
+	matcher := parseHostPattern(host)
+
+	var prefix string
+	if path != "" {
+		prefix = "/" + path
+	}
+
+	group := r.Group(prefix)
+	group.Use(&Middleware[Handler]{
+		ID:       "keratin.host",
+		Priority: math.MinInt,
+		Func: func(next Handler) Handler {
+			return HandlerFunc(func(w http.ResponseWriter, req *http.Request) error {
+				tenant, ok := matcher.match(req.Host)
+				if !ok {
+					return ErrNotFound
+				}
+
+				SetTenant(req.Context(), tenant)
+
+				return next.ServeHTTP(w, req)
+			})
+		},
+	})
+
+	return group
+}
+
+// hostPattern holds the lowercase host labels, the wildcard label is at index wildcard.
+type hostPattern struct {
+	labels   []string
+	wildcard int
+}
+
+func parseHostPattern(host string) hostPattern {
+	p := hostPattern{
+		labels:   strings.Split(strings.ToLower(strings.TrimSuffix(host, ".")), "."),
+		wildcard: -1,
+	}
+
+	for i, label := range p.labels {
+		switch {
+		case label == HostWildcard:
+			if p.wildcard != -1 {
+				panic(fmt.Sprintf("keratin: host pattern %q: multiple %s wildcards", host, HostWildcard))
+			}
+			p.wildcard = i
+		case label == "" || strings.ContainsAny(label, "{}"):
+			panic(fmt.Sprintf("keratin: host pattern %q: invalid label %q", host, label))
+		}
+	}
+
+	return p
+}
+
+// match reports whether host (with an optional port) matches the pattern and returns the wildcard label.
+func (p hostPattern) match(host string) (string, bool) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(host, ".")
+
+	var tenant string
+	for i, label := range p.labels {
+		var part string
+		part, host, _ = strings.Cut(host, ".")
+
+		if i == p.wildcard {
+			if part == "" {
+				return "", false
+			}
+			tenant = strings.ToLower(part)
+		} else if !strings.EqualFold(part, label) {
+			return "", false
+		}
+	}
+
+	if host != "" {
+		return "", false
+	}
+	return tenant, true
+}
+
+type tenantKey struct{}
+
+// SetTenant stores the tenant in the request [Context], e.g. for a tenant resolved by a custom middleware.
+func SetTenant(ctx context.Context, tenant string) {
+	SetContextValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant of the request matched by [Router.HostGroup]
+// (or set with [SetTenant]), "" if there is none.
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ContextValue[string](ctx, tenantKey{})
+	return tenant
+}
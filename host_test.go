@@ -0,0 +1,102 @@
+package keratin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter_HostGroup(t *testing.T) {
+	router := NewRouter()
+
+	tenants := router.HostGroup("{tenant}.example.com")
+	tenants.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, "home:"+TenantFromContext(r.Context()))
+	})
+
+	api := router.HostGroup("api.{tenant}.example.com/v1")
+	api.GET("/users", func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, "users:"+TenantFromContext(r.Context()))
+	})
+
+	admin := router.HostGroup("admin.example.com")
+	admin.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, "admin:"+TenantFromContext(r.Context()))
+	})
+
+	handler := router.Build()
+
+	tests := []struct {
+		name       string
+		host       string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "tenant", host: "acme.example.com", path: "/", wantStatus: http.StatusOK, wantBody: "home:acme"},
+		{name: "tenant with port", host: "acme.example.com:8080", path: "/", wantStatus: http.StatusOK, wantBody: "home:acme"},
+		{name: "tenant is lowercased", host: "ACME.Example.com", path: "/", wantStatus: http.StatusOK, wantBody: "home:acme"},
+		{name: "literal host takes precedence", host: "admin.example.com", path: "/", wantStatus: http.StatusOK, wantBody: "admin:"},
+		{name: "wildcard in the middle with path prefix", host: "api.globex.example.com", path: "/v1/users", wantStatus: http.StatusOK, wantBody: "users:globex"},
+		{name: "nested subdomain does not match", host: "a.b.example.com", path: "/", wantStatus: http.StatusNotFound, wantBody: "Not Found\n"},
+		{name: "apex domain does not match", host: "example.com", path: "/", wantStatus: http.StatusNotFound, wantBody: "Not Found\n"},
+		{name: "other domain does not match", host: "acme.example.org", path: "/", wantStatus: http.StatusNotFound, wantBody: "Not Found\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Host = tt.host
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantBody, rec.Body.String())
+		})
+	}
+}
+
+func TestParseHostPattern(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		host      string
+		wantMatch bool
+		want      string
+	}{
+		{pattern: "{tenant}.example.com", host: "acme.example.com", wantMatch: true, want: "acme"},
+		{pattern: "{tenant}.example.com.", host: "acme.example.com.", wantMatch: true, want: "acme"},
+		{pattern: "{tenant}.Example.com", host: "acme.example.COM:443", wantMatch: true, want: "acme"},
+		{pattern: "{tenant}.example.com", host: ".example.com"},
+		{pattern: "{tenant}.example.com", host: "acme.example.com.evil.com"},
+		{pattern: "shop.{tenant}", host: "shop.acme", wantMatch: true, want: "acme"},
+		{pattern: "shop.{tenant}", host: "shop"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.host, func(t *testing.T) {
+			got, ok := parseHostPattern(tt.pattern).match(tt.host)
+			assert.Equal(t, tt.wantMatch, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, pattern := range []string{"{tenant}.{tenant}.com", "{id}.example.com", "a..example.com", "{tenant}x.example.com"} {
+		t.Run("invalid "+pattern, func(t *testing.T) {
+			assert.Panics(t, func() { parseHostPattern(pattern) })
+		})
+	}
+}
+
+func TestTenantFromContext(t *testing.T) {
+	router := NewRouter()
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		assert.Empty(t, TenantFromContext(r.Context()))
+		SetTenant(r.Context(), "acme")
+		assert.Equal(t, "acme", TenantFromContext(r.Context()))
+		return nil
+	})
+
+	router.Build().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,132 @@
+package keratin
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// MessageCatalog provides the translations of the messages, e.g. of the error messages
+// rendered by [DefaultErrorHandler] and [ProblemDetailsErrorHandler].
+type MessageCatalog interface {
+	// Message returns the message of key in locale and reports whether it was found.
+	Message(locale, key string) (string, bool)
+}
+
+// MessageCatalogFunc is an adapter to allow the use of ordinary functions as [MessageCatalog].
+type MessageCatalogFunc func(locale, key string) (string, bool)
+
+func (f MessageCatalogFunc) Message(locale, key string) (string, bool) {
+	return f(locale, key)
+}
+
+// Messages is an in-memory [MessageCatalog] of the messages by locale and key:
+//
+//	keratin.Messages{
+//		"de": {"Not Found": "Nicht gefunden", "user_not_found": "Benutzer nicht gefunden"},
+//	}
+type Messages map[string]map[string]string
+
+func (m Messages) Message(locale, key string) (string, bool) {
+	msg, ok := m[locale][key]
+	return msg, ok
+}
+
+// Translator translates the messages into the first locale of its fallback chain having a translation.
+type Translator struct {
+	Catalog MessageCatalog
+	// Locales is the fallback chain of the locales, e.g. ["de-CH", "de", "en"].
+	Locales []string
+}
+
+// NewTranslator creates a new instance of Translator.
+func NewTranslator(catalog MessageCatalog, locales ...string) *Translator {
+	return &Translator{
+		Catalog: catalog,
+		Locales: locales,
+	}
+}
+
+// Locale returns the preferred locale, "" if there is none.
+func (t *Translator) Locale() string {
+	if len(t.Locales) == 0 {
+		return ""
+	}
+	return t.Locales[0]
+}
+
+// Lookup returns the message of key in the first locale having it and reports whether it was found.
+func (t *Translator) Lookup(key string) (string, bool) {
+	if t.Catalog == nil {
+		return "", false
+	}
+	for _, locale := range t.Locales {
+		if msg, ok := t.Catalog.Message(locale, key); ok {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// Translate returns the message of key, or key itself if there is no translation.
+func (t *Translator) Translate(key string) string {
+	if msg, ok := t.Lookup(key); ok {
+		return msg
+	}
+	return key
+}
+
+// LocaleFallbacks returns the locale followed by its parent locales, e.g. "zh-Hant-TW" -> ["zh-Hant-TW", "zh-Hant", "zh"].
+func LocaleFallbacks(locale string) []string {
+	if locale == "" {
+		return nil
+	}
+
+	locales := []string{locale}
+	for {
+		i := strings.LastIndexAny(locale, "-_")
+		if i <= 0 {
+			return locales
+		}
+		locale = locale[:i]
+		locales = append(locales, locale)
+	}
+}
+
+type translatorKey struct{}
+
+// SetTranslator stores the translator used by the error handlers in the request [Context],
+// see the i18n middleware.
+func SetTranslator(ctx context.Context, t *Translator) {
+	SetContextValue(ctx, translatorKey{}, t)
+}
+
+// TranslatorFromContext returns the translator of the request, nil if there is none.
+func TranslatorFromContext(ctx context.Context) *Translator {
+	t, _ := ContextValue[*Translator](ctx, translatorKey{})
+	return t
+}
+
+// translateError returns a copy of httpErr with the message translated with the request translator.
+// The ErrorCode is looked up first, then the message.
+func translateError(r *http.Request, httpErr *HTTPError) *HTTPError {
+	t := TranslatorFromContext(r.Context())
+	if t == nil {
+		return httpErr
+	}
+
+	msg, ok := "", false
+	if httpErr.ErrorCode != "" {
+		msg, ok = t.Lookup(httpErr.ErrorCode)
+	}
+	if !ok && httpErr.Message != "" {
+		msg, ok = t.Lookup(httpErr.Message)
+	}
+	if !ok || msg == httpErr.Message {
+		return httpErr
+	}
+
+	cp := *httpErr
+	cp.Message = msg
+	return &cp
+}
@@ -0,0 +1,148 @@
+package keratin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testMessages = Messages{
+	"de":    {"Not Found": "Nicht gefunden", "user_not_found": "Benutzer nicht gefunden"},
+	"de-CH": {"Not Found": "Nöd gfunde"},
+	"en":    {"user_not_found": "User not found"},
+}
+
+func TestLocaleFallbacks(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   []string
+	}{
+		{locale: "", want: nil},
+		{locale: "en", want: []string{"en"}},
+		{locale: "de-CH", want: []string{"de-CH", "de"}},
+		{locale: "zh-Hant-TW", want: []string{"zh-Hant-TW", "zh-Hant", "zh"}},
+		{locale: "pt_BR", want: []string{"pt_BR", "pt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			assert.Equal(t, tt.want, LocaleFallbacks(tt.locale))
+		})
+	}
+}
+
+func TestTranslator(t *testing.T) {
+	tests := []struct {
+		name    string
+		locales []string
+		key     string
+		want    string
+		wantOK  bool
+	}{
+		{name: "first locale", locales: []string{"de-CH", "de", "en"}, key: "Not Found", want: "Nöd gfunde", wantOK: true},
+		{name: "parent locale", locales: []string{"de-CH", "de", "en"}, key: "user_not_found", want: "Benutzer nicht gefunden", wantOK: true},
+		{name: "default locale", locales: []string{"fr", "en"}, key: "user_not_found", want: "User not found", wantOK: true},
+		{name: "missing translation", locales: []string{"de", "en"}, key: "Gone", want: "Gone"},
+		{name: "no locales", key: "Not Found", want: "Not Found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translator := NewTranslator(testMessages, tt.locales...)
+
+			msg, ok := translator.Lookup(tt.key)
+			assert.Equal(t, tt.wantOK, ok)
+			if ok {
+				assert.Equal(t, tt.want, msg)
+			}
+			assert.Equal(t, tt.want, translator.Translate(tt.key))
+		})
+	}
+
+	assert.Equal(t, "de", NewTranslator(testMessages, "de", "en").Locale())
+	assert.Empty(t, NewTranslator(testMessages).Locale())
+	assert.Equal(t, "Not Found", NewTranslator(nil, "de").Translate("Not Found"))
+
+	catalog := MessageCatalogFunc(func(locale, key string) (string, bool) { return locale + ":" + key, true })
+	assert.Equal(t, "fr:Gone", NewTranslator(catalog, "fr").Translate("Gone"))
+}
+
+func TestErrorHandler_Translation(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler ErrorHandlerFunc
+		locales []string
+		err     error
+		want    string
+	}{
+		{
+			name:    "predefined error",
+			handler: DefaultErrorHandler,
+			locales: []string{"de", "en"},
+			err:     ErrNotFound,
+			want:    "Nicht gefunden\n",
+		},
+		{
+			name:    "error code is looked up first",
+			handler: DefaultErrorHandler,
+			locales: []string{"de", "en"},
+			err:     NewHTTPErrorWithCode(http.StatusNotFound, "user_not_found", "user 42 not found"),
+			want:    "Benutzer nicht gefunden\n",
+		},
+		{
+			name:    "untranslated message",
+			handler: DefaultErrorHandler,
+			locales: []string{"de", "en"},
+			err:     NewHTTPError(http.StatusNotFound, "no such page"),
+			want:    "no such page\n",
+		},
+		{
+			name:    "without translator",
+			handler: DefaultErrorHandler,
+			err:     ErrNotFound,
+			want:    "Not Found\n",
+		},
+		{
+			name:    "problem details",
+			handler: ProblemDetailsErrorHandler,
+			locales: []string{"de-CH", "de", "en"},
+			err:     ErrNotFound,
+			want:    "Nöd gfunde\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := NewRouter(WithErrorHandler(tt.handler))
+			router.GET("/", func(_ http.ResponseWriter, r *http.Request) error {
+				if tt.locales != nil {
+					SetTranslator(r.Context(), NewTranslator(testMessages, tt.locales...))
+				}
+				return tt.err
+			})
+
+			rec := httptest.NewRecorder()
+			router.Build().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+			assert.Equal(t, tt.want, rec.Body.String())
+		})
+	}
+}
+
+func TestNewProblemDetails_Translation(t *testing.T) {
+	router := NewRouter()
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		SetTranslator(r.Context(), NewTranslator(testMessages, "de", "en"))
+
+		p := NewProblemDetails(r, NewHTTPErrorWithCode(http.StatusNotFound, "user_not_found", "user 42 not found"))
+		assert.Equal(t, "Nicht gefunden", p.Title)
+		assert.Equal(t, "Benutzer nicht gefunden", p.Detail)
+		assert.Equal(t, "user_not_found", p.Extensions["error_code"])
+		return nil
+	})
+
+	router.Build().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,93 @@
+package keratin
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+
+	"github.com/gowool/keratin/internal"
+)
+
+// Interceptor replaces the response writer (T is [http.ResponseWriter]) or the request
+// (T is *[http.Request]) before the handlers run. The returned func, if not nil,
+// is called after the request has been handled.
+//
+// Interceptors run in the ascending Priority order (and in the registration order for equal priorities),
+// the returned funcs in the reverse order.
+type Interceptor[T any] struct {
+	ID       string
+	Priority int
+	Func     func(T) (T, func())
+}
+
+type Interceptors[T any] []*Interceptor[T]
+
+func (its Interceptors[T]) build() internal.Interceptors[T] {
+	if len(its) == 0 {
+		return nil
+	}
+
+	sorted := slices.Clone(its)
+	slices.SortStableFunc(sorted, func(a, b *Interceptor[T]) int {
+		return cmp.Compare(a.Priority, b.Priority)
+	})
+
+	funcs := make(internal.Interceptors[T], len(sorted))
+	for i, it := range sorted {
+		funcs[i] = it.Func
+	}
+	return funcs
+}
+
+// WithResponseInterceptors registers one or multiple router-wide response writer interceptors.
+func WithResponseInterceptors(interceptors ...*Interceptor[http.ResponseWriter]) Option {
+	return func(router *Router) {
+		for _, it := range interceptors {
+			if it != nil && it.Func != nil {
+				router.rwInterceptors = append(router.rwInterceptors, it)
+			}
+		}
+	}
+}
+
+// WithRequestInterceptors registers one or multiple router-wide request interceptors.
+func WithRequestInterceptors(interceptors ...*Interceptor[*http.Request]) Option {
+	return func(router *Router) {
+		for _, it := range interceptors {
+			if it != nil && it.Func != nil {
+				router.reqInterceptors = append(router.reqInterceptors, it)
+			}
+		}
+	}
+}
+
+// InterceptResponse registers one or multiple response writer interceptors to the current group.
+//
+// Unlike the router-wide interceptors they only run for the matched routes of the group (and its subgroups),
+// after the parent group ones and before the raw HTTP middlewares.
+func (group *RouterGroup) InterceptResponse(interceptors ...*Interceptor[http.ResponseWriter]) *RouterGroup {
+	group.ResponseInterceptors = append(group.ResponseInterceptors, interceptors...)
+
+	return group
+}
+
+// InterceptRequest registers one or multiple request interceptors to the current group.
+func (group *RouterGroup) InterceptRequest(interceptors ...*Interceptor[*http.Request]) *RouterGroup {
+	group.RequestInterceptors = append(group.RequestInterceptors, interceptors...)
+
+	return group
+}
+
+// InterceptResponse registers one or multiple response writer interceptors to the current route.
+func (route *Route) InterceptResponse(interceptors ...*Interceptor[http.ResponseWriter]) *Route {
+	route.ResponseInterceptors = append(route.ResponseInterceptors, interceptors...)
+
+	return route
+}
+
+// InterceptRequest registers one or multiple request interceptors to the current route.
+func (route *Route) InterceptRequest(interceptors ...*Interceptor[*http.Request]) *Route {
+	route.RequestInterceptors = append(route.RequestInterceptors, interceptors...)
+
+	return route
+}
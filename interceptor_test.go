@@ -0,0 +1,104 @@
+package keratin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type headerInterceptorWriter struct {
+	http.ResponseWriter
+	name string
+}
+
+func (w *headerInterceptorWriter) WriteHeader(statusCode int) {
+	w.Header().Add("X-Intercepted", w.name)
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *headerInterceptorWriter) Write(b []byte) (int, error) {
+	if !ResponseCommitted(w.ResponseWriter) {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *headerInterceptorWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func TestInterceptors_build(t *testing.T) {
+	var order []string
+
+	it := func(id string, priority int) *Interceptor[string] {
+		return &Interceptor[string]{ID: id, Priority: priority, Func: func(s string) (string, func()) {
+			order = append(order, id)
+			return s + id, func() { order = append(order, "cancel:"+id) }
+		}}
+	}
+
+	assert.Nil(t, Interceptors[string](nil).build())
+
+	interceptors := Interceptors[string]{it("b", 1), it("a", 0), it("c", 1)}
+	got, cancel := interceptors.build().Apply("")
+	cancel()
+
+	assert.Equal(t, "abc", got)
+	assert.Equal(t, []string{"a", "b", "c", "cancel:c", "cancel:b", "cancel:a"}, order)
+	assert.Equal(t, "b", interceptors[0].ID, "build must not sort in place")
+}
+
+func TestRouter_GroupInterceptors(t *testing.T) {
+	var order []string
+
+	responseInterceptor := func(name string, priority int) *Interceptor[http.ResponseWriter] {
+		return &Interceptor[http.ResponseWriter]{ID: name, Priority: priority, Func: func(w http.ResponseWriter) (http.ResponseWriter, func()) {
+			return &headerInterceptorWriter{ResponseWriter: w, name: name}, nil
+		}}
+	}
+	requestInterceptor := func(name string) *Interceptor[*http.Request] {
+		return &Interceptor[*http.Request]{ID: name, Func: func(r *http.Request) (*http.Request, func()) {
+			order = append(order, name)
+			return r, func() { order = append(order, "done:"+name) }
+		}}
+	}
+
+	router := NewRouter(
+		WithResponseInterceptors(responseInterceptor("global", 0), nil),
+		WithRequestInterceptors(requestInterceptor("global"), &Interceptor[*http.Request]{}),
+	)
+
+	api := router.Group("/api").
+		InterceptResponse(responseInterceptor("api", 0)).
+		InterceptRequest(requestInterceptor("api"))
+	api.GET("/users", func(w http.ResponseWriter, r *http.Request) error {
+		order = append(order, "handler")
+		assert.Equal(t, "/api/users", FromContext(r.Context()).Pattern())
+		return TextPlain(w, http.StatusOK, "ok")
+	}).
+		InterceptResponse(responseInterceptor("route", -1)).
+		InterceptRequest(requestInterceptor("route"))
+	router.GET("/public", func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, "ok")
+	})
+
+	handler := router.Build()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	// the route interceptor has a lower priority, so it wraps the writer before the group one
+	assert.Equal(t, []string{"api", "route", "global"}, w.Header().Values("X-Intercepted"))
+	assert.Equal(t, []string{"global", "api", "route", "handler", "done:route", "done:api", "done:global"}, order)
+
+	order = nil
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/public", nil))
+
+	assert.Equal(t, []string{"global"}, w.Header().Values("X-Intercepted"))
+	assert.Equal(t, []string{"global", "done:global"}, order)
+}
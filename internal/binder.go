@@ -0,0 +1,243 @@
+package internal
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	timeType            = reflect.TypeFor[time.Time]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// BindError describes a value which could not be bound to a struct field.
+type BindError struct {
+	Name string
+	Err  error
+}
+
+func (e *BindError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Name, e.Err)
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// BindValues sets the fields of the struct pointed to by dst from values,
+// using the tag to look up field names, e.g. `query:"page"`.
+//
+// Fields without the tag or tagged with "-" are ignored, embedded structs are bound recursively.
+// Supported field types are strings, booleans, numbers, [time.Duration], [time.Time]
+// (RFC 3339 or the layout of the `layout` tag), [encoding.TextUnmarshaler] implementations
+// and slices and pointers of them.
+//
+// Tagged struct fields are bound from the dotted keys, e.g. "address.city",
+// and slice values from the repeated or the "[]" suffixed keys, e.g. "tags[]".
+//
+// All the failing fields are reported, the returned error joins a [*BindError] per field.
+func BindValues(dst any, values map[string][]string, tag string) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("binding destination must be a non-nil pointer to a struct")
+	}
+
+	for key := range values {
+		if strings.HasSuffix(key, "[]") {
+			values = normalizeValues(values)
+			break
+		}
+	}
+
+	return errors.Join(bindStruct(rv.Elem(), values, tag, "")...)
+}
+
+// normalizeValues merges the "[]" suffixed keys into the plain ones,
+// the plain key values come first.
+func normalizeValues(values map[string][]string) map[string][]string {
+	normalized := make(map[string][]string, len(values))
+	for key, vals := range values {
+		if !strings.HasSuffix(key, "[]") {
+			normalized[key] = append(normalized[key], vals...)
+		}
+	}
+	for key, vals := range values {
+		if plain, ok := strings.CutSuffix(key, "[]"); ok {
+			normalized[plain] = append(normalized[plain], vals...)
+		}
+	}
+	return normalized
+}
+
+func bindStruct(rv reflect.Value, values map[string][]string, tag, prefix string) (errs []error) {
+	rt := rv.Type()
+
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct && fv.CanSet() {
+				errs = append(errs, bindStruct(fv, values, tag, prefix)...)
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name = prefix + name
+
+		if isNestedStruct(field.Type) {
+			errs = append(errs, bindNested(fv, values, tag, name+".")...)
+			continue
+		}
+
+		vals, ok := values[name]
+		if !ok || len(vals) == 0 {
+			continue
+		}
+
+		if err := setField(fv, vals, field.Tag.Get("layout")); err != nil {
+			errs = append(errs, &BindError{Name: name, Err: err})
+		}
+	}
+
+	return errs
+}
+
+// bindNested binds a struct or a pointer to struct field, the pointer is allocated
+// only if any value has the field prefix.
+func bindNested(fv reflect.Value, values map[string][]string, tag, prefix string) []error {
+	if fv.Kind() != reflect.Pointer {
+		return bindStruct(fv, values, tag, prefix)
+	}
+
+	if fv.IsNil() {
+		found := false
+		for key := range values {
+			if found = strings.HasPrefix(key, prefix); found {
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+		fv.Set(reflect.New(fv.Type().Elem()))
+	}
+
+	return bindStruct(fv.Elem(), values, tag, prefix)
+}
+
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+func setField(fv reflect.Value, vals []string, layout string) error {
+	if fv.Kind() == reflect.Slice && !fv.Type().Implements(textUnmarshalerType) && !reflect.PointerTo(fv.Type()).Implements(textUnmarshalerType) {
+		slice := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			if err := setValue(slice.Index(i), val, layout); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+
+	return setValue(fv, vals[0], layout)
+}
+
+// SetValue converts s and stores it in v. See [BindValues] for the supported types.
+func SetValue(v reflect.Value, s string) error {
+	return setValue(v, s, "")
+}
+
+func setValue(v reflect.Value, s, layout string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValue(v.Elem(), s, layout)
+	}
+
+	if layout != "" && v.Type() == timeType {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if v.CanAddr() {
+		if tu, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return tu.UnmarshalText([]byte(s))
+		}
+	}
+
+	switch v.Type() {
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case timeType:
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
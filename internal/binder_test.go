@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindValues(t *testing.T) {
+	type dst struct {
+		String   string        `form:"string"`
+		Int8     int8          `form:"int8"`
+		Uint     uint          `form:"uint"`
+		Float    float64       `form:"float"`
+		Bool     bool          `form:"bool"`
+		Duration time.Duration `form:"duration"`
+		Time     time.Time     `form:"time"`
+		IP       net.IP        `form:"ip"`
+		Ptr      *int          `form:"ptr"`
+		Ints     []int         `form:"ints"`
+		Untagged string
+		private  string `form:"private"`
+	}
+
+	values := map[string][]string{
+		"string":   {"s"},
+		"int8":     {"-8"},
+		"uint":     {"8"},
+		"float":    {"1.5"},
+		"bool":     {"true"},
+		"duration": {"1m"},
+		"time":     {"2024-01-02T03:04:05Z"},
+		"ip":       {"127.0.0.1"},
+		"ptr":      {"3"},
+		"ints":     {"1", "2"},
+		"Untagged": {"x"},
+		"private":  {"x"},
+	}
+
+	var d dst
+	require.NoError(t, BindValues(&d, values, "form"))
+
+	three := 3
+	assert.Equal(t, dst{
+		String:   "s",
+		Int8:     -8,
+		Uint:     8,
+		Float:    1.5,
+		Bool:     true,
+		Duration: time.Minute,
+		Time:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		IP:       net.ParseIP("127.0.0.1"),
+		Ptr:      &three,
+		Ints:     []int{1, 2},
+	}, d)
+}
+
+func TestBindValues_Errors(t *testing.T) {
+	type dst struct {
+		Int8 int8           `form:"int8"`
+		Map  map[string]int `form:"map"`
+	}
+
+	var d dst
+
+	err := BindValues(&d, map[string][]string{"int8": {"300"}}, "form")
+	var bindErr *BindError
+	require.ErrorAs(t, err, &bindErr)
+	assert.Equal(t, "int8", bindErr.Name)
+
+	err = BindValues(&d, map[string][]string{"map": {"x"}}, "form")
+	assert.EqualError(t, err, "map: unsupported type map[string]int")
+
+	assert.Error(t, BindValues(d, nil, "form"))
+	assert.Error(t, BindValues((*dst)(nil), nil, "form"))
+}
+
+func TestSetValue(t *testing.T) {
+	var n int
+	require.NoError(t, SetValue(reflect.ValueOf(&n).Elem(), "42"))
+	assert.Equal(t, 42, n)
+
+	assert.Error(t, SetValue(reflect.ValueOf(&n).Elem(), "x"))
+}
+
+func TestBindValues_Nested(t *testing.T) {
+	type address struct {
+		City string `form:"city"`
+	}
+	type dst struct {
+		Tags     []string  `form:"tags"`
+		Born     time.Time `form:"born" layout:"2006-01-02"`
+		Address  address   `form:"address"`
+		Billing  *address  `form:"billing"`
+		Shipping *address  `form:"shipping"`
+	}
+
+	values := map[string][]string{
+		"tags[]":       {"a", "b"},
+		"born":         {"2000-01-02"},
+		"address.city": {"Berlin"},
+		"billing.city": {"Paris"},
+	}
+
+	var d dst
+	require.NoError(t, BindValues(&d, values, "form"))
+
+	assert.Equal(t, dst{
+		Tags:    []string{"a", "b"},
+		Born:    time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC),
+		Address: address{City: "Berlin"},
+		Billing: &address{City: "Paris"},
+	}, d)
+}
+
+func TestBindValues_AggregatesErrors(t *testing.T) {
+	type dst struct {
+		A int `form:"a"`
+		B struct {
+			C int `form:"c"`
+		} `form:"b"`
+	}
+
+	err := BindValues(&dst{}, map[string][]string{"a": {"x"}, "b.c": {"y"}}, "form")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "a: ")
+	assert.Contains(t, err.Error(), "b.c: ")
+}
@@ -2,21 +2,44 @@ package internal
 
 type Interceptors[T any] []func(T) (T, func())
 
+func noop() {}
+
+// Apply runs the interceptors in order and returns the intercepted value with a function
+// which calls their cancel functions in reverse order.
+// It does not allocate unless more than one interceptor returns a cancel function.
 func (data Interceptors[T]) Apply(t T) (T, func()) {
-	cancels := make([]func(), 0, len(data))
+	var (
+		first   func()
+		cancels []func()
+	)
 
 	for _, item := range data {
 		var cancel func()
-		if t, cancel = item(t); cancel != nil {
+		if t, cancel = item(t); cancel == nil {
+			continue
+		}
+
+		switch {
+		case first == nil:
+			first = cancel
+		case cancels == nil:
+			cancels = make([]func(), 0, len(data))
+			cancels = append(cancels, first, cancel)
+		default:
 			cancels = append(cancels, cancel)
 		}
 	}
 
-	cancel := func() {
-		for i := len(cancels) - 1; i >= 0; i-- {
-			cancels[i]()
+	switch {
+	case cancels != nil:
+		return t, func() {
+			for i := len(cancels) - 1; i >= 0; i-- {
+				cancels[i]()
+			}
 		}
+	case first != nil:
+		return t, first
+	default:
+		return t, noop
 	}
-
-	return t, cancel
 }
@@ -394,3 +394,37 @@ func TestInterceptors_Apply_InterfaceType(t *testing.T) {
 		require.Equal(t, "test processed twice", got)
 	})
 }
+
+func TestInterceptors_Apply_Allocs(t *testing.T) {
+	cancel := func() {}
+
+	tests := []struct {
+		name         string
+		interceptors Interceptors[int]
+		want         float64
+	}{
+		{
+			name:         "no cancel functions",
+			interceptors: Interceptors[int]{func(n int) (int, func()) { return n, nil }},
+		},
+		{
+			name:         "single cancel function",
+			interceptors: Interceptors[int]{func(n int) (int, func()) { return n, nil }, func(n int) (int, func()) { return n, cancel }},
+		},
+		{
+			name:         "many cancel functions",
+			interceptors: Interceptors[int]{func(n int) (int, func()) { return n, cancel }, func(n int) (int, func()) { return n, cancel }},
+			want:         2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocs := testing.AllocsPerRun(100, func() {
+				_, cancel := tt.interceptors.Apply(1)
+				cancel()
+			})
+			require.Equal(t, tt.want, allocs)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Retryable reports whether the attempt that ended with the status code (0 for the network errors) is retried.
+func Retryable(status int) bool {
+	switch status {
+	case 0, http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests:
+		return true
+	}
+	return status >= http.StatusInternalServerError
+}
+
+// Sleep waits for d or until ctx is done, in which case it returns the context error.
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -1,53 +1,97 @@
 package internal
 
 import (
+	"strconv"
 	"strings"
 )
 
-func NegotiateFormat(accepted []string, offered ...string) string {
-	if len(offered) == 0 {
-		panic("negotiateFormat: you must provide at least one offer")
+// MediaRange is a single parsed element of an Accept header.
+type MediaRange struct {
+	Type    string
+	Subtype string
+	Q       float64
+}
+
+// specificity returns 2 for "type/subtype", 1 for "type/*" and 0 for "*/*".
+func (mr MediaRange) specificity() int {
+	switch {
+	case mr.Type == "*":
+		return 0
+	case mr.Subtype == "*":
+		return 1
+	default:
+		return 2
 	}
+}
+
+func (mr MediaRange) match(typ, subtype string) bool {
+	return (mr.Type == "*" || mr.Type == typ) && (mr.Subtype == "*" || mr.Subtype == subtype)
+}
 
-	if len(accepted) == 0 {
-		return offered[0]
+// ParseMediaRanges parses an Accept header value into lower-cased media ranges
+// with their quality values. Invalid elements are skipped.
+func ParseMediaRanges(accept string) []MediaRange {
+	if accept == "" {
+		return nil
 	}
 
-	for _, a := range accepted {
-		for _, offer := range offered {
-			// According to RFC 2616 and RFC 2396, non-ASCII characters are not allowed in headers,
-			// therefore we can just iterate over the string without casting it into []rune
-			i := 0
-			for ; i < len(a) && i < len(offer); i++ {
-				if a[i] == '*' || offer[i] == '*' {
-					return offer
-				}
-				if a[i] != offer[i] {
-					break
+	parts := strings.Split(accept, ",")
+	out := make([]MediaRange, 0, len(parts))
+	for _, part := range parts {
+		params := strings.Split(part, ";")
+
+		typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(params[0])), "/")
+		if !ok || typ == "" || subtype == "" || (typ == "*" && subtype != "*") {
+			continue
+		}
+
+		mr := MediaRange{Type: typ, Subtype: subtype, Q: 1}
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(strings.TrimSpace(key), "q") {
+				q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+				if err != nil || q < 0 || q > 1 {
+					q = 0
 				}
-			}
-			if i == len(a) {
-				return offer
+				mr.Q = q
 			}
 		}
+
+		out = append(out, mr)
 	}
-	return ""
+	return out
 }
 
-func ParseAcceptHeader(acceptHeader string) []string {
-	if acceptHeader == "" {
-		return make([]string, 0)
+// NegotiateContentType returns the best offer for the parsed media ranges following RFC 9110 rules:
+// the quality of an offer is taken from its most specific matching range and offers with a zero quality
+// are not acceptable. Ties are resolved by the specificity of the matching range, then by the order of offers.
+//
+// The first offer is returned when there are no media ranges, an empty string if no offer is acceptable.
+func NegotiateContentType(ranges []MediaRange, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if len(ranges) == 0 {
+		return offers[0]
 	}
 
-	parts := strings.Split(acceptHeader, ",")
-	out := make([]string, 0, len(parts))
-	for _, part := range parts {
-		if i := strings.IndexByte(part, ';'); i > 0 {
-			part = part[:i]
+	best, bestQ, bestSpec := "", 0.0, -1
+	for _, offer := range offers {
+		typ, subtype, _ := strings.Cut(strings.ToLower(offer), "/")
+		if i := strings.IndexByte(subtype, ';'); i >= 0 {
+			subtype = strings.TrimSpace(subtype[:i])
 		}
-		if part = strings.TrimSpace(part); part != "" {
-			out = append(out, part)
+
+		q, spec := 0.0, -1
+		for _, mr := range ranges {
+			if s := mr.specificity(); s > spec && mr.match(typ, subtype) {
+				q, spec = mr.Q, s
+			}
+		}
+
+		if q > bestQ || (q == bestQ && q > 0 && spec > bestSpec) {
+			best, bestQ, bestSpec = offer, q, spec
 		}
 	}
-	return out
+	return best
 }
@@ -0,0 +1,10 @@
+package internal
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+func UnmarshalXML(in io.Reader, out any) error {
+	return xml.NewDecoder(in).Decode(out)
+}
@@ -0,0 +1,20 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalXML(t *testing.T) {
+	var out struct {
+		Name string `xml:"name"`
+	}
+
+	require.NoError(t, UnmarshalXML(strings.NewReader("<user><name>john</name></user>"), &out))
+	assert.Equal(t, "john", out.Name)
+
+	assert.Error(t, UnmarshalXML(strings.NewReader("<user>"), &out))
+}
@@ -23,6 +23,15 @@ func RemoteIP(r *http.Request) string {
 	return parsed.StringExpanded()
 }
 
+// ClientIP returns the client IP of the request: the real IP extracted by the router
+// (see Router.IPExtractor) or the [RemoteIP] when the request is served outside the router.
+func ClientIP(r *http.Request) string {
+	if ip := FromContext(r.Context()).RealIP(); ip != "" {
+		return ip
+	}
+	return RemoteIP(r)
+}
+
 // TrustedProxy defines the trusted proxy settings.
 // https://developers.cloudflare.com/fundamentals/reference/http-headers/#x-forwarded-for
 type TrustedProxy struct {
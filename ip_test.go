@@ -466,3 +466,12 @@ func TestRemoteIP_WithPort(t *testing.T) {
 		})
 	}
 }
+
+func TestClientIP(t *testing.T) {
+	req := &http.Request{RemoteAddr: "192.168.1.1:8080"}
+	require.Equal(t, "192.168.1.1", ClientIP(req))
+
+	c := &kContext{realIP: "203.0.113.7"}
+	req = req.WithContext(context.WithValue(context.Background(), ctxKey{}, c))
+	require.Equal(t, "203.0.113.7", ClientIP(req))
+}
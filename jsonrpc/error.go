@@ -0,0 +1,93 @@
+package jsonrpc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gowool/keratin"
+)
+
+// The error codes defined by the JSON-RPC 2.0 specification.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+
+	// CodeServerError is the code of the application errors, see [ErrorFrom].
+	CodeServerError = -32000
+)
+
+// Error is the JSON-RPC 2.0 error object. The methods may return it to respond with a custom code.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// NewError creates a new instance of Error.
+func NewError(code int, message string) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+	}
+}
+
+// SetData sets data to be returned in the error object.
+func (e *Error) SetData(data any) *Error {
+	e.Data = data
+	return e
+}
+
+// Error makes it compatible with an `error` interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc: code=%d, message=%s", e.Code, e.Message)
+}
+
+// HTTPErrorData is the data of the errors mapped from [keratin.HTTPError].
+type HTTPErrorData struct {
+	Status    int    `json:"status"`
+	ErrorCode string `json:"error_code,omitempty"`
+	Details   any    `json:"details,omitempty"`
+	Data      any    `json:"data,omitempty"`
+}
+
+// ErrorFrom maps the error returned by a method to the JSON-RPC error object:
+//
+//   - *Error is returned as is;
+//   - 400 Bad Request and 422 Unprocessable Entity errors are [CodeInvalidParams];
+//   - other 4xx errors are [CodeServerError];
+//   - 5xx and the errors without a status code (see [keratin.ErrorStatusCode]) are [CodeInternalError].
+//
+// The mapped 4xx errors keep the message of the [keratin.HTTPError] and have [HTTPErrorData] as their data,
+// the message and data of the internal errors are not exposed.
+func ErrorFrom(err error) *Error {
+	if rpcErr, ok := errors.AsType[*Error](err); ok {
+		return rpcErr
+	}
+
+	status := keratin.ErrorStatusCode(err)
+	if status >= http.StatusInternalServerError || status < http.StatusBadRequest {
+		return NewError(CodeInternalError, "Internal error")
+	}
+
+	code := CodeServerError
+	if status == http.StatusBadRequest || status == http.StatusUnprocessableEntity {
+		code = CodeInvalidParams
+	}
+
+	data := HTTPErrorData{Status: status}
+	message := http.StatusText(status)
+	if httpErr, ok := errors.AsType[*keratin.HTTPError](err); ok {
+		if httpErr.Message != "" {
+			message = httpErr.Message
+		}
+		data.ErrorCode = httpErr.ErrorCode
+		data.Details = httpErr.Details
+		data.Data = httpErr.Data
+	}
+
+	return NewError(code, message).SetData(data)
+}
@@ -0,0 +1,240 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gowool/keratin"
+)
+
+// Version is the supported JSON-RPC protocol version.
+const Version = "2.0"
+
+type Config struct {
+	// MaxBatchSize is the maximum number of requests in a batch.
+	//
+	// Default: 100
+	MaxBatchSize int `env:"MAX_BATCH_SIZE" json:"maxBatchSize,omitempty" yaml:"maxBatchSize,omitempty"`
+}
+
+func (c *Config) SetDefaults() {
+	if c.MaxBatchSize <= 0 {
+		c.MaxBatchSize = 100
+	}
+}
+
+// Request is the JSON-RPC 2.0 request object, the requests without ID are notifications.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// Response is the JSON-RPC 2.0 response object.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// method calls a registered method with the raw params.
+type method func(r *http.Request, params json.RawMessage) (any, error)
+
+// Server dispatches the JSON-RPC 2.0 requests to the registered methods.
+//
+// It is a [keratin.Handler] served by a single POST route (see [Server.Register]), so the router middlewares
+// run once per HTTP request, single or batch. The transport errors (unsupported content type, body read errors)
+// are returned to the router error handler, the protocol and method errors are JSON-RPC responses (200 OK).
+// The method panics are not recovered, use the Recover middleware.
+type Server struct {
+	cfg     Config
+	mu      sync.RWMutex
+	methods map[string]method
+}
+
+func NewServer(cfg Config) *Server {
+	cfg.SetDefaults()
+
+	return &Server{
+		cfg:     cfg,
+		methods: make(map[string]method),
+	}
+}
+
+// Register registers the POST route serving the JSON-RPC requests on path in group.
+func (s *Server) Register(group *keratin.RouterGroup, path string) *keratin.Route {
+	return group.POST(path, s.ServeHTTP)
+}
+
+// Methods returns the sorted names of the registered methods.
+func (s *Server) Methods() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Sorted(maps.Keys(s.methods))
+}
+
+// Register adds the method with the typed params and result to the server.
+//
+// The params are decoded from the JSON-RPC request params (the zero P when omitted) and validated
+// with [keratin.Validate], the invalid params are responded with [CodeInvalidParams].
+// The errors returned by fn are mapped with [ErrorFrom].
+//
+// It panics if name is empty, starts with the reserved "rpc." prefix or is already registered, or if fn is nil.
+func Register[P, R any](s *Server, name string, fn func(ctx context.Context, params P) (R, error)) {
+	if name == "" {
+		panic("jsonrpc: method name is required")
+	}
+	if strings.HasPrefix(name, "rpc.") {
+		panic("jsonrpc: method name " + name + " uses the reserved \"rpc.\" prefix")
+	}
+	if fn == nil {
+		panic("jsonrpc: method " + name + " func is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.methods[name]; ok {
+		panic("jsonrpc: method " + name + " is already registered")
+	}
+
+	s.methods[name] = func(r *http.Request, raw json.RawMessage) (any, error) {
+		var params P
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return nil, NewError(CodeInvalidParams, "Invalid params").SetData(err.Error())
+			}
+		}
+		if err := keratin.Validate(r, &params); err != nil {
+			return nil, err
+		}
+		return fn(r.Context(), params)
+	}
+}
+
+// ServeHTTP handles a single or a batch JSON-RPC request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get(keratin.HeaderContentType)); mediaType != keratin.MIMEApplicationJSON {
+		return keratin.ErrUnsupportedMediaType
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || body[0] != '[' {
+		if res := s.handle(r, body); res != nil {
+			return keratin.JSON(w, http.StatusOK, res)
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err = json.Unmarshal(body, &batch); err != nil {
+		return keratin.JSON(w, http.StatusOK, errorResponse(nil, NewError(CodeParseError, "Parse error")))
+	}
+	if len(batch) == 0 {
+		return keratin.JSON(w, http.StatusOK, errorResponse(nil, NewError(CodeInvalidRequest, "Invalid Request")))
+	}
+	if len(batch) > s.cfg.MaxBatchSize {
+		return keratin.JSON(w, http.StatusOK, errorResponse(nil, NewError(CodeInvalidRequest, "Batch too large")))
+	}
+
+	responses := make([]*Response, 0, len(batch))
+	for _, raw := range batch {
+		if res := s.handle(r, raw); res != nil {
+			responses = append(responses, res)
+		}
+	}
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	return keratin.JSON(w, http.StatusOK, responses)
+}
+
+// handle calls the method of a single request and returns its response, nil for the notifications.
+func (s *Server) handle(r *http.Request, raw json.RawMessage) *Response {
+	if !json.Valid(raw) {
+		return errorResponse(nil, NewError(CodeParseError, "Parse error"))
+	}
+
+	var req Request
+	if err := json.Unmarshal(raw, &req); err != nil || !req.valid() {
+		return errorResponse(req.validID(), NewError(CodeInvalidRequest, "Invalid Request"))
+	}
+
+	s.mu.RLock()
+	fn, ok := s.methods[req.Method]
+	s.mu.RUnlock()
+
+	var (
+		result any
+		err    error
+	)
+	if ok {
+		result, err = fn(r, req.Params)
+	} else {
+		err = NewError(CodeMethodNotFound, "Method not found")
+	}
+
+	if req.ID == nil {
+		return nil
+	}
+	if err != nil {
+		return errorResponse(req.ID, ErrorFrom(err))
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return errorResponse(req.ID, NewError(CodeInternalError, "Internal error"))
+	}
+	return &Response{JSONRPC: Version, Result: data, ID: req.ID}
+}
+
+// valid reports whether the request has the supported version, a method name,
+// structured (or omitted) params and a string, number or null ID.
+func (req *Request) valid() bool {
+	if req.JSONRPC != Version || req.Method == "" {
+		return false
+	}
+	if len(req.Params) > 0 && req.Params[0] != '{' && req.Params[0] != '[' {
+		return false
+	}
+	return req.ID == nil || req.validID() != nil
+}
+
+// validID returns the request ID if it is a string, number or null, nil otherwise.
+func (req *Request) validID() json.RawMessage {
+	if len(req.ID) == 0 {
+		return nil
+	}
+	switch c := req.ID[0]; {
+	case c == '"', c == '-', c >= '0' && c <= '9', bytes.Equal(req.ID, []byte("null")):
+		return req.ID
+	}
+	return nil
+}
+
+func errorResponse(id json.RawMessage, err *Error) *Response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &Response{JSONRPC: Version, Error: err, ID: id}
+}
+
+var _ keratin.Handler = (*Server)(nil)
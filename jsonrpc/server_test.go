@@ -0,0 +1,271 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+type sumParams struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+func (p *sumParams) Validate() error {
+	if p.A < 0 || p.B < 0 {
+		return errors.New("negative operand")
+	}
+	return nil
+}
+
+func newTestServer() (*Server, http.Handler, *[]string) {
+	var notified []string
+
+	s := NewServer(Config{MaxBatchSize: 3})
+	Register(s, "sum", func(_ context.Context, p sumParams) (int, error) {
+		return p.A + p.B, nil
+	})
+	Register(s, "notify", func(_ context.Context, p []string) (any, error) {
+		notified = append(notified, p...)
+		return nil, nil
+	})
+	Register(s, "user", func(_ context.Context, p struct{}) (any, error) {
+		return nil, keratin.NewHTTPErrorWithCode(http.StatusNotFound, "user_not_found", "User not found.")
+	})
+	Register(s, "fail", func(_ context.Context, p struct{}) (any, error) {
+		return nil, errors.New("database is down")
+	})
+	Register(s, "custom", func(_ context.Context, p struct{}) (any, error) {
+		return nil, NewError(-32001, "Custom").SetData("data")
+	})
+
+	router := keratin.NewRouter()
+	s.Register(router.RouterGroup, "/rpc")
+
+	return s, router.Build(), &notified
+}
+
+func TestServer_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "call",
+			body:     `{"jsonrpc":"2.0","method":"sum","params":{"a":1,"b":2},"id":1}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"jsonrpc":"2.0","result":3,"id":1}`,
+		},
+		{
+			name:     "null id",
+			body:     `{"jsonrpc":"2.0","method":"sum","params":{"a":1,"b":2},"id":null}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"jsonrpc":"2.0","result":3,"id":null}`,
+		},
+		{
+			name:     "notification",
+			body:     `{"jsonrpc":"2.0","method":"notify","params":["a"]}`,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "parse error",
+			body:     `{"jsonrpc":"2.0","method"`,
+			wantCode: http.StatusOK,
+			wantBody: `{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"},"id":null}`,
+		},
+		{
+			name:     "invalid version",
+			body:     `{"jsonrpc":"1.0","method":"sum","id":"x"}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":"x"}`,
+		},
+		{
+			name:     "invalid params type",
+			body:     `{"jsonrpc":"2.0","method":"sum","params":1,"id":1}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":1}`,
+		},
+		{
+			name:     "invalid id",
+			body:     `{"jsonrpc":"2.0","method":"sum","id":{}}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`,
+		},
+		{
+			name:     "method not found",
+			body:     `{"jsonrpc":"2.0","method":"missing","id":1}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":1}`,
+		},
+		{
+			name:     "params decode error",
+			body:     `{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params","data":"json: cannot unmarshal array into Go value of type jsonrpc.sumParams"},"id":1}`,
+		},
+		{
+			name:     "params validation error",
+			body:     `{"jsonrpc":"2.0","method":"sum","params":{"a":-1},"id":1}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"jsonrpc":"2.0","error":{"code":-32602,"message":"negative operand","data":{"status":422}},"id":1}`,
+		},
+		{
+			name:     "http error",
+			body:     `{"jsonrpc":"2.0","method":"user","id":1}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"jsonrpc":"2.0","error":{"code":-32000,"message":"User not found.","data":{"status":404,"error_code":"user_not_found"}},"id":1}`,
+		},
+		{
+			name:     "internal error",
+			body:     `{"jsonrpc":"2.0","method":"fail","id":1}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal error"},"id":1}`,
+		},
+		{
+			name:     "custom error",
+			body:     `{"jsonrpc":"2.0","method":"custom","id":1}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"jsonrpc":"2.0","error":{"code":-32001,"message":"Custom","data":"data"},"id":1}`,
+		},
+		{
+			name:     "batch",
+			body:     `[{"jsonrpc":"2.0","method":"sum","params":{"a":1,"b":2},"id":1},{"jsonrpc":"2.0","method":"notify","params":["b"]},1]`,
+			wantCode: http.StatusOK,
+			wantBody: `[{"jsonrpc":"2.0","result":3,"id":1},{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}]`,
+		},
+		{
+			name:     "batch of notifications",
+			body:     `[{"jsonrpc":"2.0","method":"notify","params":["c"]}]`,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "empty batch",
+			body:     `[]`,
+			wantCode: http.StatusOK,
+			wantBody: `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`,
+		},
+		{
+			name:     "batch too large",
+			body:     `[1,2,3,4]`,
+			wantCode: http.StatusOK,
+			wantBody: `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Batch too large"},"id":null}`,
+		},
+		{
+			name:     "batch parse error",
+			body:     `[{"jsonrpc":"2.0"`,
+			wantCode: http.StatusOK,
+			wantBody: `{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"},"id":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, handler, _ := newTestServer()
+
+			req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(tt.body))
+			req.Header.Set(keratin.HeaderContentType, keratin.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantBody == "" {
+				assert.Empty(t, rec.Body.String())
+			} else {
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestServer_ServeHTTP_Notifications(t *testing.T) {
+	_, handler, notified := newTestServer()
+
+	req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(`[{"jsonrpc":"2.0","method":"notify","params":["a","b"]},{"jsonrpc":"2.0","method":"notify","params":["c"]}]`))
+	req.Header.Set(keratin.HeaderContentType, keratin.MIMEApplicationJSON)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, []string{"a", "b", "c"}, *notified)
+}
+
+func TestServer_ServeHTTP_Transport(t *testing.T) {
+	_, handler, _ := newTestServer()
+
+	req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(`{}`))
+	req.Header.Set(keratin.HeaderContentType, keratin.MIMETextPlain)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rpc", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestServer_Methods(t *testing.T) {
+	s, _, _ := newTestServer()
+
+	assert.Equal(t, []string{"custom", "fail", "notify", "sum", "user"}, s.Methods())
+}
+
+func TestRegister_Panics(t *testing.T) {
+	s := NewServer(Config{})
+	fn := func(context.Context, struct{}) (any, error) { return nil, nil }
+	Register(s, "echo", fn)
+
+	assert.PanicsWithValue(t, "jsonrpc: method name is required", func() { Register(s, "", fn) })
+	assert.PanicsWithValue(t, `jsonrpc: method name rpc.echo uses the reserved "rpc." prefix`, func() { Register(s, "rpc.echo", fn) })
+	assert.PanicsWithValue(t, "jsonrpc: method nil func is required", func() {
+		Register[struct{}, any](s, "nil", nil)
+	})
+	assert.PanicsWithValue(t, "jsonrpc: method echo is already registered", func() { Register(s, "echo", fn) })
+}
+
+func TestErrorFrom(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want *Error
+	}{
+		{
+			name: "rpc error",
+			err:  NewError(-32001, "Custom"),
+			want: NewError(-32001, "Custom"),
+		},
+		{
+			name: "bad request",
+			err:  keratin.ErrBadRequest,
+			want: NewError(CodeInvalidParams, "Bad Request").SetData(HTTPErrorData{Status: http.StatusBadRequest}),
+		},
+		{
+			name: "forbidden",
+			err:  keratin.NewHTTPError(http.StatusForbidden, "No access.").SetData("data"),
+			want: NewError(CodeServerError, "No access.").SetData(HTTPErrorData{Status: http.StatusForbidden, Data: "data"}),
+		},
+		{
+			name: "service unavailable",
+			err:  keratin.ErrServiceUnavailable,
+			want: NewError(CodeInternalError, "Internal error"),
+		},
+		{
+			name: "plain error",
+			err:  errors.New("boom"),
+			want: NewError(CodeInternalError, "Internal error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ErrorFrom(tt.err))
+		})
+	}
+}
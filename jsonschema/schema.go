@@ -0,0 +1,464 @@
+// Package jsonschema validates JSON values against JSON Schema (draft 2020-12) documents.
+//
+// The supported keywords are: type, enum, const, properties, required, additionalProperties,
+// patternProperties, minProperties, maxProperties, items, prefixItems, minItems, maxItems, uniqueItems,
+// minLength, maxLength, pattern, format (date, date-time, email, ipv4, ipv6, uri, uuid), minimum, maximum,
+// exclusiveMinimum, exclusiveMaximum, multipleOf, allOf, anyOf, oneOf, not and $ref to the "$defs"
+// of the same or another document of the [Registry]. Other keywords are ignored.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Schema is a compiled JSON Schema.
+type Schema struct {
+	// doc and pointer locate the schema, e.g. "user.json" and "/$defs/address"
+	doc     string
+	pointer string
+	node    any
+
+	always *bool
+
+	ref    *Schema
+	types  []string
+	enum   []any
+	cnst   any
+	isCnst bool
+
+	properties        map[string]*Schema
+	patternProperties map[*regexp.Regexp]*Schema
+	additional        *Schema
+	required          []string
+	minProperties     *int
+	maxProperties     *int
+
+	items       *Schema
+	prefixItems []*Schema
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+	format    string
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+
+	allOf []*Schema
+	anyOf []*Schema
+	oneOf []*Schema
+	not   *Schema
+}
+
+// Compile compiles a standalone schema document, its $ref may only point to its own "$defs".
+func Compile(data []byte) (*Schema, error) {
+	r := NewRegistry(nil)
+	if err := r.Add("", data); err != nil {
+		return nil, err
+	}
+	return r.Schema("")
+}
+
+// MustCompile is like [Compile] but panics if the schema cannot be compiled.
+func MustCompile(data []byte) *Schema {
+	s, err := Compile(data)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// MarshalJSON returns the schema source, e.g. to embed it in an OpenAPI document.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.node)
+}
+
+// Location returns the document name and the JSON pointer of the schema, e.g. "user.json#/$defs/address".
+func (s *Schema) Location() string {
+	if s.pointer == "" {
+		return s.doc
+	}
+	return s.doc + "#" + s.pointer
+}
+
+// Registry compiles and caches the schema documents of a file system, resolving the $ref between them.
+// It is safe for concurrent use.
+type Registry struct {
+	fsys    fs.FS
+	mu      sync.Mutex
+	docs    map[string]any
+	schemas map[string]*Schema
+}
+
+// NewRegistry creates a registry loading the schema documents from fsys (may be nil, see [Registry.Add]).
+func NewRegistry(fsys fs.FS) *Registry {
+	return &Registry{
+		fsys:    fsys,
+		docs:    make(map[string]any),
+		schemas: make(map[string]*Schema),
+	}
+}
+
+// Add adds the schema document with the given name, replacing the file of the same name.
+func (r *Registry) Add(name string, data []byte) error {
+	doc, err := decode(data)
+	if err != nil && name == "" {
+		return fmt.Errorf("jsonschema: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("jsonschema: %s: %w", name, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.docs[name] = doc
+	for key := range r.schemas {
+		if docName, _, _ := strings.Cut(key, "#"); docName == name {
+			delete(r.schemas, key)
+		}
+	}
+	return nil
+}
+
+// Schema returns the compiled schema of the document name, optionally followed by a JSON pointer fragment,
+// e.g. "user.json" or "defs.json#/$defs/address".
+func (r *Registry) Schema(name string) (*Schema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	doc, pointer, _ := strings.Cut(name, "#")
+
+	c := compiler{registry: r}
+	s, err := c.compileAt(doc, pointer)
+	if err != nil {
+		return nil, fmt.Errorf("jsonschema: %w", err)
+	}
+	return s, nil
+}
+
+// Documents returns the sources of the loaded documents by name, e.g. to add them to the OpenAPI components.
+func (r *Registry) Documents() map[string]json.RawMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	docs := make(map[string]json.RawMessage, len(r.docs))
+	for name, doc := range r.docs {
+		docs[name], _ = json.Marshal(doc)
+	}
+	return docs
+}
+
+// document returns the decoded document, loading it from the file system on the first use.
+func (r *Registry) document(name string) (any, error) {
+	if doc, ok := r.docs[name]; ok {
+		return doc, nil
+	}
+	if r.fsys == nil {
+		return nil, fmt.Errorf("document %q not found", name)
+	}
+
+	data, err := fs.ReadFile(r.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	r.docs[name] = doc
+	return doc, nil
+}
+
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	switch doc.(type) {
+	case map[string]any, bool:
+		return doc, nil
+	}
+	return nil, errors.New("schema must be an object or a boolean")
+}
+
+type compiler struct {
+	registry *Registry
+}
+
+// errorf returns the compile error of the schema s.
+func (s *Schema) errorf(format string, args ...any) error {
+	return fmt.Errorf("%s#%s: %s", s.doc, s.pointer, fmt.Sprintf(format, args...))
+}
+
+// compileAt compiles the schema at the JSON pointer of the document, the compiled schemas are cached
+// before their subschemas are compiled, so that the recursive references resolve.
+func (c *compiler) compileAt(doc, pointer string) (*Schema, error) {
+	key := doc + "#" + pointer
+	if s, ok := c.registry.schemas[key]; ok {
+		return s, nil
+	}
+
+	root, err := c.registry.document(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := resolvePointer(root, pointer)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+
+	s := &Schema{doc: doc, pointer: pointer, node: node}
+	c.registry.schemas[key] = s
+
+	if err = c.compile(s, node); err != nil {
+		delete(c.registry.schemas, key)
+		return nil, err
+	}
+	return s, nil
+}
+
+func (c *compiler) sub(parent *Schema, node any, tokens ...string) (*Schema, error) {
+	pointer := parent.pointer
+	for _, token := range tokens {
+		pointer += "/" + escape(token)
+	}
+
+	key := parent.doc + "#" + pointer
+	if s, ok := c.registry.schemas[key]; ok {
+		return s, nil
+	}
+
+	s := &Schema{doc: parent.doc, pointer: pointer, node: node}
+	c.registry.schemas[key] = s
+	if err := c.compile(s, node); err != nil {
+		delete(c.registry.schemas, key)
+		return nil, err
+	}
+	return s, nil
+}
+
+func (c *compiler) compile(s *Schema, node any) error {
+	if v, ok := node.(bool); ok {
+		s.always = &v
+		return nil
+	}
+
+	m, ok := node.(map[string]any)
+	if !ok {
+		return s.errorf("schema must be an object or a boolean")
+	}
+
+	if ref, ok := m["$ref"].(string); ok {
+		target, err := c.ref(s, ref)
+		if err != nil {
+			return err
+		}
+		s.ref = target
+	}
+
+	switch v := m["type"].(type) {
+	case string:
+		s.types = []string{v}
+	case []any:
+		for _, t := range v {
+			if name, ok := t.(string); ok {
+				s.types = append(s.types, name)
+			}
+		}
+	}
+
+	if enum, ok := m["enum"].([]any); ok {
+		s.enum = enum
+	}
+	if v, ok := m["const"]; ok {
+		s.cnst, s.isCnst = v, true
+	}
+
+	var err error
+	if props, ok := m["properties"].(map[string]any); ok {
+		s.properties = make(map[string]*Schema, len(props))
+		for name, prop := range props {
+			if s.properties[name], err = c.sub(s, prop, "properties", name); err != nil {
+				return err
+			}
+		}
+	}
+	if props, ok := m["patternProperties"].(map[string]any); ok {
+		s.patternProperties = make(map[*regexp.Regexp]*Schema, len(props))
+		for expr, prop := range props {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return s.errorf("patternProperties: %v", err)
+			}
+			if s.patternProperties[re], err = c.sub(s, prop, "patternProperties", expr); err != nil {
+				return err
+			}
+		}
+	}
+	if v, ok := m["additionalProperties"]; ok {
+		if s.additional, err = c.sub(s, v, "additionalProperties"); err != nil {
+			return err
+		}
+	}
+	if required, ok := m["required"].([]any); ok {
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				s.required = append(s.required, name)
+			}
+		}
+	}
+
+	if v, ok := m["items"]; ok {
+		if s.items, err = c.sub(s, v, "items"); err != nil {
+			return err
+		}
+	}
+	if items, ok := m["prefixItems"].([]any); ok {
+		for i, item := range items {
+			sub, err := c.sub(s, item, "prefixItems", strconv.Itoa(i))
+			if err != nil {
+				return err
+			}
+			s.prefixItems = append(s.prefixItems, sub)
+		}
+	}
+	s.uniqueItems, _ = m["uniqueItems"].(bool)
+
+	if v, ok := m["pattern"].(string); ok {
+		if s.pattern, err = regexp.Compile(v); err != nil {
+			return s.errorf("pattern: %v", err)
+		}
+	}
+	s.format, _ = m["format"].(string)
+
+	for keyword, dst := range map[string]**int{
+		"minProperties": &s.minProperties,
+		"maxProperties": &s.maxProperties,
+		"minItems":      &s.minItems,
+		"maxItems":      &s.maxItems,
+		"minLength":     &s.minLength,
+		"maxLength":     &s.maxLength,
+	} {
+		if v, ok := m[keyword]; ok {
+			n, ok := number(v)
+			if !ok || n < 0 || n != float64(int(n)) {
+				return s.errorf("%s must be a non-negative integer", keyword)
+			}
+			i := int(n)
+			*dst = &i
+		}
+	}
+	for keyword, dst := range map[string]**float64{
+		"minimum":          &s.minimum,
+		"maximum":          &s.maximum,
+		"exclusiveMinimum": &s.exclusiveMinimum,
+		"exclusiveMaximum": &s.exclusiveMaximum,
+		"multipleOf":       &s.multipleOf,
+	} {
+		if v, ok := m[keyword]; ok {
+			n, ok := number(v)
+			if !ok {
+				return s.errorf("%s must be a number", keyword)
+			}
+			*dst = &n
+		}
+	}
+	if s.multipleOf != nil && *s.multipleOf <= 0 {
+		return s.errorf("multipleOf must be greater than 0")
+	}
+
+	for keyword, dst := range map[string]*[]*Schema{"allOf": &s.allOf, "anyOf": &s.anyOf, "oneOf": &s.oneOf} {
+		if list, ok := m[keyword].([]any); ok {
+			for i, item := range list {
+				sub, err := c.sub(s, item, keyword, strconv.Itoa(i))
+				if err != nil {
+					return err
+				}
+				*dst = append(*dst, sub)
+			}
+		}
+	}
+	if v, ok := m["not"]; ok {
+		if s.not, err = c.sub(s, v, "not"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ref resolves the $ref relative to the document of s.
+func (c *compiler) ref(s *Schema, ref string) (*Schema, error) {
+	u, err := url.Parse(ref)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return nil, s.errorf("$ref %q: only references to the registry documents are supported", ref)
+	}
+
+	doc := s.doc
+	if u.Path != "" {
+		doc = path.Join(path.Dir(s.doc), u.Path)
+	}
+	return c.compileAt(doc, u.Fragment)
+}
+
+// resolvePointer returns the value at the JSON pointer (RFC 6901) of the document.
+func resolvePointer(doc any, pointer string) (any, error) {
+	if pointer == "" {
+		return doc, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+
+	node := doc
+	for token := range strings.SplitSeq(pointer[1:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+
+		switch v := node.(type) {
+		case map[string]any:
+			var ok bool
+			if node, ok = v[token]; !ok {
+				return nil, fmt.Errorf("JSON pointer %q not found", pointer)
+			}
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, fmt.Errorf("JSON pointer %q not found", pointer)
+			}
+			node = v[i]
+		default:
+			return nil, fmt.Errorf("JSON pointer %q not found", pointer)
+		}
+	}
+	return node, nil
+}
+
+// escape escapes the JSON pointer reference token.
+func escape(token string) string {
+	if !strings.ContainsAny(token, "~/") {
+		return token
+	}
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
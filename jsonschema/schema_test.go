@@ -0,0 +1,130 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr string
+	}{
+		{name: "object", schema: `{"type":"object","properties":{"name":{"type":"string"}}}`},
+		{name: "boolean", schema: `true`},
+		{name: "local ref", schema: `{"$ref":"#/$defs/name","$defs":{"name":{"type":"string"}}}`},
+		{name: "recursive ref", schema: `{"type":"object","properties":{"children":{"type":"array","items":{"$ref":"#"}}}}`},
+		{name: "invalid json", schema: `{`, wantErr: "jsonschema: unexpected EOF"},
+		{name: "not a schema", schema: `1`, wantErr: "jsonschema: schema must be an object or a boolean"},
+		{name: "invalid pattern", schema: `{"pattern":"("}`, wantErr: "jsonschema: #: pattern: error parsing regexp: missing closing ): `(`"},
+		{name: "invalid minLength", schema: `{"minLength":-1}`, wantErr: "jsonschema: #: minLength must be a non-negative integer"},
+		{name: "invalid multipleOf", schema: `{"multipleOf":0}`, wantErr: "jsonschema: #: multipleOf must be greater than 0"},
+		{name: "unknown ref", schema: `{"$ref":"#/$defs/missing"}`, wantErr: `jsonschema: #/$defs/missing: JSON pointer "/$defs/missing" not found`},
+		{name: "remote ref", schema: `{"$ref":"https://example.com/schema.json"}`, wantErr: `jsonschema: #: $ref "https://example.com/schema.json": only references to the registry documents are supported`},
+		{name: "invalid subschema", schema: `{"properties":{"name":1}}`, wantErr: "jsonschema: #/properties/name: schema must be an object or a boolean"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Compile([]byte(tt.schema))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, s)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, s)
+		})
+	}
+}
+
+func TestMustCompile(t *testing.T) {
+	assert.NotNil(t, MustCompile([]byte(`{"type":"string"}`)))
+	assert.Panics(t, func() { MustCompile([]byte(`1`)) })
+}
+
+func TestSchema_MarshalJSON(t *testing.T) {
+	s := MustCompile([]byte(`{"type":"object","properties":{"age":{"type":"integer","minimum":18}}}`))
+
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"object","properties":{"age":{"type":"integer","minimum":18}}}`, string(data))
+}
+
+func TestRegistry(t *testing.T) {
+	fsys := fstest.MapFS{
+		"user.json": {Data: []byte(`{
+			"type": "object",
+			"required": ["name", "address"],
+			"properties": {
+				"name": {"type": "string"},
+				"address": {"$ref": "common/defs.json#/$defs/address"}
+			}
+		}`)},
+		"common/defs.json": {Data: []byte(`{
+			"$defs": {
+				"address": {
+					"type": "object",
+					"required": ["city"],
+					"properties": {"city": {"type": "string", "minLength": 1}, "country": {"$ref": "#/$defs/country"}}
+				},
+				"country": {"type": "string", "minLength": 2, "maxLength": 2}
+			}
+		}`)},
+		"broken.json": {Data: []byte(`{"$ref":"missing.json"}`)},
+	}
+
+	r := NewRegistry(fsys)
+
+	s, err := r.Schema("user.json")
+	require.NoError(t, err)
+	assert.Equal(t, "user.json", s.Location())
+
+	assert.NoError(t, s.ValidateJSON([]byte(`{"name":"Jane","address":{"city":"Berlin","country":"DE"}}`)))
+	assert.Equal(t, ValidationError{
+		{Pointer: "/address/city", Keyword: "minLength", Message: "must be at least 1 characters long"},
+		{Pointer: "/address/country", Keyword: "maxLength", Message: "must be at most 2 characters long"},
+	}, s.ValidateJSON([]byte(`{"name":"Jane","address":{"city":"","country":"DEU"}}`)))
+
+	cached, err := r.Schema("user.json")
+	require.NoError(t, err)
+	assert.Same(t, s, cached)
+
+	address, err := r.Schema("common/defs.json#/$defs/address")
+	require.NoError(t, err)
+	assert.Equal(t, "common/defs.json#/$defs/address", address.Location())
+
+	_, err = r.Schema("broken.json")
+	assert.EqualError(t, err, "jsonschema: open missing.json: file does not exist")
+
+	_, err = r.Schema("missing.json")
+	assert.Error(t, err)
+
+	docs := r.Documents()
+	assert.Len(t, docs, 3)
+	assert.Contains(t, docs, "common/defs.json")
+}
+
+func TestRegistry_Add(t *testing.T) {
+	r := NewRegistry(nil)
+
+	require.NoError(t, r.Add("name.json", []byte(`{"type":"string"}`)))
+	s, err := r.Schema("name.json")
+	require.NoError(t, err)
+	assert.NoError(t, s.Validate("Jane"))
+
+	require.NoError(t, r.Add("name.json", []byte(`{"type":"integer"}`)))
+	s, err = r.Schema("name.json")
+	require.NoError(t, err)
+	assert.Error(t, s.Validate("Jane"))
+
+	assert.Error(t, r.Add("broken.json", []byte(`[`)))
+
+	_, err = r.Schema("missing.json")
+	assert.EqualError(t, err, `jsonschema: document "missing.json" not found`)
+}
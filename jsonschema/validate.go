@@ -0,0 +1,346 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Error is a single schema violation.
+type Error struct {
+	// Pointer is the JSON pointer (RFC 6901) of the invalid value, "" for the root value.
+	Pointer string `json:"pointer"`
+	// Keyword is the failed schema keyword, e.g. "required".
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists the schema violations of a value.
+type ValidationError []Error
+
+func (ve ValidationError) Error() string {
+	msgs := make([]string, len(ve))
+	for i, e := range ve {
+		msgs[i] = displayPointer(e.Pointer) + ": " + e.Message
+	}
+	return "jsonschema: " + strings.Join(msgs, "; ")
+}
+
+func displayPointer(pointer string) string {
+	if pointer == "" {
+		return "/"
+	}
+	return pointer
+}
+
+// ValidateJSON decodes the JSON data and validates it, see [Schema.Validate].
+func (s *Schema) ValidateJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("jsonschema: decode value: %w", err)
+	}
+	return s.Validate(v)
+}
+
+// Validate validates the value decoded from JSON (nil, bool, float64, [json.Number], string,
+// []any and map[string]any) and returns a [ValidationError] with all the violations.
+func (s *Schema) Validate(v any) error {
+	var errs ValidationError
+	s.validate(v, "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (s *Schema) valid(v any, pointer string) bool {
+	var errs ValidationError
+	s.validate(v, pointer, &errs)
+	return len(errs) == 0
+}
+
+func (s *Schema) validate(v any, pointer string, errs *ValidationError) {
+	fail := func(keyword, format string, args ...any) {
+		*errs = append(*errs, Error{Pointer: pointer, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.always != nil {
+		if !*s.always {
+			fail("false", "is not allowed")
+		}
+		return
+	}
+
+	if s.ref != nil {
+		s.ref.validate(v, pointer, errs)
+	}
+
+	if len(s.types) > 0 && !slices.ContainsFunc(s.types, func(t string) bool { return isType(v, t) }) {
+		fail("type", "must be of type %s", strings.Join(s.types, " or "))
+		return
+	}
+
+	if s.enum != nil && !slices.ContainsFunc(s.enum, func(e any) bool { return equal(v, e) }) {
+		fail("enum", "must be one of the allowed values")
+	}
+	if s.isCnst && !equal(v, s.cnst) {
+		fail("const", "must be equal to the constant value")
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		s.validateObject(v, pointer, errs, fail)
+	case []any:
+		s.validateArray(v, pointer, errs, fail)
+	case string:
+		s.validateString(v, fail)
+	default:
+		if n, ok := number(v); ok {
+			s.validateNumber(n, fail)
+		}
+	}
+
+	for _, sub := range s.allOf {
+		sub.validate(v, pointer, errs)
+	}
+	if len(s.anyOf) > 0 && !slices.ContainsFunc(s.anyOf, func(sub *Schema) bool { return sub.valid(v, pointer) }) {
+		fail("anyOf", "must match at least one schema")
+	}
+	if len(s.oneOf) > 0 {
+		matched := 0
+		for _, sub := range s.oneOf {
+			if sub.valid(v, pointer) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			fail("oneOf", "must match exactly one schema, matched %d", matched)
+		}
+	}
+	if s.not != nil && s.not.valid(v, pointer) {
+		fail("not", "must not match the schema")
+	}
+}
+
+func (s *Schema) validateObject(v map[string]any, pointer string, errs *ValidationError, fail func(string, string, ...any)) {
+	for _, name := range s.required {
+		if _, ok := v[name]; !ok {
+			*errs = append(*errs, Error{Pointer: pointer + "/" + escape(name), Keyword: "required", Message: "is required"})
+		}
+	}
+	if s.minProperties != nil && len(v) < *s.minProperties {
+		fail("minProperties", "must have at least %d properties", *s.minProperties)
+	}
+	if s.maxProperties != nil && len(v) > *s.maxProperties {
+		fail("maxProperties", "must have at most %d properties", *s.maxProperties)
+	}
+
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		value, child := v[name], pointer+"/"+escape(name)
+
+		matched := false
+		if prop, ok := s.properties[name]; ok {
+			prop.validate(value, child, errs)
+			matched = true
+		}
+		for re, prop := range s.patternProperties {
+			if re.MatchString(name) {
+				prop.validate(value, child, errs)
+				matched = true
+			}
+		}
+
+		if !matched && s.additional != nil {
+			if s.additional.always != nil && !*s.additional.always {
+				*errs = append(*errs, Error{Pointer: child, Keyword: "additionalProperties", Message: "is not allowed"})
+				continue
+			}
+			s.additional.validate(value, child, errs)
+		}
+	}
+}
+
+func (s *Schema) validateArray(v []any, pointer string, errs *ValidationError, fail func(string, string, ...any)) {
+	if s.minItems != nil && len(v) < *s.minItems {
+		fail("minItems", "must have at least %d items", *s.minItems)
+	}
+	if s.maxItems != nil && len(v) > *s.maxItems {
+		fail("maxItems", "must have at most %d items", *s.maxItems)
+	}
+
+	for i, item := range v {
+		child := pointer + "/" + strconv.Itoa(i)
+		switch {
+		case i < len(s.prefixItems):
+			s.prefixItems[i].validate(item, child, errs)
+		case s.items != nil:
+			s.items.validate(item, child, errs)
+		}
+	}
+
+	if s.uniqueItems {
+		for i := 1; i < len(v); i++ {
+			if slices.ContainsFunc(v[:i], func(prev any) bool { return equal(prev, v[i]) }) {
+				fail("uniqueItems", "must have unique items")
+				break
+			}
+		}
+	}
+}
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func (s *Schema) validateString(v string, fail func(string, string, ...any)) {
+	if s.minLength != nil || s.maxLength != nil {
+		n := utf8.RuneCountInString(v)
+		if s.minLength != nil && n < *s.minLength {
+			fail("minLength", "must be at least %d characters long", *s.minLength)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			fail("maxLength", "must be at most %d characters long", *s.maxLength)
+		}
+	}
+	if s.pattern != nil && !s.pattern.MatchString(v) {
+		fail("pattern", "must match the pattern %q", s.pattern.String())
+	}
+
+	var valid bool
+	switch s.format {
+	case "date":
+		_, err := time.Parse(time.DateOnly, v)
+		valid = err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, v)
+		valid = err == nil
+	case "email":
+		addr, err := mail.ParseAddress(v)
+		valid = err == nil && addr.Name == "" && addr.Address == v
+	case "ipv4":
+		addr, err := netip.ParseAddr(v)
+		valid = err == nil && addr.Is4()
+	case "ipv6":
+		addr, err := netip.ParseAddr(v)
+		valid = err == nil && addr.Is6()
+	case "uri":
+		u, err := url.Parse(v)
+		valid = err == nil && u.Scheme != ""
+	case "uuid":
+		valid = uuidRegexp.MatchString(v)
+	default:
+		// the unknown formats are annotations only
+		return
+	}
+	if !valid {
+		fail("format", "must be a valid %s", s.format)
+	}
+}
+
+func (s *Schema) validateNumber(n float64, fail func(string, string, ...any)) {
+	if s.minimum != nil && n < *s.minimum {
+		fail("minimum", "must be >= %v", *s.minimum)
+	}
+	if s.maximum != nil && n > *s.maximum {
+		fail("maximum", "must be <= %v", *s.maximum)
+	}
+	if s.exclusiveMinimum != nil && n <= *s.exclusiveMinimum {
+		fail("exclusiveMinimum", "must be > %v", *s.exclusiveMinimum)
+	}
+	if s.exclusiveMaximum != nil && n >= *s.exclusiveMaximum {
+		fail("exclusiveMaximum", "must be < %v", *s.exclusiveMaximum)
+	}
+	if s.multipleOf != nil {
+		if q := n / *s.multipleOf; math.Abs(q-math.Round(q)) > 1e-9 {
+			fail("multipleOf", "must be a multiple of %v", *s.multipleOf)
+		}
+	}
+}
+
+func isType(v any, t string) bool {
+	switch t {
+	case "null":
+		return v == nil
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := number(v)
+		return ok
+	case "integer":
+		n, ok := number(v)
+		return ok && n == math.Trunc(n) && !math.IsInf(n, 0)
+	}
+	return false
+}
+
+// number returns the value of a JSON number.
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// equal reports whether the JSON values are equal, the numbers are compared by value.
+func equal(a, b any) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+
+	switch x := a.(type) {
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, xv := range x {
+			yv, ok := y[k]
+			if !ok || !equal(xv, yv) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		return ok && slices.EqualFunc(x, y, equal)
+	}
+	return a == b
+}
@@ -0,0 +1,150 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_ValidateJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		want   ValidationError
+	}{
+		{name: "true schema", schema: `true`, value: `1`},
+		{name: "false schema", schema: `false`, value: `1`, want: ValidationError{{Keyword: "false", Message: "is not allowed"}}},
+		{name: "type", schema: `{"type":"string"}`, value: `1`, want: ValidationError{{Keyword: "type", Message: "must be of type string"}}},
+		{name: "type list", schema: `{"type":["string","null"]}`, value: `null`},
+		{name: "integer", schema: `{"type":"integer"}`, value: `1.0`},
+		{name: "not integer", schema: `{"type":"integer"}`, value: `1.5`, want: ValidationError{{Keyword: "type", Message: "must be of type integer"}}},
+		{name: "enum", schema: `{"enum":["a",1]}`, value: `1.0`},
+		{name: "not in enum", schema: `{"enum":["a",1]}`, value: `"b"`, want: ValidationError{{Keyword: "enum", Message: "must be one of the allowed values"}}},
+		{name: "const", schema: `{"const":{"a":[1]}}`, value: `{"a":[1]}`},
+		{name: "not const", schema: `{"const":{"a":[1]}}`, value: `{"a":[2]}`, want: ValidationError{{Keyword: "const", Message: "must be equal to the constant value"}}},
+		{
+			name:   "object",
+			schema: `{"type":"object","required":["name","age"],"properties":{"name":{"type":"string"},"age":{"type":"integer","minimum":18}},"additionalProperties":false}`,
+			value:  `{"age":17,"extra":true}`,
+			want: ValidationError{
+				{Pointer: "/name", Keyword: "required", Message: "is required"},
+				{Pointer: "/age", Keyword: "minimum", Message: "must be >= 18"},
+				{Pointer: "/extra", Keyword: "additionalProperties", Message: "is not allowed"},
+			},
+		},
+		{
+			name:   "additional properties schema",
+			schema: `{"patternProperties":{"^x-":{"type":"string"}},"additionalProperties":{"type":"integer"}}`,
+			value:  `{"x-a":"a","b":1,"c":"c"}`,
+			want:   ValidationError{{Pointer: "/c", Keyword: "type", Message: "must be of type integer"}},
+		},
+		{
+			name:   "escaped pointer",
+			schema: `{"properties":{"a/b~c":{"type":"string"}}}`,
+			value:  `{"a/b~c":1}`,
+			want:   ValidationError{{Pointer: "/a~1b~0c", Keyword: "type", Message: "must be of type string"}},
+		},
+		{
+			name:   "object size",
+			schema: `{"minProperties":2,"maxProperties":1}`,
+			value:  `{"a":1}`,
+			want:   ValidationError{{Keyword: "minProperties", Message: "must have at least 2 properties"}},
+		},
+		{
+			name:   "array",
+			schema: `{"type":"array","prefixItems":[{"type":"string"}],"items":{"type":"integer"},"minItems":2,"maxItems":3,"uniqueItems":true}`,
+			value:  `["a",1,"b",1]`,
+			want: ValidationError{
+				{Keyword: "maxItems", Message: "must have at most 3 items"},
+				{Pointer: "/2", Keyword: "type", Message: "must be of type integer"},
+				{Keyword: "uniqueItems", Message: "must have unique items"},
+			},
+		},
+		{
+			name:   "string",
+			schema: `{"type":"string","minLength":3,"maxLength":4,"pattern":"^[a-z]+$"}`,
+			value:  `"Äb"`,
+			want: ValidationError{
+				{Keyword: "minLength", Message: "must be at least 3 characters long"},
+				{Keyword: "pattern", Message: `must match the pattern "^[a-z]+$"`},
+			},
+		},
+		{name: "date", schema: `{"format":"date"}`, value: `"2024-02-30"`, want: ValidationError{{Keyword: "format", Message: "must be a valid date"}}},
+		{name: "date-time", schema: `{"format":"date-time"}`, value: `"2024-02-01T10:00:00Z"`},
+		{name: "email", schema: `{"format":"email"}`, value: `"Jane <jane@example.com>"`, want: ValidationError{{Keyword: "format", Message: "must be a valid email"}}},
+		{name: "ipv4", schema: `{"format":"ipv4"}`, value: `"::1"`, want: ValidationError{{Keyword: "format", Message: "must be a valid ipv4"}}},
+		{name: "ipv6", schema: `{"format":"ipv6"}`, value: `"::1"`},
+		{name: "uri", schema: `{"format":"uri"}`, value: `"/relative"`, want: ValidationError{{Keyword: "format", Message: "must be a valid uri"}}},
+		{name: "uuid", schema: `{"format":"uuid"}`, value: `"0b4c5d3e-8c7f-4a53-9a39-5d0e2f8f0c11"`},
+		{name: "unknown format", schema: `{"format":"color"}`, value: `"red"`},
+		{
+			name:   "number",
+			schema: `{"exclusiveMinimum":0,"exclusiveMaximum":10,"maximum":5,"multipleOf":0.5}`,
+			value:  `7.25`,
+			want: ValidationError{
+				{Keyword: "maximum", Message: "must be <= 5"},
+				{Keyword: "multipleOf", Message: "must be a multiple of 0.5"},
+			},
+		},
+		{name: "exclusive minimum", schema: `{"exclusiveMinimum":0}`, value: `0`, want: ValidationError{{Keyword: "exclusiveMinimum", Message: "must be > 0"}}},
+		{
+			name:   "all of",
+			schema: `{"allOf":[{"type":"integer"},{"minimum":3}]}`,
+			value:  `2`,
+			want:   ValidationError{{Keyword: "minimum", Message: "must be >= 3"}},
+		},
+		{name: "any of", schema: `{"anyOf":[{"type":"integer"},{"type":"string"}]}`, value: `"a"`},
+		{name: "not any of", schema: `{"anyOf":[{"type":"integer"},{"type":"string"}]}`, value: `true`, want: ValidationError{{Keyword: "anyOf", Message: "must match at least one schema"}}},
+		{name: "one of", schema: `{"oneOf":[{"type":"integer"},{"minimum":1}]}`, value: `2`, want: ValidationError{{Keyword: "oneOf", Message: "must match exactly one schema, matched 2"}}},
+		{name: "not", schema: `{"not":{"type":"null"}}`, value: `null`, want: ValidationError{{Keyword: "not", Message: "must not match the schema"}}},
+		{
+			name:   "ref with siblings",
+			schema: `{"$defs":{"positive":{"minimum":1}},"$ref":"#/$defs/positive","maximum":3}`,
+			value:  `0`,
+			want:   ValidationError{{Keyword: "minimum", Message: "must be >= 1"}},
+		},
+		{
+			name:   "recursive ref",
+			schema: `{"type":"object","properties":{"name":{"type":"string"},"children":{"type":"array","items":{"$ref":"#"}}}}`,
+			value:  `{"name":"a","children":[{"name":"b","children":[{"name":1}]}]}`,
+			want:   ValidationError{{Pointer: "/children/0/children/0/name", Keyword: "type", Message: "must be of type string"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Compile([]byte(tt.schema))
+			require.NoError(t, err)
+
+			err = s.ValidateJSON([]byte(tt.value))
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.want, err)
+		})
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	s := MustCompile([]byte(`{"type":"object","properties":{"n":{"type":"integer","maximum":3}}}`))
+
+	assert.NoError(t, s.Validate(map[string]any{"n": 3}))
+	assert.NoError(t, s.Validate(map[string]any{"n": float64(2)}))
+	assert.NoError(t, s.Validate(map[string]any{"n": json.Number("1")}))
+	assert.Error(t, s.Validate(map[string]any{"n": int64(4)}))
+
+	assert.EqualError(t, s.ValidateJSON([]byte(`{`)), "jsonschema: decode value: unexpected EOF")
+}
+
+func TestValidationError_Error(t *testing.T) {
+	err := ValidationError{
+		{Keyword: "type", Message: "must be of type object"},
+		{Pointer: "/name", Keyword: "required", Message: "is required"},
+	}
+
+	assert.EqualError(t, err, "jsonschema: /: must be of type object; /name: is required")
+}
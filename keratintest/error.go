@@ -0,0 +1,89 @@
+package keratintest
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gowool/keratin"
+)
+
+// DecodeHTTPError decodes the error response rendered by [keratin.DefaultErrorHandler]
+// (JSON or plain text) or [keratin.ProblemDetailsErrorHandler] into an [keratin.HTTPError].
+// The Data of the problem details responses holds their extension members
+// except "error_code" and "details", which are decoded into ErrorCode and Details.
+func DecodeHTTPError(rec *httptest.ResponseRecorder) (*keratin.HTTPError, error) {
+	mediaType, _, _ := mime.ParseMediaType(rec.Header().Get(keratin.HeaderContentType))
+
+	switch mediaType {
+	case keratin.MIMEApplicationJSON:
+		var httpErr keratin.HTTPError
+		if err := json.Unmarshal(rec.Body.Bytes(), &httpErr); err != nil {
+			return nil, fmt.Errorf("decode error response: %w", err)
+		}
+		if httpErr.Code == 0 {
+			httpErr.Code = rec.Code
+		}
+		return &httpErr, nil
+	case keratin.MIMEApplicationProblemJSON:
+		var problem map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+			return nil, fmt.Errorf("decode problem response: %w", err)
+		}
+
+		httpErr := &keratin.HTTPError{Code: rec.Code}
+		if status, ok := problem["status"].(float64); ok {
+			httpErr.Code = int(status)
+		}
+		httpErr.Message, _ = problem["detail"].(string)
+		if httpErr.Message == "" {
+			httpErr.Message, _ = problem["title"].(string)
+		}
+		httpErr.ErrorCode, _ = problem["error_code"].(string)
+		httpErr.Details = problem["details"]
+		for _, key := range []string{"type", "title", "status", "detail", "instance", "error_code", "details"} {
+			delete(problem, key)
+		}
+		if len(problem) > 0 {
+			httpErr.Data = problem
+		}
+		return httpErr, nil
+	default:
+		return &keratin.HTTPError{
+			Code:    rec.Code,
+			Message: strings.TrimSuffix(rec.Body.String(), "\n"),
+		}, nil
+	}
+}
+
+// AssertHTTPError asserts that the recorded response is an error response with the given status code
+// and message, see [DecodeHTTPError]. An empty message is not checked.
+func AssertHTTPError(t testing.TB, rec *httptest.ResponseRecorder, code int, message string) bool {
+	t.Helper()
+
+	if rec.Code != code {
+		t.Errorf("status code: expected %d, got %d (body %q)", code, rec.Code, rec.Body.String())
+		return false
+	}
+
+	httpErr, err := DecodeHTTPError(rec)
+	if err != nil {
+		t.Error(err)
+		return false
+	}
+
+	if httpErr.Code != code {
+		t.Errorf("error code: expected %d, got %d", code, httpErr.Code)
+		return false
+	}
+
+	if message != "" && httpErr.Message != message {
+		t.Errorf("error message: expected %q, got %q", message, httpErr.Message)
+		return false
+	}
+
+	return true
+}
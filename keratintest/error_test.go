@@ -0,0 +1,92 @@
+package keratintest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gowool/keratin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeHTTPError(t *testing.T) {
+	err := keratin.NewHTTPError(http.StatusUnprocessableEntity, "invalid input")
+
+	tests := []struct {
+		name         string
+		errorHandler keratin.ErrorHandlerFunc
+		accept       string
+		want         *keratin.HTTPError
+	}{
+		{
+			name:         "json",
+			errorHandler: keratin.DefaultErrorHandler,
+			accept:       keratin.MIMEApplicationJSON,
+			want:         &keratin.HTTPError{Code: http.StatusUnprocessableEntity, Message: "invalid input"},
+		},
+		{
+			name:         "text",
+			errorHandler: keratin.DefaultErrorHandler,
+			want:         &keratin.HTTPError{Code: http.StatusUnprocessableEntity, Message: "invalid input"},
+		},
+		{
+			name:         "problem details",
+			errorHandler: keratin.ProblemDetailsErrorHandler,
+			want:         &keratin.HTTPError{Code: http.StatusUnprocessableEntity, Message: "invalid input"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRequest(http.MethodGet, "/", WithHeader(keratin.HeaderAccept, tt.accept))
+			rec := httptest.NewRecorder()
+			tt.errorHandler(rec, r, err)
+
+			got, decodeErr := DecodeHTTPError(rec)
+			require.NoError(t, decodeErr)
+			assert.Equal(t, tt.want.Code, got.Code)
+			assert.Equal(t, tt.want.Message, got.Message)
+
+			assert.True(t, AssertHTTPError(t, rec, http.StatusUnprocessableEntity, "invalid input"))
+		})
+	}
+}
+
+func TestDecodeHTTPError_ErrorCode(t *testing.T) {
+	sentinel := keratin.NewHTTPErrorWithCode(http.StatusNotFound, "user_not_found", "user not found")
+
+	for _, errorHandler := range []keratin.ErrorHandlerFunc{keratin.DefaultErrorHandler, keratin.ProblemDetailsErrorHandler} {
+		r := NewRequest(http.MethodGet, "/", WithHeader(keratin.HeaderAccept, keratin.MIMEApplicationJSON))
+		rec := httptest.NewRecorder()
+		errorHandler(rec, r, sentinel.WithDetails("42"))
+
+		got, err := DecodeHTTPError(rec)
+		require.NoError(t, err)
+		assert.Equal(t, "user_not_found", got.ErrorCode)
+		assert.Equal(t, "42", got.Details)
+		assert.Nil(t, got.Data)
+		assert.ErrorIs(t, got, sentinel)
+	}
+}
+
+func TestDecodeHTTPError_InvalidJSON(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set(keratin.HeaderContentType, keratin.MIMEApplicationJSON)
+	rec.WriteHeader(http.StatusBadRequest)
+	_, _ = rec.WriteString("{")
+
+	_, err := DecodeHTTPError(rec)
+	assert.Error(t, err)
+}
+
+func TestAssertHTTPError_Failures(t *testing.T) {
+	rec := httptest.NewRecorder()
+	keratin.DefaultErrorHandler(rec, NewRequest(http.MethodGet, "/"), errors.New("boom"))
+
+	mock := new(testing.T)
+	assert.False(t, AssertHTTPError(mock, rec, http.StatusBadRequest, ""))
+	assert.False(t, AssertHTTPError(mock, rec, http.StatusInternalServerError, "other"))
+	assert.True(t, AssertHTTPError(t, rec, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)))
+}
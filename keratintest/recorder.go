@@ -0,0 +1,133 @@
+// Package keratintest provides utilities for testing keratin handlers and middlewares.
+package keratintest
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/gowool/keratin"
+)
+
+var (
+	_ keratin.Committer   = (*ResponseRecorder)(nil)
+	_ keratin.StatusCoder = (*ResponseRecorder)(nil)
+	_ keratin.Sizer       = (*ResponseRecorder)(nil)
+	_ http.Flusher        = (*ResponseRecorder)(nil)
+	_ http.Hijacker       = (*ResponseRecorder)(nil)
+)
+
+// ResponseRecorder is an [httptest.ResponseRecorder] which also implements the keratin
+// response interfaces ([keratin.Committer], [keratin.StatusCoder], [keratin.Sizer])
+// and [http.Hijacker], so it can stand in for the router response writer.
+type ResponseRecorder struct {
+	*httptest.ResponseRecorder
+
+	mu        sync.Mutex
+	committed bool
+	size      int64
+	hijacked  bool
+	conn      net.Conn
+}
+
+// NewRecorder returns an initialized [ResponseRecorder].
+func NewRecorder() *ResponseRecorder {
+	return &ResponseRecorder{ResponseRecorder: httptest.NewRecorder()}
+}
+
+func (r *ResponseRecorder) WriteHeader(statusCode int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if statusCode < 100 || statusCode > 199 || statusCode == http.StatusSwitchingProtocols {
+		if r.committed {
+			return
+		}
+		r.committed = true
+	}
+	r.ResponseRecorder.WriteHeader(statusCode)
+}
+
+func (r *ResponseRecorder) Write(b []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.committed = true
+
+	n, err := r.ResponseRecorder.Write(b)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *ResponseRecorder) WriteString(s string) (int, error) {
+	return r.Write([]byte(s))
+}
+
+func (r *ResponseRecorder) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.committed = true
+	r.ResponseRecorder.Flush()
+}
+
+// Committed reports whether the response header has been written.
+func (r *ResponseRecorder) Committed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.committed
+}
+
+// StatusCode returns the written status code, or 0 when the header has not been written yet.
+func (r *ResponseRecorder) StatusCode() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.committed {
+		return 0
+	}
+	return r.Code
+}
+
+// Size returns the number of written body bytes.
+func (r *ResponseRecorder) Size() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.size
+}
+
+// Hijack implements [http.Hijacker] with an in-memory connection, see [ResponseRecorder.Conn].
+func (r *ResponseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.hijacked {
+		return nil, nil, http.ErrHijacked
+	}
+
+	server, client := net.Pipe()
+	r.hijacked = true
+	r.conn = client
+
+	return server, bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server)), nil
+}
+
+// Hijacked reports whether the connection has been hijacked.
+func (r *ResponseRecorder) Hijacked() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.hijacked
+}
+
+// Conn returns the client side of the hijacked connection, or nil if it has not been hijacked.
+func (r *ResponseRecorder) Conn() net.Conn {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.conn
+}
@@ -0,0 +1,60 @@
+package keratintest
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gowool/keratin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseRecorder(t *testing.T) {
+	rec := NewRecorder()
+
+	assert.False(t, rec.Committed())
+	assert.Equal(t, 0, rec.StatusCode())
+	assert.Equal(t, 0, keratin.ResponseStatusCode(rec))
+
+	rec.WriteHeader(http.StatusCreated)
+	rec.WriteHeader(http.StatusInternalServerError)
+	_, err := rec.WriteString("hello")
+	require.NoError(t, err)
+
+	assert.True(t, keratin.ResponseCommitted(rec))
+	assert.Equal(t, http.StatusCreated, keratin.ResponseStatusCode(rec))
+	assert.Equal(t, int64(5), keratin.ResponseSize(rec))
+	assert.Equal(t, "hello", rec.Body.String())
+}
+
+func TestResponseRecorder_Flush(t *testing.T) {
+	rec := NewRecorder()
+
+	require.NoError(t, http.NewResponseController(rec).Flush())
+	assert.True(t, rec.Committed())
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, http.StatusOK, rec.StatusCode())
+}
+
+func TestResponseRecorder_Hijack(t *testing.T) {
+	rec := NewRecorder()
+	assert.Nil(t, rec.Conn())
+
+	conn, rw, err := http.NewResponseController(rec).Hijack()
+	require.NoError(t, err)
+	require.NotNil(t, rw)
+	assert.True(t, rec.Hijacked())
+
+	go func() {
+		_, _ = conn.Write([]byte("ping"))
+		_ = conn.Close()
+	}()
+
+	b, err := io.ReadAll(rec.Conn())
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(b))
+
+	_, _, err = rec.Hijack()
+	assert.ErrorIs(t, err, http.ErrHijacked)
+}
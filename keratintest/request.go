@@ -0,0 +1,111 @@
+package keratintest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/gowool/keratin"
+)
+
+// RequestOption configures a request created by [NewRequest].
+type RequestOption func(*http.Request)
+
+// NewRequest returns a new incoming server request like [httptest.NewRequest], configured with the options.
+func NewRequest(method, target string, options ...RequestOption) *http.Request {
+	r := httptest.NewRequest(method, target, nil)
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+// WithBody sets the request body and its content type.
+func WithBody(contentType string, body []byte) RequestOption {
+	return func(r *http.Request) {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		r.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		if contentType != "" {
+			r.Header.Set(keratin.HeaderContentType, contentType)
+		}
+	}
+}
+
+// WithJSON sets the JSON encoded v as the request body. It panics if v cannot be encoded.
+func WithJSON(v any) RequestOption {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return WithBody(keratin.MIMEApplicationJSON, b)
+}
+
+// WithForm sets the URL encoded form values as the request body.
+func WithForm(values url.Values) RequestOption {
+	return WithBody(keratin.MIMEApplicationForm, []byte(values.Encode()))
+}
+
+// WithQuery adds the values to the request URL query.
+func WithQuery(values url.Values) RequestOption {
+	return func(r *http.Request) {
+		query := r.URL.Query()
+		for key, vs := range values {
+			for _, v := range vs {
+				query.Add(key, v)
+			}
+		}
+		r.URL.RawQuery = query.Encode()
+		r.RequestURI = r.URL.RequestURI()
+	}
+}
+
+// WithHeader sets the request header.
+func WithHeader(name, value string) RequestOption {
+	return func(r *http.Request) {
+		r.Header.Set(name, value)
+	}
+}
+
+// WithCookie adds the cookie to the request.
+func WithCookie(cookie *http.Cookie) RequestOption {
+	return func(r *http.Request) {
+		r.AddCookie(cookie)
+	}
+}
+
+// WithSession adds the session cookie with the given name and token to the request,
+// e.g. a token committed by a session store in the test setup.
+func WithSession(cookieName, token string) RequestOption {
+	return WithCookie(&http.Cookie{Name: cookieName, Value: token})
+}
+
+// WithContext replaces the request context.
+func WithContext(ctx context.Context) RequestOption {
+	return func(r *http.Request) {
+		*r = *r.WithContext(ctx)
+	}
+}
+
+// WithCanceledContext makes the request look like the client has already disconnected:
+// its context is canceled with [context.Canceled].
+func WithCanceledContext() RequestOption {
+	return func(r *http.Request) {
+		ctx, cancel := context.WithCancel(r.Context())
+		cancel()
+		*r = *r.WithContext(ctx)
+	}
+}
+
+// Disconnectable returns a copy of r with a cancelable context and the func
+// which simulates the client disconnection while the request is handled.
+func Disconnectable(r *http.Request) (*http.Request, func()) {
+	ctx, cancel := context.WithCancel(r.Context())
+	return r.WithContext(ctx), cancel
+}
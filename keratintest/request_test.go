@@ -0,0 +1,67 @@
+package keratintest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gowool/keratin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRequest(t *testing.T) {
+	type ctxKey struct{}
+
+	r := NewRequest(http.MethodPost, "/users?page=1",
+		WithJSON(map[string]string{"name": "john"}),
+		WithQuery(url.Values{"sort": {"name"}}),
+		WithHeader(keratin.HeaderAccept, keratin.MIMEApplicationJSON),
+		WithSession("session", "token"),
+		WithContext(context.WithValue(context.Background(), ctxKey{}, "value")),
+	)
+
+	body, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"name":"john"}`, string(body))
+	assert.Equal(t, int64(len(body)), r.ContentLength)
+	assert.Equal(t, keratin.MIMEApplicationJSON, r.Header.Get(keratin.HeaderContentType))
+	assert.Equal(t, keratin.MIMEApplicationJSON, r.Header.Get(keratin.HeaderAccept))
+	assert.Equal(t, "1", r.URL.Query().Get("page"))
+	assert.Equal(t, "name", r.URL.Query().Get("sort"))
+	assert.Equal(t, "/users?page=1&sort=name", r.RequestURI)
+	assert.Equal(t, "value", r.Context().Value(ctxKey{}))
+
+	cookie, err := r.Cookie("session")
+	require.NoError(t, err)
+	assert.Equal(t, "token", cookie.Value)
+}
+
+func TestWithForm(t *testing.T) {
+	r := NewRequest(http.MethodPost, "/", WithForm(url.Values{"a": {"1", "2"}}))
+
+	require.NoError(t, r.ParseForm())
+	assert.Equal(t, []string{"1", "2"}, r.PostForm["a"])
+	assert.Equal(t, keratin.MIMEApplicationForm, r.Header.Get(keratin.HeaderContentType))
+}
+
+func TestWithJSON_Invalid(t *testing.T) {
+	assert.Panics(t, func() { WithJSON(make(chan int)) })
+}
+
+func TestWithCanceledContext(t *testing.T) {
+	r := NewRequest(http.MethodGet, "/", WithCanceledContext())
+
+	assert.ErrorIs(t, r.Context().Err(), context.Canceled)
+}
+
+func TestDisconnectable(t *testing.T) {
+	r, disconnect := Disconnectable(NewRequest(http.MethodGet, "/"))
+	assert.NoError(t, r.Context().Err())
+
+	disconnect()
+	assert.ErrorIs(t, r.Context().Err(), context.Canceled)
+}
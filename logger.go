@@ -0,0 +1,62 @@
+package keratin
+
+import (
+	"context"
+	"log/slog"
+)
+
+// The attribute keys of the request-scoped loggers, see [LoggerFromContext].
+const (
+	LogKeyRequestID = "request_id"
+	LogKeyRoute     = "route"
+	LogKeyRealIP    = "real_ip"
+)
+
+// WithLoggerInjector sets the logger from which the router derives a request-scoped logger
+// for every request, see [LoggerFromContext].
+func WithLoggerInjector(logger *slog.Logger) Option {
+	return func(router *Router) {
+		if logger != nil {
+			router.logger = logger
+		}
+	}
+}
+
+// LoggerFromContext returns the request-scoped logger derived from the [WithLoggerInjector] logger
+// and enriched with the request ID, the matched route pattern and the client real IP.
+//
+// The attributes are taken when LoggerFromContext is called, so the logger should be obtained
+// after the RequestID middleware ran. It returns [slog.Default] when the router has no logger injected.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	c, ok := ctx.Value(ctxKey{}).(*kContext)
+	if !ok || c.baseLogger == nil {
+		return slog.Default()
+	}
+	return c.logger()
+}
+
+func (c *kContext) logger() *slog.Logger {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.reqLogger != nil && c.reqLoggerID == c.requestID && c.reqLoggerPattern == c.pattern {
+		return c.reqLogger
+	}
+
+	attrs := make([]any, 0, 3)
+	if c.requestID != "" {
+		attrs = append(attrs, slog.String(LogKeyRequestID, c.requestID))
+	}
+	if c.pattern != "" {
+		attrs = append(attrs, slog.String(LogKeyRoute, c.pattern))
+	}
+	if c.realIP != "" {
+		attrs = append(attrs, slog.String(LogKeyRealIP, c.realIP))
+	}
+
+	c.reqLogger = c.baseLogger.With(attrs...)
+	c.reqLoggerID = c.requestID
+	c.reqLoggerPattern = c.pattern
+
+	return c.reqLogger
+}
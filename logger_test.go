@@ -0,0 +1,80 @@
+package keratin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggerFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	base := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	router := NewRouter(WithLoggerInjector(base), WithIPExtractor(func(*http.Request) string { return "10.0.0.1" }))
+	router.UseFunc(func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			FromContext(r.Context()).SetRequestID("rid-1")
+			return next.ServeHTTP(w, r)
+		})
+	})
+
+	var first, second *slog.Logger
+	router.GET("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		first = LoggerFromContext(r.Context())
+		second = LoggerFromContext(r.Context())
+		first.InfoContext(r.Context(), "hello")
+		return nil
+	})
+
+	router.Build().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+	assert.Same(t, first, second)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "hello", record["msg"])
+	assert.Equal(t, "rid-1", record[LogKeyRequestID])
+	assert.Equal(t, "/users/{id}", record[LogKeyRoute])
+	assert.Equal(t, "10.0.0.1", record[LogKeyRealIP])
+}
+
+func TestLoggerFromContext_Default(t *testing.T) {
+	assert.Same(t, slog.Default(), LoggerFromContext(context.Background()))
+
+	var logger *slog.Logger
+	router := NewRouter(WithLoggerInjector(nil))
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		logger = LoggerFromContext(r.Context())
+		return nil
+	})
+	router.Build().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Same(t, slog.Default(), logger)
+}
+
+func TestKContext_logger_Refresh(t *testing.T) {
+	var buf bytes.Buffer
+
+	c := &kContext{baseLogger: slog.New(slog.NewTextHandler(&buf, nil))}
+
+	before := c.logger()
+	assert.Same(t, before, c.logger())
+
+	c.SetRequestID("rid")
+	after := c.logger()
+	assert.NotSame(t, before, after)
+
+	after.Info("msg")
+	assert.Contains(t, buf.String(), "request_id=rid")
+
+	c.reset()
+	assert.Nil(t, c.baseLogger)
+	assert.Nil(t, c.reqLogger)
+}
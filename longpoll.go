@@ -0,0 +1,75 @@
+package keratin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// HeaderXPollCursor is the long polling cursor header, see [LongPoll].
+const HeaderXPollCursor = "X-Poll-Cursor"
+
+// LongPollInterval is the delay between the poll calls of [LongPoll] which returned no events.
+var LongPollInterval = 250 * time.Millisecond
+
+// LongPollResponse is the body of the [LongPoll] responses with events.
+type LongPollResponse struct {
+	Events []any  `json:"events"`
+	Cursor string `json:"cursor"`
+}
+
+// LongPoll answers a long polling request: it calls poll with the client cursor (the X-Poll-Cursor header
+// or the "cursor" query param) until poll returns events or wait elapses, pausing [LongPollInterval]
+// between the empty polls. The poll context is done when wait elapses, so poll may also block itself.
+//
+// The events are sent as a 200 OK [LongPollResponse] JSON, a timeout as 204 No Content;
+// both responses carry the next cursor in the X-Poll-Cursor header, so the client resumes from it.
+// The poll errors are returned as is. When the client disconnects the context error is returned
+// without writing a response.
+func LongPoll(w http.ResponseWriter, r *http.Request, wait time.Duration, poll func(ctx context.Context, cursor string) (events []any, next string, err error)) error {
+	cursor := r.Header.Get(HeaderXPollCursor)
+	if cursor == "" {
+		cursor = r.URL.Query().Get("cursor")
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), wait)
+	defer cancel()
+
+	timer := time.NewTimer(LongPollInterval)
+	defer timer.Stop()
+
+	for {
+		events, next, err := poll(ctx, cursor)
+		if err != nil && (ctx.Err() == nil || !errors.Is(err, context.DeadlineExceeded)) {
+			return err
+		}
+		if next != "" {
+			cursor = next
+		}
+
+		if err == nil && len(events) > 0 {
+			w.Header().Set(HeaderXPollCursor, cursor)
+			w.Header().Set(HeaderCacheControl, "no-store")
+			return JSON(w, http.StatusOK, LongPollResponse{Events: events, Cursor: cursor})
+		}
+
+		if err == nil {
+			timer.Reset(LongPollInterval)
+			select {
+			case <-ctx.Done():
+			case <-timer.C:
+				continue
+			}
+		}
+
+		if err = r.Context().Err(); err != nil {
+			return err
+		}
+
+		w.Header().Set(HeaderXPollCursor, cursor)
+		w.Header().Set(HeaderCacheControl, "no-store")
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+}
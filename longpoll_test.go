@@ -0,0 +1,134 @@
+package keratin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLongPoll(t *testing.T) {
+	interval := LongPollInterval
+	LongPollInterval = time.Millisecond
+	defer func() { LongPollInterval = interval }()
+
+	errPoll := errors.New("poll failed")
+
+	tests := []struct {
+		name       string
+		target     string
+		header     string
+		poll       func(calls int, ctx context.Context, cursor string) ([]any, string, error)
+		wantCursor string
+		wantCode   int
+		wantBody   string
+		wantErr    error
+	}{
+		{
+			name:   "events",
+			header: "5",
+			poll: func(calls int, _ context.Context, cursor string) ([]any, string, error) {
+				assert.Equal(t, "5", cursor)
+				if calls < 3 {
+					return nil, "", nil
+				}
+				return []any{"a", "b"}, "7", nil
+			},
+			wantCursor: "7",
+			wantCode:   http.StatusOK,
+			wantBody:   `{"events":["a","b"],"cursor":"7"}`,
+		},
+		{
+			name:   "cursor query param",
+			target: "/?cursor=3",
+			poll: func(_ int, _ context.Context, cursor string) ([]any, string, error) {
+				return []any{cursor}, "", nil
+			},
+			wantCursor: "3",
+			wantCode:   http.StatusOK,
+			wantBody:   `{"events":["3"],"cursor":"3"}`,
+		},
+		{
+			name:   "timeout",
+			header: "5",
+			poll: func(_ int, _ context.Context, cursor string) ([]any, string, error) {
+				return nil, "6", nil
+			},
+			wantCursor: "6",
+			wantCode:   http.StatusNoContent,
+		},
+		{
+			name:   "blocking poll timeout",
+			header: "5",
+			poll: func(_ int, ctx context.Context, cursor string) ([]any, string, error) {
+				<-ctx.Done()
+				return nil, "", ctx.Err()
+			},
+			wantCursor: "5",
+			wantCode:   http.StatusNoContent,
+		},
+		{
+			name: "poll error",
+			poll: func(int, context.Context, string) ([]any, string, error) {
+				return nil, "", errPoll
+			},
+			wantCode: http.StatusOK,
+			wantErr:  errPoll,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := tt.target
+			if target == "" {
+				target = "/"
+			}
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			if tt.header != "" {
+				req.Header.Set(HeaderXPollCursor, tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			var calls int
+			err := LongPoll(rec, req, 20*time.Millisecond, func(ctx context.Context, cursor string) ([]any, string, error) {
+				calls++
+				return tt.poll(calls, ctx, cursor)
+			})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Equal(t, tt.wantCursor, rec.Header().Get(HeaderXPollCursor))
+			assert.Equal(t, "no-store", rec.Header().Get(HeaderCacheControl))
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			} else {
+				assert.Empty(t, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestLongPoll_ClientDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	err := LongPoll(rec, req, time.Minute, func(context.Context, string) ([]any, string, error) {
+		return nil, "", nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, rec.Flushed)
+	assert.Empty(t, rec.Header().Get(HeaderXPollCursor))
+}
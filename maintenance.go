@@ -0,0 +1,68 @@
+package keratin
+
+import "time"
+
+// WithMaintenanceRetryAfter sets the Retry-After value sent with 503 responses in maintenance mode.
+// Default value is 1 minute.
+func WithMaintenanceRetryAfter(retryAfter time.Duration) Option {
+	return func(router *Router) {
+		if retryAfter > 0 {
+			router.maintenanceRetryAfter = retryAfter
+		}
+	}
+}
+
+type maintenance struct {
+	err       error
+	allowlist map[string]struct{}
+}
+
+// allowed reports whether the route registered with the given pattern
+// (e.g. "GET /health") and path pattern (e.g. "/health") is allowlisted.
+func (m *maintenance) allowed(pattern, pathPattern string) bool {
+	if _, ok := m.allowlist[pattern]; ok {
+		return true
+	}
+	_, ok := m.allowlist[pathPattern]
+	return ok
+}
+
+// SetMaintenance switches the router in or out of maintenance mode at runtime.
+//
+// In maintenance mode all matched routes respond with 503 Service Unavailable and
+// the Retry-After header, except the allowlisted ones. An allowlist entry is either
+// a full route pattern (e.g. "GET /health") or a path pattern matching all methods (e.g. "/health").
+// Unmatched requests keep responding with 404/405.
+//
+// SetMaintenance is safe to call concurrently with serving requests.
+func (r *Router) SetMaintenance(enabled bool, allowlist ...string) {
+	if !enabled {
+		r.maintenance.Store(nil)
+		return
+	}
+
+	m := &maintenance{
+		err:       NewServiceUnavailableError(r.maintenanceRetryAfter),
+		allowlist: make(map[string]struct{}, len(allowlist)),
+	}
+	for _, pattern := range allowlist {
+		m.allowlist[pattern] = struct{}{}
+	}
+
+	r.maintenance.Store(m)
+}
+
+// InMaintenance reports whether the router is in maintenance mode.
+func (r *Router) InMaintenance() bool {
+	return r.maintenance.Load() != nil
+}
+
+// checkMaintenance returns the 503 Service Unavailable [RetryableError]
+// when the router is in maintenance mode and the route is not allowlisted.
+func (r *Router) checkMaintenance(pattern, pathPattern string) error {
+	m := r.maintenance.Load()
+	if m == nil || m.allowed(pattern, pathPattern) {
+		return nil
+	}
+	return m.err
+}
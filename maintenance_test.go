@@ -0,0 +1,71 @@
+package keratin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter_SetMaintenance(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, "ok")
+	}
+
+	router := NewRouter(WithMaintenanceRetryAfter(2 * time.Minute))
+	router.GET("/users", ok)
+	router.POST("/users", ok)
+	router.GET("/health", ok)
+	router.Any("/metrics", ok)
+
+	handler := router.Build()
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	assert.False(t, router.InMaintenance())
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/users").Code)
+
+	router.SetMaintenance(true, "GET /health", "/metrics")
+	assert.True(t, router.InMaintenance())
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{name: "blocked route", method: http.MethodGet, path: "/users", wantStatus: http.StatusServiceUnavailable},
+		{name: "blocked method", method: http.MethodPost, path: "/users", wantStatus: http.StatusServiceUnavailable},
+		{name: "allowlisted route", method: http.MethodGet, path: "/health", wantStatus: http.StatusOK},
+		{name: "allowlisted path", method: http.MethodDelete, path: "/metrics", wantStatus: http.StatusOK},
+		{name: "unknown route", method: http.MethodGet, path: "/unknown", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(tt.method, tt.path)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusServiceUnavailable {
+				assert.Equal(t, "120", w.Header().Get(HeaderRetryAfter))
+			} else {
+				assert.Empty(t, w.Header().Get(HeaderRetryAfter))
+			}
+		})
+	}
+
+	router.SetMaintenance(false)
+	assert.False(t, router.InMaintenance())
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/users").Code)
+}
+
+func TestWithMaintenanceRetryAfter(t *testing.T) {
+	assert.Equal(t, time.Minute, NewRouter(WithMaintenanceRetryAfter(0)).maintenanceRetryAfter)
+	assert.Equal(t, time.Second, NewRouter(WithMaintenanceRetryAfter(time.Second)).maintenanceRetryAfter)
+}
@@ -1,23 +1,38 @@
 package keratin
 
 import (
+	"errors"
+	"fmt"
+	"slices"
 	"sort"
+	"strings"
 
 	"github.com/google/uuid"
 )
 
+// ErrMiddlewareCycle is returned when the Before/After constraints of the middlewares form a cycle.
+var ErrMiddlewareCycle = errors.New("middleware order constraints form a cycle")
+
 type Middleware[H any] struct {
 	ID       string
 	Priority int
 	Func     func(H) H
+
+	// Before lists the IDs of the middlewares this one must run before (wrap).
+	// Unknown IDs are ignored.
+	Before []string
+
+	// After lists the IDs of the middlewares this one must run after (be wrapped by).
+	// Unknown IDs are ignored.
+	After []string
 }
 
 type Middlewares[H any] []*Middleware[H]
 
 func (mws Middlewares[H]) build(handler H) H {
-	sort.SliceStable(mws, func(i, j int) bool {
-		return mws[i].Priority < mws[j].Priority
-	})
+	if err := mws.sort(); err != nil {
+		panic(fmt.Errorf("keratin: %w", err))
+	}
 
 	for i := len(mws) - 1; i >= 0; i-- {
 		if mws[i].ID == "" {
@@ -28,3 +43,89 @@ func (mws Middlewares[H]) build(handler H) H {
 
 	return handler
 }
+
+// sort orders the middlewares in place by Priority and then by their Before/After constraints,
+// keeping the Priority (and registration) order for the unconstrained ones.
+func (mws Middlewares[H]) sort() error {
+	sort.SliceStable(mws, func(i, j int) bool {
+		return mws[i].Priority < mws[j].Priority
+	})
+
+	constrained := slices.ContainsFunc(mws, func(mw *Middleware[H]) bool {
+		return len(mw.Before) > 0 || len(mw.After) > 0
+	})
+	if !constrained {
+		return nil
+	}
+
+	byID := make(map[string][]int, len(mws))
+	for i, mw := range mws {
+		if mw.ID != "" {
+			byID[mw.ID] = append(byID[mw.ID], i)
+		}
+	}
+
+	// deps[i] lists the middlewares which must run before i
+	deps := make([][]int, len(mws))
+	for i, mw := range mws {
+		for _, id := range mw.Before {
+			for _, j := range byID[id] {
+				deps[j] = append(deps[j], i)
+			}
+		}
+		for _, id := range mw.After {
+			deps[i] = append(deps[i], byID[id]...)
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	var (
+		sorted = make(Middlewares[H], 0, len(mws))
+		state  = make([]int, len(mws))
+		path   []string
+		visit  func(i int) error
+	)
+
+	// visit places the dependencies of each middleware right before it,
+	// so the Priority order is changed as little as possible
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := append(path[slices.Index(path, mws[i].ID):], mws[i].ID)
+			return fmt.Errorf("%w: %s", ErrMiddlewareCycle, strings.Join(cycle, " -> "))
+		}
+
+		state[i] = visiting
+		path = append(path, mws[i].ID)
+
+		for _, j := range deps[i] {
+			if j != i {
+				if err := visit(j); err != nil {
+					return err
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[i] = visited
+		sorted = append(sorted, mws[i])
+
+		return nil
+	}
+
+	for i := range mws {
+		if err := visit(i); err != nil {
+			return err
+		}
+	}
+
+	copy(mws, sorted)
+	return nil
+}
@@ -0,0 +1,387 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gowool/keratin"
+)
+
+const (
+	// CommonLogFormat is the Apache Common Log Format.
+	CommonLogFormat = `%h %l %u %t "%r" %>s %b`
+	// CombinedLogFormat is the Apache Combined Log Format.
+	CombinedLogFormat = CommonLogFormat + ` "%{Referer}i" "%{User-Agent}i"`
+)
+
+type AccessLogConfig struct {
+	// Writer is the destination of the log lines.
+	// Optional. Default value os.Stdout.
+	Writer io.Writer `json:"-" yaml:"-"`
+
+	// Format is "common", "combined" or a custom format made of Apache mod_log_config directives:
+	//
+	//	%h        client IP
+	//	%l        remote logname (always "-")
+	//	%u        basic auth user or "-"
+	//	%t        time the request was received, [02/Jan/2006:15:04:05 -0700]
+	//	%r        request line, e.g. GET /path?q=1 HTTP/1.1
+	//	%s, %>s   status code
+	//	%b        response size in bytes or "-" when zero
+	//	%B        response size in bytes
+	//	%D        time taken to serve the request in microseconds
+	//	%T        time taken to serve the request in seconds
+	//	%H        request protocol
+	//	%m        request method
+	//	%U        URL path
+	//	%q        query string prefixed with "?" or empty
+	//	%v        request host
+	//	%{Name}i  request header
+	//	%{Name}o  response header
+	//	%%        literal percent sign
+	//
+	// Optional. Default value "common".
+	Format string `env:"FORMAT" json:"format,omitempty" yaml:"format,omitempty"`
+
+	// BufferSize enables buffering of the log lines. Lines are always written whole,
+	// so the destination can be rotated (e.g. by logrotate copytruncate) without broken lines.
+	// Optional. Default value 0 (unbuffered).
+	BufferSize int `env:"BUFFER_SIZE" json:"bufferSize,omitempty" yaml:"bufferSize,omitempty"`
+
+	// FlushInterval is the maximum time buffered lines are kept before being written.
+	// Optional. Default value 1s.
+	FlushInterval time.Duration `env:"FLUSH_INTERVAL" json:"flushInterval,omitempty" yaml:"flushInterval,omitempty"`
+}
+
+func (c *AccessLogConfig) SetDefaults() {
+	if c.Writer == nil {
+		c.Writer = os.Stdout
+	}
+	switch strings.ToLower(c.Format) {
+	case "", "common":
+		c.Format = CommonLogFormat
+	case "combined":
+		c.Format = CombinedLogFormat
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = time.Second
+	}
+}
+
+type accessLogEntry struct {
+	w       http.ResponseWriter
+	r       *http.Request
+	code    int
+	start   time.Time
+	latency time.Duration
+}
+
+type accessLogDirective func(buf []byte, e *accessLogEntry) []byte
+
+// AccessLogger writes the access log lines of [AccessLogger.Middleware].
+type AccessLogger struct {
+	directives []accessLogDirective
+	out        *accessLogWriter
+	pool       sync.Pool
+}
+
+// NewAccessLogger returns an AccessLogger writing in the Apache Common,
+// Combined or a custom log format, see [AccessLogConfig.Format].
+// It panics if the format contains an unknown directive.
+func NewAccessLogger(cfg AccessLogConfig) *AccessLogger {
+	cfg.SetDefaults()
+
+	directives, err := compileAccessLogFormat(cfg.Format)
+	if err != nil {
+		panic(err)
+	}
+
+	l := &AccessLogger{
+		directives: directives,
+		out:        newAccessLogWriter(cfg.Writer, cfg.BufferSize, cfg.FlushInterval),
+	}
+	l.pool.New = func() any {
+		buf := make([]byte, 0, 256)
+		return &buf
+	}
+	return l
+}
+
+// AccessLog returns a middleware that writes one line per request in the Apache Common,
+// Combined or a custom log format, see [AccessLogConfig.Format].
+// It panics if the format contains an unknown directive.
+//
+// Use [NewAccessLogger] to flush the buffered lines (see AccessLogConfig.BufferSize) at shutdown.
+func AccessLog(cfg AccessLogConfig, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	return NewAccessLogger(cfg).Middleware(skippers...)
+}
+
+// Middleware returns a middleware that writes one line per request.
+func (l *AccessLogger) Middleware(skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	skip := ChainSkipper(skippers...)
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if skip(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			start := time.Now()
+
+			err := next.ServeHTTP(w, r)
+
+			e := accessLogEntry{w: w, r: r, start: start, latency: time.Since(start)}
+			if err == nil {
+				e.code = keratin.ResponseStatusCode(w)
+			} else {
+				e.code = keratin.HTTPErrorStatusCode(err)
+			}
+
+			bufPtr := l.pool.Get().(*[]byte)
+			buf := (*bufPtr)[:0]
+			for _, d := range l.directives {
+				buf = d(buf, &e)
+			}
+			buf = append(buf, '\n')
+
+			l.out.write(buf)
+
+			*bufPtr = buf
+			l.pool.Put(bufPtr)
+
+			return err
+		})
+	}
+}
+
+// Flush writes the buffered lines to the destination, e.g. at shutdown.
+func (l *AccessLogger) Flush() error {
+	return l.out.flush()
+}
+
+func compileAccessLogFormat(format string) ([]accessLogDirective, error) {
+	var directives []accessLogDirective
+
+	literal := func(s string) {
+		if s != "" {
+			directives = append(directives, func(buf []byte, _ *accessLogEntry) []byte {
+				return append(buf, s...)
+			})
+		}
+	}
+
+	for {
+		index := strings.IndexByte(format, '%')
+		if index < 0 || index == len(format)-1 {
+			literal(format)
+			return directives, nil
+		}
+
+		literal(format[:index])
+		format = format[index+1:]
+
+		if format[0] == '{' {
+			end := strings.IndexByte(format, '}')
+			if end < 0 || end == len(format)-1 {
+				return nil, fmt.Errorf("access log: unterminated directive %q", "%"+format)
+			}
+
+			name := format[1:end]
+			switch format[end+1] {
+			case 'i':
+				directives = append(directives, func(buf []byte, e *accessLogEntry) []byte {
+					return appendEscapedOrDash(buf, e.r.Header.Get(name))
+				})
+			case 'o':
+				directives = append(directives, func(buf []byte, e *accessLogEntry) []byte {
+					return appendEscapedOrDash(buf, e.w.Header().Get(name))
+				})
+			default:
+				return nil, fmt.Errorf("access log: unknown directive %q", "%"+format[:end+2])
+			}
+
+			format = format[end+2:]
+			continue
+		}
+
+		if format[0] == '>' && len(format) > 1 && format[1] == 's' {
+			format = format[1:]
+		}
+
+		directive, ok := accessLogDirectives[format[0]]
+		if !ok {
+			return nil, fmt.Errorf("access log: unknown directive %q", "%"+format[:1])
+		}
+		directives = append(directives, directive)
+		format = format[1:]
+	}
+}
+
+var accessLogDirectives = map[byte]accessLogDirective{
+	'%': func(buf []byte, _ *accessLogEntry) []byte {
+		return append(buf, '%')
+	},
+	'h': func(buf []byte, e *accessLogEntry) []byte {
+		return appendOrDash(buf, keratin.FromContext(e.r.Context()).RealIP())
+	},
+	'l': func(buf []byte, _ *accessLogEntry) []byte {
+		return append(buf, '-')
+	},
+	'u': func(buf []byte, e *accessLogEntry) []byte {
+		user, _, _ := e.r.BasicAuth()
+		return appendEscapedOrDash(buf, user)
+	},
+	't': func(buf []byte, e *accessLogEntry) []byte {
+		buf = append(buf, '[')
+		buf = e.start.AppendFormat(buf, "02/Jan/2006:15:04:05 -0700")
+		return append(buf, ']')
+	},
+	'r': func(buf []byte, e *accessLogEntry) []byte {
+		buf = appendEscaped(buf, e.r.Method)
+		buf = append(buf, ' ')
+		buf = appendEscaped(buf, e.r.URL.RequestURI())
+		buf = append(buf, ' ')
+		return appendEscaped(buf, e.r.Proto)
+	},
+	's': func(buf []byte, e *accessLogEntry) []byte {
+		return strconv.AppendInt(buf, int64(e.code), 10)
+	},
+	'b': func(buf []byte, e *accessLogEntry) []byte {
+		if size := keratin.ResponseSize(e.w); size > 0 {
+			return strconv.AppendInt(buf, size, 10)
+		}
+		return append(buf, '-')
+	},
+	'B': func(buf []byte, e *accessLogEntry) []byte {
+		return strconv.AppendInt(buf, keratin.ResponseSize(e.w), 10)
+	},
+	'D': func(buf []byte, e *accessLogEntry) []byte {
+		return strconv.AppendInt(buf, e.latency.Microseconds(), 10)
+	},
+	'T': func(buf []byte, e *accessLogEntry) []byte {
+		return strconv.AppendInt(buf, int64(e.latency/time.Second), 10)
+	},
+	'H': func(buf []byte, e *accessLogEntry) []byte {
+		return appendEscaped(buf, e.r.Proto)
+	},
+	'm': func(buf []byte, e *accessLogEntry) []byte {
+		return appendEscaped(buf, e.r.Method)
+	},
+	'U': func(buf []byte, e *accessLogEntry) []byte {
+		return appendEscaped(buf, e.r.URL.Path)
+	},
+	'q': func(buf []byte, e *accessLogEntry) []byte {
+		if e.r.URL.RawQuery == "" {
+			return buf
+		}
+		buf = append(buf, '?')
+		return appendEscaped(buf, e.r.URL.RawQuery)
+	},
+	'v': func(buf []byte, e *accessLogEntry) []byte {
+		return appendEscapedOrDash(buf, e.r.Host)
+	},
+}
+
+func appendOrDash(buf []byte, s string) []byte {
+	if s == "" {
+		return append(buf, '-')
+	}
+	return append(buf, s...)
+}
+
+func appendEscapedOrDash(buf []byte, s string) []byte {
+	if s == "" {
+		return append(buf, '-')
+	}
+	return appendEscaped(buf, s)
+}
+
+// appendEscaped appends the client controlled s escaped like Apache mod_log_config does,
+// so that it cannot forge log lines or break the quoted fields: quotes and backslashes
+// are escaped with a backslash, control and non-ASCII bytes as \xhh.
+func appendEscaped(buf []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			buf = append(buf, '\\', c)
+		case '\b':
+			buf = append(buf, '\\', 'b')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		case '\v':
+			buf = append(buf, '\\', 'v')
+		default:
+			if c < 0x20 || c >= 0x7f {
+				buf = append(buf, '\\', 'x', hex[c>>4], hex[c&0xf])
+			} else {
+				buf = append(buf, c)
+			}
+		}
+	}
+	return buf
+}
+
+// accessLogWriter writes whole log lines to the destination, optionally buffered.
+type accessLogWriter struct {
+	mu       sync.Mutex
+	w        io.Writer
+	buf      *bufio.Writer
+	interval time.Duration
+	timer    *time.Timer
+}
+
+func newAccessLogWriter(w io.Writer, size int, interval time.Duration) *accessLogWriter {
+	out := &accessLogWriter{w: w, interval: interval}
+	if size > 0 {
+		out.buf = bufio.NewWriterSize(w, size)
+	}
+	return out
+}
+
+func (w *accessLogWriter) write(line []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf == nil {
+		_, _ = w.w.Write(line)
+		return
+	}
+
+	// flush before the line would be split between two writes
+	if len(line) > w.buf.Available() && w.buf.Buffered() > 0 {
+		_ = w.buf.Flush()
+	}
+	_, _ = w.buf.Write(line)
+
+	if w.buf.Buffered() > 0 && w.timer == nil {
+		w.timer = time.AfterFunc(w.interval, func() { _ = w.flush() })
+	}
+}
+
+func (w *accessLogWriter) flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf == nil {
+		return nil
+	}
+
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	return w.buf.Flush()
+}
@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestAccessLogConfig_SetDefaults(t *testing.T) {
+	cfg := AccessLogConfig{}
+	cfg.SetDefaults()
+	assert.NotNil(t, cfg.Writer)
+	assert.Equal(t, CommonLogFormat, cfg.Format)
+	assert.Equal(t, time.Second, cfg.FlushInterval)
+
+	cfg = AccessLogConfig{Format: "Combined"}
+	cfg.SetDefaults()
+	assert.Equal(t, CombinedLogFormat, cfg.Format)
+
+	cfg = AccessLogConfig{Format: "%m %U"}
+	cfg.SetDefaults()
+	assert.Equal(t, "%m %U", cfg.Format)
+}
+
+func TestAccessLog(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		handler func(w http.ResponseWriter, r *http.Request) error
+		want    string
+	}{
+		{
+			name:   "common",
+			format: "common",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				return keratin.TextPlain(w, http.StatusOK, "hello")
+			},
+			want: `^192\.0\.2\.1 - frank \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /path\?q=1 HTTP/1\.1" 200 5\n$`,
+		},
+		{
+			name:   "combined",
+			format: "combined",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusNoContent)
+				return nil
+			},
+			want: `^192\.0\.2\.1 - frank \[.+\] "GET /path\?q=1 HTTP/1\.1" 204 - "https://example\.com/" "test-agent"\n$`,
+		},
+		{
+			name:   "custom with error",
+			format: `%m %U%q %>s %B %{X-Out}o %{X-Missing}i %v 100%% %Dus`,
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("X-Out", "out")
+				return keratin.ErrTeapot
+			},
+			want: `^GET /path\?q=1 418 0 out - example\.com 100% \d+us\n$`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := new(syncBuffer)
+
+			router := keratin.NewRouter()
+			router.UseFunc(AccessLog(AccessLogConfig{Writer: out, Format: tt.format}))
+			router.GET("/path", tt.handler)
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/path?q=1", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			req.SetBasicAuth("frank", "secret")
+			req.Header.Set("Referer", "https://example.com/")
+			req.Header.Set("User-Agent", "test-agent")
+
+			router.Build().ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Regexp(t, regexp.MustCompile(tt.want), out.String())
+		})
+	}
+}
+
+func TestAccessLog_InvalidFormat(t *testing.T) {
+	for _, format := range []string{"%x", "%{Referer}", "%{Referer}z"} {
+		assert.Panics(t, func() {
+			AccessLog(AccessLogConfig{Writer: new(syncBuffer), Format: format})
+		}, format)
+	}
+}
+
+func TestAccessLog_Buffered(t *testing.T) {
+	out := new(syncBuffer)
+
+	mw := AccessLog(AccessLogConfig{
+		Writer:        out,
+		Format:        "%m %U",
+		BufferSize:    1024,
+		FlushInterval: 10 * time.Millisecond,
+	})
+	h := mw(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}))
+
+	for range 3 {
+		require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil)))
+	}
+
+	assert.Empty(t, out.String())
+	assert.Eventually(t, func() bool {
+		return out.String() == strings.Repeat("GET /a\n", 3)
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestAccessLogWriter_WholeLines(t *testing.T) {
+	out := new(syncBuffer)
+	w := newAccessLogWriter(out, 8, time.Hour)
+
+	w.write([]byte("12345\n"))
+	w.write([]byte("67890\n"))
+
+	// the first line is flushed whole before the second one would overflow the buffer
+	assert.Equal(t, "12345\n", out.String())
+
+	require.NoError(t, w.flush())
+	assert.Equal(t, "12345\n67890\n", out.String())
+}
+
+func TestAccessLog_Escaping(t *testing.T) {
+	out := new(syncBuffer)
+
+	router := keratin.NewRouter()
+	router.UseFunc(AccessLog(AccessLogConfig{Writer: out, Format: "combined"}))
+	router.GET("/path", func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/path", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.SetBasicAuth("frank\" 200 5\n192.0.2.9 - admin", "secret")
+	req.Header.Set("Referer", `\"x\"`)
+	req.Header.Set("User-Agent", "agent\x01\xff")
+
+	router.Build().ServeHTTP(httptest.NewRecorder(), req)
+
+	line := out.String()
+	assert.Equal(t, 1, strings.Count(line, "\n"))
+	assert.Contains(t, line, ` - frank\" 200 5\n192.0.2.9 - admin [`)
+	assert.True(t, strings.HasSuffix(line, ` "\\\"x\\\"" "agent\x01\xff"`+"\n"), line)
+}
+
+func TestAccessLogger_Flush(t *testing.T) {
+	out := new(syncBuffer)
+
+	logger := NewAccessLogger(AccessLogConfig{Writer: out, Format: "%m %U", BufferSize: 1024, FlushInterval: time.Hour})
+	h := logger.Middleware()(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}))
+
+	require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil)))
+	assert.Empty(t, out.String())
+
+	require.NoError(t, logger.Flush())
+	assert.Equal(t, "GET /a\n", out.String())
+
+	// unbuffered
+	require.NoError(t, NewAccessLogger(AccessLogConfig{Writer: out}).Flush())
+}
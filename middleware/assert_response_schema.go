@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gowool/keratin"
+	"github.com/gowool/keratin/jsonschema"
+)
+
+// ErrResponseSchemaViolation replaces the responses which do not match their JSON Schema
+// when AssertResponseSchemaConfig.Fail is set, the details are the [jsonschema.Error] list.
+var ErrResponseSchemaViolation = keratin.NewHTTPErrorWithCode(http.StatusInternalServerError, "response_schema_violation", "Response body does not match the schema.")
+
+type AssertResponseSchemaConfig struct {
+	// Enabled turns the assertions on. Keep it off in production,
+	// the disabled middleware passes the requests through untouched.
+	// Optional. Default value false.
+	Enabled bool `env:"ENABLED" json:"enabled,omitempty" yaml:"enabled,omitempty"`
+
+	// Fail replaces the mismatching responses with ErrResponseSchemaViolation (500),
+	// otherwise they are only logged and sent as is.
+	// Optional. Default value false.
+	Fail bool `env:"FAIL" json:"fail,omitempty" yaml:"fail,omitempty"`
+
+	// FS holds the JSON Schema documents.
+	// Required when enabled.
+	FS fs.FS `json:"-" yaml:"-"`
+
+	// Routes maps the route patterns, with the method (e.g. "GET /users/{id}") or without it,
+	// to the schema of their responses. The routes missing here use the [keratin.Route] ResponseSchema.
+	// Optional.
+	Routes map[string]string `json:"routes,omitempty" yaml:"routes,omitempty"`
+
+	// MaxSize is the maximum size of the checked response bodies,
+	// the larger (and the flushed) responses are streamed without the check.
+	// Optional. Default value 1MB.
+	MaxSize int64 `env:"MAX_SIZE" json:"maxSize,omitempty" yaml:"maxSize,omitempty"`
+}
+
+func (c *AssertResponseSchemaConfig) SetDefaults() {
+	if c.MaxSize <= 0 {
+		c.MaxSize = maxBufferSize
+	}
+}
+
+// AssertResponseSchema returns a middleware validating the successful (2xx) JSON responses against
+// the JSON Schema documents of AssertResponseSchemaConfig.FS, to catch the contract drift in development and tests.
+//
+// The schema of a route is its Routes entry or else the [keratin.Route] ResponseSchema (see [keratin.Route.Returns]).
+// The response is buffered until the handler returns, the mismatches are logged at the warn level
+// with the request-scoped logger and, if AssertResponseSchemaConfig.Fail is set, the response is replaced
+// by [ErrResponseSchemaViolation].
+//
+// The Routes schemas are compiled when the middleware is created, it panics if they are invalid.
+func AssertResponseSchema(cfg AssertResponseSchemaConfig, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	if !cfg.Enabled {
+		return func(next keratin.Handler) keratin.Handler { return next }
+	}
+	if cfg.FS == nil {
+		panic("middleware: assert response schema: schema fs is required")
+	}
+
+	cfg.SetDefaults()
+
+	skip := ChainSkipper(skippers...)
+	registry := jsonschema.NewRegistry(cfg.FS)
+
+	for _, name := range cfg.Routes {
+		if _, err := registry.Schema(name); err != nil {
+			panic("middleware: assert response schema: " + err.Error())
+		}
+	}
+
+	pool := &sync.Pool{
+		New: func() any { return new(bufferWriter) },
+	}
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if skip(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			name := routeSchema(r, cfg.Routes, func(route *keratin.Route) string { return route.ResponseSchema })
+			if name == "" {
+				return next.ServeHTTP(w, r)
+			}
+
+			schema, err := registry.Schema(name)
+			if err != nil {
+				return err
+			}
+
+			bw := pool.Get().(*bufferWriter)
+			bw.reset(w, cfg.MaxSize)
+
+			defer func() {
+				bw.reset(nil, 0)
+				pool.Put(bw)
+			}()
+
+			if err = next.ServeHTTP(bw, r); err != nil {
+				return err
+			}
+
+			if err = checkResponse(bw, schema); err != nil {
+				keratin.LoggerFromContext(r.Context()).LogAttrs(r.Context(), slog.LevelWarn, "response schema violation",
+					slog.String("method", r.Method),
+					slog.String("pattern", r.Pattern),
+					slog.Int("status", bw.StatusCode()),
+					slog.String("schema", name),
+					slog.String("error", err.Error()),
+				)
+
+				if cfg.Fail {
+					var details []jsonschema.Error
+					if ve, ok := errors.AsType[jsonschema.ValidationError](err); ok {
+						details = ve
+					}
+					return ErrResponseSchemaViolation.WithDetails(details).Wrap(err)
+				}
+			}
+
+			return bw.spill()
+		})
+	}
+}
+
+// checkResponse validates the buffered successful JSON response, the other responses are not checked.
+func checkResponse(bw *bufferWriter, schema *jsonschema.Schema) error {
+	if bw.spilled || bw.code < 200 || bw.code > 299 || bw.code == http.StatusNoContent {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(bw.header.Get(keratin.HeaderContentType))
+	if mediaType != keratin.MIMEApplicationJSON && !strings.HasSuffix(mediaType, "+json") {
+		return nil
+	}
+
+	return schema.ValidateJSON(bw.buf.Bytes())
+}
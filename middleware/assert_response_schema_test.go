@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+var responseSchemaTestFS = fstest.MapFS{
+	"user.json": {Data: []byte(`{
+		"type": "object",
+		"required": ["id", "name"],
+		"properties": {
+			"id": {"type": "integer"},
+			"name": {"type": "string"}
+		}
+	}`)},
+	"users.json":  {Data: []byte(`{"type": "array", "items": {"$ref": "user.json"}}`)},
+	"broken.json": {Data: []byte(`{"type": "object", "pattern": "("}`)},
+}
+
+func newAssertResponseSchemaRouter(cfg AssertResponseSchemaConfig, logs *bytes.Buffer) http.Handler {
+	router := keratin.NewRouter(keratin.WithLoggerInjector(slog.New(slog.NewTextHandler(logs, nil))))
+	router.UseFunc(AssertResponseSchema(cfg))
+
+	router.GET("/users", func(w http.ResponseWriter, _ *http.Request) error {
+		return keratin.JSON(w, http.StatusOK, []map[string]any{{"id": 1, "name": "Jane"}})
+	})
+	router.GET("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		switch r.PathValue("id") {
+		case "1":
+			return keratin.JSON(w, http.StatusOK, map[string]any{"id": 1, "name": "Jane"})
+		case "2":
+			return keratin.JSON(w, http.StatusOK, map[string]any{"id": "2"})
+		case "3":
+			return keratin.TextPlain(w, http.StatusOK, "Jane")
+		case "4":
+			_, _ = w.Write([]byte(`{"id":"4"}`))
+			return errors.New("handler error")
+		}
+		return keratin.JSON(w, http.StatusNotFound, map[string]any{"message": "Not Found"})
+	}).Returns("user.json")
+	router.GET("/broken", func(w http.ResponseWriter, _ *http.Request) error {
+		return keratin.JSON(w, http.StatusOK, map[string]any{})
+	}).Returns("broken.json")
+	router.GET("/free", func(w http.ResponseWriter, _ *http.Request) error {
+		return keratin.JSON(w, http.StatusOK, map[string]any{"any": true})
+	})
+
+	return router.Build()
+}
+
+func TestAssertResponseSchema(t *testing.T) {
+	tests := []struct {
+		name        string
+		fail        bool
+		target      string
+		wantCode    int
+		wantBody    string
+		wantLog     bool
+		wantDetails string
+	}{
+		{
+			name:     "valid",
+			target:   "/users/1",
+			wantCode: http.StatusOK,
+			wantBody: `{"id":1,"name":"Jane"}` + "\n",
+		},
+		{
+			name:     "valid routes entry",
+			target:   "/users",
+			wantCode: http.StatusOK,
+			wantBody: `[{"id":1,"name":"Jane"}]` + "\n",
+		},
+		{
+			name:     "invalid logged",
+			target:   "/users/2",
+			wantCode: http.StatusOK,
+			wantBody: `{"id":"2"}` + "\n",
+			wantLog:  true,
+		},
+		{
+			name:     "invalid failed",
+			fail:     true,
+			target:   "/users/2",
+			wantCode: http.StatusInternalServerError,
+			wantLog:  true,
+			wantDetails: `[
+				{"pointer":"/name","keyword":"required","message":"is required"},
+				{"pointer":"/id","keyword":"type","message":"must be of type integer"}
+			]`,
+		},
+		{
+			name:     "not json",
+			fail:     true,
+			target:   "/users/3",
+			wantCode: http.StatusOK,
+			wantBody: "Jane",
+		},
+		{
+			name:     "handler error",
+			fail:     true,
+			target:   "/users/4",
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "not successful",
+			fail:     true,
+			target:   "/users/5",
+			wantCode: http.StatusNotFound,
+			wantBody: `{"message":"Not Found"}` + "\n",
+		},
+		{
+			name:     "broken route schema",
+			target:   "/broken",
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "route without schema",
+			fail:     true,
+			target:   "/free",
+			wantCode: http.StatusOK,
+			wantBody: `{"any":true}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			handler := newAssertResponseSchemaRouter(AssertResponseSchemaConfig{
+				Enabled: true,
+				Fail:    tt.fail,
+				FS:      responseSchemaTestFS,
+				Routes:  map[string]string{"GET /users": "users.json"},
+			}, &logs)
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set(keratin.HeaderAccept, keratin.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+			}
+			if tt.wantDetails != "" {
+				assert.JSONEq(t, `{
+					"code": 500,
+					"message": "Response body does not match the schema.",
+					"error_code": "response_schema_violation",
+					"details": `+tt.wantDetails+`
+				}`, rec.Body.String())
+			}
+			if tt.wantLog {
+				assert.Contains(t, logs.String(), `level=WARN msg="response schema violation"`)
+				assert.Contains(t, logs.String(), "schema=user.json")
+			} else {
+				assert.NotContains(t, logs.String(), "response schema violation")
+			}
+		})
+	}
+}
+
+func TestAssertResponseSchema_Disabled(t *testing.T) {
+	var logs bytes.Buffer
+	handler := newAssertResponseSchemaRouter(AssertResponseSchemaConfig{Fail: true}, &logs)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/2", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id":"2"}`, rec.Body.String())
+	assert.Empty(t, logs.String())
+}
+
+func TestAssertResponseSchema_Panics(t *testing.T) {
+	assert.PanicsWithValue(t, "middleware: assert response schema: schema fs is required", func() {
+		AssertResponseSchema(AssertResponseSchemaConfig{Enabled: true})
+	})
+	assert.Panics(t, func() {
+		AssertResponseSchema(AssertResponseSchemaConfig{Enabled: true, FS: responseSchemaTestFS, Routes: map[string]string{"/broken": "broken.json"}})
+	})
+	require.NotPanics(t, func() {
+		AssertResponseSchema(AssertResponseSchemaConfig{FS: nil})
+	})
+}
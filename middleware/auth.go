@@ -0,0 +1,250 @@
+package middleware
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gowool/keratin"
+)
+
+// ErrNoCredentials is returned by an [Authenticator] when the request carries no credentials
+// it understands, so the next authenticator is tried.
+var ErrNoCredentials = errors.New("no credentials")
+
+// Principal is an authenticated user, service or device.
+type Principal interface {
+	// Subject returns the unique identifier of the principal.
+	Subject() string
+}
+
+// Identity is a general purpose [Principal].
+type Identity struct {
+	// ID is the principal unique identifier, e.g. the user ID or the API key name.
+	ID string `json:"id"`
+
+	// Scheme is the authentication scheme the principal was authenticated with, e.g. "bearer".
+	Scheme string `json:"scheme,omitempty"`
+
+	// Roles are the principal roles.
+	Roles []string `json:"roles,omitempty"`
+
+	// Attributes are the additional principal attributes, e.g. the token claims.
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+func (i *Identity) Subject() string {
+	return i.ID
+}
+
+// HasRole reports whether the identity has the role.
+func (i *Identity) HasRole(role string) bool {
+	return slices.Contains(i.Roles, role)
+}
+
+// Authenticator authenticates a request.
+//
+// It returns [ErrNoCredentials] if the request has no credentials for it,
+// any other error rejects the credentials. Errors without HTTP status code
+// are reported as 401 Unauthorized, return e.g. keratin.ErrForbidden to respond with 403.
+type Authenticator interface {
+	Authenticate(r *http.Request) (Principal, error)
+}
+
+// AuthenticatorFunc is an adapter to allow the use of ordinary functions as [Authenticator].
+type AuthenticatorFunc func(r *http.Request) (Principal, error)
+
+func (f AuthenticatorFunc) Authenticate(r *http.Request) (Principal, error) {
+	return f(r)
+}
+
+// Challenger can be implemented by an [Authenticator] to add the WWW-Authenticate
+// challenge to the 401 Unauthorized responses, e.g. `Bearer realm="api"`.
+type Challenger interface {
+	Challenge() string
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal.
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// CtxPrincipal returns the principal authenticated by [Auth], nil for anonymous requests.
+func CtxPrincipal(ctx context.Context) Principal {
+	principal, _ := ctx.Value(principalKey{}).(Principal)
+	return principal
+}
+
+// PrincipalAs returns the principal authenticated by [Auth] as T.
+//
+//	user, ok := middleware.PrincipalAs[*User](r.Context())
+func PrincipalAs[T Principal](ctx context.Context) (T, bool) {
+	principal, ok := ctx.Value(principalKey{}).(T)
+	return principal, ok
+}
+
+type AuthConfig struct {
+	// Authenticators are tried in order, the first one returning a principal wins.
+	// Required.
+	Authenticators []Authenticator `json:"-" yaml:"-"`
+
+	// Optional lets the requests without credentials through anonymously,
+	// the requests with rejected credentials still fail.
+	// Optional. Default value false.
+	Optional bool `env:"OPTIONAL" json:"optional,omitempty" yaml:"optional,omitempty"`
+
+	// ErrorHandler defines a function which is executed for returning custom errors.
+	ErrorHandler func(r *http.Request, err error) error `json:"-" yaml:"-"`
+}
+
+// Auth authenticates the requests with the configured authenticators and stores the principal
+// in the request context, see [CtxPrincipal] and [PrincipalAs].
+//
+// Requests without credentials and with rejected credentials fail with 401 Unauthorized
+// unless the authenticator error has its own HTTP status code, e.g. 403 Forbidden.
+func Auth(cfg AuthConfig, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	if len(cfg.Authenticators) == 0 {
+		panic("middleware: auth: at least one authenticator is required")
+	}
+
+	var challenges []string
+	for _, authenticator := range cfg.Authenticators {
+		if c, ok := authenticator.(Challenger); ok {
+			challenges = append(challenges, c.Challenge())
+		}
+	}
+
+	skip := ChainSkipper(skippers...)
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if skip(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			var authErr error
+			for _, authenticator := range cfg.Authenticators {
+				principal, err := authenticator.Authenticate(r)
+				if err == nil && principal != nil {
+					return next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+				}
+				if err != nil && !errors.Is(err, ErrNoCredentials) && authErr == nil {
+					authErr = err
+				}
+			}
+
+			if authErr == nil {
+				if cfg.Optional {
+					return next.ServeHTTP(w, r)
+				}
+				authErr = ErrNoCredentials
+			}
+
+			if keratin.ErrorStatusCode(authErr) < http.StatusBadRequest {
+				authErr = keratin.ErrUnauthorized.Wrap(authErr)
+			}
+			if keratin.ErrorStatusCode(authErr) == http.StatusUnauthorized {
+				for _, challenge := range challenges {
+					w.Header().Add(keratin.HeaderWWWAuthenticate, challenge)
+				}
+			}
+
+			if cfg.ErrorHandler != nil {
+				return cfg.ErrorHandler(r, authErr)
+			}
+			return authErr
+		})
+	}
+}
+
+// KeyAuthenticator authenticates the requests with a key (e.g. an API key or a bearer token)
+// extracted with the lookup, see [CreateExtractors] for its format, e.g. "header:X-API-Key"
+// or "header:Authorization:Bearer ".
+func KeyAuthenticator(lookup string, validate func(ctx context.Context, key string) (Principal, error)) Authenticator {
+	if validate == nil {
+		panic("middleware: auth: key validator is required")
+	}
+
+	extractors, err := CreateExtractors(lookup, 1)
+	if err != nil {
+		panic(fmt.Errorf("middleware: auth: %w", err))
+	}
+
+	return AuthenticatorFunc(func(r *http.Request) (Principal, error) {
+		for _, extractor := range extractors {
+			keys, _, err := extractor(r)
+			if err != nil || len(keys) == 0 {
+				continue
+			}
+			return validate(r.Context(), keys[0])
+		}
+		return nil, ErrNoCredentials
+	})
+}
+
+type bearerAuthenticator struct {
+	Authenticator
+	realm string
+}
+
+func (a bearerAuthenticator) Challenge() string {
+	if a.realm == "" {
+		return "Bearer"
+	}
+	return fmt.Sprintf("Bearer realm=%q", a.realm)
+}
+
+// BearerAuthenticator authenticates the requests with the "Authorization: Bearer <token>" header,
+// e.g. validating a JWT. The realm is used in the WWW-Authenticate challenge.
+func BearerAuthenticator(realm string, validate func(ctx context.Context, token string) (Principal, error)) Authenticator {
+	return bearerAuthenticator{
+		Authenticator: KeyAuthenticator("header:"+keratin.HeaderAuthorization+":Bearer ", validate),
+		realm:         realm,
+	}
+}
+
+type basicAuthenticator struct {
+	validate func(ctx context.Context, username, password string) (Principal, error)
+	realm    string
+}
+
+func (a basicAuthenticator) Authenticate(r *http.Request) (Principal, error) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	return a.validate(r.Context(), username, password)
+}
+
+func (a basicAuthenticator) Challenge() string {
+	return fmt.Sprintf("Basic realm=%q, charset=\"UTF-8\"", a.realm)
+}
+
+// BasicAuthenticator authenticates the requests with the HTTP Basic authentication.
+// The realm is used in the WWW-Authenticate challenge.
+func BasicAuthenticator(realm string, validate func(ctx context.Context, username, password string) (Principal, error)) Authenticator {
+	if validate == nil {
+		panic("middleware: auth: basic validator is required")
+	}
+	return basicAuthenticator{validate: validate, realm: realm}
+}
+
+// ClientCertAuthenticator authenticates the requests with the verified TLS client certificate (mTLS).
+// The server must be configured to request and verify the client certificates.
+func ClientCertAuthenticator(validate func(ctx context.Context, cert *x509.Certificate) (Principal, error)) Authenticator {
+	if validate == nil {
+		panic("middleware: auth: certificate validator is required")
+	}
+
+	return AuthenticatorFunc(func(r *http.Request) (Principal, error) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+			return nil, ErrNoCredentials
+		}
+		return validate(r.Context(), r.TLS.VerifiedChains[0][0])
+	})
+}
@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+type authTestUser struct {
+	name string
+}
+
+func (u *authTestUser) Subject() string {
+	return u.name
+}
+
+func TestAuth(t *testing.T) {
+	bearer := BearerAuthenticator("api", func(_ context.Context, token string) (Principal, error) {
+		switch token {
+		case "valid":
+			return &authTestUser{name: "john"}, nil
+		case "banned":
+			return nil, keratin.ErrForbidden
+		}
+		return nil, errors.New("invalid token")
+	})
+	apiKey := KeyAuthenticator("header:X-API-Key", func(_ context.Context, key string) (Principal, error) {
+		if key == "secret" {
+			return &Identity{ID: "service", Scheme: "api-key"}, nil
+		}
+		return nil, errors.New("invalid key")
+	})
+	basic := BasicAuthenticator("admin", func(_ context.Context, username, password string) (Principal, error) {
+		if username == "admin" && password == "pass" {
+			return &Identity{ID: "admin", Scheme: "basic"}, nil
+		}
+		return nil, errors.New("invalid password")
+	})
+
+	challenges := []string{`Bearer realm="api"`, `Basic realm="admin", charset="UTF-8"`}
+
+	tests := []struct {
+		name          string
+		optional      bool
+		headers       map[string]string
+		wantSubject   string
+		wantCode      int
+		wantChallenge []string
+	}{
+		{name: "bearer", headers: map[string]string{"Authorization": "Bearer valid"}, wantSubject: "john"},
+		{name: "api key", headers: map[string]string{"X-API-Key": "secret"}, wantSubject: "service"},
+		{name: "basic", headers: map[string]string{"Authorization": "Basic YWRtaW46cGFzcw=="}, wantSubject: "admin"},
+		{name: "no credentials", wantCode: http.StatusUnauthorized, wantChallenge: challenges},
+		{name: "optional without credentials", optional: true},
+		{name: "optional with invalid credentials", optional: true, headers: map[string]string{"X-API-Key": "wrong"}, wantCode: http.StatusUnauthorized, wantChallenge: challenges},
+		{name: "invalid token", headers: map[string]string{"Authorization": "Bearer wrong"}, wantCode: http.StatusUnauthorized, wantChallenge: challenges},
+		{name: "forbidden", headers: map[string]string{"Authorization": "Bearer banned"}, wantCode: http.StatusForbidden},
+		{name: "first success wins", headers: map[string]string{"Authorization": "Bearer wrong", "X-API-Key": "secret"}, wantSubject: "service"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var subject string
+			h := Auth(AuthConfig{
+				Authenticators: []Authenticator{bearer, apiKey, basic},
+				Optional:       tt.optional,
+			})(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				if p := CtxPrincipal(r.Context()); p != nil {
+					subject = p.Subject()
+				}
+				return nil
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+
+			err := h.ServeHTTP(rec, req)
+			if tt.wantCode != 0 {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantCode, keratin.HTTPErrorStatusCode(err))
+				assert.Equal(t, tt.wantChallenge, rec.Header().Values(keratin.HeaderWWWAuthenticate))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSubject, subject)
+		})
+	}
+}
+
+func TestAuth_ErrorHandler(t *testing.T) {
+	h := Auth(AuthConfig{
+		Authenticators: []Authenticator{AuthenticatorFunc(func(r *http.Request) (Principal, error) {
+			return nil, ErrNoCredentials
+		})},
+		ErrorHandler: func(r *http.Request, err error) error {
+			return keratin.NewHTTPError(http.StatusTeapot, err.Error())
+		},
+	})(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}))
+
+	err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusTeapot, keratin.HTTPErrorStatusCode(err))
+}
+
+func TestAuth_Skipper(t *testing.T) {
+	called := false
+	h := Auth(AuthConfig{
+		Authenticators: []Authenticator{AuthenticatorFunc(func(r *http.Request) (Principal, error) {
+			return nil, ErrNoCredentials
+		})},
+	}, func(r *http.Request) bool { return true })(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		called = true
+		return nil
+	}))
+
+	require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil)))
+	assert.True(t, called)
+}
+
+func TestPrincipalAs(t *testing.T) {
+	ctx := WithPrincipal(context.Background(), &authTestUser{name: "john"})
+
+	user, ok := PrincipalAs[*authTestUser](ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "john", user.Subject())
+
+	_, ok = PrincipalAs[*Identity](ctx)
+	assert.False(t, ok)
+
+	assert.Nil(t, CtxPrincipal(context.Background()))
+}
+
+func TestClientCertAuthenticator(t *testing.T) {
+	authenticator := ClientCertAuthenticator(func(_ context.Context, cert *x509.Certificate) (Principal, error) {
+		return &Identity{ID: cert.Subject.CommonName, Scheme: "mtls"}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	_, err := authenticator.Authenticate(req)
+	assert.ErrorIs(t, err, ErrNoCredentials)
+
+	cert := &x509.Certificate{}
+	cert.Subject.CommonName = "device-1"
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+
+	p, err := authenticator.Authenticate(req)
+	require.NoError(t, err)
+	assert.Equal(t, "device-1", p.Subject())
+}
+
+func TestKeyAuthenticator_JSONLookup(t *testing.T) {
+	auth := KeyAuthenticator("header:X-API-Key,json:credentials.api_key", func(_ context.Context, key string) (Principal, error) {
+		if key == "secret" {
+			return &Identity{ID: "service"}, nil
+		}
+		return nil, errors.New("invalid key")
+	})
+
+	body := `{"credentials":{"api_key":"secret"},"name":"test"}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	principal, err := auth.Authenticate(req)
+	require.NoError(t, err)
+	assert.Equal(t, "service", principal.Subject())
+
+	rest, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(rest))
+}
+
+func TestAuth_Panics(t *testing.T) {
+	assert.Panics(t, func() { Auth(AuthConfig{}) })
+	assert.Panics(t, func() { KeyAuthenticator("header:X-API-Key", nil) })
+	assert.Panics(t, func() {
+		KeyAuthenticator("invalid", func(context.Context, string) (Principal, error) { return nil, nil })
+	})
+	assert.Panics(t, func() {
+		KeyAuthenticator("unknown:x", func(context.Context, string) (Principal, error) { return nil, nil })
+	})
+}
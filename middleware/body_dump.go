@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gowool/keratin"
+)
+
+// BodyDumpHandler receives the captured request and response bodies.
+// The bodies are only valid until BodyDumpHandler returns and must be copied to be retained.
+type BodyDumpHandler func(r *http.Request, reqBody, resBody []byte)
+
+type BodyDumpConfig struct {
+	// Handler receives the captured bodies after the request has been handled.
+	// Required.
+	Handler BodyDumpHandler `json:"-" yaml:"-"`
+
+	// MaxBodySize is the maximum number of bytes captured per body.
+	// Larger bodies are still streamed as is, only the captured part is truncated.
+	// Optional. Default value 64KB.
+	MaxBodySize int `env:"MAX_BODY_SIZE" json:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty"`
+
+	// ContentTypes is a list of media types whose bodies are captured, "type/*" wildcards are supported.
+	// Optional. Default value JSON, XML, form and text types.
+	ContentTypes []string `env:"CONTENT_TYPES" json:"contentTypes,omitempty" yaml:"contentTypes,omitempty"`
+}
+
+func (c *BodyDumpConfig) SetDefaults() {
+	if c.MaxBodySize <= 0 {
+		c.MaxBodySize = 64 << 10
+	}
+
+	if len(c.ContentTypes) == 0 {
+		c.ContentTypes = defaultBodyContentTypes()
+	}
+}
+
+// BodyDump returns a middleware which captures the request and response bodies
+// and passes them to [BodyDumpConfig.Handler], e.g. for audit trails.
+//
+// The request body is teed while the handler reads it and the response body while it is written,
+// so streaming responses are not buffered. A request body buffered by [keratin.BufferBody] in the outer
+// middlewares is dumped as is. The handler is also called for failed requests.
+func BodyDump(cfg BodyDumpConfig, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	if cfg.Handler == nil {
+		panic("body dump middleware requires a handler")
+	}
+
+	cfg.SetDefaults()
+
+	skip := ChainSkipper(skippers...)
+
+	pool := &sync.Pool{
+		New: func() any { return new(bodyCapture) },
+	}
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if skip(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			bc := pool.Get().(*bodyCapture)
+			bc.reset(cfg.MaxBodySize)
+
+			defer func() {
+				bc.reset(0)
+				pool.Put(bc)
+			}()
+
+			var buffered []byte
+			if r.Body != nil && r.Body != http.NoBody && keratin.MatchMediaType(r.Header.Get(keratin.HeaderContentType), cfg.ContentTypes...) {
+				// a body buffered by the outer middlewares may be read many times, it is dumped as is
+				if data, ok := keratin.BufferedBody(r); ok {
+					buffered = data[:min(len(data), cfg.MaxBodySize)]
+				} else {
+					bc.body = r.Body
+					r.Body = bc.requestReader()
+				}
+			}
+
+			bc.ResponseWriter = w
+
+			err := next.ServeHTTP(bc, r)
+
+			reqBody, resBody := buffered, []byte(nil)
+			if bc.body != nil {
+				reqBody = bc.reqBuf.Bytes()
+			}
+			if keratin.MatchMediaType(w.Header().Get(keratin.HeaderContentType), cfg.ContentTypes...) {
+				resBody = bc.resBuf.Bytes()
+			}
+
+			cfg.Handler(r, reqBody, resBody)
+
+			return err
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gowool/keratin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyDumpConfig_SetDefaults(t *testing.T) {
+	cfg := BodyDumpConfig{}
+	cfg.SetDefaults()
+
+	assert.Equal(t, 64<<10, cfg.MaxBodySize)
+	assert.Equal(t, defaultBodyContentTypes(), cfg.ContentTypes)
+
+	cfg = BodyDumpConfig{MaxBodySize: 10, ContentTypes: []string{"text/plain"}}
+	cfg.SetDefaults()
+
+	assert.Equal(t, 10, cfg.MaxBodySize)
+	assert.Equal(t, []string{"text/plain"}, cfg.ContentTypes)
+}
+
+func TestBodyDump_RequiresHandler(t *testing.T) {
+	assert.PanicsWithValue(t, "body dump middleware requires a handler", func() {
+		BodyDump(BodyDumpConfig{})
+	})
+}
+
+func TestBodyDump(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            BodyDumpConfig
+		reqContentType string
+		reqBody        string
+		resContentType string
+		resBody        string
+		handlerErr     error
+		wantReq        string
+		wantRes        string
+	}{
+		{
+			name:           "captures both bodies",
+			reqContentType: keratin.MIMEApplicationJSON,
+			reqBody:        `{"name":"john"}`,
+			resContentType: keratin.MIMEApplicationJSON + "; " + keratin.CharsetUTF8,
+			resBody:        `{"id":1}`,
+			wantReq:        `{"name":"john"}`,
+			wantRes:        `{"id":1}`,
+		},
+		{
+			name:           "truncates to max body size",
+			cfg:            BodyDumpConfig{MaxBodySize: 4},
+			reqContentType: keratin.MIMETextPlain,
+			reqBody:        "request body",
+			resContentType: keratin.MIMETextPlain,
+			resBody:        "response body",
+			wantReq:        "requ",
+			wantRes:        "resp",
+		},
+		{
+			name:           "skips filtered content types",
+			reqContentType: keratin.MIMEOctetStream,
+			reqBody:        "binary",
+			resContentType: "image/png",
+			resBody:        "png",
+		},
+		{
+			name:           "failed request",
+			reqContentType: keratin.MIMETextPlain,
+			reqBody:        "data",
+			handlerErr:     errors.New("boom"),
+			wantReq:        "data",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				called           bool
+				gotReq, gotRes   string
+				handlerBody      string
+				dumpedRequestURI string
+			)
+
+			tt.cfg.Handler = func(r *http.Request, reqBody, resBody []byte) {
+				called = true
+				dumpedRequestURI = r.RequestURI
+				gotReq = string(reqBody)
+				gotRes = string(resBody)
+			}
+
+			h := BodyDump(tt.cfg)(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				b, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				handlerBody = string(b)
+
+				if tt.handlerErr != nil {
+					return tt.handlerErr
+				}
+				return keratin.Blob(w, http.StatusOK, tt.resContentType, []byte(tt.resBody))
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/audit", strings.NewReader(tt.reqBody))
+			req.Header.Set(keratin.HeaderContentType, tt.reqContentType)
+			w := httptest.NewRecorder()
+
+			err := h.ServeHTTP(w, req)
+
+			assert.ErrorIs(t, err, tt.handlerErr)
+			assert.True(t, called)
+			assert.Equal(t, "/audit", dumpedRequestURI)
+			assert.Equal(t, tt.reqBody, handlerBody)
+			assert.Equal(t, tt.wantReq, gotReq)
+			assert.Equal(t, tt.wantRes, gotRes)
+			if tt.handlerErr == nil {
+				assert.Equal(t, tt.resBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestBodyDump_Skipper(t *testing.T) {
+	called := false
+
+	h := BodyDump(BodyDumpConfig{Handler: func(*http.Request, []byte, []byte) { called = true }},
+		func(*http.Request) bool { return true },
+	)(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}))
+
+	require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil)))
+	assert.False(t, called)
+}
+
+func TestBodyDump_Flush(t *testing.T) {
+	h := BodyDump(BodyDumpConfig{Handler: func(*http.Request, []byte, []byte) {}})(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		_, _ = w.Write([]byte("chunk"))
+		return http.NewResponseController(w).Flush()
+	}))
+
+	w := httptest.NewRecorder()
+	require.NoError(t, h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil)))
+	assert.True(t, w.Flushed)
+}
+
+func TestBodyDump_BufferedBody(t *testing.T) {
+	var dumped string
+
+	buffer := func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if _, err := keratin.BufferBody(r, 0); err != nil {
+				return err
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+
+	dump := BodyDump(BodyDumpConfig{
+		MaxBodySize: 8,
+		Handler: func(_ *http.Request, reqBody, _ []byte) {
+			dumped = string(reqBody)
+		},
+	})
+
+	h := buffer(dump(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		// read twice, the body must not be dumped twice
+		for range 2 {
+			if _, err := io.ReadAll(r.Body); err != nil {
+				return err
+			}
+			keratin.RewindBody(r)
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"keratin"}`))
+	req.Header.Set(keratin.HeaderContentType, keratin.MIMEApplicationJSON)
+
+	require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), req))
+	assert.Equal(t, `{"name":`, dumped)
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"errors"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -599,3 +600,34 @@ func TestBodyLimit_EdgeCases(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 	})
 }
+
+func TestBodyLimit_MultipartReader(t *testing.T) {
+	body := new(strings.Builder)
+	mw := multipart.NewWriter(body)
+	fw, err := mw.CreateFormFile("file", "file.txt")
+	require.NoError(t, err)
+	_, err = io.WriteString(fw, strings.Repeat("a", 4096))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	router := keratin.NewRouter()
+	router.UseFunc(BodyLimit(BodyLimitConfig{Limit: 1024}))
+	router.POST("/", func(w http.ResponseWriter, r *http.Request) error {
+		stream, err := keratin.MultipartReader(r)
+		if err != nil {
+			return err
+		}
+		return stream.Each(func(part *keratin.MultipartPart) error {
+			_, err := io.Copy(io.Discard, part)
+			return err
+		})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body.String()))
+	req.Header.Set(keratin.HeaderContentType, mw.FormDataContentType())
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+	router.Build().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
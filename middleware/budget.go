@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gowool/keratin"
+)
+
+const (
+	// HeaderRequestTimeout is the default header of the request deadline budget, see [Budget].
+	HeaderRequestTimeout = "X-Request-Timeout"
+	// HeaderGRPCTimeout is the gRPC deadline header, e.g. "100m" for 100 milliseconds.
+	HeaderGRPCTimeout = "Grpc-Timeout"
+)
+
+type budgetKey struct{}
+
+// InjectBudget sets the remaining deadline budget of ctx in the header of an outbound request,
+// in the header read by [Budget]. It does nothing without the Budget middleware or when
+// the budget is already spent.
+func InjectBudget(ctx context.Context, header http.Header) {
+	name, _ := ctx.Value(budgetKey{}).(string)
+	if name == "" {
+		return
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return
+	}
+
+	if remaining := time.Until(deadline); remaining > 0 {
+		header.Set(name, formatBudget(name, remaining))
+	}
+}
+
+// Budget returns a middleware setting the request context deadline from the remaining budget sent
+// by the caller in header (X-Request-Timeout by default), capped by maxBudget. The requests without
+// a valid budget get the maxBudget one. The remaining budget is written to the outbound requests
+// by [InjectBudget], so the deadline is propagated end to end.
+//
+// The budget is a duration (e.g. "1.5s"), a number of milliseconds (e.g. "1500")
+// or a grpc-timeout value (e.g. "1500m"). A handler failing with the deadline error
+// is reported as 503 Service Unavailable.
+//
+// It panics if maxBudget is not positive.
+func Budget(header string, maxBudget time.Duration, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	if maxBudget <= 0 {
+		panic("middleware: budget: max budget must be positive")
+	}
+	if header == "" {
+		header = HeaderRequestTimeout
+	}
+	header = textproto.CanonicalMIMEHeaderKey(header)
+
+	skip := ChainSkipper(skippers...)
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if skip(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			budget := maxBudget
+			if d, ok := parseBudget(r.Header.Get(header)); ok && d < maxBudget {
+				budget = d
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), budget)
+			defer cancel()
+
+			ctx = context.WithValue(ctx, budgetKey{}, header)
+
+			err := next.ServeHTTP(w, r.WithContext(ctx))
+			if err != nil && errors.Is(err, context.DeadlineExceeded) && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return keratin.ErrServiceUnavailable.Wrap(err)
+			}
+			return err
+		})
+	}
+}
+
+// parseBudget parses a duration, a number of milliseconds or a grpc-timeout value.
+func parseBudget(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(ms) * time.Millisecond, ms >= 0
+	}
+
+	if d, ok := parseGRPCTimeout(value); ok {
+		return d, true
+	}
+
+	d, err := time.ParseDuration(value)
+	return d, err == nil && d >= 0
+}
+
+var grpcTimeoutUnits = map[byte]time.Duration{
+	'H': time.Hour,
+	'M': time.Minute,
+	'S': time.Second,
+	'm': time.Millisecond,
+	'u': time.Microsecond,
+	'n': time.Nanosecond,
+}
+
+// parseGRPCTimeout parses a grpc-timeout value: at most 8 digits followed by the unit.
+func parseGRPCTimeout(value string) (time.Duration, bool) {
+	if len(value) < 2 || len(value) > 9 {
+		return 0, false
+	}
+
+	unit, ok := grpcTimeoutUnits[value[len(value)-1]]
+	if !ok {
+		return 0, false
+	}
+
+	n, err := strconv.ParseUint(value[:len(value)-1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(n) * unit, true
+}
+
+// formatBudget formats the remaining budget as a grpc-timeout value for the Grpc-Timeout header,
+// as a number of milliseconds otherwise.
+func formatBudget(header string, remaining time.Duration) string {
+	ms := max(remaining.Milliseconds(), 1)
+
+	if header != HeaderGRPCTimeout {
+		return strconv.FormatInt(ms, 10)
+	}
+	if ms < 1e8 {
+		return strconv.FormatInt(ms, 10) + "m"
+	}
+	return strconv.FormatInt(int64(remaining/time.Second), 10) + "S"
+}
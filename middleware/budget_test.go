@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+func TestBudget(t *testing.T) {
+	var remaining time.Duration
+	router := keratin.NewRouter()
+	router.UseFunc(Budget("", time.Minute))
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		deadline, ok := r.Context().Deadline()
+		require.True(t, ok)
+		remaining = time.Until(deadline)
+		return nil
+	})
+	router.GET("/slow", func(w http.ResponseWriter, r *http.Request) error {
+		<-r.Context().Done()
+		return r.Context().Err()
+	})
+	handler := router.Build()
+
+	tests := []struct {
+		name   string
+		budget string
+		want   time.Duration
+	}{
+		{name: "missing", want: time.Minute},
+		{name: "duration", budget: "1.5s", want: 1500 * time.Millisecond},
+		{name: "milliseconds", budget: "2500", want: 2500 * time.Millisecond},
+		{name: "grpc timeout", budget: "3S", want: 3 * time.Second},
+		{name: "capped", budget: "1h", want: time.Minute},
+		{name: "invalid", budget: "soon", want: time.Minute},
+		{name: "negative", budget: "-1s", want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.budget != "" {
+				req.Header.Set(HeaderRequestTimeout, tt.budget)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.InDelta(t, tt.want, remaining, float64(100*time.Millisecond))
+		})
+	}
+
+	t.Run("spent", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+		req.Header.Set(HeaderRequestTimeout, "10")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+}
+
+func TestInjectBudget(t *testing.T) {
+	var received http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: PropagatingTransport(nil)}
+
+	router := keratin.NewRouter()
+	router.UseFunc(Budget(HeaderGRPCTimeout, time.Minute))
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, upstream.URL, nil)
+		if err != nil {
+			return err
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		return res.Body.Close()
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderGRPCTimeout, "2000m")
+	rec := httptest.NewRecorder()
+	router.Build().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	value := received.Get(HeaderGRPCTimeout)
+	require.NotEmpty(t, value)
+	assert.Equal(t, byte('m'), value[len(value)-1])
+	ms, err := strconv.Atoi(value[:len(value)-1])
+	require.NoError(t, err)
+	assert.InDelta(t, 2000, ms, 100)
+
+	// no budget outside the middleware or without a deadline
+	h := make(http.Header)
+	InjectBudget(t.Context(), h)
+	ctx := context.WithValue(t.Context(), budgetKey{}, HeaderRequestTimeout)
+	InjectBudget(ctx, h)
+	assert.Empty(t, h)
+}
+
+func TestFormatBudget(t *testing.T) {
+	assert.Equal(t, "1500", formatBudget(HeaderRequestTimeout, 1500*time.Millisecond))
+	assert.Equal(t, "1", formatBudget(HeaderRequestTimeout, time.Microsecond))
+	assert.Equal(t, "1500m", formatBudget(HeaderGRPCTimeout, 1500*time.Millisecond))
+	assert.Equal(t, "100000S", formatBudget(HeaderGRPCTimeout, 100000*time.Second))
+}
+
+func TestBudget_Panics(t *testing.T) {
+	assert.PanicsWithValue(t, "middleware: budget: max budget must be positive", func() {
+		Budget("", 0)
+	})
+}
@@ -0,0 +1,238 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"maps"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/gowool/keratin"
+)
+
+const maxBufferSize int64 = 1 << 20
+
+var (
+	_ http.Flusher      = (*bufferWriter)(nil)
+	_ http.Hijacker     = (*bufferWriter)(nil)
+	_ keratin.Committer = (*bufferWriter)(nil)
+	_ BodyBuffer        = (*bufferWriter)(nil)
+)
+
+// BodyBuffer is implemented by response writers which keep the response body in memory.
+type BodyBuffer interface {
+	BufferedBody() []byte
+}
+
+// BufferedBody returns the response body captured so far by the [Buffer] middleware.
+// It returns false if w is not (or does not wrap) a buffering writer.
+func BufferedBody(w http.ResponseWriter) ([]byte, bool) {
+	for {
+		switch t := w.(type) {
+		case BodyBuffer:
+			return t.BufferedBody(), true
+		case keratin.RWUnwrapper:
+			w = t.Unwrap()
+		default:
+			return nil, false
+		}
+	}
+}
+
+type BufferConfig struct {
+	// MaxSize is the maximum number of bytes kept in memory.
+	// When the response grows beyond it, the buffered data is sent to the client
+	// and the rest of the response is streamed as is.
+	// Optional. Default value 1MB.
+	MaxSize int64 `env:"MAX_SIZE" json:"maxSize,omitempty" yaml:"maxSize,omitempty"`
+}
+
+func (c *BufferConfig) SetDefaults() {
+	if c.MaxSize <= 0 {
+		c.MaxSize = maxBufferSize
+	}
+}
+
+// Buffer returns a middleware that captures the response (status, headers and body) in memory
+// and sends it only after the handler has returned without an error.
+//
+// If the handler returns an error, the captured response is discarded so the error handler
+// can still produce a clean error response even after partial writes.
+// Calling Flush or exceeding BufferConfig.MaxSize sends the captured data and disables
+// buffering for the rest of the response. The routes marked with [keratin.Route.NoBuffer] are not buffered.
+func Buffer(cfg BufferConfig, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	cfg.SetDefaults()
+
+	skip := ChainSkipper(append(skippers[:len(skippers):len(skippers)], RouteFlagSkipper(keratin.MetaBuffer))...)
+
+	pool := &sync.Pool{
+		New: func() any { return new(bufferWriter) },
+	}
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if skip(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			bw := pool.Get().(*bufferWriter)
+			bw.reset(w, cfg.MaxSize)
+
+			defer func() {
+				bw.reset(nil, 0)
+				pool.Put(bw)
+			}()
+
+			if err := next.ServeHTTP(bw, r); err != nil {
+				return err
+			}
+
+			return bw.spill()
+		})
+	}
+}
+
+type bufferWriter struct {
+	http.ResponseWriter
+	header  http.Header
+	buf     bytes.Buffer
+	maxSize int64
+	code    int
+	spilled bool
+}
+
+func (b *bufferWriter) reset(w http.ResponseWriter, maxSize int64) {
+	b.ResponseWriter = w
+	b.header = nil
+	b.buf.Reset()
+	b.maxSize = maxSize
+	b.code = 0
+	b.spilled = false
+
+	if w != nil {
+		b.header = w.Header().Clone()
+		if b.header == nil {
+			b.header = make(http.Header)
+		}
+	}
+}
+
+func (b *bufferWriter) Header() http.Header {
+	if b.spilled {
+		return b.ResponseWriter.Header()
+	}
+	return b.header
+}
+
+func (b *bufferWriter) WriteHeader(statusCode int) {
+	if b.code != 0 {
+		return
+	}
+
+	// informational responses (e.g. 103 Early Hints) are sent right away with the captured headers
+	if statusCode >= 100 && statusCode < 200 && statusCode != http.StatusSwitchingProtocols {
+		if !b.spilled {
+			header := b.ResponseWriter.Header()
+			clear(header)
+			maps.Copy(header, b.header.Clone())
+		}
+		b.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+
+	b.code = statusCode
+
+	if b.spilled {
+		b.ResponseWriter.WriteHeader(statusCode)
+	}
+}
+
+func (b *bufferWriter) Write(data []byte) (int, error) {
+	if b.code == 0 {
+		b.WriteHeader(http.StatusOK)
+	}
+
+	if b.spilled {
+		return b.ResponseWriter.Write(data)
+	}
+
+	n, _ := b.buf.Write(data)
+
+	if int64(b.buf.Len()) > b.maxSize {
+		return n, b.spill()
+	}
+
+	return n, nil
+}
+
+// StatusCode returns the status code written so far.
+func (b *bufferWriter) StatusCode() int {
+	return b.code
+}
+
+// Committed reports whether the status code has been written.
+func (b *bufferWriter) Committed() bool {
+	return b.code != 0
+}
+
+// BufferedBody returns the captured response body. It returns nil once the buffer has been spilled.
+func (b *bufferWriter) BufferedBody() []byte {
+	if b.spilled {
+		return nil
+	}
+	return b.buf.Bytes()
+}
+
+// Flush spills the buffer and flushes the underlying writer,
+// the router response writer applies the flush policy, see keratin.WithFlushPolicy.
+func (b *bufferWriter) Flush() {
+	if err := b.spill(); err != nil {
+		return
+	}
+	keratin.ResponseFlush(b.ResponseWriter)
+}
+
+// FlushError spills the buffer and flushes the underlying writer,
+// it returns keratin.ErrFlushNotSupported if the underlying writer cannot flush.
+func (b *bufferWriter) FlushError() error {
+	if err := b.spill(); err != nil {
+		return err
+	}
+	return keratin.ResponseFlushError(b.ResponseWriter)
+}
+
+func (b *bufferWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(b.ResponseWriter).Hijack()
+}
+
+func (b *bufferWriter) Unwrap() http.ResponseWriter {
+	return b.ResponseWriter
+}
+
+// spill sends the captured headers, status code and body to the underlying writer
+// and switches to pass-through mode.
+func (b *bufferWriter) spill() error {
+	if b.spilled {
+		return nil
+	}
+	b.spilled = true
+
+	header := b.ResponseWriter.Header()
+	clear(header)
+	maps.Copy(header, b.header)
+
+	if b.code == 0 {
+		return nil
+	}
+
+	b.ResponseWriter.WriteHeader(b.code)
+
+	if b.buf.Len() == 0 {
+		return nil
+	}
+
+	_, err := b.ResponseWriter.Write(b.buf.Bytes())
+	b.buf.Reset()
+	return err
+}
@@ -0,0 +1,295 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+func TestBufferConfig_SetDefaults(t *testing.T) {
+	cfg := BufferConfig{}
+	cfg.SetDefaults()
+	assert.Equal(t, maxBufferSize, cfg.MaxSize)
+
+	cfg = BufferConfig{MaxSize: 10}
+	cfg.SetDefaults()
+	assert.Equal(t, int64(10), cfg.MaxSize)
+}
+
+func TestBuffer(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxSize    int64
+		handler    func(w http.ResponseWriter, r *http.Request) error
+		wantCode   int
+		wantBody   string
+		wantHeader string
+	}{
+		{
+			name: "sends buffered response on success",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("X-Test", "ok")
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte("hello "))
+				_, _ = w.Write([]byte("world"))
+				return nil
+			},
+			wantCode:   http.StatusCreated,
+			wantBody:   "hello world",
+			wantHeader: "ok",
+		},
+		{
+			name: "discards partial response on error",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("X-Test", "partial")
+				_, _ = w.Write([]byte("partial"))
+				return keratin.ErrTeapot
+			},
+			wantCode: http.StatusTeapot,
+			wantBody: "I'm a teapot\n",
+		},
+		{
+			name:    "spills when max size is exceeded",
+			maxSize: 4,
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("X-Test", "spilled")
+				_, _ = w.Write([]byte("hello world"))
+				return keratin.ErrTeapot
+			},
+			wantCode:   http.StatusOK,
+			wantBody:   "hello world",
+			wantHeader: "spilled",
+		},
+		{
+			name: "empty response",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("X-Test", "empty")
+				return nil
+			},
+			wantCode:   http.StatusOK,
+			wantHeader: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := keratin.NewRouter()
+			router.UseFunc(Buffer(BufferConfig{MaxSize: tt.maxSize}))
+			router.GET("/", tt.handler)
+
+			res := httptest.NewRecorder()
+			router.Build().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, tt.wantCode, res.Code)
+			assert.Equal(t, tt.wantBody, res.Body.String())
+			assert.Equal(t, tt.wantHeader, res.Header().Get("X-Test"))
+		})
+	}
+}
+
+func TestBuffer_PreservesOuterHeaders(t *testing.T) {
+	router := keratin.NewRouter()
+	router.UseFunc(
+		func(next keratin.Handler) keratin.Handler {
+			return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("X-Outer", "1")
+				return next.ServeHTTP(w, r)
+			})
+		},
+		Buffer(BufferConfig{}),
+	)
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		return keratin.TextPlain(w, http.StatusOK, "ok")
+	})
+
+	res := httptest.NewRecorder()
+	router.Build().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, "1", res.Header().Get("X-Outer"))
+	assert.Equal(t, keratin.MIMETextPlainCharsetUTF8, res.Header().Get(keratin.HeaderContentType))
+	assert.Equal(t, "ok", res.Body.String())
+}
+
+func TestBuffer_Flush(t *testing.T) {
+	router := keratin.NewRouter()
+	router.UseFunc(Buffer(BufferConfig{}))
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		_, _ = w.Write([]byte("chunk"))
+		w.(http.Flusher).Flush()
+		assert.True(t, keratin.ResponseCommitted(w))
+		return errors.New("late error")
+	})
+
+	res := httptest.NewRecorder()
+	router.Build().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.True(t, res.Flushed)
+	assert.Equal(t, "chunk", res.Body.String())
+}
+
+func TestBuffer_InformationalAndTrailers(t *testing.T) {
+	router := keratin.NewRouter()
+	router.UseFunc(Buffer(BufferConfig{}))
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		keratin.EarlyHints(w, keratin.PreloadLink("/app.css", "style"))
+		assert.False(t, keratin.ResponseCommitted(w))
+
+		_, _ = w.Write([]byte("ok"))
+		keratin.SetTrailer(w, "X-Checksum", "abc")
+		return nil
+	})
+
+	var informational []int
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, _ textproto.MIMEHeader) error {
+			informational = append(informational, code)
+			return nil
+		},
+	}
+
+	srv := httptest.NewServer(router.Build())
+	defer srv.Close()
+
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	res, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	defer func() { _ = res.Body.Close() }()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{http.StatusEarlyHints}, informational)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, "abc", res.Trailer.Get("X-Checksum"))
+}
+
+func TestBufferedBody(t *testing.T) {
+	inner := func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if err := next.ServeHTTP(w, r); err != nil {
+				return err
+			}
+
+			body, ok := BufferedBody(w)
+			require.True(t, ok)
+			w.Header().Set("X-Length", strings.Repeat("x", len(body)))
+			return nil
+		})
+	}
+
+	router := keratin.NewRouter()
+	router.UseFunc(Buffer(BufferConfig{}), inner)
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		_, err := w.Write([]byte("abc"))
+		return err
+	})
+
+	res := httptest.NewRecorder()
+	router.Build().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, "xxx", res.Header().Get("X-Length"))
+	assert.Equal(t, "abc", res.Body.String())
+
+	_, ok := BufferedBody(httptest.NewRecorder())
+	assert.False(t, ok)
+}
+
+func TestBuffer_NoBufferRoute(t *testing.T) {
+	var buffered []bool
+	handler := func(w http.ResponseWriter, _ *http.Request) error {
+		_, ok := BufferedBody(w)
+		buffered = append(buffered, ok)
+		return nil
+	}
+
+	router := keratin.NewRouter()
+	router.UseFunc(Buffer(BufferConfig{}))
+	router.GET("/page", handler)
+	router.GET("/events", handler).NoBuffer()
+	h := router.Build()
+
+	for _, target := range []string{"/page", "/events"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	assert.Equal(t, []bool{true, false}, buffered)
+}
+
+// nonFlushingWriter hides the http.Flusher of the recorder.
+type nonFlushingWriter struct {
+	http.ResponseWriter
+}
+
+func TestBuffer_FlushPolicy(t *testing.T) {
+	router := keratin.NewRouter(keratin.WithFlushPolicy(keratin.FlushIgnore))
+	router.UseFunc(Buffer(BufferConfig{}))
+	router.GET("/events", func(w http.ResponseWriter, _ *http.Request) error {
+		_, _ = w.Write([]byte("data"))
+		w.(http.Flusher).Flush()
+		return nil
+	})
+	h := router.Build()
+
+	rec := httptest.NewRecorder()
+	assert.NotPanics(t, func() {
+		h.ServeHTTP(nonFlushingWriter{rec}, httptest.NewRequest(http.MethodGet, "/events", nil))
+	})
+	assert.Equal(t, "data", rec.Body.String())
+}
+
+func TestBuffer_FlushOutsideRouter(t *testing.T) {
+	h := Buffer(BufferConfig{})(keratin.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		_, _ = w.Write([]byte("data"))
+		assert.NotPanics(t, w.(http.Flusher).Flush)
+		return http.NewResponseController(w).Flush()
+	}))
+
+	rec := httptest.NewRecorder()
+	err := h.ServeHTTP(nonFlushingWriter{rec}, httptest.NewRequest(http.MethodGet, "/events", nil))
+	assert.ErrorIs(t, err, keratin.ErrFlushNotSupported)
+	assert.Equal(t, "data", rec.Body.String())
+}
+
+func TestResponseWriters_FlushError(t *testing.T) {
+	tests := []struct {
+		name string
+		wrap func(w http.ResponseWriter) http.ResponseWriter
+	}{
+		{name: "body capture", wrap: func(w http.ResponseWriter) http.ResponseWriter { return &bodyCapture{ResponseWriter: w} }},
+		{name: "idempotency", wrap: func(w http.ResponseWriter) http.ResponseWriter { return &idempotencyWriter{ResponseWriter: w} }},
+		{name: "sanitize headers", wrap: func(w http.ResponseWriter) http.ResponseWriter {
+			return &sanitizeHeadersWriter{ResponseWriter: w, written: true}
+		}},
+		{name: "spa", wrap: func(w http.ResponseWriter) http.ResponseWriter { return &notFoundWriter{ResponseWriter: w} }},
+		{name: "cache control", wrap: func(w http.ResponseWriter) http.ResponseWriter {
+			return &cacheControlWriter{ResponseWriter: w, applied: true}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			require.NoError(t, http.NewResponseController(tt.wrap(rec)).Flush())
+			assert.True(t, rec.Flushed)
+
+			w := tt.wrap(nonFlushingWriter{httptest.NewRecorder()})
+			assert.ErrorIs(t, http.NewResponseController(w).Flush(), keratin.ErrFlushNotSupported)
+			assert.NotPanics(t, w.(http.Flusher).Flush)
+		})
+	}
+}
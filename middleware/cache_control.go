@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"bufio"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gowool/keratin"
+)
+
+// CacheRule selects the caching directives of the responses, see [CacheControl].
+type CacheRule struct {
+	// Pattern is the route pattern, with the method (e.g. "GET /users/{id}") or without it.
+	// Optional. Empty matches any route.
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+
+	// ContentType is the media type of the response, e.g. "application/json",
+	// or a media type range, e.g. "image/*".
+	// Optional. Empty matches any content type.
+	ContentType string `json:"contentType,omitempty" yaml:"contentType,omitempty"`
+
+	// Options are the directives of the matched responses.
+	Options keratin.CacheOptions `json:"options" yaml:"options"`
+}
+
+// CacheControl returns a middleware setting the Cache-Control, Surrogate-Control and Expires headers
+// (see [keratin.SetCacheControl]) of the first rule matching the route and the response content type.
+//
+// The directives are applied when the response status is written, only to the successful and redirect
+// responses which have no Cache-Control header yet, so the handlers can still override them.
+// The routes marked with [keratin.Route.NoCache] are left untouched.
+//
+// It panics if no rule is given.
+func CacheControl(rules ...CacheRule) func(keratin.Handler) keratin.Handler {
+	if len(rules) == 0 {
+		panic("middleware: cache control: at least one rule is required")
+	}
+
+	noCache := RouteFlagSkipper(keratin.MetaCache)
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if noCache(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			var matched []CacheRule
+			for _, rule := range rules {
+				if rule.matchRoute(r) {
+					matched = append(matched, rule)
+				}
+			}
+			if len(matched) == 0 {
+				return next.ServeHTTP(w, r)
+			}
+
+			return next.ServeHTTP(&cacheControlWriter{ResponseWriter: w, rules: matched}, r)
+		})
+	}
+}
+
+func (rule CacheRule) matchRoute(r *http.Request) bool {
+	if rule.Pattern == "" {
+		return true
+	}
+
+	c := keratin.FromContext(r.Context())
+
+	route := c.Route()
+	if route == nil {
+		return false
+	}
+	return rule.Pattern == c.Pattern() || (route.Method != "" && rule.Pattern == route.Method+" "+c.Pattern())
+}
+
+func (rule CacheRule) matchContentType(contentType string) bool {
+	if rule.ContentType == "" {
+		return true
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if prefix, ok := strings.CutSuffix(rule.ContentType, "*"); ok {
+		return strings.HasPrefix(mediaType, prefix)
+	}
+	return strings.EqualFold(mediaType, rule.ContentType)
+}
+
+// cacheControlWriter sets the caching headers of the first matching rule right before the status is written.
+type cacheControlWriter struct {
+	http.ResponseWriter
+	rules   []CacheRule
+	applied bool
+}
+
+func (w *cacheControlWriter) WriteHeader(statusCode int) {
+	if statusCode >= 200 && !w.applied {
+		w.applied = true
+		w.apply(statusCode)
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *cacheControlWriter) Write(b []byte) (int, error) {
+	if !w.applied {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *cacheControlWriter) apply(statusCode int) {
+	if statusCode >= http.StatusBadRequest || keratin.ResponseCommitted(w.ResponseWriter) {
+		return
+	}
+
+	header := w.Header()
+	if header.Get(keratin.HeaderCacheControl) != "" {
+		return
+	}
+
+	contentType := header.Get(keratin.HeaderContentType)
+	for _, rule := range w.rules {
+		if rule.matchContentType(contentType) {
+			keratin.SetCacheControl(w.ResponseWriter, rule.Options)
+			return
+		}
+	}
+}
+
+func (w *cacheControlWriter) Flush() {
+	if !w.applied {
+		w.WriteHeader(http.StatusOK)
+	}
+	keratin.ResponseFlush(w.ResponseWriter)
+}
+
+func (w *cacheControlWriter) FlushError() error {
+	if !w.applied {
+		w.WriteHeader(http.StatusOK)
+	}
+	return keratin.ResponseFlushError(w.ResponseWriter)
+}
+
+func (w *cacheControlWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *cacheControlWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
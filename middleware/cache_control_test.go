@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gowool/keratin"
+)
+
+func TestCacheControl(t *testing.T) {
+	router := keratin.NewRouter()
+	router.UseFunc(CacheControl(
+		CacheRule{Pattern: "GET /users/{id}", Options: keratin.CacheOptions{Private: true, MaxAge: time.Minute}},
+		CacheRule{ContentType: "image/*", Options: keratin.CacheOptions{Public: true, MaxAge: time.Hour, SurrogateMaxAge: 24 * time.Hour}},
+		CacheRule{Pattern: "/news", ContentType: keratin.MIMEApplicationJSON, Options: keratin.CacheOptions{Public: true, SharedMaxAge: 10 * time.Second}},
+	))
+
+	router.GET("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		if r.PathValue("id") == "0" {
+			return keratin.JSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		}
+		return keratin.JSON(w, http.StatusOK, map[string]string{"id": r.PathValue("id")})
+	})
+	router.GET("/images/{name}", func(w http.ResponseWriter, r *http.Request) error {
+		if r.PathValue("name") == "own.png" {
+			w.Header().Set(keratin.HeaderCacheControl, "no-store")
+		}
+		return keratin.Blob(w, http.StatusOK, "image/png", []byte("png"))
+	})
+	router.GET("/avatars/{name}", func(w http.ResponseWriter, _ *http.Request) error {
+		return keratin.Blob(w, http.StatusOK, "image/png", []byte("png"))
+	}).NoCache()
+	router.GET("/news", func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Query().Has("html") {
+			return keratin.HTML(w, http.StatusOK, "<p>news</p>")
+		}
+		return keratin.JSON(w, http.StatusOK, []string{"news"})
+	})
+	router.GET("/empty", func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+
+	handler := router.Build()
+
+	tests := []struct {
+		name          string
+		target        string
+		wantCache     string
+		wantSurrogate string
+	}{
+		{name: "route", target: "/users/1", wantCache: "private, max-age=60"},
+		{name: "error response", target: "/users/0"},
+		{name: "content type", target: "/images/logo.png", wantCache: "public, max-age=3600", wantSurrogate: "max-age=86400"},
+		{name: "handler directives", target: "/images/own.png", wantCache: "no-store"},
+		{name: "no cache route", target: "/avatars/me.png"},
+		{name: "route and content type", target: "/news", wantCache: "public, s-maxage=10"},
+		{name: "route and other content type", target: "/news?html"},
+		{name: "no rule", target: "/empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			assert.Equal(t, tt.wantCache, rec.Header().Get(keratin.HeaderCacheControl))
+			assert.Equal(t, tt.wantSurrogate, rec.Header().Get(keratin.HeaderSurrogateControl))
+		})
+	}
+}
+
+func TestCacheControl_Panics(t *testing.T) {
+	assert.PanicsWithValue(t, "middleware: cache control: at least one rule is required", func() {
+		CacheControl()
+	})
+}
@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gowool/keratin"
+)
+
+const (
+	HeaderChallengeToken = "X-Challenge-Token"
+
+	TurnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+	HCaptchaVerifyURL  = "https://api.hcaptcha.com/siteverify"
+)
+
+// ErrChallengeFailed is returned by the verifiers for rejected challenge tokens.
+var ErrChallengeFailed = errors.New("challenge failed")
+
+// ChallengeVerifier verifies the challenge response token of a request, e.g. a captcha or a proof-of-work solution.
+type ChallengeVerifier interface {
+	Verify(r *http.Request, token string) error
+}
+
+// ChallengeVerifierFunc is an adapter to allow the use of ordinary functions as [ChallengeVerifier].
+type ChallengeVerifierFunc func(r *http.Request, token string) error
+
+func (f ChallengeVerifierFunc) Verify(r *http.Request, token string) error {
+	return f(r, token)
+}
+
+// ChallengeInfo describes the challenge in the body of the rejected responses,
+// so the clients know which widget to render.
+type ChallengeInfo struct {
+	Provider string `json:"provider,omitempty"`
+	SiteKey  string `json:"siteKey,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+type ChallengeConfig struct {
+	// Verifier verifies the challenge tokens.
+	// Required.
+	Verifier ChallengeVerifier `json:"-" yaml:"-"`
+
+	// Decider reports whether the request must pass the challenge,
+	// e.g. after a rate limit breach or from a suspicious IP.
+	// Optional. Default value challenges every request.
+	Decider func(r *http.Request) bool `json:"-" yaml:"-"`
+
+	// TokenLookup is a string in the form of "<source>:<name>" or "<source>:<name>,<source>:<name>" that is used
+	// to extract the challenge token from the request, see [CreateExtractors].
+	// Optional. Default value "header:X-Challenge-Token".
+	TokenLookup string `env:"TOKEN_LOOKUP" json:"tokenLookup,omitempty" yaml:"tokenLookup,omitempty"`
+
+	// Provider is the challenge provider name returned to the clients, e.g. "turnstile" or "hcaptcha".
+	// Optional.
+	Provider string `env:"PROVIDER" json:"provider,omitempty" yaml:"provider,omitempty"`
+
+	// SiteKey is the public site key of the provider returned to the clients.
+	// Optional.
+	SiteKey string `env:"SITE_KEY" json:"siteKey,omitempty" yaml:"siteKey,omitempty"`
+
+	// ErrorHandler defines a function which is executed for returning custom errors.
+	ErrorHandler func(r *http.Request, err error) error `json:"-" yaml:"-"`
+}
+
+func (c *ChallengeConfig) SetDefaults() {
+	if c.Decider == nil {
+		c.Decider = func(*http.Request) bool { return true }
+	}
+	if c.TokenLookup == "" {
+		c.TokenLookup = "header:" + HeaderChallengeToken
+	}
+}
+
+// Challenge returns a middleware which requires the requests selected by the ChallengeConfig.Decider
+// to carry a challenge token accepted by the ChallengeConfig.Verifier.
+//
+// Requests without a token fail with 429 Too Many Requests and rejected tokens with 403 Forbidden,
+// the error data is the [ChallengeInfo] to solve. Verifier errors carrying a status code, e.g. 503
+// when the provider is unavailable, are returned as is.
+func Challenge(cfg ChallengeConfig, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	cfg.SetDefaults()
+
+	if cfg.Verifier == nil {
+		panic("middleware: challenge: verifier is required")
+	}
+
+	extractors, err := CreateExtractors(cfg.TokenLookup, 1)
+	if err != nil {
+		panic(fmt.Sprintf("middleware: challenge: %v", err))
+	}
+
+	skip := ChainSkipper(skippers...)
+
+	reject := func(r *http.Request, code int, reason string) error {
+		err := keratin.NewHTTPError(code, reason).SetData(ChallengeInfo{
+			Provider: cfg.Provider,
+			SiteKey:  cfg.SiteKey,
+			Reason:   reason,
+		})
+		if cfg.ErrorHandler != nil {
+			return cfg.ErrorHandler(r, err)
+		}
+		return err
+	}
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if skip(r) || !cfg.Decider(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			var token string
+			for _, extractor := range extractors {
+				if values, _, err := extractor(r); err == nil && len(values) > 0 && values[0] != "" {
+					token = values[0]
+					break
+				}
+			}
+			if token == "" {
+				return reject(r, http.StatusTooManyRequests, "challenge required")
+			}
+
+			if err := cfg.Verifier.Verify(r, token); err != nil {
+				if keratin.ErrorStatusCode(err) >= http.StatusBadRequest {
+					if cfg.ErrorHandler != nil {
+						return cfg.ErrorHandler(r, err)
+					}
+					return err
+				}
+				return reject(r, http.StatusForbidden, "challenge failed")
+			}
+
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SiteVerifier verifies the tokens with a "siteverify" API, like Cloudflare Turnstile and hCaptcha provide.
+type SiteVerifier struct {
+	// URL is the siteverify endpoint.
+	URL string
+	// Secret is the secret key of the site.
+	Secret string
+	// Client sends the verification requests, http.DefaultClient if nil.
+	Client *http.Client
+}
+
+// NewTurnstileVerifier returns a [SiteVerifier] for Cloudflare Turnstile.
+func NewTurnstileVerifier(secret string) *SiteVerifier {
+	return &SiteVerifier{URL: TurnstileVerifyURL, Secret: secret}
+}
+
+// NewHCaptchaVerifier returns a [SiteVerifier] for hCaptcha.
+func NewHCaptchaVerifier(secret string) *SiteVerifier {
+	return &SiteVerifier{URL: HCaptchaVerifyURL, Secret: secret}
+}
+
+// Verify posts the token and the client IP to the siteverify endpoint.
+// It returns [ErrChallengeFailed] for rejected tokens and 503 Service Unavailable when the endpoint fails.
+func (v *SiteVerifier) Verify(r *http.Request, token string) error {
+	form := url.Values{"secret": {v.Secret}, "response": {token}}
+	if ip := keratin.ClientIP(r); ip != "" {
+		form.Set("remoteip", ip)
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, v.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return keratin.ErrServiceUnavailable.Wrap(err)
+	}
+	req.Header.Set(keratin.HeaderContentType, keratin.MIMEApplicationForm)
+
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return keratin.ErrServiceUnavailable.Wrap(err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != http.StatusOK {
+		return keratin.ErrServiceUnavailable.Wrap(fmt.Errorf("siteverify: unexpected status %d", res.StatusCode))
+	}
+
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err = json.NewDecoder(io.LimitReader(res.Body, 64<<10)).Decode(&result); err != nil {
+		return keratin.ErrServiceUnavailable.Wrap(fmt.Errorf("siteverify: %w", err))
+	}
+
+	if !result.Success {
+		return fmt.Errorf("%w: %s", ErrChallengeFailed, strings.Join(result.ErrorCodes, ", "))
+	}
+	return nil
+}
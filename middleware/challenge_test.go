@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+func TestChallenge(t *testing.T) {
+	verifier := ChallengeVerifierFunc(func(_ *http.Request, token string) error {
+		switch token {
+		case "valid":
+			return nil
+		case "unavailable":
+			return keratin.ErrServiceUnavailable.Wrap(errors.New("provider down"))
+		}
+		return ErrChallengeFailed
+	})
+
+	mw := Challenge(ChallengeConfig{
+		Verifier:    verifier,
+		Decider:     func(r *http.Request) bool { return r.Header.Get("X-Suspicious") != "" },
+		TokenLookup: "header:" + HeaderChallengeToken + ",form:cf-turnstile-response",
+		Provider:    "turnstile",
+		SiteKey:     "site-key",
+	})
+
+	h := mw(keratin.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}))
+
+	tests := []struct {
+		name       string
+		suspicious bool
+		header     string
+		form       string
+		wantCode   int
+		wantReason string
+	}{
+		{name: "not challenged", wantCode: http.StatusNoContent},
+		{name: "missing token", suspicious: true, wantCode: http.StatusTooManyRequests, wantReason: "challenge required"},
+		{name: "valid header token", suspicious: true, header: "valid", wantCode: http.StatusNoContent},
+		{name: "valid form token", suspicious: true, form: "valid", wantCode: http.StatusNoContent},
+		{name: "invalid token", suspicious: true, header: "invalid", wantCode: http.StatusForbidden, wantReason: "challenge failed"},
+		{name: "verifier unavailable", suspicious: true, header: "unavailable", wantCode: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(url.Values{"cf-turnstile-response": {tt.form}}.Encode()))
+			r.Header.Set(keratin.HeaderContentType, keratin.MIMEApplicationForm)
+			if tt.suspicious {
+				r.Header.Set("X-Suspicious", "1")
+			}
+			if tt.header != "" {
+				r.Header.Set(HeaderChallengeToken, tt.header)
+			}
+
+			rec := httptest.NewRecorder()
+			err := h.ServeHTTP(rec, r)
+
+			if tt.wantCode == http.StatusNoContent {
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusNoContent, rec.Code)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, tt.wantCode, keratin.HTTPErrorStatusCode(err))
+
+			if tt.wantReason != "" {
+				var httpErr *keratin.HTTPError
+				require.ErrorAs(t, err, &httpErr)
+				assert.Equal(t, ChallengeInfo{Provider: "turnstile", SiteKey: "site-key", Reason: tt.wantReason}, httpErr.Data)
+			}
+		})
+	}
+}
+
+func TestChallenge_ErrorHandler(t *testing.T) {
+	custom := errors.New("custom")
+
+	h := Challenge(ChallengeConfig{
+		Verifier:     ChallengeVerifierFunc(func(*http.Request, string) error { return nil }),
+		ErrorHandler: func(*http.Request, error) error { return custom },
+	})(keratin.HandlerFunc(func(http.ResponseWriter, *http.Request) error { return nil }))
+
+	err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.ErrorIs(t, err, custom)
+}
+
+func TestChallenge_Panics(t *testing.T) {
+	assert.Panics(t, func() { Challenge(ChallengeConfig{}) })
+	assert.Panics(t, func() {
+		Challenge(ChallengeConfig{
+			Verifier:    ChallengeVerifierFunc(func(*http.Request, string) error { return nil }),
+			TokenLookup: "invalid",
+		})
+	})
+}
+
+func TestSiteVerifier(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "secret", r.PostForm.Get("secret"))
+		assert.Equal(t, "192.0.2.1", r.PostForm.Get("remoteip"))
+
+		switch r.PostForm.Get("response") {
+		case "valid":
+			_, _ = w.Write([]byte(`{"success":true}`))
+		case "invalid":
+			_, _ = w.Write([]byte(`{"success":false,"error-codes":["invalid-input-response"]}`))
+		case "garbage":
+			_, _ = w.Write([]byte(`<html>`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	v := &SiteVerifier{URL: srv.URL, Secret: "secret", Client: srv.Client()}
+
+	verify := func(token string) error {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		return v.Verify(r, token)
+	}
+
+	assert.NoError(t, verify("valid"))
+
+	err := verify("invalid")
+	assert.ErrorIs(t, err, ErrChallengeFailed)
+	assert.ErrorContains(t, err, "invalid-input-response")
+
+	assert.Equal(t, http.StatusServiceUnavailable, keratin.HTTPErrorStatusCode(verify("garbage")))
+	assert.Equal(t, http.StatusServiceUnavailable, keratin.HTTPErrorStatusCode(verify("error")))
+}
+
+func TestNewSiteVerifiers(t *testing.T) {
+	assert.Equal(t, &SiteVerifier{URL: TurnstileVerifyURL, Secret: "a"}, NewTurnstileVerifier("a"))
+	assert.Equal(t, &SiteVerifier{URL: HCaptchaVerifyURL, Secret: "b"}, NewHCaptchaVerifier("b"))
+}
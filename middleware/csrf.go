@@ -28,10 +28,14 @@ package middleware
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -85,7 +89,9 @@ type CSRFConfig struct {
 	Generator func() string `json:"-" yaml:"-"`
 
 	// Name of the CSRF cookie. This cookie will store CSRF token.
-	// Optional. Default value "csrf".
+	// The "__Host-" and "__Secure-" prefixes require CookieSecure,
+	// the "__Host-" prefix also requires no CookieDomain and CookiePath "/" (or none).
+	// Optional. Default value "_csrf".
 	CookieName string `env:"COOKIE_NAME" json:"cookieName,omitempty" yaml:"cookieName,omitempty"`
 
 	// Domain of the CSRF cookie.
@@ -93,7 +99,7 @@ type CSRFConfig struct {
 	CookieDomain string `env:"COOKIE_DOMAIN" json:"cookieDomain,omitempty" yaml:"cookieDomain,omitempty"`
 
 	// Path of the CSRF cookie.
-	// Optional. Default value none.
+	// Optional. Default value "/".
 	CookiePath string `env:"COOKIE_PATH" json:"cookiePath,omitempty" yaml:"cookiePath,omitempty"`
 
 	// Max age (in seconds) of the CSRF cookie.
@@ -108,10 +114,39 @@ type CSRFConfig struct {
 	// Optional. Default value false.
 	CookieHTTPOnly bool `env:"COOKIE_HTTP_ONLY" json:"cookieHTTPOnly,omitempty" yaml:"cookieHTTPOnly,omitempty"`
 
+	// Indicates if CSRF cookie is partitioned (CHIPS), it requires CookieSecure.
+	// Optional. Default value false.
+	CookiePartitioned bool `env:"COOKIE_PARTITIONED" json:"cookiePartitioned,omitempty" yaml:"cookiePartitioned,omitempty"`
+
 	// Indicates SameSite mode of the CSRF cookie.
 	// Optional. Default value SameSiteDefaultMode.
 	CookieSameSite http.SameSite `env:"COOKIE_SAME_SITE" json:"cookieSameSite,omitempty" yaml:"cookieSameSite,omitempty"`
 
+	// MaskToken enables per-request masking of the token exposed via CtxCSRF.
+	// The cookie keeps the raw token, while every response gets a different
+	// one-time pad XOR-ed value so that the secret is never reflected verbatim
+	// in (possibly compressed) response bodies, mitigating BREACH-style attacks.
+	// Both masked and raw client tokens are accepted during validation.
+	// Optional. Default value false.
+	MaskToken bool `env:"MASK_TOKEN" json:"maskToken,omitempty" yaml:"maskToken,omitempty"`
+
+	// TokenMaxAge limits the lifetime of a token, an expired token is replaced with a new one
+	// and requests carrying it are rejected, so a leaked token is not valid forever.
+	// The issue time is appended to the token, e.g. "<token>.<issued at>.<session binding>".
+	// Optional. Default value 0 (tokens never expire).
+	TokenMaxAge time.Duration `env:"TOKEN_MAX_AGE" json:"tokenMaxAge,omitempty,format:units" yaml:"tokenMaxAge,omitempty"`
+
+	// RotateToken replaces the token with a new one after every successfully validated
+	// unsafe (e.g. POST) request, the handler gets the new token via CtxCSRF.
+	// Optional. Default value false.
+	RotateToken bool `env:"ROTATE_TOKEN" json:"rotateToken,omitempty" yaml:"rotateToken,omitempty"`
+
+	// SessionID returns the current session identifier, e.g. the session token.
+	// When set, the token is bound to the session and becomes invalid once the session changes,
+	// e.g. after login or logout.
+	// Optional. Default value nil.
+	SessionID func(r *http.Request) string `json:"-" yaml:"-"`
+
 	// ErrorHandler defines a function which is executed for returning custom errors.
 	ErrorHandler func(r *http.Request, err error) error `json:"-" yaml:"-"`
 }
@@ -137,6 +172,20 @@ func (c *CSRFConfig) SetDefaults() {
 	}
 }
 
+func (c *CSRFConfig) cookie(token string) keratin.CookieOpts {
+	return keratin.CookieOpts{
+		Name:        c.CookieName,
+		Value:       token,
+		Path:        c.CookiePath,
+		Domain:      c.CookieDomain,
+		Expires:     time.Now().Add(time.Duration(c.CookieMaxAge) * time.Second),
+		Secure:      c.CookieSecure,
+		Scriptable:  !c.CookieHTTPOnly,
+		Partitioned: c.CookiePartitioned,
+		SameSite:    c.CookieSameSite,
+	}
+}
+
 func CSRF(cfg CSRFConfig, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
 	cfg.SetDefaults()
 
@@ -146,6 +195,10 @@ func CSRF(cfg CSRFConfig, skippers ...Skipper) func(keratin.Handler) keratin.Han
 		}
 	}
 
+	if err := cfg.cookie("").Validate(); err != nil {
+		panic(fmt.Errorf("middleware: csrf: %w", err))
+	}
+
 	extractors, err := CreateExtractors(cfg.TokenLookup, 1)
 	if err != nil {
 		panic(fmt.Errorf("middleware: csrf: %w", err))
@@ -171,8 +224,8 @@ func CSRF(cfg CSRFConfig, skippers ...Skipper) func(keratin.Handler) keratin.Han
 			// Fallback to legacy token based CSRF protection
 
 			token := ""
-			if k, err := r.Cookie(cfg.CookieName); err != nil {
-				token = cfg.Generator() // Generate token
+			if k, err := r.Cookie(cfg.CookieName); err != nil || !cfg.validToken(r, k.Value) {
+				token = cfg.newToken(r) // Generate token
 			} else {
 				token = k.Value // Reuse token
 			}
@@ -192,7 +245,8 @@ func CSRF(cfg CSRFConfig, skippers ...Skipper) func(keratin.Handler) keratin.Han
 					}
 
 					for _, clientToken := range clientTokens {
-						if validateCSRFToken(token, clientToken) {
+						if validateCSRFToken(token, clientToken) ||
+							(cfg.MaskToken && validateCSRFToken(token, unmaskCSRFToken(clientToken))) {
 							lastTokenErr = nil
 							lastExtractorErr = nil
 							break outer
@@ -212,28 +266,21 @@ func CSRF(cfg CSRFConfig, skippers ...Skipper) func(keratin.Handler) keratin.Han
 					}
 					return finalErr
 				}
+
+				if cfg.RotateToken {
+					token = cfg.newToken(r)
+				}
 			}
 
 			// Set CSRF cookie
-			cookie := new(http.Cookie)
-			cookie.Name = cfg.CookieName
-			cookie.Value = token
-			if cfg.CookiePath != "" {
-				cookie.Path = cfg.CookiePath
-			}
-			if cfg.CookieDomain != "" {
-				cookie.Domain = cfg.CookieDomain
-			}
-			if cfg.CookieSameSite != http.SameSiteDefaultMode {
-				cookie.SameSite = cfg.CookieSameSite
-			}
-			cookie.Expires = time.Now().Add(time.Duration(cfg.CookieMaxAge) * time.Second)
-			cookie.Secure = cfg.CookieSecure
-			cookie.HttpOnly = cfg.CookieHTTPOnly
-			http.SetCookie(w, cookie)
+			keratin.SetCookie(w, cfg.cookie(token))
 
 			// Store token in the context
-			ctx := context.WithValue(r.Context(), csrfKey{}, token)
+			ctxToken := token
+			if cfg.MaskToken {
+				ctxToken = maskCSRFToken(token)
+			}
+			ctx := context.WithValue(r.Context(), csrfKey{}, ctxToken)
 			r = r.WithContext(ctx)
 
 			// Protect clients from caching the response
@@ -244,10 +291,95 @@ func CSRF(cfg CSRFConfig, skippers ...Skipper) func(keratin.Handler) keratin.Han
 	}
 }
 
+// newToken generates a token, with the issue time and the session binding
+// if the token expiry or the session binding is enabled.
+func (c *CSRFConfig) newToken(r *http.Request) string {
+	token := c.Generator()
+	if c.TokenMaxAge <= 0 && c.SessionID == nil {
+		return token
+	}
+
+	return token + "." + strconv.FormatInt(time.Now().Unix(), 36) + "." + c.sessionBinding(r)
+}
+
+// validToken reports whether the token is neither expired nor bound to another session.
+func (c *CSRFConfig) validToken(r *http.Request, token string) bool {
+	if c.TokenMaxAge <= 0 && c.SessionID == nil {
+		return true
+	}
+
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return false
+	}
+	binding := token[i+1:]
+
+	j := strings.LastIndexByte(token[:i], '.')
+	if j < 0 {
+		return false
+	}
+	issuedAt, err := strconv.ParseInt(token[j+1:i], 36, 64)
+	if err != nil {
+		return false
+	}
+
+	if c.TokenMaxAge > 0 && time.Since(time.Unix(issuedAt, 0)) > c.TokenMaxAge {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(binding), []byte(c.sessionBinding(r))) == 1
+}
+
+// sessionBinding returns a short hash of the current session identifier, empty without session.
+func (c *CSRFConfig) sessionBinding(r *http.Request) string {
+	if c.SessionID == nil {
+		return ""
+	}
+
+	id := c.SessionID(r)
+	if id == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
 func validateCSRFToken(token, clientToken string) bool {
 	return subtle.ConstantTimeCompare([]byte(token), []byte(clientToken)) == 1
 }
 
+// maskCSRFToken returns base64(otp || otp XOR token) using a fresh one-time pad,
+// so the same token produces a different value on every call.
+func maskCSRFToken(token string) string {
+	size := len(token)
+
+	b := make([]byte, 2*size)
+	_, _ = rand.Read(b[:size])
+
+	for i := range size {
+		b[size+i] = b[i] ^ token[i]
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// unmaskCSRFToken reverses maskCSRFToken. An empty string is returned for
+// values that are not masked tokens.
+func unmaskCSRFToken(clientToken string) string {
+	b, err := base64.RawURLEncoding.DecodeString(clientToken)
+	if err != nil || len(b) == 0 || len(b)%2 != 0 {
+		return ""
+	}
+
+	size := len(b) / 2
+	for i := range size {
+		b[size+i] ^= b[i]
+	}
+
+	return string(b[size:])
+}
+
 var safeMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace}
 
 func (c *CSRFConfig) checkSecFetchSiteRequest(r *http.Request) (bool, error) {
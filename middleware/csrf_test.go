@@ -2,13 +2,17 @@ package middleware
 
 import (
 	"cmp"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/gowool/keratin"
 )
@@ -385,6 +389,31 @@ func TestCSRF(t *testing.T) {
 
 }
 
+func TestCSRF_FormTokenKeepsBody(t *testing.T) {
+	token := randomString(16)
+	body := "_csrf=" + token + "&name=keratin"
+
+	h := CSRF(CSRFConfig{TokenLookup: "form:_csrf"})(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		raw, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, body, string(raw))
+		assert.Equal(t, "keratin", r.PostFormValue("name"))
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(keratin.HeaderContentType, keratin.MIMEApplicationForm)
+	req.AddCookie(&http.Cookie{Name: "_csrf", Value: token})
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.ServeHTTP(rec, req))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
 func TestCSRFSetSameSiteMode(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	rec := httptest.NewRecorder()
@@ -404,6 +433,35 @@ func TestCSRFSetSameSiteMode(t *testing.T) {
 	assert.Regexp(t, "SameSite=Strict", rec.Header()["Set-Cookie"])
 }
 
+func TestCSRFCookiePath(t *testing.T) {
+	tests := []struct {
+		name       string
+		cookiePath string
+		want       string
+	}{
+		{name: "default", want: "/"},
+		{name: "custom", cookiePath: "/admin", want: "/admin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+
+			h := CSRF(CSRFConfig{CookiePath: tt.cookiePath})(keratin.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+				w.WriteHeader(http.StatusOK)
+				return nil
+			}))
+
+			require.NoError(t, h.ServeHTTP(rec, req))
+
+			cookies := rec.Result().Cookies()
+			require.Len(t, cookies, 1)
+			assert.Equal(t, tt.want, cookies[0].Path)
+		})
+	}
+}
+
 func TestCSRFWithoutSameSiteMode(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	rec := httptest.NewRecorder()
@@ -461,6 +519,37 @@ func TestCSRFWithSameSiteModeNone(t *testing.T) {
 	assert.Regexp(t, "Secure", rec.Header()["Set-Cookie"])
 }
 
+func TestCSRFWithPartitionedHostCookie(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	csrf := CSRF(CSRFConfig{
+		CookieName:        "__Host-csrf",
+		CookieSecure:      true,
+		CookiePartitioned: true,
+	})
+
+	h := csrf(keratin.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}))
+
+	assert.NoError(t, h.ServeHTTP(rec, req))
+	assert.Regexp(t, "^__Host-csrf=.*; Path=/;.*Secure; Partitioned", rec.Header().Get("Set-Cookie"))
+}
+
+func TestCSRFInvalidCookieConfig(t *testing.T) {
+	assert.PanicsWithError(t, `middleware: csrf: keratin: cookie "__Host-csrf": __Host- prefix requires Secure`, func() {
+		CSRF(CSRFConfig{CookieName: "__Host-csrf"})
+	})
+	assert.Panics(t, func() {
+		CSRF(CSRFConfig{CookieName: "__Host-csrf", CookieSecure: true, CookiePath: "/app"})
+	})
+	assert.Panics(t, func() {
+		CSRF(CSRFConfig{CookiePartitioned: true})
+	})
+}
+
 func TestCSRFConfig_skipper(t *testing.T) {
 	var testCases = []struct {
 		name          string
@@ -872,3 +961,161 @@ func TestCSRFConfig_checkSecFetchSiteRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestCSRF_MaskToken(t *testing.T) {
+	const rawToken = "abcdefghijklmnopqrstuvwxyzABCDEF"
+
+	cfg := CSRFConfig{
+		MaskToken: true,
+		Generator: func() string { return rawToken },
+	}
+
+	var tokens []string
+	h := CSRF(cfg)(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		tokens = append(tokens, CtxCSRF(r.Context()))
+		return nil
+	}))
+
+	for range 2 {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+		assert.NoError(t, h.ServeHTTP(res, req))
+		assert.Contains(t, res.Header().Get(keratin.HeaderSetCookie), "_csrf="+rawToken)
+	}
+
+	assert.Len(t, tokens, 2)
+	assert.NotEqual(t, tokens[0], tokens[1])
+	assert.NotEqual(t, rawToken, tokens[0])
+	assert.Equal(t, rawToken, unmaskCSRFToken(tokens[0]))
+	assert.Equal(t, rawToken, unmaskCSRFToken(tokens[1]))
+
+	tests := []struct {
+		name        string
+		clientToken string
+		wantErr     error
+	}{
+		{name: "masked token", clientToken: tokens[0]},
+		{name: "another masked token", clientToken: tokens[1]},
+		{name: "raw token", clientToken: rawToken},
+		{name: "invalid token", clientToken: maskCSRFToken("invalid"), wantErr: ErrCSRFInvalid},
+		{name: "garbage token", clientToken: "!!!", wantErr: ErrCSRFInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header.Set(keratin.HeaderXCSRFToken, tt.clientToken)
+			req.AddCookie(&http.Cookie{Name: "_csrf", Value: rawToken})
+			res := httptest.NewRecorder()
+
+			err := h.ServeHTTP(res, req)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestCSRF_TokenMaxAge(t *testing.T) {
+	h := CSRF(CSRFConfig{TokenMaxAge: time.Hour})(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}))
+
+	fresh := "abc." + strconv.FormatInt(time.Now().Unix(), 36) + "."
+	expired := "abc." + strconv.FormatInt(time.Now().Add(-2*time.Hour).Unix(), 36) + "."
+
+	tests := []struct {
+		name        string
+		cookie      string
+		wantErr     error
+		wantRenewed bool
+	}{
+		{name: "fresh token", cookie: fresh},
+		{name: "expired token", cookie: expired, wantErr: ErrCSRFInvalid, wantRenewed: true},
+		{name: "token without issue time", cookie: "abc", wantErr: ErrCSRFInvalid, wantRenewed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header.Set(keratin.HeaderXCSRFToken, tt.cookie)
+			req.AddCookie(&http.Cookie{Name: "_csrf", Value: tt.cookie})
+			res := httptest.NewRecorder()
+
+			err := h.ServeHTTP(res, req)
+			assert.Equal(t, tt.wantErr, err)
+
+			if tt.wantErr == nil {
+				assert.Contains(t, res.Header().Get(keratin.HeaderSetCookie), "_csrf="+tt.cookie+";")
+			}
+		})
+	}
+
+	// a safe request with an expired token gets a new one
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "_csrf", Value: expired})
+	res := httptest.NewRecorder()
+	require.NoError(t, h.ServeHTTP(res, req))
+
+	cookie := res.Result().Cookies()[0]
+	assert.NotEqual(t, expired, cookie.Value)
+	assert.Regexp(t, `^[a-zA-Z]{32}\.[0-9a-z]+\.$`, cookie.Value)
+}
+
+func TestCSRF_RotateToken(t *testing.T) {
+	var ctxToken string
+	h := CSRF(CSRFConfig{RotateToken: true})(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		ctxToken = CtxCSRF(r.Context())
+		return nil
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "_csrf", Value: "token"})
+	res := httptest.NewRecorder()
+	require.NoError(t, h.ServeHTTP(res, req))
+	assert.Equal(t, "token", ctxToken, "safe requests keep the token")
+
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(keratin.HeaderXCSRFToken, "token")
+	req.AddCookie(&http.Cookie{Name: "_csrf", Value: "token"})
+	res = httptest.NewRecorder()
+	require.NoError(t, h.ServeHTTP(res, req))
+
+	assert.NotEqual(t, "token", ctxToken)
+	assert.Equal(t, ctxToken, res.Result().Cookies()[0].Value)
+}
+
+func TestCSRF_SessionBinding(t *testing.T) {
+	cfg := CSRFConfig{
+		SessionID: func(r *http.Request) string { return r.Header.Get("X-Session") },
+	}
+	var token string
+	h := CSRF(cfg)(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		token = CtxCSRF(r.Context())
+		return nil
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Session", "session-1")
+	require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), req))
+
+	tests := []struct {
+		name    string
+		session string
+		wantErr error
+	}{
+		{name: "same session", session: "session-1"},
+		{name: "another session", session: "session-2", wantErr: ErrCSRFInvalid},
+		{name: "no session", wantErr: ErrCSRFInvalid},
+	}
+
+	issued := token
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header.Set("X-Session", tt.session)
+			req.Header.Set(keratin.HeaderXCSRFToken, issued)
+			req.AddCookie(&http.Cookie{Name: "_csrf", Value: issued})
+
+			assert.Equal(t, tt.wantErr, h.ServeHTTP(httptest.NewRecorder(), req))
+		})
+	}
+}
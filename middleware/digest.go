@@ -0,0 +1,242 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/md5" //nolint:gosec // Content-MD5 is a legacy integrity check, not a security one
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gowool/keratin"
+)
+
+const (
+	HeaderDigest     = "Digest"
+	HeaderContentMD5 = "Content-MD5"
+
+	DigestSHA256 = "sha-256"
+	DigestSHA512 = "sha-512"
+	DigestMD5    = "md5"
+
+	maxDigestBodyLen = 1 << 20
+)
+
+var (
+	// ErrDigestMissing is returned when DigestConfig.Required is set and the request carries no supported digest.
+	ErrDigestMissing = keratin.NewHTTPError(http.StatusBadRequest, "missing request body digest")
+	// ErrDigestMismatch is returned when the request body does not match its digest.
+	ErrDigestMismatch = keratin.NewHTTPError(http.StatusBadRequest, "request body digest mismatch")
+)
+
+type DigestConfig struct {
+	// Algorithms are the accepted digest algorithms of the requests: "sha-256", "sha-512" and "md5".
+	// Optional. Default value ["sha-256", "sha-512"].
+	Algorithms []string `env:"ALGORITHMS" json:"algorithms,omitempty" yaml:"algorithms,omitempty"`
+
+	// Required rejects the requests without a digest of an accepted algorithm.
+	// Optional. Default value false (the requests without a digest are not verified).
+	Required bool `env:"REQUIRED" json:"required,omitempty" yaml:"required,omitempty"`
+
+	// MaxBodySize is the maximum size of the request body read to compute its digest,
+	// and of the response body buffered to compute the response digest.
+	// Optional. Default value 1MB.
+	MaxBodySize int64 `env:"MAX_BODY_SIZE" json:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty"`
+
+	// ResponseAlgorithm adds the Content-Digest header with the digest of this algorithm to the responses,
+	// "sha-256" or "sha-512". The responses larger than MaxBodySize or flushed are sent without it.
+	// Optional. Default value "" (no response digest).
+	ResponseAlgorithm string `env:"RESPONSE_ALGORITHM" json:"responseAlgorithm,omitempty" yaml:"responseAlgorithm,omitempty"`
+}
+
+func (c *DigestConfig) SetDefaults() {
+	if len(c.Algorithms) == 0 {
+		c.Algorithms = []string{DigestSHA256, DigestSHA512}
+	}
+	if c.MaxBodySize <= 0 {
+		c.MaxBodySize = maxDigestBodyLen
+	}
+}
+
+// Digest returns a middleware which verifies the integrity of the request body against its digests:
+// the RFC 9530 Content-Digest header (`sha-256=:base64:`), the legacy RFC 3230 Digest header
+// (`SHA-256=base64`) and the Content-MD5 header. All the digests of an accepted algorithm must match,
+// the ones of other algorithms are ignored. The body is buffered with [keratin.BufferBody],
+// so the next handlers read it as usual.
+//
+// With DigestConfig.ResponseAlgorithm the response is buffered and sent with its Content-Digest header,
+// except for the routes marked with [keratin.Route.NoBuffer].
+//
+// It panics if an algorithm is not supported.
+func Digest(cfg DigestConfig, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	cfg.SetDefaults()
+
+	for _, alg := range cfg.Algorithms {
+		if digestHash(alg) == nil {
+			panic("middleware: digest: unsupported algorithm " + alg)
+		}
+	}
+	if cfg.ResponseAlgorithm != "" && cfg.ResponseAlgorithm != DigestSHA256 && cfg.ResponseAlgorithm != DigestSHA512 {
+		panic("middleware: digest: unsupported response algorithm " + cfg.ResponseAlgorithm)
+	}
+
+	skip := ChainSkipper(skippers...)
+	noBuffer := RouteFlagSkipper(keratin.MetaBuffer)
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if skip(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			if err := cfg.verify(r); err != nil {
+				return err
+			}
+
+			if cfg.ResponseAlgorithm == "" || r.Method == http.MethodHead || noBuffer(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			bw := new(bufferWriter)
+			bw.reset(w, cfg.MaxBodySize)
+
+			if err := next.ServeHTTP(bw, r); err != nil {
+				return err
+			}
+
+			if !bw.spilled && bw.code != http.StatusNoContent && bw.code != http.StatusNotModified {
+				h := digestHash(cfg.ResponseAlgorithm)()
+				h.Write(bw.buf.Bytes())
+				bw.header.Set(HeaderContentDigest, cfg.ResponseAlgorithm+"=:"+base64.StdEncoding.EncodeToString(h.Sum(nil))+":")
+			}
+			return bw.spill()
+		})
+	}
+}
+
+func (c *DigestConfig) verify(r *http.Request) error {
+	digests, err := c.requestDigests(r.Header)
+	if err != nil {
+		return ErrDigestMismatch.Wrap(err)
+	}
+	if len(digests) == 0 {
+		if c.Required {
+			return ErrDigestMissing
+		}
+		return nil
+	}
+
+	body, err := keratin.BufferBody(r, c.MaxBodySize)
+	if err != nil {
+		return err
+	}
+
+	if err = verifyDigests(digests, body); err != nil {
+		return ErrDigestMismatch.Wrap(err)
+	}
+	return nil
+}
+
+// requestDigests returns the digests of the accepted algorithms sent with the request.
+func (c *DigestConfig) requestDigests(header http.Header) (map[string][]byte, error) {
+	digests, err := parseContentDigest(header.Values(HeaderContentDigest), c.Algorithms)
+	if err != nil {
+		return nil, err
+	}
+
+	add := func(alg string, digest []byte) error {
+		return addDigest(digests, alg, digest)
+	}
+
+	for _, member := range splitStructuredList(strings.Join(header.Values(HeaderDigest), ",")) {
+		alg, value, ok := strings.Cut(member, "=")
+		if !ok {
+			return nil, errors.New("malformed Digest header")
+		}
+		alg = strings.ToLower(strings.TrimSpace(alg))
+		if !slices.Contains(c.Algorithms, alg) {
+			continue
+		}
+		digest, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		if err = add(alg, digest); err != nil {
+			return nil, err
+		}
+	}
+
+	if value := header.Get(HeaderContentMD5); value != "" && slices.Contains(c.Algorithms, DigestMD5) {
+		digest, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		if err = add(DigestMD5, digest); err != nil {
+			return nil, err
+		}
+	}
+
+	return digests, nil
+}
+
+// parseContentDigest parses the RFC 9530 Content-Digest header values into the digests
+// of the accepted algorithms, the ones of other algorithms and the member parameters are ignored.
+func parseContentDigest(values []string, algorithms []string) (map[string][]byte, error) {
+	digests := make(map[string][]byte)
+
+	for _, member := range splitStructuredList(strings.Join(values, ",")) {
+		alg, value, ok := strings.Cut(member, "=")
+		if !ok {
+			return nil, errors.New("malformed Content-Digest header")
+		}
+		alg = strings.ToLower(strings.TrimSpace(alg))
+		if !slices.Contains(algorithms, alg) {
+			continue
+		}
+		value, _, _ = strings.Cut(value, ";")
+		digest, err := parseByteSequence(value)
+		if err != nil {
+			return nil, err
+		}
+		if err = addDigest(digests, alg, digest); err != nil {
+			return nil, err
+		}
+	}
+	return digests, nil
+}
+
+func addDigest(digests map[string][]byte, alg string, digest []byte) error {
+	if prev, ok := digests[alg]; ok && !hmac.Equal(prev, digest) {
+		return errors.New("conflicting digests of " + alg)
+	}
+	digests[alg] = digest
+	return nil
+}
+
+// verifyDigests checks that the body matches all the digests.
+func verifyDigests(digests map[string][]byte, body []byte) error {
+	for alg, expected := range digests {
+		h := digestHash(alg)()
+		h.Write(body)
+		if !hmac.Equal(expected, h.Sum(nil)) {
+			return errors.New("digest mismatch of " + alg)
+		}
+	}
+	return nil
+}
+
+func digestHash(alg string) func() hash.Hash {
+	switch alg {
+	case DigestSHA256:
+		return sha256.New
+	case DigestSHA512:
+		return sha512.New
+	case DigestMD5:
+		return md5.New
+	}
+	return nil
+}
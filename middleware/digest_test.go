@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"crypto/md5" //nolint:gosec // Content-MD5 test vectors
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+func digestTestHandler(cfg DigestConfig) keratin.Handler {
+	return Digest(cfg)(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		return keratin.TextPlain(w, http.StatusOK, string(body))
+	}))
+}
+
+func TestDigest_Request(t *testing.T) {
+	const body = `{"event":"paid"}`
+
+	sha256Sum := sha256.Sum256([]byte(body))
+	sha512Sum := sha512.Sum512([]byte(body))
+	md5Sum := md5.Sum([]byte(body)) //nolint:gosec
+	sha256B64 := base64.StdEncoding.EncodeToString(sha256Sum[:])
+	sha512B64 := base64.StdEncoding.EncodeToString(sha512Sum[:])
+	md5B64 := base64.StdEncoding.EncodeToString(md5Sum[:])
+	wrongB64 := base64.StdEncoding.EncodeToString(make([]byte, 32))
+
+	tests := []struct {
+		name     string
+		cfg      DigestConfig
+		headers  map[string]string
+		wantCode int
+	}{
+		{name: "no digest", wantCode: http.StatusOK},
+		{name: "no digest required", cfg: DigestConfig{Required: true}, wantCode: http.StatusBadRequest},
+		{name: "content digest", headers: map[string]string{HeaderContentDigest: "sha-256=:" + sha256B64 + ":"}, wantCode: http.StatusOK},
+		{name: "content digest multiple", headers: map[string]string{HeaderContentDigest: "sha-512=:" + sha512B64 + ":, sha-256=:" + sha256B64 + ":"}, wantCode: http.StatusOK},
+		{name: "content digest mismatch", headers: map[string]string{HeaderContentDigest: "sha-256=:" + wrongB64 + ":"}, wantCode: http.StatusBadRequest},
+		{name: "content digest malformed", headers: map[string]string{HeaderContentDigest: "sha-256=" + sha256B64}, wantCode: http.StatusBadRequest},
+		{name: "content digest one mismatch", headers: map[string]string{HeaderContentDigest: "sha-512=:" + sha512B64 + ":, sha-256=:" + wrongB64 + ":"}, wantCode: http.StatusBadRequest},
+		{name: "unsupported algorithm ignored", headers: map[string]string{HeaderContentDigest: "sha=:" + wrongB64 + ":"}, wantCode: http.StatusOK},
+		{name: "unsupported algorithm required", cfg: DigestConfig{Required: true}, headers: map[string]string{HeaderContentDigest: "sha=:" + wrongB64 + ":"}, wantCode: http.StatusBadRequest},
+		{name: "legacy digest", headers: map[string]string{HeaderDigest: "SHA-256=" + sha256B64}, wantCode: http.StatusOK},
+		{name: "legacy digest mismatch", headers: map[string]string{HeaderDigest: "SHA-256=" + wrongB64}, wantCode: http.StatusBadRequest},
+		{name: "conflicting digests", headers: map[string]string{HeaderDigest: "SHA-256=" + sha256B64, HeaderContentDigest: "sha-256=:" + wrongB64 + ":"}, wantCode: http.StatusBadRequest},
+		{name: "content md5", cfg: DigestConfig{Algorithms: []string{DigestMD5}}, headers: map[string]string{HeaderContentMD5: md5B64}, wantCode: http.StatusOK},
+		{name: "content md5 mismatch", cfg: DigestConfig{Algorithms: []string{DigestMD5}}, headers: map[string]string{HeaderContentMD5: sha256B64}, wantCode: http.StatusBadRequest},
+		{name: "content md5 not accepted", headers: map[string]string{HeaderContentMD5: sha256B64}, wantCode: http.StatusOK},
+		{name: "too large", cfg: DigestConfig{MaxBodySize: 4}, headers: map[string]string{HeaderContentDigest: "sha-256=:" + sha256B64 + ":"}, wantCode: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+
+			err := digestTestHandler(tt.cfg).ServeHTTP(rec, req)
+
+			if tt.wantCode == http.StatusOK {
+				require.NoError(t, err)
+				assert.Equal(t, body, rec.Body.String())
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.wantCode, keratin.ErrorStatusCode(err))
+		})
+	}
+}
+
+func TestDigest_Response(t *testing.T) {
+	h := digestTestHandler(DigestConfig{ResponseAlgorithm: DigestSHA256, MaxBodySize: 8})
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("hello"))))
+
+	sum := sha256.Sum256([]byte("hello"))
+	assert.Equal(t, "sha-256=:"+base64.StdEncoding.EncodeToString(sum[:])+":", rec.Header().Get(HeaderContentDigest))
+	assert.Equal(t, "hello", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	require.NoError(t, h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("hello world"))))
+	assert.Empty(t, rec.Header().Get(HeaderContentDigest))
+	assert.Equal(t, "hello world", rec.Body.String())
+
+	noContent := Digest(DigestConfig{ResponseAlgorithm: DigestSHA256})(keratin.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}))
+	rec = httptest.NewRecorder()
+	require.NoError(t, noContent.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/", nil)))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Header().Get(HeaderContentDigest))
+}
+
+func TestDigest_Panics(t *testing.T) {
+	assert.PanicsWithValue(t, "middleware: digest: unsupported algorithm sha-1", func() {
+		Digest(DigestConfig{Algorithms: []string{"sha-1"}})
+	})
+	assert.PanicsWithValue(t, "middleware: digest: unsupported response algorithm md5", func() {
+		Digest(DigestConfig{ResponseAlgorithm: DigestMD5})
+	})
+}
+
+func TestParseContentDigest(t *testing.T) {
+	sum := sha256.Sum256([]byte("body"))
+	value := "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+
+	digests, err := parseContentDigest([]string{"unixsum=30637, " + value + ";param=1"}, []string{DigestSHA256, DigestSHA512})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{DigestSHA256: sum[:]}, digests)
+	require.NoError(t, verifyDigests(digests, []byte("body")))
+	require.Error(t, verifyDigests(digests, []byte("evil")))
+
+	_, err = parseContentDigest([]string{value, "sha-256=:AAAA:"}, []string{DigestSHA256})
+	require.ErrorContains(t, err, "conflicting digests of sha-256")
+
+	_, err = parseContentDigest([]string{"sha-256"}, []string{DigestSHA256})
+	require.ErrorContains(t, err, "malformed Content-Digest header")
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gowool/keratin"
+)
+
+type EarlyHintsConfig struct {
+	// Links is a list of Link header values sent with the 103 Early Hints response,
+	// e.g. `</static/app.css>; rel=preload; as=style`.
+	// See [keratin.PreloadLink].
+	Links []string `env:"LINKS" json:"links,omitempty" yaml:"links,omitempty"`
+
+	// Push additionally initiates HTTP/2 server pushes for the given resources
+	// when supported by the client connection.
+	// Optional. Default value none.
+	Push []string `env:"PUSH" json:"push,omitempty" yaml:"push,omitempty"`
+}
+
+// EarlyHints returns a middleware that sends a 103 Early Hints response with the configured
+// preload links (and optional HTTP/2 pushes) before calling the next handler.
+// Only GET and HEAD requests are hinted.
+func EarlyHints(cfg EarlyHintsConfig, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	skip := ChainSkipper(skippers...)
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if skip(r) || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+				return next.ServeHTTP(w, r)
+			}
+
+			if len(cfg.Push) > 0 {
+				// pushing is best effort, clients are free to refuse it
+				_ = keratin.Push(w, cfg.Push...)
+			}
+
+			keratin.EarlyHints(w, cfg.Links...)
+
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gowool/keratin"
+)
+
+type earlyHintsRecorder struct {
+	*httptest.ResponseRecorder
+	informational []int
+	pushed        []string
+}
+
+func (m *earlyHintsRecorder) WriteHeader(code int) {
+	if code >= 100 && code < 200 {
+		m.informational = append(m.informational, code)
+		return
+	}
+	m.ResponseRecorder.WriteHeader(code)
+}
+
+func (m *earlyHintsRecorder) Push(target string, _ *http.PushOptions) error {
+	m.pushed = append(m.pushed, target)
+	return nil
+}
+
+func TestEarlyHints(t *testing.T) {
+	cfg := EarlyHintsConfig{
+		Links: []string{keratin.PreloadLink("/app.css", "style")},
+		Push:  []string{"/app.js"},
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		skippers  []Skipper
+		wantHints bool
+	}{
+		{name: "GET request is hinted", method: http.MethodGet, wantHints: true},
+		{name: "HEAD request is hinted", method: http.MethodHead, wantHints: true},
+		{name: "POST request is not hinted", method: http.MethodPost},
+		{name: "skipped request is not hinted", method: http.MethodGet, skippers: []Skipper{func(*http.Request) bool { return true }}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := keratin.NewRouter()
+			router.UseFunc(EarlyHints(cfg, tt.skippers...))
+			router.Any("/", func(w http.ResponseWriter, r *http.Request) error {
+				return keratin.HTML(w, http.StatusOK, "<html></html>")
+			})
+
+			rec := &earlyHintsRecorder{ResponseRecorder: httptest.NewRecorder()}
+			router.Build().ServeHTTP(rec, httptest.NewRequest(tt.method, "/", nil))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			if tt.wantHints {
+				assert.Equal(t, []int{http.StatusEarlyHints}, rec.informational)
+				assert.Equal(t, []string{"/app.js"}, rec.pushed)
+				assert.Equal(t, "</app.css>; rel=preload; as=style", rec.Header().Get(keratin.HeaderLink))
+			} else {
+				assert.Empty(t, rec.informational)
+				assert.Empty(t, rec.pushed)
+				assert.Empty(t, rec.Header().Get(keratin.HeaderLink))
+			}
+		})
+	}
+}
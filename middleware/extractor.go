@@ -27,10 +27,13 @@ package middleware
 // -------------------------------------------------------------------
 
 import (
+	"encoding/json"
 	"fmt"
+	"mime"
 	"net/http"
 	"net/textproto"
 	"strings"
+	"sync"
 
 	"github.com/gowool/keratin"
 )
@@ -39,6 +42,9 @@ const (
 	// extractorLimit is arbitrary number to limit values extractor can return. this limits possible resource exhaustion
 	// attack vector
 	extractorLimit = 20
+
+	// maxFormBodySize is the size limit of the url-encoded bodies parsed by [http.Request.ParseForm]
+	maxFormBodySize = 10 << 20
 )
 
 // ExtractorSource is type to indicate source for extracted value
@@ -55,6 +61,8 @@ const (
 	ExtractorSourceCookie ExtractorSource = "cookie"
 	// ExtractorSourceForm means value was extracted from request form values
 	ExtractorSourceForm ExtractorSource = "form"
+	// ExtractorSourceJSON means value was extracted from the JSON request body
+	ExtractorSourceJSON ExtractorSource = "json"
 )
 
 // ValueExtractorError is error type when middleware extractor is unable to extract value from lookups
@@ -73,10 +81,61 @@ var errQueryExtractorValueMissing = &ValueExtractorError{message: "missing value
 var errParamExtractorValueMissing = &ValueExtractorError{message: "missing value in path params"}
 var errCookieExtractorValueMissing = &ValueExtractorError{message: "missing value in cookies"}
 var errFormExtractorValueMissing = &ValueExtractorError{message: "missing value in the form"}
+var errJSONExtractorValueMissing = &ValueExtractorError{message: "missing value in the JSON body"}
 
 // ValuesExtractor defines a function for extracting values (keys/tokens) from the given context.
 type ValuesExtractor func(r *http.Request) ([]string, ExtractorSource, error)
 
+// ExtractorFactory creates the [ValuesExtractor] of a lookup source. name is the part of the lookup
+// after "<source>:", e.g. "Authorization:Bearer " for "header:Authorization:Bearer ", and limit
+// is the maximum number of the extracted values.
+type ExtractorFactory func(name string, limit uint) (ValuesExtractor, error)
+
+var (
+	extractorSourcesMu sync.RWMutex
+	extractorSources   = map[ExtractorSource]ExtractorFactory{
+		ExtractorSourceHeader: func(name string, limit uint) (ValuesExtractor, error) {
+			header, prefix, _ := strings.Cut(name, ":")
+			return valuesFromHeader(header, prefix, limit), nil
+		},
+		ExtractorSourceQuery: func(name string, limit uint) (ValuesExtractor, error) {
+			return valuesFromQuery(name, limit), nil
+		},
+		ExtractorSourcePathParam: func(name string, _ uint) (ValuesExtractor, error) {
+			return valuesFromParam(name), nil
+		},
+		ExtractorSourceCookie: func(name string, limit uint) (ValuesExtractor, error) {
+			return valuesFromCookie(name, limit), nil
+		},
+		ExtractorSourceForm: func(name string, limit uint) (ValuesExtractor, error) {
+			return valuesFromForm(name, limit), nil
+		},
+		ExtractorSourceJSON: func(name string, limit uint) (ValuesExtractor, error) {
+			return valuesFromJSON(name, limit), nil
+		},
+	}
+)
+
+// RegisterExtractorSource registers a custom lookup source for [CreateExtractors], so that
+// the middlewares configured with lookups (CSRF, KeyAuthenticator, Challenge...) can use it.
+// It panics if the source is empty, contains ':' or ',' or is already registered.
+func RegisterExtractorSource(source ExtractorSource, factory ExtractorFactory) {
+	if source == "" || strings.ContainsAny(string(source), ":,") {
+		panic(fmt.Sprintf("middleware: invalid extractor source %q", source))
+	}
+	if factory == nil {
+		panic(fmt.Sprintf("middleware: extractor source %q: factory is required", source))
+	}
+
+	extractorSourcesMu.Lock()
+	defer extractorSourcesMu.Unlock()
+
+	if _, ok := extractorSources[source]; ok {
+		panic(fmt.Sprintf("middleware: extractor source %q is already registered", source))
+	}
+	extractorSources[source] = factory
+}
+
 // CreateExtractors creates ValuesExtractors from given lookups.
 // lookups is a string in the form of "<source>:<name>" or "<source>:<name>,<source>:<name>" that is used
 // to extract key from the request.
@@ -90,6 +149,8 @@ type ValuesExtractor func(r *http.Request) ([]string, ExtractorSource, error)
 //   - "param:<name>"
 //   - "form:<name>"
 //   - "cookie:<name>"
+//   - "json:<field>" where field may be a dot separated path, e.g. "json:auth.token"
+//   - "<source>:<name>" of the sources registered with [RegisterExtractorSource]
 //
 // Multiple sources example:
 // - "header:Authorization,header:X-Api-Key"
@@ -105,30 +166,27 @@ func CreateExtractors(lookups string, limit uint) ([]ValuesExtractor, error) {
 		limit = extractorLimit
 	}
 
+	extractorSourcesMu.RLock()
+	defer extractorSourcesMu.RUnlock()
+
 	sources := strings.Split(lookups, ",")
 	var extractors = make([]ValuesExtractor, 0)
 	for _, source := range sources {
-		parts := strings.Split(source, ":")
-		if len(parts) < 2 {
+		kind, name, ok := strings.Cut(source, ":")
+		if !ok {
 			return nil, fmt.Errorf("extractor source for lookup could not be split into needed parts: %v", source)
 		}
 
-		switch parts[0] {
-		case "query":
-			extractors = append(extractors, valuesFromQuery(parts[1], limit))
-		case "param":
-			extractors = append(extractors, valuesFromParam(parts[1]))
-		case "cookie":
-			extractors = append(extractors, valuesFromCookie(parts[1], limit))
-		case "form":
-			extractors = append(extractors, valuesFromForm(parts[1], limit))
-		case "header":
-			prefix := ""
-			if len(parts) > 2 {
-				prefix = parts[2]
-			}
-			extractors = append(extractors, valuesFromHeader(parts[1], prefix, limit))
+		factory, ok := extractorSources[ExtractorSource(kind)]
+		if !ok {
+			return nil, fmt.Errorf("unknown extractor source %q in lookup: %v", kind, source)
+		}
+
+		extractor, err := factory(name, limit)
+		if err != nil {
+			return nil, fmt.Errorf("extractor source for lookup %v: %w", source, err)
 		}
+		extractors = append(extractors, extractor)
 	}
 	return extractors, nil
 }
@@ -243,7 +301,13 @@ func valuesFromForm(name string, limit uint) ValuesExtractor {
 	}
 	return func(r *http.Request) ([]string, ExtractorSource, error) {
 		if r.Form == nil {
+			// keep the url-encoded body readable by the next handlers, the multipart
+			// forms stay available in r.MultipartForm
+			if ct, _, _ := mime.ParseMediaType(r.Header.Get(keratin.HeaderContentType)); ct == keratin.MIMEApplicationForm {
+				_, _ = keratin.BufferBody(r, maxFormBodySize)
+			}
 			_ = r.ParseMultipartForm(keratin.MultipartMaxMemory)
+			keratin.RewindBody(r)
 		}
 		values := r.Form[name]
 		if len(values) == 0 {
@@ -256,3 +320,54 @@ func valuesFromForm(name string, limit uint) ValuesExtractor {
 		return result, ExtractorSourceForm, nil
 	}
 }
+
+// valuesFromJSON returns a function that extracts the string (or array of strings) field of the JSON request body.
+// The body is kept readable by the next handlers.
+func valuesFromJSON(field string, limit uint) ValuesExtractor {
+	if limit == 0 {
+		limit = 1
+	}
+	path := strings.Split(field, ".")
+
+	return func(r *http.Request) ([]string, ExtractorSource, error) {
+		ct, _, _ := mime.ParseMediaType(r.Header.Get(keratin.HeaderContentType))
+		if ct != keratin.MIMEApplicationJSON && !strings.HasSuffix(ct, "+json") {
+			return nil, ExtractorSourceJSON, errJSONExtractorValueMissing
+		}
+
+		body, err := keratin.BufferBody(r, maxFormBodySize)
+		if err != nil || len(body) == 0 {
+			return nil, ExtractorSourceJSON, errJSONExtractorValueMissing
+		}
+
+		var value any
+		if err = json.Unmarshal(body, &value); err != nil {
+			return nil, ExtractorSourceJSON, errJSONExtractorValueMissing
+		}
+
+		for _, key := range path {
+			object, ok := value.(map[string]any)
+			if !ok {
+				return nil, ExtractorSourceJSON, errJSONExtractorValueMissing
+			}
+			value = object[key]
+		}
+
+		var result []string
+		switch v := value.(type) {
+		case string:
+			result = []string{v}
+		case []any:
+			for _, item := range v {
+				if s, ok := item.(string); ok && uint(len(result)) < limit {
+					result = append(result, s)
+				}
+			}
+		}
+
+		if len(result) == 0 {
+			return nil, ExtractorSourceJSON, errJSONExtractorValueMissing
+		}
+		return result, ExtractorSourceJSON, nil
+	}
+}
@@ -1,6 +1,9 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -66,6 +69,16 @@ func TestCreateExtractors(t *testing.T) {
 			lookups: "headerAuthorization",
 			wantErr: true,
 		},
+		{
+			name:      "json lookup",
+			lookups:   "json:auth.token",
+			wantCount: 1,
+		},
+		{
+			name:    "unknown source",
+			lookups: "header:X,unknown:x",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -535,6 +548,168 @@ func TestValuesFromForm(t *testing.T) {
 	}
 }
 
+func TestValuesFromJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		field       string
+		contentType string
+		body        string
+		limit       uint
+		want        []string
+		wantErr     error
+	}{
+		{
+			name:        "top level field",
+			field:       "token",
+			contentType: "application/json",
+			body:        `{"token":"abc"}`,
+			want:        []string{"abc"},
+		},
+		{
+			name:        "nested field",
+			field:       "auth.token",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"auth":{"token":"abc"}}`,
+			want:        []string{"abc"},
+		},
+		{
+			name:        "structured suffix content type",
+			field:       "token",
+			contentType: "application/vnd.api+json",
+			body:        `{"token":"abc"}`,
+			want:        []string{"abc"},
+		},
+		{
+			name:        "array of strings respects limit",
+			field:       "tokens",
+			contentType: "application/json",
+			body:        `{"tokens":["a",1,"b","c"]}`,
+			limit:       2,
+			want:        []string{"a", "b"},
+		},
+		{
+			name:        "missing field",
+			field:       "auth.token",
+			contentType: "application/json",
+			body:        `{"auth":"abc"}`,
+			wantErr:     errJSONExtractorValueMissing,
+		},
+		{
+			name:        "non string value",
+			field:       "token",
+			contentType: "application/json",
+			body:        `{"token":42}`,
+			wantErr:     errJSONExtractorValueMissing,
+		},
+		{
+			name:        "invalid JSON",
+			field:       "token",
+			contentType: "application/json",
+			body:        `{"token":`,
+			wantErr:     errJSONExtractorValueMissing,
+		},
+		{
+			name:        "not a JSON request",
+			field:       "token",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "token=abc",
+			wantErr:     errJSONExtractorValueMissing,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			values, source, err := valuesFromJSON(tt.field, tt.limit)(req)
+			assert.Equal(t, ExtractorSourceJSON, source)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, values)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, values)
+			}
+
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.body, string(body))
+		})
+	}
+}
+
+func TestRegisterExtractorSource(t *testing.T) {
+	RegisterExtractorSource("test-upper", func(name string, _ uint) (ValuesExtractor, error) {
+		if name == "" {
+			return nil, errors.New("name is required")
+		}
+		return func(r *http.Request) ([]string, ExtractorSource, error) {
+			return []string{strings.ToUpper(r.Header.Get(name))}, "test-upper", nil
+		}, nil
+	})
+	t.Cleanup(func() {
+		extractorSourcesMu.Lock()
+		delete(extractorSources, "test-upper")
+		extractorSourcesMu.Unlock()
+	})
+
+	t.Run("custom source", func(t *testing.T) {
+		extractors, err := CreateExtractors("query:missing,test-upper:X-Key", 0)
+		require.NoError(t, err)
+		require.Len(t, extractors, 2)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Key", "abc")
+
+		values, source, err := extractors[1](req)
+		require.NoError(t, err)
+		assert.Equal(t, ExtractorSource("test-upper"), source)
+		assert.Equal(t, []string{"ABC"}, values)
+	})
+
+	t.Run("factory error", func(t *testing.T) {
+		extractors, err := CreateExtractors("test-upper:", 0)
+		assert.ErrorContains(t, err, "name is required")
+		assert.Nil(t, extractors)
+	})
+
+	t.Run("key auth", func(t *testing.T) {
+		auth := KeyAuthenticator("test-upper:X-Key", func(_ context.Context, key string) (Principal, error) {
+			if key != "ABC" {
+				return nil, errors.New("invalid key")
+			}
+			return &Identity{ID: "service"}, nil
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Key", "abc")
+
+		principal, err := auth.Authenticate(req)
+		require.NoError(t, err)
+		assert.Equal(t, "service", principal.Subject())
+	})
+
+	factory := func(string, uint) (ValuesExtractor, error) { return nil, nil }
+	panics := []struct {
+		name    string
+		source  ExtractorSource
+		factory ExtractorFactory
+	}{
+		{name: "empty source", source: "", factory: factory},
+		{name: "source with colon", source: "a:b", factory: factory},
+		{name: "source with comma", source: "a,b", factory: factory},
+		{name: "nil factory", source: "test-nil", factory: nil},
+		{name: "built-in source", source: ExtractorSourceHeader, factory: factory},
+		{name: "duplicate source", source: "test-upper", factory: factory},
+	}
+	for _, tt := range panics {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Panics(t, func() { RegisterExtractorSource(tt.source, tt.factory) })
+		})
+	}
+}
+
 func TestValueExtractorError(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -591,6 +766,11 @@ func TestExtractorConstants(t *testing.T) {
 			value: ExtractorSourceForm,
 			want:  "form",
 		},
+		{
+			name:  "ExtractorSourceJSON",
+			value: ExtractorSourceJSON,
+			want:  "json",
+		},
 	}
 
 	for _, tt := range tests {
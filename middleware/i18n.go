@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gowool/keratin"
+)
+
+type I18nConfig struct {
+	// Catalog provides the translated messages.
+	// Required.
+	Catalog keratin.MessageCatalog `json:"-" yaml:"-"`
+
+	// Locales are the supported locales, the first one is the default locale
+	// which ends the fallback chain of every request.
+	// Required.
+	Locales []string `env:"LOCALES" json:"locales,omitempty" yaml:"locales,omitempty"`
+
+	// LocaleLookup is a string in the form of "<source>:<name>" or "<source>:<name>,<source>:<name>"
+	// used to extract the locale chosen explicitly by the client, it takes precedence over the
+	// Accept-Language header. See [CreateExtractors] for the possible values.
+	// Optional. Default value "query:lang,cookie:lang".
+	LocaleLookup string `env:"LOCALE_LOOKUP" json:"localeLookup,omitempty" yaml:"localeLookup,omitempty"`
+}
+
+func (c *I18nConfig) SetDefaults() {
+	if c.LocaleLookup == "" {
+		c.LocaleLookup = "query:lang,cookie:lang"
+	}
+}
+
+// I18n returns a middleware that negotiates the request locale and stores a [keratin.Translator]
+// in the request context, so that the error handlers render the messages in the negotiated locale.
+//
+// The locale is chosen from the LocaleLookup values, then from the Accept-Language header,
+// a locale is matched by its exact value or its parent locales, e.g. "de-CH" matches "de".
+// The fallback chain of the translator is the negotiated locale, its parent locales and the default locale.
+// The negotiated locale is sent in the Content-Language header.
+func I18n(cfg I18nConfig, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	cfg.SetDefaults()
+
+	if cfg.Catalog == nil {
+		panic("middleware: i18n: catalog is required")
+	}
+	if len(cfg.Locales) == 0 {
+		panic("middleware: i18n: at least one locale is required")
+	}
+
+	extractors, err := CreateExtractors(cfg.LocaleLookup, 1)
+	if err != nil {
+		panic(fmt.Errorf("middleware: i18n: %w", err))
+	}
+
+	supported := make(map[string]string, len(cfg.Locales))
+	for _, locale := range cfg.Locales {
+		supported[strings.ToLower(locale)] = locale
+	}
+
+	match := func(candidate string) (string, bool) {
+		for _, locale := range keratin.LocaleFallbacks(strings.ToLower(strings.TrimSpace(candidate))) {
+			if l, ok := supported[locale]; ok {
+				return l, true
+			}
+		}
+		return "", false
+	}
+
+	negotiate := func(r *http.Request) string {
+		for _, extractor := range extractors {
+			values, _, err := extractor(r)
+			if err != nil || len(values) == 0 {
+				continue
+			}
+			if locale, ok := match(values[0]); ok {
+				return locale
+			}
+		}
+		for _, candidate := range keratin.ParseAcceptLanguage(r.Header.Get(keratin.HeaderAcceptLanguage)) {
+			if locale, ok := match(candidate); ok {
+				return locale
+			}
+		}
+		return cfg.Locales[0]
+	}
+
+	// the fallback chains are precomputed for every supported locale
+	translators := make(map[string]*keratin.Translator, len(cfg.Locales))
+	for _, locale := range cfg.Locales {
+		chain := keratin.LocaleFallbacks(locale)
+		if !slices.Contains(chain, cfg.Locales[0]) {
+			chain = append(chain, cfg.Locales[0])
+		}
+		translators[locale] = keratin.NewTranslator(cfg.Catalog, chain...)
+	}
+
+	skip := ChainSkipper(skippers...)
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if skip(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			locale := negotiate(r)
+			keratin.SetTranslator(r.Context(), translators[locale])
+
+			w.Header().Set(keratin.HeaderContentLanguage, locale)
+			w.Header().Add(keratin.HeaderVary, keratin.HeaderAcceptLanguage)
+
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
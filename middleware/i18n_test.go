@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+func TestI18n(t *testing.T) {
+	cfg := I18nConfig{
+		Catalog: keratin.Messages{
+			"de": {"Not Found": "Nicht gefunden"},
+			"fr": {"Not Found": "Introuvable"},
+		},
+		Locales: []string{"en", "de", "de-CH", "fr"},
+	}
+
+	tests := []struct {
+		name           string
+		target         string
+		acceptLanguage string
+		cookie         string
+		skippers       []Skipper
+		wantLocale     string
+		wantChain      []string
+		wantBody       string
+	}{
+		{name: "default locale", target: "/", wantLocale: "en", wantChain: []string{"en"}, wantBody: "Not Found\n"},
+		{name: "accept language", target: "/", acceptLanguage: "fr-CA, de;q=0.8", wantLocale: "fr", wantChain: []string{"fr", "en"}, wantBody: "Introuvable\n"},
+		{name: "regional locale falls back to its parent", target: "/", acceptLanguage: "de-ch", wantLocale: "de-CH", wantChain: []string{"de-CH", "de", "en"}, wantBody: "Nicht gefunden\n"},
+		{name: "unsupported languages are skipped", target: "/", acceptLanguage: "es, de", wantLocale: "de", wantChain: []string{"de", "en"}, wantBody: "Nicht gefunden\n"},
+		{name: "query takes precedence", target: "/?lang=fr", acceptLanguage: "de", wantLocale: "fr", wantChain: []string{"fr", "en"}, wantBody: "Introuvable\n"},
+		{name: "cookie takes precedence", target: "/", acceptLanguage: "fr", cookie: "de", wantLocale: "de", wantChain: []string{"de", "en"}, wantBody: "Nicht gefunden\n"},
+		{name: "unsupported query locale", target: "/?lang=es", acceptLanguage: "fr", wantLocale: "fr", wantChain: []string{"fr", "en"}, wantBody: "Introuvable\n"},
+		{name: "skipped", target: "/", acceptLanguage: "de", skippers: []Skipper{func(*http.Request) bool { return true }}, wantBody: "Not Found\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var translator *keratin.Translator
+
+			router := keratin.NewRouter()
+			router.UseFunc(I18n(cfg, tt.skippers...))
+			router.GET("/", func(_ http.ResponseWriter, r *http.Request) error {
+				translator = keratin.TranslatorFromContext(r.Context())
+				return keratin.ErrNotFound
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set(keratin.HeaderAcceptLanguage, tt.acceptLanguage)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "lang", Value: tt.cookie})
+			}
+			rec := httptest.NewRecorder()
+			router.Build().ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantBody, rec.Body.String())
+			assert.Equal(t, tt.wantLocale, rec.Header().Get(keratin.HeaderContentLanguage))
+
+			if tt.wantLocale == "" {
+				assert.Nil(t, translator)
+				assert.Empty(t, rec.Header().Values(keratin.HeaderVary))
+				return
+			}
+			require.NotNil(t, translator)
+			assert.Equal(t, tt.wantChain, translator.Locales)
+			assert.Contains(t, rec.Header().Values(keratin.HeaderVary), keratin.HeaderAcceptLanguage)
+		})
+	}
+}
+
+func TestI18n_Panics(t *testing.T) {
+	catalog := keratin.Messages{}
+
+	assert.Panics(t, func() { I18n(I18nConfig{Locales: []string{"en"}}) })
+	assert.Panics(t, func() { I18n(I18nConfig{Catalog: catalog}) })
+	assert.Panics(t, func() { I18n(I18nConfig{Catalog: catalog, Locales: []string{"en"}, LocaleLookup: "invalid"}) })
+}
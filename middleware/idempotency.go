@@ -0,0 +1,346 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gowool/keratin"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotentReplayed  = "Idempotent-Replayed"
+	maxIdempotencyResponseLen = 1 << 20
+	maxIdempotencyBodyLen     = 1 << 20
+)
+
+var (
+	// ErrIdempotencyKeyMissing is returned when IdempotencyConfig.Required is set and the request has no key.
+	ErrIdempotencyKeyMissing = keratin.NewHTTPError(http.StatusBadRequest, "Idempotency-Key header is required.")
+	// ErrIdempotencyKeyInUse is returned while the first request with the same key is still being processed.
+	ErrIdempotencyKeyInUse = keratin.NewHTTPError(http.StatusConflict, "A request with the same Idempotency-Key is being processed.")
+	// ErrIdempotencyKeyReused is returned when the key was used with a different request payload.
+	ErrIdempotencyKeyReused = keratin.NewHTTPError(http.StatusUnprocessableEntity, "Idempotency-Key was used with a different request payload.")
+)
+
+// IdempotencyStorage stores the responses of idempotent requests.
+type IdempotencyStorage interface {
+	// Get gets the value for the given key. `nil, nil` is returned when the key does not exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Reserve stores the value only if the key does not exist (like Redis SET NX)
+	// and reports whether it was stored.
+	Reserve(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+
+	// Set stores the value for the given key.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes the given key.
+	Delete(ctx context.Context, key string) error
+}
+
+type IdempotencyConfig struct {
+	// Storage stores the responses.
+	// Optional. Default value NewIdempotencyMemoryStorage().
+	Storage IdempotencyStorage `json:"-" yaml:"-"`
+
+	// HeaderName is the request header holding the idempotency key.
+	// Optional. Default value "Idempotency-Key".
+	HeaderName string `env:"HEADER_NAME" json:"headerName,omitempty" yaml:"headerName,omitempty"`
+
+	// Methods is the list of request methods the middleware applies to.
+	// Optional. Default value [POST, PATCH].
+	Methods []string `env:"METHODS" json:"methods,omitempty" yaml:"methods,omitempty"`
+
+	// Required rejects requests without the idempotency key with 400 Bad Request.
+	// Optional. Default value false.
+	Required bool `env:"REQUIRED" json:"required,omitempty" yaml:"required,omitempty"`
+
+	// TTL is how long responses are kept and replayed.
+	// Optional. Default value 24h.
+	TTL time.Duration `env:"TTL" json:"ttl,omitempty" yaml:"ttl,omitempty"`
+
+	// LockTTL is how long a key is reserved while its first request is processed.
+	// Optional. Default value 1m.
+	LockTTL time.Duration `env:"LOCK_TTL" json:"lockTTL,omitempty" yaml:"lockTTL,omitempty"`
+
+	// MaxResponseSize is the maximum size of a stored response body.
+	// Larger responses are not stored and the key can be reused.
+	// Optional. Default value 1MB.
+	MaxResponseSize int `env:"MAX_RESPONSE_SIZE" json:"maxResponseSize,omitempty" yaml:"maxResponseSize,omitempty"`
+
+	// MaxBodySize is the maximum size of the request body read to compute its hash.
+	// Larger requests are rejected with 413 Request Entity Too Large.
+	// Optional. Default value 1MB.
+	MaxBodySize int64 `env:"MAX_BODY_SIZE" json:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty"`
+
+	// ScopeFunc returns the scope of the idempotency keys, so a client cannot replay the response
+	// stored for the key of another client, e.g. the session ID.
+	// Optional. Default value the subject of the principal authenticated by [Auth], empty for anonymous requests.
+	ScopeFunc func(r *http.Request) string `json:"-" yaml:"-"`
+
+	// KeyPrefix is prepended to the storage keys.
+	// Optional. Default value "idempotency:".
+	KeyPrefix string `env:"KEY_PREFIX" json:"keyPrefix,omitempty" yaml:"keyPrefix,omitempty"`
+}
+
+func (c *IdempotencyConfig) SetDefaults() {
+	if c.Storage == nil {
+		c.Storage = NewIdempotencyMemoryStorage()
+	}
+	if c.HeaderName == "" {
+		c.HeaderName = HeaderIdempotencyKey
+	}
+	if len(c.Methods) == 0 {
+		c.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	if c.TTL <= 0 {
+		c.TTL = 24 * time.Hour
+	}
+	if c.LockTTL <= 0 {
+		c.LockTTL = time.Minute
+	}
+	if c.MaxResponseSize <= 0 {
+		c.MaxResponseSize = maxIdempotencyResponseLen
+	}
+	if c.MaxBodySize <= 0 {
+		c.MaxBodySize = maxIdempotencyBodyLen
+	}
+	if c.ScopeFunc == nil {
+		c.ScopeFunc = principalScope
+	}
+	if c.KeyPrefix == "" {
+		c.KeyPrefix = "idempotency:"
+	}
+}
+
+// principalScope returns the subject of the authenticated principal.
+func principalScope(r *http.Request) string {
+	if principal := CtxPrincipal(r.Context()); principal != nil {
+		return principal.Subject()
+	}
+	return ""
+}
+
+type idempotencyRecord struct {
+	Hash   string      `json:"hash"`
+	Done   bool        `json:"done,omitempty"`
+	Status int         `json:"status,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// Idempotency returns a middleware which stores the first response for an Idempotency-Key
+// (per scope, method, path and query) and replays it for retries within IdempotencyConfig.TTL.
+// Only the headers set by the handler are stored, the ones of the outer middlewares (e.g. the request ID)
+// are set again for the retry. The Set-Cookie headers are neither stored nor replayed.
+//
+// Retries with a different request payload are rejected with 422 Unprocessable Entity,
+// retries arriving while the first request is still processed with 409 Conflict.
+// Failed requests (handler errors and 5xx responses) are not stored so they can be retried.
+func Idempotency(cfg IdempotencyConfig, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	cfg.SetDefaults()
+
+	skip := ChainSkipper(skippers...)
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if skip(r) || !slices.Contains(cfg.Methods, r.Method) {
+				return next.ServeHTTP(w, r)
+			}
+
+			idempotencyKey := r.Header.Get(cfg.HeaderName)
+			if idempotencyKey == "" {
+				if cfg.Required {
+					return ErrIdempotencyKeyMissing
+				}
+				return next.ServeHTTP(w, r)
+			}
+
+			hash, err := hashRequestBody(r, cfg.MaxBodySize)
+			if err != nil {
+				return err
+			}
+
+			ctx := r.Context()
+			target := r.URL.Path
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			key := cfg.KeyPrefix + cfg.ScopeFunc(r) + ":" + r.Method + " " + target + ":" + idempotencyKey
+
+			raw, err := json.Marshal(idempotencyRecord{Hash: hash})
+			if err != nil {
+				return err
+			}
+
+			reserved, err := cfg.Storage.Reserve(ctx, key, raw, cfg.LockTTL)
+			if err != nil {
+				return err
+			}
+
+			if !reserved {
+				return replayIdempotent(ctx, w, cfg.Storage, key, hash)
+			}
+
+			// the headers set by the outer middlewares so far are not part of the stored response
+			initial := w.Header().Clone()
+			iw := &idempotencyWriter{ResponseWriter: w, maxSize: cfg.MaxResponseSize}
+
+			if err = next.ServeHTTP(iw, r); err != nil || iw.status() >= http.StatusInternalServerError || iw.overflow {
+				_ = cfg.Storage.Delete(context.WithoutCancel(ctx), key)
+				return err
+			}
+
+			header := handlerHeader(initial, w.Header())
+
+			raw, err = json.Marshal(idempotencyRecord{
+				Hash:   hash,
+				Done:   true,
+				Status: iw.status(),
+				Header: header,
+				Body:   iw.buf.Bytes(),
+			})
+			if err == nil {
+				err = cfg.Storage.Set(context.WithoutCancel(ctx), key, raw, cfg.TTL)
+			}
+			if err != nil {
+				// the response has been sent already, so do not fail the request
+				_ = cfg.Storage.Delete(context.WithoutCancel(ctx), key)
+			}
+			return nil
+		})
+	}
+}
+
+func replayIdempotent(ctx context.Context, w http.ResponseWriter, storage IdempotencyStorage, key, hash string) error {
+	raw, err := storage.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if raw == nil {
+		// the first request failed or the record expired in the meantime
+		return ErrIdempotencyKeyInUse
+	}
+
+	var rec idempotencyRecord
+	if err = json.Unmarshal(raw, &rec); err != nil {
+		return err
+	}
+
+	if rec.Hash != hash {
+		return ErrIdempotencyKeyReused
+	}
+	if !rec.Done {
+		return ErrIdempotencyKeyInUse
+	}
+
+	header := w.Header()
+	for name, values := range rec.Header {
+		if http.CanonicalHeaderKey(name) != keratin.HeaderSetCookie {
+			header[name] = values
+		}
+	}
+	header.Set(HeaderIdempotentReplayed, "true")
+
+	w.WriteHeader(rec.Status)
+	_, err = w.Write(rec.Body)
+	return err
+}
+
+// handlerHeader returns the headers added or changed since initial, without the Set-Cookie ones.
+func handlerHeader(initial, header http.Header) http.Header {
+	changed := make(http.Header)
+	for name, values := range header {
+		if name != keratin.HeaderSetCookie && !slices.Equal(initial[name], values) {
+			changed[name] = slices.Clone(values)
+		}
+	}
+	return changed
+}
+
+func hashRequestBody(r *http.Request, limit int64) (string, error) {
+	body, err := keratin.BufferBody(r, limit)
+	if err != nil {
+		if code := keratin.ErrorStatusCode(err); code >= http.StatusBadRequest {
+			return "", err
+		}
+		return "", keratin.ErrBadRequest.Wrap(err)
+	}
+
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// idempotencyWriter captures the response status code and body while writing them through.
+type idempotencyWriter struct {
+	http.ResponseWriter
+	buf      bytes.Buffer
+	code     int
+	maxSize  int
+	overflow bool
+}
+
+func (w *idempotencyWriter) WriteHeader(statusCode int) {
+	if w.code == 0 && (statusCode < 100 || statusCode > 199) {
+		w.code = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	if !w.overflow {
+		if w.buf.Len()+len(b) > w.maxSize {
+			w.overflow = true
+			w.buf.Reset()
+		} else {
+			w.buf.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyWriter) status() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+	return w.code
+}
+
+func (w *idempotencyWriter) Flush() {
+	keratin.ResponseFlush(w.ResponseWriter)
+}
+
+func (w *idempotencyWriter) FlushError() error {
+	return keratin.ResponseFlushError(w.ResponseWriter)
+}
+
+func (w *idempotencyWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *idempotencyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+var _ IdempotencyStorage = (*IdempotencyMemoryStorage)(nil)
+
+// IdempotencyMemoryStorage is an in-memory [IdempotencyStorage] for single instance deployments.
+type IdempotencyMemoryStorage struct {
+	memoryStorage
+}
+
+func NewIdempotencyMemoryStorage() *IdempotencyMemoryStorage {
+	return &IdempotencyMemoryStorage{memoryStorage: newMemoryStorage()}
+}
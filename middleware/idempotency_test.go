@@ -0,0 +1,318 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gowool/keratin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyConfig_SetDefaults(t *testing.T) {
+	cfg := IdempotencyConfig{}
+	cfg.SetDefaults()
+
+	assert.NotNil(t, cfg.Storage)
+	assert.Equal(t, HeaderIdempotencyKey, cfg.HeaderName)
+	assert.Equal(t, []string{http.MethodPost, http.MethodPatch}, cfg.Methods)
+	assert.Equal(t, 24*time.Hour, cfg.TTL)
+	assert.Equal(t, time.Minute, cfg.LockTTL)
+	assert.Equal(t, maxIdempotencyResponseLen, cfg.MaxResponseSize)
+	assert.Equal(t, int64(maxIdempotencyBodyLen), cfg.MaxBodySize)
+	assert.Equal(t, "idempotency:", cfg.KeyPrefix)
+	assert.NotNil(t, cfg.ScopeFunc)
+}
+
+func newIdempotencyRequest(method, path, key, body string) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	return req
+}
+
+func TestIdempotency(t *testing.T) {
+	var calls atomic.Int32
+
+	handler := keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		n := calls.Add(1)
+		w.Header().Set("X-Call", strings.Repeat("i", int(n)))
+		w.WriteHeader(http.StatusCreated)
+		_, err := w.Write([]byte("created"))
+		return err
+	})
+
+	newMiddleware := func(cfg IdempotencyConfig) keratin.Handler {
+		calls.Store(0)
+		return Idempotency(cfg)(handler)
+	}
+
+	t.Run("replays stored response", func(t *testing.T) {
+		h := newMiddleware(IdempotencyConfig{})
+
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.ServeHTTP(rec, newIdempotencyRequest(http.MethodPost, "/pay", "k1", `{"a":1}`)))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Empty(t, rec.Header().Get(HeaderIdempotentReplayed))
+
+		rec = httptest.NewRecorder()
+		require.NoError(t, h.ServeHTTP(rec, newIdempotencyRequest(http.MethodPost, "/pay", "k1", `{"a":1}`)))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "created", rec.Body.String())
+		assert.Equal(t, "i", rec.Header().Get("X-Call"))
+		assert.Equal(t, "true", rec.Header().Get(HeaderIdempotentReplayed))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("different payload", func(t *testing.T) {
+		h := newMiddleware(IdempotencyConfig{})
+
+		require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), newIdempotencyRequest(http.MethodPost, "/pay", "k1", `{"a":1}`)))
+
+		err := h.ServeHTTP(httptest.NewRecorder(), newIdempotencyRequest(http.MethodPost, "/pay", "k1", `{"a":2}`))
+		assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+		assert.Equal(t, http.StatusUnprocessableEntity, keratin.HTTPErrorStatusCode(err))
+	})
+
+	t.Run("keys are scoped by route", func(t *testing.T) {
+		h := newMiddleware(IdempotencyConfig{})
+
+		require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), newIdempotencyRequest(http.MethodPost, "/a", "k1", "")))
+		require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), newIdempotencyRequest(http.MethodPost, "/b", "k1", "")))
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("keys are scoped by query", func(t *testing.T) {
+		h := newMiddleware(IdempotencyConfig{})
+
+		require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), newIdempotencyRequest(http.MethodPost, "/orders", "k1", "")))
+
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.ServeHTTP(rec, newIdempotencyRequest(http.MethodPost, "/orders?dry_run=1", "k1", "")))
+		assert.Empty(t, rec.Header().Get(HeaderIdempotentReplayed))
+		assert.Equal(t, int32(2), calls.Load())
+
+		rec = httptest.NewRecorder()
+		require.NoError(t, h.ServeHTTP(rec, newIdempotencyRequest(http.MethodPost, "/orders?dry_run=1", "k1", "")))
+		assert.Equal(t, "true", rec.Header().Get(HeaderIdempotentReplayed))
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("outer headers are not replayed", func(t *testing.T) {
+		var requestID atomic.Int32
+		outer := func(next keratin.Handler) keratin.Handler {
+			return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set(keratin.HeaderXRequestID, strconv.Itoa(int(requestID.Add(1))))
+				w.Header().Add(keratin.HeaderVary, keratin.HeaderOrigin)
+				return next.ServeHTTP(w, r)
+			})
+		}
+		h := outer(newMiddleware(IdempotencyConfig{}))
+
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.ServeHTTP(rec, newIdempotencyRequest(http.MethodPost, "/pay", "k1", "")))
+		assert.Equal(t, "1", rec.Header().Get(keratin.HeaderXRequestID))
+
+		rec = httptest.NewRecorder()
+		require.NoError(t, h.ServeHTTP(rec, newIdempotencyRequest(http.MethodPost, "/pay", "k1", "")))
+		assert.Equal(t, "true", rec.Header().Get(HeaderIdempotentReplayed))
+		assert.Equal(t, "2", rec.Header().Get(keratin.HeaderXRequestID))
+		assert.Equal(t, []string{keratin.HeaderOrigin}, rec.Header().Values(keratin.HeaderVary))
+		assert.Equal(t, "i", rec.Header().Get("X-Call"))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("keys are scoped by principal", func(t *testing.T) {
+		h := newMiddleware(IdempotencyConfig{})
+
+		as := func(subject string) *http.Request {
+			r := newIdempotencyRequest(http.MethodPost, "/pay", "k1", `{"a":1}`)
+			return r.WithContext(WithPrincipal(r.Context(), &Identity{ID: subject}))
+		}
+
+		require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), as("alice")))
+
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.ServeHTTP(rec, as("bob")))
+		assert.Empty(t, rec.Header().Get(HeaderIdempotentReplayed))
+		assert.Equal(t, int32(2), calls.Load())
+
+		rec = httptest.NewRecorder()
+		require.NoError(t, h.ServeHTTP(rec, as("alice")))
+		assert.Equal(t, "true", rec.Header().Get(HeaderIdempotentReplayed))
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("cookies are not replayed", func(t *testing.T) {
+		h := Idempotency(IdempotencyConfig{})(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret"})
+			w.WriteHeader(http.StatusCreated)
+			return nil
+		}))
+
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.ServeHTTP(rec, newIdempotencyRequest(http.MethodPost, "/login", "k1", "")))
+		assert.NotEmpty(t, rec.Header().Get(keratin.HeaderSetCookie))
+
+		rec = httptest.NewRecorder()
+		require.NoError(t, h.ServeHTTP(rec, newIdempotencyRequest(http.MethodPost, "/login", "k1", "")))
+		assert.Equal(t, "true", rec.Header().Get(HeaderIdempotentReplayed))
+		assert.Empty(t, rec.Header().Get(keratin.HeaderSetCookie))
+	})
+
+	t.Run("without key", func(t *testing.T) {
+		h := newMiddleware(IdempotencyConfig{})
+
+		require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), newIdempotencyRequest(http.MethodPost, "/pay", "", "")))
+		require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), newIdempotencyRequest(http.MethodPost, "/pay", "", "")))
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("required key", func(t *testing.T) {
+		h := newMiddleware(IdempotencyConfig{Required: true})
+
+		err := h.ServeHTTP(httptest.NewRecorder(), newIdempotencyRequest(http.MethodPost, "/pay", "", ""))
+		assert.ErrorIs(t, err, ErrIdempotencyKeyMissing)
+		assert.Equal(t, int32(0), calls.Load())
+	})
+
+	t.Run("ignored methods", func(t *testing.T) {
+		h := newMiddleware(IdempotencyConfig{})
+
+		require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), newIdempotencyRequest(http.MethodPut, "/pay", "k1", "")))
+		require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), newIdempotencyRequest(http.MethodPut, "/pay", "k1", "")))
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("request body is restored", func(t *testing.T) {
+		var body string
+		h := Idempotency(IdempotencyConfig{})(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			b, err := io.ReadAll(r.Body)
+			body = string(b)
+			return err
+		}))
+
+		require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), newIdempotencyRequest(http.MethodPost, "/pay", "k1", "payload")))
+		assert.Equal(t, "payload", body)
+	})
+
+	t.Run("request body too large", func(t *testing.T) {
+		h := newMiddleware(IdempotencyConfig{MaxBodySize: 4})
+
+		err := h.ServeHTTP(httptest.NewRecorder(), newIdempotencyRequest(http.MethodPost, "/pay", "k1", "payload"))
+		assert.ErrorIs(t, err, keratin.ErrRequestEntityTooLarge)
+		assert.Equal(t, int32(0), calls.Load())
+	})
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	h := Idempotency(IdempotencyConfig{})(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		close(started)
+		<-release
+		return nil
+	}))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.ServeHTTP(httptest.NewRecorder(), newIdempotencyRequest(http.MethodPost, "/pay", "k1", ""))
+	}()
+
+	<-started
+	err := h.ServeHTTP(httptest.NewRecorder(), newIdempotencyRequest(http.MethodPost, "/pay", "k1", ""))
+	assert.ErrorIs(t, err, ErrIdempotencyKeyInUse)
+	assert.Equal(t, http.StatusConflict, keratin.HTTPErrorStatusCode(err))
+
+	close(release)
+	require.NoError(t, <-done)
+}
+
+func TestIdempotency_FailuresAreNotStored(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     IdempotencyConfig
+		handler keratin.HandlerFunc
+	}{
+		{
+			name: "handler error",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				return errors.New("boom")
+			},
+		},
+		{
+			name: "server error status",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusBadGateway)
+				return nil
+			},
+		},
+		{
+			name: "response too large",
+			cfg:  IdempotencyConfig{MaxResponseSize: 4},
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				_, err := w.Write([]byte("too large"))
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := NewIdempotencyMemoryStorage()
+			tt.cfg.Storage = storage
+
+			var calls int
+			h := Idempotency(tt.cfg)(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				calls++
+				return tt.handler(w, r)
+			}))
+
+			_ = h.ServeHTTP(httptest.NewRecorder(), newIdempotencyRequest(http.MethodPost, "/pay", "k1", ""))
+			_ = h.ServeHTTP(httptest.NewRecorder(), newIdempotencyRequest(http.MethodPost, "/pay", "k1", ""))
+			assert.Equal(t, 2, calls)
+			assert.Empty(t, storage.data)
+		})
+	}
+}
+
+func TestIdempotencyMemoryStorage(t *testing.T) {
+	ctx := context.Background()
+	s := NewIdempotencyMemoryStorage()
+
+	ok, err := s.Reserve(ctx, "k", []byte("a"), time.Hour)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = s.Reserve(ctx, "k", []byte("b"), time.Hour)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	v, err := s.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("a"), v)
+
+	require.NoError(t, s.Set(ctx, "k", []byte("c"), -time.Second))
+	v, err = s.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Nil(t, v)
+
+	ok, err = s.Reserve(ctx, "k", []byte("d"), time.Hour)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.NoError(t, s.Delete(ctx, "k"))
+	v, err = s.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
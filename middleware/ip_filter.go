@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/gowool/keratin"
+)
+
+// ErrIPForbidden is returned for the requests rejected by [IPFilter].
+var ErrIPForbidden = keratin.NewHTTPError(http.StatusForbidden, "access denied")
+
+// GeoIPResolver resolves the country of an IP address, e.g. backed by a MaxMind database.
+type GeoIPResolver interface {
+	// Country returns the ISO 3166-1 alpha-2 country code of the address.
+	Country(ctx context.Context, addr netip.Addr) (string, error)
+}
+
+// GeoIPResolverFunc is an adapter to allow the use of ordinary functions as [GeoIPResolver].
+type GeoIPResolverFunc func(ctx context.Context, addr netip.Addr) (string, error)
+
+func (f GeoIPResolverFunc) Country(ctx context.Context, addr netip.Addr) (string, error) {
+	return f(ctx, addr)
+}
+
+type IPFilterConfig struct {
+	// Allow is the list of the allowed IP addresses and CIDR prefixes.
+	// Optional.
+	Allow []string `env:"ALLOW" json:"allow,omitempty" yaml:"allow,omitempty"`
+
+	// Deny is the list of the denied IP addresses and CIDR prefixes.
+	// Optional.
+	Deny []string `env:"DENY" json:"deny,omitempty" yaml:"deny,omitempty"`
+
+	// AllowCountries is the list of the allowed ISO 3166-1 alpha-2 country codes, requires GeoIP.
+	// Optional.
+	AllowCountries []string `env:"ALLOW_COUNTRIES" json:"allowCountries,omitempty" yaml:"allowCountries,omitempty"`
+
+	// DenyCountries is the list of the denied ISO 3166-1 alpha-2 country codes, requires GeoIP.
+	// Optional.
+	DenyCountries []string `env:"DENY_COUNTRIES" json:"denyCountries,omitempty" yaml:"denyCountries,omitempty"`
+
+	// GeoIP resolves the countries of the clients.
+	// Optional. Required with AllowCountries or DenyCountries.
+	GeoIP GeoIPResolver `json:"-" yaml:"-"`
+
+	// IPExtractor extracts the client IP from the request.
+	// Optional. Default value is the IP extracted by the router [keratin.IPExtractor], see [keratin.WithIPExtractor],
+	// or [keratin.RemoteIP] outside the router.
+	IPExtractor keratin.IPExtractor `json:"-" yaml:"-"`
+
+	// Logger logs the rejected requests.
+	// Optional. Default value nil (no logging).
+	Logger *slog.Logger `json:"-" yaml:"-"`
+
+	// ErrorHandler defines a function which is executed for returning custom errors.
+	ErrorHandler func(r *http.Request, err error) error `json:"-" yaml:"-"`
+}
+
+func (c *IPFilterConfig) SetDefaults() {
+	if c.IPExtractor == nil {
+		c.IPExtractor = keratin.ClientIP
+	}
+}
+
+// IPFilter returns a middleware which rejects the requests by the client IP address and country
+// with 403 Forbidden.
+//
+// The deny rules win over the allow rules. If any allow rule is configured, the client must match
+// at least one of them. Requests with an unparsable client IP and, with AllowCountries, the clients
+// with an unresolved country are rejected.
+func IPFilter(cfg IPFilterConfig, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	cfg.SetDefaults()
+
+	allow, err := parsePrefixes(cfg.Allow)
+	if err != nil {
+		panic(fmt.Sprintf("middleware: ip filter: allow: %v", err))
+	}
+	deny, err := parsePrefixes(cfg.Deny)
+	if err != nil {
+		panic(fmt.Sprintf("middleware: ip filter: deny: %v", err))
+	}
+
+	allowCountries := upperAll(cfg.AllowCountries)
+	denyCountries := upperAll(cfg.DenyCountries)
+	geo := len(allowCountries) > 0 || len(denyCountries) > 0
+
+	if geo && cfg.GeoIP == nil {
+		panic("middleware: ip filter: GeoIP resolver is required for country rules")
+	}
+
+	hasAllow := len(allow) > 0 || len(allowCountries) > 0
+
+	check := func(r *http.Request) (string, string) {
+		ip := cfg.IPExtractor(r)
+
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return ip, "invalid client IP"
+		}
+		addr = addr.Unmap()
+
+		if containsAddr(deny, addr) {
+			return ip, "denied IP"
+		}
+
+		var country string
+		if geo {
+			if country, err = cfg.GeoIP.Country(r.Context(), addr); err == nil {
+				country = strings.ToUpper(country)
+			} else {
+				country = ""
+			}
+			if country != "" && slices.Contains(denyCountries, country) {
+				return ip, "denied country " + country
+			}
+		}
+
+		if !hasAllow || containsAddr(allow, addr) || country != "" && slices.Contains(allowCountries, country) {
+			return ip, ""
+		}
+		return ip, "not allowed"
+	}
+
+	skip := ChainSkipper(skippers...)
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if skip(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			ip, reason := check(r)
+			if reason == "" {
+				return next.ServeHTTP(w, r)
+			}
+
+			if cfg.Logger != nil {
+				cfg.Logger.LogAttrs(r.Context(), slog.LevelWarn, "ip filter: request rejected",
+					slog.String("ip", ip),
+					slog.String("reason", reason),
+					slog.String("method", r.Method),
+					slog.String("uri", r.RequestURI),
+				)
+			}
+
+			err := ErrIPForbidden.Wrap(fmt.Errorf("ip filter: %s: %s", ip, reason))
+			if cfg.ErrorHandler != nil {
+				return cfg.ErrorHandler(r, err)
+			}
+			return err
+		})
+	}
+}
+
+func parsePrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if strings.Contains(value, "/") {
+			prefix, err := netip.ParsePrefix(value)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, err
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func upperAll(values []string) []string {
+	upper := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.ToUpper(strings.TrimSpace(value)); value != "" {
+			upper = append(upper, value)
+		}
+	}
+	return upper
+}
@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+func TestIPFilter(t *testing.T) {
+	geo := GeoIPResolverFunc(func(_ context.Context, addr netip.Addr) (string, error) {
+		switch {
+		case netip.MustParsePrefix("203.0.113.0/24").Contains(addr):
+			return "de", nil
+		case netip.MustParsePrefix("198.51.100.0/24").Contains(addr):
+			return "KP", nil
+		}
+		return "", errors.New("unknown")
+	})
+
+	tests := []struct {
+		name       string
+		cfg        IPFilterConfig
+		remoteAddr string
+		wantCode   int
+	}{
+		{
+			name:       "no rules",
+			remoteAddr: "192.0.2.1:1234",
+			wantCode:   http.StatusOK,
+		},
+		{
+			name:       "allowed CIDR",
+			cfg:        IPFilterConfig{Allow: []string{"10.0.0.0/8", "192.0.2.0/24"}},
+			remoteAddr: "192.0.2.1:1234",
+			wantCode:   http.StatusOK,
+		},
+		{
+			name:       "not allowed",
+			cfg:        IPFilterConfig{Allow: []string{"10.0.0.0/8"}},
+			remoteAddr: "192.0.2.1:1234",
+			wantCode:   http.StatusForbidden,
+		},
+		{
+			name:       "allowed single IPv6",
+			cfg:        IPFilterConfig{Allow: []string{"2001:db8::1"}},
+			remoteAddr: "[2001:db8::1]:1234",
+			wantCode:   http.StatusOK,
+		},
+		{
+			name:       "IPv4-mapped IPv6",
+			cfg:        IPFilterConfig{Deny: []string{"192.0.2.1"}},
+			remoteAddr: "[::ffff:192.0.2.1]:1234",
+			wantCode:   http.StatusForbidden,
+		},
+		{
+			name:       "deny wins over allow",
+			cfg:        IPFilterConfig{Allow: []string{"192.0.2.0/24"}, Deny: []string{"192.0.2.128/25"}},
+			remoteAddr: "192.0.2.200:1234",
+			wantCode:   http.StatusForbidden,
+		},
+		{
+			name:       "invalid client IP",
+			cfg:        IPFilterConfig{Deny: []string{"192.0.2.1"}},
+			remoteAddr: "@",
+			wantCode:   http.StatusForbidden,
+		},
+		{
+			name:       "allowed country",
+			cfg:        IPFilterConfig{AllowCountries: []string{"DE"}, GeoIP: geo},
+			remoteAddr: "203.0.113.7:1234",
+			wantCode:   http.StatusOK,
+		},
+		{
+			name:       "unresolved country",
+			cfg:        IPFilterConfig{AllowCountries: []string{"DE"}, GeoIP: geo},
+			remoteAddr: "192.0.2.1:1234",
+			wantCode:   http.StatusForbidden,
+		},
+		{
+			name:       "allowed by IP without country",
+			cfg:        IPFilterConfig{Allow: []string{"192.0.2.1"}, AllowCountries: []string{"DE"}, GeoIP: geo},
+			remoteAddr: "192.0.2.1:1234",
+			wantCode:   http.StatusOK,
+		},
+		{
+			name:       "denied country",
+			cfg:        IPFilterConfig{DenyCountries: []string{"kp"}, GeoIP: geo},
+			remoteAddr: "198.51.100.7:1234",
+			wantCode:   http.StatusForbidden,
+		},
+		{
+			name:       "denied country wins over allowed IP",
+			cfg:        IPFilterConfig{Allow: []string{"198.51.100.0/24"}, DenyCountries: []string{"KP"}, GeoIP: geo},
+			remoteAddr: "198.51.100.7:1234",
+			wantCode:   http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := IPFilter(tt.cfg)(keratin.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+				w.WriteHeader(http.StatusOK)
+				return nil
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+
+			err := h.ServeHTTP(httptest.NewRecorder(), r)
+			if tt.wantCode == http.StatusOK {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.wantCode, keratin.HTTPErrorStatusCode(err))
+		})
+	}
+}
+
+func TestIPFilter_RouterIPExtractor(t *testing.T) {
+	var logs bytes.Buffer
+
+	router := keratin.NewRouter(keratin.WithIPExtractor(keratin.RealIP(func(context.Context) (*keratin.TrustedProxy, error) {
+		return &keratin.TrustedProxy{Headers: []string{keratin.HeaderXForwardedFor}}, nil
+	})))
+	router.UseFunc(IPFilter(IPFilterConfig{
+		Deny:   []string{"203.0.113.0/24"},
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	}))
+	router.GET("/", func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+	handler := router.Build()
+
+	tests := []struct {
+		name     string
+		xff      string
+		wantCode int
+	}{
+		{name: "trusted header allowed", xff: "192.0.2.1", wantCode: http.StatusNoContent},
+		{name: "trusted header denied", xff: "203.0.113.9", wantCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = "10.0.0.1:1234"
+			r.Header.Set(keratin.HeaderXForwardedFor, tt.xff)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+
+	assert.Contains(t, logs.String(), "ip=203.0.113.9")
+	assert.Contains(t, logs.String(), "reason=\"denied IP\"")
+}
+
+func TestIPFilter_ErrorHandler(t *testing.T) {
+	custom := errors.New("custom")
+
+	h := IPFilter(IPFilterConfig{
+		Deny:         []string{"0.0.0.0/0"},
+		ErrorHandler: func(_ *http.Request, err error) error { return errors.Join(custom, err) },
+	})(keratin.HandlerFunc(func(http.ResponseWriter, *http.Request) error { return nil }))
+
+	err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	require.ErrorIs(t, err, custom)
+	assert.Equal(t, http.StatusForbidden, keratin.HTTPErrorStatusCode(err))
+}
+
+func TestIPFilter_Panics(t *testing.T) {
+	assert.Panics(t, func() { IPFilter(IPFilterConfig{Allow: []string{"invalid"}}) })
+	assert.Panics(t, func() { IPFilter(IPFilterConfig{Deny: []string{"10.0.0.0/99"}}) })
+	assert.Panics(t, func() { IPFilter(IPFilterConfig{AllowCountries: []string{"DE"}}) })
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"errors"
+	"io/fs"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gowool/keratin"
+	"github.com/gowool/keratin/jsonschema"
+)
+
+// maxSchemaBodySize limits the request bodies validated by the JSONSchema middleware.
+const maxSchemaBodySize = 10 << 20
+
+// ErrSchemaViolation is returned when the request body does not match its JSON Schema,
+// the details are the [jsonschema.Error] list with the JSON pointers of the invalid values.
+var ErrSchemaViolation = keratin.NewHTTPErrorWithCode(http.StatusUnprocessableEntity, "schema_violation", "Request body does not match the schema.")
+
+// JSONSchema returns a middleware validating the JSON request bodies against the JSON Schema documents of schemaFS.
+//
+// The schema of a route is its routeToSchema entry, keyed by the route pattern with its method (e.g. "POST /users")
+// or without it for any method, or else the [keratin.Route] RequestSchema (see [keratin.Route.Schema]).
+// Requests of the routes without schema are not checked. The body is left readable for the next handlers.
+//
+// Invalid bodies are rejected with [ErrSchemaViolation] (422), malformed JSON with 400 Bad Request
+// and the bodies not of a JSON media type with 415 Unsupported Media Type.
+//
+// The routeToSchema schemas are compiled when the middleware is created, it panics if they are invalid.
+func JSONSchema(schemaFS fs.FS, routeToSchema map[string]string, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	if schemaFS == nil {
+		panic("middleware: json schema: schema fs is required")
+	}
+
+	skip := ChainSkipper(skippers...)
+	registry := jsonschema.NewRegistry(schemaFS)
+
+	for _, name := range routeToSchema {
+		if _, err := registry.Schema(name); err != nil {
+			panic("middleware: json schema: " + err.Error())
+		}
+	}
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if skip(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			name := routeSchema(r, routeToSchema, func(route *keratin.Route) string { return route.RequestSchema })
+			if name == "" {
+				return next.ServeHTTP(w, r)
+			}
+
+			schema, err := registry.Schema(name)
+			if err != nil {
+				return err
+			}
+
+			if err = validateBody(r, schema); err != nil {
+				return err
+			}
+
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// routeSchema returns the schema name of the matched route from routeToSchema or the route itself,
+// "" if it has none.
+func routeSchema(r *http.Request, routeToSchema map[string]string, fromRoute func(*keratin.Route) string) string {
+	c := keratin.FromContext(r.Context())
+
+	route := c.Route()
+	if route == nil {
+		return ""
+	}
+
+	if route.Method != "" {
+		if name, ok := routeToSchema[route.Method+" "+c.Pattern()]; ok {
+			return name
+		}
+	}
+	if name, ok := routeToSchema[c.Pattern()]; ok {
+		return name
+	}
+	return fromRoute(route)
+}
+
+func validateBody(r *http.Request, schema *jsonschema.Schema) error {
+	body, err := keratin.BufferBody(r, maxSchemaBodySize)
+	if err != nil {
+		return err
+	}
+
+	if len(body) == 0 {
+		// a missing body is validated as null
+		body = []byte("null")
+	} else {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get(keratin.HeaderContentType))
+		if mediaType != keratin.MIMEApplicationJSON && !strings.HasSuffix(mediaType, "+json") {
+			return keratin.ErrUnsupportedMediaType
+		}
+	}
+
+	err = schema.ValidateJSON(body)
+	if ve, ok := errors.AsType[jsonschema.ValidationError](err); ok {
+		return ErrSchemaViolation.WithDetails([]jsonschema.Error(ve)).Wrap(err)
+	}
+	if err != nil {
+		return keratin.NewHTTPError(http.StatusBadRequest, "Request body is not valid JSON.").Wrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+var schemaTestFS = fstest.MapFS{
+	"create-user.json": {Data: []byte(`{
+		"type": "object",
+		"required": ["name", "email"],
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"email": {"type": "string", "format": "email"}
+		},
+		"additionalProperties": false
+	}`)},
+	"update-user.json": {Data: []byte(`{"type": "object", "properties": {"name": {"type": "string"}}, "minProperties": 1}`)},
+	"broken.json":      {Data: []byte(`{"type": "object", "pattern": "("}`)},
+}
+
+func TestJSONSchema(t *testing.T) {
+	echo := func(w http.ResponseWriter, r *http.Request) error {
+		body, _ := io.ReadAll(r.Body)
+		return keratin.TextPlain(w, http.StatusOK, string(body))
+	}
+
+	router := keratin.NewRouter()
+	router.UseFunc(JSONSchema(schemaTestFS, map[string]string{"POST /users": "create-user.json"}))
+	router.POST("/users", echo)
+	router.PATCH("/users/{id}", echo).Schema("update-user.json")
+	router.POST("/broken", echo).Schema("broken.json")
+	router.POST("/free", echo)
+
+	handler := router.Build()
+
+	tests := []struct {
+		name        string
+		target      string
+		method      string
+		contentType string
+		body        string
+		wantCode    int
+		wantBody    string
+		wantDetails string
+	}{
+		{
+			name:        "valid",
+			method:      http.MethodPost,
+			target:      "/users",
+			contentType: keratin.MIMEApplicationJSON,
+			body:        `{"name":"Jane","email":"jane@example.com"}`,
+			wantCode:    http.StatusOK,
+			wantBody:    `{"name":"Jane","email":"jane@example.com"}`,
+		},
+		{
+			name:        "invalid",
+			method:      http.MethodPost,
+			target:      "/users",
+			contentType: keratin.MIMEApplicationJSON,
+			body:        `{"name":"","email":"jane","admin":true}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantDetails: `[
+				{"pointer":"/admin","keyword":"additionalProperties","message":"is not allowed"},
+				{"pointer":"/email","keyword":"format","message":"must be a valid email"},
+				{"pointer":"/name","keyword":"minLength","message":"must be at least 1 characters long"}
+			]`,
+		},
+		{
+			name:        "empty body",
+			method:      http.MethodPost,
+			target:      "/users",
+			wantCode:    http.StatusUnprocessableEntity,
+			wantDetails: `[{"pointer":"","keyword":"type","message":"must be of type object"}]`,
+		},
+		{
+			name:        "malformed json",
+			method:      http.MethodPost,
+			target:      "/users",
+			contentType: keratin.MIMEApplicationJSON,
+			body:        `{"name":`,
+			wantCode:    http.StatusBadRequest,
+		},
+		{
+			name:        "not json",
+			method:      http.MethodPost,
+			target:      "/users",
+			contentType: keratin.MIMEApplicationForm,
+			body:        `name=Jane`,
+			wantCode:    http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "route schema",
+			method:      http.MethodPatch,
+			target:      "/users/1",
+			contentType: "application/merge-patch+json",
+			body:        `{}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantDetails: `[{"pointer":"","keyword":"minProperties","message":"must have at least 1 properties"}]`,
+		},
+		{
+			name:        "broken route schema",
+			method:      http.MethodPost,
+			target:      "/broken",
+			contentType: keratin.MIMEApplicationJSON,
+			body:        `{}`,
+			wantCode:    http.StatusInternalServerError,
+		},
+		{
+			name:        "route without schema",
+			method:      http.MethodPost,
+			target:      "/free",
+			contentType: keratin.MIMETextPlain,
+			body:        `anything`,
+			wantCode:    http.StatusOK,
+			wantBody:    `anything`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set(keratin.HeaderContentType, tt.contentType)
+			}
+			req.Header.Set(keratin.HeaderAccept, keratin.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+			}
+			if tt.wantDetails != "" {
+				assert.JSONEq(t, `{
+					"code": 422,
+					"message": "Request body does not match the schema.",
+					"error_code": "schema_violation",
+					"details": `+tt.wantDetails+`
+				}`, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestJSONSchema_Panics(t *testing.T) {
+	assert.PanicsWithValue(t, "middleware: json schema: schema fs is required", func() {
+		JSONSchema(nil, nil)
+	})
+	assert.Panics(t, func() {
+		JSONSchema(schemaTestFS, map[string]string{"POST /broken": "broken.json"})
+	})
+	assert.Panics(t, func() {
+		JSONSchema(schemaTestFS, map[string]string{"POST /missing": "missing.json"})
+	})
+	require.NotPanics(t, func() {
+		JSONSchema(schemaTestFS, map[string]string{"/users": "create-user.json"})
+	})
+}
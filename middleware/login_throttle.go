@@ -0,0 +1,537 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gowool/keratin"
+)
+
+// ErrLoginThrottled is returned for the login attempts of a locked out identity or client,
+// wrapped in a 429 Too Many Requests error with the Retry-After delay.
+var ErrLoginThrottled = keratin.NewHTTPError(http.StatusTooManyRequests, "Too many failed login attempts.")
+
+// LoginEventType is the type of a [LoginEvent].
+type LoginEventType string
+
+const (
+	// LoginFailed is a login attempt recognized as failed by LoginThrottleConfig.IsFailure.
+	LoginFailed LoginEventType = "failed"
+	// LoginSucceeded is a successful (2xx) login attempt, it resets the identity counters.
+	LoginSucceeded LoginEventType = "succeeded"
+	// LoginLocked is a login attempt rejected because of the lockout.
+	LoginLocked LoginEventType = "locked"
+	// LoginChallenged is a login attempt rejected because of a missing or invalid challenge token.
+	LoginChallenged LoginEventType = "challenged"
+)
+
+// LoginEvent describes a login attempt for the audit log, see LoginThrottleConfig.OnEvent.
+type LoginEvent struct {
+	Type     LoginEventType
+	Identity string
+	IP       string
+
+	// Failures is the number of the recent failed attempts of the identity from the IP,
+	// or of the IP for the attempts without an identity.
+	Failures int
+
+	// RetryAfter is the lockout delay, zero if the attempts are not locked out.
+	RetryAfter time.Duration
+}
+
+// LoginThrottleStorage stores the failed login counters.
+//
+// The counters are read, updated and written back under a lock per key of the middleware instance,
+// the concurrent attempts on the other instances sharing the storage may be missed.
+type LoginThrottleStorage interface {
+	// Get gets the value for the given key. `nil, nil` is returned when the key does not exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set stores the value for the given key.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes the given key.
+	Delete(ctx context.Context, key string) error
+}
+
+type LoginThrottleConfig struct {
+	// Storage stores the failed login counters.
+	// Optional. Default value NewLoginThrottleMemoryStorage().
+	Storage LoginThrottleStorage `json:"-" yaml:"-"`
+
+	// IdentityLookup is a string in the form of "<source>:<name>" or "<source>:<name>,<source>:<name>" that is used
+	// to extract the login identity (account name) from the request, see [CreateExtractors].
+	// The identity is trimmed and lowercased. Without an identity only the IP counter applies.
+	// Optional. Default value "form:username".
+	IdentityLookup string `env:"IDENTITY_LOOKUP" json:"identityLookup,omitempty" yaml:"identityLookup,omitempty"`
+
+	// MaxFailures is the number of the failed attempts of an identity from an IP before the lockout.
+	// Optional. Default value 5.
+	MaxFailures int `env:"MAX_FAILURES" json:"maxFailures,omitempty" yaml:"maxFailures,omitempty"`
+
+	// MaxIdentityFailures is the number of the failed attempts of an identity from any IP before the lockout,
+	// against the distributed attacks of an account. Negative value disables the identity counter.
+	// Optional. Default value 20.
+	MaxIdentityFailures int `env:"MAX_IDENTITY_FAILURES" json:"maxIdentityFailures,omitempty" yaml:"maxIdentityFailures,omitempty"`
+
+	// MaxIPFailures is the number of the failed attempts from an IP for any identity before the lockout,
+	// against the password spraying. Negative value disables the IP counter.
+	// Optional. Default value 100.
+	MaxIPFailures int `env:"MAX_IP_FAILURES" json:"maxIPFailures,omitempty" yaml:"maxIPFailures,omitempty"`
+
+	// BaseDelay is the first lockout delay, doubled by every further failed attempt up to MaxDelay.
+	// Set BaseDelay equal to MaxDelay for a fixed lockout.
+	// Optional. Default value 1s.
+	BaseDelay time.Duration `env:"BASE_DELAY" json:"baseDelay,omitempty,format:units" yaml:"baseDelay,omitempty"`
+
+	// MaxDelay is the maximum lockout delay.
+	// Optional. Default value 15m, or BaseDelay when it is longer.
+	MaxDelay time.Duration `env:"MAX_DELAY" json:"maxDelay,omitempty,format:units" yaml:"maxDelay,omitempty"`
+
+	// Window is how long the failed attempts are counted after the last one.
+	// Optional. Default value 15m.
+	Window time.Duration `env:"WINDOW" json:"window,omitempty,format:units" yaml:"window,omitempty"`
+
+	// IsFailure reports whether the login attempt failed from the response status code.
+	// Optional. Default value reports 401 Unauthorized and 403 Forbidden.
+	IsFailure func(r *http.Request, statusCode int) bool `json:"-" yaml:"-"`
+
+	// Challenge verifies the challenge tokens (e.g. a captcha) required after ChallengeAfter failed attempts
+	// of an identity from an IP, before the lockout. Without a Challenge the attempts are not challenged.
+	// Optional.
+	Challenge ChallengeVerifier `json:"-" yaml:"-"`
+
+	// ChallengeAfter is the number of the failed attempts before the challenge is required.
+	// Optional. Default value 3.
+	ChallengeAfter int `env:"CHALLENGE_AFTER" json:"challengeAfter,omitempty" yaml:"challengeAfter,omitempty"`
+
+	// ChallengeTokenLookup is the lookup of the challenge token, see [CreateExtractors].
+	// Optional. Default value "header:X-Challenge-Token".
+	ChallengeTokenLookup string `env:"CHALLENGE_TOKEN_LOOKUP" json:"challengeTokenLookup,omitempty" yaml:"challengeTokenLookup,omitempty"`
+
+	// OnEvent is called for every throttled login attempt, e.g. to write the audit log
+	// or to escalate the protection.
+	// Optional.
+	OnEvent func(r *http.Request, event LoginEvent) `json:"-" yaml:"-"`
+
+	// KeyPrefix is prepended to the storage keys.
+	// Optional. Default value "login:".
+	KeyPrefix string `env:"KEY_PREFIX" json:"keyPrefix,omitempty" yaml:"keyPrefix,omitempty"`
+}
+
+func (c *LoginThrottleConfig) SetDefaults() {
+	if c.Storage == nil {
+		c.Storage = NewLoginThrottleMemoryStorage()
+	}
+	if c.IdentityLookup == "" {
+		c.IdentityLookup = "form:username"
+	}
+	if c.MaxFailures <= 0 {
+		c.MaxFailures = 5
+	}
+	if c.MaxIdentityFailures == 0 {
+		c.MaxIdentityFailures = 20
+	}
+	if c.MaxIPFailures == 0 {
+		c.MaxIPFailures = 100
+	}
+	if c.BaseDelay <= 0 {
+		c.BaseDelay = time.Second
+	}
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = max(15*time.Minute, c.BaseDelay)
+	}
+	if c.Window <= 0 {
+		c.Window = 15 * time.Minute
+	}
+	if c.IsFailure == nil {
+		c.IsFailure = func(_ *http.Request, statusCode int) bool {
+			return statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden
+		}
+	}
+	if c.ChallengeAfter <= 0 {
+		c.ChallengeAfter = 3
+	}
+	if c.ChallengeTokenLookup == "" {
+		c.ChallengeTokenLookup = "header:" + HeaderChallengeToken
+	}
+	if c.KeyPrefix == "" {
+		c.KeyPrefix = "login:"
+	}
+}
+
+// loginCounter is the stored state of a failed login counter.
+type loginCounter struct {
+	Failures int       `json:"failures"`
+	Last     time.Time `json:"last"`
+}
+
+// loginKey is a failed login counter of a login attempt with its lockout threshold.
+type loginKey struct {
+	key     string
+	max     int
+	counter loginCounter
+	reset   bool
+}
+
+// LoginThrottle returns a middleware protecting the login endpoints against the brute-force attacks.
+//
+// It counts the failed attempts of the identity from the client IP, of the identity and of the IP,
+// the attempts over a counter threshold are rejected with 429 Too Many Requests for an exponentially
+// growing delay. Unlike the rate limiters, only the failed attempts are counted and the successful
+// login resets the identity counters.
+//
+// With a LoginThrottleConfig.Challenge the attempts after a few failures must carry a challenge token,
+// the missing tokens are rejected with 401 Unauthorized and the invalid ones with 403 Forbidden,
+// the error data is the [ChallengeInfo] to solve.
+func LoginThrottle(cfg LoginThrottleConfig, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	cfg.SetDefaults()
+
+	identityExtractors, err := CreateExtractors(cfg.IdentityLookup, 1)
+	if err != nil {
+		panic(fmt.Sprintf("middleware: login throttle: %v", err))
+	}
+	tokenExtractors, err := CreateExtractors(cfg.ChallengeTokenLookup, 1)
+	if err != nil {
+		panic(fmt.Sprintf("middleware: login throttle: %v", err))
+	}
+
+	skip := ChainSkipper(skippers...)
+
+	// serializes the check and the read-modify-write of the counters of this instance per key
+	var locks loginLocks
+
+	emit := func(r *http.Request, event LoginEvent) {
+		if cfg.OnEvent != nil {
+			cfg.OnEvent(r, event)
+		}
+	}
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if skip(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			ctx := r.Context()
+			event := LoginEvent{
+				Identity: extractLoginIdentity(r, identityExtractors),
+				IP:       keratin.ClientIP(r),
+			}
+
+			keys := cfg.keys(event.Identity, event.IP)
+			if len(keys) == 0 {
+				return next.ServeHTTP(w, r)
+			}
+			now := time.Now()
+
+			// the attempt is reserved as a failure before the handler runs, so that
+			// the concurrent attempts are counted against the threshold too
+			unlock := locks.lock(keys)
+			err := cfg.load(ctx, keys)
+			if err == nil {
+				for _, k := range keys {
+					event.RetryAfter = max(event.RetryAfter, cfg.lockout(k, now))
+				}
+				event.Failures = keys[0].counter.Failures
+
+				switch {
+				case event.RetryAfter > 0:
+					event.Type = LoginLocked
+				case cfg.Challenge != nil && event.Failures >= cfg.ChallengeAfter:
+					if err = verifyLoginChallenge(r, cfg.Challenge, tokenExtractors); err != nil {
+						event.Type = LoginChallenged
+					}
+				}
+				if event.Type == "" && err == nil {
+					err = cfg.reserve(ctx, keys, now)
+				}
+			}
+			unlock()
+
+			switch {
+			case event.Type == LoginLocked:
+				emit(r, event)
+
+				return keratin.NewTooManyRequestsError(event.RetryAfter).Wrap(ErrLoginThrottled)
+			case event.Type == LoginChallenged:
+				emit(r, event)
+
+				return err
+			case err != nil:
+				return err
+			}
+
+			err = next.ServeHTTP(w, r)
+
+			var code int
+			if err == nil {
+				code = keratin.ResponseStatusCode(w)
+			} else {
+				code = keratin.HTTPErrorStatusCode(err)
+			}
+
+			switch {
+			case cfg.IsFailure(r, code):
+				// the reserved failure is kept
+				event.Type = LoginFailed
+				event.Failures = keys[0].counter.Failures
+				event.RetryAfter = 0
+				for _, k := range keys {
+					event.RetryAfter = max(event.RetryAfter, cfg.lockout(k, now))
+				}
+				emit(r, event)
+			case code >= 200 && code < 300:
+				unlock = locks.lock(keys)
+				recordErr := cfg.succeed(ctx, keys)
+				unlock()
+
+				event.Type = LoginSucceeded
+				emit(r, event)
+
+				if recordErr != nil {
+					keratin.LoggerFromContext(ctx).LogAttrs(ctx, slog.LevelWarn, "login throttle: failed to reset the failed attempts",
+						slog.Any("error", recordErr))
+				}
+			default:
+				unlock = locks.lock(keys)
+				recordErr := cfg.release(ctx, keys, func(*loginKey) bool { return true })
+				unlock()
+
+				if recordErr != nil {
+					keratin.LoggerFromContext(ctx).LogAttrs(ctx, slog.LevelWarn, "login throttle: failed to release the reserved attempt",
+						slog.Any("error", recordErr))
+				}
+			}
+
+			return err
+		})
+	}
+}
+
+// keys returns the counters of a login attempt, the identity and IP one first.
+// Without an identity only the IP counter applies.
+func (c *LoginThrottleConfig) keys(identity, ip string) []*loginKey {
+	var keys []*loginKey
+	if identity != "" {
+		keys = append(keys, &loginKey{key: c.KeyPrefix + "pair:" + identity + "|" + ip, max: c.MaxFailures, reset: true})
+		if c.MaxIdentityFailures > 0 {
+			keys = append(keys, &loginKey{key: c.KeyPrefix + "identity:" + identity, max: c.MaxIdentityFailures, reset: true})
+		}
+	}
+	if c.MaxIPFailures > 0 {
+		keys = append(keys, &loginKey{key: c.KeyPrefix + "ip:" + ip, max: c.MaxIPFailures})
+	}
+	return keys
+}
+
+func (c *LoginThrottleConfig) load(ctx context.Context, keys []*loginKey) error {
+	for _, k := range keys {
+		raw, err := c.Storage.Get(ctx, k.key)
+		if err != nil {
+			return fmt.Errorf("login throttle: failed to get counter: %w", err)
+		}
+
+		k.counter = loginCounter{}
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &k.counter); err != nil {
+				return fmt.Errorf("login throttle: failed to unmarshal counter: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// lockout returns the remaining lockout delay of the counter at now.
+func (c *LoginThrottleConfig) lockout(k *loginKey, now time.Time) time.Duration {
+	if k.counter.Failures < k.max {
+		return 0
+	}
+	return max(k.counter.Last.Add(c.delay(k.counter.Failures-k.max)).Sub(now), 0)
+}
+
+// delay returns the lockout delay after the excess failed attempts over the threshold.
+func (c *LoginThrottleConfig) delay(excess int) time.Duration {
+	delay := c.BaseDelay
+	for range excess {
+		if delay >= c.MaxDelay {
+			break
+		}
+		delay *= 2
+	}
+	return min(delay, c.MaxDelay)
+}
+
+// reserve counts the attempt as a failure in the loaded counters until its outcome is known.
+func (c *LoginThrottleConfig) reserve(ctx context.Context, keys []*loginKey, now time.Time) error {
+	for _, k := range keys {
+		k.counter.Failures++
+		k.counter.Last = now
+
+		if err := c.store(ctx, k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// release undoes the reserved failure of the counters matching the filter in the reloaded counters,
+// so the concurrent attempts are all kept.
+func (c *LoginThrottleConfig) release(ctx context.Context, keys []*loginKey, filter func(*loginKey) bool) error {
+	var errs []error
+	for _, k := range keys {
+		if !filter(k) {
+			continue
+		}
+
+		counter := *k
+		if err := c.load(ctx, []*loginKey{&counter}); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		counter.counter.Failures--
+		if counter.counter.Failures <= 0 {
+			if err := c.Storage.Delete(ctx, k.key); err != nil {
+				errs = append(errs, fmt.Errorf("login throttle: failed to delete counter: %w", err))
+			}
+			continue
+		}
+		if err := c.store(ctx, &counter); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *LoginThrottleConfig) store(ctx context.Context, k *loginKey) error {
+	raw, err := json.Marshal(k.counter)
+	if err != nil {
+		return fmt.Errorf("login throttle: failed to marshal counter: %w", err)
+	}
+
+	ttl := max(c.Window, c.delay(k.counter.Failures-k.max))
+	if err := c.Storage.Set(ctx, k.key, raw, ttl); err != nil {
+		return fmt.Errorf("login throttle: failed to store counter: %w", err)
+	}
+	return nil
+}
+
+// succeed resets the identity counters and releases the reserved failure of the IP counter,
+// which is kept against the password spraying.
+func (c *LoginThrottleConfig) succeed(ctx context.Context, keys []*loginKey) error {
+	var errs []error
+	for _, k := range keys {
+		if k.reset {
+			if err := c.Storage.Delete(ctx, k.key); err != nil {
+				errs = append(errs, fmt.Errorf("login throttle: failed to delete counter: %w", err))
+			}
+		}
+	}
+
+	errs = append(errs, c.release(ctx, keys, func(k *loginKey) bool { return !k.reset }))
+	return errors.Join(errs...)
+}
+
+// loginLocks serializes the read-modify-write of the counters per storage key,
+// so that the attempts of the unrelated identities and IPs do not wait for each other.
+type loginLocks struct {
+	mu    sync.Mutex
+	locks map[string]*loginLock
+}
+
+type loginLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks the counters of the keys in a stable order and returns the func unlocking them.
+func (l *loginLocks) lock(keys []*loginKey) func() {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k.key
+	}
+	slices.Sort(names)
+
+	held := make([]*loginLock, len(names))
+	for i, name := range names {
+		l.mu.Lock()
+		if l.locks == nil {
+			l.locks = make(map[string]*loginLock)
+		}
+		lock, ok := l.locks[name]
+		if !ok {
+			lock = &loginLock{}
+			l.locks[name] = lock
+		}
+		lock.refs++
+		l.mu.Unlock()
+
+		lock.Lock()
+		held[i] = lock
+	}
+
+	return func() {
+		for i, lock := range held {
+			lock.Unlock()
+
+			l.mu.Lock()
+			if lock.refs--; lock.refs == 0 {
+				delete(l.locks, names[i])
+			}
+			l.mu.Unlock()
+		}
+	}
+}
+
+func extractLoginIdentity(r *http.Request, extractors []ValuesExtractor) string {
+	for _, extractor := range extractors {
+		if values, _, err := extractor(r); err == nil && len(values) > 0 {
+			return strings.ToLower(strings.TrimSpace(values[0]))
+		}
+	}
+	return ""
+}
+
+func verifyLoginChallenge(r *http.Request, verifier ChallengeVerifier, extractors []ValuesExtractor) error {
+	var token string
+	for _, extractor := range extractors {
+		if values, _, err := extractor(r); err == nil && len(values) > 0 && values[0] != "" {
+			token = values[0]
+			break
+		}
+	}
+
+	if token == "" {
+		return keratin.NewHTTPError(http.StatusUnauthorized, "challenge required").
+			SetData(ChallengeInfo{Reason: "challenge required"})
+	}
+
+	if err := verifier.Verify(r, token); err != nil {
+		if keratin.ErrorStatusCode(err) != 0 {
+			return err
+		}
+		return keratin.NewHTTPError(http.StatusForbidden, "challenge failed").
+			SetData(ChallengeInfo{Reason: "challenge failed"}).Wrap(err)
+	}
+	return nil
+}
+
+var _ LoginThrottleStorage = (*LoginThrottleMemoryStorage)(nil)
+
+// LoginThrottleMemoryStorage is an in-memory [LoginThrottleStorage] for single instance deployments.
+type LoginThrottleMemoryStorage struct {
+	memoryStorage
+}
+
+func NewLoginThrottleMemoryStorage() *LoginThrottleMemoryStorage {
+	return &LoginThrottleMemoryStorage{memoryStorage: newMemoryStorage()}
+}
@@ -0,0 +1,254 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+func newLoginThrottleHandler(t *testing.T, cfg LoginThrottleConfig) (http.Handler, func() []LoginEvent) {
+	t.Helper()
+
+	var (
+		mu     sync.Mutex
+		events []LoginEvent
+	)
+	cfg.OnEvent = func(_ *http.Request, event LoginEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+
+	router := keratin.NewRouter()
+	router.POST("/login", func(w http.ResponseWriter, r *http.Request) error {
+		if r.FormValue("password") != "secret" {
+			return keratin.ErrUnauthorized
+		}
+		return keratin.TextPlain(w, http.StatusOK, "welcome")
+	}).UseFunc(LoginThrottle(cfg))
+
+	return router.Build(), func() []LoginEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		return events
+	}
+}
+
+func login(h http.Handler, ip, username, password string, header ...string) *httptest.ResponseRecorder {
+	form := url.Values{"username": {username}, "password": {password}}
+	r := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	r.Header.Set(keratin.HeaderContentType, keratin.MIMEApplicationForm)
+	r.RemoteAddr = ip + ":1234"
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestLoginThrottle_Lockout(t *testing.T) {
+	h, events := newLoginThrottleHandler(t, LoginThrottleConfig{MaxFailures: 2, BaseDelay: time.Hour})
+
+	assert.Equal(t, http.StatusUnauthorized, login(h, "10.0.0.1", "Alice", "wrong").Code)
+	assert.Equal(t, http.StatusUnauthorized, login(h, "10.0.0.1", "alice ", "wrong").Code)
+
+	w := login(h, "10.0.0.1", "alice", "secret")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "3600", w.Header().Get(keratin.HeaderRetryAfter))
+
+	// the other identities and clients are not locked out
+	assert.Equal(t, http.StatusOK, login(h, "10.0.0.1", "bob", "secret").Code)
+	assert.Equal(t, http.StatusOK, login(h, "10.0.0.2", "alice", "secret").Code)
+
+	got := events()
+	require.Len(t, got, 5)
+	assert.Equal(t, LoginEvent{Type: LoginFailed, Identity: "alice", IP: "10.0.0.1", Failures: 1}, got[0])
+	assert.Equal(t, LoginFailed, got[1].Type)
+	assert.Equal(t, 2, got[1].Failures)
+	assert.InDelta(t, time.Hour, got[1].RetryAfter, float64(time.Second))
+	assert.Equal(t, LoginLocked, got[2].Type)
+	assert.Equal(t, LoginSucceeded, got[3].Type)
+	assert.Equal(t, "bob", got[3].Identity)
+}
+
+func TestLoginThrottle_Backoff(t *testing.T) {
+	h, _ := newLoginThrottleHandler(t, LoginThrottleConfig{MaxFailures: 1, BaseDelay: 20 * time.Millisecond, MaxDelay: 50 * time.Millisecond})
+
+	assert.Equal(t, http.StatusUnauthorized, login(h, "10.0.0.1", "alice", "wrong").Code)
+	assert.Equal(t, http.StatusTooManyRequests, login(h, "10.0.0.1", "alice", "wrong").Code)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, http.StatusUnauthorized, login(h, "10.0.0.1", "alice", "wrong").Code)
+
+	// the second lockout is doubled
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, http.StatusTooManyRequests, login(h, "10.0.0.1", "alice", "secret").Code)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, login(h, "10.0.0.1", "alice", "secret").Code)
+
+	// the success resets the counters
+	assert.Equal(t, http.StatusUnauthorized, login(h, "10.0.0.1", "alice", "wrong").Code)
+}
+
+func TestLoginThrottle_Concurrent(t *testing.T) {
+	var calls atomic.Int32
+
+	router := keratin.NewRouter()
+	router.POST("/login", func(http.ResponseWriter, *http.Request) error {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return keratin.ErrUnauthorized
+	}).UseFunc(LoginThrottle(LoginThrottleConfig{MaxFailures: 5, BaseDelay: time.Hour}))
+	h := router.Build()
+
+	var wg sync.WaitGroup
+	for range 200 {
+		wg.Go(func() {
+			code := login(h, "10.0.0.1", "alice", "wrong").Code
+			assert.Contains(t, []int{http.StatusUnauthorized, http.StatusTooManyRequests}, code)
+		})
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(5), calls.Load())
+}
+
+func TestLoginThrottle_ReleasesUnknownOutcome(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusInternalServerError)
+
+	router := keratin.NewRouter()
+	router.POST("/login", func(http.ResponseWriter, *http.Request) error {
+		return keratin.NewHTTPError(int(status.Load()), "")
+	}).UseFunc(LoginThrottle(LoginThrottleConfig{MaxFailures: 1, BaseDelay: time.Hour}))
+	h := router.Build()
+
+	// the attempts neither failed nor succeeded are not counted
+	assert.Equal(t, http.StatusInternalServerError, login(h, "10.0.0.1", "alice", "wrong").Code)
+	assert.Equal(t, http.StatusInternalServerError, login(h, "10.0.0.1", "alice", "wrong").Code)
+
+	status.Store(http.StatusUnauthorized)
+	assert.Equal(t, http.StatusUnauthorized, login(h, "10.0.0.1", "alice", "wrong").Code)
+	assert.Equal(t, http.StatusTooManyRequests, login(h, "10.0.0.1", "alice", "wrong").Code)
+}
+
+func TestLoginThrottle_IdentityAndIPCounters(t *testing.T) {
+	h, _ := newLoginThrottleHandler(t, LoginThrottleConfig{MaxFailures: 10, MaxIdentityFailures: 2, MaxIPFailures: 3, BaseDelay: time.Hour})
+
+	// distributed attack of an account
+	assert.Equal(t, http.StatusUnauthorized, login(h, "10.0.0.1", "alice", "wrong").Code)
+	assert.Equal(t, http.StatusUnauthorized, login(h, "10.0.0.2", "alice", "wrong").Code)
+	assert.Equal(t, http.StatusTooManyRequests, login(h, "10.0.0.3", "alice", "secret").Code)
+
+	// password spraying from a client
+	assert.Equal(t, http.StatusUnauthorized, login(h, "10.0.0.9", "bob", "wrong").Code)
+	assert.Equal(t, http.StatusUnauthorized, login(h, "10.0.0.9", "carol", "wrong").Code)
+	assert.Equal(t, http.StatusUnauthorized, login(h, "10.0.0.9", "dave", "wrong").Code)
+	assert.Equal(t, http.StatusTooManyRequests, login(h, "10.0.0.9", "erin", "secret").Code)
+	assert.Equal(t, http.StatusOK, login(h, "10.0.0.8", "erin", "secret").Code)
+}
+
+func TestLoginThrottle_WithoutIdentity(t *testing.T) {
+	h, events := newLoginThrottleHandler(t, LoginThrottleConfig{MaxFailures: 2, MaxIPFailures: 4, BaseDelay: time.Hour})
+
+	// only the IP counter applies to the attempts without an identity
+	for range 4 {
+		assert.Equal(t, http.StatusUnauthorized, login(h, "10.0.0.1", "", "wrong").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, login(h, "10.0.0.1", " ", "wrong").Code)
+	assert.Equal(t, http.StatusUnauthorized, login(h, "10.0.0.2", "", "wrong").Code)
+
+	got := events()
+	require.Len(t, got, 6)
+	assert.Equal(t, LoginEvent{Type: LoginFailed, IP: "10.0.0.1", Failures: 3}, got[2])
+	assert.Equal(t, LoginLocked, got[4].Type)
+	assert.Equal(t, 4, got[4].Failures)
+}
+
+func TestLoginThrottle_OutsideRouter(t *testing.T) {
+	var event LoginEvent
+	h := LoginThrottle(LoginThrottleConfig{OnEvent: func(_ *http.Request, e LoginEvent) { event = e }})(
+		keratin.HandlerFunc(func(http.ResponseWriter, *http.Request) error {
+			return keratin.ErrUnauthorized
+		}),
+	)
+
+	r := httptest.NewRequest(http.MethodPost, "/login", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+
+	err := h.ServeHTTP(httptest.NewRecorder(), r)
+	assert.ErrorIs(t, err, keratin.ErrUnauthorized)
+	assert.Equal(t, "10.0.0.1", event.IP)
+}
+
+func TestLoginThrottle_Challenge(t *testing.T) {
+	verifier := ChallengeVerifierFunc(func(_ *http.Request, token string) error {
+		if token == "valid" {
+			return nil
+		}
+		return ErrChallengeFailed
+	})
+
+	h, events := newLoginThrottleHandler(t, LoginThrottleConfig{Challenge: verifier, ChallengeAfter: 1})
+
+	assert.Equal(t, http.StatusUnauthorized, login(h, "10.0.0.1", "alice", "wrong").Code)
+
+	w := login(h, "10.0.0.1", "alice", "secret")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "challenge required")
+
+	w = login(h, "10.0.0.1", "alice", "secret", HeaderChallengeToken, "invalid")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = login(h, "10.0.0.1", "alice", "secret", HeaderChallengeToken, "valid")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// the failures were reset
+	assert.Equal(t, http.StatusOK, login(h, "10.0.0.1", "alice", "secret").Code)
+
+	var types []LoginEventType
+	for _, event := range events() {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []LoginEventType{LoginFailed, LoginChallenged, LoginChallenged, LoginSucceeded, LoginSucceeded}, types)
+}
+
+func TestLoginThrottle_Panics(t *testing.T) {
+	assert.PanicsWithValue(t, "middleware: login throttle: extractor source for lookup could not be split into needed parts: invalid", func() {
+		LoginThrottle(LoginThrottleConfig{IdentityLookup: "invalid"})
+	})
+}
+
+func TestLoginThrottleMemoryStorage(t *testing.T) {
+	s := NewLoginThrottleMemoryStorage()
+	ctx := t.Context()
+
+	require.NoError(t, s.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, s.Set(ctx, "b", []byte("2"), -time.Second))
+
+	v, err := s.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), v)
+
+	v, err = s.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.Nil(t, v)
+
+	require.NoError(t, s.Delete(ctx, "a"))
+	v, err = s.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+type memoryItem struct {
+	value []byte
+	exp   time.Time
+}
+
+// memoryStorage is the in-memory TTL map of the memory storages of the middlewares.
+type memoryStorage struct {
+	mu        sync.Mutex
+	data      map[string]memoryItem
+	lastSweep time.Time
+}
+
+func newMemoryStorage() memoryStorage {
+	return memoryStorage{data: make(map[string]memoryItem)}
+}
+
+func (s *memoryStorage) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.data[key]
+	if !ok || time.Now().After(item.exp) {
+		return nil, nil
+	}
+	return slices.Clone(item.value), nil
+}
+
+func (s *memoryStorage) Reserve(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	if item, ok := s.data[key]; ok && !now.After(item.exp) {
+		return false, nil
+	}
+
+	s.data[key] = memoryItem{value: slices.Clone(value), exp: now.Add(ttl)}
+	return true, nil
+}
+
+func (s *memoryStorage) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	s.data[key] = memoryItem{value: slices.Clone(value), exp: now.Add(ttl)}
+	return nil
+}
+
+func (s *memoryStorage) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data, key)
+	return nil
+}
+
+// sweep removes expired items at most once per minute.
+func (s *memoryStorage) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, item := range s.data {
+		if now.After(item.exp) {
+			delete(s.data, key)
+		}
+	}
+}
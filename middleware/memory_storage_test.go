@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStorage_Sweep(t *testing.T) {
+	s := newMemoryStorage()
+	ctx := t.Context()
+
+	require.NoError(t, s.Set(ctx, "expired", []byte("1"), -time.Second))
+	require.NoError(t, s.Set(ctx, "alive", []byte("2"), time.Hour))
+	assert.Len(t, s.data, 2)
+
+	// the sweeps run at most once per minute
+	s.lastSweep = time.Now().Add(-2 * time.Minute)
+
+	ok, err := s.Reserve(ctx, "new", []byte("3"), time.Hour)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Len(t, s.data, 2)
+	assert.NotContains(t, s.data, "expired")
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/textproto"
+	"slices"
+	"strings"
+
+	"github.com/gowool/keratin"
+)
+
+type propagatedKey struct{}
+
+// CtxPropagatedHeaders returns the inbound request headers captured by [PropagateHeaders], nil without them.
+// The returned header must not be modified.
+func CtxPropagatedHeaders(ctx context.Context) http.Header {
+	h, _ := ctx.Value(propagatedKey{}).(http.Header)
+	return h
+}
+
+// InjectPropagatedHeaders copies the headers captured by [PropagateHeaders] from ctx to the header
+// of an outbound request, the headers already set are kept.
+func InjectPropagatedHeaders(ctx context.Context, header http.Header) {
+	for name, values := range CtxPropagatedHeaders(ctx) {
+		if _, ok := header[name]; !ok {
+			header[name] = slices.Clone(values)
+		}
+	}
+}
+
+// PropagatingTransport returns a [http.RoundTripper] injecting the headers captured by [PropagateHeaders]
+// and the remaining deadline budget of the request context into the outbound requests,
+// see [InjectPropagatedHeaders] and [InjectBudget]. A nil next is [http.DefaultTransport].
+func PropagatingTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		ctx := r.Context()
+		if CtxPropagatedHeaders(ctx) == nil && ctx.Value(budgetKey{}) == nil {
+			return next.RoundTrip(r)
+		}
+
+		// a RoundTripper must not modify the request
+		r = r.Clone(ctx)
+		InjectPropagatedHeaders(ctx, r.Header)
+		InjectBudget(ctx, r.Header)
+		return next.RoundTrip(r)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// PropagateHeaders returns a middleware capturing the named inbound request headers into the request context,
+// so the outbound calls to the other services carry them, see [InjectPropagatedHeaders] and [PropagatingTransport].
+// A name ending with "*" matches the headers with its prefix, e.g. "X-B3-*".
+//
+//	router.Use(middleware.PropagateHeaders(keratin.HeaderXRequestID, "X-B3-*", keratin.HeaderTraceparent))
+//
+// It panics if no name is given.
+func PropagateHeaders(names ...string) func(keratin.Handler) keratin.Handler {
+	if len(names) == 0 {
+		panic("middleware: propagate headers: at least one header name is required")
+	}
+
+	var (
+		exact    []string
+		prefixes []string
+	)
+	for _, name := range names {
+		if prefix, ok := strings.CutSuffix(name, "*"); ok {
+			prefixes = append(prefixes, textproto.CanonicalMIMEHeaderKey(prefix))
+		} else {
+			exact = append(exact, textproto.CanonicalMIMEHeaderKey(name))
+		}
+	}
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			var captured http.Header
+
+			capture := func(name string, values []string) {
+				if captured == nil {
+					captured = make(http.Header)
+				}
+				captured[name] = slices.Clone(values)
+			}
+
+			for _, name := range exact {
+				if values := r.Header[name]; len(values) > 0 {
+					capture(name, values)
+				}
+			}
+			if len(prefixes) > 0 {
+				for name, values := range r.Header {
+					for _, prefix := range prefixes {
+						if len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) && len(values) > 0 {
+							capture(name, values)
+							break
+						}
+					}
+				}
+			}
+
+			if captured == nil {
+				return next.ServeHTTP(w, r)
+			}
+
+			ctx := context.WithValue(r.Context(), propagatedKey{}, captured)
+
+			return next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+func TestPropagateHeaders(t *testing.T) {
+	var received http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: PropagatingTransport(nil)}
+
+	var captured http.Header
+	router := keratin.NewRouter()
+	router.UseFunc(PropagateHeaders(keratin.HeaderXRequestID, "x-b3-*", keratin.HeaderTraceparent))
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		captured = CtxPropagatedHeaders(r.Context())
+
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, upstream.URL, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(keratin.HeaderTraceparent, "outbound")
+
+		res, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		return res.Body.Close()
+	})
+	handler := router.Build()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(keratin.HeaderXRequestID, "req-1")
+	req.Header.Set("X-B3-TraceId", "trace")
+	req.Header.Add("X-B3-Flags", "1")
+	req.Header.Set(keratin.HeaderTraceparent, "inbound")
+	req.Header.Set("X-Other", "other")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, http.Header{
+		keratin.HeaderXRequestID:  {"req-1"},
+		"X-B3-Traceid":            {"trace"},
+		"X-B3-Flags":              {"1"},
+		keratin.HeaderTraceparent: {"inbound"},
+	}, captured)
+
+	assert.Equal(t, "req-1", received.Get(keratin.HeaderXRequestID))
+	assert.Equal(t, "trace", received.Get("X-B3-TraceId"))
+	assert.Equal(t, "1", received.Get("X-B3-Flags"))
+	assert.Equal(t, "outbound", received.Get(keratin.HeaderTraceparent))
+	assert.Empty(t, received.Get("X-Other"))
+
+	// without the headers nothing is captured
+	captured = nil
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Nil(t, captured)
+}
+
+func TestInjectPropagatedHeaders(t *testing.T) {
+	h := http.Header{"X-Set": {"kept"}}
+	InjectPropagatedHeaders(t.Context(), h)
+	assert.Equal(t, http.Header{"X-Set": {"kept"}}, h)
+}
+
+func TestPropagateHeaders_Panics(t *testing.T) {
+	assert.PanicsWithValue(t, "middleware: propagate headers: at least one header name is required", func() {
+		PropagateHeaders()
+	})
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gowool/keratin"
+)
+
+// MetaRoles is the route metadata key of the roles required by [RequireRoles], e.g.
+//
+//	router.DELETE("/users/{id}", deleteUser).SetMeta(middleware.MetaRoles, []string{"admin"})
+const MetaRoles = "roles"
+
+// RoleHolder is implemented by the principals having roles, e.g. [Identity].
+type RoleHolder interface {
+	HasRole(role string) bool
+}
+
+// RequireRoles allows only the principals (see [Auth]) having all the roles
+// and the roles of the matched route metadata (see [MetaRoles]).
+//
+// Without roles the middleware is driven by the route metadata only, e.g. a group guard
+// where the routes declare their roles.
+//
+// Anonymous requests fail with 401 Unauthorized, principals missing a role
+// or not implementing [RoleHolder] fail with 403 Forbidden. The middleware fails closed
+// with 403 Forbidden when there is no matched route (e.g. registered with Pre),
+// its [MetaRoles] metadata is not a []string or a []any of strings (as decoded from a configuration),
+// or no role is required at all.
+func RequireRoles(roles ...string) func(keratin.Handler) keratin.Handler {
+	return RequirePermission(func(principal Principal, route *keratin.Route) error {
+		if route == nil {
+			return keratin.ErrForbidden.Wrap(errors.New("no matched route"))
+		}
+
+		routeRoles, err := metaRoles(route)
+		if err != nil {
+			return keratin.ErrForbidden.Wrap(err)
+		}
+
+		required := append(roles[:len(roles):len(roles)], routeRoles...)
+		if len(required) == 0 {
+			return keratin.ErrForbidden.Wrap(fmt.Errorf("route %s %s: no roles required", route.Method, route.Path))
+		}
+
+		holder, ok := principal.(RoleHolder)
+		if !ok {
+			return keratin.ErrForbidden.Wrap(fmt.Errorf("principal %q has no roles", principal.Subject()))
+		}
+
+		for _, role := range required {
+			if !holder.HasRole(role) {
+				return keratin.ErrForbidden.Wrap(fmt.Errorf("principal %q misses role %q", principal.Subject(), role))
+			}
+		}
+		return nil
+	})
+}
+
+// metaRoles returns the [MetaRoles] metadata of the route.
+func metaRoles(route *keratin.Route) ([]string, error) {
+	switch meta := route.Meta(MetaRoles).(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return meta, nil
+	case []any:
+		roles := make([]string, 0, len(meta))
+		for _, v := range meta {
+			role, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("route %s %s: invalid %q metadata role %T", route.Method, route.Path, MetaRoles, v)
+			}
+			roles = append(roles, role)
+		}
+		return roles, nil
+	default:
+		return nil, fmt.Errorf("route %s %s: invalid %q metadata %T", route.Method, route.Path, MetaRoles, meta)
+	}
+}
+
+// RequirePermission authorizes the principal (see [Auth]) with check, which gets the matched route
+// to read its metadata (nil if the middleware runs before routing, e.g. registered with Pre).
+//
+// Anonymous requests fail with 401 Unauthorized. Errors of check without HTTP status code
+// are reported as 403 Forbidden.
+func RequirePermission(check func(principal Principal, route *keratin.Route) error, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	if check == nil {
+		panic("middleware: require permission: check function is required")
+	}
+
+	skip := ChainSkipper(skippers...)
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if skip(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			principal := CtxPrincipal(r.Context())
+			if principal == nil {
+				return keratin.ErrUnauthorized
+			}
+
+			if err := check(principal, keratin.FromContext(r.Context()).Route()); err != nil {
+				if keratin.ErrorStatusCode(err) < http.StatusBadRequest {
+					return keratin.ErrForbidden.Wrap(err)
+				}
+				return err
+			}
+
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gowool/keratin"
+)
+
+func TestRequireRoles(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) error {
+		return keratin.TextPlain(w, http.StatusOK, "ok")
+	}
+
+	principal := &Identity{ID: "john", Roles: []string{"editor"}}
+	authenticate := func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			switch r.Header.Get("X-User") {
+			case "john":
+				r = r.WithContext(WithPrincipal(r.Context(), principal))
+			case "device":
+				r = r.WithContext(WithPrincipal(r.Context(), &authTestUser{name: "device"}))
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+
+	router := keratin.NewRouter()
+	router.UseFunc(authenticate)
+
+	api := router.Group("/api")
+	api.UseFunc(RequireRoles("editor"))
+	api.GET("/posts", ok)
+	api.DELETE("/posts", ok).SetMeta(MetaRoles, []string{"admin"})
+	api.PUT("/posts", ok).SetMeta(MetaRoles, "admin")
+	api.PATCH("/posts", ok).SetMeta(MetaRoles, []any{"editor", 1})
+	api.POST("/posts", ok).SetMeta(MetaRoles, []any{"editor"})
+
+	guarded := router.Group("/guarded")
+	guarded.UseFunc(RequireRoles())
+	guarded.GET("/posts", ok).SetMeta(MetaRoles, []string{"editor"})
+	guarded.GET("/open", ok)
+
+	router.GET("/editor", ok).UseFunc(RequireRoles("editor"))
+
+	handler := router.Build()
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		user     string
+		wantCode int
+	}{
+		{name: "group role granted", method: http.MethodGet, target: "/api/posts", user: "john", wantCode: http.StatusOK},
+		{name: "malformed route roles", method: http.MethodPut, target: "/api/posts", user: "john", wantCode: http.StatusForbidden},
+		{name: "malformed decoded route roles", method: http.MethodPatch, target: "/api/posts", user: "john", wantCode: http.StatusForbidden},
+		{name: "decoded route roles", method: http.MethodPost, target: "/api/posts", user: "john", wantCode: http.StatusOK},
+		{name: "metadata only", method: http.MethodGet, target: "/guarded/posts", user: "john", wantCode: http.StatusOK},
+		{name: "metadata only without roles", method: http.MethodGet, target: "/guarded/open", user: "john", wantCode: http.StatusForbidden},
+		{name: "anonymous", method: http.MethodGet, target: "/api/posts", wantCode: http.StatusUnauthorized},
+		{name: "route role missing", method: http.MethodDelete, target: "/api/posts", user: "john", wantCode: http.StatusForbidden},
+		{name: "role granted", method: http.MethodGet, target: "/editor", user: "john", wantCode: http.StatusOK},
+		{name: "principal without roles", method: http.MethodGet, target: "/editor", user: "device", wantCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.user != "" {
+				req.Header.Set("X-User", tt.user)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+
+	principal.Roles = append(principal.Roles, "admin")
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/api/posts", nil)
+	req.Header.Set("X-User", "john")
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRequireRoles_FailsClosed(t *testing.T) {
+	// without a matched route, e.g. registered with Pre
+	h := RequireRoles("editor")(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(WithPrincipal(req.Context(), &Identity{ID: "john", Roles: []string{"editor"}}))
+	assert.Equal(t, http.StatusForbidden, keratin.HTTPErrorStatusCode(h.ServeHTTP(httptest.NewRecorder(), req)))
+}
+
+func TestRequirePermission(t *testing.T) {
+	h := RequirePermission(func(principal Principal, route *keratin.Route) error {
+		if principal.Subject() == "teapot" {
+			return keratin.ErrTeapot
+		}
+		return errors.New("denied")
+	})(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(WithPrincipal(req.Context(), &Identity{ID: "john"}))
+	assert.Equal(t, http.StatusForbidden, keratin.HTTPErrorStatusCode(h.ServeHTTP(httptest.NewRecorder(), req)))
+
+	req = req.WithContext(WithPrincipal(req.Context(), &Identity{ID: "teapot"}))
+	assert.Equal(t, http.StatusTeapot, keratin.HTTPErrorStatusCode(h.ServeHTTP(httptest.NewRecorder(), req)))
+
+	assert.Panics(t, func() { RequirePermission(nil) })
+}
@@ -2,7 +2,6 @@ package middleware
 
 import (
 	"errors"
-	"fmt"
 	"log/slog"
 	"net/http"
 	"runtime"
@@ -22,30 +21,24 @@ func (c *RecoverConfig) SetDefaults() {
 	}
 }
 
+// HTTPRecover returns a raw HTTP middleware which recovers from panics, logs them and responds with 500.
+// When logger is nil, the request-scoped logger of [keratin.LoggerFromContext] is used.
 func HTTPRecover(cfg RecoverConfig, logger *slog.Logger) func(next http.Handler) http.Handler {
-	if logger == nil {
-		logger = slog.Default()
+	if logger != nil {
+		logger = logger.WithGroup("recover")
 	}
 
-	logger = logger.WithGroup("recover")
-
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if rec := recover(); rec != nil {
-					recoverErr, ok := rec.(error)
-					if !ok {
-						recoverErr = fmt.Errorf("%v", rec)
-					} else if errors.Is(recoverErr, http.ErrAbortHandler) {
-						// don't recover ErrAbortHandler so the response to the client can be aborted
-						panic(recoverErr)
-					}
-
-					stack := make([]byte, cfg.StackSize)
-					length := runtime.Stack(stack, true)
+					panicErr := recoverPanic(rec, cfg.StackSize)
 
-					internal := fmt.Errorf("[PANIC RECOVER] %w %s", recoverErr, stack[:length])
-					logger.ErrorContext(r.Context(), "panic recovered", "error", internal)
+					log := logger
+					if log == nil {
+						log = keratin.LoggerFromContext(r.Context()).WithGroup("recover")
+					}
+					log.ErrorContext(r.Context(), "panic recovered", "error", panicErr)
 
 					if keratin.ResponseCommitted(w) {
 						return
@@ -60,6 +53,8 @@ func HTTPRecover(cfg RecoverConfig, logger *slog.Logger) func(next http.Handler)
 	}
 }
 
+// Recover returns a middleware which recovers from panics and returns them as 500 Internal Server Error
+// wrapping [keratin.PanicError], so that they match [keratin.ErrPanic].
 func Recover(cfg RecoverConfig) func(keratin.Handler) keratin.Handler {
 	cfg.SetDefaults()
 
@@ -67,18 +62,7 @@ func Recover(cfg RecoverConfig) func(keratin.Handler) keratin.Handler {
 		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) (err error) {
 			defer func() {
 				if rec := recover(); rec != nil {
-					recoverErr, ok := rec.(error)
-					if !ok {
-						recoverErr = fmt.Errorf("%v", rec)
-					} else if errors.Is(recoverErr, http.ErrAbortHandler) {
-						// don't recover ErrAbortHandler so the response to the client can be aborted
-						panic(recoverErr)
-					}
-
-					stack := make([]byte, cfg.StackSize)
-					length := runtime.Stack(stack, true)
-					internal := fmt.Errorf("[PANIC RECOVER] %w %s", recoverErr, stack[:length])
-					err = keratin.ErrInternalServerError.Wrap(internal)
+					err = keratin.ErrInternalServerError.Wrap(recoverPanic(rec, cfg.StackSize))
 				}
 			}()
 
@@ -88,3 +72,16 @@ func Recover(cfg RecoverConfig) func(keratin.Handler) keratin.Handler {
 		})
 	}
 }
+
+// recoverPanic converts the recovered value to [keratin.PanicError].
+func recoverPanic(rec any, stackSize int) *keratin.PanicError {
+	if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+		// don't recover ErrAbortHandler so the response to the client can be aborted
+		panic(err)
+	}
+
+	stack := make([]byte, stackSize)
+	length := runtime.Stack(stack, true)
+
+	return &keratin.PanicError{Value: rec, Stack: stack[:length]}
+}
@@ -493,6 +493,47 @@ func TestRecover_ErrorUnwrapping(t *testing.T) {
 	})
 }
 
+func TestRecover_PanicError(t *testing.T) {
+	cause := errors.New("database connection failed")
+
+	tests := []struct {
+		name       string
+		panicValue any
+	}{
+		{name: "string panic", panicValue: "boom"},
+		{name: "error panic", panicValue: cause},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Recover(RecoverConfig{})(keratin.HandlerFunc(func(http.ResponseWriter, *http.Request) error {
+				panic(tt.panicValue)
+			}))
+
+			err := handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.ErrorIs(t, err, keratin.ErrPanic)
+			panicErr, ok := errors.AsType[*keratin.PanicError](err)
+			require.True(t, ok)
+			assert.Equal(t, tt.panicValue, panicErr.Value)
+			assert.Contains(t, string(panicErr.Stack), "goroutine")
+
+			if cause, ok := tt.panicValue.(error); ok {
+				assert.ErrorIs(t, err, cause)
+			}
+		})
+	}
+
+	t.Run("ordinary errors are not panics", func(t *testing.T) {
+		handler := Recover(RecoverConfig{})(keratin.HandlerFunc(func(http.ResponseWriter, *http.Request) error {
+			return keratin.ErrInternalServerError
+		}))
+
+		err := handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.NotErrorIs(t, err, keratin.ErrPanic)
+	})
+}
+
 func TestRecover_FormattedError(t *testing.T) {
 	t.Run("formats panic string correctly", func(t *testing.T) {
 		handler := keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
@@ -1133,3 +1174,21 @@ func TestHTTPRecover_ResponseCommitter(t *testing.T) {
 		assert.Contains(t, logContent, "panic recovered")
 	})
 }
+
+func TestHTTPRecover_ContextLogger(t *testing.T) {
+	var buf strings.Builder
+
+	router := keratin.NewRouter(keratin.WithLoggerInjector(slog.New(slog.NewTextHandler(&buf, nil))))
+	router.PreHTTPFunc(HTTPRecover(RecoverConfig{StackSize: 64}, nil))
+	router.GET("/panic", func(w http.ResponseWriter, r *http.Request) error {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	router.Build().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, buf.String(), "panic recovered")
+	assert.Contains(t, buf.String(), "route=/panic")
+	assert.Contains(t, buf.String(), "recover.error=")
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gowool/keratin"
+)
+
+// ErrAmbiguousRequest is returned for the requests which the proxies in front of the server
+// may interpret differently than the server, see [RejectAmbiguousRequests].
+var ErrAmbiguousRequest = keratin.NewHTTPError(http.StatusBadRequest, "ambiguous request")
+
+// singletonHeaders are the request headers which must have a single value.
+var singletonHeaders = []string{
+	keratin.HeaderAuthorization,
+	keratin.HeaderContentLength,
+	keratin.HeaderContentType,
+	keratin.HeaderHost,
+	keratin.HeaderXForwardedHost,
+	keratin.HeaderXForwardedProto,
+}
+
+// RejectAmbiguousRequests returns a middleware which rejects with 400 Bad Request the requests
+// open to the request smuggling and the header spoofing behind proxies parsing them differently:
+//
+//   - both Transfer-Encoding and Content-Length headers, or a Transfer-Encoding other than "chunked"
+//     (the [http.Server] already enforces it, the other servers and adapters may not);
+//   - a body with a GET, HEAD or TRACE request;
+//   - differing values of a header which must have a single value, e.g. Content-Type or Host;
+//   - a header name with an underscore, which some proxies map to a hyphen, e.g. "X_Forwarded_For".
+func RejectAmbiguousRequests(skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	skip := ChainSkipper(skippers...)
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if skip(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			if err := checkAmbiguousRequest(r); err != nil {
+				return ErrAmbiguousRequest.Wrap(err)
+			}
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func checkAmbiguousRequest(r *http.Request) error {
+	if te := r.Header.Values(keratin.HeaderTransferEncoding); len(te) > 0 {
+		if r.Header.Get(keratin.HeaderContentLength) != "" {
+			return errors.New("both Transfer-Encoding and Content-Length headers")
+		}
+		if len(te) != 1 || !strings.EqualFold(strings.TrimSpace(te[0]), "chunked") {
+			return fmt.Errorf("unsupported Transfer-Encoding %q", te)
+		}
+	}
+	if len(r.TransferEncoding) > 1 || len(r.TransferEncoding) == 1 && r.TransferEncoding[0] != "chunked" {
+		return fmt.Errorf("unsupported Transfer-Encoding %q", r.TransferEncoding)
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodTrace:
+		if r.ContentLength > 0 || len(r.TransferEncoding) > 0 {
+			return fmt.Errorf("%s request with a body", r.Method)
+		}
+	}
+
+	for _, name := range singletonHeaders {
+		if values := r.Header.Values(name); len(values) > 1 && slices.ContainsFunc(values[1:], func(v string) bool { return v != values[0] }) {
+			return fmt.Errorf("multiple %s headers", name)
+		}
+	}
+
+	for name := range r.Header {
+		if strings.Contains(name, "_") {
+			return fmt.Errorf("header %q with an underscore", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+func TestRejectAmbiguousRequests(t *testing.T) {
+	h := RejectAmbiguousRequests()(keratin.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}))
+
+	tests := []struct {
+		name    string
+		request func() *http.Request
+		wantErr bool
+	}{
+		{
+			name: "plain get",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/", nil)
+			},
+		},
+		{
+			name: "post with body",
+			request: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("a=1"))
+				r.Header.Set(keratin.HeaderContentType, keratin.MIMEApplicationForm)
+				return r
+			},
+		},
+		{
+			name: "chunked post",
+			request: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("a=1"))
+				r.ContentLength = -1
+				r.TransferEncoding = []string{"chunked"}
+				return r
+			},
+		},
+		{
+			name: "transfer encoding and content length",
+			request: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("a=1"))
+				r.Header.Set(keratin.HeaderTransferEncoding, "chunked")
+				r.Header.Set(keratin.HeaderContentLength, "3")
+				return r
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported transfer encoding",
+			request: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("a=1"))
+				r.Header.Set(keratin.HeaderTransferEncoding, "gzip, chunked")
+				return r
+			},
+			wantErr: true,
+		},
+		{
+			name: "get with body",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/", strings.NewReader("0\r\n\r\n"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "conflicting content types",
+			request: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+				r.Header.Add(keratin.HeaderContentType, keratin.MIMEApplicationJSON)
+				r.Header.Add(keratin.HeaderContentType, keratin.MIMETextPlain)
+				return r
+			},
+			wantErr: true,
+		},
+		{
+			name: "repeated same authorization",
+			request: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.Header.Add(keratin.HeaderAuthorization, "Bearer a")
+				r.Header.Add(keratin.HeaderAuthorization, "Bearer a")
+				return r
+			},
+		},
+		{
+			name: "underscore header",
+			request: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.Header["X_Forwarded_For"] = []string{"10.0.0.1"}
+				return r
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			err := h.ServeHTTP(rec, tt.request())
+
+			if tt.wantErr {
+				var httpErr *keratin.HTTPError
+				require.ErrorAs(t, err, &httpErr)
+				assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+				assert.Equal(t, ErrAmbiguousRequest.Message, httpErr.Message)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+		})
+	}
+}
@@ -10,8 +10,10 @@ import (
 type reqIDKey struct{}
 
 func CtxRequestID(ctx context.Context) string {
-	value, _ := ctx.Value(reqIDKey{}).(string)
-	return value
+	if value, _ := ctx.Value(reqIDKey{}).(string); value != "" {
+		return value
+	}
+	return keratin.FromContext(ctx).RequestID()
 }
 
 // RequestIDConfig defines the config for RequestID middleware.
@@ -55,6 +57,8 @@ func RequestID(cfg RequestIDConfig, skippers ...Skipper) func(keratin.Handler) k
 
 			w.Header().Set(cfg.TargetHeader, rid)
 
+			keratin.FromContext(r.Context()).SetRequestID(rid)
+
 			ctx := context.WithValue(r.Context(), reqIDKey{}, rid)
 
 			return next.ServeHTTP(w, r.WithContext(ctx))
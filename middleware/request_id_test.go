@@ -386,3 +386,20 @@ func TestRequestID_ContextPropagation(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestID_KeratinContext(t *testing.T) {
+	var got string
+
+	router := keratin.NewRouter()
+	router.UseFunc(RequestID(RequestIDConfig{}))
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		got = keratin.FromContext(r.Context()).RequestID()
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(keratin.HeaderXRequestID, "router-id")
+	router.Build().ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "router-id", got)
+}
@@ -1,9 +1,18 @@
 package middleware
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"errors"
+	"io"
 	"log/slog"
+	"math/rand/v2"
+	"net"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gowool/keratin"
@@ -14,14 +23,35 @@ type RequestMetadata struct {
 	Error      error
 	StartTime  time.Time
 	EndTime    time.Time
+
+	// RequestBody and ResponseBody hold the captured (and possibly truncated) bodies
+	// when body capture is enabled, see RequestLoggerConfig.LogRequestBody.
+	RequestBody  []byte
+	ResponseBody []byte
 }
 
+// RequestLoggerLevelFunc returns the log level for a request with the given status code and error.
+type RequestLoggerLevelFunc func(status int, err error) slog.Level
+
+// TraceContextFunc returns the trace and span IDs of the request, or empty strings when there are none.
+type TraceContextFunc func(r *http.Request) (traceID, spanID string)
+
+// RequestLoggerSamplerFunc reports whether a successful request should be logged.
+type RequestLoggerSamplerFunc func(r *http.Request, metadata RequestMetadata) bool
+
 // RequestLoggerAttrsFunc defines a function type for generating logging attributes based on HTTP request and response.
 type RequestLoggerAttrsFunc func(w http.ResponseWriter, r *http.Request, metadata RequestMetadata) []slog.Attr
 
 // ErrorStatusFunc return an error code.
 type ErrorStatusFunc func(context.Context, error) int
 
+// LevelRange is the log level of the requests with a status code in the range [From, To].
+type LevelRange struct {
+	From  int        `json:"from" yaml:"from"`
+	To    int        `json:"to" yaml:"to"`
+	Level slog.Level `json:"level" yaml:"level"`
+}
+
 type RequestLoggerConfig struct {
 	// RequestLoggerAttrsFunc defines a function type for generating logging attributes based on HTTP request and response.
 	RequestLoggerAttrsFunc `json:"-" yaml:"-"`
@@ -31,6 +61,64 @@ type RequestLoggerConfig struct {
 
 	// Logger is the logger used to log the request.
 	Logger *slog.Logger `json:"-" yaml:"-"`
+
+	// UseContextLogger logs the requests with the request-scoped logger of [keratin.LoggerFromContext]
+	// instead of Logger, skipping the attributes the request-scoped logger already has.
+	// Optional. Default value false.
+	UseContextLogger bool `env:"USE_CONTEXT_LOGGER" json:"useContextLogger,omitempty" yaml:"useContextLogger,omitempty"`
+
+	// LevelFunc returns the log level for a request.
+	// Optional. Default value [RequestLoggerLevels] of Levels, [DefaultRequestLoggerLevel] without Levels.
+	LevelFunc RequestLoggerLevelFunc `json:"-" yaml:"-"`
+
+	// Levels maps the status code ranges to the log levels, e.g. to log 404 Not Found at info level:
+	//
+	//	levels:
+	//	  - {from: 404, to: 404, level: INFO}
+	//
+	// The first matching range wins, the statuses matching no range get the [DefaultRequestLoggerLevel].
+	// It is ignored when LevelFunc is set.
+	// Optional.
+	Levels []LevelRange `json:"levels,omitempty" yaml:"levels,omitempty"`
+
+	// TraceContextFunc returns the trace and span IDs added as "trace_id" and "span_id" attributes.
+	// With OpenTelemetry, it can be set to read the active span:
+	//
+	//	func(r *http.Request) (string, string) {
+	//		sc := trace.SpanContextFromContext(r.Context())
+	//		if !sc.IsValid() {
+	//			return "", ""
+	//		}
+	//		return sc.TraceID().String(), sc.SpanID().String()
+	//	}
+	//
+	// Optional. Default value [W3CTraceContext].
+	TraceContextFunc TraceContextFunc `json:"-" yaml:"-"`
+
+	// SampleRate is the fraction (0, 1] of successful requests to log.
+	// Requests which failed (error or status code >= 400) are always logged.
+	// Optional. Default value 1 (log all requests).
+	SampleRate float64 `env:"SAMPLE_RATE" json:"sampleRate,omitempty" yaml:"sampleRate,omitempty"`
+
+	// SamplerFunc decides whether a successful request is logged. It takes precedence over SampleRate.
+	// Optional. Default value nil.
+	SamplerFunc RequestLoggerSamplerFunc `json:"-" yaml:"-"`
+
+	// LogRequestBody enables capturing the request body, read by the handler, into the "request_body" attribute.
+	// Optional. Default value false.
+	LogRequestBody bool `env:"LOG_REQUEST_BODY" json:"logRequestBody,omitempty" yaml:"logRequestBody,omitempty"`
+
+	// LogResponseBody enables capturing the response body into the "response_body" attribute.
+	// Optional. Default value false.
+	LogResponseBody bool `env:"LOG_RESPONSE_BODY" json:"logResponseBody,omitempty" yaml:"logResponseBody,omitempty"`
+
+	// MaxBodySize is the maximum number of bytes captured per body.
+	// Optional. Default value 4KB.
+	MaxBodySize int `env:"MAX_BODY_SIZE" json:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty"`
+
+	// BodyContentTypes is a list of media types whose bodies are captured, "type/*" wildcards are supported.
+	// Optional. Default value JSON, XML, form and text types.
+	BodyContentTypes []string `env:"BODY_CONTENT_TYPES" json:"bodyContentTypes,omitempty" yaml:"bodyContentTypes,omitempty"`
 }
 
 func (c *RequestLoggerConfig) SetDefaults() {
@@ -39,14 +127,42 @@ func (c *RequestLoggerConfig) SetDefaults() {
 	}
 
 	if c.ErrorStatusFunc == nil {
-		c.ErrorStatusFunc = func(_ context.Context, err error) int {
-			return keratin.HTTPErrorStatusCode(err)
-		}
+		c.ErrorStatusFunc = DefaultErrorStatus
 	}
 
 	if c.Logger == nil {
 		c.Logger = slog.Default()
 	}
+
+	if c.LevelFunc == nil {
+		c.LevelFunc = RequestLoggerLevels(c.Levels...)
+	}
+
+	if c.TraceContextFunc == nil {
+		c.TraceContextFunc = W3CTraceContext
+	}
+
+	if c.SampleRate <= 0 || c.SampleRate > 1 {
+		c.SampleRate = 1
+	}
+
+	if c.MaxBodySize <= 0 {
+		c.MaxBodySize = 4 << 10
+	}
+
+	if len(c.BodyContentTypes) == 0 {
+		c.BodyContentTypes = defaultBodyContentTypes()
+	}
+}
+
+func defaultBodyContentTypes() []string {
+	return []string{
+		keratin.MIMEApplicationJSON,
+		keratin.MIMEApplicationProblemJSON,
+		keratin.MIMEApplicationXML,
+		keratin.MIMEApplicationForm,
+		"text/*",
+	}
 }
 
 func RequestLogger(cfg RequestLoggerConfig, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
@@ -54,12 +170,55 @@ func RequestLogger(cfg RequestLoggerConfig, skippers ...Skipper) func(keratin.Ha
 
 	skip := ChainSkipper(skippers...)
 
+	sample := cfg.SamplerFunc
+	if sample == nil && cfg.SampleRate < 1 {
+		sample = func(*http.Request, RequestMetadata) bool {
+			return rand.Float64() < cfg.SampleRate
+		}
+	}
+
+	capture := cfg.LogRequestBody || cfg.LogResponseBody
+
+	pool := &sync.Pool{
+		New: func() any { return new(bodyCapture) },
+	}
+
 	return func(next keratin.Handler) keratin.Handler {
 		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
 			if skip(r) {
 				return next.ServeHTTP(w, r)
 			}
 
+			var bc *bodyCapture
+			if capture {
+				bc = pool.Get().(*bodyCapture)
+				bc.reset(cfg.MaxBodySize)
+
+				var reader *captureReader
+
+				defer func() {
+					// The request and its body may outlive the handler (e.g. in a goroutine it started):
+					// detach the reader from the pooled capture before it is reused by another request.
+					if reader != nil {
+						reader.detach()
+						r.Body = bc.body
+					}
+					bc.reset(0)
+					pool.Put(bc)
+				}()
+
+				if cfg.LogRequestBody && r.Body != nil && r.Body != http.NoBody && keratin.MatchMediaType(r.Header.Get(keratin.HeaderContentType), cfg.BodyContentTypes...) {
+					bc.body = r.Body
+					reader = bc.requestReader()
+					r.Body = reader
+				}
+
+				if cfg.LogResponseBody {
+					bc.ResponseWriter = w
+					w = bc
+				}
+			}
+
 			startTime := time.Now().UTC()
 
 			err := next.ServeHTTP(w, r)
@@ -73,26 +232,47 @@ func RequestLogger(cfg RequestLoggerConfig, skippers ...Skipper) func(keratin.Ha
 				code = cfg.ErrorStatusFunc(r.Context(), err)
 			}
 
-			var level slog.Level
-			switch {
-			case code >= http.StatusBadRequest && code < http.StatusInternalServerError:
-				level = slog.LevelWarn
-			case code >= http.StatusInternalServerError:
-				level = slog.LevelError
-			default:
-				level = slog.LevelInfo
+			metadata := RequestMetadata{
+				StatusCode: code,
+				Error:      err,
+				StartTime:  startTime,
+				EndTime:    endTime,
+			}
+
+			if err == nil && code < http.StatusBadRequest && sample != nil && !sample(r, metadata) {
+				return nil
+			}
+
+			if bc != nil {
+				if bc.body != nil {
+					metadata.RequestBody = bc.reqBuf.Bytes()
+				}
+				if cfg.LogResponseBody && keratin.MatchMediaType(w.Header().Get(keratin.HeaderContentType), cfg.BodyContentTypes...) {
+					metadata.ResponseBody = bc.resBuf.Bytes()
+				}
 			}
 
-			cfg.Logger.LogAttrs(
+			attrs := cfg.RequestLoggerAttrsFunc(w, r, metadata)
+			if traceID, spanID := cfg.TraceContextFunc(r); traceID != "" {
+				attrs = append(attrs, slog.String("trace_id", traceID))
+				if spanID != "" {
+					attrs = append(attrs, slog.String("span_id", spanID))
+				}
+			}
+
+			logger := cfg.Logger
+			if cfg.UseContextLogger {
+				logger = keratin.LoggerFromContext(r.Context())
+				attrs = slices.DeleteFunc(attrs, func(attr slog.Attr) bool {
+					return attr.Key == keratin.LogKeyRequestID || attr.Key == keratin.LogKeyRealIP
+				})
+			}
+
+			logger.LogAttrs(
 				r.Context(),
-				level,
+				cfg.LevelFunc(code, err),
 				"incoming request",
-				cfg.RequestLoggerAttrsFunc(w, r, RequestMetadata{
-					StatusCode: code,
-					Error:      err,
-					StartTime:  startTime,
-					EndTime:    endTime,
-				})...,
+				attrs...,
 			)
 
 			return err
@@ -100,6 +280,76 @@ func RequestLogger(cfg RequestLoggerConfig, skippers ...Skipper) func(keratin.Ha
 	}
 }
 
+// DefaultErrorStatus returns the status code of the error: its HTTP status if any,
+// [keratin.StatusClientClosedRequest] for the client disconnects (see [keratin.IsClientDisconnect]),
+// 504 Gateway Timeout for the exceeded deadlines and 500 Internal Server Error otherwise.
+func DefaultErrorStatus(_ context.Context, err error) int {
+	if code := keratin.ErrorStatusCode(err); code >= http.StatusBadRequest {
+		return code
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+	return keratin.HTTPErrorStatusCode(err)
+}
+
+// RequestLoggerLevels returns a [RequestLoggerLevelFunc] logging the requests at the level of the first range
+// matching their status code, and at the [DefaultRequestLoggerLevel] if no range matches.
+func RequestLoggerLevels(ranges ...LevelRange) RequestLoggerLevelFunc {
+	if len(ranges) == 0 {
+		return DefaultRequestLoggerLevel
+	}
+
+	ranges = slices.Clone(ranges)
+
+	return func(status int, err error) slog.Level {
+		for _, r := range ranges {
+			if status >= r.From && status <= r.To {
+				return r.Level
+			}
+		}
+		return DefaultRequestLoggerLevel(status, err)
+	}
+}
+
+// DefaultRequestLoggerLevel logs server errors (5xx) at error level, client errors (4xx) at warn level,
+// client disconnects (see [keratin.IsClientDisconnect]) at debug level and everything else at info level.
+func DefaultRequestLoggerLevel(status int, err error) slog.Level {
+	switch {
+	case status == keratin.StatusClientClosedRequest || keratin.IsClientDisconnect(err):
+		return slog.LevelDebug
+	case status >= http.StatusBadRequest && status < http.StatusInternalServerError:
+		return slog.LevelWarn
+	case status >= http.StatusInternalServerError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// W3CTraceContext returns the trace and parent span IDs of the W3C Trace Context "traceparent" request header,
+// e.g. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func W3CTraceContext(r *http.Request) (traceID, spanID string) {
+	parts := strings.Split(r.Header.Get(keratin.HeaderTraceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", ""
+	}
+	if !isLowerHex(parts[1]) || !isLowerHex(parts[2]) ||
+		parts[1] == "00000000000000000000000000000000" || parts[2] == "0000000000000000" {
+		return "", ""
+	}
+	return parts[1], parts[2]
+}
+
+func isLowerHex(s string) bool {
+	for i := range len(s) {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
 func RequestLoggerAttrs() RequestLoggerAttrsFunc {
 	return func(w http.ResponseWriter, r *http.Request, metadata RequestMetadata) []slog.Attr {
 		size := 14
@@ -127,10 +377,23 @@ func RequestLoggerAttrs() RequestLoggerAttrsFunc {
 			size++
 		}
 
+		isPanic := errors.Is(metadata.Error, keratin.ErrPanic)
 		if metadata.Error != nil {
 			size++
 		}
 
+		if isPanic {
+			size++
+		}
+
+		if metadata.RequestBody != nil {
+			size++
+		}
+
+		if metadata.ResponseBody != nil {
+			size++
+		}
+
 		c := keratin.FromContext(r.Context())
 
 		attrs := make([]slog.Attr, 0, size)
@@ -171,6 +434,91 @@ func RequestLoggerAttrs() RequestLoggerAttrsFunc {
 			attrs = append(attrs, slog.Any("error", metadata.Error))
 		}
 
+		if isPanic {
+			attrs = append(attrs, slog.Bool("panic", true))
+		}
+
+		if metadata.RequestBody != nil {
+			attrs = append(attrs, slog.String("request_body", string(metadata.RequestBody)))
+		}
+
+		if metadata.ResponseBody != nil {
+			attrs = append(attrs, slog.String("response_body", string(metadata.ResponseBody)))
+		}
+
 		return attrs
 	}
 }
+
+// bodyCapture keeps the first bytes of the request and response bodies for logging.
+type bodyCapture struct {
+	http.ResponseWriter
+	body    io.ReadCloser
+	reqBuf  bytes.Buffer
+	resBuf  bytes.Buffer
+	maxSize int
+}
+
+func (c *bodyCapture) reset(maxSize int) {
+	c.ResponseWriter = nil
+	c.body = nil
+	c.reqBuf.Reset()
+	c.resBuf.Reset()
+	c.maxSize = maxSize
+}
+
+func (c *bodyCapture) requestReader() *captureReader {
+	return &captureReader{ReadCloser: c.body, c: c}
+}
+
+func (c *bodyCapture) Write(b []byte) (int, error) {
+	capBuffer(&c.resBuf, b, c.maxSize)
+	return c.ResponseWriter.Write(b)
+}
+
+func (c *bodyCapture) Flush() {
+	keratin.ResponseFlush(c.ResponseWriter)
+}
+
+func (c *bodyCapture) FlushError() error {
+	return keratin.ResponseFlushError(c.ResponseWriter)
+}
+
+func (c *bodyCapture) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(c.ResponseWriter).Hijack()
+}
+
+func (c *bodyCapture) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+type captureReader struct {
+	io.ReadCloser
+	mu sync.Mutex
+	c  *bodyCapture
+}
+
+func (r *captureReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+
+	r.mu.Lock()
+	if r.c != nil {
+		capBuffer(&r.c.reqBuf, p[:n], r.c.maxSize)
+	}
+	r.mu.Unlock()
+
+	return n, err
+}
+
+// detach stops capturing: the later reads go to the original body only.
+func (r *captureReader) detach() {
+	r.mu.Lock()
+	r.c = nil
+	r.mu.Unlock()
+}
+
+func capBuffer(buf *bytes.Buffer, b []byte, maxSize int) {
+	if remaining := maxSize - buf.Len(); remaining > 0 {
+		buf.Write(b[:min(len(b), remaining)])
+	}
+}
@@ -1,8 +1,12 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -11,6 +15,7 @@ import (
 	"time"
 
 	"github.com/gowool/keratin"
+	"github.com/gowool/keratin/keratintest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -840,6 +845,26 @@ func TestRequestLoggerAttrs(t *testing.T) {
 
 		attrMap := attrsToMap(attrs)
 		assert.Contains(t, attrMap, "error")
+		assert.NotContains(t, attrMap, "panic")
+	})
+
+	t.Run("marks recovered panics", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		rec := httptest.NewRecorder()
+
+		metadata := RequestMetadata{
+			StatusCode: http.StatusInternalServerError,
+			Error:      keratin.ErrInternalServerError.Wrap(&keratin.PanicError{Value: "boom"}),
+			StartTime:  time.Now().UTC(),
+			EndTime:    time.Now().UTC(),
+		}
+
+		attrs := RequestLoggerAttrs()(rec, req, metadata)
+
+		attrMap := attrsToMap(attrs)
+		assert.Contains(t, attrMap, "error")
+		assert.Equal(t, true, attrMap["panic"])
+		assert.Len(t, attrs, cap(attrs))
 	})
 
 	t.Run("does not include error when nil", func(t *testing.T) {
@@ -1028,26 +1053,8 @@ func (h *testLogHandler) WithGroup(name string) slog.Handler {
 	return h
 }
 
-type testRecorder struct {
-	*httptest.ResponseRecorder
-}
-
-func newTestRecorder() *testRecorder {
-	return &testRecorder{
-		ResponseRecorder: httptest.NewRecorder(),
-	}
-}
-
-func (r *testRecorder) StatusCode() int {
-	return r.Code
-}
-
-func (r *testRecorder) Size() int64 {
-	return int64(r.Body.Len())
-}
-
-func (r *testRecorder) Unwrap() http.ResponseWriter {
-	return r.ResponseRecorder
+func newTestRecorder() *keratintest.ResponseRecorder {
+	return keratintest.NewRecorder()
 }
 
 func attrsToString(attrs []slog.Attr) string {
@@ -1068,3 +1075,392 @@ func attrsToMap(attrs []slog.Attr) map[string]any {
 	}
 	return m
 }
+
+func TestRequestLogger_Sampling(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     RequestLoggerConfig
+		handler keratin.HandlerFunc
+		want    int
+	}{
+		{
+			name: "sampler drops successful requests",
+			cfg: RequestLoggerConfig{SamplerFunc: func(*http.Request, RequestMetadata) bool {
+				return false
+			}},
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusOK)
+				return nil
+			},
+			want: 0,
+		},
+		{
+			name: "sampler keeps errors",
+			cfg: RequestLoggerConfig{SamplerFunc: func(*http.Request, RequestMetadata) bool {
+				return false
+			}},
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				return errors.New("boom")
+			},
+			want: 10,
+		},
+		{
+			name: "sample rate keeps failed responses",
+			cfg:  RequestLoggerConfig{SampleRate: 0.000001},
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				return keratin.TextPlain(w, http.StatusNotFound, "missing")
+			},
+			want: 10,
+		},
+		{
+			name: "sample rate 1 logs everything",
+			cfg:  RequestLoggerConfig{SampleRate: 1},
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusOK)
+				return nil
+			},
+			want: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logged int
+			tt.cfg.Logger = slog.New(&testLogHandler{logAttrs: func(context.Context, slog.Level, string, ...slog.Attr) {
+				logged++
+			}})
+
+			router := keratin.NewRouter(keratin.WithErrorHandler(func(http.ResponseWriter, *http.Request, error) {}))
+			router.UseFunc(RequestLogger(tt.cfg))
+			router.GET("/", tt.handler)
+			h := router.Build()
+
+			for range 10 {
+				h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			}
+
+			assert.Equal(t, tt.want, logged)
+		})
+	}
+}
+
+func TestRequestLogger_SampleRate(t *testing.T) {
+	var logged int
+	cfg := RequestLoggerConfig{
+		SampleRate: 0.5,
+		Logger: slog.New(&testLogHandler{logAttrs: func(context.Context, slog.Level, string, ...slog.Attr) {
+			logged++
+		}}),
+	}
+
+	h := RequestLogger(cfg)(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}))
+
+	for range 1000 {
+		require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil)))
+	}
+
+	assert.Greater(t, logged, 300)
+	assert.Less(t, logged, 700)
+}
+
+func TestRequestLogger_BodyCapture(t *testing.T) {
+	tests := []struct {
+		name            string
+		cfg             RequestLoggerConfig
+		reqContentType  string
+		resContentType  string
+		wantContains    []string
+		wantNotContains []string
+	}{
+		{
+			name:           "captures both bodies",
+			cfg:            RequestLoggerConfig{LogRequestBody: true, LogResponseBody: true},
+			reqContentType: keratin.MIMEApplicationJSON,
+			resContentType: keratin.MIMEApplicationJSON,
+			wantContains:   []string{`request_body: {"name":"john"}`, `response_body: {"id":1}`},
+		},
+		{
+			name:           "truncates bodies",
+			cfg:            RequestLoggerConfig{LogRequestBody: true, LogResponseBody: true, MaxBodySize: 4},
+			reqContentType: keratin.MIMEApplicationJSON,
+			resContentType: keratin.MIMEApplicationJSON,
+			wantContains:   []string{`request_body: {"na,`, `response_body: {"id,`},
+		},
+		{
+			name:            "filters content types",
+			cfg:             RequestLoggerConfig{LogRequestBody: true, LogResponseBody: true},
+			reqContentType:  "application/octet-stream",
+			resContentType:  "image/png",
+			wantNotContains: []string{"request_body", "response_body"},
+		},
+		{
+			name:            "disabled by default",
+			reqContentType:  keratin.MIMEApplicationJSON,
+			resContentType:  keratin.MIMEApplicationJSON,
+			wantNotContains: []string{"request_body", "response_body"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var loggedAttrs []slog.Attr
+			tt.cfg.Logger = slog.New(&testLogHandler{logAttrs: func(_ context.Context, _ slog.Level, _ string, attrs ...slog.Attr) {
+				loggedAttrs = attrs
+			}})
+
+			h := RequestLogger(tt.cfg)(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, `{"name":"john"}`, string(body))
+
+				w.Header().Set(keratin.HeaderContentType, tt.resContentType)
+				_, err = w.Write([]byte(`{"id":1}`))
+				return err
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"john"}`))
+			req.Header.Set(keratin.HeaderContentType, tt.reqContentType)
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, h.ServeHTTP(rec, req))
+			assert.Equal(t, `{"id":1}`, rec.Body.String())
+
+			got := attrsToString(loggedAttrs)
+			for _, s := range tt.wantContains {
+				assert.Contains(t, got, s)
+			}
+			for _, s := range tt.wantNotContains {
+				assert.NotContains(t, got, s)
+			}
+		})
+	}
+}
+
+func TestRequestLogger_BodyCaptureDetached(t *testing.T) {
+	var loggedAttrs []slog.Attr
+	cfg := RequestLoggerConfig{
+		LogRequestBody: true,
+		Logger: slog.New(&testLogHandler{logAttrs: func(_ context.Context, _ slog.Level, _ string, attrs ...slog.Attr) {
+			loggedAttrs = attrs
+		}}),
+	}
+
+	var kept io.Reader
+	h := RequestLogger(cfg)(keratin.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) error {
+		if kept == nil {
+			kept = r.Body
+			return nil
+		}
+		_, err := io.ReadAll(r.Body)
+		return err
+	}))
+
+	first := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"first"}`))
+	first.Header.Set(keratin.HeaderContentType, keratin.MIMEApplicationJSON)
+	require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), first))
+	assert.NotEqual(t, kept, first.Body, "the original body is restored")
+
+	second := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"second"}`))
+	second.Header.Set(keratin.HeaderContentType, keratin.MIMEApplicationJSON)
+	require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), second))
+
+	// A read through the body kept past the first request must not leak into the reused capture.
+	body, err := io.ReadAll(kept)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"first"}`, string(body))
+
+	got := attrsToString(loggedAttrs)
+	assert.Contains(t, got, `request_body: {"name":"second"}`)
+	assert.NotContains(t, got, "first")
+}
+
+func TestDefaultRequestLoggerLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelInfo, DefaultRequestLoggerLevel(http.StatusOK, nil))
+	assert.Equal(t, slog.LevelInfo, DefaultRequestLoggerLevel(http.StatusFound, nil))
+	assert.Equal(t, slog.LevelWarn, DefaultRequestLoggerLevel(http.StatusNotFound, nil))
+	assert.Equal(t, slog.LevelError, DefaultRequestLoggerLevel(http.StatusBadGateway, nil))
+	assert.Equal(t, slog.LevelDebug, DefaultRequestLoggerLevel(keratin.StatusClientClosedRequest, nil))
+	assert.Equal(t, slog.LevelDebug, DefaultRequestLoggerLevel(http.StatusInternalServerError, context.Canceled))
+}
+
+func TestRequestLogger_LevelFunc(t *testing.T) {
+	var loggedLevel slog.Level
+	cfg := RequestLoggerConfig{
+		LevelFunc: func(status int, err error) slog.Level {
+			if status == http.StatusNotFound {
+				return slog.LevelDebug
+			}
+			return DefaultRequestLoggerLevel(status, err)
+		},
+		Logger: slog.New(&testLogHandler{logAttrs: func(_ context.Context, level slog.Level, _ string, _ ...slog.Attr) {
+			loggedLevel = level
+		}}),
+	}
+
+	h := RequestLogger(cfg)(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return keratin.ErrNotFound
+	}))
+
+	_ = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, slog.LevelDebug, loggedLevel)
+}
+
+func TestW3CTraceContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		wantTrace   string
+		wantSpan    string
+	}{
+		{
+			name:        "valid",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantTrace:   "4bf92f3577b34da6a3ce929d0e0e4736",
+			wantSpan:    "00f067aa0ba902b7",
+		},
+		{name: "missing"},
+		{name: "malformed", traceparent: "00-abc-def-01"},
+		{name: "uppercase", traceparent: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01"},
+		{name: "zero trace id", traceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.traceparent != "" {
+				r.Header.Set(keratin.HeaderTraceparent, tt.traceparent)
+			}
+
+			traceID, spanID := W3CTraceContext(r)
+			assert.Equal(t, tt.wantTrace, traceID)
+			assert.Equal(t, tt.wantSpan, spanID)
+		})
+	}
+}
+
+func TestRequestLogger_TraceContext(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       RequestLoggerConfig
+		header    string
+		want      []string
+		wantEmpty bool
+	}{
+		{
+			name:   "traceparent header",
+			header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			want:   []string{"trace_id: 4bf92f3577b34da6a3ce929d0e0e4736", "span_id: 00f067aa0ba902b7"},
+		},
+		{
+			name: "custom trace context func",
+			cfg: RequestLoggerConfig{TraceContextFunc: func(*http.Request) (string, string) {
+				return "trace", "span"
+			}},
+			want: []string{"trace_id: trace", "span_id: span"},
+		},
+		{
+			name:      "no trace",
+			wantEmpty: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var loggedAttrs []slog.Attr
+			tt.cfg.Logger = slog.New(&testLogHandler{logAttrs: func(_ context.Context, _ slog.Level, _ string, attrs ...slog.Attr) {
+				loggedAttrs = attrs
+			}})
+
+			h := RequestLogger(tt.cfg)(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				return nil
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set(keratin.HeaderTraceparent, tt.header)
+			}
+			require.NoError(t, h.ServeHTTP(httptest.NewRecorder(), r))
+
+			got := attrsToString(loggedAttrs)
+			for _, s := range tt.want {
+				assert.Contains(t, got, s)
+			}
+			if tt.wantEmpty {
+				assert.NotContains(t, got, "trace_id")
+				assert.NotContains(t, got, "span_id")
+			}
+		})
+	}
+}
+
+func TestRequestLogger_UseContextLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	router := keratin.NewRouter(keratin.WithLoggerInjector(slog.New(slog.NewJSONHandler(&buf, nil))))
+	router.Use(&keratin.Middleware[keratin.Handler]{
+		Priority: -1,
+		Func:     RequestID(RequestIDConfig{Generator: func() string { return "rid-1" }}),
+	})
+	router.UseFunc(RequestLogger(RequestLoggerConfig{
+		Logger:           slog.New(&mockHandler{}),
+		UseContextLogger: true,
+	}))
+	router.GET("/test", func(w http.ResponseWriter, r *http.Request) error {
+		return keratin.TextPlain(w, http.StatusOK, "ok")
+	})
+
+	router.Build().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "incoming request", record["msg"])
+	assert.Equal(t, "rid-1", record[keratin.LogKeyRequestID])
+	assert.Equal(t, "/test", record[keratin.LogKeyRoute])
+	assert.Equal(t, 1, strings.Count(buf.String(), `"request_id"`))
+}
+
+func TestDefaultErrorStatus(t *testing.T) {
+	ctx := context.Background()
+
+	assert.Equal(t, http.StatusNotFound, DefaultErrorStatus(ctx, keratin.ErrNotFound))
+	assert.Equal(t, http.StatusServiceUnavailable, DefaultErrorStatus(ctx, keratin.ErrServiceUnavailable.Wrap(context.DeadlineExceeded)))
+	assert.Equal(t, keratin.StatusClientClosedRequest, DefaultErrorStatus(ctx, fmt.Errorf("query: %w", context.Canceled)))
+	assert.Equal(t, http.StatusGatewayTimeout, DefaultErrorStatus(ctx, fmt.Errorf("upstream: %w", context.DeadlineExceeded)))
+	assert.Equal(t, http.StatusInternalServerError, DefaultErrorStatus(ctx, errors.New("boom")))
+}
+
+func TestRequestLoggerLevels(t *testing.T) {
+	levels := RequestLoggerLevels(
+		LevelRange{From: 404, To: 404, Level: slog.LevelInfo},
+		LevelRange{From: 499, To: 499, Level: slog.LevelInfo},
+		LevelRange{From: 400, To: 499, Level: slog.LevelError},
+	)
+
+	assert.Equal(t, slog.LevelInfo, levels(http.StatusNotFound, keratin.ErrNotFound))
+	assert.Equal(t, slog.LevelInfo, levels(keratin.StatusClientClosedRequest, context.Canceled))
+	assert.Equal(t, slog.LevelError, levels(http.StatusConflict, nil))
+	assert.Equal(t, slog.LevelError, levels(http.StatusBadGateway, nil))
+	assert.Equal(t, slog.LevelInfo, levels(http.StatusOK, nil))
+
+	assert.Equal(t, slog.LevelWarn, RequestLoggerLevels()(http.StatusNotFound, nil))
+}
+
+func TestRequestLoggerConfig_Levels(t *testing.T) {
+	var cfg RequestLoggerConfig
+	require.NoError(t, json.Unmarshal([]byte(`{"levels":[{"from":500,"to":599,"level":"WARN"}]}`), &cfg))
+	require.Equal(t, []LevelRange{{From: 500, To: 599, Level: slog.LevelWarn}}, cfg.Levels)
+
+	var loggedLevel slog.Level
+	cfg.Logger = slog.New(&testLogHandler{logAttrs: func(_ context.Context, level slog.Level, _ string, _ ...slog.Attr) {
+		loggedLevel = level
+	}})
+
+	h := RequestLogger(cfg)(keratin.HandlerFunc(func(http.ResponseWriter, *http.Request) error {
+		return errors.New("boom")
+	}))
+
+	_ = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, slog.LevelWarn, loggedLevel)
+}
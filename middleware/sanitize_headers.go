@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/textproto"
+
+	"github.com/gowool/keratin"
+)
+
+type SanitizeHeadersConfig struct {
+	// Strip are the response headers removed, e.g. "Server" or "X-Powered-By".
+	// Optional.
+	Strip []string `env:"STRIP" json:"strip,omitempty" yaml:"strip,omitempty"`
+
+	// Allow turns on the allowlist mode: only the listed response headers are kept, all the other ones
+	// are removed. The list must contain the headers needed by the clients, e.g. "Content-Type".
+	// Optional.
+	Allow []string `env:"ALLOW" json:"allow,omitempty" yaml:"allow,omitempty"`
+
+	// Replace are the values replacing the present response headers, e.g. {"Server": "keratin"}.
+	// Optional.
+	Replace map[string]string `env:"REPLACE" json:"replace,omitempty" yaml:"replace,omitempty"`
+}
+
+// StripHeaders returns a response interceptor removing the named response headers, see [SanitizeHeaders].
+func StripHeaders(names ...string) *keratin.Interceptor[http.ResponseWriter] {
+	return SanitizeHeaders(SanitizeHeadersConfig{Strip: names})
+}
+
+// SanitizeHeaders returns a response interceptor removing or replacing the response headers
+// right before they are written, whatever handler, middleware or error handler set them.
+// It is registered once at the router level:
+//
+//	router := keratin.NewRouter(keratin.WithResponseInterceptors(
+//		middleware.StripHeaders("Server", "X-Powered-By"),
+//	))
+//
+// The headers added by the [http.Server] itself (e.g. Date) are not sanitized.
+// It panics if the config has nothing to sanitize.
+func SanitizeHeaders(cfg SanitizeHeadersConfig) *keratin.Interceptor[http.ResponseWriter] {
+	if len(cfg.Strip) == 0 && len(cfg.Allow) == 0 && len(cfg.Replace) == 0 {
+		panic("middleware: sanitize headers: strip, allow or replace headers are required")
+	}
+
+	s := &headerSanitizer{replace: make(map[string]string, len(cfg.Replace))}
+	for _, name := range cfg.Strip {
+		s.strip = append(s.strip, textproto.CanonicalMIMEHeaderKey(name))
+	}
+	if len(cfg.Allow) > 0 {
+		s.allow = make(map[string]struct{}, len(cfg.Allow))
+		for _, name := range cfg.Allow {
+			s.allow[textproto.CanonicalMIMEHeaderKey(name)] = struct{}{}
+		}
+	}
+	for name, value := range cfg.Replace {
+		s.replace[textproto.CanonicalMIMEHeaderKey(name)] = value
+	}
+
+	return &keratin.Interceptor[http.ResponseWriter]{
+		ID: "middleware.sanitize_headers",
+		Func: func(w http.ResponseWriter) (http.ResponseWriter, func()) {
+			return &sanitizeHeadersWriter{ResponseWriter: w, sanitizer: s}, nil
+		},
+	}
+}
+
+type headerSanitizer struct {
+	strip   []string
+	allow   map[string]struct{}
+	replace map[string]string
+}
+
+func (s *headerSanitizer) sanitize(header http.Header) {
+	for _, name := range s.strip {
+		delete(header, name)
+	}
+
+	if s.allow != nil {
+		for name := range header {
+			if _, ok := s.allow[name]; !ok {
+				delete(header, name)
+			}
+		}
+	}
+
+	for name, value := range s.replace {
+		if _, ok := header[name]; ok {
+			header.Set(name, value)
+		}
+	}
+}
+
+// sanitizeHeadersWriter sanitizes the response headers right before every status is written,
+// including the informational ones.
+type sanitizeHeadersWriter struct {
+	http.ResponseWriter
+	sanitizer *headerSanitizer
+	written   bool
+}
+
+func (w *sanitizeHeadersWriter) WriteHeader(statusCode int) {
+	if !w.written {
+		w.sanitizer.sanitize(w.Header())
+		w.written = statusCode >= 200
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *sanitizeHeadersWriter) Write(b []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *sanitizeHeadersWriter) Flush() {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	keratin.ResponseFlush(w.ResponseWriter)
+}
+
+func (w *sanitizeHeadersWriter) FlushError() error {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	return keratin.ResponseFlushError(w.ResponseWriter)
+}
+
+func (w *sanitizeHeadersWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *sanitizeHeadersWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
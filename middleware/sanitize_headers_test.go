@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gowool/keratin"
+)
+
+func TestSanitizeHeaders(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Server", "nginx/1.25")
+		w.Header().Set("X-Powered-By", "PHP/8.3")
+		w.Header().Set("X-Debug-Token", "abc")
+		if r.URL.Query().Has("fail") {
+			return keratin.ErrBadRequest
+		}
+		if r.URL.Query().Has("write") {
+			_, err := w.Write([]byte("body"))
+			return err
+		}
+		return keratin.TextPlain(w, http.StatusOK, "ok")
+	}
+
+	tests := []struct {
+		name   string
+		cfg    SanitizeHeadersConfig
+		target string
+		want   http.Header
+	}{
+		{
+			name:   "strip",
+			cfg:    SanitizeHeadersConfig{Strip: []string{"server", "X-Powered-By"}},
+			target: "/",
+			want:   http.Header{"Content-Type": {keratin.MIMETextPlainCharsetUTF8}, "X-Debug-Token": {"abc"}},
+		},
+		{
+			name:   "strip on error",
+			cfg:    SanitizeHeadersConfig{Strip: []string{"Server", "X-Powered-By", "X-Debug-Token"}},
+			target: "/?fail",
+		},
+		{
+			name:   "strip on implicit status",
+			cfg:    SanitizeHeadersConfig{Strip: []string{"Server", "X-Powered-By", "X-Debug-Token"}},
+			target: "/?write",
+		},
+		{
+			name:   "allow",
+			cfg:    SanitizeHeadersConfig{Allow: []string{"content-type"}},
+			target: "/",
+			want:   http.Header{"Content-Type": {keratin.MIMETextPlainCharsetUTF8}},
+		},
+		{
+			name:   "replace",
+			cfg:    SanitizeHeadersConfig{Strip: []string{"X-Powered-By", "X-Debug-Token"}, Replace: map[string]string{"server": "keratin", "Via": "proxy"}},
+			target: "/",
+			want:   http.Header{"Content-Type": {keratin.MIMETextPlainCharsetUTF8}, "Server": {"keratin"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := keratin.NewRouter(keratin.WithResponseInterceptors(SanitizeHeaders(tt.cfg)))
+			router.GET("/", handler)
+
+			w := httptest.NewRecorder()
+			router.Build().ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			header := w.Header().Clone()
+			if tt.want == nil {
+				assert.NotContains(t, header, "Server")
+				assert.NotContains(t, header, "X-Powered-By")
+				assert.NotContains(t, header, "X-Debug-Token")
+				return
+			}
+			header.Del(keratin.HeaderXContentTypeOptions)
+			assert.Equal(t, tt.want, header)
+		})
+	}
+}
+
+func TestStripHeaders(t *testing.T) {
+	router := keratin.NewRouter(keratin.WithResponseInterceptors(StripHeaders("X-Powered-By")))
+	router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("X-Powered-By", "keratin")
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	router.Build().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get("X-Powered-By"))
+}
+
+func TestSanitizeHeaders_Panics(t *testing.T) {
+	assert.PanicsWithValue(t, "middleware: sanitize headers: strip, allow or replace headers are required", func() {
+		SanitizeHeaders(SanitizeHeadersConfig{})
+	})
+}
@@ -0,0 +1,528 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gowool/keratin"
+)
+
+const (
+	HeaderSignature        = "X-Signature"
+	HeaderSignatureKeyID   = "X-Signature-Key-Id"
+	HeaderSignatureNonce   = "X-Signature-Nonce"
+	HeaderSignatureInput   = "Signature-Input"
+	HeaderHTTPSignature    = "Signature"
+	HeaderContentDigest    = "Content-Digest"
+	maxSignatureBodyLen    = 1 << 20
+	signatureAlgHMACSHA256 = "hmac-sha256"
+)
+
+var (
+	// ErrSignatureMissing is returned when the request is not signed.
+	ErrSignatureMissing = keratin.NewHTTPError(http.StatusUnauthorized, "missing request signature")
+	// ErrSignatureInvalid is returned when the request signature is malformed or does not match.
+	ErrSignatureInvalid = keratin.NewHTTPError(http.StatusUnauthorized, "invalid request signature")
+	// ErrSignatureExpired is returned when the request was signed outside the allowed clock skew.
+	ErrSignatureExpired = keratin.NewHTTPError(http.StatusUnauthorized, "request signature expired")
+	// ErrSignatureReplayed is returned when the request nonce was already used.
+	ErrSignatureReplayed = keratin.NewHTTPError(http.StatusUnauthorized, "request replayed")
+)
+
+// NonceStore remembers the nonces of the signed requests for the replay protection.
+// [IdempotencyStorage] implementations, e.g. [IdempotencyMemoryStorage], satisfy it.
+type NonceStore interface {
+	// Reserve stores the value only if the key does not exist (like Redis SET NX)
+	// and reports whether it was stored.
+	Reserve(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+}
+
+type SignatureConfig struct {
+	// Secrets maps the key IDs to the shared HMAC secrets.
+	// Required.
+	Secrets map[string]string `env:"SECRETS" json:"-" yaml:"-"`
+
+	// NonceStore enables the replay protection, the signed requests must carry a nonce
+	// which is remembered for twice the MaxSkew.
+	// Optional. Default value nil (no replay protection).
+	NonceStore NonceStore `json:"-" yaml:"-"`
+
+	// MaxSkew is the maximum difference between the signature time and the server time.
+	// Optional. Default value 5m.
+	MaxSkew time.Duration `env:"MAX_SKEW" json:"maxSkew,omitempty,format:units" yaml:"maxSkew,omitempty"`
+
+	// MaxBodySize is the maximum size of the request body read to compute its digest.
+	// Optional. Default value 1MB.
+	MaxBodySize int64 `env:"MAX_BODY_SIZE" json:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty"`
+
+	// HTTPMessageSignatures accepts the RFC 9421 HTTP Message Signatures (Signature-Input and Signature
+	// headers) with the "hmac-sha256" algorithm, besides the X-Signature scheme.
+	// Optional. Default value false.
+	HTTPMessageSignatures bool `env:"HTTP_MESSAGE_SIGNATURES" json:"httpMessageSignatures,omitempty" yaml:"httpMessageSignatures,omitempty"`
+
+	// RequiredComponents are the components every HTTP Message Signature must cover,
+	// "@path" and "@query" are covered by "@target-uri" and "@request-target" too.
+	// The "content-digest" component is always required for the requests with a body.
+	// Optional. Default value ["@method", "@path", "@query"].
+	RequiredComponents []string `env:"REQUIRED_COMPONENTS" json:"requiredComponents,omitempty" yaml:"requiredComponents,omitempty"`
+
+	// ErrorHandler defines a function which is executed for returning custom errors.
+	ErrorHandler func(r *http.Request, err error) error `json:"-" yaml:"-"`
+}
+
+func (c *SignatureConfig) SetDefaults() {
+	if c.MaxSkew <= 0 {
+		c.MaxSkew = 5 * time.Minute
+	}
+	if c.MaxBodySize <= 0 {
+		c.MaxBodySize = maxSignatureBodyLen
+	}
+	if len(c.RequiredComponents) == 0 {
+		c.RequiredComponents = []string{"@method", "@path", "@query"}
+	}
+}
+
+// VerifySignature returns a middleware which verifies the signature of the requests signed
+// with a shared secret, e.g. the webhooks.
+//
+// The X-Signature scheme (see [SignRequest]) signs with HMAC-SHA256 the canonical string
+//
+//	METHOD \n REQUEST-URI \n DATE \n NONCE \n BASE64(SHA-256(BODY))
+//
+// where DATE is the Date header and NONCE the X-Signature-Nonce header. The X-Signature-Key-Id header
+// selects the secret, it may be omitted if only one secret is configured.
+//
+// With SignatureConfig.HTTPMessageSignatures the RFC 9421 signatures are verified too, the "created"
+// and "nonce" signature parameters are used for the expiry and the replay protection.
+// The signatures of the requests with a body must cover the Content-Digest header,
+// which is checked against the body.
+//
+// Unsigned, invalid, expired and replayed requests fail with 401 Unauthorized.
+func VerifySignature(cfg SignatureConfig, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	cfg.SetDefaults()
+
+	if len(cfg.Secrets) == 0 {
+		panic("middleware: verify signature: at least one secret is required")
+	}
+
+	skip := ChainSkipper(skippers...)
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if skip(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			var err error
+			switch {
+			case cfg.HTTPMessageSignatures && r.Header.Get(HeaderSignatureInput) != "":
+				err = cfg.verifyHTTPMessageSignature(r)
+			case r.Header.Get(HeaderSignature) != "":
+				err = cfg.verifyHMACSignature(r)
+			default:
+				err = ErrSignatureMissing
+			}
+
+			if err != nil {
+				if keratin.ErrorStatusCode(err) < http.StatusBadRequest {
+					err = ErrSignatureInvalid.Wrap(err)
+				}
+				if cfg.ErrorHandler != nil {
+					return cfg.ErrorHandler(r, err)
+				}
+				return err
+			}
+
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (c *SignatureConfig) verifyHMACSignature(r *http.Request) error {
+	secret, err := c.secret(r.Header.Get(HeaderSignatureKeyID))
+	if err != nil {
+		return err
+	}
+
+	date, err := http.ParseTime(r.Header.Get(keratin.HeaderDate))
+	if err != nil {
+		return fmt.Errorf("invalid Date header: %w", err)
+	}
+	if err = c.checkTime(date); err != nil {
+		return err
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(r.Header.Get(HeaderSignature))
+	if err != nil {
+		return err
+	}
+
+	body, err := c.readBody(r)
+	if err != nil {
+		return err
+	}
+
+	nonce := r.Header.Get(HeaderSignatureNonce)
+	expected := hmacSignature(secret, r.Method, r.URL.RequestURI(), r.Header.Get(keratin.HeaderDate), nonce, body)
+	if !hmac.Equal(signature, expected) {
+		return ErrSignatureInvalid
+	}
+
+	return c.checkNonce(r.Context(), nonce)
+}
+
+func (c *SignatureConfig) verifyHTTPMessageSignature(r *http.Request) error {
+	label, components, rawParams, params, err := parseSignatureInput(r.Header.Get(HeaderSignatureInput))
+	if err != nil {
+		return err
+	}
+
+	if alg, ok := params["alg"]; ok && alg != signatureAlgHMACSHA256 {
+		return fmt.Errorf("unsupported signature algorithm %q", alg)
+	}
+
+	for _, required := range c.RequiredComponents {
+		if !coversComponent(components, required) {
+			return fmt.Errorf("signature does not cover %q", required)
+		}
+	}
+
+	coversDigest := slices.Contains(components, "content-digest")
+	if !coversDigest && hasBody(r) {
+		return errors.New(`signature does not cover "content-digest" of the body`)
+	}
+
+	secret, err := c.secret(params["keyid"])
+	if err != nil {
+		return err
+	}
+
+	created, err := strconv.ParseInt(params["created"], 10, 64)
+	if err != nil {
+		return errors.New("signature has no created parameter")
+	}
+	if err = c.checkTime(time.Unix(created, 0)); err != nil {
+		return err
+	}
+	if expires, ok := params["expires"]; ok {
+		if exp, err := strconv.ParseInt(expires, 10, 64); err != nil || time.Now().Unix() > exp {
+			return ErrSignatureExpired
+		}
+	}
+
+	signature, err := parseSignature(r.Header.Get(HeaderHTTPSignature), label)
+	if err != nil {
+		return err
+	}
+
+	if coversDigest {
+		if err = c.checkContentDigest(r); err != nil {
+			return err
+		}
+	}
+
+	base, err := signatureBase(r, components, rawParams)
+	if err != nil {
+		return err
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(base))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return ErrSignatureInvalid
+	}
+
+	return c.checkNonce(r.Context(), params["nonce"])
+}
+
+// coversComponent reports whether the components cover the required one,
+// the URI components cover the path and the query.
+func coversComponent(components []string, required string) bool {
+	if slices.Contains(components, required) {
+		return true
+	}
+	switch required {
+	case "@path", "@query":
+		return slices.Contains(components, "@target-uri") || slices.Contains(components, "@request-target")
+	}
+	return false
+}
+
+// hasBody reports whether the request may have a body.
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
+
+func (c *SignatureConfig) secret(keyID string) ([]byte, error) {
+	if keyID == "" && len(c.Secrets) == 1 {
+		for _, secret := range c.Secrets {
+			return []byte(secret), nil
+		}
+	}
+
+	secret, ok := c.Secrets[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown signature key %q", keyID)
+	}
+	return []byte(secret), nil
+}
+
+func (c *SignatureConfig) checkTime(t time.Time) error {
+	if skew := time.Since(t); skew > c.MaxSkew || skew < -c.MaxSkew {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+func (c *SignatureConfig) checkNonce(ctx context.Context, nonce string) error {
+	if c.NonceStore == nil {
+		return nil
+	}
+	if nonce == "" {
+		return errors.New("signature has no nonce")
+	}
+
+	ok, err := c.NonceStore.Reserve(ctx, "signature:nonce:"+nonce, []byte{1}, 2*c.MaxSkew)
+	if err != nil {
+		return keratin.ErrInternalServerError.Wrap(err)
+	}
+	if !ok {
+		return ErrSignatureReplayed
+	}
+	return nil
+}
+
+// readBody reads the request body and leaves it readable for the next handlers.
+func (c *SignatureConfig) readBody(r *http.Request) ([]byte, error) {
+	return keratin.BufferBody(r, c.MaxBodySize)
+}
+
+func (c *SignatureConfig) checkContentDigest(r *http.Request) error {
+	digests, err := parseContentDigest(r.Header.Values(HeaderContentDigest), []string{DigestSHA256, DigestSHA512})
+	if err != nil {
+		return err
+	}
+	if len(digests) == 0 {
+		return errors.New("missing or unsupported Content-Digest header")
+	}
+
+	body, err := c.readBody(r)
+	if err != nil {
+		return err
+	}
+
+	if err = verifyDigests(digests, body); err != nil {
+		return fmt.Errorf("content digest mismatch: %w", err)
+	}
+	return nil
+}
+
+// SignRequest signs the request with the X-Signature scheme verified by [VerifySignature].
+// It sets the Date header if missing, the nonce (if not empty) and the key ID (if not empty) headers.
+func SignRequest(r *http.Request, keyID, secret, nonce string) error {
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return err
+		}
+		_ = r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if r.Header.Get(keratin.HeaderDate) == "" {
+		r.Header.Set(keratin.HeaderDate, time.Now().UTC().Format(http.TimeFormat))
+	}
+	if keyID != "" {
+		r.Header.Set(HeaderSignatureKeyID, keyID)
+	}
+	if nonce != "" {
+		r.Header.Set(HeaderSignatureNonce, nonce)
+	}
+
+	signature := hmacSignature([]byte(secret), r.Method, r.URL.RequestURI(), r.Header.Get(keratin.HeaderDate), nonce, body)
+	r.Header.Set(HeaderSignature, base64.StdEncoding.EncodeToString(signature))
+
+	return nil
+}
+
+func hmacSignature(secret []byte, method, requestURI, date, nonce string, body []byte) []byte {
+	digest := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(method + "\n" + requestURI + "\n" + date + "\n" + nonce + "\n"))
+	mac.Write([]byte(base64.StdEncoding.EncodeToString(digest[:])))
+	return mac.Sum(nil)
+}
+
+// signatureBase creates the RFC 9421 signature base for the covered components.
+func signatureBase(r *http.Request, components []string, rawParams string) (string, error) {
+	var b strings.Builder
+
+	for _, component := range components {
+		value, err := componentValue(r, component)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(strconv.Quote(component))
+		b.WriteString(": ")
+		b.WriteString(value)
+		b.WriteByte('\n')
+	}
+
+	b.WriteString(`"@signature-params": `)
+	b.WriteString(rawParams)
+
+	return b.String(), nil
+}
+
+func componentValue(r *http.Request, component string) (string, error) {
+	switch component {
+	case "@method":
+		return r.Method, nil
+	case "@target-uri":
+		return keratin.Scheme(r) + "://" + r.Host + r.URL.RequestURI(), nil
+	case "@authority":
+		return strings.ToLower(r.Host), nil
+	case "@scheme":
+		return keratin.Scheme(r), nil
+	case "@request-target":
+		return r.URL.RequestURI(), nil
+	case "@path":
+		if path := r.URL.EscapedPath(); path != "" {
+			return path, nil
+		}
+		return "/", nil
+	case "@query":
+		return "?" + r.URL.RawQuery, nil
+	}
+
+	if strings.HasPrefix(component, "@") {
+		return "", fmt.Errorf("unsupported signature component %q", component)
+	}
+
+	values := r.Header.Values(component)
+	if len(values) == 0 {
+		return "", fmt.Errorf("missing signed header %q", component)
+	}
+
+	// the values are trimmed in a copy, they share the backing array of the request header
+	trimmed := make([]string, len(values))
+	for i, value := range values {
+		trimmed[i] = strings.TrimSpace(value)
+	}
+	return strings.Join(trimmed, ", "), nil
+}
+
+// parseSignatureInput parses the first member of the Signature-Input header, e.g.
+// `sig1=("@method" "@path");created=1618884473;keyid="key"`.
+func parseSignatureInput(header string) (label string, components []string, rawParams string, params map[string]string, err error) {
+	members := splitStructuredList(header)
+	if len(members) == 0 {
+		return "", nil, "", nil, errors.New("empty Signature-Input header")
+	}
+
+	label, rawParams, ok := strings.Cut(members[0], "=")
+	if !ok || !strings.HasPrefix(rawParams, "(") {
+		return "", nil, "", nil, errors.New("malformed Signature-Input header")
+	}
+	label = strings.TrimSpace(label)
+
+	end := strings.IndexByte(rawParams, ')')
+	if end < 0 {
+		return "", nil, "", nil, errors.New("malformed Signature-Input header")
+	}
+
+	for item := range strings.FieldsSeq(rawParams[1:end]) {
+		component, err := strconv.Unquote(item)
+		if err != nil || strings.Contains(item, ";") {
+			return "", nil, "", nil, fmt.Errorf("unsupported signature component %s", item)
+		}
+		components = append(components, strings.ToLower(component))
+	}
+
+	params = make(map[string]string)
+	for param := range strings.SplitSeq(rawParams[end+1:], ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		params[key] = value
+	}
+
+	return label, components, rawParams, params, nil
+}
+
+// parseSignature returns the signature with the label from the Signature header, e.g. `sig1=:base64:`.
+func parseSignature(header, label string) ([]byte, error) {
+	for _, member := range splitStructuredList(header) {
+		l, value, ok := strings.Cut(member, "=")
+		if ok && strings.TrimSpace(l) == label {
+			return parseByteSequence(value)
+		}
+	}
+	return nil, fmt.Errorf("missing signature %q", label)
+}
+
+func parseByteSequence(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 || value[0] != ':' || value[len(value)-1] != ':' {
+		return nil, errors.New("malformed byte sequence")
+	}
+	return base64.StdEncoding.DecodeString(value[1 : len(value)-1])
+}
+
+// splitStructuredList splits a structured field list or dictionary by the top level commas.
+func splitStructuredList(header string) []string {
+	var (
+		members []string
+		quoted  bool
+		depth   int
+		start   int
+	)
+
+	for i := 0; i < len(header); i++ {
+		switch header[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case '(':
+			if !quoted {
+				depth++
+			}
+		case ')':
+			if !quoted {
+				depth--
+			}
+		case ',':
+			if !quoted && depth == 0 {
+				if member := strings.TrimSpace(header[start:i]); member != "" {
+					members = append(members, member)
+				}
+				start = i + 1
+			}
+		}
+	}
+
+	if member := strings.TrimSpace(header[start:]); member != "" {
+		members = append(members, member)
+	}
+	return members
+}
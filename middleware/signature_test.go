@@ -0,0 +1,339 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+func signatureTestHandler(t *testing.T, cfg SignatureConfig) keratin.Handler {
+	t.Helper()
+
+	return VerifySignature(cfg)(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		return keratin.TextPlain(w, http.StatusOK, string(body))
+	}))
+}
+
+func TestVerifySignature_HMAC(t *testing.T) {
+	h := signatureTestHandler(t, SignatureConfig{
+		Secrets:    map[string]string{"k1": "secret1", "k2": "secret2"},
+		NonceStore: NewIdempotencyMemoryStorage(),
+	})
+
+	newRequest := func(body string) *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/webhooks?source=billing", strings.NewReader(body))
+	}
+
+	tests := []struct {
+		name     string
+		request  func() *http.Request
+		wantCode int
+	}{
+		{
+			name: "valid",
+			request: func() *http.Request {
+				r := newRequest(`{"event":"paid"}`)
+				require.NoError(t, SignRequest(r, "k2", "secret2", "nonce-1"))
+				return r
+			},
+		},
+		{
+			name: "replayed",
+			request: func() *http.Request {
+				r := newRequest(`{"event":"paid"}`)
+				require.NoError(t, SignRequest(r, "k2", "secret2", "nonce-1"))
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "unsigned",
+			request:  func() *http.Request { return newRequest("{}") },
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "tampered body",
+			request: func() *http.Request {
+				r := newRequest(`{"event":"paid"}`)
+				require.NoError(t, SignRequest(r, "k1", "secret1", "nonce-2"))
+				r.Body = io.NopCloser(strings.NewReader(`{"event":"refunded"}`))
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "wrong secret",
+			request: func() *http.Request {
+				r := newRequest("{}")
+				require.NoError(t, SignRequest(r, "k1", "secret2", "nonce-3"))
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "unknown key",
+			request: func() *http.Request {
+				r := newRequest("{}")
+				require.NoError(t, SignRequest(r, "k3", "secret1", "nonce-4"))
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "expired",
+			request: func() *http.Request {
+				r := newRequest("{}")
+				r.Header.Set(keratin.HeaderDate, time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+				require.NoError(t, SignRequest(r, "k1", "secret1", "nonce-5"))
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "missing nonce",
+			request: func() *http.Request {
+				r := newRequest("{}")
+				require.NoError(t, SignRequest(r, "k1", "secret1", ""))
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			err := h.ServeHTTP(rec, tt.request())
+
+			if tt.wantCode != 0 {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantCode, keratin.HTTPErrorStatusCode(err))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, `{"event":"paid"}`, rec.Body.String(), "the body is restored")
+		})
+	}
+}
+
+func TestVerifySignature_SingleSecretWithoutKeyID(t *testing.T) {
+	h := signatureTestHandler(t, SignatureConfig{Secrets: map[string]string{"only": "secret"}})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, SignRequest(r, "", "secret", ""))
+
+	assert.NoError(t, h.ServeHTTP(httptest.NewRecorder(), r))
+}
+
+func TestVerifySignature_HTTPMessageSignatures(t *testing.T) {
+	const (
+		secret = "rfc9421-secret"
+		body   = `{"hello":"world"}`
+	)
+
+	h := signatureTestHandler(t, SignatureConfig{
+		Secrets:               map[string]string{"test-key": secret},
+		NonceStore:            NewIdempotencyMemoryStorage(),
+		HTTPMessageSignatures: true,
+	})
+
+	bodyDigest := sha256.Sum256([]byte(body))
+	contentDigest := "sha-256=:" + base64.StdEncoding.EncodeToString(bodyDigest[:]) + ":"
+
+	sign := func(r *http.Request, params string, components ...string) {
+		input := `("` + strings.Join(components, `" "`) + `")` + params
+
+		var base strings.Builder
+		for _, component := range components {
+			var value string
+			switch component {
+			case "@method":
+				value = r.Method
+			case "@path":
+				value = r.URL.Path
+			case "@query":
+				value = "?" + r.URL.RawQuery
+			case "@authority":
+				value = r.Host
+			default:
+				value = r.Header.Get(component)
+			}
+			base.WriteString(`"` + component + `": ` + value + "\n")
+		}
+		base.WriteString(`"@signature-params": ` + input)
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(base.String()))
+
+		r.Header.Set(HeaderSignatureInput, "sig1="+input)
+		r.Header.Set(HeaderHTTPSignature, "sig1=:"+base64.StdEncoding.EncodeToString(mac.Sum(nil))+":")
+	}
+
+	created := strconv.FormatInt(time.Now().Unix(), 10)
+	params := func(nonce string) string {
+		return `;created=` + created + `;keyid="test-key";alg="hmac-sha256";nonce="` + nonce + `"`
+	}
+
+	newRequest := func() *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/foo?param=value", strings.NewReader(body))
+		r.Header.Set(HeaderContentDigest, contentDigest)
+		r.Header.Set(keratin.HeaderContentType, keratin.MIMEApplicationJSON)
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		request  func() *http.Request
+		wantCode int
+	}{
+		{
+			name: "valid",
+			request: func() *http.Request {
+				r := newRequest()
+				sign(r, params("n1"), "@method", "@authority", "@path", "@query", "content-digest", "content-type")
+				return r
+			},
+		},
+		{
+			name: "replayed",
+			request: func() *http.Request {
+				r := newRequest()
+				sign(r, params("n1"), "@method", "@authority", "@path", "@query", "content-digest", "content-type")
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "body does not match digest",
+			request: func() *http.Request {
+				r := newRequest()
+				sign(r, params("n2"), "@method", "@path", "@query", "content-digest")
+				r.Body = io.NopCloser(strings.NewReader(`{"hello":"evil"}`))
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "required component not covered",
+			request: func() *http.Request {
+				r := newRequest()
+				sign(r, params("n3"), "@method", "content-digest")
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "tampered header",
+			request: func() *http.Request {
+				r := newRequest()
+				sign(r, params("n4"), "@method", "@path", "@query", "content-digest", "content-type")
+				r.Header.Set(keratin.HeaderContentType, keratin.MIMETextPlain)
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "body not covered",
+			request: func() *http.Request {
+				r := newRequest()
+				sign(r, params("n7"), "@method", "@path", "@query")
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "query not covered",
+			request: func() *http.Request {
+				r := newRequest()
+				sign(r, params("n8"), "@method", "@path", "content-digest")
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "tampered query",
+			request: func() *http.Request {
+				r := newRequest()
+				sign(r, params("n9"), "@method", "@path", "@query", "content-digest")
+				r.URL.RawQuery = "param=evil"
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "expired",
+			request: func() *http.Request {
+				r := newRequest()
+				old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+				sign(r, `;created=`+old+`;keyid="test-key";nonce="n5"`, "@method", "@path", "@query", "content-digest")
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "unsupported algorithm",
+			request: func() *http.Request {
+				r := newRequest()
+				sign(r, `;created=`+created+`;keyid="test-key";alg="ed25519";nonce="n6"`, "@method", "@path", "@query", "content-digest")
+				return r
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			err := h.ServeHTTP(rec, tt.request())
+
+			if tt.wantCode != 0 {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantCode, keratin.HTTPErrorStatusCode(err))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, body, rec.Body.String())
+		})
+	}
+}
+
+func TestComponentValue_DoesNotModifyHeader(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Add("X-Value", " a ")
+	r.Header.Add("X-Value", "b ")
+
+	value, err := componentValue(r, "x-value")
+	require.NoError(t, err)
+	assert.Equal(t, "a, b", value)
+	assert.Equal(t, []string{" a ", "b "}, r.Header.Values("X-Value"))
+}
+
+func TestSplitStructuredList(t *testing.T) {
+	assert.Equal(t,
+		[]string{`sig1=("@method" "@path");keyid="a,b"`, `sig2=("@method")`},
+		splitStructuredList(`sig1=("@method" "@path");keyid="a,b", sig2=("@method")`),
+	)
+	assert.Empty(t, splitStructuredList(" "))
+}
+
+func TestVerifySignature_Panics(t *testing.T) {
+	assert.Panics(t, func() { VerifySignature(SignatureConfig{}) })
+}
@@ -3,10 +3,13 @@ package middleware
 import (
 	"net/http"
 	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/expr-lang/expr"
 	"github.com/expr-lang/expr/vm"
+
+	"github.com/gowool/keratin"
 	"github.com/gowool/keratin/internal"
 )
 
@@ -62,6 +65,87 @@ func EqualPathSkipper(paths ...string) Skipper {
 	}
 }
 
+// RegexPathSkipper skips requests whose path matches one of the regular expressions.
+// Like the other path skippers, a pattern can be prefixed with a method, e.g. "GET ^/health(z)?$".
+// It panics if a pattern is not a valid regular expression.
+func RegexPathSkipper(patterns ...string) Skipper {
+	type methodRegexp struct {
+		method string
+		re     *regexp.Regexp
+	}
+
+	regexps := make([]methodRegexp, len(patterns))
+	for i, pattern := range patterns {
+		if index := strings.IndexRune(pattern, ' '); index > 0 && strings.ToUpper(pattern[:index]) == pattern[:index] {
+			regexps[i].method = pattern[:index]
+			pattern = strings.TrimSpace(pattern[index+1:])
+		}
+		regexps[i].re = regexp.MustCompile(pattern)
+	}
+
+	return func(req *http.Request) bool {
+		for _, mr := range regexps {
+			if (mr.method == "" || mr.method == req.Method) && mr.re.MatchString(req.URL.Path) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HeaderSkipper skips requests which have the header with one of the values (case-insensitive).
+// Comma separated header values are matched token by token, e.g. HeaderSkipper("Connection", "upgrade").
+// Without values, requests are skipped whenever the header is present.
+func HeaderSkipper(name string, values ...string) Skipper {
+	return func(req *http.Request) bool {
+		headerValues := req.Header.Values(name)
+		if len(values) == 0 {
+			return len(headerValues) > 0
+		}
+
+		for _, headerValue := range headerValues {
+			for token := range strings.SplitSeq(headerValue, ",") {
+				token = strings.TrimSpace(token)
+				for _, value := range values {
+					if strings.EqualFold(token, value) {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	}
+}
+
+// ContentTypeSkipper skips requests whose media type matches one of the types.
+// Types can contain a subtype wildcard, e.g. "multipart/*".
+func ContentTypeSkipper(types ...string) Skipper {
+	return func(req *http.Request) bool {
+		return keratin.MatchMediaType(req.Header.Get(keratin.HeaderContentType), types...)
+	}
+}
+
+// MethodSkipper skips requests with one of the methods, e.g. MethodSkipper(http.MethodOptions).
+func MethodSkipper(methods ...string) Skipper {
+	return func(req *http.Request) bool {
+		for _, method := range methods {
+			if strings.EqualFold(req.Method, method) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RouteFlagSkipper skips requests whose matched route turns the metadata flag off,
+// e.g. RouteFlagSkipper(keratin.MetaCompress) skips the routes marked with [keratin.Route.NoCompress].
+func RouteFlagSkipper(key string) Skipper {
+	return func(req *http.Request) bool {
+		enabled, ok := keratin.RouteFlag(req.Context(), key)
+		return ok && !enabled
+	}
+}
+
 func CheckMethod(method, pattern string) (string, bool) {
 	if index := strings.IndexRune(pattern, ' '); index > 0 {
 		if method == pattern[:index] {
@@ -2,10 +2,13 @@ package middleware
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/gowool/keratin"
 )
 
 func TestChainSkipper(t *testing.T) {
@@ -849,3 +852,204 @@ func createRequest(method, path string) *http.Request {
 		URL:    &url.URL{Path: path},
 	}
 }
+
+func TestRegexPathSkipper(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		method   string
+		want     bool
+	}{
+		{
+			name:     "matches pattern",
+			patterns: []string{`^/health(z)?$`},
+			path:     "/healthz",
+			method:   http.MethodGet,
+			want:     true,
+		},
+		{
+			name:     "matches one of multiple patterns",
+			patterns: []string{`^/health$`, `^/static/.+\.(css|js)$`},
+			path:     "/static/app.js",
+			method:   http.MethodGet,
+			want:     true,
+		},
+		{
+			name:     "no match",
+			patterns: []string{`^/health$`},
+			path:     "/health/live",
+			method:   http.MethodGet,
+			want:     false,
+		},
+		{
+			name:     "pattern with method specifier",
+			patterns: []string{`GET ^/users/\d+$`},
+			path:     "/users/42",
+			method:   http.MethodGet,
+			want:     true,
+		},
+		{
+			name:     "pattern with wrong method",
+			patterns: []string{`GET ^/users/\d+$`},
+			path:     "/users/42",
+			method:   http.MethodPost,
+			want:     false,
+		},
+		{
+			name:     "pattern with space",
+			patterns: []string{`^/a b$`},
+			path:     "/a b",
+			method:   http.MethodGet,
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skipper := RegexPathSkipper(tt.patterns...)
+			assert.Equal(t, tt.want, skipper(createRequest(tt.method, tt.path)))
+		})
+	}
+}
+
+func TestRegexPathSkipper_InvalidPattern(t *testing.T) {
+	assert.Panics(t, func() {
+		RegexPathSkipper(`^/users/(\d+$`)
+	})
+}
+
+func TestHeaderSkipper(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		values []string
+		set    map[string]string
+		want   bool
+	}{
+		{
+			name:   "header present without values",
+			header: "X-Health-Check",
+			set:    map[string]string{"X-Health-Check": "1"},
+			want:   true,
+		},
+		{
+			name:   "header missing without values",
+			header: "X-Health-Check",
+			want:   false,
+		},
+		{
+			name:   "case insensitive value",
+			header: "Upgrade",
+			values: []string{"websocket"},
+			set:    map[string]string{"Upgrade": "WebSocket"},
+			want:   true,
+		},
+		{
+			name:   "comma separated token",
+			header: "Connection",
+			values: []string{"upgrade"},
+			set:    map[string]string{"Connection": "keep-alive, Upgrade"},
+			want:   true,
+		},
+		{
+			name:   "value mismatch",
+			header: "Upgrade",
+			values: []string{"websocket"},
+			set:    map[string]string{"Upgrade": "h2c"},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := createRequest(http.MethodGet, "/")
+			req.Header = make(http.Header)
+			for k, v := range tt.set {
+				req.Header.Set(k, v)
+			}
+			assert.Equal(t, tt.want, HeaderSkipper(tt.header, tt.values...)(req))
+		})
+	}
+}
+
+func TestContentTypeSkipper(t *testing.T) {
+	tests := []struct {
+		name        string
+		types       []string
+		contentType string
+		want        bool
+	}{
+		{
+			name:        "exact match with parameters",
+			types:       []string{"application/json"},
+			contentType: "application/json; charset=utf-8",
+			want:        true,
+		},
+		{
+			name:        "wildcard subtype",
+			types:       []string{"multipart/*"},
+			contentType: "multipart/form-data; boundary=abc",
+			want:        true,
+		},
+		{
+			name:        "no match",
+			types:       []string{"application/json", "multipart/*"},
+			contentType: "text/plain",
+			want:        false,
+		},
+		{
+			name:        "missing content type",
+			types:       []string{"application/json"},
+			contentType: "",
+			want:        false,
+		},
+		{
+			name:        "invalid content type",
+			types:       []string{"application/json"},
+			contentType: "application/json; =",
+			want:        false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := createRequest(http.MethodPost, "/")
+			req.Header = make(http.Header)
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			assert.Equal(t, tt.want, ContentTypeSkipper(tt.types...)(req))
+		})
+	}
+}
+
+func TestMethodSkipper(t *testing.T) {
+	skipper := MethodSkipper(http.MethodOptions, "head")
+
+	assert.True(t, skipper(createRequest(http.MethodOptions, "/")))
+	assert.True(t, skipper(createRequest(http.MethodHead, "/")))
+	assert.False(t, skipper(createRequest(http.MethodGet, "/")))
+}
+
+func TestRouteFlagSkipper(t *testing.T) {
+	var skipped []bool
+	skipper := RouteFlagSkipper(keratin.MetaCompress)
+	handler := func(_ http.ResponseWriter, r *http.Request) error {
+		skipped = append(skipped, skipper(r))
+		return nil
+	}
+
+	router := keratin.NewRouter()
+	router.GET("/default", handler)
+	router.GET("/forced", handler).Compress()
+	router.GET("/exempt", handler).NoCompress()
+	h := router.Build()
+
+	for _, target := range []string{"/default", "/forced", "/exempt"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	assert.Equal(t, []bool{false, false, true}, skipped)
+	assert.False(t, skipper(httptest.NewRequest(http.MethodGet, "/", nil)))
+}
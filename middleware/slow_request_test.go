@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+func TestSlowRequest(t *testing.T) {
+	errHandler := errors.New("handler error")
+
+	tests := []struct {
+		name     string
+		delay    time.Duration
+		err      error
+		skippers []Skipper
+		wantSlow bool
+	}{
+		{name: "fast request", delay: 0},
+		{name: "slow request", delay: 30 * time.Millisecond, wantSlow: true},
+		{name: "slow failed request", delay: 30 * time.Millisecond, err: errHandler, wantSlow: true},
+		{name: "skipped slow request", delay: 30 * time.Millisecond, skippers: []Skipper{func(*http.Request) bool { return true }}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				pattern string
+				latency time.Duration
+			)
+			onSlow := func(r *http.Request, d time.Duration) {
+				pattern = r.Pattern
+				latency = d
+			}
+
+			router := keratin.NewRouter(keratin.WithErrorHandler(func(w http.ResponseWriter, _ *http.Request, err error) {
+				assert.ErrorIs(t, err, errHandler)
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			router.UseFunc(SlowRequest(10*time.Millisecond, onSlow, tt.skippers...))
+			router.GET("/users/{id}", func(w http.ResponseWriter, _ *http.Request) error {
+				time.Sleep(tt.delay)
+				if tt.err != nil {
+					return tt.err
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return nil
+			})
+
+			rec := httptest.NewRecorder()
+			router.Build().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+			if tt.wantSlow {
+				assert.Equal(t, "GET /users/{id}", pattern)
+				assert.GreaterOrEqual(t, latency, tt.delay)
+			} else {
+				assert.Empty(t, pattern)
+				assert.Zero(t, latency)
+			}
+		})
+	}
+}
+
+func TestSlowRequest_DefaultLogger(t *testing.T) {
+	var buf bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	handler := SlowRequest(time.Millisecond, nil)(keratin.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/reports", nil)
+	req.Pattern = "GET /reports"
+	require.NoError(t, handler.ServeHTTP(httptest.NewRecorder(), req))
+
+	out := buf.String()
+	assert.Contains(t, out, "level=WARN")
+	assert.Contains(t, out, `msg="slow request"`)
+	assert.Contains(t, out, `pattern="GET /reports"`)
+	assert.Contains(t, out, "threshold=1ms")
+}
+
+func TestSlowRequest_Panics(t *testing.T) {
+	assert.Panics(t, func() { SlowRequest(0, nil) })
+	assert.Panics(t, func() { SlowRequest(-time.Second, nil) })
+}
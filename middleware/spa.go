@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"bufio"
+	"io/fs"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gowool/keratin"
+)
+
+type SPAConfig struct {
+	// FS is the file system with the built single-page application.
+	// Required.
+	FS fs.FS `json:"-" yaml:"-"`
+
+	// Index is the page served for the unknown paths.
+	// Optional. Default value "index.html".
+	Index string `env:"INDEX" json:"index,omitempty" yaml:"index,omitempty"`
+
+	// Exclude is a list of path prefixes passed through without serving files or falling back to Index.
+	// Optional. Default value ["/api/"].
+	Exclude []string `env:"EXCLUDE" json:"exclude,omitempty" yaml:"exclude,omitempty"`
+}
+
+func (c *SPAConfig) SetDefaults() {
+	if c.Index == "" {
+		c.Index = keratin.IndexPage
+	}
+
+	if len(c.Exclude) == 0 {
+		c.Exclude = []string{"/api/"}
+	}
+}
+
+// SPA returns a middleware hosting a single-page application with the history API fallback.
+//
+// GET and HEAD requests for files existing in [SPAConfig.FS] are served directly.
+// Other requests are passed to the next handler and, when it responds with 404 Not Found,
+// [SPAConfig.Index] is served instead, so the client-side router can handle the path.
+// Requests with an excluded path prefix (e.g. "/api/") are always passed through.
+//
+// SPA is meant to be registered with [keratin.Router.Pre] so it also handles the paths without a route.
+func SPA(cfg SPAConfig, skippers ...Skipper) func(keratin.Handler) keratin.Handler {
+	if cfg.FS == nil {
+		panic("spa middleware requires a file system")
+	}
+
+	cfg.SetDefaults()
+
+	skip := ChainSkipper(skippers...)
+	index := keratin.FileFS(cfg.FS, cfg.Index)
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if skip(r) ||
+				(r.Method != http.MethodGet && r.Method != http.MethodHead) ||
+				hasAnyPrefix(r.URL.Path, cfg.Exclude) {
+				return next.ServeHTTP(w, r)
+			}
+
+			name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+			if name != "" && name != cfg.Index && fs.ValidPath(name) {
+				if fi, err := fs.Stat(cfg.FS, name); err == nil && fi.Mode().IsRegular() {
+					return keratin.FileFS(cfg.FS, name).ServeHTTP(w, r)
+				}
+			}
+
+			nw := &notFoundWriter{ResponseWriter: w}
+
+			err := next.ServeHTTP(nw, r)
+			if !nw.notFound && (err == nil || keratin.HTTPErrorStatusCode(err) != http.StatusNotFound || keratin.ResponseCommitted(w)) {
+				return err
+			}
+
+			return index.ServeHTTP(w, r)
+		})
+	}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// notFoundWriter swallows a 404 Not Found response, so that another one can be written instead.
+type notFoundWriter struct {
+	http.ResponseWriter
+	notFound bool
+}
+
+func (w *notFoundWriter) WriteHeader(statusCode int) {
+	if w.notFound {
+		return
+	}
+
+	if statusCode == http.StatusNotFound && !keratin.ResponseCommitted(w.ResponseWriter) {
+		w.notFound = true
+
+		// drop the headers of the not found response, e.g. set by http.Error
+		h := w.Header()
+		h.Del(keratin.HeaderContentType)
+		h.Del(keratin.HeaderXContentTypeOptions)
+		return
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *notFoundWriter) Write(b []byte) (int, error) {
+	if w.notFound {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *notFoundWriter) Flush() {
+	if !w.notFound {
+		keratin.ResponseFlush(w.ResponseWriter)
+	}
+}
+
+func (w *notFoundWriter) FlushError() error {
+	if w.notFound {
+		return nil
+	}
+	return keratin.ResponseFlushError(w.ResponseWriter)
+}
+
+func (w *notFoundWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *notFoundWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
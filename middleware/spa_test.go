@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gowool/keratin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSPAConfig_SetDefaults(t *testing.T) {
+	cfg := SPAConfig{}
+	cfg.SetDefaults()
+
+	assert.Equal(t, keratin.IndexPage, cfg.Index)
+	assert.Equal(t, []string{"/api/"}, cfg.Exclude)
+}
+
+func TestSPA_RequiresFS(t *testing.T) {
+	assert.PanicsWithValue(t, "spa middleware requires a file system", func() {
+		SPA(SPAConfig{})
+	})
+}
+
+func TestSPA(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":    {Data: []byte("<html>app</html>")},
+		"assets/app.js": {Data: []byte("console.log(1)")},
+	}
+
+	router := keratin.NewRouter()
+	router.PreFunc(SPA(SPAConfig{FS: fsys}))
+	router.GET("/api/users", func(w http.ResponseWriter, r *http.Request) error {
+		return keratin.TextPlain(w, http.StatusOK, "users")
+	})
+	router.GET("/health", func(w http.ResponseWriter, r *http.Request) error {
+		return keratin.TextPlain(w, http.StatusOK, "healthy")
+	})
+	router.GET("/items/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		return keratin.ErrNotFound
+	})
+	router.POST("/form", func(w http.ResponseWriter, r *http.Request) error {
+		return keratin.TextPlain(w, http.StatusOK, "posted")
+	})
+
+	handler := router.Build()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "real file", method: http.MethodGet, path: "/assets/app.js", wantStatus: http.StatusOK, wantBody: "console.log(1)"},
+		{name: "root", method: http.MethodGet, path: "/", wantStatus: http.StatusOK, wantBody: "<html>app</html>"},
+		{name: "client route", method: http.MethodGet, path: "/settings/profile", wantStatus: http.StatusOK, wantBody: "<html>app</html>"},
+		{name: "handler not found", method: http.MethodGet, path: "/items/1", wantStatus: http.StatusOK, wantBody: "<html>app</html>"},
+		{name: "server route", method: http.MethodGet, path: "/health", wantStatus: http.StatusOK, wantBody: "healthy"},
+		{name: "api route", method: http.MethodGet, path: "/api/users", wantStatus: http.StatusOK, wantBody: "users"},
+		{name: "unknown api route", method: http.MethodGet, path: "/api/unknown", wantStatus: http.StatusNotFound, wantBody: "404 page not found\n"},
+		{name: "post passes through", method: http.MethodPost, path: "/form", wantStatus: http.StatusOK, wantBody: "posted"},
+		{name: "unknown post", method: http.MethodPost, path: "/unknown", wantStatus: http.StatusNotFound, wantBody: "404 page not found\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantBody, w.Body.String())
+			if tt.wantBody == "<html>app</html>" {
+				assert.Contains(t, w.Header().Get(keratin.HeaderContentType), keratin.MIMETextHTML)
+				assert.Empty(t, w.Header().Get(keratin.HeaderXContentTypeOptions))
+			}
+		})
+	}
+}
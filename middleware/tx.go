@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gowool/keratin"
+)
+
+type txKey[T any] struct{}
+
+// CtxTx returns the request-scoped transaction stored by [TxMiddleware].
+func CtxTx[T any](ctx context.Context) (T, bool) {
+	tx, ok := ctx.Value(txKey[T]{}).(T)
+	return tx, ok
+}
+
+// TxMiddleware returns a middleware that opens a transaction per request using begin,
+// stores it in the request context (see [CtxTx]) and finalizes it once the handler returns.
+//
+// The transaction is committed only when the handler returns no error and the response
+// status code (if any was written) is 2xx or 3xx. Otherwise, including when the handler
+// panics, the transaction is rolled back. Panics are re-raised after the rollback.
+func TxMiddleware[T any](
+	begin func(context.Context) (T, error),
+	commit func(T) error,
+	rollback func(T) error,
+	skippers ...Skipper,
+) func(keratin.Handler) keratin.Handler {
+	if begin == nil || commit == nil || rollback == nil {
+		panic("middleware: tx: begin, commit and rollback are required")
+	}
+
+	skip := ChainSkipper(skippers...)
+
+	return func(next keratin.Handler) keratin.Handler {
+		return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) (err error) {
+			if skip(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			tx, err := begin(r.Context())
+			if err != nil {
+				return fmt.Errorf("middleware: tx: begin: %w", err)
+			}
+
+			defer func() {
+				if rec := recover(); rec != nil {
+					_ = rollback(tx)
+					panic(rec)
+				}
+
+				if err != nil || keratin.ResponseStatusCode(w) >= http.StatusBadRequest {
+					if rbErr := rollback(tx); rbErr != nil {
+						err = errors.Join(err, fmt.Errorf("middleware: tx: rollback: %w", rbErr))
+					}
+					return
+				}
+
+				if cErr := commit(tx); cErr != nil {
+					err = fmt.Errorf("middleware: tx: commit: %w", cErr)
+				}
+			}()
+
+			ctx := context.WithValue(r.Context(), txKey[T]{}, tx)
+
+			return next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+type testTx struct {
+	committed  bool
+	rolledBack bool
+}
+
+func newTestTxMiddleware(beginErr, commitErr error) (func(keratin.Handler) keratin.Handler, *testTx) {
+	tx := new(testTx)
+
+	mw := TxMiddleware(
+		func(context.Context) (*testTx, error) {
+			if beginErr != nil {
+				return nil, beginErr
+			}
+			return tx, nil
+		},
+		func(tx *testTx) error {
+			tx.committed = true
+			return commitErr
+		},
+		func(tx *testTx) error {
+			tx.rolledBack = true
+			return nil
+		},
+	)
+
+	return mw, tx
+}
+
+func TestTxMiddleware(t *testing.T) {
+	beginErr := errors.New("begin failed")
+	commitErr := errors.New("commit failed")
+	handlerErr := errors.New("handler failed")
+
+	tests := []struct {
+		name           string
+		beginErr       error
+		commitErr      error
+		handler        func(w http.ResponseWriter, r *http.Request) error
+		wantErr        error
+		wantCommitted  bool
+		wantRolledBack bool
+	}{
+		{
+			name: "commits on success",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusCreated)
+				return nil
+			},
+			wantCommitted: true,
+		},
+		{
+			name: "commits on redirect",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusFound)
+				return nil
+			},
+			wantCommitted: true,
+		},
+		{
+			name: "rolls back on error",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				return handlerErr
+			},
+			wantErr:        handlerErr,
+			wantRolledBack: true,
+		},
+		{
+			name: "rolls back on failed response",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusConflict)
+				return nil
+			},
+			wantRolledBack: true,
+		},
+		{
+			name:     "returns begin error",
+			beginErr: beginErr,
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				t.Fatal("handler must not be called")
+				return nil
+			},
+			wantErr: beginErr,
+		},
+		{
+			name:      "returns commit error",
+			commitErr: commitErr,
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				return nil
+			},
+			wantErr:       commitErr,
+			wantCommitted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mw, tx := newTestTxMiddleware(tt.beginErr, tt.commitErr)
+
+			var gotErr error
+			router := keratin.NewRouter(keratin.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+				gotErr = err
+			}))
+			router.UseFunc(mw)
+			router.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+				got, ok := CtxTx[*testTx](r.Context())
+				require.True(t, ok)
+				require.Same(t, tx, got)
+				return tt.handler(w, r)
+			})
+
+			router.Build().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, gotErr, tt.wantErr)
+			} else {
+				assert.NoError(t, gotErr)
+			}
+			assert.Equal(t, tt.wantCommitted, tx.committed)
+			assert.Equal(t, tt.wantRolledBack, tx.rolledBack)
+		})
+	}
+}
+
+func TestTxMiddleware_Panic(t *testing.T) {
+	mw, tx := newTestTxMiddleware(nil, nil)
+
+	h := mw(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		panic("boom")
+	}))
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+	assert.True(t, tx.rolledBack)
+	assert.False(t, tx.committed)
+}
+
+func TestTxMiddleware_RequiresFuncs(t *testing.T) {
+	assert.Panics(t, func() {
+		TxMiddleware[int](nil, nil, nil)
+	})
+}
+
+func TestCtxTx_Missing(t *testing.T) {
+	_, ok := CtxTx[*testTx](context.Background())
+	assert.False(t, ok)
+}
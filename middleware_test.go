@@ -457,3 +457,86 @@ func TestMiddlewares_build_ExecutionOrder(t *testing.T) {
 	}
 	assert.Equal(t, expected, executionOrder)
 }
+
+func TestMiddlewares_sort(t *testing.T) {
+	mw := func(id string, priority int, before, after []string) *Middleware[Handler] {
+		return &Middleware[Handler]{ID: id, Priority: priority, Before: before, After: after}
+	}
+
+	ids := func(mws Middlewares[Handler]) []string {
+		var result []string
+		for _, m := range mws {
+			result = append(result, m.ID)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name        string
+		middlewares Middlewares[Handler]
+		expected    []string
+		wantErr     string
+	}{
+		{
+			name: "priority only",
+			middlewares: Middlewares[Handler]{
+				mw("b", 1, nil, nil),
+				mw("a", 0, nil, nil),
+				mw("c", 1, nil, nil),
+			},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name: "before constraint overrides priority",
+			middlewares: Middlewares[Handler]{
+				mw("recover", 0, nil, nil),
+				mw("session", 0, nil, nil),
+				mw("logger", 10, []string{"recover"}, nil),
+			},
+			expected: []string{"logger", "recover", "session"},
+		},
+		{
+			name: "after constraint overrides priority",
+			middlewares: Middlewares[Handler]{
+				mw("csrf", -5, nil, []string{"session"}),
+				mw("logger", 0, nil, nil),
+				mw("session", 0, nil, nil),
+			},
+			expected: []string{"session", "csrf", "logger"},
+		},
+		{
+			name: "unknown ids are ignored",
+			middlewares: Middlewares[Handler]{
+				mw("a", 0, []string{"missing"}, []string{"unknown"}),
+				mw("b", 0, nil, nil),
+			},
+			expected: []string{"a", "b"},
+		},
+		{
+			name: "cycle",
+			middlewares: Middlewares[Handler]{
+				mw("a", 0, []string{"b"}, nil),
+				mw("b", 0, []string{"c"}, nil),
+				mw("c", 0, []string{"a"}, nil),
+				mw("d", 0, nil, nil),
+			},
+			wantErr: "middleware order constraints form a cycle: a -> c -> b -> a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.middlewares.sort()
+
+			if tt.wantErr != "" {
+				require.ErrorIs(t, err, ErrMiddlewareCycle)
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Panics(t, func() { tt.middlewares.build(nil) })
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ids(tt.middlewares))
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package keratin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"mime"
+	"mime/multipart"
+	"net/http"
+)
+
+// MultipartOptions restricts the parts streamed by [MultipartReader].
+type MultipartOptions struct {
+	// MaxPartSize is the maximum size of a single part in bytes.
+	// Optional. Default value 0 (unlimited).
+	MaxPartSize int64 `env:"MAX_PART_SIZE" json:"maxPartSize,omitempty" yaml:"maxPartSize,omitempty"`
+
+	// MaxParts is the maximum number of parts.
+	// Optional. Default value 0 (unlimited).
+	MaxParts int `env:"MAX_PARTS" json:"maxParts,omitempty" yaml:"maxParts,omitempty"`
+
+	// AllowedTypes is a list of allowed media types of the file parts detected from their content,
+	// e.g. "image/png" or "image/*".
+	// Optional. Default value none (any type).
+	AllowedTypes []string `env:"ALLOWED_TYPES" json:"allowedTypes,omitempty" yaml:"allowedTypes,omitempty"`
+}
+
+// MultipartPart is a part of a streamed multipart body. Reading it fails with 413 when
+// the part exceeds MultipartOptions.MaxPartSize or the request body exceeds its limit.
+type MultipartPart struct {
+	*multipart.Part
+
+	// ContentType is the media type detected from the content of a file part,
+	// the declared one (or "text/plain") of a form field.
+	ContentType string
+
+	reader io.Reader
+}
+
+// IsFile reports whether the part is a file, i.e. it has a file name.
+func (p *MultipartPart) IsFile() bool {
+	return p.FileName() != ""
+}
+
+func (p *MultipartPart) Read(b []byte) (int, error) {
+	n, err := p.reader.Read(b)
+	if err != nil && !errors.Is(err, io.EOF) {
+		err = multipartError(err)
+	}
+	return n, err
+}
+
+// MultipartStream iterates the parts of a multipart/form-data body without buffering them,
+// see [MultipartReader].
+type MultipartStream struct {
+	reader *multipart.Reader
+	opts   MultipartOptions
+	parts  int
+}
+
+// MultipartReader returns a stream of the parts of a multipart/form-data request body, so large
+// uploads can be processed (e.g. copied to an object storage) without the memory and temporary
+// files used by [http.Request.ParseMultipartForm]. Every part must be consumed before the next one.
+//
+// The failures are returned as [HTTPError]s: 415 for a not multipart body or a file with not allowed
+// content type, 413 for too many or too large parts and when the request body exceeds the limit set
+// by [Route.MaxBody] or the body limit middleware, 400 for a malformed body.
+func MultipartReader(r *http.Request, opts ...MultipartOptions) (*MultipartStream, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, multipartError(err)
+	}
+
+	s := &MultipartStream{reader: reader}
+	if len(opts) > 0 {
+		s.opts = opts[0]
+	}
+	return s, nil
+}
+
+// Next returns the next part, or [io.EOF] after the last one.
+func (s *MultipartStream) Next() (*MultipartPart, error) {
+	part, err := s.reader.NextPart()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, multipartError(err)
+	}
+
+	s.parts++
+	if s.opts.MaxParts > 0 && s.parts > s.opts.MaxParts {
+		return nil, NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("too many parts, at most %d allowed", s.opts.MaxParts))
+	}
+
+	p := &MultipartPart{Part: part, reader: part}
+	if s.opts.MaxPartSize > 0 {
+		p.reader = &partLimitReader{r: part, name: part.FormName(), remaining: s.opts.MaxPartSize}
+	}
+
+	if !p.IsFile() {
+		p.ContentType = "text/plain"
+		if mediaType, _, err := mime.ParseMediaType(part.Header.Get(HeaderContentType)); err == nil {
+			p.ContentType = mediaType
+		}
+		return p, nil
+	}
+
+	// sniff the file content and put the sniffed bytes back in front of the part
+	var buf [512]byte
+	n, err := io.ReadFull(p, buf[:])
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	p.reader = io.MultiReader(bytes.NewReader(buf[:n]), p.reader)
+	p.ContentType, _, _ = mime.ParseMediaType(http.DetectContentType(buf[:n]))
+
+	if len(s.opts.AllowedTypes) > 0 && !MatchMediaType(p.ContentType, s.opts.AllowedTypes...) {
+		return nil, NewHTTPError(http.StatusUnsupportedMediaType, fmt.Sprintf("file %q has not allowed content type %q", part.FileName(), p.ContentType))
+	}
+	return p, nil
+}
+
+// Parts returns an iterator over the remaining parts, it stops after the first error.
+func (s *MultipartStream) Parts() iter.Seq2[*MultipartPart, error] {
+	return func(yield func(*MultipartPart, error) bool) {
+		for {
+			part, err := s.Next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if !yield(part, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Each calls fn for every remaining part and returns the first error of the stream or fn.
+// The part data left unread by fn is discarded.
+func (s *MultipartStream) Each(fn func(part *MultipartPart) error) error {
+	for part, err := range s.Parts() {
+		if err != nil {
+			return err
+		}
+		if err = fn(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partLimitReader fails with 413 when the part exceeds its size limit.
+type partLimitReader struct {
+	r         io.Reader
+	name      string
+	remaining int64
+}
+
+func (l *partLimitReader) Read(b []byte) (int, error) {
+	// read one byte more than allowed to detect the overflow
+	if int64(len(b)) > l.remaining+1 {
+		b = b[:l.remaining+1]
+	}
+
+	n, err := l.r.Read(b)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("part %q is too large", l.name))
+	}
+	return n, err
+}
@@ -0,0 +1,191 @@
+package keratin
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMultipartBody writes the parts in order, the names prefixed with "file:" are file parts.
+func newMultipartBody(t *testing.T, parts ...[2]string) (io.Reader, string) {
+	t.Helper()
+
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	for _, part := range parts {
+		var (
+			w   io.Writer
+			err error
+		)
+		if name, ok := strings.CutPrefix(part[0], "file:"); ok {
+			w, err = mw.CreateFormFile(name, name+".bin")
+		} else {
+			w, err = mw.CreateFormField(part[0])
+		}
+		require.NoError(t, err)
+		_, err = io.WriteString(w, part[1])
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+
+	return body, mw.FormDataContentType()
+}
+
+func TestMultipartReader(t *testing.T) {
+	type got struct {
+		name, file, contentType, data string
+	}
+
+	tests := []struct {
+		name     string
+		parts    [][2]string
+		opts     MultipartOptions
+		maxBody  int64
+		want     []got
+		wantCode int
+	}{
+		{
+			name:  "fields and files",
+			parts: [][2]string{{"title", "hello"}, {"file:image", string(uploadTestPNG)}, {"file:doc", "plain text"}},
+			want: []got{
+				{name: "title", contentType: "text/plain", data: "hello"},
+				{name: "image", file: "image.bin", contentType: "image/png", data: string(uploadTestPNG)},
+				{name: "doc", file: "doc.bin", contentType: "text/plain", data: "plain text"},
+			},
+		},
+		{
+			name:  "within limits",
+			parts: [][2]string{{"file:image", string(uploadTestPNG)}},
+			opts:  MultipartOptions{MaxPartSize: int64(len(uploadTestPNG)), MaxParts: 1, AllowedTypes: []string{"image/*"}},
+			want:  []got{{name: "image", file: "image.bin", contentType: "image/png", data: string(uploadTestPNG)}},
+		},
+		{
+			name:     "part too large",
+			parts:    [][2]string{{"title", "hello"}},
+			opts:     MultipartOptions{MaxPartSize: 4},
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "sniffed part too large",
+			parts:    [][2]string{{"file:doc", strings.Repeat("a", 1024)}},
+			opts:     MultipartOptions{MaxPartSize: 100},
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "too many parts",
+			parts:    [][2]string{{"a", "1"}, {"b", "2"}},
+			opts:     MultipartOptions{MaxParts: 1},
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "not allowed type",
+			parts:    [][2]string{{"file:doc", "plain text"}},
+			opts:     MultipartOptions{AllowedTypes: []string{"image/*"}},
+			wantCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:     "body limit",
+			parts:    [][2]string{{"file:doc", strings.Repeat("a", 4096)}},
+			maxBody:  1024,
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var parts []got
+
+			router := NewRouter()
+			route := router.POST("/", func(w http.ResponseWriter, r *http.Request) error {
+				stream, err := MultipartReader(r, tt.opts)
+				if err != nil {
+					return err
+				}
+				return stream.Each(func(part *MultipartPart) error {
+					data, err := io.ReadAll(part)
+					if err != nil {
+						return err
+					}
+					parts = append(parts, got{name: part.FormName(), file: part.FileName(), contentType: part.ContentType, data: string(data)})
+					return nil
+				})
+			})
+			if tt.maxBody > 0 {
+				route.MaxBody(tt.maxBody)
+			}
+
+			body, contentType := newMultipartBody(t, tt.parts...)
+			req := httptest.NewRequest(http.MethodPost, "/", body)
+			req.Header.Set(HeaderContentType, contentType)
+			req.ContentLength = -1
+			rec := httptest.NewRecorder()
+			router.Build().ServeHTTP(rec, req)
+
+			if tt.wantCode != 0 {
+				assert.Equal(t, tt.wantCode, rec.Code, rec.Body.String())
+				return
+			}
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			assert.Equal(t, tt.want, parts)
+		})
+	}
+}
+
+func TestMultipartReader_NotMultipart(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+	req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+
+	_, err := MultipartReader(req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, ErrorStatusCode(err))
+}
+
+func TestMultipartStream_Parts(t *testing.T) {
+	body, contentType := newMultipartBody(t, [2]string{"a", "1"}, [2]string{"b", "2"}, [2]string{"c", "3"})
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set(HeaderContentType, contentType)
+
+	stream, err := MultipartReader(req)
+	require.NoError(t, err)
+
+	var names []string
+	for part, err := range stream.Parts() {
+		require.NoError(t, err)
+		if names = append(names, part.FormName()); len(names) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"a", "b"}, names)
+
+	part, err := stream.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "c", part.FormName())
+	assert.False(t, part.IsFile())
+
+	_, err = stream.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestMultipartStream_Each_Error(t *testing.T) {
+	body, contentType := newMultipartBody(t, [2]string{"a", "1"}, [2]string{"b", "2"})
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set(HeaderContentType, contentType)
+
+	stream, err := MultipartReader(req)
+	require.NoError(t, err)
+
+	calls := 0
+	err = stream.Each(func(part *MultipartPart) error {
+		calls++
+		return fmt.Errorf("stop at %s", part.FormName())
+	})
+	assert.EqualError(t, err, "stop at a")
+	assert.Equal(t, 1, calls)
+}
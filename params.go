@@ -0,0 +1,148 @@
+package keratin
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Param returns the path parameter value, or the optional default value when it is empty.
+func Param(r *http.Request, name string, def ...string) string {
+	if value := r.PathValue(name); value != "" {
+		return value
+	}
+	if len(def) > 0 {
+		return def[0]
+	}
+	return ""
+}
+
+// ParamInt returns the path parameter converted to int.
+//
+// An empty parameter results in the optional default value, or in a 400 error when there is none.
+// Conversion failures are returned as 400 errors.
+func ParamInt(r *http.Request, name string, def ...int) (int, error) {
+	return pathParam(r, name, strconv.Atoi, def)
+}
+
+// ParamInt64 returns the path parameter converted to int64. See [ParamInt].
+func ParamInt64(r *http.Request, name string, def ...int64) (int64, error) {
+	return pathParam(r, name, func(s string) (int64, error) {
+		return strconv.ParseInt(s, 10, 64)
+	}, def)
+}
+
+// ParamUUID returns the path parameter parsed as UUID. See [ParamInt].
+func ParamUUID(r *http.Request, name string, def ...uuid.UUID) (uuid.UUID, error) {
+	return pathParam(r, name, uuid.Parse, def)
+}
+
+// ParamTime returns the path parameter parsed as time using the layout,
+// e.g. [time.RFC3339] or [time.DateOnly]. See [ParamInt].
+func ParamTime(r *http.Request, name, layout string, def ...time.Time) (time.Time, error) {
+	return pathParam(r, name, func(s string) (time.Time, error) {
+		return time.Parse(layout, s)
+	}, def)
+}
+
+// Wildcard returns the remainder matched by the catch-all wildcard of the route, e.g. {rest...}
+// in "GET /files/{rest...}", split into URL-decoded segments. The empty segments are dropped.
+// Unlike [http.Request.PathValue], an encoded slash ("%2F") does not split a segment.
+//
+// The segments are safe to join into a file path or an upstream URL: ".", ".." and the segments
+// containing a slash, a backslash or a NUL byte are rejected with a 400 error.
+// It returns nil when the route has no catch-all wildcard.
+func Wildcard(r *http.Request) ([]string, error) {
+	remainder, ok := wildcardRemainder(r)
+	if !ok {
+		return nil, nil
+	}
+
+	var segments []string
+	for raw := range strings.SplitSeq(remainder, "/") {
+		if raw == "" {
+			continue
+		}
+
+		segment, err := url.PathUnescape(raw)
+		if err != nil {
+			return nil, ErrBadRequest.Wrap(fmt.Errorf("invalid wildcard segment %q: %w", raw, err))
+		}
+		if segment == "." || segment == ".." || strings.ContainsAny(segment, "/\\\x00") {
+			return nil, ErrBadRequest.Wrap(fmt.Errorf("invalid wildcard segment %q", raw))
+		}
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+// WildcardPath returns the [Wildcard] segments joined with slashes, a relative path valid
+// for [io/fs.FS] (see [io/fs.ValidPath]), or "." when the remainder is empty.
+func WildcardPath(r *http.Request) (string, error) {
+	segments, err := Wildcard(r)
+	if err != nil || len(segments) == 0 {
+		return ".", err
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// wildcardRemainder returns the escaped request path matched by the catch-all wildcard
+// of the request pattern.
+func wildcardRemainder(r *http.Request) (string, bool) {
+	pattern := r.Pattern
+	if _, rest, ok := strings.Cut(pattern, " "); ok {
+		pattern = rest
+	}
+	if i := strings.IndexByte(pattern, '/'); i >= 0 {
+		pattern = pattern[i:]
+	} else {
+		return "", false
+	}
+
+	end := strings.LastIndex(pattern, "...}")
+	if end < 0 {
+		return "", false
+	}
+	start := strings.LastIndexByte(pattern[:end], '{')
+	if start < 0 {
+		return "", false
+	}
+
+	// the wildcard is the last segment, skip as many segments of the request path as precede it
+	path := r.URL.EscapedPath()
+	for range strings.Count(pattern[:start], "/") {
+		i := strings.IndexByte(path, '/')
+		if i < 0 {
+			return "", true
+		}
+		path = path[i+1:]
+	}
+	return path, true
+}
+
+func pathParam[T any](r *http.Request, name string, parse func(string) (T, error), def []T) (T, error) {
+	return parseParam("path", name, r.PathValue(name), parse, def)
+}
+
+func parseParam[T any](kind, name, value string, parse func(string) (T, error), def []T) (T, error) {
+	if value == "" {
+		if len(def) > 0 {
+			return def[0], nil
+		}
+
+		var zero T
+		return zero, ErrBadRequest.Wrap(fmt.Errorf("missing %s parameter %q", kind, name))
+	}
+
+	v, err := parse(value)
+	if err != nil {
+		var zero T
+		return zero, ErrBadRequest.Wrap(fmt.Errorf("invalid %s parameter %q: %w", kind, name, err))
+	}
+	return v, nil
+}
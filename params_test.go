@@ -0,0 +1,155 @@
+package keratin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newParamRequest(params map[string]string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for name, value := range params {
+		r.SetPathValue(name, value)
+	}
+	return r
+}
+
+func TestParam(t *testing.T) {
+	r := newParamRequest(map[string]string{"slug": "hello"})
+
+	assert.Equal(t, "hello", Param(r, "slug"))
+	assert.Equal(t, "hello", Param(r, "slug", "default"))
+	assert.Empty(t, Param(r, "missing"))
+	assert.Equal(t, "default", Param(r, "missing", "default"))
+}
+
+func TestParamInt(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]string
+		def     []int
+		want    int
+		wantErr bool
+	}{
+		{name: "valid", params: map[string]string{"id": "42"}, want: 42},
+		{name: "negative", params: map[string]string{"id": "-1"}, want: -1},
+		{name: "invalid", params: map[string]string{"id": "abc"}, wantErr: true},
+		{name: "invalid with default", params: map[string]string{"id": "abc"}, def: []int{7}, wantErr: true},
+		{name: "missing", wantErr: true},
+		{name: "missing with default", def: []int{7}, want: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParamInt(newParamRequest(tt.params), "id", tt.def...)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, http.StatusBadRequest, HTTPErrorStatusCode(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParamInt64(t *testing.T) {
+	got, err := ParamInt64(newParamRequest(map[string]string{"id": "9223372036854775807"}), "id")
+	require.NoError(t, err)
+	assert.Equal(t, int64(9223372036854775807), got)
+
+	_, err = ParamInt64(newParamRequest(map[string]string{"id": "9223372036854775808"}), "id")
+	assert.ErrorContains(t, err, `invalid path parameter "id"`)
+}
+
+func TestParamUUID(t *testing.T) {
+	id := uuid.New()
+
+	got, err := ParamUUID(newParamRequest(map[string]string{"id": id.String()}), "id")
+	require.NoError(t, err)
+	assert.Equal(t, id, got)
+
+	_, err = ParamUUID(newParamRequest(map[string]string{"id": "not-a-uuid"}), "id")
+	assert.Equal(t, http.StatusBadRequest, HTTPErrorStatusCode(err))
+
+	got, err = ParamUUID(newParamRequest(nil), "id", uuid.Nil)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, got)
+}
+
+func TestParamTime(t *testing.T) {
+	got, err := ParamTime(newParamRequest(map[string]string{"day": "2024-02-29"}), "day", time.DateOnly)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), got)
+
+	_, err = ParamTime(newParamRequest(map[string]string{"day": "2023-02-29"}), "day", time.DateOnly)
+	assert.Equal(t, http.StatusBadRequest, HTTPErrorStatusCode(err))
+
+	_, err = ParamTime(newParamRequest(nil), "day", time.DateOnly)
+	assert.ErrorContains(t, err, `missing path parameter "day"`)
+}
+
+func TestWildcard(t *testing.T) {
+	type result struct {
+		Segments []string `json:"segments"`
+		Path     string   `json:"path"`
+	}
+
+	router := NewRouter(WithBasePath("/api"))
+	handler := func(w http.ResponseWriter, r *http.Request) error {
+		segments, err := Wildcard(r)
+		if err != nil {
+			return err
+		}
+		path, err := WildcardPath(r)
+		if err != nil {
+			return err
+		}
+		return JSON(w, http.StatusOK, result{Segments: segments, Path: path})
+	}
+	router.GET("/files/{bucket}/{rest...}", handler)
+	router.GET("/all/{rest...}", handler)
+	router.GET("/plain/{id}", handler)
+	h := router.Build()
+
+	tests := []struct {
+		name     string
+		target   string
+		want     result
+		wantCode int
+	}{
+		{name: "segments", target: "/api/files/b/docs/a.txt", want: result{Segments: []string{"docs", "a.txt"}, Path: "docs/a.txt"}},
+		{name: "decoded", target: "/api/files/b/my%20docs/%C3%A9.txt", want: result{Segments: []string{"my docs", "é.txt"}, Path: "my docs/é.txt"}},
+		{name: "trailing slash", target: "/api/files/b/docs/", want: result{Segments: []string{"docs"}, Path: "docs"}},
+		{name: "empty", target: "/api/all/", want: result{Path: "."}},
+		{name: "no wildcard", target: "/api/plain/1", want: result{Path: "."}},
+		{name: "encoded slash", target: "/api/files/b/..%2Fsecret", wantCode: http.StatusBadRequest},
+		{name: "encoded dots", target: "/api/all/%2E%2E/secret", wantCode: http.StatusBadRequest},
+		{name: "backslash", target: "/api/all/..%5Csecret", wantCode: http.StatusBadRequest},
+		{name: "nul", target: "/api/all/a%00b", wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if tt.wantCode != 0 {
+				assert.Equal(t, tt.wantCode, w.Code)
+				return
+			}
+
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var got result
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
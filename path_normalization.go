@@ -0,0 +1,148 @@
+package keratin
+
+import (
+	"net/http"
+	"strings"
+)
+
+// PathNormalization configures how request paths are normalized before the route matching,
+// see [WithPathNormalization].
+type PathNormalization struct {
+	// Lowercase lowercases the path, so the static routes match case-insensitively.
+	// Note the path parameters are lowercased too.
+	Lowercase bool `env:"LOWERCASE" json:"lowercase,omitempty" yaml:"lowercase,omitempty"`
+
+	// CollapseSlashes replaces the duplicate slashes with a single one, e.g. "/a//b" becomes "/a/b".
+	CollapseSlashes bool `env:"COLLAPSE_SLASHES" json:"collapseSlashes,omitempty" yaml:"collapseSlashes,omitempty"`
+
+	// CleanDots resolves the "." and ".." segments, e.g. "/a/./b/../c" becomes "/a/c".
+	CleanDots bool `env:"CLEAN_DOTS" json:"cleanDots,omitempty" yaml:"cleanDots,omitempty"`
+
+	// Redirect redirects the client to the normalized path instead of rewriting the request path.
+	// GET and HEAD requests are redirected with 301 Moved Permanently, other methods with 308 Permanent Redirect.
+	Redirect bool `env:"REDIRECT" json:"redirect,omitempty" yaml:"redirect,omitempty"`
+}
+
+// WithPathNormalization normalizes the request paths before they are matched against the routes,
+// as the [http.ServeMux] patterns are strict and clients often send messy paths.
+//
+// The normalization runs before all the interceptors and middlewares.
+func WithPathNormalization(opts PathNormalization) Option {
+	return func(router *Router) {
+		if opts.Lowercase || opts.CollapseSlashes || opts.CleanDots {
+			router.pathNormalization = &opts
+		} else {
+			router.pathNormalization = nil
+		}
+	}
+}
+
+// normalize returns the normalized path.
+func (n *PathNormalization) normalize(path string) string {
+	if n.CleanDots {
+		path = cleanDots(path)
+	}
+	if n.CollapseSlashes {
+		path = collapseSlashes(path)
+	}
+	if n.Lowercase {
+		path = strings.ToLower(path)
+	}
+	return path
+}
+
+// handler wraps next with the path normalization.
+func (n *PathNormalization) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		path := n.normalize(req.URL.Path)
+		if path == req.URL.Path {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		u := *req.URL
+		u.Path = path
+		if req.URL.RawPath != "" {
+			// the escaped path is used only if it is still a valid encoding of the path, see url.URL.EscapedPath
+			u.RawPath = n.normalize(req.URL.RawPath)
+		}
+
+		if n.Redirect {
+			code := http.StatusPermanentRedirect
+			if req.Method == http.MethodGet || req.Method == http.MethodHead {
+				code = http.StatusMovedPermanently
+			}
+
+			// "//host" and "/\host" are network-path references to another host for the browsers
+			u.Path = "/" + strings.TrimLeft(u.Path, `/\`)
+			if u.RawPath != "" {
+				u.RawPath = "/" + strings.TrimLeft(u.RawPath, `/\`)
+			}
+			http.Redirect(w, req, u.RequestURI(), code)
+			return
+		}
+
+		r2 := new(http.Request)
+		*r2 = *req
+		r2.URL = &u
+		r2.RequestURI = u.RequestURI()
+
+		next.ServeHTTP(w, r2)
+	})
+}
+
+func collapseSlashes(path string) string {
+	if !strings.Contains(path, "//") {
+		return path
+	}
+
+	var b strings.Builder
+	b.Grow(len(path))
+
+	for i := 0; i < len(path); i++ {
+		if path[i] == '/' && i > 0 && path[i-1] == '/' {
+			continue
+		}
+		b.WriteByte(path[i])
+	}
+
+	return b.String()
+}
+
+// cleanDots resolves the dot segments per RFC 3986 section 5.2.4,
+// unlike [path.Clean] it keeps the empty segments and the trailing slash.
+func cleanDots(path string) string {
+	if !strings.Contains(path, ".") {
+		return path
+	}
+
+	segments := strings.Split(path, "/")
+	out := make([]string, 0, len(segments))
+
+	for i, segment := range segments {
+		last := i == len(segments)-1
+
+		switch segment {
+		case ".":
+			if last {
+				out = append(out, "")
+			}
+		case "..":
+			// the first segment is always empty for absolute paths, keep it
+			if len(out) > 1 {
+				out = out[:len(out)-1]
+			}
+			if last {
+				out = append(out, "")
+			}
+		default:
+			out = append(out, segment)
+		}
+	}
+
+	if len(out) == 1 && out[0] == "" {
+		return "/"
+	}
+
+	return strings.Join(out, "/")
+}
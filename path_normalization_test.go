@@ -0,0 +1,116 @@
+package keratin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathNormalization_normalize(t *testing.T) {
+	all := &PathNormalization{Lowercase: true, CollapseSlashes: true, CleanDots: true}
+
+	tests := []struct {
+		name string
+		n    *PathNormalization
+		path string
+		want string
+	}{
+		{name: "unchanged", n: all, path: "/users/1", want: "/users/1"},
+		{name: "lowercase", n: &PathNormalization{Lowercase: true}, path: "/Users/ABC", want: "/users/abc"},
+		{name: "collapse slashes", n: &PathNormalization{CollapseSlashes: true}, path: "//users///1/", want: "/users/1/"},
+		{name: "clean dots", n: &PathNormalization{CleanDots: true}, path: "/a/./b/../c", want: "/a/c"},
+		{name: "clean dots keeps trailing slash", n: &PathNormalization{CleanDots: true}, path: "/a/b/..", want: "/a/"},
+		{name: "clean dots above root", n: &PathNormalization{CleanDots: true}, path: "/../../a", want: "/a"},
+		{name: "clean dots keeps empty segments", n: &PathNormalization{CleanDots: true}, path: "/a//./b", want: "/a//b"},
+		{name: "clean dots keeps file names", n: &PathNormalization{CleanDots: true}, path: "/a/file.txt", want: "/a/file.txt"},
+		{name: "all", n: all, path: "/API//V1/./Users/../items", want: "/api/v1/items"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.n.normalize(tt.path))
+		})
+	}
+}
+
+func TestWithPathNormalization(t *testing.T) {
+	newRouter := func(redirect bool) http.Handler {
+		router := NewRouter(WithPathNormalization(PathNormalization{
+			Lowercase:       true,
+			CollapseSlashes: true,
+			CleanDots:       true,
+			Redirect:        redirect,
+		}))
+		router.GET("/api/items", func(w http.ResponseWriter, r *http.Request) error {
+			return TextPlain(w, http.StatusOK, r.URL.RequestURI())
+		})
+		router.POST("/api/items", func(w http.ResponseWriter, r *http.Request) error {
+			return TextPlain(w, http.StatusCreated, r.URL.RequestURI())
+		})
+		return router.Build()
+	}
+
+	t.Run("rewrite", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newRouter(false).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/API//Items?q=1", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "/api/items?q=1", w.Body.String())
+	})
+
+	t.Run("redirect", func(t *testing.T) {
+		handler := newRouter(true)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/API//Items?q=1", nil))
+		assert.Equal(t, http.StatusMovedPermanently, w.Code)
+		assert.Equal(t, "/api/items?q=1", w.Header().Get(HeaderLocation))
+
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/./items", nil))
+		assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+		assert.Equal(t, "/api/items", w.Header().Get(HeaderLocation))
+
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/items", nil))
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("redirect stays on the host", func(t *testing.T) {
+		tests := []struct {
+			n        PathNormalization
+			target   string
+			location string
+		}{
+			{n: PathNormalization{Lowercase: true, Redirect: true}, target: "//EVIL.com/", location: "/evil.com/"},
+			{n: PathNormalization{CleanDots: true, Redirect: true}, target: "/..//evil.com/", location: "/evil.com/"},
+			{n: PathNormalization{CleanDots: true, Redirect: true}, target: "/./\\evil.com/", location: "/evil.com/"},
+		}
+
+		for _, tt := range tests {
+			w := httptest.NewRecorder()
+			NewRouter(WithPathNormalization(tt.n)).Build().ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			assert.Equal(t, http.StatusMovedPermanently, w.Code, tt.target)
+			assert.Equal(t, tt.location, w.Header().Get(HeaderLocation), tt.target)
+		}
+	})
+
+	t.Run("rewrite keeps encoded segments", func(t *testing.T) {
+		router := NewRouter(WithPathNormalization(PathNormalization{CollapseSlashes: true}))
+		router.GET("/files/{name}", func(w http.ResponseWriter, r *http.Request) error {
+			return TextPlain(w, http.StatusOK, r.PathValue("name")+" "+r.URL.EscapedPath())
+		})
+
+		w := httptest.NewRecorder()
+		router.Build().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "//files/a%2Fb", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "a/b /files/a%2Fb", w.Body.String())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		router := NewRouter(WithPathNormalization(PathNormalization{Redirect: true}))
+		assert.Nil(t, router.pathNormalization)
+	})
+}
@@ -0,0 +1,56 @@
+package keratin
+
+import (
+	"net/http"
+	"slices"
+)
+
+// WithPreflightFastPath enables the fast path of the CORS preflight requests (see [IsPreflightRequest]):
+// they run only through the router pre middlewares ([Router.PreHTTP], [Router.Pre]), so a CORS middleware
+// registered there answers them, and skip the route matching with all the group and route middlewares,
+// e.g. auth, sessions, rate limiting and logging. The preflight requests not answered by the pre middlewares
+// are responded with 204 No Content.
+//
+// Use [WithPreflightSkip] to also skip some pre middlewares.
+func WithPreflightFastPath(enabled bool) Option {
+	return func(router *Router) {
+		router.preflightFastPath = enabled
+	}
+}
+
+// WithPreflightSkip sets the IDs of the pre middlewares (e.g. a request logger) skipped on
+// the preflight fast path, see [WithPreflightFastPath].
+func WithPreflightSkip(ids ...string) Option {
+	return func(router *Router) {
+		router.preflightSkip = append(router.preflightSkip, ids...)
+	}
+}
+
+// IsPreflightRequest reports whether the request is a CORS preflight request, aka. an OPTIONS request
+// with the Origin and Access-Control-Request-Method headers.
+func IsPreflightRequest(r *http.Request) bool {
+	return r.Method == http.MethodOptions &&
+		r.Header.Get(HeaderOrigin) != "" &&
+		r.Header.Get(HeaderAccessControlRequestMethod) != ""
+}
+
+// preflightHandler builds the handler of the preflight fast path.
+func (r *Router) preflightHandler() http.Handler {
+	skip := func(mw *Middleware[Handler]) bool {
+		return slices.Contains(r.preflightSkip, mw.ID)
+	}
+	skipHTTP := func(mw *Middleware[http.Handler]) bool {
+		return slices.Contains(r.preflightSkip, mw.ID)
+	}
+
+	handler := slices.DeleteFunc(slices.Clone(r.PreMiddlewares), skip).build(HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}))
+
+	return slices.DeleteFunc(slices.Clone(r.HTTPMiddlewares), skipHTTP).build(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := handler.ServeHTTP(w, req); err != nil {
+			r.errorHandler(w, req, err)
+		}
+	}))
+}
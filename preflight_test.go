@@ -0,0 +1,135 @@
+package keratin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPreflightRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		header map[string]string
+		want   bool
+	}{
+		{
+			name:   "preflight",
+			method: http.MethodOptions,
+			header: map[string]string{HeaderOrigin: "https://example.com", HeaderAccessControlRequestMethod: http.MethodPut},
+			want:   true,
+		},
+		{
+			name:   "options without request method",
+			method: http.MethodOptions,
+			header: map[string]string{HeaderOrigin: "https://example.com"},
+		},
+		{
+			name:   "options without origin",
+			method: http.MethodOptions,
+			header: map[string]string{HeaderAccessControlRequestMethod: http.MethodPut},
+		},
+		{
+			name:   "get",
+			method: http.MethodGet,
+			header: map[string]string{HeaderOrigin: "https://example.com", HeaderAccessControlRequestMethod: http.MethodPut},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			assert.Equal(t, tt.want, IsPreflightRequest(req))
+		})
+	}
+}
+
+func TestWithPreflightFastPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   []Option
+		cors      bool
+		wantCode  int
+		wantCalls []string
+	}{
+		{
+			name:      "disabled",
+			cors:      true,
+			wantCode:  http.StatusUnauthorized,
+			wantCalls: []string{"cors", "logger", "auth"},
+		},
+		{
+			name:      "enabled",
+			options:   []Option{WithPreflightFastPath(true)},
+			cors:      true,
+			wantCode:  http.StatusNoContent,
+			wantCalls: []string{"cors", "logger"},
+		},
+		{
+			name:      "enabled with skipped pre middleware",
+			options:   []Option{WithPreflightFastPath(true), WithPreflightSkip("logger")},
+			cors:      true,
+			wantCode:  http.StatusNoContent,
+			wantCalls: []string{"cors"},
+		},
+		{
+			name:      "enabled without cors",
+			options:   []Option{WithPreflightFastPath(true)},
+			wantCode:  http.StatusNoContent,
+			wantCalls: []string{"logger"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+
+			router := NewRouter(tt.options...)
+			router.Pre(&Middleware[Handler]{ID: "logger", Func: func(next Handler) Handler {
+				return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+					calls = append(calls, "logger")
+					return next.ServeHTTP(w, r)
+				})
+			}})
+			if tt.cors {
+				router.PreHTTPFunc(func(next http.Handler) http.Handler {
+					return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						calls = append(calls, "cors")
+						w.Header().Set(HeaderAccessControlAllowOrigin, "*")
+						next.ServeHTTP(w, r)
+					})
+				})
+			}
+			router.UseFunc(func(next Handler) Handler {
+				return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+					calls = append(calls, "auth")
+					return ErrUnauthorized
+				})
+			})
+			router.Any("/users", func(w http.ResponseWriter, r *http.Request) error {
+				return nil
+			})
+
+			req := httptest.NewRequest(http.MethodOptions, "/users", nil)
+			req.Header.Set(HeaderOrigin, "https://example.com")
+			req.Header.Set(HeaderAccessControlRequestMethod, http.MethodPut)
+			rec := httptest.NewRecorder()
+			router.Build().ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Equal(t, tt.wantCalls, calls)
+
+			// other requests still run the full chain
+			calls = nil
+			rec = httptest.NewRecorder()
+			router.Build().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Contains(t, calls, "auth")
+		})
+	}
+}
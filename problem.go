@@ -0,0 +1,152 @@
+package keratin
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+
+	"github.com/gowool/keratin/internal"
+)
+
+// ProblemDetails is a "problem detail" object as defined by RFC 9457.
+// See https://www.rfc-editor.org/rfc/rfc9457
+type ProblemDetails struct {
+	// Type is a URI reference that identifies the problem type, defaults to "about:blank".
+	Type string
+	// Title is a short, human-readable summary of the problem type.
+	Title string
+	// Status is the HTTP status code generated by the origin server.
+	Status int
+	// Detail is a human-readable explanation specific to this occurrence of the problem.
+	Detail string
+	// Instance is a URI reference that identifies the specific occurrence of the problem.
+	Instance string
+	// Extensions are additional members serialized next to the standard ones.
+	Extensions map[string]any
+}
+
+// MarshalJSON implements [json.Marshaler] flattening the extension members into the object.
+func (p ProblemDetails) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(p.Extensions)+5)
+	maps.Copy(m, p.Extensions)
+
+	if p.Type != "" {
+		m["type"] = p.Type
+	}
+	if p.Title != "" {
+		m["title"] = p.Title
+	}
+	if p.Status != 0 {
+		m["status"] = p.Status
+	}
+	if p.Detail != "" {
+		m["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		m["instance"] = p.Instance
+	}
+
+	return json.Marshal(m)
+}
+
+// ProblemTyper can be implemented by errors to provide the problem "type" URI.
+type ProblemTyper interface {
+	ProblemType() string
+}
+
+// ProblemExtender can be implemented by errors to provide custom problem extension members.
+type ProblemExtender interface {
+	ProblemExtensions() map[string]any
+}
+
+// NewProblemDetails builds a [ProblemDetails] object describing err in the scope of r.
+// The title and the detail are translated with the request [Translator], if any.
+func NewProblemDetails(r *http.Request, err error) ProblemDetails {
+	code := HTTPErrorStatusCode(err)
+
+	p := ProblemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(code),
+		Status:   code,
+		Instance: r.URL.Path,
+	}
+
+	if t := TranslatorFromContext(r.Context()); t != nil {
+		p.Title = t.Translate(p.Title)
+	}
+
+	if httpErr, ok := errors.AsType[*HTTPError](err); ok {
+		httpErr = translateError(r, httpErr)
+		if httpErr.Message != "" && httpErr.Message != p.Title {
+			p.Detail = httpErr.Message
+		}
+		if httpErr.Data != nil || httpErr.ErrorCode != "" || httpErr.Details != nil {
+			p.Extensions = make(map[string]any, 3)
+		}
+		if httpErr.Data != nil {
+			p.Extensions["data"] = httpErr.Data
+		}
+		if httpErr.ErrorCode != "" {
+			p.Extensions["error_code"] = httpErr.ErrorCode
+		}
+		if httpErr.Details != nil {
+			p.Extensions["details"] = httpErr.Details
+		}
+	}
+
+	var typer ProblemTyper
+	if errors.As(err, &typer) {
+		p.Type = typer.ProblemType()
+	}
+
+	var extender ProblemExtender
+	if errors.As(err, &extender) {
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]any)
+		}
+		maps.Copy(p.Extensions, extender.ProblemExtensions())
+	}
+
+	return p
+}
+
+// ProblemDetailsErrorHandler is an [ErrorHandlerFunc] rendering errors as RFC 9457
+// "application/problem+json" documents when the client accepts JSON, and as plain text otherwise.
+// It sets the Retry-After header for the errors implementing [RetryAfterer].
+//
+// Example:
+//
+//	router := keratin.NewRouter(keratin.WithErrorHandler(keratin.ProblemDetailsErrorHandler))
+func ProblemDetailsErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	if ResponseCommitted(w) {
+		return
+	}
+
+	setRetryAfter(w.Header(), err)
+
+	p := NewProblemDetails(r, err)
+
+	switch NegotiateContentType(r, MIMETextPlain, MIMEApplicationProblemJSON, MIMEApplicationJSON) {
+	case MIMEApplicationProblemJSON, MIMEApplicationJSON:
+		if err := writeProblem(w, p); err == nil || ResponseCommitted(w) {
+			return
+		}
+	}
+
+	msg := p.Detail
+	if msg == "" {
+		msg = p.Title
+	}
+
+	http.Error(w, msg, p.Status)
+}
+
+func writeProblem(w http.ResponseWriter, p ProblemDetails) error {
+	w = newDelayedStatusWriter(w)
+
+	w.Header().Set(HeaderContentType, MIMEApplicationProblemJSON)
+	w.WriteHeader(p.Status)
+
+	return internal.MarshalJSON(w, p, "")
+}
@@ -0,0 +1,158 @@
+package keratin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type problemTestError struct{}
+
+func (problemTestError) Error() string       { return "out of credit" }
+func (problemTestError) StatusCode() int     { return http.StatusForbidden }
+func (problemTestError) ProblemType() string { return "https://example.com/probs/out-of-credit" }
+func (problemTestError) ProblemExtensions() map[string]any {
+	return map[string]any{"balance": 30}
+}
+
+func TestProblemDetails_MarshalJSON(t *testing.T) {
+	p := ProblemDetails{
+		Type:       "about:blank",
+		Title:      "Not Found",
+		Status:     http.StatusNotFound,
+		Extensions: map[string]any{"trace": "abc", "title": "ignored"},
+	}
+
+	b, err := json.Marshal(p)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"about:blank","title":"Not Found","status":404,"trace":"abc"}`, string(b))
+}
+
+func TestNewProblemDetails(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/accounts/1", nil)
+
+	tests := []struct {
+		name string
+		err  error
+		want ProblemDetails
+	}{
+		{
+			name: "generic error",
+			err:  errors.New("boom"),
+			want: ProblemDetails{Type: "about:blank", Title: "Internal Server Error", Status: 500, Instance: "/accounts/1"},
+		},
+		{
+			name: "HTTPError with message and data",
+			err:  NewHTTPError(http.StatusBadRequest, "invalid id").SetData("id"),
+			want: ProblemDetails{
+				Type:       "about:blank",
+				Title:      "Bad Request",
+				Status:     400,
+				Detail:     "invalid id",
+				Instance:   "/accounts/1",
+				Extensions: map[string]any{"data": "id"},
+			},
+		},
+		{
+			name: "HTTPError with error code and details",
+			err:  NewHTTPErrorWithCode(http.StatusNotFound, "user_not_found", "user not found").WithDetails("42"),
+			want: ProblemDetails{
+				Type:       "about:blank",
+				Title:      "Not Found",
+				Status:     404,
+				Detail:     "user not found",
+				Instance:   "/accounts/1",
+				Extensions: map[string]any{"error_code": "user_not_found", "details": "42"},
+			},
+		},
+		{
+			name: "error with type and extensions",
+			err:  fmt.Errorf("charge: %w", problemTestError{}),
+			want: ProblemDetails{
+				Type:       "https://example.com/probs/out-of-credit",
+				Title:      "Forbidden",
+				Status:     403,
+				Instance:   "/accounts/1",
+				Extensions: map[string]any{"balance": 30},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewProblemDetails(r, tt.err))
+		})
+	}
+}
+
+func TestProblemDetailsErrorHandler(t *testing.T) {
+	tests := []struct {
+		name            string
+		accept          string
+		err             error
+		wantCode        int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "problem json",
+			accept:          MIMEApplicationProblemJSON,
+			err:             ErrNotFound,
+			wantCode:        http.StatusNotFound,
+			wantContentType: MIMEApplicationProblemJSON,
+			wantBody:        `{"type":"about:blank","title":"Not Found","status":404,"instance":"/test"}`,
+		},
+		{
+			name:            "application json",
+			accept:          MIMEApplicationJSON,
+			err:             problemTestError{},
+			wantCode:        http.StatusForbidden,
+			wantContentType: MIMEApplicationProblemJSON,
+			wantBody:        `{"type":"https://example.com/probs/out-of-credit","title":"Forbidden","status":403,"instance":"/test","balance":30}`,
+		},
+		{
+			name:            "plain text",
+			accept:          MIMETextHTML,
+			err:             NewHTTPError(http.StatusConflict, "already exists"),
+			wantCode:        http.StatusConflict,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "already exists\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/test", nil)
+			r.Header.Set(HeaderAccept, tt.accept)
+			w := httptest.NewRecorder()
+
+			ProblemDetailsErrorHandler(w, r, tt.err)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, tt.wantContentType, w.Header().Get(HeaderContentType))
+			if tt.wantContentType == MIMEApplicationProblemJSON {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			} else {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestProblemDetailsErrorHandler_CommittedResponse(t *testing.T) {
+	res := &response{}
+	rec := httptest.NewRecorder()
+	res.reset(rec)
+	res.WriteHeader(http.StatusOK)
+
+	ProblemDetailsErrorHandler(res, httptest.NewRequest(http.MethodGet, "/", nil), ErrNotFound)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
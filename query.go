@@ -0,0 +1,86 @@
+package keratin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gowool/keratin/internal"
+)
+
+// Query returns the first value of the query parameter, or the optional default value when it is empty.
+func Query(r *http.Request, name string, def ...string) string {
+	if value := r.URL.Query().Get(name); value != "" {
+		return value
+	}
+	if len(def) > 0 {
+		return def[0]
+	}
+	return ""
+}
+
+// QueryInt returns the query parameter converted to int.
+//
+// An empty parameter results in the optional default value, or in a 400 error when there is none.
+// Conversion failures are returned as 400 errors.
+func QueryInt(r *http.Request, name string, def ...int) (int, error) {
+	return queryParam(r, name, strconv.Atoi, def)
+}
+
+// QueryBool returns the query parameter converted to bool, see [strconv.ParseBool] and [QueryInt].
+func QueryBool(r *http.Request, name string, def ...bool) (bool, error) {
+	return queryParam(r, name, strconv.ParseBool, def)
+}
+
+// QueryTime returns the query parameter parsed as time using the layout. See [QueryInt].
+func QueryTime(r *http.Request, name, layout string, def ...time.Time) (time.Time, error) {
+	return queryParam(r, name, func(s string) (time.Time, error) {
+		return time.Parse(layout, s)
+	}, def)
+}
+
+// QuerySlice returns all values of the query parameter.
+// Both repeated (?tag=a&tag=b) and comma separated (?tag=a,b) values are supported.
+// Empty values are dropped.
+func QuerySlice(r *http.Request, name string, def ...string) []string {
+	var values []string
+	for _, value := range r.URL.Query()[name] {
+		for item := range strings.SplitSeq(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+
+	if len(values) == 0 && len(def) > 0 {
+		return def
+	}
+	return values
+}
+
+// BindQuery binds the query string into the struct pointed to by dst using `query` tags.
+//
+// Example:
+//
+//	type ListParams struct {
+//		Page int      `query:"page"`
+//		Tags []string `query:"tag"`
+//	}
+//
+// Conversion failures are returned as 400 errors.
+func BindQuery(r *http.Request, dst any) error {
+	if err := internal.BindValues(dst, r.URL.Query(), "query"); err != nil {
+		if bindErr, ok := errors.AsType[*internal.BindError](err); ok {
+			return ErrBadRequest.Wrap(fmt.Errorf("invalid query parameter %q: %w", bindErr.Name, bindErr.Err))
+		}
+		return err
+	}
+	return nil
+}
+
+func queryParam[T any](r *http.Request, name string, parse func(string) (T, error), def []T) (T, error) {
+	return parseParam("query", name, r.URL.Query().Get(name), parse, def)
+}
@@ -0,0 +1,148 @@
+package keratin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuery(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/?q=hello&empty=", nil)
+
+	assert.Equal(t, "hello", Query(r, "q"))
+	assert.Equal(t, "hello", Query(r, "q", "default"))
+	assert.Equal(t, "default", Query(r, "empty", "default"))
+	assert.Equal(t, "default", Query(r, "missing", "default"))
+	assert.Empty(t, Query(r, "missing"))
+}
+
+func TestQueryInt(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		def     []int
+		want    int
+		wantErr bool
+	}{
+		{name: "valid", query: "page=2", want: 2},
+		{name: "invalid", query: "page=two", wantErr: true},
+		{name: "missing", wantErr: true},
+		{name: "missing with default", def: []int{1}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := QueryInt(httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil), "page", tt.def...)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, http.StatusBadRequest, HTTPErrorStatusCode(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestQueryBool(t *testing.T) {
+	got, err := QueryBool(httptest.NewRequest(http.MethodGet, "/?active=true", nil), "active")
+	require.NoError(t, err)
+	assert.True(t, got)
+
+	_, err = QueryBool(httptest.NewRequest(http.MethodGet, "/?active=maybe", nil), "active")
+	assert.ErrorContains(t, err, `invalid query parameter "active"`)
+
+	got, err = QueryBool(httptest.NewRequest(http.MethodGet, "/", nil), "active", false)
+	require.NoError(t, err)
+	assert.False(t, got)
+}
+
+func TestQueryTime(t *testing.T) {
+	got, err := QueryTime(httptest.NewRequest(http.MethodGet, "/?since=2024-01-02", nil), "since", time.DateOnly)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), got)
+
+	_, err = QueryTime(httptest.NewRequest(http.MethodGet, "/?since=yesterday", nil), "since", time.DateOnly)
+	assert.Equal(t, http.StatusBadRequest, HTTPErrorStatusCode(err))
+}
+
+func TestQuerySlice(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		def   []string
+		want  []string
+	}{
+		{name: "repeated", query: "tag=a&tag=b", want: []string{"a", "b"}},
+		{name: "comma separated", query: "tag=a,+b,,c", want: []string{"a", "b", "c"}},
+		{name: "mixed", query: "tag=a,b&tag=c", want: []string{"a", "b", "c"}},
+		{name: "missing", want: nil},
+		{name: "missing with default", def: []string{"x"}, want: []string{"x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := QuerySlice(httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil), "tag", tt.def...)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBindQuery(t *testing.T) {
+	type Pagination struct {
+		Page  int `query:"page"`
+		Limit int `query:"limit"`
+	}
+
+	type params struct {
+		Pagination
+		Search  string        `query:"q"`
+		Tags    []string      `query:"tag"`
+		Active  *bool         `query:"active"`
+		Timeout time.Duration `query:"timeout"`
+		Ignored string        `query:"-"`
+	}
+
+	t.Run("binds values", func(t *testing.T) {
+		var p params
+		r := httptest.NewRequest(http.MethodGet, "/?page=2&limit=10&q=go&tag=a&tag=b&active=true&timeout=5s&Ignored=x", nil)
+
+		require.NoError(t, BindQuery(r, &p))
+		assert.Equal(t, 2, p.Page)
+		assert.Equal(t, 10, p.Limit)
+		assert.Equal(t, "go", p.Search)
+		assert.Equal(t, []string{"a", "b"}, p.Tags)
+		require.NotNil(t, p.Active)
+		assert.True(t, *p.Active)
+		assert.Equal(t, 5*time.Second, p.Timeout)
+		assert.Empty(t, p.Ignored)
+	})
+
+	t.Run("keeps defaults of missing values", func(t *testing.T) {
+		p := params{Pagination: Pagination{Page: 1, Limit: 20}}
+
+		require.NoError(t, BindQuery(httptest.NewRequest(http.MethodGet, "/?limit=5", nil), &p))
+		assert.Equal(t, 1, p.Page)
+		assert.Equal(t, 5, p.Limit)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		var p params
+		err := BindQuery(httptest.NewRequest(http.MethodGet, "/?page=two", nil), &p)
+
+		assert.Equal(t, http.StatusBadRequest, HTTPErrorStatusCode(err))
+		assert.ErrorContains(t, err, `invalid query parameter "page"`)
+	})
+
+	t.Run("invalid destination", func(t *testing.T) {
+		var p params
+		err := BindQuery(httptest.NewRequest(http.MethodGet, "/", nil), p)
+
+		require.Error(t, err)
+		assert.NotEqual(t, http.StatusBadRequest, ErrorStatusCode(err))
+	})
+}
@@ -5,6 +5,20 @@ import (
 	"time"
 )
 
+// HeaderFormat selects the rate limit response header fields.
+type HeaderFormat string
+
+const (
+	// HeaderFormatX emits the X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset header fields.
+	HeaderFormatX HeaderFormat = "x"
+	// HeaderFormatDraft emits the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset,
+	// the combined RateLimit and the RateLimit-Policy header fields of the IETF
+	// "RateLimit header fields for HTTP" draft.
+	HeaderFormatDraft HeaderFormat = "draft"
+	// HeaderFormatBoth emits both the X-RateLimit-* and the draft header fields.
+	HeaderFormatBoth HeaderFormat = "both"
+)
+
 type Config struct {
 	// TimestampFunc return current unix timestamp (seconds)
 	// max value is 4294967295 -> Sun Feb 07 2106 06:28:15 GMT+0000
@@ -50,10 +64,21 @@ type Config struct {
 	// Default: false
 	DisableHeaders bool `env:"DISABLE_HEADERS" json:"disableHeaders,omitempty" yaml:"disableHeaders,omitempty"`
 
+	// HeaderFormat selects the rate limit header fields: "x", "draft" or "both".
+	//
+	// Default: "x"
+	HeaderFormat HeaderFormat `env:"HEADER_FORMAT" json:"headerFormat,omitempty" yaml:"headerFormat,omitempty"`
+
 	// DisableValueRedaction turns off masking limiter keys in logs and error messages when set to true.
 	//
 	// Default: false
 	DisableValueRedaction bool `env:"DISABLE_VALUE_REDACTION" json:"disableValueRedaction,omitempty" yaml:"disableValueRedaction,omitempty"`
+
+	// PerTenant isolates the limits of the tenants (see keratin.TenantFromContext)
+	// by prefixing the identifiers with the request tenant.
+	//
+	// Default: false
+	PerTenant bool `env:"PER_TENANT" json:"perTenant,omitempty" yaml:"perTenant,omitempty"`
 }
 
 func (c *Config) SetDefaults() {
@@ -76,6 +101,10 @@ func (c *Config) SetDefaults() {
 		}
 	}
 
+	if c.HeaderFormat == "" {
+		c.HeaderFormat = HeaderFormatX
+	}
+
 	if c.Expiration == 0 {
 		c.Expiration = 1 * time.Minute
 	}
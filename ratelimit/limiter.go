@@ -24,6 +24,58 @@ type Storage interface {
 	Set(ctx context.Context, key string, value []byte, exp time.Duration) error
 }
 
+// AtomicStorage is a [Storage] which can record a hit and update the sliding window
+// in a single atomic operation, e.g. with a Redis Lua script.
+//
+// Limiters backed by an AtomicStorage do not serialize requests through a local mutex,
+// so multiple instances sharing the storage enforce a correct limit.
+type AtomicStorage interface {
+	Storage
+
+	// Hit records a hit for key at ts (unix seconds) in a window of expiration seconds
+	// and returns the updated window state.
+	Hit(ctx context.Context, key string, ts, expiration uint64) (Window, error)
+}
+
+// Window is the sliding window state of a key.
+type Window struct {
+	// CurrHits is the number of hits in the current window.
+	CurrHits int
+	// PrevHits is the number of hits in the previous window.
+	PrevHits int
+	// Exp is the unix timestamp (seconds) when the current window ends.
+	Exp uint64
+}
+
+// Slide records a hit at ts in a window of expiration seconds.
+// AtomicStorage implementations must apply the same algorithm.
+func (win *Window) Slide(ts, expiration uint64) {
+	// Set expiration if entry does not exist
+	if win.Exp == 0 {
+		win.Exp = ts + expiration
+	} else if ts >= win.Exp {
+		// The entry has expired, handle the expiration.
+		// Set the prevHits to the current hits and reset the hits to 0.
+		win.PrevHits = win.CurrHits
+
+		// Reset the current hits to 0.
+		win.CurrHits = 0
+
+		// Check how much into the current window it currently is and sets the
+		// expiry based on that; otherwise, this would only reset on
+		// the next request and not show the correct expiry.
+		elapsed := ts - win.Exp
+		if elapsed >= expiration {
+			win.Exp = ts + expiration
+		} else {
+			win.Exp = ts + expiration - elapsed
+		}
+	}
+
+	// Increment hits
+	win.CurrHits++
+}
+
 // Limiter implements the sliding-window rate limiting strategy
 type Limiter struct {
 	cfg     Config
@@ -54,59 +106,97 @@ func (l *Limiter) Allow(w http.ResponseWriter, r *http.Request) error {
 	if err != nil {
 		return keratin.ErrForbidden.Wrap(fmt.Errorf("rate_limiter: failed to extract identifier: %w", err))
 	}
+	if l.cfg.PerTenant {
+		if tenant := keratin.TenantFromContext(r.Context()); tenant != "" {
+			key = tenant + ":" + key
+		}
+	}
 
 	maxRequests := l.maxFunc(r)
 	expiration := l.expirationFunc(r)
 
-	// Lock entry
-	l.mu.Lock()
+	// Get timestamp
+	ts := uint64(l.cfg.TimestampFunc())
 
-	// Get entry from pool and release when finished
-	entry, err := l.manager.get(r.Context(), key)
+	win, err := l.hit(r.Context(), key, ts, expiration)
 	if err != nil {
-		l.mu.Unlock()
 		return err
 	}
 
-	// Get timestamp
-	ts := uint64(l.cfg.TimestampFunc())
+	// Calculate when it resets in seconds
+	resetInSec := win.Exp - ts
 
-	// Set expiration if entry does not exist
-	if entry.exp == 0 {
-		entry.exp = ts + expiration
-	} else if ts >= entry.exp {
-		// The entry has expired, handle the expiration.
-		// Set the prevHits to the current hits and reset the hits to 0.
-		entry.prevHits = entry.currHits
+	// weight = time until current window reset / total window length
+	weight := float64(resetInSec) / float64(expiration)
 
-		// Reset the current hits to 0.
-		entry.currHits = 0
+	// rate = request count in previous window - weight + request count in current window
+	rate := int(float64(win.PrevHits)*weight) + win.CurrHits
 
-		// Check how much into the current window it currently is and sets the
-		// expiry based on that; otherwise, this would only reset on
-		// the next request and not show the correct expiry.
-		elapsed := ts - entry.exp
-		if elapsed >= expiration {
-			entry.exp = ts + expiration
-		} else {
-			entry.exp = ts + expiration - elapsed
+	// Calculate how many hits can be made based on the current rate
+	remaining := maxRequests - rate
+
+	// Check if hits exceed the cfg.Max
+	if remaining < 0 {
+		if l.cfg.DisableHeaders {
+			return ErrRateLimitExceeded
 		}
+		// the error handler responds with the Retry-After header
+		// https://tools.ietf.org/html/rfc6584
+		return keratin.NewTooManyRequestsError(time.Duration(resetInSec) * time.Second).Wrap(ErrRateLimitExceeded)
 	}
 
-	// Increment hits
-	entry.currHits++
+	if !l.cfg.DisableHeaders {
+		l.setHeaders(w.Header(), maxRequests, remaining, resetInSec, expiration)
+	}
 
-	// Calculate when it resets in seconds
-	resetInSec := entry.exp - ts
+	return nil
+}
 
-	// weight = time until current window reset / total window length
-	weight := float64(resetInSec) / float64(expiration)
+func (l *Limiter) setHeaders(h http.Header, limit, remaining int, reset, window uint64) {
+	limitStr := strconv.Itoa(limit)
+	remainingStr := strconv.Itoa(remaining)
+	resetStr := strconv.FormatUint(reset, 10)
 
-	// rate = request count in previous window - weight + request count in current window
-	rate := int(float64(entry.prevHits)*weight) + entry.currHits
+	if l.cfg.HeaderFormat != HeaderFormatDraft {
+		h.Set(keratin.HeaderXRateLimitLimit, limitStr)
+		h.Set(keratin.HeaderXRateLimitRemaining, remainingStr)
+		h.Set(keratin.HeaderXRateLimitReset, resetStr)
+	}
 
-	// Calculate how many hits can be made based on the current rate
-	remaining := maxRequests - rate
+	if l.cfg.HeaderFormat == HeaderFormatDraft || l.cfg.HeaderFormat == HeaderFormatBoth {
+		h.Set(keratin.HeaderRateLimitLimit, limitStr)
+		h.Set(keratin.HeaderRateLimitRemaining, remainingStr)
+		h.Set(keratin.HeaderRateLimitReset, resetStr)
+		h.Set(keratin.HeaderRateLimit, "limit="+limitStr+", remaining="+remainingStr+", reset="+resetStr)
+		h.Set(keratin.HeaderRateLimitPolicy, limitStr+";w="+strconv.FormatUint(window, 10))
+	}
+}
+
+func (l *Limiter) hit(ctx context.Context, key string, ts, expiration uint64) (Window, error) {
+	if atomic, ok := l.manager.storage.(AtomicStorage); ok {
+		win, err := atomic.Hit(ctx, key, ts, expiration)
+		if err != nil {
+			return Window{}, fmt.Errorf("rate_limiter: failed to record hit for key %q: %w", l.manager.logKey(key), err)
+		}
+		return win, nil
+	}
+
+	// Lock entry
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Get entry from pool and release when finished
+	entry, err := l.manager.get(ctx, key)
+	if err != nil {
+		return Window{}, err
+	}
+
+	win := Window{CurrHits: entry.currHits, PrevHits: entry.prevHits, Exp: entry.exp}
+	win.Slide(ts, expiration)
+
+	entry.currHits = win.CurrHits
+	entry.prevHits = win.PrevHits
+	entry.exp = win.Exp
 
 	// Update storage. Garbage collect when the next window ends.
 	// |--------------------------|--------------------------|
@@ -120,31 +210,11 @@ func (l *Limiter) Allow(w http.ResponseWriter, r *http.Request) error {
 	// we add the expiration to the duration.
 	// Otherwise, after the end of "sample window", attackers could launch
 	// a new request with the full window length.
-	if setErr := l.manager.set(r.Context(), key, entry, time.Duration(resetInSec+expiration)*time.Second); setErr != nil { //nolint:gosec // Not a concern
-		l.mu.Unlock()
-		return fmt.Errorf("rate_limiter: failed to persist state: %w", setErr)
+	if setErr := l.manager.set(ctx, key, entry, time.Duration(win.Exp-ts+expiration)*time.Second); setErr != nil { //nolint:gosec // Not a concern
+		return Window{}, fmt.Errorf("rate_limiter: failed to persist state: %w", setErr)
 	}
 
-	// Unlock entry
-	l.mu.Unlock()
-
-	// Check if hits exceed the cfg.Max
-	if remaining < 0 {
-		// Return response with Retry-After header
-		// https://tools.ietf.org/html/rfc6584
-		if !l.cfg.DisableHeaders {
-			w.Header().Set(keratin.HeaderRetryAfter, strconv.FormatUint(resetInSec, 10))
-		}
-		return ErrRateLimitExceeded
-	}
-
-	if !l.cfg.DisableHeaders {
-		w.Header().Set(keratin.HeaderXRateLimitLimit, strconv.Itoa(maxRequests))
-		w.Header().Set(keratin.HeaderXRateLimitRemaining, strconv.Itoa(remaining))
-		w.Header().Set(keratin.HeaderXRateLimitReset, strconv.FormatUint(resetInSec, 10))
-	}
-
-	return nil
+	return win, nil
 }
 
 func (l *Limiter) maxFunc(r *http.Request) int {
@@ -172,8 +172,9 @@ func TestLimiter_Allow_ExceedsLimit(t *testing.T) {
 		err := limiter.Allow(w, req)
 
 		assert.Error(t, err)
-		assert.Equal(t, ErrRateLimitExceeded, err)
-		assert.NotEmpty(t, w.Header().Get(keratin.HeaderRetryAfter))
+		assert.ErrorIs(t, err, ErrRateLimitExceeded)
+		assert.Equal(t, http.StatusTooManyRequests, keratin.HTTPErrorStatusCode(err))
+		assert.Positive(t, keratin.ErrorRetryAfter(err))
 	})
 
 	t.Run("does not set retry-after header when disabled", func(t *testing.T) {
@@ -197,8 +198,9 @@ func TestLimiter_Allow_ExceedsLimit(t *testing.T) {
 		err := limiter.Allow(w, req)
 
 		assert.Error(t, err)
-		assert.Equal(t, ErrRateLimitExceeded, err)
+		assert.ErrorIs(t, err, ErrRateLimitExceeded)
 		assert.Empty(t, w.Header().Get(keratin.HeaderRetryAfter))
+		assert.Zero(t, keratin.ErrorRetryAfter(err))
 	})
 }
 
@@ -233,15 +235,60 @@ func TestLimiter_Allow_DifferentKeys(t *testing.T) {
 		w1 := httptest.NewRecorder()
 		err1 := limiter.Allow(w1, req1)
 		assert.Error(t, err1)
-		assert.Equal(t, ErrRateLimitExceeded, err1)
+		assert.ErrorIs(t, err1, ErrRateLimitExceeded)
 
 		w2 := httptest.NewRecorder()
 		err2 := limiter.Allow(w2, req2)
 		assert.Error(t, err2)
-		assert.Equal(t, ErrRateLimitExceeded, err2)
+		assert.ErrorIs(t, err2, ErrRateLimitExceeded)
 	})
 }
 
+func TestLimiter_Allow_PerTenant(t *testing.T) {
+	tests := []struct {
+		name      string
+		perTenant bool
+		want      []int
+	}{
+		{name: "tenants share the limit", want: []int{http.StatusNoContent, http.StatusTooManyRequests, http.StatusTooManyRequests}},
+		{name: "tenants are isolated", perTenant: true, want: []int{http.StatusNoContent, http.StatusTooManyRequests, http.StatusNoContent}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewLimiter(Config{
+				Max:           1,
+				Expiration:    time.Minute,
+				TimestampFunc: fixedTimestampFunc,
+				PerTenant:     tt.perTenant,
+			})
+
+			router := keratin.NewRouter()
+			router.HostGroup("{tenant}.example.com").GET("/", func(w http.ResponseWriter, r *http.Request) error {
+				if err := limiter.Allow(w, r); err != nil {
+					return err
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return nil
+			})
+			handler := router.Build()
+
+			var got []int
+			for _, host := range []string{"acme.example.com", "acme.example.com", "globex.example.com"} {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Host = host
+				req.RemoteAddr = "127.0.0.1:12345"
+
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+				got = append(got, rec.Code)
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestLimiter_Allow_Expiration(t *testing.T) {
 	t.Run("resets counter after window expires", func(t *testing.T) {
 		cfg := Config{
@@ -291,7 +338,7 @@ func TestLimiter_Allow_SlidingWindow(t *testing.T) {
 		w := httptest.NewRecorder()
 		err := limiter.Allow(w, req)
 		assert.Error(t, err)
-		assert.Equal(t, ErrRateLimitExceeded, err)
+		assert.ErrorIs(t, err, ErrRateLimitExceeded)
 
 		fixedTimestamp += 15
 
@@ -327,7 +374,7 @@ func TestLimiter_Allow_DynamicMax(t *testing.T) {
 		w := httptest.NewRecorder()
 		err := limiter.Allow(w, req)
 		assert.Error(t, err)
-		assert.Equal(t, ErrRateLimitExceeded, err)
+		assert.ErrorIs(t, err, ErrRateLimitExceeded)
 
 		req.Header.Set("X-Premium", "true")
 		for range 10 {
@@ -338,7 +385,7 @@ func TestLimiter_Allow_DynamicMax(t *testing.T) {
 		w = httptest.NewRecorder()
 		err = limiter.Allow(w, req)
 		assert.Error(t, err)
-		assert.Equal(t, ErrRateLimitExceeded, err)
+		assert.ErrorIs(t, err, ErrRateLimitExceeded)
 	})
 }
 
@@ -403,7 +450,7 @@ func TestLimiter_Allow_CustomIdentifierExtractor(t *testing.T) {
 		w := httptest.NewRecorder()
 		err := limiter.Allow(w, req)
 		assert.Error(t, err)
-		assert.Equal(t, ErrRateLimitExceeded, err)
+		assert.ErrorIs(t, err, ErrRateLimitExceeded)
 
 		req.Header.Set("X-API-Key", "key-456")
 		w = httptest.NewRecorder()
@@ -700,8 +747,9 @@ func TestLimiter_Allow_Scenarios(t *testing.T) {
 		w = httptest.NewRecorder()
 		err = limiter.Allow(w, req)
 		assert.Error(t, err)
-		assert.Equal(t, ErrRateLimitExceeded, err)
-		assert.NotEmpty(t, w.Header().Get(keratin.HeaderRetryAfter))
+		assert.ErrorIs(t, err, ErrRateLimitExceeded)
+		assert.Equal(t, http.StatusTooManyRequests, keratin.HTTPErrorStatusCode(err))
+		assert.Positive(t, keratin.ErrorRetryAfter(err))
 	})
 
 	t.Run("handles high limit requests", func(t *testing.T) {
@@ -746,6 +794,101 @@ func TestLimiter_Allow_Scenarios(t *testing.T) {
 		w := httptest.NewRecorder()
 		err := limiter.Allow(w, req)
 		assert.Error(t, err)
-		assert.Equal(t, ErrRateLimitExceeded, err)
+		assert.ErrorIs(t, err, ErrRateLimitExceeded)
 	})
 }
+
+func TestWindow_Slide(t *testing.T) {
+	tests := []struct {
+		name string
+		win  Window
+		ts   uint64
+		want Window
+	}{
+		{name: "new window", ts: 100, want: Window{CurrHits: 1, Exp: 160}},
+		{name: "same window", win: Window{CurrHits: 3, Exp: 160}, ts: 120, want: Window{CurrHits: 4, Exp: 160}},
+		{name: "next window", win: Window{CurrHits: 3, PrevHits: 1, Exp: 160}, ts: 170, want: Window{CurrHits: 1, PrevHits: 3, Exp: 220}},
+		{name: "idle for long", win: Window{CurrHits: 3, Exp: 160}, ts: 500, want: Window{CurrHits: 1, PrevHits: 3, Exp: 560}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.win.Slide(tt.ts, 60)
+			assert.Equal(t, tt.want, tt.win)
+		})
+	}
+}
+
+type atomicTestStorage struct {
+	*MemoryStorage
+	hits int
+}
+
+func (s *atomicTestStorage) Hit(_ context.Context, _ string, ts, expiration uint64) (Window, error) {
+	s.hits++
+	return Window{CurrHits: s.hits, Exp: ts + expiration}, nil
+}
+
+func TestLimiter_Allow_AtomicStorage(t *testing.T) {
+	storage := &atomicTestStorage{MemoryStorage: NewMemoryStorage(fixedTimestampFunc)}
+	limiter := NewLimiterWithStorage(Config{Max: 2, TimestampFunc: fixedTimestampFunc}, storage)
+
+	for range 2 {
+		assert.NoError(t, limiter.Allow(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil)))
+	}
+	assert.ErrorIs(t, limiter.Allow(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil)), ErrRateLimitExceeded)
+	assert.Equal(t, 3, storage.hits)
+
+	got, err := storage.Get(context.Background(), "192.0.2.1:1234")
+	assert.NoError(t, err)
+	assert.Nil(t, got, "atomic storages bypass Get/Set")
+}
+
+func TestLimiter_Allow_HeaderFormat(t *testing.T) {
+	tests := []struct {
+		name       string
+		format     HeaderFormat
+		wantX      bool
+		wantDraft  bool
+		wantPolicy string
+	}{
+		{name: "default", wantX: true},
+		{name: "x", format: HeaderFormatX, wantX: true},
+		{name: "draft", format: HeaderFormatDraft, wantDraft: true},
+		{name: "both", format: HeaderFormatBoth, wantX: true, wantDraft: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewLimiter(Config{
+				Max:           10,
+				Expiration:    time.Minute,
+				HeaderFormat:  tt.format,
+				TimestampFunc: fixedTimestampFunc,
+			})
+
+			w := httptest.NewRecorder()
+			assert.NoError(t, limiter.Allow(w, httptest.NewRequest(http.MethodGet, "/", nil)))
+
+			h := w.Header()
+			if tt.wantX {
+				assert.Equal(t, "10", h.Get(keratin.HeaderXRateLimitLimit))
+				assert.Equal(t, "9", h.Get(keratin.HeaderXRateLimitRemaining))
+				assert.Equal(t, "60", h.Get(keratin.HeaderXRateLimitReset))
+			} else {
+				assert.Empty(t, h.Get(keratin.HeaderXRateLimitLimit))
+			}
+
+			if tt.wantDraft {
+				assert.Equal(t, "10", h.Get(keratin.HeaderRateLimitLimit))
+				assert.Equal(t, "9", h.Get(keratin.HeaderRateLimitRemaining))
+				assert.Equal(t, "60", h.Get(keratin.HeaderRateLimitReset))
+				assert.Equal(t, "limit=10, remaining=9, reset=60", h.Get(keratin.HeaderRateLimit))
+				assert.Equal(t, "10;w=60", h.Get(keratin.HeaderRateLimitPolicy))
+			} else {
+				assert.Empty(t, h.Get(keratin.HeaderRateLimit))
+				assert.Empty(t, h.Get(keratin.HeaderRateLimitLimit))
+			}
+		})
+	}
+}
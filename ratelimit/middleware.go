@@ -30,3 +30,53 @@ func Middleware(limiter *Limiter, skippers ...middleware.Skipper) func(http.Hand
 		})
 	}
 }
+
+// WithRateLimitStorage enables the route rate limits declared with [keratin.Route.RateLimit],
+// every rate limited route gets its own [Limiter] counting the clients per route pattern
+// in the shared storage. A nil storage is replaced by a [MemoryStorage].
+//
+// The optional cfg configures the identifier extractor and the response headers of the limiters,
+// its Max and Expiration are replaced by the route limit. The clients are identified by their IP,
+// see [keratin.Context.RealIP], unless cfg has an IdentifierExtractor.
+func WithRateLimitStorage(storage Storage, cfg ...Config) keratin.Option {
+	var base Config
+	if len(cfg) > 0 {
+		base = cfg[0]
+	}
+	if base.TimestampFunc == nil {
+		base.TimestampFunc = timestampFunc
+	}
+	if storage == nil {
+		storage = NewMemoryStorage(base.TimestampFunc)
+	}
+
+	return keratin.WithRateLimiter(func(pattern string, limit keratin.RateLimit) func(keratin.Handler) keratin.Handler {
+		c := base
+		c.Max = limit.Max
+		c.MaxFunc = nil
+		c.Expiration = limit.Window
+		c.ExpirationFunc = nil
+
+		if extractor := c.IdentifierExtractor; extractor != nil {
+			c.IdentifierExtractor = func(r *http.Request) (string, error) {
+				identifier, err := extractor(r)
+				return pattern + ":" + identifier, err
+			}
+		} else {
+			c.IdentifierExtractor = func(r *http.Request) (string, error) {
+				return pattern + ":" + keratin.ClientIP(r), nil
+			}
+		}
+
+		limiter := NewLimiterWithStorage(c, storage)
+
+		return func(next keratin.Handler) keratin.Handler {
+			return keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				if err := limiter.Allow(w, r); err != nil {
+					return err
+				}
+				return next.ServeHTTP(w, r)
+			})
+		}
+	})
+}
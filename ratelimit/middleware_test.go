@@ -253,3 +253,76 @@ func TestMiddleware(t *testing.T) {
 		assert.NotEmpty(t, w.Header().Get(keratin.HeaderRetryAfter))
 	})
 }
+
+func TestWithRateLimitStorage(t *testing.T) {
+	storage := NewMemoryStorage(timestampFunc)
+
+	router := keratin.NewRouter(WithRateLimitStorage(storage, Config{
+		IdentifierExtractor: func(r *http.Request) (string, error) {
+			return r.Header.Get("X-Client"), nil
+		},
+	}))
+	ok := func(w http.ResponseWriter, r *http.Request) error {
+		return keratin.TextPlain(w, http.StatusOK, "ok")
+	}
+	router.POST("/login", ok).RateLimit(1, minute)
+	router.POST("/password/reset", ok).RateLimit(2, minute)
+	router.GET("/", ok)
+
+	handler := router.Build()
+
+	serve := func(method, target, client string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("X-Client", client)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodPost, "/login", "a")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get(keratin.HeaderXRateLimitLimit))
+	assert.Equal(t, "0", w.Header().Get(keratin.HeaderXRateLimitRemaining))
+
+	w = serve(http.MethodPost, "/login", "a")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get(keratin.HeaderRetryAfter))
+
+	// the clients and the routes are counted separately
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/login", "b").Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/password/reset", "a").Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/password/reset", "a").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(http.MethodPost, "/password/reset", "a").Code)
+
+	for range 3 {
+		w = serve(http.MethodGet, "/", "a")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(keratin.HeaderXRateLimitLimit))
+	}
+
+	raw, err := storage.Get(t.Context(), "POST /login:a")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, raw)
+}
+
+func TestWithRateLimitStorage_DefaultIdentifier(t *testing.T) {
+	router := keratin.NewRouter(WithRateLimitStorage(nil))
+	router.POST("/login", func(w http.ResponseWriter, r *http.Request) error {
+		return keratin.TextPlain(w, http.StatusOK, "ok")
+	}).RateLimit(1, minute)
+
+	handler := router.Build()
+
+	serve := func(remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// the port of the client is not a part of its identifier
+	assert.Equal(t, http.StatusOK, serve("10.0.0.1:11111"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("10.0.0.1:22222"))
+	assert.Equal(t, http.StatusOK, serve("10.0.0.2:11111"))
+}
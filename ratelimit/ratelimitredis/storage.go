@@ -0,0 +1,162 @@
+// Package ratelimitredis provides a Redis backed [ratelimit.AtomicStorage].
+//
+// The package does not depend on a specific Redis client. Any client can be used through the
+// [Evaler] interface, e.g. with github.com/redis/go-redis:
+//
+//	storage := ratelimitredis.NewStorage(ratelimitredis.EvalFunc(
+//		func(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+//			return rdb.Eval(ctx, script, keys, args...).Result()
+//		},
+//	), "ratelimit:")
+//
+//	limiter := ratelimit.NewLimiterWithStorage(cfg, storage)
+package ratelimitredis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gowool/keratin/ratelimit"
+)
+
+var _ ratelimit.AtomicStorage = (*Storage)(nil)
+
+// DefaultPrefix is the default prefix of the Redis keys.
+const DefaultPrefix = "ratelimit:"
+
+const (
+	// ScriptGet returns the value of KEYS[1] or an empty string.
+	ScriptGet = `return redis.call('GET', KEYS[1]) or ''`
+
+	// ScriptSet sets KEYS[1] to ARGV[1] with an optional ttl of ARGV[2] milliseconds.
+	ScriptSet = `
+if tonumber(ARGV[2]) > 0 then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+else
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+return 1`
+
+	// ScriptHit records a hit in the sliding window stored in the hash KEYS[1]
+	// at ARGV[1] (unix seconds) with a window of ARGV[2] seconds.
+	// It returns {currHits, prevHits, exp}. See [ratelimit.Window.Slide].
+	ScriptHit = `
+local ts = tonumber(ARGV[1])
+local expiration = tonumber(ARGV[2])
+local v = redis.call('HMGET', KEYS[1], 'curr', 'prev', 'exp')
+local curr = tonumber(v[1]) or 0
+local prev = tonumber(v[2]) or 0
+local exp = tonumber(v[3]) or 0
+if exp == 0 then
+	exp = ts + expiration
+elseif ts >= exp then
+	prev = curr
+	curr = 0
+	local elapsed = ts - exp
+	if elapsed >= expiration then
+		exp = ts + expiration
+	else
+		exp = ts + expiration - elapsed
+	end
+end
+curr = curr + 1
+redis.call('HSET', KEYS[1], 'curr', curr, 'prev', prev, 'exp', exp)
+redis.call('EXPIRE', KEYS[1], exp - ts + expiration)
+return {curr, prev, exp}`
+)
+
+// Evaler evaluates a Lua script on the Redis server.
+type Evaler interface {
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// EvalFunc is an adapter to allow the use of ordinary functions as [Evaler].
+type EvalFunc func(ctx context.Context, script string, keys []string, args ...any) (any, error)
+
+func (f EvalFunc) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+	return f(ctx, script, keys, args...)
+}
+
+// Storage is a [ratelimit.AtomicStorage] backed by Redis.
+type Storage struct {
+	client Evaler
+	prefix string
+}
+
+// NewStorage returns a new Redis storage. An empty prefix defaults to [DefaultPrefix].
+// It panics if client is nil.
+func NewStorage(client Evaler, prefix string) *Storage {
+	if client == nil {
+		panic("ratelimitredis: client is required")
+	}
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	return &Storage{client: client, prefix: prefix}
+}
+
+func (s *Storage) Get(ctx context.Context, key string) ([]byte, error) {
+	res, err := s.client.Eval(ctx, ScriptGet, []string{s.prefix + key})
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := res.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if v == "" {
+			return nil, nil
+		}
+		return []byte(v), nil
+	case []byte:
+		if len(v) == 0 {
+			return nil, nil
+		}
+		return v, nil
+	default:
+		return nil, fmt.Errorf("ratelimitredis: unexpected GET result %T", res)
+	}
+}
+
+func (s *Storage) Set(ctx context.Context, key string, value []byte, exp time.Duration) error {
+	_, err := s.client.Eval(ctx, ScriptSet, []string{s.prefix + key}, value, max(exp.Milliseconds(), 0))
+	return err
+}
+
+func (s *Storage) Hit(ctx context.Context, key string, ts, expiration uint64) (ratelimit.Window, error) {
+	res, err := s.client.Eval(ctx, ScriptHit, []string{s.prefix + key}, ts, expiration)
+	if err != nil {
+		return ratelimit.Window{}, err
+	}
+
+	values, ok := res.([]any)
+	if !ok || len(values) != 3 {
+		return ratelimit.Window{}, fmt.Errorf("ratelimitredis: unexpected hit result %v", res)
+	}
+
+	var nums [3]int64
+	for i, v := range values {
+		if nums[i], err = toInt64(v); err != nil {
+			return ratelimit.Window{}, err
+		}
+	}
+
+	return ratelimit.Window{CurrHits: int(nums[0]), PrevHits: int(nums[1]), Exp: uint64(nums[2])}, nil
+}
+
+func toInt64(v any) (int64, error) {
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case int:
+		return int64(n), nil
+	case string:
+		return strconv.ParseInt(n, 10, 64)
+	default:
+		return 0, errors.New("ratelimitredis: unexpected hit result value")
+	}
+}
@@ -0,0 +1,199 @@
+package ratelimitredis
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin/ratelimit"
+)
+
+// fakeRedis emulates the storage scripts in memory, atomically like the Redis server.
+type fakeRedis struct {
+	mu      sync.Mutex
+	strings map[string]string
+	windows map[string]ratelimit.Window
+	ttls    map[string]int64
+	err     error
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{
+		strings: make(map[string]string),
+		windows: make(map[string]ratelimit.Window),
+		ttls:    make(map[string]int64),
+	}
+}
+
+func (f *fakeRedis) Eval(_ context.Context, script string, keys []string, args ...any) (any, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	switch script {
+	case ScriptGet:
+		return f.strings[keys[0]], nil
+	case ScriptSet:
+		f.strings[keys[0]] = string(args[0].([]byte))
+		f.ttls[keys[0]] = args[1].(int64)
+		return int64(1), nil
+	case ScriptHit:
+		ts, expiration := args[0].(uint64), args[1].(uint64)
+		win := f.windows[keys[0]]
+		win.Slide(ts, expiration)
+		f.windows[keys[0]] = win
+		f.ttls[keys[0]] = int64(win.Exp - ts + expiration)
+		return []any{int64(win.CurrHits), int64(win.PrevHits), int64(win.Exp)}, nil
+	default:
+		return nil, errors.New("unknown script")
+	}
+}
+
+func TestNewStorage(t *testing.T) {
+	assert.PanicsWithValue(t, "ratelimitredis: client is required", func() {
+		NewStorage(nil, "")
+	})
+
+	s := NewStorage(newFakeRedis(), "")
+	assert.Equal(t, DefaultPrefix, s.prefix)
+
+	s = NewStorage(newFakeRedis(), "app:")
+	assert.Equal(t, "app:", s.prefix)
+}
+
+func TestStorage_GetSet(t *testing.T) {
+	client := newFakeRedis()
+	s := NewStorage(client, "")
+	ctx := context.Background()
+
+	got, err := s.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	require.NoError(t, s.Set(ctx, "key", []byte("value"), time.Minute))
+	assert.Equal(t, int64(60000), client.ttls[DefaultPrefix+"key"])
+
+	got, err = s.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), got)
+
+	require.NoError(t, s.Set(ctx, "forever", []byte("value"), -time.Second))
+	assert.Equal(t, int64(0), client.ttls[DefaultPrefix+"forever"])
+}
+
+func TestStorage_Get_Results(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  any
+		want    []byte
+		wantErr bool
+	}{
+		{name: "nil", result: nil},
+		{name: "empty bytes", result: []byte{}},
+		{name: "bytes", result: []byte("v"), want: []byte("v")},
+		{name: "unexpected", result: int64(1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStorage(EvalFunc(func(context.Context, string, []string, ...any) (any, error) {
+				return tt.result, nil
+			}), "")
+
+			got, err := s.Get(context.Background(), "key")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStorage_Hit(t *testing.T) {
+	client := newFakeRedis()
+	s := NewStorage(client, "")
+	ctx := context.Background()
+
+	win, err := s.Hit(ctx, "key", 100, 60)
+	require.NoError(t, err)
+	assert.Equal(t, ratelimit.Window{CurrHits: 1, Exp: 160}, win)
+	assert.Equal(t, int64(120), client.ttls[DefaultPrefix+"key"])
+
+	win, err = s.Hit(ctx, "key", 110, 60)
+	require.NoError(t, err)
+	assert.Equal(t, ratelimit.Window{CurrHits: 2, Exp: 160}, win)
+
+	win, err = s.Hit(ctx, "key", 170, 60)
+	require.NoError(t, err)
+	assert.Equal(t, ratelimit.Window{CurrHits: 1, PrevHits: 2, Exp: 220}, win)
+}
+
+func TestStorage_Hit_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		result any
+		err    error
+	}{
+		{name: "client error", err: errors.New("connection refused")},
+		{name: "wrong type", result: "OK"},
+		{name: "wrong length", result: []any{int64(1)}},
+		{name: "wrong value", result: []any{int64(1), 1.5, int64(1)}},
+		{name: "invalid string value", result: []any{"a", "1", "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStorage(EvalFunc(func(context.Context, string, []string, ...any) (any, error) {
+				return tt.result, tt.err
+			}), "")
+
+			_, err := s.Hit(context.Background(), "key", 1, 1)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestStorage_SharedLimit(t *testing.T) {
+	client := newFakeRedis()
+
+	cfg := ratelimit.Config{
+		Max:        10,
+		Expiration: time.Minute,
+		TimestampFunc: func() uint32 {
+			return 1000
+		},
+	}
+
+	// two limiters simulate two instances behind a load balancer
+	limiters := []*ratelimit.Limiter{
+		ratelimit.NewLimiterWithStorage(cfg, NewStorage(client, "")),
+		ratelimit.NewLimiterWithStorage(cfg, NewStorage(client, "")),
+	}
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Go(func() {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if limiters[i%2].Allow(httptest.NewRecorder(), r) == nil {
+				allowed.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(10), allowed.Load())
+}
@@ -6,8 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/gowool/keratin/internal"
 )
@@ -21,8 +23,30 @@ var (
 	_ Committer     = (*response)(nil)
 	_ StatusCoder   = (*response)(nil)
 	_ Sizer         = (*response)(nil)
+
+	_ deadliner = (*response)(nil)
+	_ deadliner = (*headWriter)(nil)
+	_ deadliner = (*delayedStatusWriter)(nil)
 )
 
+// deadliner lists the optional methods [http.ResponseController] looks for
+// before unwrapping the writer, the wrappers delegate them to the writers they wrap.
+type deadliner interface {
+	SetReadDeadline(deadline time.Time) error
+	SetWriteDeadline(deadline time.Time) error
+	EnableFullDuplex() error
+}
+
+// ResponseController returns an [http.ResponseController] for w, which reaches the
+// underlying connection through the keratin and middleware response writer wrappers.
+// Use it to manage the read/write deadlines of long-lived (streaming, websocket) responses:
+//
+//	rc := keratin.ResponseController(w)
+//	_ = rc.SetWriteDeadline(time.Now().Add(time.Minute))
+func ResponseController(w http.ResponseWriter) *http.ResponseController {
+	return http.NewResponseController(w)
+}
+
 // RWUnwrapper specifies that http.ResponseWriter could be "unwrapped"
 // (usually used with [http.ResponseController]).
 type RWUnwrapper interface {
@@ -123,6 +147,12 @@ type response struct {
 	committed bool
 	code      int
 	size      int64
+
+	flushPolicy FlushPolicy
+	logger      *slog.Logger
+
+	// release resets the response and puts it back to the router pool
+	release func()
 }
 
 func (r *response) reset(w http.ResponseWriter) {
@@ -160,6 +190,12 @@ func (r *response) WriteHeader(statusCode int) {
 		return
 	}
 
+	// informational responses (e.g. 103 Early Hints) may precede the final one
+	if informational(statusCode) {
+		r.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+
 	r.committed = true
 	r.code = statusCode
 
@@ -181,17 +217,31 @@ func (r *response) Write(b []byte) (n int, err error) {
 // Flush implements the http.Flusher interface to allow an HTTP handler to flush
 // buffered data to the client.
 // See [http.Flusher](https://golang.org/pkg/net/http/#Flusher)
+//
+// When the underlying writer cannot flush, it follows the router [FlushPolicy].
 func (r *response) Flush() {
-	if err := http.NewResponseController(r.ResponseWriter).Flush(); err != nil && errors.Is(err, http.ErrNotSupported) {
-		panic(fmt.Errorf("response writer %T does not support flushing (http.Flusher interface)", r.ResponseWriter))
+	if err := r.FlushError(); err != nil && errors.Is(err, http.ErrNotSupported) {
+		r.unsupportedFlush(err)
 	}
 }
 
+// FlushError flushes the buffered data to the client and returns [ErrFlushNotSupported]
+// if the underlying writer cannot flush. It is called by [http.ResponseController.Flush].
+func (r *response) FlushError() error {
+	return ResponseFlushError(r.ResponseWriter)
+}
+
 // Hijack implements the http.Hijacker interface to allow an HTTP handler to
 // take over the connection.
 // See [http.Hijacker](https://golang.org/pkg/net/http/#Hijacker)
+//
+// It returns [ErrHijackNotSupported] if the underlying writer cannot be hijacked.
 func (r *response) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	return http.NewResponseController(r.ResponseWriter).Hijack()
+	conn, rw, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err != nil && errors.Is(err, http.ErrNotSupported) {
+		return nil, nil, fmt.Errorf("%w: %T", ErrHijackNotSupported, r.ResponseWriter)
+	}
+	return conn, rw, err
 }
 
 // Push implements [http.Pusher] to indicate HTTP/2 server push support.
@@ -209,6 +259,22 @@ func (r *response) Push(target string, opts *http.PushOptions) error {
 	}
 }
 
+// SetReadDeadline sets the deadline for reading the request body, see [http.ResponseController.SetReadDeadline].
+func (r *response) SetReadDeadline(deadline time.Time) error {
+	return http.NewResponseController(r.ResponseWriter).SetReadDeadline(deadline)
+}
+
+// SetWriteDeadline sets the deadline for writing the response, see [http.ResponseController.SetWriteDeadline].
+func (r *response) SetWriteDeadline(deadline time.Time) error {
+	return http.NewResponseController(r.ResponseWriter).SetWriteDeadline(deadline)
+}
+
+// EnableFullDuplex allows reading the request body while writing the response,
+// see [http.ResponseController.EnableFullDuplex].
+func (r *response) EnableFullDuplex() error {
+	return http.NewResponseController(r.ResponseWriter).EnableFullDuplex()
+}
+
 // ReadFrom implements [io.ReaderFrom] by checking if the underlying writer supports it.
 // Otherwise calls [io.Copy].
 func (r *response) ReadFrom(reader io.Reader) (n int64, err error) {
@@ -220,17 +286,147 @@ func (r *response) ReadFrom(reader io.Reader) (n int64, err error) {
 	for {
 		switch rf := w.(type) {
 		case io.ReaderFrom:
-			return rf.ReadFrom(reader)
+			n, err = rf.ReadFrom(reader)
+			r.size += n
+			return
 		case RWUnwrapper:
 			w = rf.Unwrap()
 		default:
 			n, err = io.Copy(r.ResponseWriter, reader)
-			r.size = n
+			r.size += n
 			return
 		}
 	}
 }
 
+var (
+	_ http.Flusher  = (*headWriter)(nil)
+	_ io.ReaderFrom = (*headWriter)(nil)
+	_ RWUnwrapper   = (*headWriter)(nil)
+	_ Sizer         = (*headWriter)(nil)
+)
+
+// headWriter discards the response body of HEAD requests served by GET handlers
+// while keeping track of the number of bytes the handler has written.
+type headWriter struct {
+	http.ResponseWriter
+	size int64
+}
+
+func (w *headWriter) Write(b []byte) (int, error) {
+	if !ResponseCommitted(w.ResponseWriter) {
+		w.ResponseWriter.WriteHeader(http.StatusOK)
+	}
+	w.size += int64(len(b))
+	return len(b), nil
+}
+
+func (w *headWriter) ReadFrom(reader io.Reader) (int64, error) {
+	if !ResponseCommitted(w.ResponseWriter) {
+		w.ResponseWriter.WriteHeader(http.StatusOK)
+	}
+	n, err := io.Copy(io.Discard, reader)
+	w.size += n
+	return n, err
+}
+
+// Size returns the number of body bytes written by the handler (and discarded).
+func (w *headWriter) Size() int64 {
+	return w.size
+}
+
+// Flush flushes the underlying writer, the router response writer applies the flush policy.
+func (w *headWriter) Flush() {
+	ResponseFlush(w.ResponseWriter)
+}
+
+// FlushError flushes the underlying writer and returns [ErrFlushNotSupported] if it cannot flush.
+func (w *headWriter) FlushError() error {
+	return ResponseFlushError(w.ResponseWriter)
+}
+
+func (w *headWriter) SetReadDeadline(deadline time.Time) error {
+	return http.NewResponseController(w.ResponseWriter).SetReadDeadline(deadline)
+}
+
+func (w *headWriter) SetWriteDeadline(deadline time.Time) error {
+	return http.NewResponseController(w.ResponseWriter).SetWriteDeadline(deadline)
+}
+
+func (w *headWriter) EnableFullDuplex() error {
+	return http.NewResponseController(w.ResponseWriter).EnableFullDuplex()
+}
+
+func (w *headWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func informational(statusCode int) bool {
+	return statusCode >= 100 && statusCode < 200 && statusCode != http.StatusSwitchingProtocols
+}
+
+// DeclareTrailers announces in the Trailer header the names of the trailers sent after the response body.
+// It has no effect once the response is committed. Declaring the trailers is optional with [SetTrailer].
+func DeclareTrailers(w http.ResponseWriter, names ...string) {
+	if len(names) == 0 || ResponseCommitted(w) {
+		return
+	}
+
+	for _, name := range names {
+		w.Header().Add(HeaderTrailer, http.CanonicalHeaderKey(name))
+	}
+}
+
+// SetTrailer sets the value of the trailer sent after the response body.
+// It can be called before or after the body is written, the trailer does not need to be declared.
+func SetTrailer(w http.ResponseWriter, name, value string) {
+	w.Header().Set(http.TrailerPrefix+http.CanonicalHeaderKey(name), value)
+}
+
+// Push initiates HTTP/2 server pushes for the given resources.
+// It returns [http.ErrNotSupported] if the underlying writer does not support server push.
+func Push(w http.ResponseWriter, resources ...string) error {
+	for {
+		switch t := w.(type) {
+		case http.Pusher:
+			var err error
+			for _, resource := range resources {
+				if pushErr := t.Push(resource, nil); pushErr != nil {
+					err = errors.Join(err, fmt.Errorf("push %s: %w", resource, pushErr))
+				}
+			}
+			return err
+		case RWUnwrapper:
+			w = t.Unwrap()
+		default:
+			return http.ErrNotSupported
+		}
+	}
+}
+
+// EarlyHints sends a 103 Early Hints informational response with the given Link header values,
+// e.g. `</style.css>; rel=preload; as=style`.
+// The Link headers are kept and sent again with the final response.
+func EarlyHints(w http.ResponseWriter, links ...string) {
+	if len(links) == 0 || ResponseCommitted(w) {
+		return
+	}
+
+	for _, link := range links {
+		w.Header().Add(HeaderLink, link)
+	}
+
+	w.WriteHeader(http.StatusEarlyHints)
+}
+
+// PreloadLink formats a Link header value to preload target as the given destination type (e.g. "style", "script").
+func PreloadLink(target, as string) string {
+	if as == "" {
+		return fmt.Sprintf("<%s>; rel=preload", target)
+	}
+	return fmt.Sprintf("<%s>; rel=preload; as=%s", target, as)
+}
+
 func writeJSON(w http.ResponseWriter, status int, i any, indent string) error {
 	w = newDelayedStatusWriter(w)
 
@@ -334,6 +530,13 @@ func newDelayedStatusWriter(w http.ResponseWriter) *delayedStatusWriter {
 }
 
 func (w *delayedStatusWriter) WriteHeader(statusCode int) {
+	if informational(statusCode) {
+		if !w.committed {
+			w.ResponseWriter.WriteHeader(statusCode)
+		}
+		return
+	}
+
 	// in case something else writes status code explicitly before us we need mark response committed
 	w.status = statusCode
 }
@@ -349,17 +552,32 @@ func (w *delayedStatusWriter) Write(data []byte) (int, error) {
 	return w.ResponseWriter.Write(data)
 }
 
+// Flush flushes the underlying writer, the router response writer applies the flush policy.
 func (w *delayedStatusWriter) Flush() {
-	err := http.NewResponseController(w.ResponseWriter).Flush()
-	if err != nil && errors.Is(err, http.ErrNotSupported) {
-		panic(errors.New("response writer flushing is not supported"))
-	}
+	ResponseFlush(w.ResponseWriter)
+}
+
+// FlushError flushes the underlying writer and returns [ErrFlushNotSupported] if it cannot flush.
+func (w *delayedStatusWriter) FlushError() error {
+	return ResponseFlushError(w.ResponseWriter)
 }
 
 func (w *delayedStatusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	return http.NewResponseController(w.ResponseWriter).Hijack()
 }
 
+func (w *delayedStatusWriter) SetReadDeadline(deadline time.Time) error {
+	return http.NewResponseController(w.ResponseWriter).SetReadDeadline(deadline)
+}
+
+func (w *delayedStatusWriter) SetWriteDeadline(deadline time.Time) error {
+	return http.NewResponseController(w.ResponseWriter).SetWriteDeadline(deadline)
+}
+
+func (w *delayedStatusWriter) EnableFullDuplex() error {
+	return http.NewResponseController(w.ResponseWriter).EnableFullDuplex()
+}
+
 func (w *delayedStatusWriter) Unwrap() http.ResponseWriter {
 	return w.ResponseWriter
 }
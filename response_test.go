@@ -2,13 +2,16 @@ package keratin
 
 import (
 	"encoding/xml"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockWriterWithUnwrap struct {
@@ -92,6 +95,23 @@ func (m *mockPusher) Unwrap() http.ResponseWriter {
 	return m.ResponseWriter
 }
 
+type informationalRecorder struct {
+	*httptest.ResponseRecorder
+	informational []int
+}
+
+func newInformationalRecorder() *informationalRecorder {
+	return &informationalRecorder{ResponseRecorder: httptest.NewRecorder()}
+}
+
+func (m *informationalRecorder) WriteHeader(code int) {
+	if code >= 100 && code < 200 {
+		m.informational = append(m.informational, code)
+		return
+	}
+	m.ResponseRecorder.WriteHeader(code)
+}
+
 func TestResponseStatusCoder(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -657,6 +677,19 @@ func TestResponse_WriteHeader(t *testing.T) {
 			expectedCode:      http.StatusCreated,
 			expectedCommitted: true,
 		},
+		{
+			name: "informational status does not commit",
+			setupResponse: func(r *response) {
+				r.reset(newInformationalRecorder())
+			},
+			callWriteHeader: func(r *response, code int) {
+				r.WriteHeader(http.StatusEarlyHints)
+				assert.False(t, r.committed)
+				r.WriteHeader(code)
+			},
+			expectedCode:      http.StatusOK,
+			expectedCommitted: true,
+		},
 		{
 			name: "removes Content-Length header",
 			setupResponse: func(r *response) {
@@ -1586,6 +1619,23 @@ func TestDelayedStatusWriter_WriteHeader(t *testing.T) {
 	})
 }
 
+func TestDelayedStatusWriter_Informational(t *testing.T) {
+	rec := newInformationalRecorder()
+	dsw := newDelayedStatusWriter(rec)
+
+	dsw.WriteHeader(http.StatusEarlyHints)
+	assert.Equal(t, []int{http.StatusEarlyHints}, rec.informational)
+	assert.Zero(t, dsw.status)
+
+	dsw.WriteHeader(http.StatusAccepted)
+	_, err := dsw.Write([]byte("ok"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	dsw.WriteHeader(http.StatusEarlyHints)
+	assert.Len(t, rec.informational, 1)
+}
+
 func TestDelayedStatusWriter_Write(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -1653,6 +1703,43 @@ func TestDelayedStatusWriter_Unwrap(t *testing.T) {
 	})
 }
 
+func TestDelayedStatusWriter_Flush(t *testing.T) {
+	t.Run("flushes the underlying writer", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		dsw := newDelayedStatusWriter(rec)
+
+		require.NoError(t, dsw.FlushError())
+		assert.True(t, rec.Flushed)
+	})
+
+	t.Run("outside the router", func(t *testing.T) {
+		dsw := newDelayedStatusWriter(&plainWriter{})
+
+		assert.NotPanics(t, dsw.Flush)
+		assert.ErrorIs(t, http.NewResponseController(dsw).Flush(), ErrFlushNotSupported)
+	})
+
+	t.Run("applies the router flush policy", func(t *testing.T) {
+		res := &response{}
+		res.reset(&plainWriter{})
+
+		assert.Panics(t, newDelayedStatusWriter(res).Flush)
+
+		res.flushPolicy = FlushIgnore
+		assert.NotPanics(t, newDelayedStatusWriter(res).Flush)
+	})
+}
+
+func TestHeadWriter_Flush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	require.NoError(t, http.NewResponseController(&headWriter{ResponseWriter: rec}).Flush())
+	assert.True(t, rec.Flushed)
+
+	hw := &headWriter{ResponseWriter: &plainWriter{}}
+	assert.NotPanics(t, hw.Flush)
+	assert.ErrorIs(t, http.NewResponseController(hw).Flush(), ErrFlushNotSupported)
+}
+
 func TestDelayedStatusWriter_Hijack(t *testing.T) {
 	t.Run("hijack with httptest not supported", func(t *testing.T) {
 		rec := httptest.NewRecorder()
@@ -1693,3 +1780,179 @@ func TestDelayedStatusWriter_WriteHeaderThenWrite(t *testing.T) {
 		assert.Equal(t, "created", rec.Body.String())
 	})
 }
+
+func TestPush(t *testing.T) {
+	pushErr := errors.New("push failed")
+
+	tests := []struct {
+		name    string
+		w       http.ResponseWriter
+		wantErr error
+	}{
+		{
+			name:    "not supported",
+			w:       httptest.NewRecorder(),
+			wantErr: http.ErrNotSupported,
+		},
+		{
+			name: "pushes through unwrapped writers",
+			w:    &response{ResponseWriter: &mockPusher{ResponseWriter: httptest.NewRecorder()}},
+		},
+		{
+			name:    "returns push error",
+			w:       &mockPusher{ResponseWriter: httptest.NewRecorder(), pushError: pushErr},
+			wantErr: pushErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Push(tt.w, "/app.css", "/app.js")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEarlyHints(t *testing.T) {
+	rec := newInformationalRecorder()
+	w := &response{}
+	w.reset(rec)
+
+	EarlyHints(w, PreloadLink("/app.css", "style"), PreloadLink("/app.js", ""))
+	assert.Equal(t, []int{http.StatusEarlyHints}, rec.informational)
+	assert.False(t, w.Committed())
+
+	_, err := w.Write([]byte("ok"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"</app.css>; rel=preload; as=style", "</app.js>; rel=preload"}, rec.Header().Values(HeaderLink))
+
+	// no hints once the response is committed
+	EarlyHints(w, PreloadLink("/late.css", "style"))
+	assert.Len(t, rec.informational, 1)
+
+	// no hints without links
+	rec = newInformationalRecorder()
+	EarlyHints(rec)
+	assert.Empty(t, rec.informational)
+}
+
+func TestTrailers(t *testing.T) {
+	router := NewRouter()
+	router.GET("/", func(w http.ResponseWriter, _ *http.Request) error {
+		DeclareTrailers(w, "x-checksum")
+		EarlyHints(w, PreloadLink("/app.css", "style"))
+
+		_, err := w.Write([]byte("body"))
+
+		SetTrailer(w, "X-Checksum", "abc")
+		SetTrailer(w, "X-Undeclared", "def")
+		DeclareTrailers(w, "X-Late")
+		return err
+	})
+
+	srv := httptest.NewServer(router.Build())
+	defer srv.Close()
+
+	res, err := srv.Client().Get(srv.URL)
+	require.NoError(t, err)
+	defer func() { _ = res.Body.Close() }()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, "body", string(body))
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "</app.css>; rel=preload; as=style", res.Header.Get(HeaderLink))
+	assert.Equal(t, "abc", res.Trailer.Get("X-Checksum"))
+	assert.Equal(t, "def", res.Trailer.Get("X-Undeclared"))
+	assert.NotContains(t, res.Trailer, "X-Late")
+}
+
+type deadlineRecorder struct {
+	*httptest.ResponseRecorder
+	readDeadline  time.Time
+	writeDeadline time.Time
+	fullDuplex    bool
+}
+
+func (d *deadlineRecorder) SetReadDeadline(deadline time.Time) error {
+	d.readDeadline = deadline
+	return nil
+}
+
+func (d *deadlineRecorder) SetWriteDeadline(deadline time.Time) error {
+	d.writeDeadline = deadline
+	return nil
+}
+
+func (d *deadlineRecorder) EnableFullDuplex() error {
+	d.fullDuplex = true
+	return nil
+}
+
+func TestResponseController(t *testing.T) {
+	deadline := time.Now().Add(time.Minute)
+
+	tests := []struct {
+		name string
+		wrap func(w http.ResponseWriter) http.ResponseWriter
+	}{
+		{name: "response", wrap: func(w http.ResponseWriter) http.ResponseWriter {
+			res := &response{}
+			res.reset(w)
+			return res
+		}},
+		{name: "head writer", wrap: func(w http.ResponseWriter) http.ResponseWriter { return &headWriter{ResponseWriter: w} }},
+		{name: "delayed status writer", wrap: func(w http.ResponseWriter) http.ResponseWriter { return newDelayedStatusWriter(w) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &deadlineRecorder{ResponseRecorder: httptest.NewRecorder()}
+			rc := ResponseController(tt.wrap(tt.wrap(rec)))
+
+			require.NoError(t, rc.SetReadDeadline(deadline))
+			require.NoError(t, rc.SetWriteDeadline(deadline))
+			require.NoError(t, rc.EnableFullDuplex())
+
+			assert.Equal(t, deadline, rec.readDeadline)
+			assert.Equal(t, deadline, rec.writeDeadline)
+			assert.True(t, rec.fullDuplex)
+		})
+	}
+
+	t.Run("not supported", func(t *testing.T) {
+		res := &response{}
+		res.reset(httptest.NewRecorder())
+
+		assert.ErrorIs(t, ResponseController(res).SetWriteDeadline(deadline), http.ErrNotSupported)
+	})
+
+	t.Run("server", func(t *testing.T) {
+		router := NewRouter()
+		router.GET("/", func(w http.ResponseWriter, _ *http.Request) error {
+			rc := ResponseController(w)
+			if err := rc.SetReadDeadline(time.Now().Add(time.Minute)); err != nil {
+				return err
+			}
+			if err := rc.SetWriteDeadline(time.Now().Add(time.Minute)); err != nil {
+				return err
+			}
+			return TextPlain(w, http.StatusOK, "ok")
+		})
+
+		srv := httptest.NewServer(router.Build())
+		defer srv.Close()
+
+		res, err := srv.Client().Get(srv.URL)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+}
@@ -1,10 +1,81 @@
 package keratin
 
+import (
+	"net/http"
+	"sync/atomic"
+)
+
 type Route struct {
 	Method      string
 	Path        string
 	Handler     Handler
 	Middlewares Middlewares[Handler]
+
+	// HTTPMiddlewares are raw [http.Handler] middlewares run before the route Middlewares.
+	HTTPMiddlewares Middlewares[http.Handler]
+
+	// ResponseInterceptors and RequestInterceptors run before the route middlewares,
+	// see [Route.InterceptResponse] and [Route.InterceptRequest].
+	ResponseInterceptors Interceptors[http.ResponseWriter]
+	RequestInterceptors  Interceptors[*http.Request]
+
+	ErrorTranslators ErrorTranslators
+
+	// Limits are the route timeout and body size limit, see [Route.Timeout] and [Route.MaxBody].
+	Limits Limits
+
+	// Metadata are arbitrary route annotations, e.g. the required roles,
+	// available to middlewares via [Context.Route], see [Route.SetMeta].
+	Metadata map[string]any
+
+	// Consumes are the request body media types accepted by the route, see [Route.Accepts].
+	Consumes []string
+
+	// RequiredHeaders are the canonical names of the headers required by the route, see [Route.RequiresHeaders].
+	RequiredHeaders []string
+
+	// RequestSchema is the name of the JSON Schema of the request body, see [Route.Schema].
+	RequestSchema string
+
+	// ResponseSchema is the name of the JSON Schema of the successful responses, see [Route.Returns].
+	ResponseSchema string
+
+	disabled atomic.Bool
+}
+
+// SetMeta sets the route metadata value for key.
+func (route *Route) SetMeta(key string, value any) *Route {
+	if route.Metadata == nil {
+		route.Metadata = make(map[string]any)
+	}
+	route.Metadata[key] = value
+
+	return route
+}
+
+// Meta returns the route metadata value for key, nil if not set.
+func (route *Route) Meta(key string) any {
+	return route.Metadata[key]
+}
+
+// Disable turns the route off at runtime. Disabled routes respond with 404 Not Found
+// through the router error handler and are skipped by [Router.Patterns].
+func (route *Route) Disable() *Route {
+	route.disabled.Store(true)
+
+	return route
+}
+
+// Enable turns a disabled route back on.
+func (route *Route) Enable() *Route {
+	route.disabled.Store(false)
+
+	return route
+}
+
+// Disabled reports whether the route is disabled.
+func (route *Route) Disabled() bool {
+	return route.disabled.Load()
 }
 
 // UseFunc registers one or multiple middleware functions to the current route.
@@ -28,3 +99,35 @@ func (route *Route) Use(middlewares ...*Middleware[Handler]) *Route {
 
 	return route
 }
+
+// UseHTTPFunc registers one or multiple raw HTTP middleware functions to the current route.
+//
+// They run after the group raw HTTP middlewares and before all the [Handler] middlewares.
+func (route *Route) UseHTTPFunc(middlewareFuncs ...func(http.Handler) http.Handler) *Route {
+	for _, mdw := range middlewareFuncs {
+		route.HTTPMiddlewares = append(route.HTTPMiddlewares, &Middleware[http.Handler]{Func: mdw})
+	}
+
+	return route
+}
+
+// UseHTTP registers one or multiple raw HTTP middleware handlers to the current route.
+func (route *Route) UseHTTP(middlewares ...*Middleware[http.Handler]) *Route {
+	route.HTTPMiddlewares = append(route.HTTPMiddlewares, middlewares...)
+
+	return route
+}
+
+// OnError registers one or multiple error translators to the current route.
+//
+// Route error translators run before the ones of the parent groups, in the order they were registered,
+// and before the router-wide error handler.
+//
+// The error translators run inside the route and group middlewares: the middlewares (e.g. the request
+// logger or the transaction middleware) see the translated errors, and the errors returned
+// by the middlewares themselves are not translated.
+func (route *Route) OnError(translators ...ErrorTranslatorFunc) *Route {
+	route.ErrorTranslators = append(route.ErrorTranslators, translators...)
+
+	return route
+}
@@ -0,0 +1,80 @@
+package keratin
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// Accepts restricts the request body media types accepted by the route, e.g. "application/json",
+// "image/*" or "+json" (any structured syntax suffix).
+//
+// Requests with a body and a Content-Type not matching any of the media types
+// are rejected with 415 Unsupported Media Type before reaching the route handler.
+// Requests without body are not checked.
+func (route *Route) Accepts(mediaTypes ...string) *Route {
+	route.Consumes = append(route.Consumes, mediaTypes...)
+
+	return route
+}
+
+// Schema declares the name of the JSON Schema document (e.g. "create-user.json") of the request body.
+// The body is validated by the JSONSchema middleware, the name is also listed by [Router.Routes].
+func (route *Route) Schema(name string) *Route {
+	route.RequestSchema = name
+
+	return route
+}
+
+// Returns declares the name of the JSON Schema document (e.g. "user.json") of the successful (2xx) JSON responses.
+// The responses are checked by the AssertResponseSchema middleware, the name is also listed by [Router.Routes].
+func (route *Route) Returns(name string) *Route {
+	route.ResponseSchema = name
+
+	return route
+}
+
+// RequiresHeaders declares headers which must be present and not empty in the request.
+//
+// Requests missing any of them are rejected with 400 Bad Request before reaching the route handler.
+func (route *Route) RequiresHeaders(names ...string) *Route {
+	for _, name := range names {
+		route.RequiredHeaders = append(route.RequiredHeaders, http.CanonicalHeaderKey(name))
+	}
+
+	return route
+}
+
+// constraintsHandler wraps the route handler with the [Route.Accepts] and [Route.RequiresHeaders] checks.
+func (route *Route) constraintsHandler(next Handler) Handler {
+	if len(route.Consumes) == 0 && len(route.RequiredHeaders) == 0 {
+		return next
+	}
+
+	consumes := route.Consumes
+	requiredHeaders := route.RequiredHeaders
+
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		for _, name := range requiredHeaders {
+			if r.Header.Get(name) == "" {
+				return NewHTTPError(http.StatusBadRequest, fmt.Sprintf("missing required header %q", name))
+			}
+		}
+
+		if len(consumes) > 0 && hasBody(r) {
+			mediaType, _, err := mime.ParseMediaType(r.Header.Get(HeaderContentType))
+			if err != nil {
+				return ErrUnsupportedMediaType.Wrap(err)
+			}
+			if !MatchMediaType(mediaType, consumes...) {
+				return ErrUnsupportedMediaType.Wrap(fmt.Errorf("unsupported content type %q", mediaType))
+			}
+		}
+
+		return next.ServeHTTP(w, r)
+	})
+}
+
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
@@ -0,0 +1,75 @@
+package keratin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoute_Constraints(t *testing.T) {
+	router := NewRouter()
+	router.POST("/items", func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusCreated, "created")
+	}).Accepts(MIMEApplicationJSON, "+xml", "image/*").RequiresHeaders("x-tenant-id")
+
+	handler := router.Build()
+
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		tenant      string
+		wantCode    int
+	}{
+		{name: "json", body: "{}", contentType: "application/json; charset=utf-8", tenant: "t1", wantCode: http.StatusCreated},
+		{name: "suffix", body: "<a/>", contentType: "application/atom+xml", tenant: "t1", wantCode: http.StatusCreated},
+		{name: "wildcard", body: "png", contentType: "image/png", tenant: "t1", wantCode: http.StatusCreated},
+		{name: "no body", tenant: "t1", wantCode: http.StatusCreated},
+		{name: "unsupported", body: "a=b", contentType: MIMEApplicationForm, tenant: "t1", wantCode: http.StatusUnsupportedMediaType},
+		{name: "missing content type", body: "{}", tenant: "t1", wantCode: http.StatusUnsupportedMediaType},
+		{name: "missing header", body: "{}", contentType: MIMEApplicationJSON, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(tt.body))
+			if tt.body == "" {
+				req = httptest.NewRequest(http.MethodPost, "/items", nil)
+			}
+			if tt.contentType != "" {
+				req.Header.Set(HeaderContentType, tt.contentType)
+			}
+			if tt.tenant != "" {
+				req.Header.Set("X-Tenant-ID", tt.tenant)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+
+	routes := router.Routes()
+	if assert.Len(t, routes, 1) {
+		assert.Equal(t, []string{MIMEApplicationJSON, "+xml", "image/*"}, routes[0].Consumes)
+		assert.Equal(t, []string{"X-Tenant-Id"}, routes[0].RequiredHeaders)
+	}
+}
+
+func TestRoute_Schema(t *testing.T) {
+	router := NewRouter()
+	route := router.POST("/users", func(http.ResponseWriter, *http.Request) error { return nil }).Schema("create-user.json").Returns("user.json")
+
+	assert.Equal(t, "create-user.json", route.RequestSchema)
+	assert.Equal(t, "user.json", route.ResponseSchema)
+
+	routes := router.Routes()
+	if assert.Len(t, routes, 1) {
+		assert.Equal(t, "create-user.json", routes[0].RequestSchema)
+		assert.Equal(t, "user.json", routes[0].ResponseSchema)
+	}
+}
@@ -0,0 +1,50 @@
+package keratin
+
+import "context"
+
+// Route metadata keys of the response handling flags read by the middlewares,
+// see [Route.NoCompress], [Route.NoBuffer] and [Route.NoCache].
+const (
+	MetaCompress = "keratin.compress"
+	MetaBuffer   = "keratin.buffer"
+	MetaCache    = "keratin.cache"
+)
+
+// NoCompress exempts the route responses from the compression, e.g. of the already compressed
+// files or the Server-Sent Events streams.
+func (route *Route) NoCompress() *Route {
+	return route.SetMeta(MetaCompress, false)
+}
+
+// Compress forces the compression of the route responses, whatever the compression middleware
+// decides from their content type or size.
+func (route *Route) Compress() *Route {
+	return route.SetMeta(MetaCompress, true)
+}
+
+// NoBuffer exempts the route responses from the response buffering (e.g. the Buffer and the Digest middlewares),
+// so that they are streamed to the client as written, e.g. the Server-Sent Events streams.
+func (route *Route) NoBuffer() *Route {
+	return route.SetMeta(MetaBuffer, false)
+}
+
+// NoCache exempts the route responses from the caching directives of the CacheControl middleware.
+func (route *Route) NoCache() *Route {
+	return route.SetMeta(MetaCache, false)
+}
+
+// RouteFlag returns the boolean route metadata flag of the route matched by the request, e.g. [MetaCompress].
+// It reports false if no route matched or the flag is not set, so the middlewares apply their defaults:
+//
+//	if enabled, ok := keratin.RouteFlag(r.Context(), keratin.MetaCompress); ok && !enabled {
+//		return next.ServeHTTP(w, r)
+//	}
+func RouteFlag(ctx context.Context, key string) (enabled, ok bool) {
+	route := FromContext(ctx).Route()
+	if route == nil {
+		return false, false
+	}
+
+	enabled, ok = route.Meta(key).(bool)
+	return enabled, ok
+}
@@ -0,0 +1,64 @@
+package keratin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoute_Flags(t *testing.T) {
+	route := &Route{}
+
+	route.NoCompress().NoBuffer().NoCache()
+	assert.Equal(t, false, route.Meta(MetaCompress))
+	assert.Equal(t, false, route.Meta(MetaBuffer))
+	assert.Equal(t, false, route.Meta(MetaCache))
+
+	route.Compress()
+	assert.Equal(t, true, route.Meta(MetaCompress))
+}
+
+func TestRouteFlag(t *testing.T) {
+	type flag struct {
+		enabled, ok bool
+	}
+
+	var got flag
+	handler := func(_ http.ResponseWriter, r *http.Request) error {
+		got.enabled, got.ok = RouteFlag(r.Context(), MetaCompress)
+		return nil
+	}
+
+	router := NewRouter()
+	router.GET("/default", handler)
+	router.GET("/events", handler).NoCompress()
+	router.GET("/report", handler).Compress()
+	h := router.Build()
+
+	tests := []struct {
+		target string
+		want   flag
+	}{
+		{target: "/default", want: flag{}},
+		{target: "/events", want: flag{enabled: false, ok: true}},
+		{target: "/report", want: flag{enabled: true, ok: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			got = flag{}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	enabled, ok := RouteFlag(t.Context(), MetaCompress)
+	assert.False(t, enabled)
+	assert.False(t, ok)
+}
@@ -0,0 +1,176 @@
+package keratin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"time"
+)
+
+// Limits are the operational limits of a route or a group of routes,
+// the route limits override the group ones and the inner group limits override the outer ones.
+type Limits struct {
+	// Timeout is the deadline of the request context, zero means no timeout.
+	Timeout time.Duration
+
+	// MaxBody is the maximum request body size in bytes, zero means no limit.
+	MaxBody int64
+
+	// RateLimit is the request rate limit of every client, zero means no limit.
+	RateLimit RateLimit
+}
+
+// RateLimit allows a client at most Max requests of a route in a sliding Window, see [Route.RateLimit].
+type RateLimit struct {
+	Max    uint
+	Window time.Duration
+}
+
+// RateLimiterFunc returns the middleware enforcing limit on the route with pattern, e.g. "POST /login".
+// The clients must be counted per pattern, so that every route has its own limit, see [WithRateLimiter].
+type RateLimiterFunc func(pattern string, limit RateLimit) func(Handler) Handler
+
+// merge returns the limits overridden by the set ones of other.
+func (l Limits) merge(other Limits) Limits {
+	if other.Timeout != 0 {
+		l.Timeout = other.Timeout
+	}
+	if other.MaxBody != 0 {
+		l.MaxBody = other.MaxBody
+	}
+	if other.RateLimit.Window != 0 {
+		l.RateLimit = other.RateLimit
+	}
+	return l
+}
+
+// rateLimited reports whether the limits have a rate limit.
+func (l Limits) rateLimited() bool {
+	return l.RateLimit.Max > 0 && l.RateLimit.Window > 0
+}
+
+// middlewares returns the middlewares enforcing the limits of the route with pattern,
+// they run before all other middlewares.
+func (l Limits) middlewares(pattern string, rateLimiter RateLimiterFunc) Middlewares[Handler] {
+	var mws Middlewares[Handler]
+
+	if l.rateLimited() && rateLimiter != nil {
+		mws = append(mws, &Middleware[Handler]{ID: "keratin.rate_limit", Priority: math.MinInt, Func: rateLimiter(pattern, l.RateLimit)})
+	}
+
+	if l.MaxBody > 0 {
+		mws = append(mws, &Middleware[Handler]{ID: "keratin.max_body", Priority: math.MinInt, Func: maxBodyMiddleware(l.MaxBody)})
+	}
+	if l.Timeout > 0 {
+		mws = append(mws, &Middleware[Handler]{ID: "keratin.timeout", Priority: math.MinInt, Func: timeoutMiddleware(l.Timeout)})
+	}
+
+	return mws
+}
+
+// Timeout sets the request context deadline of the route.
+//
+// The timeout is cooperative: the handler must watch the request context.
+// A handler failing with the deadline error is reported as 503 Service Unavailable.
+// Negative value disables a timeout set by the parent groups.
+func (route *Route) Timeout(timeout time.Duration) *Route {
+	route.Limits.Timeout = timeout
+
+	return route
+}
+
+// MaxBody limits the request body size of the route, larger bodies are rejected
+// with 413 Request Entity Too Large. Negative value disables a limit set by the parent groups.
+func (route *Route) MaxBody(size int64) *Route {
+	route.Limits.MaxBody = size
+
+	return route
+}
+
+// RateLimit allows every client at most max requests of the route in a sliding window,
+// e.g. for the login or password reset endpoints. The limit is enforced by the router rate limiter,
+// see [WithRateLimiter], and the routes sharing a group limit are counted separately.
+// Negative window disables a limit set by the parent groups.
+func (route *Route) RateLimit(max uint, window time.Duration) *Route {
+	route.Limits.RateLimit = RateLimit{Max: max, Window: window}
+
+	return route
+}
+
+// Timeout sets the request context deadline of the group routes, see [Route.Timeout].
+func (group *RouterGroup) Timeout(timeout time.Duration) *RouterGroup {
+	group.Limits.Timeout = timeout
+
+	return group
+}
+
+// MaxBody limits the request body size of the group routes, see [Route.MaxBody].
+func (group *RouterGroup) MaxBody(size int64) *RouterGroup {
+	group.Limits.MaxBody = size
+
+	return group
+}
+
+// RateLimit allows every client at most max requests of each group route in a sliding window,
+// see [Route.RateLimit].
+func (group *RouterGroup) RateLimit(max uint, window time.Duration) *RouterGroup {
+	group.Limits.RateLimit = RateLimit{Max: max, Window: window}
+
+	return group
+}
+
+func timeoutMiddleware(timeout time.Duration) func(Handler) Handler {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			err := next.ServeHTTP(w, r.WithContext(ctx))
+			if err != nil && errors.Is(err, context.DeadlineExceeded) && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return ErrServiceUnavailable.Wrap(err)
+			}
+			return err
+		})
+	}
+}
+
+func maxBodyMiddleware(size int64) func(Handler) Handler {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if r.ContentLength > size {
+				return ErrRequestEntityTooLarge
+			}
+
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = &maxBodyReader{ReadCloser: r.Body, remaining: size}
+			}
+
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type maxBodyReader struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (r *maxBodyReader) Read(b []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, ErrRequestEntityTooLarge
+	}
+
+	// read one byte more than allowed to detect the overflow
+	if int64(len(b)) > r.remaining+1 {
+		b = b[:r.remaining+1]
+	}
+
+	n, err := r.ReadCloser.Read(b)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n + int(r.remaining), ErrRequestEntityTooLarge
+	}
+	return n, err
+}
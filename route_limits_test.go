@@ -0,0 +1,141 @@
+package keratin
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoute_Limits(t *testing.T) {
+	echo := func(w http.ResponseWriter, r *http.Request) error {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		return TextPlain(w, http.StatusOK, string(b))
+	}
+	wait := func(w http.ResponseWriter, r *http.Request) error {
+		select {
+		case <-r.Context().Done():
+			return r.Context().Err()
+		case <-time.After(time.Second):
+			return TextPlain(w, http.StatusOK, "done")
+		}
+	}
+
+	router := NewRouter()
+	api := router.Group("/api").MaxBody(4).Timeout(time.Second)
+	api.POST("/echo", echo)
+	api.POST("/large", echo).MaxBody(-1)
+	api.GET("/slow", wait).Timeout(10 * time.Millisecond)
+	api.Group("/inner").Timeout(20*time.Millisecond).GET("/slow", wait)
+
+	handler := router.Build()
+
+	tests := []struct {
+		name          string
+		method        string
+		target        string
+		body          string
+		contentLength int64
+		wantCode      int
+	}{
+		{name: "within limit", method: http.MethodPost, target: "/api/echo", body: "1234", contentLength: 4, wantCode: http.StatusOK},
+		{name: "content length over limit", method: http.MethodPost, target: "/api/echo", body: "12345", contentLength: 5, wantCode: http.StatusRequestEntityTooLarge},
+		{name: "streamed body over limit", method: http.MethodPost, target: "/api/echo", body: "12345", contentLength: -1, wantCode: http.StatusRequestEntityTooLarge},
+		{name: "route disables group limit", method: http.MethodPost, target: "/api/large", body: "12345", contentLength: -1, wantCode: http.StatusOK},
+		{name: "route timeout", method: http.MethodGet, target: "/api/slow", wantCode: http.StatusServiceUnavailable},
+		{name: "inner group timeout", method: http.MethodGet, target: "/api/inner/slow", wantCode: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			r.ContentLength = tt.contentLength
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestLimits_merge(t *testing.T) {
+	l := Limits{Timeout: time.Second, MaxBody: 10}
+
+	assert.Equal(t, l, l.merge(Limits{}))
+	assert.Equal(t, Limits{Timeout: time.Minute, MaxBody: 10}, l.merge(Limits{Timeout: time.Minute}))
+	assert.Equal(t, Limits{Timeout: time.Second, MaxBody: -1}, l.merge(Limits{MaxBody: -1}))
+	assert.Equal(t, Limits{Timeout: time.Second, MaxBody: 10, RateLimit: RateLimit{Max: 5, Window: time.Minute}}, l.merge(Limits{RateLimit: RateLimit{Max: 5, Window: time.Minute}}))
+	assert.Empty(t, Limits{Timeout: -1, MaxBody: -1, RateLimit: RateLimit{Max: 5, Window: -1}}.middlewares("GET /", func(string, RateLimit) func(Handler) Handler {
+		return func(next Handler) Handler { return next }
+	}))
+}
+
+func TestRoute_RateLimit(t *testing.T) {
+	var limited []string
+	rateLimiter := func(pattern string, limit RateLimit) func(Handler) Handler {
+		limited = append(limited, pattern)
+
+		var hits uint
+		return func(next Handler) Handler {
+			return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				if hits++; hits > limit.Max {
+					return ErrTooManyRequests
+				}
+				return next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	router := NewRouter(WithRateLimiter(rateLimiter))
+	router.POST("/login", func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, "ok")
+	}).RateLimit(2, time.Minute)
+
+	account := router.Group("/account").RateLimit(1, time.Minute)
+	account.POST("/reset", func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, "ok")
+	})
+	account.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, "ok")
+	}).RateLimit(0, -1)
+
+	handler := router.Build()
+	assert.ElementsMatch(t, []string{"POST /login", "POST /account/reset"}, limited)
+
+	serve := func(method, target string) int {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/login"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/login"))
+	assert.Equal(t, http.StatusTooManyRequests, serve(http.MethodPost, "/login"))
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/account/reset"))
+	assert.Equal(t, http.StatusTooManyRequests, serve(http.MethodPost, "/account/reset"))
+
+	for range 3 {
+		assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/account/"))
+	}
+}
+
+func TestRoute_RateLimit_WithoutRateLimiter(t *testing.T) {
+	router := NewRouter()
+	router.POST("/login", func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}).RateLimit(5, time.Minute)
+
+	assert.ErrorContains(t, router.Validate(), `route "POST /login": rate limit requires a rate limiter`)
+}
@@ -1,6 +1,7 @@
 package keratin
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -607,3 +608,70 @@ func TestRoute_Use_NilMiddleware(t *testing.T) {
 	assert.Len(t, route.Middlewares, 1)
 	assert.Nil(t, route.Middlewares[0].Func)
 }
+
+func TestRoute_OnError(t *testing.T) {
+	route := &Route{}
+
+	et1 := func(w http.ResponseWriter, r *http.Request, err error) error { return err }
+	et2 := func(w http.ResponseWriter, r *http.Request, err error) error { return nil }
+
+	result := route.OnError(et1).OnError(et2)
+
+	assert.Same(t, route, result)
+	assert.Len(t, route.ErrorTranslators, 2)
+}
+
+func TestRoute_DisableEnable(t *testing.T) {
+	router := NewRouter()
+	route := router.GET("/flag", func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, "on")
+	})
+	handler := router.Build()
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/flag", nil))
+		return w
+	}
+
+	assert.False(t, route.Disabled())
+	assert.Equal(t, http.StatusOK, serve().Code)
+
+	assert.Same(t, route, route.Disable())
+	assert.True(t, route.Disabled())
+	assert.Equal(t, http.StatusNotFound, serve().Code)
+	assert.Empty(t, collectPatterns(router.Patterns()))
+
+	assert.Same(t, route, route.Enable())
+	assert.False(t, route.Disabled())
+	assert.Equal(t, "on", serve().Body.String())
+	assert.Equal(t, []string{"GET /flag"}, collectPatterns(router.Patterns()))
+}
+
+func TestRoute_UseHTTP(t *testing.T) {
+	route := new(Route)
+	mw := &Middleware[http.Handler]{ID: "raw", Func: func(next http.Handler) http.Handler { return next }}
+
+	assert.Same(t, route, route.UseHTTP(mw))
+	assert.Same(t, route, route.UseHTTPFunc(func(next http.Handler) http.Handler { return next }))
+
+	require.Len(t, route.HTTPMiddlewares, 2)
+	assert.Same(t, mw, route.HTTPMiddlewares[0])
+}
+
+func TestRoute_Meta(t *testing.T) {
+	router := NewRouter()
+
+	var matched *Route
+	route := router.GET("/users", func(w http.ResponseWriter, r *http.Request) error {
+		matched = FromContext(r.Context()).Route()
+		return nil
+	}).SetMeta("roles", []string{"admin"})
+
+	assert.Equal(t, []string{"admin"}, route.Meta("roles"))
+	assert.Nil(t, route.Meta("missing"))
+
+	router.Build().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Same(t, route, matched)
+	assert.Nil(t, FromContext(context.Background()).Route())
+}
@@ -2,12 +2,17 @@ package keratin
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"iter"
-	"maps"
+	"log/slog"
+	"math"
 	"net/http"
+	"slices"
+	"strings"
 	"sync"
-
-	"github.com/gowool/keratin/internal"
+	"sync/atomic"
+	"time"
 )
 
 // MultipartMaxMemory is the maximum memory to use when parsing multipart form data.
@@ -34,7 +39,7 @@ func WithIPExtractor(ipExtractor IPExtractor) Option {
 func WithResponseInterceptor(interceptor func(w http.ResponseWriter) (http.ResponseWriter, func())) Option {
 	return func(router *Router) {
 		if interceptor != nil {
-			router.rwInterceptors = append(router.rwInterceptors, interceptor)
+			router.rwInterceptors = append(router.rwInterceptors, &Interceptor[http.ResponseWriter]{Func: interceptor})
 		}
 	}
 }
@@ -42,28 +47,123 @@ func WithResponseInterceptor(interceptor func(w http.ResponseWriter) (http.Respo
 func WithRequestInterceptor(interceptor func(r *http.Request) (*http.Request, func())) Option {
 	return func(router *Router) {
 		if interceptor != nil {
-			router.reqInterceptors = append(router.reqInterceptors, interceptor)
+			router.reqInterceptors = append(router.reqInterceptors, &Interceptor[*http.Request]{Func: interceptor})
+		}
+	}
+}
+
+// WithValidator sets the validator used by [BindAndValidate] and [Validate].
+func WithValidator(validator Validator) Option {
+	return func(router *Router) {
+		if validator != nil {
+			router.validator = validator
+		}
+	}
+}
+
+// WithNotFoundHandler sets the handler called when no route matches the request path.
+// The handler is wrapped with the root group middlewares and its error is passed to the error handler.
+func WithNotFoundHandler(handler Handler) Option {
+	return func(router *Router) {
+		if handler != nil {
+			router.notFoundHandler = handler
+		}
+	}
+}
+
+// WithMethodNotAllowedHandler sets the handler called when a route matches the request path
+// but not the request method. The Allow header is set before the handler is called.
+// The handler is wrapped with the root group middlewares and its error is passed to the error handler.
+func WithMethodNotAllowedHandler(handler Handler) Option {
+	return func(router *Router) {
+		if handler != nil {
+			router.methodNotAllowedHandler = handler
 		}
 	}
 }
 
+// WithAutoHead enables serving HEAD requests by the matching GET handlers
+// with the response body discarded. The response status code and size are still recorded.
+func WithAutoHead(enabled bool) Option {
+	return func(router *Router) {
+		router.autoHead = enabled
+	}
+}
+
+// WithBasePath serves all the routes under the base path (e.g. "/service-a"), so the same service
+// can be deployed behind different ingress path prefixes without touching the route definitions.
+//
+// The base path only prefixes the patterns registered in the [http.ServeMux]: the route paths,
+// the context pattern, [Router.Routes] and [Router.Remove] stay without it.
+// Use [BasePath] to build the URLs of the routes. It panics if path does not start with "/".
+func WithBasePath(path string) Option {
+	if path != "" && path[0] != '/' {
+		panic("keratin: base path must start with /")
+	}
+
+	return func(router *Router) {
+		router.basePath = strings.TrimRight(path, "/")
+	}
+}
+
+// WithRateLimiter sets the rate limiter enforcing the route rate limits, see [Route.RateLimit].
+// [Router.Validate] reports the rate limited routes when there is none.
+func WithRateLimiter(rateLimiter RateLimiterFunc) Option {
+	return func(router *Router) {
+		router.rateLimiter = rateLimiter
+	}
+}
+
 type rPattern struct {
 	pattern    string
 	methods    string
 	anyMethods bool
+	params     []string
 }
 
 type Router struct {
 	*RouterGroup
 
-	rwInterceptors  internal.Interceptors[http.ResponseWriter]
-	reqInterceptors internal.Interceptors[*http.Request]
-	patterns        map[string]struct{}
+	rwInterceptors  Interceptors[http.ResponseWriter]
+	reqInterceptors Interceptors[*http.Request]
+	patterns        map[string]*Route
 	rPatterns       map[string]*rPattern
 	ctxPool         sync.Pool
 	resPool         sync.Pool
 	ipExtractor     IPExtractor
+	validator       Validator
 	errorHandler    ErrorHandlerFunc
+	autoHead        bool
+	basePath        string
+	policy          Policy
+	rateLimiter     RateLimiterFunc
+	flushPolicy     FlushPolicy
+
+	preflightFastPath bool
+	preflightSkip     []string
+
+	pathNormalization *PathNormalization
+
+	maintenance           atomic.Pointer[maintenance]
+	maintenanceRetryAfter time.Duration
+
+	logger             *slog.Logger
+	debug              bool
+	debugSlowThreshold time.Duration
+	debugLogger        *slog.Logger
+
+	deferred deferredPool
+
+	rebuildMu sync.Mutex
+	handler   atomic.Pointer[http.Handler]
+
+	// routesMu guards the group tree and the patterns of the last build
+	// against the concurrent Rebuild, Remove and the introspection methods.
+	routesMu sync.RWMutex
+
+	notFoundHandler         Handler
+	methodNotAllowedHandler Handler
+
 	PreMiddlewares  Middlewares[Handler]
 	HTTPMiddlewares Middlewares[http.Handler]
 }
@@ -71,16 +171,46 @@ type Router struct {
 func NewRouter(options ...Option) *Router {
 	r := &Router{
 		RouterGroup:  new(RouterGroup),
-		patterns:     make(map[string]struct{}),
+		patterns:     make(map[string]*Route),
 		rPatterns:    make(map[string]*rPattern),
-		resPool:      sync.Pool{New: func() any { return new(response) }},
-		ctxPool:      sync.Pool{New: func() any { return new(kContext) }},
 		errorHandler: DefaultErrorHandler,
 		ipExtractor:  RemoteIP,
+
+		maintenanceRetryAfter: time.Minute,
+		debugSlowThreshold:    10 * time.Millisecond,
+		debugLogger:           slog.Default(),
 	}
 
-	r.rwInterceptors = append(r.rwInterceptors, r.responseInterceptor)
-	r.reqInterceptors = append(r.reqInterceptors, r.requestInterceptor)
+	// the pooled values keep their release functions, so that releasing them does not allocate
+	r.resPool.New = func() any {
+		res := new(response)
+		res.release = func() {
+			res.reset(nil)
+			r.resPool.Put(res)
+		}
+		return res
+	}
+	r.ctxPool.New = func() any {
+		c := new(kContext)
+		c.release = func() {
+			r.submitDeferred(c)
+			c.reset()
+			r.ctxPool.Put(c)
+		}
+		return c
+	}
+
+	// the router interceptors always run first
+	r.rwInterceptors = append(r.rwInterceptors, &Interceptor[http.ResponseWriter]{
+		ID:       "keratin.response",
+		Priority: math.MinInt,
+		Func:     r.responseInterceptor,
+	})
+	r.reqInterceptors = append(r.reqInterceptors, &Interceptor[*http.Request]{
+		ID:       "keratin.context",
+		Priority: math.MinInt,
+		Func:     r.requestInterceptor,
+	})
 
 	for _, option := range options {
 		option(r)
@@ -90,8 +220,61 @@ func NewRouter(options ...Option) *Router {
 }
 
 // Patterns returns a sequence of all route patterns currently registered in the router as strings.
+// Disabled and removed routes are skipped.
 func (r *Router) Patterns() iter.Seq[string] {
-	return maps.Keys(r.patterns)
+	r.routesMu.RLock()
+	patterns := make([]string, 0, len(r.patterns))
+	for pattern, route := range r.patterns {
+		if !route.Disabled() {
+			patterns = append(patterns, pattern)
+		}
+	}
+	r.routesMu.RUnlock()
+
+	return slices.Values(patterns)
+}
+
+// Remove unregisters the routes with the given method and full path (including the group prefixes)
+// and reports whether any route was removed. Use "" as method for the routes matching any method.
+//
+// Already built handlers respond to the removed routes with 404 Not Found
+// until the router is rebuilt, see [Router.Rebuild].
+func (r *Router) Remove(method, path string) bool {
+	method = strings.ToUpper(method)
+
+	pattern := path
+	if method != "" {
+		pattern = method + " " + path
+	}
+
+	r.routesMu.Lock()
+	defer r.routesMu.Unlock()
+
+	removed := r.remove(r.RouterGroup, "", method, path)
+	if removed {
+		delete(r.patterns, pattern)
+	}
+	return removed
+}
+
+func (r *Router) remove(group *RouterGroup, prefix, method, path string) (removed bool) {
+	prefix += group.prefix
+
+	group.children = slices.DeleteFunc(group.children, func(child any) bool {
+		switch v := child.(type) {
+		case *RouterGroup:
+			removed = r.remove(v, prefix, method, path) || removed
+		case *Route:
+			if v.Method == method && prefix+v.Path == path {
+				v.Disable()
+				removed = true
+				return true
+			}
+		}
+		return false
+	})
+
+	return removed
 }
 
 // PreHTTPFunc registers one or multiple HTTP middleware to be executed before all middlewares.
@@ -126,14 +309,213 @@ func (r *Router) Pre(middlewares ...*Middleware[Handler]) {
 	r.PreMiddlewares = append(r.PreMiddlewares, middlewares...)
 }
 
+// Validate checks all registered routes for invalid, duplicate or conflicting patterns
+// (e.g. "GET /users/{id}" and "GET /users/{name}") and middleware chains with cyclic
+// Before/After constraints, and returns the joined list of problems.
+//
+// [Router.Build] panics with the same error, so Validate can be used to fail gracefully at startup.
+func (r *Router) Validate() error {
+	r.routesMu.RLock()
+	defer r.routesMu.RUnlock()
+
+	var (
+		errs []error
+		mux  = http.NewServeMux()
+		noop = http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	)
+
+	if err := slices.Clone(r.PreMiddlewares).sort(); err != nil {
+		errs = append(errs, fmt.Errorf("pre middlewares: %w", err))
+	}
+	if err := slices.Clone(r.HTTPMiddlewares).sort(); err != nil {
+		errs = append(errs, fmt.Errorf("pre http middlewares: %w", err))
+	}
+
+	r.walk(r.RouterGroup, "", func(pattern string, route *Route, groups []*RouterGroup) {
+		if err := registerPattern(mux, r.muxPattern(route.Method, pattern), noop); err != nil {
+			errs = append(errs, err)
+		}
+
+		var (
+			middlewares     Middlewares[Handler]
+			httpMiddlewares Middlewares[http.Handler]
+			limits          Limits
+		)
+		for _, g := range groups {
+			middlewares = append(middlewares, g.Middlewares...)
+			httpMiddlewares = append(httpMiddlewares, g.HTTPMiddlewares...)
+			limits = limits.merge(g.Limits)
+		}
+		middlewares = append(middlewares, route.Middlewares...)
+		httpMiddlewares = append(httpMiddlewares, route.HTTPMiddlewares...)
+		limits = limits.merge(route.Limits)
+
+		if limits.rateLimited() && r.rateLimiter == nil {
+			errs = append(errs, fmt.Errorf("route %q: rate limit requires a rate limiter, see WithRateLimiter", pattern))
+		}
+
+		if err := middlewares.sort(); err != nil {
+			errs = append(errs, fmt.Errorf("route %q: %w", pattern, err))
+		}
+		if err := httpMiddlewares.sort(); err != nil {
+			errs = append(errs, fmt.Errorf("route %q: http %w", pattern, err))
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// walk calls fn for every route with its full pattern and the chain of groups from the root one.
+// The routes replaced by a later duplicate are skipped, see [DuplicatePatternReplaceLast].
+func (r *Router) walk(group *RouterGroup, prefix string, fn func(pattern string, route *Route, groups []*RouterGroup)) {
+	replaced := r.replacedRoutes()
+
+	r.walkGroups(group, prefix, nil, func(pattern string, route *Route, groups []*RouterGroup) {
+		if _, ok := replaced[route]; !ok {
+			fn(pattern, route, groups)
+		}
+	})
+}
+
+func (r *Router) walkGroups(group *RouterGroup, prefix string, parents []*RouterGroup, fn func(string, *Route, []*RouterGroup)) {
+	prefix += group.prefix
+	groups := append(parents[:len(parents):len(parents)], group)
+
+	for _, child := range group.children {
+		switch v := child.(type) {
+		case *RouterGroup:
+			r.walkGroups(v, prefix, groups, fn)
+		case *Route:
+			pattern := prefix + v.Path
+			if v.Method != "" {
+				pattern = v.Method + " " + pattern
+			}
+			fn(pattern, v, groups)
+		}
+	}
+}
+
+// muxPattern returns the [http.ServeMux] pattern of the route pattern with the base path
+// inserted before the path, see [WithBasePath].
+func (r *Router) muxPattern(method, pattern string) string {
+	if r.basePath == "" {
+		return pattern
+	}
+
+	pattern = strings.TrimPrefix(pattern, method+" ")
+	if i := strings.IndexByte(pattern, '/'); i >= 0 {
+		pattern = pattern[:i] + r.basePath + pattern[i:]
+	}
+
+	if method != "" {
+		pattern = method + " " + pattern
+	}
+	return pattern
+}
+
+// registerPattern registers the handler in mux and converts the ServeMux panic
+// on invalid or conflicting patterns into an error.
+func registerPattern(mux *http.ServeMux, pattern string, handler http.Handler) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("route %q: %v", pattern, rec)
+		}
+	}()
+
+	mux.Handle(pattern, handler)
+	return nil
+}
+
 func (r *Router) Build() http.Handler {
 	return r.BuildWithMux(http.NewServeMux())
 }
 
 func (r *Router) BuildWithMux(mux *http.ServeMux) http.Handler {
-	r.build(mux, r.RouterGroup, nil)
+	if err := r.Validate(); err != nil {
+		panic(fmt.Errorf("keratin: invalid routes: %w", err))
+	}
+
+	return r.buildHandler(mux)
+}
+
+// Handler returns a stable [http.Handler] which serves requests with the most recently
+// built routes, so that routes can be added or removed after the server started
+// and applied by [Router.Rebuild] without downtime.
+//
+// The routes are built on the first call if [Router.Rebuild] has not been called yet.
+// Handler panics if the registered routes are invalid.
+func (r *Router) Handler() http.Handler {
+	if r.handler.Load() == nil {
+		if err := r.Rebuild(); err != nil {
+			panic(fmt.Errorf("keratin: invalid routes: %w", err))
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		(*r.handler.Load()).ServeHTTP(w, req)
+	})
+}
+
+// Rebuild builds the registered routes into a new [http.ServeMux] and atomically swaps it
+// into the handler returned by [Router.Handler]. In-flight requests complete with the previous routes.
+//
+// If the routes are invalid, the error is returned and the current routes keep serving.
+// Rebuild must not be called concurrently with route registration.
+func (r *Router) Rebuild() error {
+	r.rebuildMu.Lock()
+	defer r.rebuildMu.Unlock()
+
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	handler := r.buildHandler(http.NewServeMux())
+	r.handler.Store(&handler)
+
+	return nil
+}
+
+func (r *Router) buildHandler(mux *http.ServeMux) http.Handler {
+	r.routesMu.Lock()
+	// the handlers of a previous build keep their own patterns
+	r.patterns = make(map[string]*Route)
+	r.rPatterns = make(map[string]*rPattern)
+	r.build(mux, r.RouterGroup, nil, r.replacedRoutes())
+	r.routesMu.Unlock()
+
+	notFound := r.fallbackHandler(r.notFoundHandler)
+	methodNotAllowed := r.fallbackHandler(r.methodNotAllowedHandler)
 
 	handler := r.PreMiddlewares.build(HandlerFunc(func(w http.ResponseWriter, req *http.Request) error {
+		resolved, slashNotFound := r.resolveTrailingSlash(mux, w, req)
+		if slashNotFound {
+			if notFound != nil {
+				return notFound.ServeHTTP(w, req)
+			}
+			http.NotFound(w, req)
+			return nil
+		}
+		if resolved == nil {
+			return nil
+		}
+		req = resolved
+
+		if notFound != nil || methodNotAllowed != nil {
+			if h, pattern := mux.Handler(req); pattern == "" {
+				// the mux has no matching route, find out whether it is a 404 or a 405
+				probe := &muxProbe{header: make(http.Header)}
+				h.ServeHTTP(probe, req)
+
+				switch {
+				case probe.code == http.StatusNotFound && notFound != nil:
+					return notFound.ServeHTTP(w, req)
+				case probe.code == http.StatusMethodNotAllowed && methodNotAllowed != nil:
+					w.Header().Set(HeaderAllow, probe.header.Get(HeaderAllow))
+					return methodNotAllowed.ServeHTTP(w, req)
+				}
+			}
+		}
+
 		mux.ServeHTTP(w, req)
 
 		return req.Context().Value(ctxKey{}).(*kContext).err
@@ -141,49 +523,108 @@ func (r *Router) BuildWithMux(mux *http.ServeMux) http.Handler {
 
 	httpHandler := r.HTTPMiddlewares.build(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		if err := handler.ServeHTTP(w, req); err != nil {
+			if IsClientDisconnect(err) {
+				LoggerFromContext(req.Context()).DebugContext(req.Context(), "client disconnected", slog.Any("error", err))
+			}
 			r.errorHandler(w, req, err)
 		}
 	}))
 
-	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		w, cancelW := r.rwInterceptors.Apply(w)
+	var preflightHandler http.Handler
+	if r.preflightFastPath {
+		preflightHandler = r.preflightHandler()
+	}
+
+	rwInterceptors := r.rwInterceptors.build()
+	reqInterceptors := r.reqInterceptors.build()
+
+	var root http.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w, cancelW := rwInterceptors.Apply(w)
 		defer cancelW()
 
-		req, cancelReq := r.reqInterceptors.Apply(req)
+		req, cancelReq := reqInterceptors.Apply(req)
 		defer cancelReq()
 
+		if preflightHandler != nil && IsPreflightRequest(req) {
+			preflightHandler.ServeHTTP(w, req)
+			return
+		}
+
 		httpHandler.ServeHTTP(w, req)
 	})
+
+	if r.pathNormalization != nil {
+		root = r.pathNormalization.handler(root)
+	}
+
+	return root
+}
+
+func (r *Router) fallbackHandler(handler Handler) Handler {
+	if handler == nil {
+		return nil
+	}
+	return r.Middlewares.build(r.ErrorTranslators.build(handler))
 }
 
-func (r *Router) build(mux *http.ServeMux, group *RouterGroup, parents []*RouterGroup) {
+func (r *Router) build(mux *http.ServeMux, group *RouterGroup, parents []*RouterGroup, replaced map[*Route]struct{}) {
 	for _, child := range group.children {
 		switch v := child.(type) {
 		case *RouterGroup:
-			r.build(mux, v, append(parents, group))
+			r.build(mux, v, append(parents, group), replaced)
 		case *Route:
+			if _, ok := replaced[v]; ok {
+				continue
+			}
+
 			var (
-				pattern     string
-				middlewares Middlewares[Handler]
+				pattern         string
+				middlewares     Middlewares[Handler]
+				httpMiddlewares Middlewares[http.Handler]
+				rwInterceptors  Interceptors[http.ResponseWriter]
+				reqInterceptors Interceptors[*http.Request]
+				limits          Limits
 			)
 
 			// add parent groups Middlewares
 			for _, p := range parents {
 				pattern += p.prefix
 				middlewares = append(middlewares, p.Middlewares...)
+				httpMiddlewares = append(httpMiddlewares, p.HTTPMiddlewares...)
+				rwInterceptors = append(rwInterceptors, p.ResponseInterceptors...)
+				reqInterceptors = append(reqInterceptors, p.RequestInterceptors...)
+				limits = limits.merge(p.Limits)
 			}
 
 			// add current groups Middlewares
 			pattern += group.prefix
 			middlewares = append(middlewares, group.Middlewares...)
+			httpMiddlewares = append(httpMiddlewares, group.HTTPMiddlewares...)
+			rwInterceptors = append(rwInterceptors, group.ResponseInterceptors...)
+			reqInterceptors = append(reqInterceptors, group.RequestInterceptors...)
+			limits = limits.merge(group.Limits)
 
 			// add current route Middlewares
 			pattern += v.Path
 			middlewares = append(middlewares, v.Middlewares...)
+			httpMiddlewares = append(httpMiddlewares, v.HTTPMiddlewares...)
+			rwInterceptors = append(rwInterceptors, v.ResponseInterceptors...)
+			reqInterceptors = append(reqInterceptors, v.RequestInterceptors...)
+			limits = limits.merge(v.Limits)
+
+			// the limits run before all the route middlewares
+			methodPattern := pattern
+			if v.Method != "" {
+				methodPattern = v.Method + " " + pattern
+			}
+			middlewares = append(limits.middlewares(methodPattern, r.rateLimiter), middlewares...)
+
+			routeRWInterceptors := rwInterceptors.build()
+			routeReqInterceptors := reqInterceptors.build()
 
 			rp, ok := r.rPatterns[pattern]
 			if !ok {
-				rp = &rPattern{pattern: pattern}
+				rp = &rPattern{pattern: pattern, params: patternParams(pattern)}
 				r.rPatterns[pattern] = rp
 			}
 
@@ -199,20 +640,71 @@ func (r *Router) build(mux *http.ServeMux, group *RouterGroup, parents []*Router
 				pattern = v.Method + " " + pattern
 			}
 
-			r.patterns[pattern] = struct{}{}
+			r.patterns[pattern] = v
 
-			handler := middlewares.build(v.Handler)
+			// compose error translators from the innermost (route) to the outermost (root group)
+			// inside the middlewares, so that they see the translated errors
+			handler := v.ErrorTranslators.build(v.constraintsHandler(v.Handler))
+			handler = group.ErrorTranslators.build(handler)
+			for i := len(parents) - 1; i >= 0; i-- {
+				handler = parents[i].ErrorTranslators.build(handler)
+			}
+
+			handler = r.traceMiddlewares(middlewares).build(handler)
 
-			mux.HandleFunc(pattern, func(w http.ResponseWriter, req *http.Request) {
+			// the whole route chain is composed here once, the routes without http middlewares
+			// and interceptors call it straight from the mux handler
+			var httpHandler http.Handler
+			if len(httpMiddlewares) > 0 {
+				httpHandler = httpMiddlewares.build(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					c := req.Context().Value(ctxKey{}).(*kContext)
+					c.err = handler.ServeHTTP(w, req)
+				}))
+			}
+			intercepted := len(routeRWInterceptors) > 0 || len(routeReqInterceptors) > 0
+
+			autoHead := r.autoHead && (v.Method == "" || v.Method == http.MethodGet)
+			routePattern := pattern
+
+			mux.HandleFunc(r.muxPattern(v.Method, pattern), func(w http.ResponseWriter, req *http.Request) {
 				c := req.Context().Value(ctxKey{}).(*kContext)
 
-				if current, ok := r.rPatterns[Pattern(req)]; ok {
-					c.pattern = current.pattern
-					c.methods = current.methods
-					c.anyMethods = current.anyMethods
+				if v.Disabled() {
+					c.err = ErrNotFound
+					return
+				}
+
+				if autoHead && req.Method == http.MethodHead {
+					w = &headWriter{ResponseWriter: w}
+				}
+
+				c.pattern = rp.pattern
+				c.methods = rp.methods
+				c.anyMethods = rp.anyMethods
+				c.route = v
+				c.paramNames = rp.params
+				c.setParams(req.PathValue)
+
+				if c.err = r.checkMaintenance(routePattern, rp.pattern); c.err != nil {
+					return
+				}
+
+				if intercepted {
+					var cancelW, cancelReq func()
+
+					w, cancelW = routeRWInterceptors.Apply(w)
+					defer cancelW()
+
+					req, cancelReq = routeReqInterceptors.Apply(req)
+					defer cancelReq()
 				}
 
-				c.err = handler.ServeHTTP(w, req)
+				if httpHandler == nil {
+					c.err = handler.ServeHTTP(w, req)
+					return
+				}
+
+				httpHandler.ServeHTTP(w, req)
 			})
 		}
 	}
@@ -221,27 +713,54 @@ func (r *Router) build(mux *http.ServeMux, group *RouterGroup, parents []*Router
 func (r *Router) responseInterceptor(w http.ResponseWriter) (http.ResponseWriter, func()) {
 	res := r.resPool.Get().(*response)
 	res.reset(w)
+	res.flushPolicy = r.flushPolicy
+	res.logger = r.logger
 
-	cancel := func() {
-		res.reset(nil)
-		r.resPool.Put(res)
-	}
-
-	return res, cancel
+	return res, res.release
 }
 
 func (r *Router) requestInterceptor(req *http.Request) (*http.Request, func()) {
 	c := r.ctxPool.Get().(*kContext)
-	cancel := func() {
-		c.reset()
-		r.ctxPool.Put(c)
-	}
 
 	c.scheme = Scheme(req)
 	c.realIP = r.ipExtractor(req)
+	c.validator = r.validator
+	c.baseLogger = r.logger
+	c.basePath = r.basePath
+	c.startTime = time.Now()
+	c.header = req.Header
+	if r.debug {
+		c.trace = new(chainTrace)
+	}
+
+	c.parent = req.Context()
 
 	ctx := context.WithValue(req.Context(), ctxKey{}, c)
 	req = req.WithContext(ctx)
 
-	return req, cancel
+	return req, c.release
+}
+
+// muxProbe records the status code and headers of the ServeMux fallback (404/405) handlers
+// and discards their body.
+type muxProbe struct {
+	header http.Header
+	code   int
+}
+
+func (p *muxProbe) Header() http.Header {
+	return p.header
+}
+
+func (p *muxProbe) WriteHeader(statusCode int) {
+	if p.code == 0 {
+		p.code = statusCode
+	}
+}
+
+func (p *muxProbe) Write(b []byte) (int, error) {
+	if p.code == 0 {
+		p.code = http.StatusOK
+	}
+	return len(b), nil
 }
@@ -1,10 +1,14 @@
 package keratin
 
 import (
+	"context"
 	"errors"
 	"iter"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -1637,3 +1641,684 @@ func collectPatterns(seq iter.Seq[string]) []string {
 	})
 	return patterns
 }
+
+func TestRouter_ErrorTranslators(t *testing.T) {
+	errDomain := errors.New("domain error")
+
+	tests := []struct {
+		name      string
+		setup     func(r *Router) *[]string
+		path      string
+		wantCode  int
+		wantBody  string
+		wantOrder []string
+	}{
+		{
+			name: "route translator converts error",
+			setup: func(r *Router) *[]string {
+				r.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+					return errDomain
+				}).OnError(func(w http.ResponseWriter, r *http.Request, err error) error {
+					if errors.Is(err, errDomain) {
+						return ErrUnprocessableEntity.Wrap(err)
+					}
+					return err
+				})
+				return nil
+			},
+			path:     "/",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "Unprocessable Entity\n",
+		},
+		{
+			name: "translator handles error",
+			setup: func(r *Router) *[]string {
+				r.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+					return errDomain
+				}).OnError(func(w http.ResponseWriter, r *http.Request, err error) error {
+					return TextPlain(w, http.StatusAccepted, "handled")
+				})
+				return nil
+			},
+			path:     "/",
+			wantCode: http.StatusAccepted,
+			wantBody: "handled",
+		},
+		{
+			name: "translators compose from route to root",
+			setup: func(r *Router) *[]string {
+				var order []string
+				translator := func(name string) ErrorTranslatorFunc {
+					return func(w http.ResponseWriter, r *http.Request, err error) error {
+						order = append(order, name)
+						return err
+					}
+				}
+
+				r.OnError(translator("root"))
+				api := r.Group("/api").OnError(translator("api"))
+				v1 := api.Group("/v1").OnError(translator("v1-1"), translator("v1-2"))
+				v1.GET("/test", func(w http.ResponseWriter, r *http.Request) error {
+					return ErrConflict
+				}).OnError(translator("route"))
+
+				return &order
+			},
+			path:      "/api/v1/test",
+			wantCode:  http.StatusConflict,
+			wantBody:  "Conflict\n",
+			wantOrder: []string{"route", "v1-1", "v1-2", "api", "root"},
+		},
+		{
+			name: "translators are not called without error",
+			setup: func(r *Router) *[]string {
+				r.GET("/", func(w http.ResponseWriter, r *http.Request) error {
+					return TextPlain(w, http.StatusOK, "ok")
+				}).OnError(func(w http.ResponseWriter, r *http.Request, err error) error {
+					panic("must not be called")
+				})
+				return nil
+			},
+			path:     "/",
+			wantCode: http.StatusOK,
+			wantBody: "ok",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := NewRouter()
+			order := tt.setup(router)
+
+			w := httptest.NewRecorder()
+			router.Build().ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, tt.wantBody, w.Body.String())
+			if tt.wantOrder != nil {
+				assert.Equal(t, tt.wantOrder, *order)
+			}
+		})
+	}
+}
+
+func TestRouter_ErrorTranslatorsInsideMiddlewares(t *testing.T) {
+	errDomain := errors.New("domain error")
+
+	var seen []int
+	observe := func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			err := next.ServeHTTP(w, r)
+			seen = append(seen, HTTPErrorStatusCode(err))
+			return err
+		})
+	}
+
+	router := NewRouter()
+	router.UseFunc(observe)
+	router.OnError(func(w http.ResponseWriter, r *http.Request, err error) error {
+		if errors.Is(err, errDomain) {
+			return ErrUnprocessableEntity.Wrap(err)
+		}
+		return err
+	})
+
+	api := router.Group("/api")
+	api.UseFunc(observe)
+	api.GET("/test", func(w http.ResponseWriter, r *http.Request) error {
+		return errDomain
+	}).UseFunc(observe)
+
+	// the errors of the middlewares are not translated
+	api.GET("/denied", func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}).UseFunc(func(Handler) Handler {
+		return HandlerFunc(func(http.ResponseWriter, *http.Request) error {
+			return errDomain
+		})
+	})
+
+	handler := router.Build()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/test", nil))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, []int{http.StatusUnprocessableEntity, http.StatusUnprocessableEntity, http.StatusUnprocessableEntity}, seen)
+
+	seen = nil
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/denied", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, []int{http.StatusInternalServerError, http.StatusInternalServerError}, seen)
+}
+
+func TestRouter_NotFoundAndMethodNotAllowedHandlers(t *testing.T) {
+	var handledErr error
+
+	router := NewRouter(
+		WithNotFoundHandler(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return ErrNotFound
+		})),
+		WithMethodNotAllowedHandler(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return TextPlain(w, http.StatusMethodNotAllowed, "custom 405")
+		})),
+		WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+			handledErr = err
+			DefaultErrorHandler(w, r, err)
+		}),
+	)
+	router.UseFunc(func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			w.Header().Set("X-Middleware", "1")
+			return next.ServeHTTP(w, r)
+		})
+	})
+	router.GET("/test", func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, "ok")
+	})
+	router.DELETE("/test", func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	handler := router.Build()
+
+	t.Run("matched route", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "ok", w.Body.String())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		handledErr = nil
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/missing", nil)
+		r.Header.Set(HeaderAccept, MIMEApplicationJSON)
+		handler.ServeHTTP(w, r)
+
+		assert.ErrorIs(t, handledErr, ErrNotFound)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "1", w.Header().Get("X-Middleware"))
+		assert.JSONEq(t, `{"code":404,"message":"Not Found"}`, w.Body.String())
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/test", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "1", w.Header().Get("X-Middleware"))
+		assert.Equal(t, "DELETE, GET, HEAD", w.Header().Get(HeaderAllow))
+		assert.Equal(t, "custom 405", w.Body.String())
+	})
+
+	t.Run("trailing slash redirect is kept", func(t *testing.T) {
+		router := NewRouter(WithNotFoundHandler(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return ErrNotFound
+		})))
+		router.GET("/dir/", func(w http.ResponseWriter, r *http.Request) error { return nil })
+
+		w := httptest.NewRecorder()
+		router.Build().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dir", nil))
+
+		assert.Equal(t, "/dir/", w.Header().Get(HeaderLocation))
+	})
+}
+
+func TestRouter_WithAutoHead(t *testing.T) {
+	tests := []struct {
+		name     string
+		autoHead bool
+		method   string
+		wantBody string
+		wantSize int64
+	}{
+		{name: "GET request", autoHead: true, method: http.MethodGet, wantBody: "hello", wantSize: 5},
+		{name: "HEAD request", autoHead: true, method: http.MethodHead, wantSize: 5},
+		{name: "HEAD request without auto head", method: http.MethodHead, wantBody: "hello", wantSize: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				gotSize int64
+				gotCode int
+			)
+
+			router := NewRouter(WithAutoHead(tt.autoHead))
+			router.UseFunc(func(next Handler) Handler {
+				return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+					err := next.ServeHTTP(w, r)
+					gotSize = ResponseSize(w)
+					gotCode = ResponseStatusCode(w)
+					return err
+				})
+			})
+			router.GET("/test", func(w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("X-Test", "1")
+				return TextPlain(w, http.StatusAccepted, "hello")
+			})
+
+			w := httptest.NewRecorder()
+			router.Build().ServeHTTP(w, httptest.NewRequest(tt.method, "/test", nil))
+
+			assert.Equal(t, http.StatusAccepted, w.Code)
+			assert.Equal(t, "1", w.Header().Get("X-Test"))
+			assert.Equal(t, tt.wantBody, w.Body.String())
+			assert.Equal(t, tt.wantSize, gotSize)
+			assert.Equal(t, http.StatusAccepted, gotCode)
+		})
+	}
+}
+
+func TestRouter_WithAutoHead_ExplicitHeadRoute(t *testing.T) {
+	router := NewRouter(WithAutoHead(true))
+	router.GET("/test", func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, "get")
+	})
+	router.HEAD("/test", func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("X-Head", "1")
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	router.Build().ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/test", nil))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-Head"))
+}
+
+func TestRouter_Validate(t *testing.T) {
+	noop := func(w http.ResponseWriter, r *http.Request) error { return nil }
+
+	t.Run("valid routes", func(t *testing.T) {
+		router := NewRouter()
+		router.GET("/users/{id}", noop)
+		router.GET("/users/admin", noop)
+		router.POST("/users/{id}", noop)
+		router.Group("/api").GET("/users/{id}", noop)
+
+		assert.NoError(t, router.Validate())
+		assert.NotPanics(t, func() { router.Build() })
+	})
+
+	t.Run("duplicate and conflicting routes", func(t *testing.T) {
+		router := NewRouter()
+		router.GET("/users/{id}", noop)
+		router.GET("/users/{name}", noop)
+		api := router.Group("/api")
+		api.GET("/items", noop)
+		api.GET("/items", noop)
+		router.GET("/ok", noop)
+
+		err := router.Validate()
+		require.Error(t, err)
+
+		msg := err.Error()
+		assert.Contains(t, msg, `route "GET /users/{name}"`)
+		assert.Contains(t, msg, `route "GET /api/items"`)
+		assert.NotContains(t, msg, "/ok")
+
+		assert.PanicsWithError(t, "keratin: invalid routes: "+msg, func() { router.Build() })
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		router := NewRouter()
+		router.GET("/users/{id", noop)
+
+		assert.ErrorContains(t, router.Validate(), `route "GET /users/{id"`)
+	})
+}
+
+func TestRouter_HandlerAndRebuild(t *testing.T) {
+	router := NewRouter()
+	router.GET("/a", func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, "a")
+	})
+
+	handler := router.Handler()
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	assert.Equal(t, "a", serve("/a").Body.String())
+	assert.Equal(t, http.StatusNotFound, serve("/b").Code)
+
+	router.GET("/b/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, "b"+r.PathValue("id"))
+	})
+
+	// not applied until rebuilt
+	assert.Equal(t, http.StatusNotFound, serve("/b/1").Code)
+
+	require.NoError(t, router.Rebuild())
+	assert.Equal(t, "b1", serve("/b/1").Body.String())
+	assert.Equal(t, "a", serve("/a").Body.String())
+	assert.ElementsMatch(t, []string{"GET /a", "GET /b/{id}"}, slices.Collect(router.Patterns()))
+
+	// invalid routes keep the current handler
+	router.GET("/b/{name}", func(w http.ResponseWriter, r *http.Request) error { return nil })
+	require.Error(t, router.Rebuild())
+	assert.Equal(t, "b1", serve("/b/1").Body.String())
+}
+
+func TestRouter_Handler_InvalidRoutes(t *testing.T) {
+	router := NewRouter()
+	router.GET("/{id}", func(w http.ResponseWriter, r *http.Request) error { return nil })
+	router.GET("/{name}", func(w http.ResponseWriter, r *http.Request) error { return nil })
+
+	assert.Panics(t, func() { router.Handler() })
+}
+
+func TestRouter_Rebuild_Concurrent(t *testing.T) {
+	router := NewRouter()
+	router.GET("/a", func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, Pattern(r))
+	})
+	handler := router.Handler()
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for range 100 {
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/a", nil))
+				assert.Equal(t, http.StatusOK, w.Code)
+			}
+		})
+	}
+	for range 10 {
+		require.NoError(t, router.Rebuild())
+	}
+	wg.Wait()
+}
+
+func TestRouter_Remove_ConcurrentRebuild(t *testing.T) {
+	router := NewRouter()
+	for i := range 50 {
+		router.GET("/r"+strconv.Itoa(i), func(w http.ResponseWriter, r *http.Request) error { return nil })
+	}
+	router.Handler()
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for i := range 50 {
+			assert.True(t, router.Remove(http.MethodGet, "/r"+strconv.Itoa(i)))
+		}
+	})
+	wg.Go(func() {
+		for range 20 {
+			_ = router.Routes()
+			_ = slices.Collect(router.Patterns())
+		}
+	})
+	for range 10 {
+		require.NoError(t, router.Rebuild())
+	}
+	wg.Wait()
+
+	require.NoError(t, router.Rebuild())
+	assert.Empty(t, router.Routes())
+	assert.Empty(t, collectPatterns(router.Patterns()))
+}
+
+func TestRouter_WithBasePath(t *testing.T) {
+	router := NewRouter(WithBasePath("/service-a/"))
+	router.GET("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, FromContext(r.Context()).Pattern()+" "+BasePath(r.Context())+"/users/"+r.PathValue("id"))
+	})
+	router.Group("example.com/api").Any("/health", func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, "ok")
+	})
+
+	handler := router.Build()
+
+	tests := []struct {
+		name     string
+		target   string
+		wantCode int
+		wantBody string
+	}{
+		{name: "base path", target: "/service-a/users/1", wantCode: http.StatusOK, wantBody: "/users/{id} /service-a/users/1"},
+		{name: "without base path", target: "/users/1", wantCode: http.StatusNotFound},
+		{name: "host group", target: "http://example.com/service-a/api/health", wantCode: http.StatusOK, wantBody: "ok"},
+		{name: "host group without base path", target: "http://example.com/api/health", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+
+	assert.ElementsMatch(t, []string{"GET /users/{id}", "example.com/api/health"}, collectPatterns(router.Patterns()))
+	assert.True(t, router.Remove(http.MethodGet, "/users/{id}"))
+
+	assert.Equal(t, "", BasePath(context.Background()))
+	assert.PanicsWithValue(t, "keratin: base path must start with /", func() { WithBasePath("service-a") })
+}
+
+func TestRouter_Remove(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, "ok")
+	}
+
+	router := NewRouter()
+	api := router.Group("/api")
+	api.GET("/users", ok)
+	api.POST("/users", ok)
+	api.Any("/health", ok)
+
+	handler := router.Handler()
+
+	serve := func(method, path string) int {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code
+	}
+
+	assert.False(t, router.Remove(http.MethodGet, "/users"))
+	assert.False(t, router.Remove(http.MethodDelete, "/api/users"))
+
+	assert.True(t, router.Remove("get", "/api/users"))
+	assert.True(t, router.Remove("", "/api/health"))
+	assert.False(t, router.Remove(http.MethodGet, "/api/users"))
+
+	assert.Equal(t, []string{"POST /api/users"}, collectPatterns(router.Patterns()))
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/api/users"))
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/api/health"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/users"))
+
+	require.NoError(t, router.Rebuild())
+	assert.Equal(t, []string{"POST /api/users"}, collectPatterns(router.Patterns()))
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodGet, "/api/users"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/users"))
+}
+
+func TestRouter_GroupHTTPMiddlewares(t *testing.T) {
+	var order []string
+
+	raw := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				w.Header().Add("X-Raw", name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	router := NewRouter()
+	router.PreHTTPFunc(raw("pre"))
+	router.UseFunc(func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			order = append(order, "use")
+			return next.ServeHTTP(w, r)
+		})
+	})
+
+	api := router.Group("/api").UseHTTPFunc(raw("api"))
+	api.GET("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		order = append(order, "handler:"+FromContext(r.Context()).Params()["id"])
+		return ErrTeapot
+	}).UseHTTPFunc(raw("route"))
+	router.GET("/public", func(w http.ResponseWriter, r *http.Request) error {
+		order = append(order, "public")
+		return nil
+	})
+
+	handler := router.Build()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users/1", nil))
+	assert.Equal(t, []string{"pre", "api", "route", "use", "handler:1"}, order)
+	assert.Equal(t, []string{"pre", "api", "route"}, w.Header().Values("X-Raw"))
+	assert.Equal(t, http.StatusTeapot, w.Code)
+
+	order = nil
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/public", nil))
+	assert.Equal(t, []string{"pre", "use", "public"}, order)
+	assert.Equal(t, []string{"pre"}, w.Header().Values("X-Raw"))
+}
+
+func TestRouter_Validate_MiddlewareCycle(t *testing.T) {
+	noop := func(next Handler) Handler { return next }
+
+	router := NewRouter()
+	router.Use(&Middleware[Handler]{ID: "a", Func: noop, Before: []string{"b"}})
+	router.GET("/test", func(w http.ResponseWriter, r *http.Request) error { return nil }).
+		Use(&Middleware[Handler]{ID: "b", Func: noop, Before: []string{"a"}})
+
+	err := router.Validate()
+	require.ErrorIs(t, err, ErrMiddlewareCycle)
+	assert.Contains(t, err.Error(), `route "GET /test"`)
+	assert.Panics(t, func() { router.Build() })
+	assert.Error(t, router.Rebuild())
+}
+
+func BenchmarkRouter_ServeHTTP(b *testing.B) {
+	noContent := func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+
+	benchmarks := []struct {
+		name  string
+		setup func(router *Router)
+		path  string
+	}{
+		{
+			name:  "static",
+			setup: func(router *Router) { router.GET("/users", noContent) },
+			path:  "/users",
+		},
+		{
+			name:  "params",
+			setup: func(router *Router) { router.GET("/users/{id}/posts/{post}", noContent) },
+			path:  "/users/42/posts/7",
+		},
+		{
+			name: "middlewares and interceptors",
+			setup: func(router *Router) {
+				passThrough := func(next Handler) Handler { return next }
+				router.UseFunc(passThrough)
+				router.InterceptResponse(&Interceptor[http.ResponseWriter]{
+					Func: func(w http.ResponseWriter) (http.ResponseWriter, func()) { return w, nil },
+				})
+
+				api := router.Group("/api").UseFunc(passThrough)
+				api.Group("/v1").UseFunc(passThrough).GET("/users/{id}", noContent).UseFunc(passThrough)
+			},
+			path: "/api/v1/users/42",
+		},
+		{
+			name:  "deep groups",
+			setup: func(router *Router) { deepGroups(router, 8, 2).GET("/users/{id}", noContent) },
+			path:  "/g/g/g/g/g/g/g/g/users/42",
+		},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			router := NewRouter()
+			bm.setup(router)
+			handler := router.Build()
+
+			req := httptest.NewRequest(http.MethodGet, bm.path, nil)
+			w := httptest.NewRecorder()
+
+			b.ReportAllocs()
+			for b.Loop() {
+				handler.ServeHTTP(w, req)
+			}
+		})
+	}
+}
+
+// deepGroups nests depth groups with the "/g" prefix, each with perGroup pass-through middlewares.
+func deepGroups(router *Router, depth, perGroup int) *RouterGroup {
+	group := router.RouterGroup
+	for range depth {
+		group = group.Group("/g")
+		for range perGroup {
+			group.UseFunc(func(next Handler) Handler { return next })
+		}
+	}
+	return group
+}
+
+func TestRouter_PrecomposedChains(t *testing.T) {
+	var composed int
+
+	router := NewRouter()
+	group := deepGroups(router, 8, 2)
+	group.UseFunc(func(next Handler) Handler {
+		composed++
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return next.ServeHTTP(w, r)
+		})
+	})
+	group.GET("/deep", func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+	router.GET("/flat", func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+
+	handler := router.Build()
+	require.Equal(t, 1, composed)
+
+	serve := func(path string) func() {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		return func() {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusNoContent, rec.Code)
+		}
+	}
+
+	// the recorder allocations are the same for both routes
+	deep := testing.AllocsPerRun(100, serve("/g/g/g/g/g/g/g/g/deep"))
+	flat := testing.AllocsPerRun(100, serve("/flat"))
+
+	assert.Equal(t, 1, composed, "the chains must not be composed per request")
+
+	if raceEnabled {
+		t.Skip("the race detector adds allocations")
+	}
+	assert.Equal(t, flat, deep, "group depth must not add per-request allocations")
+}
@@ -0,0 +1,182 @@
+package keratin
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"runtime"
+	"slices"
+)
+
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	// Method is the route method, empty for the routes matching any method.
+	Method string `json:"method,omitempty"`
+
+	// Pattern is the full [http.ServeMux] pattern, e.g. "GET /api/users/{id}".
+	Pattern string `json:"pattern"`
+
+	// Path is the full route path including the group prefixes.
+	Path string `json:"path"`
+
+	// Group is the concatenated prefix of the route groups.
+	Group string `json:"group,omitempty"`
+
+	// Handler is the name of the route handler function or its type.
+	Handler string `json:"handler"`
+
+	// Middlewares are the route middleware IDs (or function names of the anonymous ones)
+	// in their execution order, including the group middlewares.
+	Middlewares []string `json:"middlewares,omitempty"`
+
+	// Consumes are the accepted request body media types, see [Route.Accepts].
+	Consumes []string `json:"consumes,omitempty"`
+
+	// RequiredHeaders are the required request headers, see [Route.RequiresHeaders].
+	RequiredHeaders []string `json:"requiredHeaders,omitempty"`
+
+	// RequestSchema is the JSON Schema document of the request body, see [Route.Schema].
+	RequestSchema string `json:"requestSchema,omitempty"`
+
+	// ResponseSchema is the JSON Schema document of the successful responses, see [Route.Returns].
+	ResponseSchema string `json:"responseSchema,omitempty"`
+
+	// Disabled reports whether the route is disabled, see [Route.Disable].
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// Routes returns the registered routes sorted by path and method.
+func (r *Router) Routes() []RouteInfo {
+	r.routesMu.RLock()
+	defer r.routesMu.RUnlock()
+
+	var routes []RouteInfo
+
+	r.walk(r.RouterGroup, "", func(pattern string, route *Route, groups []*RouterGroup) {
+		var (
+			group       string
+			middlewares Middlewares[Handler]
+		)
+		for _, g := range groups {
+			group += g.prefix
+			middlewares = append(middlewares, g.Middlewares...)
+		}
+		middlewares = append(middlewares, route.Middlewares...)
+
+		routes = append(routes, RouteInfo{
+			Method:          route.Method,
+			Pattern:         pattern,
+			Path:            group + route.Path,
+			Group:           group,
+			Handler:         handlerName(route.Handler),
+			Middlewares:     middlewares.names(),
+			Consumes:        slices.Clone(route.Consumes),
+			RequiredHeaders: slices.Clone(route.RequiredHeaders),
+			RequestSchema:   route.RequestSchema,
+			ResponseSchema:  route.ResponseSchema,
+			Disabled:        route.Disabled(),
+		})
+	})
+
+	slices.SortStableFunc(routes, func(a, b RouteInfo) int {
+		return cmp.Or(cmp.Compare(a.Path, b.Path), cmp.Compare(a.Method, b.Method))
+	})
+
+	return routes
+}
+
+// LogRoutes logs one record per registered route with its method, pattern,
+// middleware chain and handler name. A nil logger falls back to [slog.Default].
+func (r *Router) LogRoutes(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	ctx := context.Background()
+
+	for _, route := range r.Routes() {
+		logger.LogAttrs(ctx, slog.LevelInfo, "route",
+			slog.String("method", cmp.Or(route.Method, "*")),
+			slog.String("pattern", route.Pattern),
+			slog.String("handler", route.Handler),
+			slog.Any("middlewares", route.Middlewares),
+			slog.Bool("disabled", route.Disabled),
+		)
+	}
+}
+
+// names returns the middleware IDs, or function names for the anonymous middlewares,
+// in the execution order without sorting the middlewares in place.
+func (mws Middlewares[H]) names() []string {
+	if len(mws) == 0 {
+		return nil
+	}
+
+	sorted := slices.Clone(mws)
+	_ = sorted.sort()
+
+	names := make([]string, len(sorted))
+	for i, mw := range sorted {
+		names[i] = cmp.Or(mw.ID, handlerName(mw.Func))
+	}
+	return names
+}
+
+// handlerName returns the function name of h or its type name.
+func handlerName(h any) string {
+	if h == nil {
+		return ""
+	}
+
+	v := reflect.ValueOf(h)
+	if v.Kind() == reflect.Func && !v.IsNil() {
+		if fn := runtime.FuncForPC(v.Pointer()); fn != nil {
+			return fn.Name()
+		}
+	}
+
+	return fmt.Sprintf("%T", h)
+}
+
+var debugRoutesTemplate = template.Must(template.New("routes").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Routes</title></head>
+<body>
+<table>
+<thead><tr><th>Method</th><th>Path</th><th>Group</th><th>Handler</th><th>Middlewares</th><th>Disabled</th></tr></thead>
+<tbody>
+{{- range .}}
+<tr><td>{{or .Method "*"}}</td><td>{{.Path}}</td><td>{{.Group}}</td><td>{{.Handler}}</td><td>{{range $i, $m := .Middlewares}}{{if $i}}<br>{{end}}{{$m}}{{end}}</td><td>{{if .Disabled}}yes{{end}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
+
+// DebugRoutesHandler returns a handler which renders the router routes (see [Router.Routes])
+// as an HTML table when the client prefers text/html, otherwise as JSON.
+func DebugRoutesHandler(router *Router) Handler {
+	if router == nil {
+		panic("keratin: debug routes handler requires a router")
+	}
+
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		routes := router.Routes()
+
+		if NegotiateContentType(r, MIMEApplicationJSON, MIMETextHTML) != MIMETextHTML {
+			return JSON(w, http.StatusOK, routes)
+		}
+
+		var buf bytes.Buffer
+		if err := debugRoutesTemplate.Execute(&buf, routes); err != nil {
+			return err
+		}
+		return HTMLBlob(w, http.StatusOK, buf.Bytes())
+	})
+}
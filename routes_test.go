@@ -0,0 +1,135 @@
+package keratin
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func routesTestHandler(http.ResponseWriter, *http.Request) error { return nil }
+
+type routesTestHandlerType struct{}
+
+func (routesTestHandlerType) ServeHTTP(http.ResponseWriter, *http.Request) error { return nil }
+
+func routesTestMiddleware(next Handler) Handler { return next }
+
+func newRoutesTestRouter() *Router {
+	router := NewRouter()
+	router.Use(&Middleware[Handler]{ID: "logger", Func: routesTestMiddleware})
+
+	api := router.Group("/api")
+	api.Use(&Middleware[Handler]{ID: "auth", Func: routesTestMiddleware})
+	api.Use(&Middleware[Handler]{ID: "recover", Priority: -1, Func: routesTestMiddleware})
+
+	api.GET("/users/{id}", routesTestHandler).UseFunc(routesTestMiddleware)
+	api.Route(http.MethodPost, "/users", routesTestHandlerType{}).Disable()
+	router.Any("/health", routesTestHandler)
+
+	return router
+}
+
+func TestRouter_Routes(t *testing.T) {
+	routes := newRoutesTestRouter().Routes()
+
+	require.Len(t, routes, 3)
+
+	assert.Equal(t, RouteInfo{
+		Method:      http.MethodPost,
+		Pattern:     "POST /api/users",
+		Path:        "/api/users",
+		Group:       "/api",
+		Handler:     "keratin.routesTestHandlerType",
+		Middlewares: []string{"recover", "logger", "auth"},
+		Disabled:    true,
+	}, routes[0])
+
+	assert.Equal(t, RouteInfo{
+		Method:  http.MethodGet,
+		Pattern: "GET /api/users/{id}",
+		Path:    "/api/users/{id}",
+		Group:   "/api",
+		Handler: "github.com/gowool/keratin.routesTestHandler",
+		Middlewares: []string{
+			"recover", "logger", "auth", "github.com/gowool/keratin.routesTestMiddleware",
+		},
+	}, routes[1])
+
+	assert.Equal(t, RouteInfo{
+		Pattern:     "/health",
+		Path:        "/health",
+		Handler:     "github.com/gowool/keratin.routesTestHandler",
+		Middlewares: []string{"logger"},
+	}, routes[2])
+}
+
+func TestHandlerName(t *testing.T) {
+	assert.Empty(t, handlerName(nil))
+	assert.Equal(t, "github.com/gowool/keratin.routesTestHandler", handlerName(HandlerFunc(routesTestHandler)))
+	assert.Equal(t, "keratin.routesTestHandlerType", handlerName(routesTestHandlerType{}))
+	assert.Equal(t, "keratin.HandlerFunc", handlerName(HandlerFunc(nil)))
+}
+
+func TestDebugRoutesHandler(t *testing.T) {
+	assert.Panics(t, func() { DebugRoutesHandler(nil) })
+
+	router := newRoutesTestRouter()
+	router.GET("/debug/routes", DebugRoutesHandler(router).ServeHTTP)
+	handler := router.Build()
+
+	t.Run("json", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/routes", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, strings.HasPrefix(w.Header().Get(HeaderContentType), MIMEApplicationJSON))
+
+		var routes []RouteInfo
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &routes))
+		assert.Equal(t, router.Routes(), routes)
+	})
+
+	t.Run("html", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/debug/routes", nil)
+		req.Header.Set(HeaderAccept, "text/html,application/xhtml+xml,*/*;q=0.8")
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, strings.HasPrefix(w.Header().Get(HeaderContentType), MIMETextHTML))
+		assert.Contains(t, w.Body.String(), "<td>/api/users/{id}</td>")
+		assert.Contains(t, w.Body.String(), "<td>*</td><td>/health</td>")
+	})
+}
+
+func TestRouter_LogRoutes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	newRoutesTestRouter().LogRoutes(logger)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, "route", record["msg"])
+	assert.Equal(t, "GET", record["method"])
+	assert.Equal(t, "GET /api/users/{id}", record["pattern"])
+	assert.Equal(t, "github.com/gowool/keratin.routesTestHandler", record["handler"])
+	assert.Equal(t, []any{"recover", "logger", "auth", "github.com/gowool/keratin.routesTestMiddleware"}, record["middlewares"])
+	assert.Equal(t, false, record["disabled"])
+
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &record))
+	assert.Equal(t, "*", record["method"])
+
+	assert.NotPanics(t, func() { NewRouter().LogRoutes(nil) })
+}
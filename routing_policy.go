@@ -0,0 +1,150 @@
+package keratin
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// redirectHandlerType is the type of the handlers of the [http.ServeMux] redirects.
+var redirectHandlerType = reflect.TypeOf(http.RedirectHandler("/", http.StatusMovedPermanently))
+
+// DuplicatePatternPolicy decides what happens when several routes are registered with the same pattern.
+type DuplicatePatternPolicy uint8
+
+const (
+	// DuplicatePatternError reports the duplicate patterns as errors of [Router.Validate],
+	// so [Router.Build] fails fast. It is the default.
+	DuplicatePatternError DuplicatePatternPolicy = iota
+
+	// DuplicatePatternReplaceLast serves the last route registered with a pattern,
+	// the previous ones are ignored.
+	DuplicatePatternReplaceLast
+)
+
+// TrailingSlashPolicy decides how the request paths differing from a route path
+// only by the trailing slash are handled.
+type TrailingSlashPolicy uint8
+
+const (
+	// TrailingSlashMux keeps the [http.ServeMux] behavior: "/users/" matches the whole subtree
+	// and "/users" is redirected to it, while "/users" only matches "/users" itself. It is the default.
+	TrailingSlashMux TrailingSlashPolicy = iota
+
+	// TrailingSlashStrict matches the paths as registered: "/users" and "/users/" are
+	// different paths and none of them is redirected to the other one.
+	TrailingSlashStrict
+
+	// TrailingSlashRedirect redirects the request to the path with or without the trailing slash
+	// which matches a route. GET and HEAD requests are redirected with 301 Moved Permanently,
+	// other methods with 308 Permanent Redirect.
+	TrailingSlashRedirect
+
+	// TrailingSlashIgnore serves the request with the route matching the path with or without
+	// the trailing slash, without a redirect.
+	TrailingSlashIgnore
+)
+
+// Policy configures how the router resolves the ambiguous routes, see [WithRoutingPolicy].
+type Policy struct {
+	DuplicatePattern DuplicatePatternPolicy
+	TrailingSlash    TrailingSlashPolicy
+}
+
+// WithRoutingPolicy sets the routing policy, by default the duplicate patterns are errors
+// and the trailing slashes follow the [http.ServeMux] semantics.
+func WithRoutingPolicy(policy Policy) Option {
+	return func(router *Router) {
+		router.policy = policy
+	}
+}
+
+// replacedRoutes returns the routes ignored in favor of a later route with the same pattern,
+// nil unless the DuplicatePatternReplaceLast policy is set.
+func (r *Router) replacedRoutes() map[*Route]struct{} {
+	if r.policy.DuplicatePattern != DuplicatePatternReplaceLast {
+		return nil
+	}
+
+	last := make(map[string]*Route)
+	r.walkGroups(r.RouterGroup, "", nil, func(pattern string, route *Route, _ []*RouterGroup) {
+		last[pattern] = route
+	})
+
+	replaced := make(map[*Route]struct{})
+	r.walkGroups(r.RouterGroup, "", nil, func(pattern string, route *Route, _ []*RouterGroup) {
+		if last[pattern] != route {
+			replaced[route] = struct{}{}
+		}
+	})
+	return replaced
+}
+
+// resolveTrailingSlash applies the trailing slash policy to the request. It returns the request
+// to serve, possibly with the alternative path, or nil if the response was written or the request
+// must be answered with 404 Not Found (notFound is true).
+func (r *Router) resolveTrailingSlash(mux *http.ServeMux, w http.ResponseWriter, req *http.Request) (_ *http.Request, notFound bool) {
+	path := req.URL.Path
+	if r.policy.TrailingSlash == TrailingSlashMux || path == "/" || path == "" {
+		return req, false
+	}
+
+	h, pattern := mux.Handler(req)
+
+	redirect := isSlashRedirect(h, path)
+	if pattern != "" && !redirect {
+		return req, false
+	}
+
+	if r.policy.TrailingSlash == TrailingSlashStrict {
+		if redirect {
+			return nil, true
+		}
+		return req, false
+	}
+
+	alt := path + "/"
+	if strings.HasSuffix(path, "/") {
+		alt = strings.TrimSuffix(path, "/")
+	}
+
+	u := *req.URL
+	u.Path = alt
+	u.RawPath = ""
+
+	altReq := new(http.Request)
+	*altReq = *req
+	altReq.URL = &u
+	altReq.RequestURI = u.RequestURI()
+
+	if !redirect {
+		if _, altPattern := mux.Handler(altReq); altPattern == "" || altPattern == alt+"/" {
+			return req, false
+		}
+	}
+
+	if r.policy.TrailingSlash == TrailingSlashIgnore {
+		return altReq, false
+	}
+
+	code := http.StatusPermanentRedirect
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		code = http.StatusMovedPermanently
+	}
+	http.Redirect(w, req, u.RequestURI(), code)
+	return nil, false
+}
+
+// isSlashRedirect reports whether h is the mux redirect of path to path with the trailing slash.
+func isSlashRedirect(h http.Handler, path string) bool {
+	if strings.HasSuffix(path, "/") || reflect.TypeOf(h) != redirectHandlerType {
+		return false
+	}
+
+	probe := &muxProbe{header: make(http.Header)}
+	h.ServeHTTP(probe, &http.Request{Method: http.MethodGet, URL: &url.URL{Path: path}})
+
+	location, err := url.Parse(probe.header.Get(HeaderLocation))
+	return err == nil && location.Path == path+"/"
+}
@@ -0,0 +1,155 @@
+package keratin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRoutingPolicy_TrailingSlash(t *testing.T) {
+	type result struct {
+		code     int
+		location string
+		body     string
+	}
+
+	tests := []struct {
+		name   string
+		policy TrailingSlashPolicy
+		want   map[string]result
+	}{
+		{
+			name:   "mux",
+			policy: TrailingSlashMux,
+			want: map[string]result{
+				"GET /users":      {code: http.StatusOK, body: "users"},
+				"GET /users/":     {code: http.StatusNotFound},
+				"GET /files/a":    {code: http.StatusOK, body: "files"},
+				"POST /users/":    {code: http.StatusNotFound},
+				"GET /missing":    {code: http.StatusNotFound},
+				"GET /missing/":   {code: http.StatusNotFound},
+				"GET /":           {code: http.StatusNotFound},
+				"DELETE /users/1": {code: http.StatusNotFound},
+			},
+		},
+		{
+			name:   "strict",
+			policy: TrailingSlashStrict,
+			want: map[string]result{
+				"GET /users":    {code: http.StatusOK, body: "users"},
+				"GET /users/":   {code: http.StatusNotFound},
+				"GET /files":    {code: http.StatusNotFound},
+				"GET /files/a":  {code: http.StatusOK, body: "files"},
+				"GET /missing/": {code: http.StatusNotFound},
+			},
+		},
+		{
+			name:   "redirect",
+			policy: TrailingSlashRedirect,
+			want: map[string]result{
+				"GET /users":     {code: http.StatusOK, body: "users"},
+				"GET /users/?q=": {code: http.StatusMovedPermanently, location: "/users?q="},
+				"POST /users/":   {code: http.StatusPermanentRedirect, location: "/users"},
+				"GET /files":     {code: http.StatusMovedPermanently, location: "/files/"},
+				"POST /files":    {code: http.StatusMethodNotAllowed},
+				"GET /missing/":  {code: http.StatusNotFound},
+			},
+		},
+		{
+			name:   "ignore",
+			policy: TrailingSlashIgnore,
+			want: map[string]result{
+				"GET /users":    {code: http.StatusOK, body: "users"},
+				"GET /users/":   {code: http.StatusOK, body: "users"},
+				"POST /users/":  {code: http.StatusCreated, body: "created"},
+				"GET /files":    {code: http.StatusOK, body: "files"},
+				"GET /missing/": {code: http.StatusNotFound},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := NewRouter(WithRoutingPolicy(Policy{TrailingSlash: tt.policy}))
+			router.GET("/users", func(w http.ResponseWriter, r *http.Request) error {
+				return TextPlain(w, http.StatusOK, "users")
+			})
+			router.POST("/users", func(w http.ResponseWriter, r *http.Request) error {
+				return TextPlain(w, http.StatusCreated, "created")
+			})
+			router.GET("/files/", func(w http.ResponseWriter, r *http.Request) error {
+				return TextPlain(w, http.StatusOK, "files")
+			})
+			handler := router.Build()
+
+			if tt.policy == TrailingSlashMux {
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/files", nil))
+				assert.True(t, w.Code >= 300 && w.Code < 400, w.Code)
+				assert.Equal(t, "/files/", w.Header().Get(HeaderLocation))
+			}
+
+			for request, want := range tt.want {
+				method, target, _ := strings.Cut(request, " ")
+
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+
+				assert.Equal(t, want.code, w.Code, request)
+				assert.Equal(t, want.location, w.Header().Get(HeaderLocation), request)
+				if want.body != "" {
+					assert.Equal(t, want.body, w.Body.String(), request)
+				}
+			}
+		})
+	}
+}
+
+func TestWithRoutingPolicy_TrailingSlash_NotFoundHandler(t *testing.T) {
+	router := NewRouter(
+		WithRoutingPolicy(Policy{TrailingSlash: TrailingSlashStrict}),
+		WithNotFoundHandler(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return TextPlain(w, http.StatusNotFound, "custom")
+		})),
+	)
+	router.GET("/files/", func(w http.ResponseWriter, r *http.Request) error {
+		return TextPlain(w, http.StatusOK, "files")
+	})
+
+	w := httptest.NewRecorder()
+	router.Build().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/files", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "custom", w.Body.String())
+}
+
+func TestWithRoutingPolicy_DuplicatePattern(t *testing.T) {
+	register := func(router *Router) {
+		router.GET("/users", func(w http.ResponseWriter, r *http.Request) error {
+			return TextPlain(w, http.StatusOK, "first")
+		})
+		router.Group("/").GET("users", func(w http.ResponseWriter, r *http.Request) error {
+			return TextPlain(w, http.StatusOK, "second")
+		})
+	}
+
+	router := NewRouter()
+	register(router)
+	assert.ErrorContains(t, router.Validate(), `route "GET /users"`)
+
+	router = NewRouter(WithRoutingPolicy(Policy{DuplicatePattern: DuplicatePatternReplaceLast}))
+	register(router)
+	require.NoError(t, router.Validate())
+
+	w := httptest.NewRecorder()
+	router.Build().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, "second", w.Body.String())
+
+	routes := router.Routes()
+	require.Len(t, routes, 1)
+	assert.Equal(t, "GET /users", routes[0].Pattern)
+}
@@ -45,6 +45,7 @@ func (c *Config) SetDefaults() {
 	}
 
 	c.HTTP2.SetDefaults()
+	c.Transport.SetDefaults()
 }
 
 func (c *Config) Validate() error {
@@ -92,6 +93,9 @@ func (c *HTTP2Config) SetDefaults() {
 	}
 }
 
+// HTTP3Config enables serving the handler over HTTP/3 (QUIC) on the UDP port of the address,
+// alongside the TLS listener, which advertises the HTTP/3 endpoint with the Alt-Svc header.
+// It requires a TLS configuration.
 type HTTP3Config struct {
 	// AdvertisedPort defines which UDP port to advertise as the HTTP/3 authority.
 	// It defaults to the entryPoint's address port. It can be used to override
@@ -113,9 +117,9 @@ type TransportConfig struct {
 	// ReadHeaderTimeout is the amount of time allowed to read
 	// request headers. The connection's read deadline is reset
 	// after reading the headers and the Handler can decide what
-	// is considered too slow for the body. If zero, the value of
-	// ReadTimeout is used. If negative, or if zero and ReadTimeout
-	// is zero or negative, there is no timeout.
+	// is considered too slow for the body. If negative, there is no timeout.
+	// It defaults to 10s, so the slow clients cannot hold the connections
+	// by sending the headers byte by byte (Slowloris).
 	ReadHeaderTimeout time.Duration `env:"READ_HEADER_TIMEOUT" json:"readHeaderTimeout,omitempty,format:units" yaml:"readHeaderTimeout,omitempty"`
 
 	// WriteTimeout is the maximum duration before timing out
@@ -135,10 +139,19 @@ type TransportConfig struct {
 	// server will read parsing the request header's keys and
 	// values, including the request line. It does not limit the
 	// size of the request body.
-	// If zero, http.DefaultMaxHeaderBytes is used.
+	// It defaults to 64KB, far below http.DefaultMaxHeaderBytes (1MB).
 	MaxHeaderBytes int `env:"MAX_HEADER_BYTES" json:"maxHeaderBytes,omitempty" yaml:"maxHeaderBytes,omitempty"`
 }
 
+func (c *TransportConfig) SetDefaults() {
+	if c.ReadHeaderTimeout == 0 {
+		c.ReadHeaderTimeout = 10 * time.Second
+	}
+	if c.MaxHeaderBytes <= 0 {
+		c.MaxHeaderBytes = 64 << 10
+	}
+}
+
 type TLSConfig struct {
 	InsecureSkipVerify bool                `env:"INSECURE_SKIP_VERIFY" json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
 	Certificates       []CertificateConfig `envPrefix:"CERTIFICATE_" json:"certificates,omitempty" yaml:"certificates,omitempty"`
@@ -444,6 +444,20 @@ func TestTransportConfig(t *testing.T) {
 	assert.Equal(t, 8192, config.MaxHeaderBytes, "MaxHeaderBytes should be set")
 }
 
+func TestTransportConfig_SetDefaults(t *testing.T) {
+	var config TransportConfig
+	config.SetDefaults()
+
+	assert.Equal(t, 10*time.Second, config.ReadHeaderTimeout)
+	assert.Equal(t, 64<<10, config.MaxHeaderBytes)
+
+	config = TransportConfig{ReadHeaderTimeout: -1, MaxHeaderBytes: 1 << 20}
+	config.SetDefaults()
+
+	assert.Equal(t, time.Duration(-1), config.ReadHeaderTimeout)
+	assert.Equal(t, 1<<20, config.MaxHeaderBytes)
+}
+
 // TestHTTP3Config tests that HTTP3Config has the expected fields
 func TestHTTP3Config(t *testing.T) {
 	config := HTTP3Config{
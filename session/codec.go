@@ -3,12 +3,15 @@ package session
 import (
 	"bytes"
 	"encoding/gob"
+	"errors"
 	"time"
 )
 
+// Codec encodes and decodes the session data: its deadline, whether its expiry
+// is fixed (see Session.SetExpiry) and its values.
 type Codec interface {
-	Encode(deadline time.Time, values map[string]any) ([]byte, error)
-	Decode([]byte) (deadline time.Time, values map[string]any, err error)
+	Encode(deadline time.Time, fixedExpiry bool, values map[string]any) ([]byte, error)
+	Decode([]byte) (deadline time.Time, fixedExpiry bool, values map[string]any, err error)
 }
 
 type GobCodec struct{}
@@ -17,13 +20,15 @@ func NewGobCodec() GobCodec {
 	return GobCodec{}
 }
 
-func (GobCodec) Encode(deadline time.Time, values map[string]any) ([]byte, error) {
+func (GobCodec) Encode(deadline time.Time, fixedExpiry bool, values map[string]any) ([]byte, error) {
 	aux := &struct {
-		Deadline time.Time
-		Values   map[string]any
+		Deadline    time.Time
+		FixedExpiry bool
+		Values      map[string]any
 	}{
-		Deadline: deadline,
-		Values:   values,
+		Deadline:    deadline,
+		FixedExpiry: fixedExpiry,
+		Values:      values,
 	}
 
 	var b bytes.Buffer
@@ -33,15 +38,49 @@ func (GobCodec) Encode(deadline time.Time, values map[string]any) ([]byte, error
 	return b.Bytes(), nil
 }
 
-func (GobCodec) Decode(b []byte) (time.Time, map[string]any, error) {
+func (GobCodec) Decode(b []byte) (time.Time, bool, map[string]any, error) {
 	aux := &struct {
-		Deadline time.Time
-		Values   map[string]any
+		Deadline    time.Time
+		FixedExpiry bool
+		Values      map[string]any
 	}{}
 
 	r := bytes.NewReader(b)
 	if err := gob.NewDecoder(r).Decode(&aux); err != nil {
-		return time.Time{}, nil, err
+		return time.Time{}, false, nil, err
 	}
-	return aux.Deadline, aux.Values, nil
+	return aux.Deadline, aux.FixedExpiry, aux.Values, nil
+}
+
+type fallbackCodec struct {
+	codecs []Codec
+}
+
+// FallbackCodec returns a [Codec] encoding with current and decoding with the first of current
+// and previous which succeeds, so the sessions of a previous format are still read while
+// being migrated to the current one on their next commit, e.g.
+//
+//	FallbackCodec(NewJSONCodec(), NewGobCodec())
+func FallbackCodec(current Codec, previous ...Codec) Codec {
+	if current == nil {
+		panic("session: fallback codec: current codec is required")
+	}
+
+	return &fallbackCodec{codecs: append([]Codec{current}, previous...)}
+}
+
+func (c *fallbackCodec) Encode(deadline time.Time, fixedExpiry bool, values map[string]any) ([]byte, error) {
+	return c.codecs[0].Encode(deadline, fixedExpiry, values)
+}
+
+func (c *fallbackCodec) Decode(b []byte) (time.Time, bool, map[string]any, error) {
+	var errs []error
+	for _, codec := range c.codecs {
+		deadline, fixedExpiry, values, err := codec.Decode(b)
+		if err == nil {
+			return deadline, fixedExpiry, values, nil
+		}
+		errs = append(errs, err)
+	}
+	return time.Time{}, false, nil, errors.Join(errs...)
 }
@@ -0,0 +1,76 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"time"
+)
+
+// ErrDecrypt is returned by the [EncryptedCodec] when the data is not decrypted by any of its keys.
+var ErrDecrypt = errors.New("session: cannot decrypt the session data")
+
+type encryptedCodec struct {
+	inner Codec
+	aeads []cipher.AEAD
+}
+
+// EncryptedCodec returns a [Codec] encrypting the data encoded by inner with AES-256-GCM,
+// so the session data is not stored in plain text.
+//
+// The first key is the current one and encrypts the data, all the keys decrypt it,
+// so a key is rotated by prepending the new key and removing the old one once
+// the sessions encrypted with it have expired.
+//
+// It panics if no key is given.
+func EncryptedCodec(inner Codec, keys ...[32]byte) Codec {
+	if inner == nil {
+		panic("session: encrypted codec: inner codec is required")
+	}
+	if len(keys) == 0 {
+		panic("session: encrypted codec: at least one key is required")
+	}
+
+	aeads := make([]cipher.AEAD, len(keys))
+	for i, key := range keys {
+		block, err := aes.NewCipher(key[:])
+		if err != nil {
+			panic("session: encrypted codec: " + err.Error())
+		}
+		if aeads[i], err = cipher.NewGCM(block); err != nil {
+			panic("session: encrypted codec: " + err.Error())
+		}
+	}
+
+	return &encryptedCodec{inner: inner, aeads: aeads}
+}
+
+func (c *encryptedCodec) Encode(deadline time.Time, fixedExpiry bool, values map[string]any) ([]byte, error) {
+	b, err := c.inner.Encode(deadline, fixedExpiry, values)
+	if err != nil {
+		return nil, err
+	}
+
+	aead := c.aeads[0]
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(b)+aead.Overhead())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, b, nil), nil
+}
+
+func (c *encryptedCodec) Decode(b []byte) (time.Time, bool, map[string]any, error) {
+	for _, aead := range c.aeads {
+		if len(b) < aead.NonceSize() {
+			break
+		}
+
+		nonce, ciphertext := b[:aead.NonceSize()], b[aead.NonceSize():]
+		if plaintext, err := aead.Open(nil, nonce, ciphertext, nil); err == nil {
+			return c.inner.Decode(plaintext)
+		}
+	}
+	return time.Time{}, false, nil, ErrDecrypt
+}
@@ -0,0 +1,67 @@
+package session
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedCodec(t *testing.T) {
+	oldKey := [32]byte{1}
+	newKey := [32]byte{2}
+
+	deadline := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	values := map[string]any{"user_id": "42"}
+
+	oldCodec := EncryptedCodec(NewGobCodec(), oldKey)
+	rotated := EncryptedCodec(NewGobCodec(), newKey, oldKey)
+	other := EncryptedCodec(NewGobCodec(), [32]byte{3})
+
+	encoded, err := oldCodec.Encode(deadline, false, values)
+	require.NoError(t, err)
+
+	plain, err := NewGobCodec().Encode(deadline, false, values)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(encoded, []byte("user_id")))
+	assert.NotEqual(t, plain, encoded)
+
+	// the data encrypted with the old key is still decrypted after the rotation
+	gotDeadline, _, gotValues, err := rotated.Decode(encoded)
+	require.NoError(t, err)
+	assert.True(t, deadline.Equal(gotDeadline))
+	assert.Equal(t, values, gotValues)
+
+	// the new data is encrypted with the current key
+	encoded, err = rotated.Encode(deadline, false, values)
+	require.NoError(t, err)
+
+	_, _, _, err = oldCodec.Decode(encoded)
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	_, _, gotValues, err = EncryptedCodec(NewGobCodec(), newKey).Decode(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, values, gotValues)
+
+	_, _, _, err = other.Decode(encoded)
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	_, _, _, err = rotated.Decode([]byte("short"))
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	// tampered data
+	encoded[len(encoded)-1] ^= 0xff
+	_, _, _, err = rotated.Decode(encoded)
+	assert.ErrorIs(t, err, ErrDecrypt)
+}
+
+func TestEncryptedCodec_Panics(t *testing.T) {
+	assert.PanicsWithValue(t, "session: encrypted codec: inner codec is required", func() {
+		EncryptedCodec(nil, [32]byte{})
+	})
+	assert.PanicsWithValue(t, "session: encrypted codec: at least one key is required", func() {
+		EncryptedCodec(NewGobCodec())
+	})
+}
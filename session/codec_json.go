@@ -0,0 +1,39 @@
+package session
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JSONCodec encodes the session data as a JSON object {"deadline": ..., "fixed_expiry": true, "values": {...}},
+// readable by the other languages and the operators.
+//
+// The values are decoded as the JSON types: the numbers as float64, the objects
+// as map[string]any and the arrays as []any, so the typed getters (e.g. GetInt)
+// only work for the strings and the booleans.
+type JSONCodec struct{}
+
+func NewJSONCodec() JSONCodec {
+	return JSONCodec{}
+}
+
+type jsonSession struct {
+	Deadline    time.Time      `json:"deadline"`
+	FixedExpiry bool           `json:"fixed_expiry,omitempty"`
+	Values      map[string]any `json:"values"`
+}
+
+func (JSONCodec) Encode(deadline time.Time, fixedExpiry bool, values map[string]any) ([]byte, error) {
+	return json.Marshal(jsonSession{Deadline: deadline, FixedExpiry: fixedExpiry, Values: values})
+}
+
+func (JSONCodec) Decode(b []byte) (time.Time, bool, map[string]any, error) {
+	var aux jsonSession
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return time.Time{}, false, nil, err
+	}
+	if aux.Values == nil {
+		aux.Values = make(map[string]any)
+	}
+	return aux.Deadline, aux.FixedExpiry, aux.Values, nil
+}
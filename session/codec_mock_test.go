@@ -11,12 +11,12 @@ type MockCodec struct {
 	mock.Mock
 }
 
-func (m *MockCodec) Encode(deadline time.Time, values map[string]any) ([]byte, error) {
-	args := m.Called(deadline, values)
+func (m *MockCodec) Encode(deadline time.Time, fixedExpiry bool, values map[string]any) ([]byte, error) {
+	args := m.Called(deadline, fixedExpiry, values)
 	return args.Get(0).([]byte), args.Error(1)
 }
 
-func (m *MockCodec) Decode(b []byte) (time.Time, map[string]any, error) {
+func (m *MockCodec) Decode(b []byte) (time.Time, bool, map[string]any, error) {
 	args := m.Called(b)
-	return args.Get(0).(time.Time), args.Get(1).(map[string]any), args.Error(2)
+	return args.Get(0).(time.Time), args.Bool(1), args.Get(2).(map[string]any), args.Error(3)
 }
@@ -0,0 +1,67 @@
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// MsgpackCodec encodes the session data as a MessagePack map {"deadline": timestamp, "fixed_expiry": bool, "values": {...}},
+// more compact than JSON and readable by the other languages.
+//
+// The values are decoded as the MessagePack types: the signed integers as int64,
+// the unsigned ones as uint64, the maps as map[string]any and the arrays as []any.
+type MsgpackCodec struct{}
+
+func NewMsgpackCodec() MsgpackCodec {
+	return MsgpackCodec{}
+}
+
+func (MsgpackCodec) Encode(deadline time.Time, fixedExpiry bool, values map[string]any) ([]byte, error) {
+	b := msgp.AppendMapHeader(nil, 3)
+	b = msgp.AppendString(b, "deadline")
+	b = msgp.AppendTimeExt(b, deadline)
+	b = msgp.AppendString(b, "fixed_expiry")
+	b = msgp.AppendBool(b, fixedExpiry)
+	b = msgp.AppendString(b, "values")
+	return msgp.AppendMapStrIntf(b, values)
+}
+
+func (MsgpackCodec) Decode(b []byte) (time.Time, bool, map[string]any, error) {
+	sz, b, err := msgp.ReadMapHeaderBytes(b)
+	if err != nil {
+		return time.Time{}, false, nil, err
+	}
+
+	var (
+		deadline    time.Time
+		fixedExpiry bool
+		values      map[string]any
+		key         string
+	)
+	for range sz {
+		if key, b, err = msgp.ReadStringBytes(b); err != nil {
+			return time.Time{}, false, nil, err
+		}
+
+		switch key {
+		case "deadline":
+			deadline, b, err = msgp.ReadTimeBytes(b)
+		case "fixed_expiry":
+			fixedExpiry, b, err = msgp.ReadBoolBytes(b)
+		case "values":
+			values, b, err = msgp.ReadMapStrIntfBytes(b, nil)
+		default:
+			err = fmt.Errorf("session: msgpack codec: unknown field %q", key)
+		}
+		if err != nil {
+			return time.Time{}, false, nil, err
+		}
+	}
+
+	if values == nil {
+		values = make(map[string]any)
+	}
+	return deadline.UTC(), fixedExpiry, values, nil
+}
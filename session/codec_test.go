@@ -65,7 +65,7 @@ func TestGobCodec_Encode(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := codec.Encode(tt.deadline, tt.values)
+			result, err := codec.Encode(tt.deadline, false, tt.values)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -89,7 +89,7 @@ func TestGobCodec_Decode(t *testing.T) {
 		"role":    "admin",
 		"active":  true,
 	}
-	encoded, err := codec.Encode(deadline, values)
+	encoded, err := codec.Encode(deadline, false, values)
 	require.NoError(t, err)
 
 	tests := []struct {
@@ -125,7 +125,7 @@ func TestGobCodec_Decode(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			decodedDeadline, decodedValues, err := codec.Decode(tt.data)
+			decodedDeadline, _, decodedValues, err := codec.Decode(tt.data)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -185,12 +185,12 @@ func TestGobCodec_EncodeDecodeRoundTrip(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// Encode
-			encoded, err := codec.Encode(tc.deadline, tc.values)
+			encoded, err := codec.Encode(tc.deadline, false, tc.values)
 			require.NoError(t, err)
 			assert.NotEmpty(t, encoded)
 
 			// Decode
-			decodedDeadline, decodedValues, err := codec.Decode(encoded)
+			decodedDeadline, _, decodedValues, err := codec.Decode(encoded)
 			require.NoError(t, err)
 
 			// Verify
@@ -214,7 +214,7 @@ func TestGobCodec_EncodeDecodeConsistency(t *testing.T) {
 	// Encode the same data multiple times
 	var encodings [][]byte
 	for range 5 {
-		encoded, err := codec.Encode(deadline, values)
+		encoded, err := codec.Encode(deadline, false, values)
 		require.NoError(t, err)
 		encodings = append(encodings, encoded)
 	}
@@ -222,7 +222,7 @@ func TestGobCodec_EncodeDecodeConsistency(t *testing.T) {
 	// All encodings should decode to the same data
 	for i, encoded := range encodings {
 		t.Run(fmt.Sprintf("decoding_iteration_%d", i), func(t *testing.T) {
-			decodedDeadline, decodedValues, err := codec.Decode(encoded)
+			decodedDeadline, _, decodedValues, err := codec.Decode(encoded)
 			require.NoError(t, err)
 			assert.True(t, deadline.Equal(decodedDeadline))
 			assert.Equal(t, values, decodedValues)
@@ -243,7 +243,7 @@ func TestGobCodec_EdgeCases(t *testing.T) {
 			"custom": CustomType{},
 		}
 
-		_, err := codec.Encode(time.Now(), values)
+		_, err := codec.Encode(time.Now(), false, values)
 		assert.Error(t, err)
 	})
 
@@ -258,10 +258,10 @@ func TestGobCodec_EdgeCases(t *testing.T) {
 			"large_data": largeSlice,
 		}
 
-		encoded, err := codec.Encode(time.Now().Add(time.Hour), values)
+		encoded, err := codec.Encode(time.Now().Add(time.Hour), false, values)
 		require.NoError(t, err)
 
-		decodedDeadline, decodedValues, err := codec.Decode(encoded)
+		decodedDeadline, _, decodedValues, err := codec.Decode(encoded)
 		require.NoError(t, err)
 		assert.NotZero(t, decodedDeadline)
 		assert.Equal(t, len(largeSlice), len(decodedValues["large_data"].([]string)))
@@ -276,10 +276,10 @@ func TestGobCodec_EdgeCases(t *testing.T) {
 			"quotes":   `'single' and "double" quotes`,
 		}
 
-		encoded, err := codec.Encode(time.Now().Add(time.Hour), values)
+		encoded, err := codec.Encode(time.Now().Add(time.Hour), false, values)
 		require.NoError(t, err)
 
-		_, decodedValues, err := codec.Decode(encoded)
+		_, _, decodedValues, err := codec.Decode(encoded)
 		require.NoError(t, err)
 		assert.Equal(t, values, decodedValues)
 	})
@@ -291,7 +291,7 @@ func TestGobCodec_PartialCorruption(t *testing.T) {
 	// Create valid encoded data
 	deadline := time.Now().Add(time.Hour)
 	values := map[string]any{"test": "value"}
-	encoded, err := codec.Encode(deadline, values)
+	encoded, err := codec.Encode(deadline, false, values)
 	require.NoError(t, err)
 	require.Greater(t, len(encoded), 10) // Ensure we have enough data to corrupt
 
@@ -337,7 +337,7 @@ func TestGobCodec_PartialCorruption(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			corrupted := tt.mutateFn(encoded)
 
-			_, decodedValues, err := codec.Decode(corrupted)
+			_, _, decodedValues, err := codec.Decode(corrupted)
 
 			assert.Error(t, err)
 			assert.Nil(t, decodedValues)
@@ -363,7 +363,7 @@ func TestGobCodec_ConcurrentAccess(t *testing.T) {
 					"iteration": j,
 					"data":      "test data",
 				}
-				_, err := codec.Encode(time.Now().Add(time.Hour), values)
+				_, err := codec.Encode(time.Now().Add(time.Hour), false, values)
 				if err != nil {
 					errChan <- err
 					return
@@ -376,13 +376,13 @@ func TestGobCodec_ConcurrentAccess(t *testing.T) {
 	// Concurrent decoding (using pre-encoded data)
 	deadline := time.Now().Add(time.Hour)
 	values := map[string]any{"concurrent": "test"}
-	encoded, err := codec.Encode(deadline, values)
+	encoded, err := codec.Encode(deadline, false, values)
 	require.NoError(t, err)
 
 	for range numGoroutines {
 		go func() {
 			for range numIterations {
-				_, _, err := codec.Decode(encoded)
+				_, _, _, err := codec.Decode(encoded)
 				if err != nil {
 					errChan <- err
 					return
@@ -399,3 +399,83 @@ func TestGobCodec_ConcurrentAccess(t *testing.T) {
 		}
 	}
 }
+
+func TestJSONCodec(t *testing.T) {
+	codec := NewJSONCodec()
+	deadline := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	b, err := codec.Encode(deadline, false, map[string]any{"user_id": "42", "admin": true, "count": 3})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"deadline":"2026-10-16T12:00:00Z","values":{"user_id":"42","admin":true,"count":3}}`, string(b))
+
+	gotDeadline, _, gotValues, err := codec.Decode(b)
+	require.NoError(t, err)
+	assert.True(t, deadline.Equal(gotDeadline))
+	assert.Equal(t, map[string]any{"user_id": "42", "admin": true, "count": float64(3)}, gotValues)
+
+	_, _, gotValues, err = codec.Decode([]byte(`{"deadline":"2026-10-16T12:00:00Z"}`))
+	require.NoError(t, err)
+	assert.NotNil(t, gotValues)
+
+	_, _, _, err = codec.Decode([]byte("invalid"))
+	assert.Error(t, err)
+}
+
+func TestMsgpackCodec(t *testing.T) {
+	codec := NewMsgpackCodec()
+	deadline := time.Date(2026, 10, 16, 12, 0, 0, 500, time.UTC)
+
+	b, err := codec.Encode(deadline, false, map[string]any{"user_id": "42", "admin": true, "count": 3, "tags": []string{"a"}})
+	require.NoError(t, err)
+
+	gotDeadline, _, gotValues, err := codec.Decode(b)
+	require.NoError(t, err)
+	assert.Equal(t, deadline, gotDeadline)
+	assert.Equal(t, map[string]any{"user_id": "42", "admin": true, "count": int64(3), "tags": []any{"a"}}, gotValues)
+
+	b, err = codec.Encode(deadline, false, nil)
+	require.NoError(t, err)
+	_, _, gotValues, err = codec.Decode(b)
+	require.NoError(t, err)
+	assert.Empty(t, gotValues)
+
+	_, _, _, err = codec.Decode([]byte{0x81, 0xa3, 'f', 'o', 'o', 0xc0})
+	assert.EqualError(t, err, `session: msgpack codec: unknown field "foo"`)
+
+	_, _, _, err = codec.Decode([]byte("invalid"))
+	assert.Error(t, err)
+
+	_, err = codec.Encode(deadline, false, map[string]any{"chan": make(chan int)})
+	assert.Error(t, err)
+}
+
+func TestFallbackCodec(t *testing.T) {
+	deadline := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	values := map[string]any{"user_id": "42"}
+
+	codec := FallbackCodec(NewMsgpackCodec(), NewJSONCodec(), NewGobCodec())
+
+	for _, previous := range []Codec{NewGobCodec(), NewJSONCodec(), NewMsgpackCodec()} {
+		b, err := previous.Encode(deadline, true, values)
+		require.NoError(t, err)
+
+		gotDeadline, gotFixedExpiry, gotValues, err := codec.Decode(b)
+		require.NoError(t, err)
+		assert.True(t, deadline.Equal(gotDeadline))
+		assert.True(t, gotFixedExpiry)
+		assert.Equal(t, values, gotValues)
+	}
+
+	b, err := codec.Encode(deadline, false, values)
+	require.NoError(t, err)
+	_, _, gotValues, err := NewMsgpackCodec().Decode(b)
+	require.NoError(t, err)
+	assert.Equal(t, values, gotValues)
+
+	_, _, _, err = codec.Decode([]byte("invalid"))
+	assert.Error(t, err)
+
+	assert.PanicsWithValue(t, "session: fallback codec: current codec is required", func() {
+		FallbackCodec(nil)
+	})
+}
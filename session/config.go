@@ -3,6 +3,8 @@ package session
 import (
 	"net/http"
 	"time"
+
+	"github.com/gowool/keratin"
 )
 
 type SameSite string
@@ -36,6 +38,18 @@ type Cookie struct {
 	SameSite    SameSite `env:"SAME_SITE" json:"sameSite,omitempty" yaml:"sameSite,omitempty"`
 }
 
+// Validate reports the violated "__Host-"/"__Secure-" name prefix and Partitioned (CHIPS) requirements,
+// see [keratin.CookieOpts.Validate].
+func (c *Cookie) Validate() error {
+	return keratin.CookieOpts{
+		Name:        c.Name,
+		Path:        c.Path,
+		Domain:      c.Domain,
+		Secure:      c.Secure,
+		Partitioned: c.Partitioned,
+	}.Validate()
+}
+
 func (c *Cookie) SetDefaults() {
 	if c.Name == "" {
 		c.Name = "session"
@@ -61,9 +75,24 @@ type Config struct {
 	// hours.
 	Lifetime time.Duration `env:"LIFETIME" json:"lifetime,omitempty,format:units" yaml:"lifetime,omitempty"`
 
+	// DisableSliding turns off the renewal of the IdleTimeout on every request, the idle expiry
+	// is then only extended when the session is modified or touched (see Session.Touch).
+	DisableSliding bool `env:"DISABLE_SLIDING" json:"disableSliding,omitempty" yaml:"disableSliding,omitempty"`
+
+	// LockTimeout enables the serialization of the concurrent requests sharing a session token,
+	// e.g. the parallel AJAX requests of a browser: a request waits up to LockTimeout for the previous
+	// ones to finish before loading the session, so they do not overwrite the changes of each other.
+	// The locks are local to the process. By default the requests are not serialized.
+	LockTimeout time.Duration `env:"LOCK_TIMEOUT" json:"lockTimeout,omitempty,format:units" yaml:"lockTimeout,omitempty"`
+
 	// HashTokenInStore controls to store the session token or a hashed version in the store.
 	HashTokenInStore bool `env:"HASH_TOKEN_IN_STORE" json:"hashTokenInStore,omitempty" yaml:"hashTokenInStore,omitempty"`
 
+	// PerTenant isolates the sessions of the tenants (see keratin.TenantFromContext)
+	// by prefixing the tokens in the store with the request tenant,
+	// so that a session token of a tenant is unknown to the other ones.
+	PerTenant bool `env:"PER_TENANT" json:"perTenant,omitempty" yaml:"perTenant,omitempty"`
+
 	// Cookie contains the configuration settings for session cookies.
 	Cookie Cookie `envPrefix:"COOKIE_" json:"cookie,omitzero" yaml:"cookie,omitempty"`
 }
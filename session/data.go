@@ -12,6 +12,7 @@ import (
 	"time"
 	"unsafe"
 
+	"github.com/gowool/keratin"
 	"github.com/gowool/keratin/session/internal"
 )
 
@@ -34,10 +35,13 @@ const (
 
 type sessionData struct {
 	deadline time.Time
-	status   Status
-	token    string
-	values   map[string]any
-	mu       sync.Mutex
+	// fixedExpiry reports whether the idle timeout does not apply to the session, see Session.SetExpiry.
+	fixedExpiry bool
+	status      Status
+	token       string
+	values      map[string]any
+	unlock      func()
+	mu          sync.Mutex
 }
 
 func newSessionData(lifetime time.Duration) *sessionData {
@@ -94,14 +98,14 @@ func (s *Session) Load(ctx context.Context, token string) (context.Context, erro
 		status: Unmodified,
 		token:  token,
 	}
-	if sd.deadline, sd.values, err = s.codec.Decode(b); err != nil {
+	if sd.deadline, sd.fixedExpiry, sd.values, err = s.codec.Decode(b); err != nil {
 		return nil, err
 	}
 
-	// Mark the session data as modified if an idle timeout is being used. This
-	// will force the session data to be re-committed to the session store with
-	// a new expiry time.
-	if s.config.IdleTimeout > 0 {
+	// Mark the session data as modified if a sliding idle timeout is being used.
+	// This will force the session data to be re-committed to the session store
+	// with a new expiry time.
+	if s.config.IdleTimeout > 0 && !s.config.DisableSliding && !sd.fixedExpiry {
 		sd.status = Modified
 	}
 
@@ -123,13 +127,13 @@ func (s *Session) Commit(ctx context.Context) (string, time.Time, error) {
 		}
 	}
 
-	b, err := s.codec.Encode(sd.deadline, sd.values)
+	b, err := s.codec.Encode(sd.deadline, sd.fixedExpiry, sd.values)
 	if err != nil {
 		return "", time.Time{}, err
 	}
 
 	expiry := sd.deadline
-	if s.config.IdleTimeout > 0 {
+	if s.config.IdleTimeout > 0 && !sd.fixedExpiry {
 		ie := time.Now().Add(s.config.IdleTimeout).UTC()
 		if ie.Before(expiry) {
 			expiry = ie
@@ -162,6 +166,7 @@ func (s *Session) Destroy(ctx context.Context) error {
 	// Reset everything else to defaults.
 	sd.token = ""
 	sd.deadline = time.Now().Add(s.config.Lifetime).UTC()
+	sd.fixedExpiry = false
 	clear(sd.values)
 	return nil
 }
@@ -191,6 +196,35 @@ func (s *Session) SetDeadline(ctx context.Context, expire time.Time) {
 	sd.status = Modified
 }
 
+// SetExpiry fixes the session expiry at d from now regardless of the activity:
+// the deadline is set to now + d and the idle timeout no longer applies to the
+// session, e.g. "remember me for 30 days" (together with RememberMe) or "expire
+// exactly at the shift end". The session data status will be set to Modified.
+func (s *Session) SetExpiry(ctx context.Context, d time.Duration) {
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	sd.deadline = time.Now().Add(d).UTC()
+	sd.fixedExpiry = true
+	sd.status = Modified
+}
+
+// Touch sets the session data status to Modified without changing the data, so
+// the session is committed again and its idle timeout renewed. It is needed to
+// keep a session alive when Config.DisableSliding is set.
+func (s *Session) Touch(ctx context.Context) {
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	if sd.status != Destroyed {
+		sd.status = Modified
+	}
+}
+
 // Get returns the value for a given key from the session data. The return
 // value has the type any so will usually need to be type asserted
 // before you can use it. For example:
@@ -331,8 +365,9 @@ func (s *Session) SetToken(ctx context.Context, token string) {
 }
 
 // RenewToken updates the session data to have a new session token while
-// retaining the current session data. The session lifetime is also reset and
-// the session data status will be set to Modified.
+// retaining the current session data. The session lifetime is also reset, unless
+// the expiry was fixed with SetExpiry, and the session data status will be set
+// to Modified.
 //
 // The old session token and accompanying data are deleted from the session store.
 //
@@ -359,7 +394,9 @@ func (s *Session) RenewToken(ctx context.Context) error {
 	}
 
 	sd.token = newToken
-	sd.deadline = time.Now().Add(s.config.Lifetime).UTC()
+	if !sd.fixedExpiry {
+		sd.deadline = time.Now().Add(s.config.Lifetime).UTC()
+	}
 	sd.status = Modified
 
 	return nil
@@ -378,7 +415,7 @@ func (s *Session) MergeSession(ctx context.Context, token string) error {
 		return nil
 	}
 
-	deadline, values, err := s.codec.Decode(b)
+	deadline, _, values, err := s.codec.Decode(b)
 	if err != nil {
 		return err
 	}
@@ -750,22 +787,26 @@ func (s *Session) getSessionDataFromContext(ctx context.Context) *sessionData {
 }
 
 func (s *Session) doStoreDelete(ctx context.Context, token string) (err error) {
-	if s.config.HashTokenInStore {
-		token = hashToken(token)
-	}
-	return s.store.Delete(ctx, token)
+	return s.store.Delete(ctx, s.storeToken(ctx, token))
 }
 
 func (s *Session) doStoreFind(ctx context.Context, token string) (b []byte, found bool, err error) {
-	if s.config.HashTokenInStore {
-		token = hashToken(token)
-	}
-	return s.store.Find(ctx, token)
+	return s.store.Find(ctx, s.storeToken(ctx, token))
 }
 
 func (s *Session) doStoreCommit(ctx context.Context, token string, b []byte, expiry time.Time) (err error) {
+	return s.store.Commit(ctx, s.storeToken(ctx, token), b, expiry)
+}
+
+// storeToken returns the store key of the token.
+func (s *Session) storeToken(ctx context.Context, token string) string {
+	if s.config.PerTenant {
+		if tenant := keratin.TenantFromContext(ctx); tenant != "" {
+			token = tenant + ":" + token
+		}
+	}
 	if s.config.HashTokenInStore {
 		token = hashToken(token)
 	}
-	return s.store.Commit(ctx, token, b, expiry)
+	return token
 }
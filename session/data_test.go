@@ -2,12 +2,16 @@ package session
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
 )
 
 // Test setup helpers
@@ -127,7 +131,7 @@ func TestLoad_ExistingSession(t *testing.T) {
 	token := "existing-token"
 	storedData := []byte("encoded-data")
 	mockStore.On("Find", mock.Anything, token).Return(storedData, true, nil)
-	mockCodec.On("Decode", storedData).Return(time.Now().Add(time.Hour), map[string]any{"key": "value"}, nil)
+	mockCodec.On("Decode", storedData).Return(time.Now().Add(time.Hour), false, map[string]any{"key": "value"}, nil)
 
 	ctx := context.Background()
 	resultCtx, err := session.Load(ctx, token)
@@ -174,7 +178,7 @@ func TestLoad_CodecError(t *testing.T) {
 	token := "corrupted-token"
 	storedData := []byte("corrupted-data")
 	mockStore.On("Find", mock.Anything, token).Return(storedData, true, nil)
-	mockCodec.On("Decode", storedData).Return(time.Time{}, map[string]any(nil), assert.AnError)
+	mockCodec.On("Decode", storedData).Return(time.Time{}, false, map[string]any(nil), assert.AnError)
 
 	ctx := context.Background()
 	_, err := session.Load(ctx, token)
@@ -192,7 +196,7 @@ func TestLoad_IdleTimeout(t *testing.T) {
 	token := "existing-token"
 	storedData := []byte("encoded-data")
 	mockStore.On("Find", mock.Anything, token).Return(storedData, true, nil)
-	mockCodec.On("Decode", storedData).Return(time.Now().Add(time.Hour), map[string]any{"key": "value"}, nil)
+	mockCodec.On("Decode", storedData).Return(time.Now().Add(time.Hour), false, map[string]any{"key": "value"}, nil)
 
 	ctx := context.Background()
 	resultCtx, err := session.Load(ctx, token)
@@ -213,7 +217,7 @@ func TestCommit_NewToken(t *testing.T) {
 	expectedData := []byte("encoded-data")
 	mockStore := session.store.(*MockStore)
 	mockCodec := session.codec.(*MockCodec)
-	mockCodec.On("Encode", mock.Anything, mock.Anything).Return(expectedData, nil)
+	mockCodec.On("Encode", mock.Anything, mock.Anything, mock.Anything).Return(expectedData, nil)
 	mockStore.On("Commit", mock.Anything, mock.Anything, expectedData, mock.Anything).Return(nil)
 
 	token, expiry, err := session.Commit(ctx)
@@ -236,7 +240,7 @@ func TestCommit_ExistingToken(t *testing.T) {
 	expectedData := []byte("encoded-data")
 	mockStore := session.store.(*MockStore)
 	mockCodec := session.codec.(*MockCodec)
-	mockCodec.On("Encode", mock.Anything, mock.Anything).Return(expectedData, nil)
+	mockCodec.On("Encode", mock.Anything, mock.Anything, mock.Anything).Return(expectedData, nil)
 	mockStore.On("Commit", mock.Anything, "existing-token", expectedData, mock.Anything).Return(nil)
 
 	token, expiry, err := session.Commit(ctx)
@@ -255,7 +259,7 @@ func TestCommit_TokenGenerationError(t *testing.T) {
 	expectedData := []byte("encoded-data")
 	mockStore := session.store.(*MockStore)
 	mockCodec := session.codec.(*MockCodec)
-	mockCodec.On("Encode", mock.Anything, mock.Anything).Return(expectedData, nil)
+	mockCodec.On("Encode", mock.Anything, mock.Anything, mock.Anything).Return(expectedData, nil)
 	mockStore.On("Commit", mock.Anything, mock.Anything, expectedData, mock.Anything).Return(assert.AnError)
 
 	_, _, err = session.Commit(ctx)
@@ -334,6 +338,108 @@ func TestSetDeadline(t *testing.T) {
 	assert.Equal(t, Modified, session.Status(ctx))
 }
 
+func TestSetExpiry(t *testing.T) {
+	config := Config{Lifetime: 24 * time.Hour, IdleTimeout: time.Hour}
+	config.SetDefaults()
+	session := New(config, NewMemoryStore())
+
+	ctx, err := session.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	session.Put(ctx, "user_id", "42")
+	session.SetExpiry(ctx, 30*24*time.Hour)
+
+	deadline := session.Deadline(ctx)
+	assert.WithinDuration(t, time.Now().Add(30*24*time.Hour), deadline, time.Second)
+	assert.Equal(t, Modified, session.Status(ctx))
+	assert.Equal(t, []string{"user_id"}, session.Keys(ctx))
+
+	// the idle timeout does not apply to the fixed expiry
+	token, expiry, err := session.Commit(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, deadline, expiry)
+
+	// nor after the session is loaded again: it is neither slid nor shortened
+	ctx, err = session.Load(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, Unmodified, session.Status(ctx))
+	assert.True(t, deadline.Equal(session.Deadline(ctx)))
+
+	// clearing the values keeps the fixed expiry
+	require.NoError(t, session.Clear(ctx))
+	assert.Empty(t, session.Keys(ctx))
+
+	_, expiry, err = session.Commit(ctx)
+	require.NoError(t, err)
+	assert.True(t, deadline.Equal(expiry))
+
+	// destroying the session resets it
+	require.NoError(t, session.Destroy(ctx))
+	_, expiry, err = session.Commit(ctx)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiry, time.Second)
+}
+
+func TestTouch(t *testing.T) {
+	session, ctx, err := setupTestSession()
+	require.NoError(t, err)
+
+	assert.Equal(t, Unmodified, session.Status(ctx))
+
+	session.Touch(ctx)
+	assert.Equal(t, Modified, session.Status(ctx))
+	assert.Empty(t, session.Keys(ctx))
+
+	mockStore := session.store.(*MockStore)
+	mockStore.On("Delete", mock.Anything, mock.Anything).Return(nil)
+	require.NoError(t, session.Destroy(ctx))
+
+	session.Touch(ctx)
+	assert.Equal(t, Destroyed, session.Status(ctx))
+}
+
+func TestLoad_Sliding(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		fixedExpiry bool
+		wantStatus  Status
+	}{
+		{
+			name:       "sliding",
+			config:     Config{IdleTimeout: 30 * time.Minute},
+			wantStatus: Modified,
+		},
+		{
+			name:       "sliding disabled",
+			config:     Config{IdleTimeout: 30 * time.Minute, DisableSliding: true},
+			wantStatus: Unmodified,
+		},
+		{
+			name:        "fixed expiry",
+			config:      Config{IdleTimeout: 30 * time.Minute},
+			fixedExpiry: true,
+			wantStatus:  Unmodified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := &MockStore{}
+			mockCodec := &MockCodec{}
+			session := NewWithCodec(tt.config, mockStore, mockCodec)
+
+			mockStore.On("Find", mock.Anything, "token").Return([]byte("encoded-data"), true, nil)
+			mockCodec.On("Decode", []byte("encoded-data")).Return(time.Now().Add(time.Hour), tt.fixedExpiry, map[string]any{}, nil)
+
+			ctx, err := session.Load(context.Background(), "token")
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantStatus, session.Status(ctx))
+		})
+	}
+}
+
 func TestGet(t *testing.T) {
 	session, ctx, err := setupTestSessionWithData()
 	require.NoError(t, err)
@@ -626,6 +732,17 @@ func TestRenewToken(t *testing.T) {
 	mockStore.AssertExpectations(t)
 }
 
+func TestRenewToken_FixedExpiry(t *testing.T) {
+	session, ctx, err := setupTestSession()
+	require.NoError(t, err)
+
+	session.SetExpiry(ctx, time.Minute)
+	deadline := session.Deadline(ctx)
+
+	require.NoError(t, session.RenewToken(ctx))
+	assert.Equal(t, deadline, session.Deadline(ctx))
+}
+
 func TestRenewToken_NoExistingToken(t *testing.T) {
 	session, ctx, err := setupTestSession()
 	require.NoError(t, err)
@@ -674,7 +791,7 @@ func TestMergeSession(t *testing.T) {
 
 	encodedData := []byte("encoded")
 	mockStore.On("Find", mock.Anything, token).Return(encodedData, true, nil)
-	mockCodec.On("Decode", encodedData).Return(time.Now().Add(time.Hour), otherData, nil)
+	mockCodec.On("Decode", encodedData).Return(time.Now().Add(time.Hour), false, otherData, nil)
 	mockStore.On("Delete", mock.Anything, token).Return(nil)
 
 	err = session.MergeSession(ctx, token)
@@ -749,7 +866,7 @@ func TestMergeSession_CodecError(t *testing.T) {
 
 	encodedData := []byte("corrupted")
 	mockStore.On("Find", mock.Anything, token).Return(encodedData, true, nil)
-	mockCodec.On("Decode", encodedData).Return(time.Time{}, map[string]any(nil), assert.AnError)
+	mockCodec.On("Decode", encodedData).Return(time.Time{}, false, map[string]any(nil), assert.AnError)
 
 	err = session.MergeSession(ctx, token)
 	assert.Error(t, err)
@@ -864,7 +981,7 @@ func TestMergeSession_DeadlineBefore(t *testing.T) {
 
 	encodedData := []byte("encoded")
 	mockStore.On("Find", mock.Anything, token).Return(encodedData, true, nil)
-	mockCodec.On("Decode", encodedData).Return(newDeadline, otherData, nil)
+	mockCodec.On("Decode", encodedData).Return(newDeadline, false, otherData, nil)
 	mockStore.On("Delete", mock.Anything, token).Return(nil)
 
 	err = session.MergeSession(ctx, token)
@@ -1074,3 +1191,36 @@ func TestPopTime_NonExistent(t *testing.T) {
 	value := session.PopTime(ctx, "nonexistent")
 	assert.True(t, value.IsZero(), "should return zero value for non-existent key")
 }
+
+func TestSession_StoreToken_PerTenant(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    Config
+		tenant    string
+		wantToken string
+	}{
+		{name: "disabled", config: Config{}, tenant: "acme", wantToken: "test-token"},
+		{name: "tenant prefix", config: Config{PerTenant: true}, tenant: "acme", wantToken: "acme:test-token"},
+		{name: "no tenant", config: Config{PerTenant: true}, wantToken: "test-token"},
+		{name: "hashed with tenant prefix", config: Config{PerTenant: true, HashTokenInStore: true}, tenant: "acme", wantToken: hashToken("acme:test-token")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := NewWithCodec(tt.config, &MockStore{}, &MockCodec{})
+
+			var got string
+			router := keratin.NewRouter()
+			router.GET("/", func(_ http.ResponseWriter, r *http.Request) error {
+				if tt.tenant != "" {
+					keratin.SetTenant(r.Context(), tt.tenant)
+				}
+				got = session.storeToken(r.Context(), "test-token")
+				return nil
+			})
+			router.Build().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, tt.wantToken, got)
+		})
+	}
+}
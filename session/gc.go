@@ -0,0 +1,54 @@
+package session
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// GCReport is the outcome of a garbage collection run, e.g. to record the purged sessions metrics.
+type GCReport struct {
+	// Purged is the number of the deleted expired sessions.
+	Purged int
+	// Duration is the run duration.
+	Duration time.Duration
+	// Err is the store error of a failed run.
+	Err error
+}
+
+// GC deletes the expired sessions of store every interval until ctx is done, it blocks so it is
+// usually started in its own goroutine. The report funcs are called after every run, the failed runs
+// are also logged with [slog.Default].
+//
+// It panics if store is nil or interval is not positive.
+func GC(ctx context.Context, store IterableStore, interval time.Duration, report ...func(GCReport)) {
+	if store == nil {
+		panic("session: gc store is required")
+	}
+	if interval <= 0 {
+		panic("session: gc interval must be positive")
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		start := time.Now()
+		purged, err := store.DeleteExpired(ctx)
+		r := GCReport{Purged: purged, Duration: time.Since(start), Err: err}
+
+		if err != nil && ctx.Err() == nil {
+			slog.Default().ErrorContext(ctx, "session gc failed", "error", err)
+		}
+
+		for _, fn := range report {
+			fn(r)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingStore struct {
+	*MemoryStore
+}
+
+func (failingStore) DeleteExpired(context.Context) (int, error) {
+	return 0, errors.New("store is down")
+}
+
+func TestGC(t *testing.T) {
+	store := NewMemoryStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, token := range []string{"a", "b"} {
+		require.NoError(t, store.Commit(ctx, token, []byte(token), time.Now().Add(-time.Second)))
+	}
+	require.NoError(t, store.Commit(ctx, "c", []byte("c"), time.Now().Add(time.Hour)))
+
+	reports := make(chan GCReport, 10)
+	done := make(chan struct{})
+	go func() {
+		GC(ctx, store, 5*time.Millisecond, func(r GCReport) { reports <- r })
+		close(done)
+	}()
+
+	r := <-reports
+	assert.Equal(t, 2, r.Purged)
+	assert.NoError(t, r.Err)
+
+	r = <-reports
+	assert.Equal(t, 0, r.Purged)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("GC did not stop")
+	}
+
+	all, err := store.All(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+}
+
+func TestGC_Error(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reports := make(chan GCReport, 10)
+	go GC(ctx, failingStore{NewMemoryStore()}, 5*time.Millisecond, func(r GCReport) { reports <- r })
+
+	r := <-reports
+	assert.EqualError(t, r.Err, "store is down")
+}
+
+func TestGC_Panics(t *testing.T) {
+	assert.PanicsWithValue(t, "session: gc store is required", func() {
+		GC(context.Background(), nil, time.Second)
+	})
+	assert.PanicsWithValue(t, "session: gc interval must be positive", func() {
+		GC(context.Background(), NewMemoryStore(), 0)
+	})
+}
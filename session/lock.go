@@ -0,0 +1,68 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrLockTimeout is returned when a request waited Config.LockTimeout for the other requests
+// of the same session without getting its turn.
+var ErrLockTimeout = errors.New("session: lock timeout")
+
+// tokenLocks serializes the requests sharing a session token.
+type tokenLocks struct {
+	mu    sync.Mutex
+	locks map[string]*tokenLock
+}
+
+type tokenLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+// lock waits until the token is unlocked, timeout elapses or ctx is done.
+// The returned func unlocks the token, it may be called more than once.
+func (l *tokenLocks) lock(ctx context.Context, token string, timeout time.Duration) (func(), error) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*tokenLock)
+	}
+	tl, ok := l.locks[token]
+	if !ok {
+		tl = &tokenLock{ch: make(chan struct{}, 1)}
+		l.locks[token] = tl
+	}
+	tl.refs++
+	l.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case tl.ch <- struct{}{}:
+		var once sync.Once
+		return func() {
+			once.Do(func() {
+				<-tl.ch
+				l.release(token, tl)
+			})
+		}, nil
+	case <-timer.C:
+		l.release(token, tl)
+		return nil, ErrLockTimeout
+	case <-ctx.Done():
+		l.release(token, tl)
+		return nil, ctx.Err()
+	}
+}
+
+func (l *tokenLocks) release(token string, tl *tokenLock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if tl.refs--; tl.refs == 0 {
+		delete(l.locks, token)
+	}
+}
@@ -0,0 +1,99 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenLocks(t *testing.T) {
+	var locks tokenLocks
+	ctx := context.Background()
+
+	unlock, err := locks.lock(ctx, "a", time.Second)
+	require.NoError(t, err)
+
+	// the other tokens are not locked
+	unlockB, err := locks.lock(ctx, "b", time.Second)
+	require.NoError(t, err)
+	unlockB()
+
+	_, err = locks.lock(ctx, "a", 10*time.Millisecond)
+	assert.ErrorIs(t, err, ErrLockTimeout)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = locks.lock(cancelled, "a", time.Second)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	acquired := make(chan func())
+	go func() {
+		next, err := locks.lock(ctx, "a", time.Second)
+		assert.NoError(t, err)
+		acquired <- next
+	}()
+
+	unlock()
+	unlock() // no-op
+	(<-acquired)()
+
+	assert.Empty(t, locks.locks)
+}
+
+func TestMiddleware_LockTimeout(t *testing.T) {
+	store := NewMemoryStore()
+	data, err := NewGobCodec().Encode(time.Now().Add(time.Hour), false, map[string]any{"count": 0})
+	require.NoError(t, err)
+	require.NoError(t, store.Commit(context.Background(), "token", data, time.Now().Add(time.Hour)))
+
+	session := New(Config{LockTimeout: 50 * time.Millisecond}, store)
+
+	release := make(chan struct{})
+	entered := make(chan struct{}, 2)
+	handler := Middleware(NewRegistry(session), nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+		session.Put(r.Context(), "count", session.GetInt(r.Context(), "count")+1)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.AddCookie(&http.Cookie{Name: "session", Value: "token"})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		assert.Equal(t, http.StatusOK, request("/slow").Code)
+	})
+	<-entered
+
+	// the concurrent request of the session waits for the slow one and times out
+	assert.Equal(t, http.StatusServiceUnavailable, request("/fast").Code)
+
+	// the waiting request sees the changes of the previous one
+	wg.Go(func() {
+		assert.Equal(t, http.StatusOK, request("/fast").Code)
+	})
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	b, found, err := store.Find(context.Background(), "token")
+	require.NoError(t, err)
+	require.True(t, found)
+	_, _, values, err := NewGobCodec().Decode(b)
+	require.NoError(t, err)
+	assert.Equal(t, 2, values["count"])
+}
@@ -0,0 +1,82 @@
+package session
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+var _ IterableStore = (*MemoryStore)(nil)
+
+type memoryItem struct {
+	data   []byte
+	expiry time.Time
+}
+
+// MemoryStore is an in-memory [IterableStore] for the tests and the single instance deployments.
+// The expired sessions are not found but kept until DeleteExpired is called, see [GC].
+type MemoryStore struct {
+	mu    sync.RWMutex
+	items map[string]memoryItem
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{items: make(map[string]memoryItem)}
+}
+
+func (m *MemoryStore) Find(_ context.Context, token string) ([]byte, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	item, ok := m.items[token]
+	if !ok || !time.Now().Before(item.expiry) {
+		return nil, false, nil
+	}
+	return slices.Clone(item.data), true, nil
+}
+
+func (m *MemoryStore) Commit(_ context.Context, token string, data []byte, expiry time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.items[token] = memoryItem{data: slices.Clone(data), expiry: expiry}
+	return nil
+}
+
+func (m *MemoryStore) Delete(_ context.Context, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.items, token)
+	return nil
+}
+
+func (m *MemoryStore) All(_ context.Context) (map[string][]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	all := make(map[string][]byte, len(m.items))
+	for token, item := range m.items {
+		if now.Before(item.expiry) {
+			all[token] = slices.Clone(item.data)
+		}
+	}
+	return all, nil
+}
+
+func (m *MemoryStore) DeleteExpired(_ context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	n := 0
+	for token, item := range m.items {
+		if !now.Before(item.expiry) {
+			delete(m.items, token)
+			n++
+		}
+	}
+	return n, nil
+}
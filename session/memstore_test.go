@@ -0,0 +1,42 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	require.NoError(t, store.Commit(ctx, "live", []byte("live-data"), time.Now().Add(time.Hour)))
+	require.NoError(t, store.Commit(ctx, "expired", []byte("expired-data"), time.Now().Add(-time.Second)))
+
+	data, found, err := store.Find(ctx, "live")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("live-data"), data)
+
+	_, found, err = store.Find(ctx, "expired")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	all, err := store.All(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"live": []byte("live-data")}, all)
+
+	n, err := store.DeleteExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Len(t, store.items, 1)
+
+	require.NoError(t, store.Delete(ctx, "live"))
+	_, found, err = store.Find(ctx, "live")
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.Empty(t, store.items)
+}
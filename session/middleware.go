@@ -1,9 +1,11 @@
 package session
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gowool/keratin/middleware"
 )
@@ -27,6 +29,15 @@ func Middleware(registry *Registry, logger *slog.Logger, skippers ...middleware.
 			}
 
 			r, err := registry.ReadSessions(r)
+			defer registry.UnlockSessions(r)
+
+			if errors.Is(err, ErrLockTimeout) {
+				logger.WarnContext(r.Context(), "failed to lock sessions", "error", err)
+
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+
+				return
+			}
 			if err != nil {
 				logger.ErrorContext(r.Context(), "failed to read sessions", "error", err)
 
@@ -63,12 +74,30 @@ func (sw *sessionWriter) reset(w http.ResponseWriter, request *http.Request, reg
 }
 
 func (sw *sessionWriter) WriteHeader(code int) {
+	// the session cookies are sent with the final response, not with the informational ones
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		sw.ResponseWriter.WriteHeader(code)
+		return
+	}
+
 	if err := sw.registry.WriteSessions(sw, sw.request); err != nil {
 		sw.logger.ErrorContext(sw.request.Context(), "failed to write sessions", "error", err)
 	}
 	sw.ResponseWriter.WriteHeader(code)
 }
 
+func (sw *sessionWriter) SetReadDeadline(deadline time.Time) error {
+	return http.NewResponseController(sw.ResponseWriter).SetReadDeadline(deadline)
+}
+
+func (sw *sessionWriter) SetWriteDeadline(deadline time.Time) error {
+	return http.NewResponseController(sw.ResponseWriter).SetWriteDeadline(deadline)
+}
+
+func (sw *sessionWriter) EnableFullDuplex() error {
+	return http.NewResponseController(sw.ResponseWriter).EnableFullDuplex()
+}
+
 func (sw *sessionWriter) Unwrap() http.ResponseWriter {
 	return sw.ResponseWriter
 }
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/gowool/keratin/middleware"
 	"github.com/stretchr/testify/assert"
@@ -165,7 +166,7 @@ func TestMiddleware(t *testing.T) {
 
 		mockStore := &MockStore{}
 		mockCodec := &MockCodec{}
-		mockCodec.On("Encode", mock.Anything, mock.Anything).Return([]byte("encoded"), nil)
+		mockCodec.On("Encode", mock.Anything, mock.Anything, mock.Anything).Return([]byte("encoded"), nil)
 		mockStore.On("Commit", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("write error"))
 
 		session := NewWithCodec(Config{Cookie: Cookie{Name: "test"}}, mockStore, mockCodec)
@@ -230,7 +231,7 @@ func TestSessionWriter(t *testing.T) {
 	t.Run("WriteHeader calls WriteSessions", func(t *testing.T) {
 		mockStore := &MockStore{}
 		mockCodec := &MockCodec{}
-		mockCodec.On("Encode", mock.Anything, mock.Anything).Return([]byte("encoded"), nil)
+		mockCodec.On("Encode", mock.Anything, mock.Anything, mock.Anything).Return([]byte("encoded"), nil)
 		mockStore.On("Commit", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 		session := NewWithCodec(Config{Cookie: Cookie{Name: "test"}}, mockStore, mockCodec)
@@ -256,13 +257,54 @@ func TestSessionWriter(t *testing.T) {
 		mockCodec.AssertExpectations(t)
 	})
 
+	t.Run("delegates deadlines", func(t *testing.T) {
+		deadline := time.Now().Add(time.Minute)
+		rec := &deadlineRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+		sw := &sessionWriter{}
+		sw.reset(rec, httptest.NewRequest(http.MethodGet, "/", nil), NewRegistry(), slog.New(slog.DiscardHandler))
+
+		rc := http.NewResponseController(sw)
+		require.NoError(t, rc.SetReadDeadline(deadline))
+		require.NoError(t, rc.SetWriteDeadline(deadline))
+		require.NoError(t, rc.EnableFullDuplex())
+
+		assert.Equal(t, deadline, rec.readDeadline)
+		assert.Equal(t, deadline, rec.writeDeadline)
+		assert.True(t, rec.fullDuplex)
+	})
+
+	t.Run("informational WriteHeader does not write sessions", func(t *testing.T) {
+		mockStore := &MockStore{}
+		mockCodec := &MockCodec{}
+
+		session := NewWithCodec(Config{Cookie: Cookie{Name: "test"}}, mockStore, mockCodec)
+		registry := NewRegistry(session)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+
+		ctx, err := session.Load(req.Context(), "")
+		require.NoError(t, err)
+		req = req.WithContext(ctx)
+
+		sw := &sessionWriter{}
+		sw.reset(rec, req, registry, slog.New(slog.DiscardHandler))
+
+		registry.Get("test").Put(req.Context(), "key", "value")
+
+		sw.WriteHeader(http.StatusEarlyHints)
+
+		assert.Empty(t, rec.Header().Values("Set-Cookie"))
+		mockStore.AssertNotCalled(t, "Commit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
 	t.Run("WriteHeader logs error on WriteSessions failure", func(t *testing.T) {
 		var logBuffer bytes.Buffer
 		logger := slog.New(slog.NewTextHandler(&logBuffer, nil))
 
 		mockStore := &MockStore{}
 		mockCodec := &MockCodec{}
-		mockCodec.On("Encode", mock.Anything, mock.Anything).Return([]byte("encoded"), nil)
+		mockCodec.On("Encode", mock.Anything, mock.Anything, mock.Anything).Return([]byte("encoded"), nil)
 		mockStore.On("Commit", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("write error"))
 
 		session := NewWithCodec(Config{Cookie: Cookie{Name: "test"}}, mockStore, mockCodec)
@@ -371,7 +413,7 @@ func TestMiddlewareIntegration(t *testing.T) {
 		mockStore := &MockStore{}
 		mockCodec := &MockCodec{}
 
-		mockCodec.On("Encode", mock.Anything, mock.Anything).Return([]byte("encoded"), nil)
+		mockCodec.On("Encode", mock.Anything, mock.Anything, mock.Anything).Return([]byte("encoded"), nil)
 		mockStore.On("Commit", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 		session := NewWithCodec(Config{Cookie: Cookie{Name: "user"}}, mockStore, mockCodec)
@@ -405,7 +447,7 @@ func TestMiddlewareIntegration(t *testing.T) {
 		mockStore := &MockStore{}
 		mockCodec := &MockCodec{}
 
-		mockCodec.On("Encode", mock.Anything, mock.Anything).Return([]byte("encoded"), nil).Twice()
+		mockCodec.On("Encode", mock.Anything, mock.Anything, mock.Anything).Return([]byte("encoded"), nil).Twice()
 		mockStore.On("Commit", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Twice()
 
 		session1 := NewWithCodec(Config{Cookie: Cookie{Name: "user"}}, mockStore, mockCodec)
@@ -515,3 +557,25 @@ func createTestSessionWithStore(name string, store Store) *Session {
 	}
 	return New(config, store)
 }
+
+type deadlineRecorder struct {
+	*httptest.ResponseRecorder
+	readDeadline  time.Time
+	writeDeadline time.Time
+	fullDuplex    bool
+}
+
+func (d *deadlineRecorder) SetReadDeadline(deadline time.Time) error {
+	d.readDeadline = deadline
+	return nil
+}
+
+func (d *deadlineRecorder) SetWriteDeadline(deadline time.Time) error {
+	d.writeDeadline = deadline
+	return nil
+}
+
+func (d *deadlineRecorder) EnableFullDuplex() error {
+	d.fullDuplex = true
+	return nil
+}
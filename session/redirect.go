@@ -0,0 +1,75 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const intendedURLKey = "__intendedURL"
+
+// SafeRedirectURL reports whether target is a local, same-origin path that can
+// be safely used as a redirect location. Absolute URLs, scheme-relative URLs
+// ("//evil.com") and backslash tricks ("/\evil.com") are rejected to prevent
+// open redirect attacks.
+func SafeRedirectURL(target string) bool {
+	if target == "" || target[0] != '/' {
+		return false
+	}
+	if len(target) > 1 && (target[1] == '/' || target[1] == '\\') {
+		return false
+	}
+	if strings.ContainsAny(target, "\\\r\n\t") {
+		return false
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+
+	return u.Scheme == "" && u.Host == "" && u.User == nil
+}
+
+// PutIntendedURL stores the request URI as the location the client should be
+// sent back to after a successful login. Only GET and HEAD requests are captured,
+// since other methods can't be replayed by a redirect.
+func (s *Session) PutIntendedURL(ctx context.Context, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return
+	}
+
+	if target := r.URL.RequestURI(); SafeRedirectURL(target) {
+		s.Put(ctx, intendedURLKey, target)
+	}
+}
+
+// PopIntendedURL returns the location stored with [Session.PutIntendedURL] and
+// removes it from the session data. The fallback is returned if nothing was
+// stored or the stored value is not a safe redirect location.
+func (s *Session) PopIntendedURL(ctx context.Context, fallback string) string {
+	if target := s.PopString(ctx, intendedURLKey); SafeRedirectURL(target) {
+		return target
+	}
+	return fallback
+}
+
+// RedirectToLogin stores the intended URL of r in the session and redirects
+// the client to loginURL. It is meant to be called when an unauthenticated
+// request hits a protected route.
+func (s *Session) RedirectToLogin(w http.ResponseWriter, r *http.Request, loginURL string) error {
+	s.PutIntendedURL(r.Context(), r)
+
+	http.Redirect(w, r, loginURL, http.StatusSeeOther)
+
+	return nil
+}
+
+// RedirectAfterLogin redirects the client to the location stored by
+// [Session.RedirectToLogin] or to fallback if there is none.
+func (s *Session) RedirectAfterLogin(w http.ResponseWriter, r *http.Request, fallback string) error {
+	http.Redirect(w, r, s.PopIntendedURL(r.Context(), fallback), http.StatusSeeOther)
+
+	return nil
+}
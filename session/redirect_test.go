@@ -0,0 +1,97 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+func TestSafeRedirectURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		want   bool
+	}{
+		{name: "empty", target: "", want: false},
+		{name: "root", target: "/", want: true},
+		{name: "path with query", target: "/admin/users?page=2", want: true},
+		{name: "relative path", target: "admin", want: false},
+		{name: "absolute url", target: "https://evil.com/", want: false},
+		{name: "scheme relative", target: "//evil.com", want: false},
+		{name: "backslash", target: "/\\evil.com", want: false},
+		{name: "embedded backslash", target: "/foo\\bar", want: false},
+		{name: "header injection", target: "/foo\r\nLocation: x", want: false},
+		{name: "javascript scheme", target: "javascript:alert(1)", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SafeRedirectURL(tt.target))
+		})
+	}
+}
+
+func TestSession_PutIntendedURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		want   string
+	}{
+		{name: "GET request is captured", method: http.MethodGet, target: "/admin?tab=1", want: "/admin?tab=1"},
+		{name: "HEAD request is captured", method: http.MethodHead, target: "/admin", want: "/admin"},
+		{name: "POST request is ignored", method: http.MethodPost, target: "/admin", want: "/fallback"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, ctx, err := setupTestSession()
+			require.NoError(t, err)
+
+			r := httptest.NewRequest(tt.method, tt.target, nil)
+			s.PutIntendedURL(ctx, r)
+
+			assert.Equal(t, tt.want, s.PopIntendedURL(ctx, "/fallback"))
+			assert.Equal(t, "/fallback", s.PopIntendedURL(ctx, "/fallback"))
+		})
+	}
+}
+
+func TestSession_PopIntendedURL_Unsafe(t *testing.T) {
+	s, ctx, err := setupTestSession()
+	require.NoError(t, err)
+
+	s.Put(ctx, intendedURLKey, "//evil.com")
+
+	assert.Equal(t, "/", s.PopIntendedURL(ctx, "/"))
+	assert.False(t, s.Has(ctx, intendedURLKey))
+}
+
+func TestSession_RedirectFlow(t *testing.T) {
+	s, ctx, err := setupTestSession()
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodGet, "/account/settings", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	require.NoError(t, s.RedirectToLogin(w, r, "/login"))
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/login", w.Header().Get(keratin.HeaderLocation))
+
+	r = httptest.NewRequest(http.MethodPost, "/login", nil).WithContext(ctx)
+	w = httptest.NewRecorder()
+
+	require.NoError(t, s.RedirectAfterLogin(w, r, "/"))
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/account/settings", w.Header().Get(keratin.HeaderLocation))
+
+	w = httptest.NewRecorder()
+
+	require.NoError(t, s.RedirectAfterLogin(w, r, "/"))
+	assert.Equal(t, "/", w.Header().Get(keratin.HeaderLocation))
+}
@@ -48,6 +48,13 @@ func (r *Registry) ReadSessions(req *http.Request) (_ *http.Request, err error)
 	return req, nil
 }
 
+// UnlockSessions releases the session tokens locked by ReadSessions, see Config.LockTimeout.
+func (r *Registry) UnlockSessions(req *http.Request) {
+	for _, s := range r.All() {
+		s.Unlock(req.Context())
+	}
+}
+
 func (r *Registry) WriteSessions(w http.ResponseWriter, req *http.Request) (err error) {
 	ctx := req.Context()
 
@@ -2,6 +2,7 @@ package session
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -13,6 +14,7 @@ type Session struct {
 	config Config
 	store  Store
 	codec  Codec
+	locks  tokenLocks
 
 	// contextKey is the key used to set and retrieve the session data from a
 	// context.Context. It's automatically generated to ensure uniqueness.
@@ -23,9 +25,15 @@ func New(cfg Config, store Store) *Session {
 	return NewWithCodec(cfg, store, NewGobCodec())
 }
 
+// NewWithCodec creates a new Session, it panics if the cookie configuration is invalid,
+// see [Cookie.Validate].
 func NewWithCodec(cfg Config, store Store, codec Codec) *Session {
 	cfg.SetDefaults()
 
+	if err := cfg.Cookie.Validate(); err != nil {
+		panic(fmt.Errorf("session: %w", err))
+	}
+
 	return &Session{
 		config:     cfg,
 		store:      store,
@@ -38,20 +46,56 @@ func NewWithCodec(cfg Config, store Store, codec Codec) *Session {
 // loads the session data into the request context. If the cookie is
 // invalid, it returns an error. The session data is stored in the
 // request context under the key defined by the session's contextKey.
+//
+// If Config.LockTimeout is set, the session token is locked until Unlock
+// is called, it returns ErrLockTimeout if the lock was not acquired in time.
 func (s *Session) ReadSessionCookie(r *http.Request) (*http.Request, error) {
 	var token string
 	if cookie, err := r.Cookie(s.config.Cookie.Name); err == nil {
 		token = cookie.Value
 	}
 
+	var unlock func()
+	if _, loaded := r.Context().Value(s.contextKey).(*sessionData); !loaded && token != "" && s.config.LockTimeout > 0 {
+		var err error
+		if unlock, err = s.locks.lock(r.Context(), s.storeToken(r.Context(), token), s.config.LockTimeout); err != nil {
+			return r, err
+		}
+	}
+
 	ctx, err := s.Load(r.Context(), token)
 	if err != nil {
+		if unlock != nil {
+			unlock()
+		}
 		return r, err
 	}
 
+	if unlock != nil {
+		s.getSessionDataFromContext(ctx).unlock = unlock
+	}
+
 	return r.WithContext(ctx), nil
 }
 
+// Unlock releases the session token locked by ReadSessionCookie (see Config.LockTimeout),
+// so the next request of the session can proceed. It is a no-op if the token is not locked.
+func (s *Session) Unlock(ctx context.Context) {
+	sd, ok := ctx.Value(s.contextKey).(*sessionData)
+	if !ok {
+		return
+	}
+
+	sd.mu.Lock()
+	unlock := sd.unlock
+	sd.unlock = nil
+	sd.mu.Unlock()
+
+	if unlock != nil {
+		unlock()
+	}
+}
+
 // WriteSessionCookie writes a cookie to the HTTP response with the provided
 // token as the cookie value and expiry as the cookie expiry time. The expiry
 // time will be included in the cookie only if the session is set to persist
@@ -60,8 +104,7 @@ func (s *Session) ReadSessionCookie(r *http.Request) (*http.Request, error) {
 // marked with a historical expiry time and negative max-age (so the browser
 // deletes it).
 func (s *Session) WriteSessionCookie(ctx context.Context, w http.ResponseWriter, token string, expiry time.Time) {
-	cookie := &http.Cookie{
-		HttpOnly:    true,
+	cookie := keratin.CookieOpts{
 		Value:       token,
 		Name:        s.config.Cookie.Name,
 		Path:        s.config.Cookie.Path,
@@ -90,5 +133,5 @@ func (s *Session) WriteSessionCookie(ctx context.Context, w http.ResponseWriter,
 		w.Header().Add(keratin.HeaderCacheControl, `no-cache="Set-Cookie"`)
 	}
 
-	http.SetCookie(w, cookie)
+	keratin.SetCookie(w, cookie)
 }
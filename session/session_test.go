@@ -70,6 +70,36 @@ func TestNewWithCodec(t *testing.T) {
 	assert.Equal(t, 24*time.Hour, got.config.Lifetime)
 }
 
+func TestNewWithCodec_InvalidCookie(t *testing.T) {
+	tests := []struct {
+		name    string
+		cookie  Cookie
+		wantErr string
+	}{
+		{name: "host prefix without secure", cookie: Cookie{Name: "__Host-session"}, wantErr: "requires Secure"},
+		{name: "host prefix with domain", cookie: Cookie{Name: "__Host-session", Secure: true, Domain: "example.com"}, wantErr: "forbids Domain"},
+		{name: "host prefix with path", cookie: Cookie{Name: "__Host-session", Secure: true, Path: "/app"}, wantErr: `requires Path "/"`},
+		{name: "secure prefix without secure", cookie: Cookie{Name: "__Secure-session"}, wantErr: "requires Secure"},
+		{name: "partitioned without secure", cookie: Cookie{Partitioned: true}, wantErr: "Partitioned requires Secure"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				err, ok := recover().(error)
+				require.True(t, ok)
+				assert.ErrorContains(t, err, tt.wantErr)
+			}()
+
+			NewWithCodec(Config{Cookie: tt.cookie}, &MockStore{}, &MockCodec{})
+		})
+	}
+
+	assert.NotPanics(t, func() {
+		NewWithCodec(Config{Cookie: Cookie{Name: "__Host-session", Secure: true, Partitioned: true}}, &MockStore{}, &MockCodec{})
+	})
+}
+
 func TestReadSessionCookie(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -115,7 +145,7 @@ func TestReadSessionCookie(t *testing.T) {
 			}
 
 			if tt.storeFound {
-				mockCodec.On("Decode", tt.storeResponse).Return(time.Now().Add(time.Hour), make(map[string]any), nil)
+				mockCodec.On("Decode", tt.storeResponse).Return(time.Now().Add(time.Hour), false, make(map[string]any), nil)
 			}
 
 			config := Config{}
@@ -24,3 +24,16 @@ type Store interface {
 	// expiry time should be overwritten.
 	Commit(ctx context.Context, token string, data []byte, expiry time.Time) (err error)
 }
+
+// IterableStore is implemented by the stores which can list and purge their sessions,
+// see [GC] for the scheduled purge of the expired sessions.
+type IterableStore interface {
+	Store
+
+	// All should return the data of all the unexpired sessions keyed by token.
+	All(ctx context.Context) (map[string][]byte, error)
+
+	// DeleteExpired should remove the expired sessions from the store
+	// and return the number of the removed sessions.
+	DeleteExpired(ctx context.Context) (n int, err error)
+}
@@ -0,0 +1,69 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrValueNotFound is returned by [Session.Bind] when the key does not exist in the session data.
+var ErrValueNotFound = errors.New("session: value not found")
+
+// Get returns the value of type T for a given key from the session data,
+// the boolean reports whether the key exists and its value could be converted to T.
+// See [Session.Bind] for the conversion rules.
+//
+//	cart, ok := session.Get[Cart](ctx, sessionManager, "cart")
+func Get[T any](ctx context.Context, s *Session, key string) (T, bool) {
+	var v T
+	if err := s.Bind(ctx, key, &v); err != nil {
+		return v, false
+	}
+	return v, true
+}
+
+// Pop acts like a one-time [Get], it deletes the key from the session data
+// and the session data status will be set to Modified.
+func Pop[T any](ctx context.Context, s *Session, key string) (T, bool) {
+	var v T
+	if err := bindValue(s.Pop(ctx, key), &v); err != nil {
+		return v, false
+	}
+	return v, true
+}
+
+// Bind stores the value for a given key from the session data in the value pointed to by dst.
+//
+// Values of a type assignable to dst are stored as is. Otherwise the value is converted through JSON,
+// e.g. a map[string]any produced by a JSON codec is bound to a struct or a float64 to an int.
+// It returns [ErrValueNotFound] if the key does not exist.
+func (s *Session) Bind(ctx context.Context, key string, dst any) error {
+	return bindValue(s.Get(ctx, key), dst)
+}
+
+func bindValue(val, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("session: bind destination must be a non-nil pointer")
+	}
+
+	if val == nil {
+		return ErrValueNotFound
+	}
+
+	if v := reflect.ValueOf(val); v.Type().AssignableTo(rv.Elem().Type()) {
+		rv.Elem().Set(v)
+		return nil
+	}
+
+	b, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("session: bind %T to %T: %w", val, dst, err)
+	}
+	if err = json.Unmarshal(b, dst); err != nil {
+		return fmt.Errorf("session: bind %T to %T: %w", val, dst, err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedTestCart struct {
+	Items []string `json:"items"`
+	Total int      `json:"total"`
+}
+
+func TestGet_Typed(t *testing.T) {
+	s, ctx, err := setupTestSession()
+	require.NoError(t, err)
+
+	s.Put(ctx, "cart", typedTestCart{Items: []string{"a"}, Total: 1})
+	s.Put(ctx, "json_cart", map[string]any{"items": []any{"b"}, "total": float64(2)})
+	s.Put(ctx, "count", float64(3))
+	s.Put(ctx, "name", "john")
+
+	cart, ok := Get[typedTestCart](ctx, s, "cart")
+	assert.True(t, ok)
+	assert.Equal(t, typedTestCart{Items: []string{"a"}, Total: 1}, cart)
+
+	cart, ok = Get[typedTestCart](ctx, s, "json_cart")
+	assert.True(t, ok)
+	assert.Equal(t, typedTestCart{Items: []string{"b"}, Total: 2}, cart)
+
+	count, ok := Get[int](ctx, s, "count")
+	assert.True(t, ok)
+	assert.Equal(t, 3, count)
+
+	name, ok := Get[any](ctx, s, "name")
+	assert.True(t, ok)
+	assert.Equal(t, "john", name)
+
+	_, ok = Get[int](ctx, s, "name")
+	assert.False(t, ok)
+
+	_, ok = Get[string](ctx, s, "missing")
+	assert.False(t, ok)
+}
+
+func TestPop_Typed(t *testing.T) {
+	s, ctx, err := setupTestSession()
+	require.NoError(t, err)
+
+	s.Put(ctx, "flash", "saved")
+
+	flash, ok := Pop[string](ctx, s, "flash")
+	assert.True(t, ok)
+	assert.Equal(t, "saved", flash)
+	assert.False(t, s.Has(ctx, "flash"))
+	assert.Equal(t, Modified, s.Status(ctx))
+
+	_, ok = Pop[string](ctx, s, "flash")
+	assert.False(t, ok)
+}
+
+func TestSession_Bind(t *testing.T) {
+	s, ctx, err := setupTestSession()
+	require.NoError(t, err)
+
+	s.Put(ctx, "cart", &typedTestCart{Total: 5})
+
+	var cart typedTestCart
+	require.NoError(t, s.Bind(ctx, "cart", &cart))
+	assert.Equal(t, 5, cart.Total)
+
+	assert.ErrorIs(t, s.Bind(ctx, "missing", &cart), ErrValueNotFound)
+	assert.Error(t, s.Bind(ctx, "cart", cart))
+}
@@ -0,0 +1,67 @@
+package keratin
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"net/http"
+)
+
+// SplitTarget is a handler of [Split] receiving Weight parts of the traffic.
+type SplitTarget struct {
+	Handler Handler
+	Weight  int
+}
+
+// Split returns a Handler which splits the traffic between the targets proportionally to their weights,
+// e.g. for canary releases:
+//
+//	router.GET("/checkout", keratin.Split([]keratin.SplitTarget{
+//		{Handler: checkoutV1, Weight: 95},
+//		{Handler: checkoutV2, Weight: 5},
+//	}, nil))
+//
+// The target is chosen deterministically by the hash of the keyFunc value, so that the requests
+// with the same key (e.g. a session or user ID) stick to the same target as long as the weights don't change.
+// When keyFunc is nil the client IP is used, the requests with an empty key are split randomly.
+//
+// The targets are a slice rather than a map so that their order, and thus the key assignment, is stable.
+func Split(targets []SplitTarget, keyFunc func(*http.Request) string) Handler {
+	var total uint64
+	for _, target := range targets {
+		if target.Handler == nil {
+			panic("keratin: split: target handler is required")
+		}
+		if target.Weight < 0 {
+			panic("keratin: split: target weight must not be negative")
+		}
+		total += uint64(target.Weight)
+	}
+	if total == 0 {
+		panic("keratin: split: at least one target with a positive weight is required")
+	}
+
+	if keyFunc == nil {
+		keyFunc = ClientIP
+	}
+
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		var n uint64
+		if key := keyFunc(r); key != "" {
+			h := fnv.New64a()
+			_, _ = h.Write([]byte(key))
+			n = h.Sum64() % total
+		} else {
+			n = rand.Uint64N(total)
+		}
+
+		for _, target := range targets {
+			if n < uint64(target.Weight) {
+				return target.Handler.ServeHTTP(w, r)
+			}
+			n -= uint64(target.Weight)
+		}
+
+		// unreachable, n < total
+		return targets[len(targets)-1].Handler.ServeHTTP(w, r)
+	})
+}
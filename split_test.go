@@ -0,0 +1,115 @@
+package keratin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func splitTargetHandler(name string) Handler {
+	return HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		return TextPlain(w, http.StatusOK, name)
+	})
+}
+
+func serveSplit(t *testing.T, handler Handler, req *http.Request) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, handler.ServeHTTP(rec, req))
+	return rec.Body.String()
+}
+
+func TestSplit(t *testing.T) {
+	keyFunc := func(r *http.Request) string { return r.Header.Get("X-User") }
+
+	t.Run("weights", func(t *testing.T) {
+		handler := Split([]SplitTarget{
+			{Handler: splitTargetHandler("stable"), Weight: 90},
+			{Handler: splitTargetHandler("disabled"), Weight: 0},
+			{Handler: splitTargetHandler("canary"), Weight: 10},
+		}, keyFunc)
+
+		counts := map[string]int{}
+		for i := range 10000 {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-User", "user-"+strconv.Itoa(i))
+			counts[serveSplit(t, handler, req)]++
+		}
+
+		assert.InDelta(t, 9000, counts["stable"], 300)
+		assert.InDelta(t, 1000, counts["canary"], 300)
+		assert.Zero(t, counts["disabled"])
+	})
+
+	t.Run("sticky by key", func(t *testing.T) {
+		handler := Split([]SplitTarget{
+			{Handler: splitTargetHandler("a"), Weight: 1},
+			{Handler: splitTargetHandler("b"), Weight: 1},
+		}, keyFunc)
+
+		for i := range 100 {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-User", "user-"+strconv.Itoa(i))
+
+			first := serveSplit(t, handler, req)
+			for range 5 {
+				assert.Equal(t, first, serveSplit(t, handler, req))
+			}
+		}
+	})
+
+	t.Run("client IP by default", func(t *testing.T) {
+		handler := Split([]SplitTarget{
+			{Handler: splitTargetHandler("a"), Weight: 1},
+			{Handler: splitTargetHandler("b"), Weight: 1},
+		}, nil)
+
+		seen := map[string]bool{}
+		for i := range 100 {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "10.0.0." + strconv.Itoa(i) + ":1234"
+			got := serveSplit(t, handler, req)
+			seen[got] = true
+
+			req.RemoteAddr = "10.0.0." + strconv.Itoa(i) + ":5678"
+			assert.Equal(t, got, serveSplit(t, handler, req))
+		}
+		assert.Len(t, seen, 2)
+	})
+
+	t.Run("empty key is split randomly", func(t *testing.T) {
+		handler := Split([]SplitTarget{
+			{Handler: splitTargetHandler("a"), Weight: 1},
+			{Handler: splitTargetHandler("b"), Weight: 1},
+		}, keyFunc)
+
+		seen := map[string]bool{}
+		for range 100 {
+			seen[serveSplit(t, handler, httptest.NewRequest(http.MethodGet, "/", nil))] = true
+		}
+		assert.Len(t, seen, 2)
+	})
+
+	t.Run("route handler", func(t *testing.T) {
+		router := NewRouter()
+		router.Route(http.MethodGet, "/", Split([]SplitTarget{{Handler: splitTargetHandler("only"), Weight: 1}}, nil))
+
+		rec := httptest.NewRecorder()
+		router.Build().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, "only", rec.Body.String())
+	})
+}
+
+func TestSplit_Panics(t *testing.T) {
+	ok := splitTargetHandler("ok")
+
+	assert.Panics(t, func() { Split(nil, nil) })
+	assert.Panics(t, func() { Split([]SplitTarget{{Handler: ok}}, nil) })
+	assert.Panics(t, func() { Split([]SplitTarget{{Handler: nil, Weight: 1}}, nil) })
+	assert.Panics(t, func() { Split([]SplitTarget{{Handler: ok, Weight: 2}, {Handler: ok, Weight: -1}}, nil) })
+}
@@ -0,0 +1,106 @@
+package keratin
+
+import (
+	"bytes"
+	"iter"
+	"net/http"
+
+	"github.com/gowool/keratin/internal"
+)
+
+// StreamJSONArray streams the sequence values as a JSON array with status code.
+//
+// Every value is encoded and flushed to the client as soon as it is produced,
+// so the memory usage does not depend on the sequence length.
+// Streaming stops when the request context is done (e.g. the client disconnected)
+// and the context error is returned. The bytes written are reported by [ResponseSize].
+//
+// Once the first value was written the response is committed, so an encoding error
+// leaves the client with a truncated array.
+func StreamJSONArray[T any](w http.ResponseWriter, r *http.Request, status int, seq iter.Seq[T]) error {
+	s := newJSONStreamer(w, r, status, MIMEApplicationJSON)
+
+	first := true
+	for v := range seq {
+		sep := byte(',')
+		if first {
+			sep, first = '[', false
+		}
+
+		if err := s.write(sep, v); err != nil {
+			return err
+		}
+	}
+
+	if first {
+		return s.close("[]\n")
+	}
+	return s.close("]\n")
+}
+
+// StreamNDJSON streams the sequence values as newline delimited JSON with status code.
+//
+// It has the same flushing and disconnection semantics as [StreamJSONArray].
+func StreamNDJSON[T any](w http.ResponseWriter, r *http.Request, status int, seq iter.Seq[T]) error {
+	s := newJSONStreamer(w, r, status, MIMEApplicationNDJSON)
+
+	for v := range seq {
+		if err := s.write(0, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type jsonStreamer struct {
+	w   http.ResponseWriter
+	r   *http.Request
+	rc  *http.ResponseController
+	buf bytes.Buffer
+}
+
+func newJSONStreamer(w http.ResponseWriter, r *http.Request, status int, contentType string) *jsonStreamer {
+	w.Header().Set(HeaderContentType, contentType)
+	w.Header().Set(HeaderXContentTypeOptions, "nosniff")
+	w.WriteHeader(status)
+
+	return &jsonStreamer{w: w, r: r, rc: http.NewResponseController(w)}
+}
+
+// write encodes v prefixed by sep (if not zero) and flushes it to the client.
+func (s *jsonStreamer) write(sep byte, v any) error {
+	if err := s.r.Context().Err(); err != nil {
+		return err
+	}
+
+	s.buf.Reset()
+	if sep != 0 {
+		s.buf.WriteByte(sep)
+	}
+	if err := internal.MarshalJSON(&s.buf, v, ""); err != nil {
+		return err
+	}
+	if b := s.buf.Bytes(); b[len(b)-1] != '\n' {
+		s.buf.WriteByte('\n')
+	}
+
+	if _, err := s.w.Write(s.buf.Bytes()); err != nil {
+		return err
+	}
+
+	_ = s.rc.Flush()
+
+	return nil
+}
+
+// close writes the trailing data and flushes it to the client.
+func (s *jsonStreamer) close(tail string) error {
+	if _, err := s.w.Write(internal.StringToBytes(tail)); err != nil {
+		return err
+	}
+
+	_ = s.rc.Flush()
+
+	return nil
+}
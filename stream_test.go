@@ -0,0 +1,104 @@
+package keratin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type streamItem struct {
+	ID int `json:"id"`
+}
+
+func TestStreamJSONArray(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []streamItem
+		want  []streamItem
+	}{
+		{name: "empty", items: nil, want: []streamItem{}},
+		{name: "one", items: []streamItem{{ID: 1}}, want: []streamItem{{ID: 1}}},
+		{name: "many", items: []streamItem{{ID: 1}, {ID: 2}, {ID: 3}}, want: []streamItem{{ID: 1}, {ID: 2}, {ID: 3}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+
+			err := StreamJSONArray(w, r, http.StatusOK, slices.Values(tt.items))
+			require.NoError(t, err)
+
+			assert.Equal(t, MIMEApplicationJSON, w.Header().Get(HeaderContentType))
+			assert.True(t, w.Flushed)
+
+			var got []streamItem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+
+	err := StreamNDJSON(w, r, http.StatusOK, slices.Values([]streamItem{{ID: 1}, {ID: 2}}))
+	require.NoError(t, err)
+
+	assert.Equal(t, MIMEApplicationNDJSON, w.Header().Get(HeaderContentType))
+	assert.Equal(t, []string{`{"id":1}`, `{"id":2}`}, strings.Fields(w.Body.String()))
+}
+
+func TestStreamNDJSON_ClientDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	produced := 0
+	seq := func(yield func(streamItem) bool) {
+		for i := range 10 {
+			produced++
+			if i == 2 {
+				cancel()
+			}
+			if !yield(streamItem{ID: i}) {
+				return
+			}
+		}
+	}
+
+	err := StreamNDJSON(w, r, http.StatusOK, seq)
+	require.ErrorIs(t, err, context.Canceled)
+
+	assert.Equal(t, 3, produced)
+	assert.Len(t, strings.Fields(w.Body.String()), 2)
+}
+
+func TestStreamJSONArray_ReportsSize(t *testing.T) {
+	router := NewRouter()
+	var size int64
+	router.Use(&Middleware[Handler]{Func: func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			err := next.ServeHTTP(w, r)
+			size = ResponseSize(w)
+			return err
+		})
+	}})
+	router.GET("/items", func(w http.ResponseWriter, r *http.Request) error {
+		return StreamJSONArray(w, r, http.StatusOK, slices.Values([]int{1, 2, 3}))
+	})
+
+	w := httptest.NewRecorder()
+	router.Build().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+
+	assert.Equal(t, int64(w.Body.Len()), size)
+	assert.Positive(t, size)
+}
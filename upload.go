@@ -0,0 +1,233 @@
+package keratin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// UploadLimits restricts the files accepted by [FormFile] and [FormFiles].
+type UploadLimits struct {
+	// MaxFileSize is the maximum size of a single file in bytes. When the multipart form is not parsed yet,
+	// the body is rejected as soon as one of its parts exceeds MaxFileSize plus [UploadPartHeaderSize],
+	// so the form fields are bounded too.
+	// Optional. Default value 0 (unlimited).
+	MaxFileSize int64 `env:"MAX_FILE_SIZE" json:"maxFileSize,omitempty" yaml:"maxFileSize,omitempty"`
+
+	// MaxFiles is the maximum number of files accepted by [FormFiles].
+	// Optional. Default value 0 (unlimited).
+	MaxFiles int `env:"MAX_FILES" json:"maxFiles,omitempty" yaml:"maxFiles,omitempty"`
+
+	// AllowedTypes is a list of allowed media types detected from the file content,
+	// e.g. "image/png" or "image/*".
+	// Optional. Default value none (any type).
+	AllowedTypes []string `env:"ALLOWED_TYPES" json:"allowedTypes,omitempty" yaml:"allowedTypes,omitempty"`
+}
+
+// UploadPartHeaderSize is the allowance for the headers of a part added to UploadLimits.MaxFileSize
+// when the multipart form is parsed.
+var UploadPartHeaderSize int64 = 4 * 1024
+
+// FormFile returns the first file for the provided form key.
+// The multipart form is parsed with [MultipartMaxMemory] if needed.
+//
+// Parsing failures and limit violations are returned as [HTTPError]s:
+// 400 for a missing file, 413 for too large files and 415 for not allowed content types.
+func FormFile(r *http.Request, name string, limits ...UploadLimits) (*multipart.FileHeader, error) {
+	var l UploadLimits
+	if len(limits) > 0 {
+		l = limits[0]
+	}
+
+	files, err := formFiles(r, name, l)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.check(files[0]); err != nil {
+		return nil, err
+	}
+	return files[0], nil
+}
+
+// FormFiles returns all files for the provided form key.
+// See [FormFile] for details on error handling.
+func FormFiles(r *http.Request, name string, limits ...UploadLimits) ([]*multipart.FileHeader, error) {
+	var l UploadLimits
+	if len(limits) > 0 {
+		l = limits[0]
+	}
+
+	files, err := formFiles(r, name, l)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.MaxFiles > 0 && len(files) > l.MaxFiles {
+		return nil, NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("too many files, at most %d allowed", l.MaxFiles))
+	}
+
+	for _, fh := range files {
+		if err = l.check(fh); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// DetectFileContentType sniffs the media type of the uploaded file using [http.DetectContentType].
+func DetectFileContentType(fh *multipart.FileHeader) (string, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	var buf [512]byte
+	n, err := io.ReadFull(f, buf[:])
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+
+	return http.DetectContentType(buf[:n]), nil
+}
+
+// SaveUploadedFile copies the uploaded file to dst, creating missing parent directories.
+func SaveUploadedFile(fh *multipart.FileHeader, dst string) error {
+	src, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	if err = os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return err
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(out, src); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func formFiles(r *http.Request, name string, l UploadLimits) ([]*multipart.FileHeader, error) {
+	if r.MultipartForm == nil {
+		if l.MaxFileSize > 0 {
+			limitParts(r, l.MaxFileSize+UploadPartHeaderSize)
+		}
+		if err := r.ParseMultipartForm(MultipartMaxMemory); err != nil {
+			return nil, multipartError(err)
+		}
+	}
+
+	if r.MultipartForm != nil {
+		if files := r.MultipartForm.File[name]; len(files) > 0 {
+			return files, nil
+		}
+	}
+
+	return nil, NewHTTPError(http.StatusBadRequest, fmt.Sprintf("missing file %q", name))
+}
+
+func multipartError(err error) error {
+	if _, ok := errors.AsType[*http.MaxBytesError](err); ok || errors.Is(err, multipart.ErrMessageTooLarge) {
+		return ErrRequestEntityTooLarge.Wrap(err)
+	}
+	if errors.Is(err, http.ErrNotMultipart) {
+		return ErrUnsupportedMediaType.Wrap(err)
+	}
+	if code := ErrorStatusCode(err); code >= http.StatusBadRequest {
+		return err
+	}
+	return ErrBadRequest.Wrap(err)
+}
+
+// limitParts bounds the size of every part of the multipart body of r,
+// so that a too large part is rejected before it is read whole.
+func limitParts(r *http.Request, limit int64) {
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get(HeaderContentType))
+	if err != nil || mediaType != MIMEMultipartForm || params["boundary"] == "" || r.Body == nil {
+		return
+	}
+
+	r.Body = &partSizeReader{
+		ReadCloser: r.Body,
+		delim:      []byte("--" + params["boundary"]),
+		limit:      limit,
+	}
+}
+
+// partSizeReader fails with [http.MaxBytesError] when the data between two boundary
+// delimiters of the raw multipart body exceeds the limit.
+type partSizeReader struct {
+	io.ReadCloser
+	delim []byte
+	limit int64
+	size  int64
+	tail  []byte
+}
+
+func (p *partSizeReader) Read(b []byte) (int, error) {
+	n, err := p.ReadCloser.Read(b)
+	if n == 0 {
+		return n, err
+	}
+
+	// the tail of the previous read (already counted) catches the delimiters split between reads
+	data := append(p.tail, b[:n]...)
+	counted := len(p.tail)
+	for {
+		i := bytes.Index(data, p.delim)
+		if i < 0 {
+			break
+		}
+		if p.size += int64(max(i-counted, 0)); p.size > p.limit {
+			return n, &http.MaxBytesError{Limit: p.limit}
+		}
+		data = data[i+len(p.delim):]
+		counted = 0
+		p.size = 0
+	}
+	if p.size += int64(len(data) - counted); p.size > p.limit {
+		return n, &http.MaxBytesError{Limit: p.limit}
+	}
+
+	keep := min(len(data), len(p.delim)-1)
+	p.tail = append(p.tail[:0], data[len(data)-keep:]...)
+
+	return n, err
+}
+
+func (l UploadLimits) check(fh *multipart.FileHeader) error {
+	if l.MaxFileSize > 0 && fh.Size > l.MaxFileSize {
+		return NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("file %q is too large, at most %d bytes allowed", fh.Filename, l.MaxFileSize))
+	}
+
+	if len(l.AllowedTypes) == 0 {
+		return nil
+	}
+
+	contentType, err := DetectFileContentType(fh)
+	if err != nil {
+		return ErrBadRequest.Wrap(err)
+	}
+
+	if MatchMediaType(contentType, l.AllowedTypes...) {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return NewHTTPError(http.StatusUnsupportedMediaType, fmt.Sprintf("file %q has not allowed content type %q", fh.Filename, mediaType))
+}
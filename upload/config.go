@@ -0,0 +1,45 @@
+package upload
+
+import (
+	"context"
+	"time"
+)
+
+type Config struct {
+	// Store stores the uploads.
+	//
+	// Default: NewMemoryStore()
+	Store Store `json:"-" yaml:"-"`
+
+	// MaxSize is the maximum size of an upload in bytes, 0 means unlimited.
+	MaxSize int64 `env:"MAX_SIZE" json:"maxSize,omitempty" yaml:"maxSize,omitempty"`
+
+	// Expiration is the time after the creation an upload is deleted by [Handler.Cleanup],
+	// also the completed one.
+	//
+	// Default: 24h
+	Expiration time.Duration `env:"EXPIRATION" json:"expiration,omitempty,format:units" yaml:"expiration,omitempty"`
+
+	// MaxChecksumChunkSize is the maximum size of a chunk sent with the Upload-Checksum header.
+	// Such a chunk is buffered in memory and stored only once its checksum is verified.
+	//
+	// Default: 32MB
+	MaxChecksumChunkSize int64 `env:"MAX_CHECKSUM_CHUNK_SIZE" json:"maxChecksumChunkSize,omitempty" yaml:"maxChecksumChunkSize,omitempty"`
+
+	// OnComplete is called once all the bytes of an upload are received, e.g. to move its data
+	// from the store. Its error is returned to the client sending the last chunk.
+	// Optional.
+	OnComplete func(ctx context.Context, info Info) error `json:"-" yaml:"-"`
+}
+
+func (c *Config) SetDefaults() {
+	if c.Store == nil {
+		c.Store = NewMemoryStore()
+	}
+	if c.Expiration <= 0 {
+		c.Expiration = 24 * time.Hour
+	}
+	if c.MaxChecksumChunkSize <= 0 {
+		c.MaxChecksumChunkSize = 32 << 20
+	}
+}
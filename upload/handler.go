@@ -0,0 +1,401 @@
+// Package upload implements the resumable uploads of the tus protocol (https://tus.io/protocols/resumable-upload),
+// with the creation, expiration, checksum and termination extensions. A client creates an upload with its size,
+// sends the data in one or more chunks and resumes it after a failure from the offset reported by the server.
+//
+//	uploads := upload.New(upload.Config{
+//		Store: store,
+//		OnComplete: func(ctx context.Context, info upload.Info) error {
+//			...
+//		},
+//	})
+//	uploads.Register(router.Group("/files"))
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1" //nolint:gosec // sha1 is a checksum algorithm of the tus protocol
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gowool/keratin"
+)
+
+const (
+	HeaderTusResumable          = "Tus-Resumable"
+	HeaderTusVersion            = "Tus-Version"
+	HeaderTusExtension          = "Tus-Extension"
+	HeaderTusMaxSize            = "Tus-Max-Size"
+	HeaderTusChecksumAlgorithm  = "Tus-Checksum-Algorithm"
+	HeaderUploadLength          = "Upload-Length"
+	HeaderUploadOffset          = "Upload-Offset"
+	HeaderUploadMetadata        = "Upload-Metadata"
+	HeaderUploadExpires         = "Upload-Expires"
+	HeaderUploadChecksum        = "Upload-Checksum"
+	MIMEApplicationOffsetOctets = "application/offset+octet-stream"
+
+	// Version is the supported version of the tus protocol.
+	Version = "1.0.0"
+
+	// StatusChecksumMismatch is the status of a chunk not matching its Upload-Checksum header.
+	StatusChecksumMismatch = 460
+
+	extensions         = "creation,expiration,checksum,termination"
+	checksumAlgorithms = "sha1,sha256,sha512"
+)
+
+var (
+	ErrVersionMismatch  = keratin.NewHTTPError(http.StatusPreconditionFailed, "unsupported tus version")
+	ErrOffsetMismatch   = keratin.NewHTTPError(http.StatusConflict, "upload offset mismatch")
+	ErrUploadLocked     = keratin.NewHTTPError(http.StatusLocked, "upload is being written")
+	ErrUploadExpired    = keratin.NewHTTPError(http.StatusGone, "upload expired")
+	ErrChecksumMismatch = keratin.NewHTTPError(StatusChecksumMismatch, "checksum mismatch")
+)
+
+// Handler serves the resumable uploads, see [Handler.Register].
+type Handler struct {
+	cfg Config
+
+	mu   sync.Mutex
+	busy map[string]struct{}
+}
+
+func New(cfg Config) *Handler {
+	cfg.SetDefaults()
+
+	return &Handler{cfg: cfg, busy: make(map[string]struct{})}
+}
+
+// Register registers the routes of the uploads on the group, e.g. router.Group("/files"):
+// OPTIONS and POST (creation) on the group path, HEAD, PATCH and DELETE on the upload paths "/files/{id}".
+func (h *Handler) Register(group *keratin.RouterGroup) {
+	for _, path := range []string{"", "/{$}"} {
+		group.OPTIONS(path, h.options)
+		group.POST(path, h.create)
+	}
+	group.HEAD("/{id}", h.head)
+	group.PATCH("/{id}", h.patch)
+	group.DELETE("/{id}", h.terminate)
+}
+
+// Cleanup deletes the expired uploads and returns their number. It is meant to be run periodically.
+func (h *Handler) Cleanup(ctx context.Context) (int, error) {
+	return h.cfg.Store.DeleteExpired(ctx, time.Now())
+}
+
+func (h *Handler) options(w http.ResponseWriter, _ *http.Request) error {
+	header := w.Header()
+	header.Set(HeaderTusResumable, Version)
+	header.Set(HeaderTusVersion, Version)
+	header.Set(HeaderTusExtension, extensions)
+	header.Set(HeaderTusChecksumAlgorithm, checksumAlgorithms)
+	if h.cfg.MaxSize > 0 {
+		header.Set(HeaderTusMaxSize, strconv.FormatInt(h.cfg.MaxSize, 10))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) error {
+	if err := checkVersion(w, r); err != nil {
+		return err
+	}
+
+	size, err := strconv.ParseInt(r.Header.Get(HeaderUploadLength), 10, 64)
+	if err != nil || size < 0 {
+		return keratin.NewHTTPError(http.StatusBadRequest, "invalid Upload-Length header")
+	}
+	if h.cfg.MaxSize > 0 && size > h.cfg.MaxSize {
+		return keratin.ErrRequestEntityTooLarge
+	}
+
+	metadata, err := parseMetadata(r.Header.Get(HeaderUploadMetadata))
+	if err != nil {
+		return keratin.ErrBadRequest.Wrap(err)
+	}
+
+	now := time.Now()
+	info := Info{
+		ID:        uuid.NewString(),
+		Size:      size,
+		Metadata:  metadata,
+		CreatedAt: now,
+		ExpiresAt: now.Add(h.cfg.Expiration),
+	}
+	if err = h.cfg.Store.Create(r.Context(), info); err != nil {
+		return err
+	}
+	if info.Done() {
+		if err = h.complete(r.Context(), info); err != nil {
+			return err
+		}
+	}
+
+	w.Header().Set(keratin.HeaderLocation, strings.TrimSuffix(r.URL.Path, "/")+"/"+info.ID)
+	w.Header().Set(HeaderUploadExpires, info.ExpiresAt.UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
+
+func (h *Handler) head(w http.ResponseWriter, r *http.Request) error {
+	if err := checkVersion(w, r); err != nil {
+		return err
+	}
+
+	info, err := h.get(r)
+	if err != nil {
+		return err
+	}
+
+	header := w.Header()
+	header.Set(keratin.HeaderCacheControl, "no-store")
+	header.Set(HeaderUploadLength, strconv.FormatInt(info.Size, 10))
+	header.Set(HeaderUploadOffset, strconv.FormatInt(info.Offset, 10))
+	if !info.ExpiresAt.IsZero() {
+		header.Set(HeaderUploadExpires, info.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+	if len(info.Metadata) > 0 {
+		header.Set(HeaderUploadMetadata, formatMetadata(info.Metadata))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (h *Handler) patch(w http.ResponseWriter, r *http.Request) error {
+	if err := checkVersion(w, r); err != nil {
+		return err
+	}
+	if r.Header.Get(keratin.HeaderContentType) != MIMEApplicationOffsetOctets {
+		return keratin.ErrUnsupportedMediaType
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get(HeaderUploadOffset), 10, 64)
+	if err != nil || offset < 0 {
+		return keratin.NewHTTPError(http.StatusBadRequest, "invalid Upload-Offset header")
+	}
+
+	id := r.PathValue("id")
+	if !h.lock(id) {
+		return ErrUploadLocked
+	}
+	defer h.unlock(id)
+
+	info, err := h.get(r)
+	if err != nil {
+		return err
+	}
+	if offset != info.Offset {
+		return ErrOffsetMismatch
+	}
+
+	remaining := info.Size - info.Offset
+	if r.ContentLength > remaining {
+		return keratin.ErrRequestEntityTooLarge
+	}
+
+	body, err := h.chunk(w, r, remaining)
+	if err != nil {
+		return err
+	}
+
+	n, err := h.cfg.Store.Append(r.Context(), id, body)
+	info.Offset += n
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return keratin.ErrRequestEntityTooLarge.Wrap(err)
+		}
+		return err
+	}
+
+	if info.Done() {
+		if err = h.complete(r.Context(), info); err != nil {
+			return err
+		}
+	}
+
+	w.Header().Set(HeaderUploadOffset, strconv.FormatInt(info.Offset, 10))
+	w.Header().Set(HeaderUploadExpires, info.ExpiresAt.UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (h *Handler) terminate(w http.ResponseWriter, r *http.Request) error {
+	if err := checkVersion(w, r); err != nil {
+		return err
+	}
+
+	id := r.PathValue("id")
+	if !h.lock(id) {
+		return ErrUploadLocked
+	}
+	defer h.unlock(id)
+
+	if _, err := h.get(r); err != nil {
+		return err
+	}
+	if err := h.cfg.Store.Delete(r.Context(), id); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// get returns the not expired upload of the request path.
+func (h *Handler) get(r *http.Request) (Info, error) {
+	id := r.PathValue("id")
+	if uuid.Validate(id) != nil {
+		return Info{}, keratin.ErrNotFound
+	}
+
+	info, err := h.cfg.Store.Get(r.Context(), id)
+	if errors.Is(err, ErrNotFound) {
+		return Info{}, keratin.ErrNotFound.Wrap(err)
+	}
+	if err != nil {
+		return Info{}, err
+	}
+	if info.Expired(time.Now()) {
+		return Info{}, ErrUploadExpired
+	}
+	return info, nil
+}
+
+// chunk returns the request body limited to the remaining bytes of the upload. The chunk with
+// the Upload-Checksum header is read in memory and returned only if its checksum matches.
+func (h *Handler) chunk(w http.ResponseWriter, r *http.Request, remaining int64) (io.Reader, error) {
+	value := r.Header.Get(HeaderUploadChecksum)
+	if value == "" {
+		return http.MaxBytesReader(w, r.Body, remaining), nil
+	}
+
+	algorithm, encoded, _ := strings.Cut(value, " ")
+	newHash := checksumHash(algorithm)
+	if newHash == nil {
+		return nil, keratin.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unsupported checksum algorithm %q", algorithm))
+	}
+	expected, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, keratin.NewHTTPError(http.StatusBadRequest, "invalid Upload-Checksum header")
+	}
+
+	limit := min(remaining, h.cfg.MaxChecksumChunkSize)
+	data, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, keratin.ErrRequestEntityTooLarge
+	}
+
+	hh := newHash()
+	_, _ = hh.Write(data)
+	if subtle.ConstantTimeCompare(hh.Sum(nil), expected) != 1 {
+		return nil, ErrChecksumMismatch
+	}
+	return bytes.NewReader(data), nil
+}
+
+func (h *Handler) complete(ctx context.Context, info Info) error {
+	if h.cfg.OnComplete == nil {
+		return nil
+	}
+	return h.cfg.OnComplete(ctx, info)
+}
+
+// lock marks the upload as being written, it reports false if it already is.
+func (h *Handler) lock(id string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.busy[id]; ok {
+		return false
+	}
+	h.busy[id] = struct{}{}
+	return true
+}
+
+func (h *Handler) unlock(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.busy, id)
+}
+
+func checkVersion(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set(HeaderTusResumable, Version)
+
+	if r.Header.Get(HeaderTusResumable) != Version {
+		w.Header().Set(HeaderTusVersion, Version)
+		return ErrVersionMismatch
+	}
+	return nil
+}
+
+func checksumHash(algorithm string) func() hash.Hash {
+	switch algorithm {
+	case "sha1":
+		return sha1.New
+	case "sha256":
+		return sha256.New
+	case "sha512":
+		return sha512.New
+	}
+	return nil
+}
+
+// parseMetadata parses the Upload-Metadata header: comma separated keys, each with an optional base64 value.
+func parseMetadata(header string) (map[string]string, error) {
+	if strings.TrimSpace(header) == "" {
+		return nil, nil
+	}
+
+	metadata := make(map[string]string)
+	for pair := range strings.SplitSeq(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("upload: empty metadata key")
+		}
+		if _, ok := metadata[key]; ok {
+			return nil, fmt.Errorf("upload: duplicate metadata key %q", key)
+		}
+
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("upload: metadata %q: %w", key, err)
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+func formatMetadata(metadata map[string]string) string {
+	pairs := make([]string, 0, len(metadata))
+	for _, key := range slices.Sorted(maps.Keys(metadata)) {
+		value := metadata[key]
+		if value == "" {
+			pairs = append(pairs, key)
+			continue
+		}
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(value)))
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,251 @@
+package upload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+)
+
+func newTestServer(t *testing.T, cfg Config) (*Handler, http.Handler) {
+	t.Helper()
+
+	h := New(cfg)
+	router := keratin.NewRouter()
+	h.Register(router.Group("/files"))
+	return h, router.Build()
+}
+
+func tusRequest(method, target, body string, headers ...string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(HeaderTusResumable, Version)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	return req
+}
+
+func serve(handler http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func createUpload(t *testing.T, handler http.Handler, size int, headers ...string) string {
+	t.Helper()
+
+	rec := serve(handler, tusRequest(http.MethodPost, "/files", "", append([]string{HeaderUploadLength, strconv.Itoa(size)}, headers...)...))
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	location := rec.Header().Get(keratin.HeaderLocation)
+	require.True(t, strings.HasPrefix(location, "/files/"))
+	return location
+}
+
+func patchUpload(handler http.Handler, location string, offset int, body string, headers ...string) *httptest.ResponseRecorder {
+	headers = append([]string{
+		keratin.HeaderContentType, MIMEApplicationOffsetOctets,
+		HeaderUploadOffset, strconv.Itoa(offset),
+	}, headers...)
+	return serve(handler, tusRequest(http.MethodPatch, location, body, headers...))
+}
+
+func TestHandler_Options(t *testing.T) {
+	_, handler := newTestServer(t, Config{MaxSize: 100})
+
+	rec := serve(handler, httptest.NewRequest(http.MethodOptions, "/files", nil))
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, Version, rec.Header().Get(HeaderTusVersion))
+	assert.Equal(t, "creation,expiration,checksum,termination", rec.Header().Get(HeaderTusExtension))
+	assert.Equal(t, "sha1,sha256,sha512", rec.Header().Get(HeaderTusChecksumAlgorithm))
+	assert.Equal(t, "100", rec.Header().Get(HeaderTusMaxSize))
+}
+
+func TestHandler_Resumable(t *testing.T) {
+	var completed []Info
+	_, handler := newTestServer(t, Config{
+		OnComplete: func(_ context.Context, info Info) error {
+			completed = append(completed, info)
+			return nil
+		},
+	})
+
+	location := createUpload(t, handler, 11, HeaderUploadMetadata, "filename "+base64.StdEncoding.EncodeToString([]byte("a.txt"))+",draft")
+
+	rec := serve(handler, tusRequest(http.MethodHead, location, ""))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "0", rec.Header().Get(HeaderUploadOffset))
+	assert.Equal(t, "11", rec.Header().Get(HeaderUploadLength))
+	assert.Equal(t, "no-store", rec.Header().Get(keratin.HeaderCacheControl))
+	assert.Equal(t, "draft,filename "+base64.StdEncoding.EncodeToString([]byte("a.txt")), rec.Header().Get(HeaderUploadMetadata))
+	assert.NotEmpty(t, rec.Header().Get(HeaderUploadExpires))
+
+	rec = patchUpload(handler, location, 0, "hello ")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "6", rec.Header().Get(HeaderUploadOffset))
+	assert.Empty(t, completed)
+
+	rec = patchUpload(handler, location, 0, "again")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = serve(handler, tusRequest(http.MethodHead, location, ""))
+	assert.Equal(t, "6", rec.Header().Get(HeaderUploadOffset))
+
+	rec = patchUpload(handler, location, 6, "world")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "11", rec.Header().Get(HeaderUploadOffset))
+
+	require.Len(t, completed, 1)
+	assert.Equal(t, int64(11), completed[0].Offset)
+	assert.Equal(t, map[string]string{"filename": "a.txt", "draft": ""}, completed[0].Metadata)
+}
+
+func TestHandler_Create(t *testing.T) {
+	_, handler := newTestServer(t, Config{MaxSize: 10})
+
+	tests := []struct {
+		name    string
+		headers []string
+		want    int
+	}{
+		{name: "missing length", want: http.StatusBadRequest},
+		{name: "negative length", headers: []string{HeaderUploadLength, "-1"}, want: http.StatusBadRequest},
+		{name: "too large", headers: []string{HeaderUploadLength, "11"}, want: http.StatusRequestEntityTooLarge},
+		{name: "invalid metadata", headers: []string{HeaderUploadLength, "1", HeaderUploadMetadata, "name !!"}, want: http.StatusBadRequest},
+		{name: "duplicate metadata", headers: []string{HeaderUploadLength, "1", HeaderUploadMetadata, "a,a"}, want: http.StatusBadRequest},
+		{name: "version mismatch", headers: []string{HeaderUploadLength, "1", HeaderTusResumable, "0.2.2"}, want: http.StatusPreconditionFailed},
+		{name: "created", headers: []string{HeaderUploadLength, "10"}, want: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(handler, tusRequest(http.MethodPost, "/files/", "", tt.headers...))
+
+			assert.Equal(t, tt.want, rec.Code)
+			assert.Equal(t, Version, rec.Header().Get(HeaderTusResumable))
+		})
+	}
+}
+
+func TestHandler_Patch(t *testing.T) {
+	_, handler := newTestServer(t, Config{MaxChecksumChunkSize: 8})
+
+	sum := sha256.Sum256([]byte("abc"))
+	checksum := "sha256 " + base64.StdEncoding.EncodeToString(sum[:])
+
+	t.Run("not found", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, patchUpload(handler, "/files/x", 0, "a").Code)
+		assert.Equal(t, http.StatusNotFound, patchUpload(handler, "/files/"+"00000000-0000-0000-0000-000000000000", 0, "a").Code)
+	})
+
+	t.Run("content type", func(t *testing.T) {
+		location := createUpload(t, handler, 3)
+		rec := serve(handler, tusRequest(http.MethodPatch, location, "abc", HeaderUploadOffset, "0"))
+		assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	})
+
+	t.Run("missing offset", func(t *testing.T) {
+		location := createUpload(t, handler, 3)
+		rec := serve(handler, tusRequest(http.MethodPatch, location, "abc", keratin.HeaderContentType, MIMEApplicationOffsetOctets))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		location := createUpload(t, handler, 3)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, patchUpload(handler, location, 0, "abcd").Code)
+
+		req := tusRequest(http.MethodPatch, location, "", keratin.HeaderContentType, MIMEApplicationOffsetOctets, HeaderUploadOffset, "0")
+		req.Body = io.NopCloser(strings.NewReader("abcd"))
+		req.ContentLength = -1
+		assert.Equal(t, http.StatusRequestEntityTooLarge, serve(handler, req).Code)
+	})
+
+	t.Run("checksum", func(t *testing.T) {
+		location := createUpload(t, handler, 3)
+
+		rec := patchUpload(handler, location, 0, "abd", HeaderUploadChecksum, checksum)
+		assert.Equal(t, StatusChecksumMismatch, rec.Code)
+		rec = serve(handler, tusRequest(http.MethodHead, location, ""))
+		assert.Equal(t, "0", rec.Header().Get(HeaderUploadOffset))
+
+		rec = patchUpload(handler, location, 0, "abc", HeaderUploadChecksum, "md5 AAAA")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = patchUpload(handler, location, 0, "abc", HeaderUploadChecksum, checksum)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "3", rec.Header().Get(HeaderUploadOffset))
+	})
+
+	t.Run("checksum chunk too large", func(t *testing.T) {
+		location := createUpload(t, handler, 20)
+		rec := patchUpload(handler, location, 0, strings.Repeat("a", 9), HeaderUploadChecksum, checksum)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+}
+
+func TestHandler_Locked(t *testing.T) {
+	h, handler := newTestServer(t, Config{})
+	location := createUpload(t, handler, 3)
+
+	id := strings.TrimPrefix(location, "/files/")
+	require.True(t, h.lock(id))
+
+	assert.Equal(t, http.StatusLocked, patchUpload(handler, location, 0, "abc").Code)
+	assert.Equal(t, http.StatusLocked, serve(handler, tusRequest(http.MethodDelete, location, "")).Code)
+
+	h.unlock(id)
+	assert.Equal(t, http.StatusNoContent, patchUpload(handler, location, 0, "abc").Code)
+}
+
+func TestHandler_Terminate(t *testing.T) {
+	_, handler := newTestServer(t, Config{})
+	location := createUpload(t, handler, 3)
+
+	assert.Equal(t, http.StatusNoContent, serve(handler, tusRequest(http.MethodDelete, location, "")).Code)
+	assert.Equal(t, http.StatusNotFound, serve(handler, tusRequest(http.MethodHead, location, "")).Code)
+	assert.Equal(t, http.StatusNotFound, serve(handler, tusRequest(http.MethodDelete, location, "")).Code)
+}
+
+func TestHandler_Expiration(t *testing.T) {
+	store := NewMemoryStore()
+	h, handler := newTestServer(t, Config{Store: store})
+
+	const id = "6f1b5d1e-7c1a-4f7e-9a43-6a0c1d6f2b11"
+	require.NoError(t, store.Create(context.Background(), Info{ID: id, Size: 3, ExpiresAt: time.Now().Add(-time.Second)}))
+	location := createUpload(t, handler, 3)
+
+	assert.Equal(t, http.StatusGone, patchUpload(handler, "/files/"+id, 0, "abc").Code)
+	assert.Equal(t, http.StatusGone, serve(handler, tusRequest(http.MethodHead, "/files/"+id, "")).Code)
+
+	n, err := h.Cleanup(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	assert.Equal(t, http.StatusNotFound, serve(handler, tusRequest(http.MethodHead, "/files/"+id, "")).Code)
+	assert.Equal(t, http.StatusOK, serve(handler, tusRequest(http.MethodHead, location, "")).Code)
+}
+
+func TestHandler_EmptyUpload(t *testing.T) {
+	completed := 0
+	_, handler := newTestServer(t, Config{
+		OnComplete: func(context.Context, Info) error {
+			completed++
+			return nil
+		},
+	})
+
+	createUpload(t, handler, 0)
+	assert.Equal(t, 1, completed)
+}
@@ -0,0 +1,298 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by the [Store] for an unknown upload.
+var ErrNotFound = errors.New("upload: not found")
+
+// Info is the state of an upload.
+type Info struct {
+	ID string `json:"id"`
+
+	// Size is the total size of the upload in bytes.
+	Size int64 `json:"size"`
+
+	// Offset is the number of the received bytes.
+	Offset int64 `json:"offset"`
+
+	// Metadata are the key-value pairs sent by the client with the upload creation, e.g. the file name.
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt,omitzero"`
+}
+
+// Done reports whether all the bytes of the upload are received.
+func (i Info) Done() bool {
+	return i.Offset >= i.Size
+}
+
+// Expired reports whether the upload is expired at now.
+func (i Info) Expired(now time.Time) bool {
+	return !i.ExpiresAt.IsZero() && !now.Before(i.ExpiresAt)
+}
+
+// Store stores the uploads. The [Handler] serializes the writes of an upload,
+// the implementations must only be safe for the concurrent use of different uploads.
+type Store interface {
+	// Create stores a new empty upload.
+	Create(ctx context.Context, info Info) error
+
+	// Get returns the upload, [ErrNotFound] for an unknown upload.
+	Get(ctx context.Context, id string) (Info, error)
+
+	// Append writes the data read from r at the end of the upload and advances its offset.
+	// It returns the number of the written bytes, also when reading r fails.
+	Append(ctx context.Context, id string, r io.Reader) (int64, error)
+
+	// Open returns the data of the upload.
+	Open(ctx context.Context, id string) (io.ReadCloser, error)
+
+	// Delete removes the upload.
+	Delete(ctx context.Context, id string) error
+
+	// DeleteExpired removes the uploads expired at now and returns their number.
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+var (
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*FileStore)(nil)
+)
+
+type memoryUpload struct {
+	info Info
+	data []byte
+}
+
+// MemoryStore is an in-memory [Store] for the tests and the small uploads of single instance deployments.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	uploads map[string]*memoryUpload
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{uploads: make(map[string]*memoryUpload)}
+}
+
+func (s *MemoryStore) Create(_ context.Context, info Info) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info.Metadata = maps.Clone(info.Metadata)
+	s.uploads[info.ID] = &memoryUpload{info: info}
+	return nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (Info, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.uploads[id]
+	if !ok {
+		return Info{}, ErrNotFound
+	}
+	info := u.info
+	info.Metadata = maps.Clone(info.Metadata)
+	return info, nil
+}
+
+func (s *MemoryStore) Append(_ context.Context, id string, r io.Reader) (int64, error) {
+	buf := new(bytes.Buffer)
+	n, err := io.Copy(buf, r)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.uploads[id]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	u.data = append(u.data, buf.Bytes()...)
+	u.info.Offset += n
+	return n, err
+}
+
+func (s *MemoryStore) Open(_ context.Context, id string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.uploads[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(bytes.Clone(u.data))), nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.uploads, id)
+	return nil
+}
+
+func (s *MemoryStore) DeleteExpired(_ context.Context, now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for id, u := range s.uploads {
+		if u.info.Expired(now) {
+			delete(s.uploads, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// FileStore is a [Store] keeping every upload in the directory as two files:
+// the data ("<id>.bin") and the info ("<id>.json").
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a FileStore in dir, creating it if missing.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("upload: create store directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) Create(_ context.Context, info Info) error {
+	f, err := os.OpenFile(s.dataPath(info.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("upload: create data file: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return s.writeInfo(info)
+}
+
+func (s *FileStore) Get(_ context.Context, id string) (Info, error) {
+	return s.readInfo(s.infoPath(id))
+}
+
+func (s *FileStore) Append(_ context.Context, id string, r io.Reader) (int64, error) {
+	info, err := s.readInfo(s.infoPath(id))
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := os.OpenFile(s.dataPath(id), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return 0, fmt.Errorf("upload: open data file: %w", err)
+	}
+
+	n, copyErr := io.Copy(f, r)
+	if err = f.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+
+	info.Offset += n
+	if err = s.writeInfo(info); err != nil {
+		return n, err
+	}
+	return n, copyErr
+}
+
+func (s *FileStore) Open(_ context.Context, id string) (io.ReadCloser, error) {
+	f, err := os.Open(s.dataPath(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *FileStore) Delete(_ context.Context, id string) error {
+	return errors.Join(
+		ignoreNotExist(os.Remove(s.dataPath(id))),
+		ignoreNotExist(os.Remove(s.infoPath(id))),
+	)
+}
+
+func (s *FileStore) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		info, err := s.readInfo(filepath.Join(s.dir, entry.Name()))
+		if err != nil || !info.Expired(now) {
+			continue
+		}
+		if err = s.Delete(ctx, id); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (s *FileStore) dataPath(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id)+".bin")
+}
+
+func (s *FileStore) infoPath(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id)+".json")
+}
+
+func (s *FileStore) readInfo(path string) (Info, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Info{}, ErrNotFound
+	}
+	if err != nil {
+		return Info{}, err
+	}
+
+	var info Info
+	if err = json.Unmarshal(raw, &info); err != nil {
+		return Info{}, fmt.Errorf("upload: decode info: %w", err)
+	}
+	return info, nil
+}
+
+// writeInfo replaces the info file atomically, so a crash never leaves it truncated.
+func (s *FileStore) writeInfo(info Info) error {
+	raw, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
+	tmp := s.infoPath(info.ID) + ".tmp"
+	if err = os.WriteFile(tmp, raw, 0o640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.infoPath(info.ID))
+}
+
+func ignoreNotExist(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+package upload
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStores(t *testing.T) {
+	fileStore, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	stores := map[string]Store{
+		"memory": NewMemoryStore(),
+		"file":   fileStore,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Now()
+
+			_, err := store.Get(ctx, "missing")
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			require.NoError(t, store.Create(ctx, Info{
+				ID:        "a",
+				Size:      11,
+				Metadata:  map[string]string{"filename": "a.txt"},
+				CreatedAt: now,
+				ExpiresAt: now.Add(time.Hour),
+			}))
+			require.NoError(t, store.Create(ctx, Info{ID: "b", Size: 1, CreatedAt: now, ExpiresAt: now.Add(-time.Second)}))
+
+			n, err := store.Append(ctx, "a", strings.NewReader("hello "))
+			require.NoError(t, err)
+			assert.Equal(t, int64(6), n)
+			n, err = store.Append(ctx, "a", strings.NewReader("world"))
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), n)
+
+			info, err := store.Get(ctx, "a")
+			require.NoError(t, err)
+			assert.Equal(t, int64(11), info.Offset)
+			assert.True(t, info.Done())
+			assert.Equal(t, "a.txt", info.Metadata["filename"])
+
+			rc, err := store.Open(ctx, "a")
+			require.NoError(t, err)
+			data, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			require.NoError(t, rc.Close())
+			assert.Equal(t, "hello world", string(data))
+
+			deleted, err := store.DeleteExpired(ctx, now)
+			require.NoError(t, err)
+			assert.Equal(t, 1, deleted)
+			_, err = store.Get(ctx, "b")
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			require.NoError(t, store.Delete(ctx, "a"))
+			_, err = store.Get(ctx, "a")
+			assert.ErrorIs(t, err, ErrNotFound)
+			_, err = store.Open(ctx, "a")
+			assert.ErrorIs(t, err, ErrNotFound)
+			require.NoError(t, store.Delete(ctx, "a"))
+		})
+	}
+}
+
+func TestInfo_Expired(t *testing.T) {
+	now := time.Now()
+
+	assert.False(t, Info{}.Expired(now))
+	assert.False(t, Info{ExpiresAt: now.Add(time.Second)}.Expired(now))
+	assert.True(t, Info{ExpiresAt: now}.Expired(now))
+}
@@ -0,0 +1,179 @@
+package keratin
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var uploadTestPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+
+func newUploadRequest(t *testing.T, files map[string][][]byte) *http.Request {
+	t.Helper()
+
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	for name, contents := range files {
+		for i, content := range contents {
+			fw, err := mw.CreateFormFile(name, name+string(rune('a'+i)))
+			require.NoError(t, err)
+			_, err = fw.Write(content)
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, mw.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/", body)
+	r.Header.Set(HeaderContentType, mw.FormDataContentType())
+	return r
+}
+
+func TestFormFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string][][]byte
+		limits   []UploadLimits
+		wantCode int
+	}{
+		{
+			name:  "no limits",
+			files: map[string][][]byte{"file": {[]byte("hello")}},
+		},
+		{
+			name:     "missing file",
+			files:    map[string][][]byte{"other": {[]byte("hello")}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "too large",
+			files:    map[string][][]byte{"file": {[]byte("hello")}},
+			limits:   []UploadLimits{{MaxFileSize: 4}},
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:   "allowed wildcard type",
+			files:  map[string][][]byte{"file": {uploadTestPNG}},
+			limits: []UploadLimits{{AllowedTypes: []string{"image/*"}}},
+		},
+		{
+			name:     "not allowed type",
+			files:    map[string][][]byte{"file": {[]byte("hello")}},
+			limits:   []UploadLimits{{AllowedTypes: []string{"image/png"}}},
+			wantCode: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fh, err := FormFile(newUploadRequest(t, tt.files), "file", tt.limits...)
+
+			if tt.wantCode != 0 {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantCode, HTTPErrorStatusCode(err))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "filea", fh.Filename)
+		})
+	}
+}
+
+func TestFormFile_NotMultipart(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+	r.Header.Set(HeaderContentType, MIMEApplicationJSON)
+
+	_, err := FormFile(r, "file")
+	assert.Equal(t, http.StatusUnsupportedMediaType, HTTPErrorStatusCode(err))
+}
+
+func TestFormFile_BodyTooLarge(t *testing.T) {
+	r := newUploadRequest(t, map[string][][]byte{"file": {bytes.Repeat([]byte("a"), 1024)}})
+	r.Body = http.MaxBytesReader(httptest.NewRecorder(), r.Body, 100)
+
+	_, err := FormFile(r, "file")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, HTTPErrorStatusCode(err))
+}
+
+// countingReader counts the bytes read from the request body.
+type countingReader struct {
+	io.ReadCloser
+	n int
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.ReadCloser.Read(b)
+	c.n += n
+	return n, err
+}
+
+func TestFormFile_RejectsLargePartEarly(t *testing.T) {
+	r := newUploadRequest(t, map[string][][]byte{"file": {bytes.Repeat([]byte("a"), 1<<20)}})
+	body := &countingReader{ReadCloser: r.Body}
+	r.Body = body
+
+	_, err := FormFile(r, "file", UploadLimits{MaxFileSize: 1024})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, HTTPErrorStatusCode(err))
+	assert.Less(t, body.n, 64*1024)
+}
+
+func TestFormFiles_PartLimitSplitReads(t *testing.T) {
+	files := map[string][][]byte{"file": {bytes.Repeat([]byte("a"), 100), bytes.Repeat([]byte("b"), 100)}}
+
+	r := newUploadRequest(t, files)
+	r.Body = io.NopCloser(iotest.OneByteReader(r.Body))
+	got, err := FormFiles(r, "file", UploadLimits{MaxFileSize: 100})
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	files["file"] = append(files["file"], bytes.Repeat([]byte("c"), 2*int(UploadPartHeaderSize)))
+	r = newUploadRequest(t, files)
+	r.Body = io.NopCloser(iotest.OneByteReader(r.Body))
+	_, err = FormFiles(r, "file", UploadLimits{MaxFileSize: 100})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, HTTPErrorStatusCode(err))
+}
+
+func TestFormFiles(t *testing.T) {
+	files := map[string][][]byte{"file": {[]byte("one"), []byte("two")}}
+
+	got, err := FormFiles(newUploadRequest(t, files), "file")
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	_, err = FormFiles(newUploadRequest(t, files), "file", UploadLimits{MaxFiles: 1})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, HTTPErrorStatusCode(err))
+
+	_, err = FormFiles(newUploadRequest(t, files), "file", UploadLimits{MaxFileSize: 2})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, HTTPErrorStatusCode(err))
+}
+
+func TestDetectFileContentType(t *testing.T) {
+	fh, err := FormFile(newUploadRequest(t, map[string][][]byte{"file": {uploadTestPNG}}), "file")
+	require.NoError(t, err)
+
+	contentType, err := DetectFileContentType(fh)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+}
+
+func TestSaveUploadedFile(t *testing.T) {
+	fh, err := FormFile(newUploadRequest(t, map[string][][]byte{"file": {[]byte("hello")}}), "file")
+	require.NoError(t, err)
+
+	dst := filepath.Join(t.TempDir(), "nested", "file.txt")
+	require.NoError(t, SaveUploadedFile(fh, dst))
+
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+}
@@ -1,6 +1,7 @@
 package keratin
 
 import (
+	"mime"
 	"net/http"
 	"strings"
 
@@ -56,9 +57,46 @@ func ParseAcceptLanguage(acceptLanguageHeader string) []string {
 	return languages
 }
 
-// NegotiateFormat returns an acceptable Accept format.
+// NegotiateFormat returns the best offered format for the Accept header value,
+// following the same rules as [NegotiateContentType].
 func NegotiateFormat(acceptHeader string, offered ...string) string {
-	accepted := internal.ParseAcceptHeader(acceptHeader)
+	return internal.NegotiateContentType(internal.ParseMediaRanges(acceptHeader), offered...)
+}
+
+// NegotiateContentType returns the best offered content type for the request Accept header.
+//
+// Accept media ranges are matched case-insensitively, honoring quality values and wildcards
+// ("type/*", "*/*"). When the request has no Accept header the first offer is returned,
+// when none of the offers is acceptable an empty string is returned.
+func NegotiateContentType(r *http.Request, offers ...string) string {
+	return internal.NegotiateContentType(internal.ParseMediaRanges(r.Header.Get(HeaderAccept)), offers...)
+}
 
-	return internal.NegotiateFormat(accepted, offered...)
+// MatchMediaType reports whether the media type of contentType (its parameters are ignored)
+// matches one of the patterns, case-insensitively. The patterns are a media type, "*/*",
+// a subtype wildcard, e.g. "image/*", or a structured syntax suffix, e.g. "+json".
+// An empty or malformed contentType matches nothing.
+func MatchMediaType(contentType string, patterns ...string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+
+		switch {
+		case pattern == "*/*" || pattern == mediaType:
+			return true
+		case strings.HasPrefix(pattern, "+"):
+			if strings.HasSuffix(mediaType, pattern) {
+				return true
+			}
+		case strings.HasSuffix(pattern, "/*"):
+			if strings.HasPrefix(mediaType, pattern[:len(pattern)-1]) {
+				return true
+			}
+		}
+	}
+	return false
 }
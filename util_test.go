@@ -3,6 +3,7 @@ package keratin
 import (
 	"crypto/tls"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -424,11 +425,28 @@ func TestNegotiateFormat(t *testing.T) {
 			want:         MIMEApplicationJSON,
 		},
 		{
-			name:         "quality values are ignored",
-			acceptHeader: "application/json;q=0.9, text/html;q=0.8",
+			name:         "quality values are honored",
+			acceptHeader: "text/html;q=0.8, application/json;q=0.9",
 			offered:      []string{MIMETextHTML, MIMEApplicationJSON},
 			want:         MIMEApplicationJSON,
 		},
+		{
+			name:         "zero quality is not acceptable",
+			acceptHeader: "application/json;q=0, */*",
+			offered:      []string{MIMEApplicationJSON, MIMETextHTML},
+			want:         MIMETextHTML,
+		},
+		{
+			name:         "case insensitive",
+			acceptHeader: "Application/JSON",
+			offered:      []string{MIMETextHTML, MIMEApplicationJSON},
+			want:         MIMEApplicationJSON,
+		},
+		{
+			name:         "no offers",
+			acceptHeader: "application/json",
+			want:         "",
+		},
 		{
 			name:         "no match returns empty string",
 			acceptHeader: "text/xml",
@@ -486,3 +504,39 @@ func TestNegotiateFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestNegotiateContentType(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+	}{
+		{name: "missing accept", accept: "", want: MIMETextPlain},
+		{name: "json", accept: "application/json", want: MIMEApplicationJSON},
+		{name: "browser accept", accept: "text/html,application/xhtml+xml,*/*;q=0.8", want: MIMETextPlain},
+		{name: "json preferred", accept: "text/*;q=0.5, application/*", want: MIMEApplicationJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				r.Header.Set(HeaderAccept, tt.accept)
+			}
+
+			assert.Equal(t, tt.want, NegotiateContentType(r, MIMETextPlain, MIMEApplicationJSON))
+		})
+	}
+}
+
+func TestMatchMediaType(t *testing.T) {
+	assert.True(t, MatchMediaType("text/plain", "*/*"))
+	assert.True(t, MatchMediaType("image/png", "image/*"))
+	assert.True(t, MatchMediaType("image/png", "IMAGE/PNG"))
+	assert.True(t, MatchMediaType("application/json; charset=utf-8", "text/plain", MIMEApplicationJSON))
+	assert.True(t, MatchMediaType("application/problem+json", "+json"))
+	assert.False(t, MatchMediaType("imagex/png", "image/*"))
+	assert.False(t, MatchMediaType("image/jpeg", "image/png"))
+	assert.False(t, MatchMediaType("", "*/*"))
+	assert.False(t, MatchMediaType("text/plain"))
+}
@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"net/http"
+	"time"
+)
+
+type Config struct {
+	// Client sends the deliveries. The endpoint URLs are not restricted and the default client
+	// follows redirects, so the caller owns the SSRF protection when the endpoints are registered
+	// by the users, e.g. with a client whose dialer rejects the private addresses and which
+	// does not follow redirects.
+	//
+	// Default: &http.Client{Timeout: Timeout}
+	Client *http.Client `json:"-" yaml:"-"`
+
+	// Store records the delivery attempts.
+	//
+	// Default: NewMemoryStore(), which keeps the recent deliveries only
+	Store Store `json:"-" yaml:"-"`
+
+	// Timeout is the timeout of a single delivery attempt of the default client.
+	//
+	// Default: 10s
+	Timeout time.Duration `env:"TIMEOUT" json:"timeout,omitempty,format:units" yaml:"timeout,omitempty"`
+
+	// MaxAttempts is the maximum number of delivery attempts, including the first one.
+	//
+	// Default: 5
+	MaxAttempts int `env:"MAX_ATTEMPTS" json:"maxAttempts,omitempty" yaml:"maxAttempts,omitempty"`
+
+	// InitialBackoff is the delay before the first retry, doubled for every next one.
+	//
+	// Default: 1s
+	InitialBackoff time.Duration `env:"INITIAL_BACKOFF" json:"initialBackoff,omitempty,format:units" yaml:"initialBackoff,omitempty"`
+
+	// MaxBackoff caps the delay between the retries.
+	//
+	// Default: 1m
+	MaxBackoff time.Duration `env:"MAX_BACKOFF" json:"maxBackoff,omitempty,format:units" yaml:"maxBackoff,omitempty"`
+
+	// UserAgent is the User-Agent header of the deliveries.
+	//
+	// Default: "keratin-webhook"
+	UserAgent string `env:"USER_AGENT" json:"userAgent,omitempty" yaml:"userAgent,omitempty"`
+}
+
+func (c *Config) SetDefaults() {
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.Client == nil {
+		c.Client = &http.Client{Timeout: c.Timeout}
+	}
+	if c.Store == nil {
+		c.Store = NewMemoryStore()
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 5
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = time.Minute
+	}
+	if c.UserAgent == "" {
+		c.UserAgent = "keratin-webhook"
+	}
+}
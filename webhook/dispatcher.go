@@ -0,0 +1,300 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gowool/keratin"
+	"github.com/gowool/keratin/internal"
+	"github.com/gowool/keratin/middleware"
+)
+
+const (
+	HeaderWebhookEvent    = "X-Webhook-Event"
+	HeaderWebhookDelivery = "X-Webhook-Delivery"
+)
+
+// Event is the payload of the deliveries.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// NewEvent creates an event of the given type with the JSON encoded data.
+func NewEvent(eventType string, data any) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("webhook: encode event data: %w", err)
+	}
+
+	return Event{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      raw,
+	}, nil
+}
+
+// Endpoint is a registered webhook receiver.
+type Endpoint struct {
+	ID  string `json:"id" yaml:"id"`
+	URL string `json:"url" yaml:"url"`
+
+	// KeyID is sent in the X-Signature-Key-Id header, so the receiver can select the secret.
+	KeyID string `json:"keyId,omitempty" yaml:"keyId,omitempty"`
+	// Secret signs the deliveries, see [middleware.SignRequest].
+	Secret string `json:"-" yaml:"-"`
+
+	// Events are the delivered event types, all of them if empty.
+	Events []string `json:"events,omitempty" yaml:"events,omitempty"`
+
+	// Header is added to the delivery requests.
+	Header http.Header `json:"-" yaml:"-"`
+}
+
+// Subscribed reports whether the endpoint receives the events of the given type.
+func (e Endpoint) Subscribed(eventType string) bool {
+	return len(e.Events) == 0 || slices.Contains(e.Events, eventType)
+}
+
+func (e Endpoint) validate() error {
+	if e.ID == "" {
+		return errors.New("webhook: endpoint ID is required")
+	}
+	if e.Secret == "" {
+		return fmt.Errorf("webhook: endpoint %q: secret is required", e.ID)
+	}
+	u, err := url.Parse(e.URL)
+	if err != nil {
+		return fmt.Errorf("webhook: endpoint %q: %w", e.ID, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("webhook: endpoint %q: invalid URL %q", e.ID, e.URL)
+	}
+	return nil
+}
+
+// Dispatcher delivers the events to the registered endpoints.
+//
+// Every delivery attempt is signed with the X-Signature scheme verified by [middleware.VerifySignature]
+// (the Date header is the timestamp, the nonce is unique per attempt) and recorded in the Config.Store.
+// Network errors, 408, 425, 429 and 5xx responses are retried with an exponential backoff,
+// honouring the Retry-After header, until Config.MaxAttempts is reached.
+type Dispatcher struct {
+	cfg       Config
+	mu        sync.RWMutex
+	endpoints map[string]Endpoint
+	now       func() time.Time
+	sleep     func(ctx context.Context, d time.Duration) error
+}
+
+func NewDispatcher(cfg Config) *Dispatcher {
+	cfg.SetDefaults()
+
+	return &Dispatcher{
+		cfg:       cfg,
+		endpoints: make(map[string]Endpoint),
+		now:       time.Now,
+		sleep:     internal.Sleep,
+	}
+}
+
+// Register adds the endpoint, replacing the one with the same ID.
+// The endpoint URL may have any host, see Config.Client for the SSRF protection.
+func (d *Dispatcher) Register(endpoint Endpoint) error {
+	if err := endpoint.validate(); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.endpoints[endpoint.ID] = endpoint
+	return nil
+}
+
+// Unregister removes the endpoint and reports whether it was registered.
+func (d *Dispatcher) Unregister(id string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, ok := d.endpoints[id]
+	delete(d.endpoints, id)
+	return ok
+}
+
+// Endpoints returns the registered endpoints sorted by ID.
+func (d *Dispatcher) Endpoints() []Endpoint {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	endpoints := make([]Endpoint, 0, len(d.endpoints))
+	for _, endpoint := range d.endpoints {
+		endpoints = append(endpoints, endpoint)
+	}
+	slices.SortFunc(endpoints, func(a, b Endpoint) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return endpoints
+}
+
+// Dispatch delivers the event concurrently to the subscribed endpoints and waits for the deliveries.
+// The returned error joins the failed deliveries errors.
+func (d *Dispatcher) Dispatch(ctx context.Context, event Event) ([]Delivery, error) {
+	var endpoints []Endpoint
+	for _, endpoint := range d.Endpoints() {
+		if endpoint.Subscribed(event.Type) {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+
+	deliveries := make([]Delivery, len(endpoints))
+	errs := make([]error, len(endpoints))
+
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Go(func() {
+			deliveries[i], errs[i] = d.Deliver(ctx, endpoint, event)
+		})
+	}
+	wg.Wait()
+
+	return deliveries, errors.Join(errs...)
+}
+
+// Deliver delivers the event to the endpoint, retrying the failed attempts.
+func (d *Dispatcher) Deliver(ctx context.Context, endpoint Endpoint, event Event) (Delivery, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return Delivery{}, fmt.Errorf("webhook: encode event: %w", err)
+	}
+
+	now := d.now()
+	delivery := Delivery{
+		ID:         uuid.NewString(),
+		EndpointID: endpoint.ID,
+		EventID:    event.ID,
+		EventType:  event.Type,
+		Status:     StatusPending,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err = d.cfg.Store.Save(ctx, delivery); err != nil {
+		return delivery, fmt.Errorf("webhook: save delivery: %w", err)
+	}
+
+	for {
+		delivery.Attempts++
+
+		var retryAfter time.Duration
+		delivery.StatusCode, retryAfter, err = d.attempt(ctx, endpoint, event, delivery, body)
+
+		retry := err != nil && delivery.Attempts < d.cfg.MaxAttempts && ctx.Err() == nil && internal.Retryable(delivery.StatusCode)
+
+		delivery.UpdatedAt = d.now()
+		delivery.Error = ""
+		switch {
+		case err == nil:
+			delivery.Status = StatusSucceeded
+		case retry:
+			delivery.Error = err.Error()
+		default:
+			delivery.Status = StatusFailed
+			delivery.Error = err.Error()
+		}
+
+		if saveErr := d.cfg.Store.Save(context.WithoutCancel(ctx), delivery); saveErr != nil {
+			return delivery, errors.Join(err, fmt.Errorf("webhook: save delivery: %w", saveErr))
+		}
+
+		if !retry {
+			if err != nil {
+				err = fmt.Errorf("webhook: deliver %s to endpoint %q: %w", delivery.ID, endpoint.ID, err)
+			}
+			return delivery, err
+		}
+
+		if err = d.sleep(ctx, max(retryAfter, d.backoff(delivery.Attempts))); err != nil {
+			delivery.Status = StatusFailed
+			delivery.Error = err.Error()
+			delivery.UpdatedAt = d.now()
+			_ = d.cfg.Store.Save(context.WithoutCancel(ctx), delivery)
+			return delivery, fmt.Errorf("webhook: deliver %s to endpoint %q: %w", delivery.ID, endpoint.ID, err)
+		}
+	}
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, endpoint Endpoint, event Event, delivery Delivery, body []byte) (int, time.Duration, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for name, values := range endpoint.Header {
+		r.Header[name] = slices.Clone(values)
+	}
+	r.Header.Set(keratin.HeaderContentType, keratin.MIMEApplicationJSON)
+	r.Header.Set("User-Agent", d.cfg.UserAgent)
+	r.Header.Set(HeaderWebhookEvent, event.Type)
+	r.Header.Set(HeaderWebhookDelivery, delivery.ID)
+	r.Header.Set(keratin.HeaderDate, d.now().UTC().Format(http.TimeFormat))
+
+	nonce := delivery.ID + "." + strconv.Itoa(delivery.Attempts)
+	if err = middleware.SignRequest(r, endpoint.KeyID, endpoint.Secret, nonce); err != nil {
+		return 0, 0, err
+	}
+
+	res, err := d.cfg.Client.Do(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return res.StatusCode, 0, nil
+	}
+
+	return res.StatusCode, d.retryAfter(res.Header.Get(keratin.HeaderRetryAfter)), fmt.Errorf("unexpected status %d", res.StatusCode)
+}
+
+// backoff returns the delay after the given attempt.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.InitialBackoff
+	for i := 1; i < attempt && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.cfg.MaxBackoff)
+}
+
+func (d *Dispatcher) retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		delay = t.Sub(d.now())
+	}
+
+	return min(max(delay, 0), d.cfg.MaxBackoff)
+}
@@ -0,0 +1,264 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+	"github.com/gowool/keratin/internal"
+	"github.com/gowool/keratin/middleware"
+)
+
+func newTestDispatcher(cfg Config) (*Dispatcher, *[]time.Duration) {
+	var (
+		mu     sync.Mutex
+		delays []time.Duration
+	)
+
+	d := NewDispatcher(cfg)
+	d.sleep = func(_ context.Context, delay time.Duration) error {
+		mu.Lock()
+		defer mu.Unlock()
+		delays = append(delays, delay)
+		return nil
+	}
+	return d, &delays
+}
+
+func TestNewEvent(t *testing.T) {
+	event, err := NewEvent("order.paid", map[string]int{"id": 1})
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, event.ID)
+	assert.Equal(t, "order.paid", event.Type)
+	assert.JSONEq(t, `{"id":1}`, string(event.Data))
+	assert.False(t, event.CreatedAt.IsZero())
+
+	_, err = NewEvent("invalid", func() {})
+	assert.Error(t, err)
+}
+
+func TestDispatcher_Register(t *testing.T) {
+	d := NewDispatcher(Config{})
+
+	tests := []struct {
+		name     string
+		endpoint Endpoint
+		wantErr  string
+	}{
+		{name: "valid", endpoint: Endpoint{ID: "a", URL: "https://example.com/hook", Secret: "s"}},
+		{name: "missing ID", endpoint: Endpoint{URL: "https://example.com", Secret: "s"}, wantErr: "ID is required"},
+		{name: "missing secret", endpoint: Endpoint{ID: "a", URL: "https://example.com"}, wantErr: "secret is required"},
+		{name: "invalid scheme", endpoint: Endpoint{ID: "a", URL: "ftp://example.com", Secret: "s"}, wantErr: "invalid URL"},
+		{name: "relative URL", endpoint: Endpoint{ID: "a", URL: "/hook", Secret: "s"}, wantErr: "invalid URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := d.Register(tt.endpoint)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	require.NoError(t, d.Register(Endpoint{ID: "b", URL: "http://example.com", Secret: "s"}))
+	assert.Equal(t, []string{"a", "b"}, endpointIDs(d.Endpoints()))
+
+	assert.True(t, d.Unregister("a"))
+	assert.False(t, d.Unregister("a"))
+	assert.Equal(t, []string{"b"}, endpointIDs(d.Endpoints()))
+}
+
+func endpointIDs(endpoints []Endpoint) []string {
+	ids := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		ids = append(ids, endpoint.ID)
+	}
+	return ids
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	var (
+		calls    atomic.Int32
+		received = make(chan Event, 1)
+	)
+
+	receiver := Receiver(middleware.SignatureConfig{
+		Secrets:    map[string]string{"k1": "secret"},
+		NonceStore: middleware.NewIdempotencyMemoryStorage(),
+	}, func(_ context.Context, event Event) error {
+		if calls.Add(1) < 3 {
+			return keratin.ErrServiceUnavailable
+		}
+		received <- event
+		return nil
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "order.paid", r.Header.Get(HeaderWebhookEvent))
+		assert.Equal(t, "tenant-1", r.Header.Get("X-Tenant"))
+
+		if err := receiver.ServeHTTP(w, r); err != nil {
+			w.WriteHeader(keratin.HTTPErrorStatusCode(err))
+		}
+	}))
+	defer srv.Close()
+
+	store := NewMemoryStore()
+	d, delays := newTestDispatcher(Config{Store: store, InitialBackoff: time.Second, MaxBackoff: 3 * time.Second})
+
+	require.NoError(t, d.Register(Endpoint{
+		ID:     "receiver",
+		URL:    srv.URL,
+		KeyID:  "k1",
+		Secret: "secret",
+		Events: []string{"order.paid"},
+		Header: http.Header{"X-Tenant": {"tenant-1"}},
+	}))
+	require.NoError(t, d.Register(Endpoint{ID: "other", URL: srv.URL, Secret: "other", Events: []string{"order.refunded"}}))
+
+	event, err := NewEvent("order.paid", map[string]string{"order": "42"})
+	require.NoError(t, err)
+
+	deliveries, err := d.Dispatch(context.Background(), event)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+
+	delivery := deliveries[0]
+	assert.Equal(t, StatusSucceeded, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, http.StatusNoContent, delivery.StatusCode)
+	assert.Empty(t, delivery.Error)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, *delays)
+
+	got := <-received
+	assert.Equal(t, event.ID, got.ID)
+	assert.JSONEq(t, `{"order":"42"}`, string(got.Data))
+
+	stored, err := store.Get(context.Background(), delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, delivery, stored)
+}
+
+func TestDispatcher_Deliver(t *testing.T) {
+	tests := []struct {
+		name         string
+		handler      http.HandlerFunc
+		wantStatus   Status
+		wantAttempts int
+		wantCode     int
+		wantDelays   []time.Duration
+	}{
+		{
+			name:         "success",
+			handler:      func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusAccepted) },
+			wantStatus:   StatusSucceeded,
+			wantAttempts: 1,
+			wantCode:     http.StatusAccepted,
+		},
+		{
+			name:         "client error is not retried",
+			handler:      func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusUnauthorized) },
+			wantStatus:   StatusFailed,
+			wantAttempts: 1,
+			wantCode:     http.StatusUnauthorized,
+		},
+		{
+			name:         "server error is retried until max attempts",
+			handler:      func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusBadGateway) },
+			wantStatus:   StatusFailed,
+			wantAttempts: 3,
+			wantCode:     http.StatusBadGateway,
+			wantDelays:   []time.Duration{10 * time.Millisecond, 20 * time.Millisecond},
+		},
+		{
+			name: "retry after",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set(keratin.HeaderRetryAfter, "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			wantStatus:   StatusFailed,
+			wantAttempts: 3,
+			wantCode:     http.StatusTooManyRequests,
+			wantDelays:   []time.Duration{time.Second, time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			d, delays := newTestDispatcher(Config{MaxAttempts: 3, InitialBackoff: 10 * time.Millisecond})
+			endpoint := Endpoint{ID: "e", URL: srv.URL, Secret: "secret"}
+
+			delivery, err := d.Deliver(context.Background(), endpoint, Event{ID: "1", Type: "ping"})
+			if tt.wantStatus == StatusSucceeded {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.NotEmpty(t, delivery.Error)
+			}
+
+			assert.Equal(t, tt.wantStatus, delivery.Status)
+			assert.Equal(t, tt.wantAttempts, delivery.Attempts)
+			assert.Equal(t, tt.wantCode, delivery.StatusCode)
+			assert.Equal(t, tt.wantDelays, *delays)
+		})
+	}
+}
+
+func TestDispatcher_Deliver_ContextCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	d := NewDispatcher(Config{})
+	d.sleep = func(ctx context.Context, _ time.Duration) error {
+		cancel()
+		return internal.Sleep(ctx, time.Hour)
+	}
+
+	delivery, err := d.Deliver(ctx, Endpoint{ID: "e", URL: srv.URL, Secret: "secret"}, Event{ID: "1", Type: "ping"})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, StatusFailed, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+}
+
+func TestDispatcher_backoff(t *testing.T) {
+	d := NewDispatcher(Config{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second})
+
+	assert.Equal(t, time.Second, d.backoff(1))
+	assert.Equal(t, 2*time.Second, d.backoff(2))
+	assert.Equal(t, 4*time.Second, d.backoff(3))
+	assert.Equal(t, 5*time.Second, d.backoff(4))
+	assert.Equal(t, 5*time.Second, d.backoff(100))
+}
+
+func TestDispatcher_retryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	d := NewDispatcher(Config{MaxBackoff: time.Minute})
+	d.now = func() time.Time { return now }
+
+	assert.Equal(t, time.Duration(0), d.retryAfter(""))
+	assert.Equal(t, 3*time.Second, d.retryAfter("3"))
+	assert.Equal(t, time.Minute, d.retryAfter("3600"))
+	assert.Equal(t, 10*time.Second, d.retryAfter(now.Add(10*time.Second).Format(http.TimeFormat)))
+	assert.Equal(t, time.Duration(0), d.retryAfter(now.Add(-time.Hour).Format(http.TimeFormat)))
+	assert.Equal(t, time.Duration(0), d.retryAfter("invalid"))
+}
@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gowool/keratin"
+	"github.com/gowool/keratin/middleware"
+)
+
+// EventHandler handles the received events.
+type EventHandler func(ctx context.Context, event Event) error
+
+// Receiver returns a handler which verifies the signature of the deliveries with [middleware.VerifySignature],
+// decodes their events and passes them to the handler. It responds 204 No Content when the handler succeeds,
+// any error makes the sender retry the delivery.
+func Receiver(cfg middleware.SignatureConfig, handler EventHandler) keratin.Handler {
+	if handler == nil {
+		panic("webhook: receiver: event handler is required")
+	}
+
+	return middleware.VerifySignature(cfg)(keratin.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		var event Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			return keratin.ErrBadRequest.Wrap(err)
+		}
+		if event.Type == "" {
+			event.Type = r.Header.Get(HeaderWebhookEvent)
+		}
+
+		if err := handler(r.Context(), event); err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}))
+}
+
+// Receive registers the POST route of the [Receiver] on the group.
+func Receive(group *keratin.RouterGroup, path string, cfg middleware.SignatureConfig, handler EventHandler) *keratin.Route {
+	return group.Route(http.MethodPost, path, Receiver(cfg, handler))
+}
@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/keratin"
+	"github.com/gowool/keratin/middleware"
+)
+
+func TestReceive(t *testing.T) {
+	var got Event
+
+	router := keratin.NewRouter()
+	route := Receive(router.RouterGroup, "/webhooks", middleware.SignatureConfig{
+		Secrets: map[string]string{"k1": "secret"},
+	}, func(_ context.Context, event Event) error {
+		if event.Type == "fail" {
+			return errors.New("boom")
+		}
+		got = event
+		return nil
+	})
+	assert.Equal(t, "/webhooks", route.Path)
+
+	handler := router.Build()
+
+	tests := []struct {
+		name     string
+		body     string
+		header   http.Header
+		secret   string
+		wantCode int
+	}{
+		{
+			name:     "valid",
+			body:     `{"id":"1","type":"order.paid","data":{"order":42}}`,
+			secret:   "secret",
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "event type from header",
+			body:     `{"id":"2"}`,
+			header:   http.Header{HeaderWebhookEvent: {"order.shipped"}},
+			secret:   "secret",
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "invalid signature",
+			body:     `{"id":"3","type":"order.paid"}`,
+			secret:   "wrong",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "invalid payload",
+			body:     `not json`,
+			secret:   "secret",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "handler error",
+			body:     `{"id":"4","type":"fail"}`,
+			secret:   "secret",
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = Event{}
+
+			r := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(tt.body))
+			for name, values := range tt.header {
+				r.Header[name] = values
+			}
+			require.NoError(t, middleware.SignRequest(r, "k1", tt.secret, ""))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantCode == http.StatusNoContent {
+				assert.NotEmpty(t, got.ID)
+				assert.NotEmpty(t, got.Type)
+			}
+		})
+	}
+}
+
+func TestReceiver_Panics(t *testing.T) {
+	assert.Panics(t, func() { Receiver(middleware.SignatureConfig{Secrets: map[string]string{"k": "s"}}, nil) })
+}
@@ -0,0 +1,149 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDeliveryNotFound is returned by [Store.Get] for an unknown delivery.
+var ErrDeliveryNotFound = errors.New("webhook: delivery not found")
+
+// Status is the state of a delivery.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Delivery is the delivery of an event to an endpoint.
+type Delivery struct {
+	ID         string    `json:"id"`
+	EndpointID string    `json:"endpointId"`
+	EventID    string    `json:"eventId"`
+	EventType  string    `json:"eventType"`
+	Status     Status    `json:"status"`
+	Attempts   int       `json:"attempts"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// Store records the deliveries, it is updated after every delivery attempt.
+type Store interface {
+	Save(ctx context.Context, delivery Delivery) error
+	Get(ctx context.Context, id string) (Delivery, error)
+	// List returns the deliveries of the endpoint, all of them for an empty endpointID.
+	List(ctx context.Context, endpointID string) ([]Delivery, error)
+}
+
+type MemoryStoreConfig struct {
+	// MaxDeliveries is the maximum number of the kept deliveries, the oldest ones are evicted first.
+	//
+	// Default: 1000
+	MaxDeliveries int `env:"MAX_DELIVERIES" json:"maxDeliveries,omitempty" yaml:"maxDeliveries,omitempty"`
+
+	// Retention is how long the deliveries are kept after their creation.
+	//
+	// Default: 24h
+	Retention time.Duration `env:"RETENTION" json:"retention,omitempty,format:units" yaml:"retention,omitempty"`
+}
+
+func (c *MemoryStoreConfig) SetDefaults() {
+	if c.MaxDeliveries <= 0 {
+		c.MaxDeliveries = 1000
+	}
+	if c.Retention <= 0 {
+		c.Retention = 24 * time.Hour
+	}
+}
+
+// MemoryStore is an in-memory [Store] for single instance deployments and tests,
+// it keeps a bounded number of recent deliveries, see [MemoryStoreConfig].
+type MemoryStore struct {
+	cfg   MemoryStoreConfig
+	mu    sync.RWMutex
+	data  map[string]Delivery
+	order []string
+	now   func() time.Time
+}
+
+func NewMemoryStore(cfg ...MemoryStoreConfig) *MemoryStore {
+	var c MemoryStoreConfig
+	if len(cfg) > 0 {
+		c = cfg[0]
+	}
+	c.SetDefaults()
+
+	return &MemoryStore{cfg: c, data: make(map[string]Delivery), now: time.Now}
+}
+
+// Save records the delivery and evicts the deliveries over the limits.
+func (s *MemoryStore) Save(_ context.Context, delivery Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data[delivery.ID]; !ok {
+		s.order = append(s.order, delivery.ID)
+	}
+	s.data[delivery.ID] = delivery
+
+	s.prune()
+	return nil
+}
+
+// prune evicts the deliveries in their saving order while they are over MaxDeliveries
+// or created before the retention period.
+func (s *MemoryStore) prune() {
+	cutoff := s.now().Add(-s.cfg.Retention)
+
+	var n int
+	for _, id := range s.order {
+		if len(s.order)-n <= s.cfg.MaxDeliveries && !s.data[id].CreatedAt.Before(cutoff) {
+			break
+		}
+		delete(s.data, id)
+		n++
+	}
+
+	if n > 0 {
+		s.order = slices.Delete(s.order, 0, n)
+	}
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	delivery, ok := s.data[id]
+	if !ok {
+		return Delivery{}, ErrDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+func (s *MemoryStore) List(_ context.Context, endpointID string) ([]Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deliveries := make([]Delivery, 0, len(s.data))
+	for _, delivery := range s.data {
+		if endpointID == "" || delivery.EndpointID == endpointID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	slices.SortFunc(deliveries, func(a, b Delivery) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return deliveries, nil
+}
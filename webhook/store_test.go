@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := NewMemoryStore()
+
+	_, err := store.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrDeliveryNotFound)
+
+	deliveries := []Delivery{
+		{ID: "3", EndpointID: "b", Status: StatusPending, CreatedAt: now},
+		{ID: "1", EndpointID: "a", Status: StatusPending, CreatedAt: now},
+		{ID: "2", EndpointID: "a", Status: StatusFailed, CreatedAt: now.Add(-time.Minute)},
+	}
+	for _, delivery := range deliveries {
+		require.NoError(t, store.Save(ctx, delivery))
+	}
+
+	deliveries[1].Status = StatusSucceeded
+	require.NoError(t, store.Save(ctx, deliveries[1]))
+
+	got, err := store.Get(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, got.Status)
+
+	all, err := store.List(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "1", "3"}, deliveryIDs(all))
+
+	a, err := store.List(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "1"}, deliveryIDs(a))
+
+	none, err := store.List(ctx, "c")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func deliveryIDs(deliveries []Delivery) []string {
+	ids := make([]string, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.ID)
+	}
+	return ids
+}
+
+func TestMemoryStore_Eviction(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	store := NewMemoryStore(MemoryStoreConfig{MaxDeliveries: 2, Retention: time.Hour})
+	store.now = func() time.Time { return now }
+
+	require.NoError(t, store.Save(ctx, Delivery{ID: "old", CreatedAt: now.Add(-2 * time.Hour)}))
+	require.NoError(t, store.Save(ctx, Delivery{ID: "1", CreatedAt: now}))
+
+	// expired
+	_, err := store.Get(ctx, "old")
+	assert.ErrorIs(t, err, ErrDeliveryNotFound)
+
+	require.NoError(t, store.Save(ctx, Delivery{ID: "2", CreatedAt: now}))
+	require.NoError(t, store.Save(ctx, Delivery{ID: "1", Status: StatusSucceeded, CreatedAt: now}))
+	require.NoError(t, store.Save(ctx, Delivery{ID: "3", CreatedAt: now}))
+
+	// the oldest one is evicted over the limit
+	all, err := store.List(ctx, "")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"2", "3"}, deliveryIDs(all))
+}